@@ -844,5 +844,55 @@ func (c *Client) PrevLiquidityPoolsPage(page hProtocol.LiquidityPoolsPage) (lp h
 	return
 }
 
+// ContractEvents returns the events emitted by a contract.
+func (c *Client) ContractEvents(request ContractEventsRequest) (events hProtocol.ContractEventsPage, err error) {
+	err = c.sendRequest(request, &events)
+	return
+}
+
+// NextContractEventsPage returns the next page of contract events.
+func (c *Client) NextContractEventsPage(page hProtocol.ContractEventsPage) (events hProtocol.ContractEventsPage, err error) {
+	err = c.sendGetRequest(page.Links.Next.Href, &events)
+	return
+}
+
+// PrevContractEventsPage returns the previous page of contract events.
+func (c *Client) PrevContractEventsPage(page hProtocol.ContractEventsPage) (events hProtocol.ContractEventsPage, err error) {
+	err = c.sendGetRequest(page.Links.Prev.Href, &events)
+	return
+}
+
+// StreamContractEvents streams the events emitted by a contract. Use context.WithCancel to stop
+// streaming or context.Background() if you want to stream indefinitely.
+// ContractEventHandler is a user-supplied function that is executed for each streamed event received.
+func (c *Client) StreamContractEvents(ctx context.Context, request ContractEventsRequest, handler ContractEventHandler) error {
+	return request.StreamContractEvents(ctx, c, handler)
+}
+
+// ContractData returns the current storage entries of a contract.
+func (c *Client) ContractData(request ContractDataRequest) (data hProtocol.ContractDataPage, err error) {
+	err = c.sendRequest(request, &data)
+	return
+}
+
+// NextContractDataPage returns the next page of contract data entries.
+func (c *Client) NextContractDataPage(page hProtocol.ContractDataPage) (data hProtocol.ContractDataPage, err error) {
+	err = c.sendGetRequest(page.Links.Next.Href, &data)
+	return
+}
+
+// PrevContractDataPage returns the previous page of contract data entries.
+func (c *Client) PrevContractDataPage(page hProtocol.ContractDataPage) (data hProtocol.ContractDataPage, err error) {
+	err = c.sendGetRequest(page.Links.Prev.Href, &data)
+	return
+}
+
+// StreamContractData streams the storage entries of a contract as they change. Use context.WithCancel
+// to stop streaming or context.Background() if you want to stream indefinitely.
+// ContractDataHandler is a user-supplied function that is executed for each streamed entry received.
+func (c *Client) StreamContractData(ctx context.Context, request ContractDataRequest, handler ContractDataHandler) error {
+	return request.StreamContractData(ctx, c, handler)
+}
+
 // ensure that the aurora client implements ClientInterface
 var _ ClientInterface = &Client{}
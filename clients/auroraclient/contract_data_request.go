@@ -0,0 +1,78 @@
+package auroraclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/errors"
+)
+
+// ContractDataHandler is a function that is called when a new contract data entry is received
+type ContractDataHandler func(hProtocol.ContractDataEntry)
+
+// ContractDataRequest struct contains data for getting the current storage
+// entries of a contract from a aurora server. "ContractID" is required.
+// The query parameters (Order, Cursor and Limit) are optional. All or none can be set.
+type ContractDataRequest struct {
+	ContractID string
+	Order      Order
+	Cursor     string
+	Limit      uint
+}
+
+// BuildURL creates the endpoint to be queried based on the data in the ContractDataRequest struct.
+func (r ContractDataRequest) BuildURL() (endpoint string, err error) {
+	if r.ContractID == "" {
+		return endpoint, errors.New("invalid request: no contract id provided")
+	}
+
+	endpoint = fmt.Sprintf("contracts/%s/data", r.ContractID)
+
+	queryParams := addQueryParams(cursor(r.Cursor), limit(r.Limit), r.Order)
+	if queryParams != "" {
+		endpoint = fmt.Sprintf("%s?%s", endpoint, queryParams)
+	}
+
+	_, err = url.Parse(endpoint)
+	if err != nil {
+		err = errors.Wrap(err, "failed to parse endpoint")
+	}
+
+	return endpoint, err
+}
+
+// HTTPRequest returns the http request for the contract data endpoint
+func (r ContractDataRequest) HTTPRequest(auroraURL string) (*http.Request, error) {
+	endpoint, err := r.BuildURL()
+	if err != nil {
+		return nil, err
+	}
+
+	return http.NewRequest("GET", auroraURL+endpoint, nil)
+}
+
+// StreamContractData streams the storage entries of a contract as they are
+// created or updated.
+// Use context.WithCancel to stop streaming or context.Background() if you want to stream indefinitely.
+// ContractDataHandler is a user-supplied function that is executed for each streamed entry received.
+func (r ContractDataRequest) StreamContractData(ctx context.Context, client *Client, handler ContractDataHandler) error {
+	endpoint, err := r.BuildURL()
+	if err != nil {
+		return errors.Wrap(err, "unable to build endpoint for contract data request")
+	}
+
+	url := fmt.Sprintf("%s%s", client.fixAuroraURL(), endpoint)
+	return client.stream(ctx, url, func(data []byte) error {
+		var entry hProtocol.ContractDataEntry
+		err = json.Unmarshal(data, &entry)
+		if err != nil {
+			return errors.Wrap(err, "error unmarshaling data for contract data request")
+		}
+		handler(entry)
+		return nil
+	})
+}
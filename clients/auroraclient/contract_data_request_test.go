@@ -0,0 +1,106 @@
+package auroraclient
+
+import (
+	"context"
+	"testing"
+
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/http/httptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContractDataRequestBuildUrl(t *testing.T) {
+	// It should return an error if no contract id is provided
+	_, err := ContractDataRequest{}.BuildURL()
+	require.EqualError(t, err, "invalid request: no contract id provided")
+
+	endpoint, err := ContractDataRequest{
+		ContractID: "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS",
+		Limit:      20,
+	}.BuildURL()
+	require.NoError(t, err)
+	assert.Equal(t, "contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/data?limit=20", endpoint)
+}
+
+func TestContractDataRequest(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		AuroraURL: "https://localhost/",
+		HTTP:      hmock,
+	}
+
+	request := ContractDataRequest{ContractID: "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS"}
+
+	hmock.On(
+		"GET",
+		"https://localhost/contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/data",
+	).ReturnString(200, contractDataResponse)
+
+	response, err := client.ContractData(request)
+	if assert.NoError(t, err) {
+		assert.IsType(t, response, hProtocol.ContractDataPage{})
+		record := response.Embedded.Records[0]
+		assert.Equal(t, "persistent", record.Durability)
+		assert.Equal(t, "AAAADwAAAAdjcmVhdGVkAA==", record.Key)
+		assert.Equal(t, "AAAAAwAAAAE=", record.Value)
+		assert.Equal(t, uint32(3), record.LastModifiedLedger)
+	}
+}
+
+func TestContractDataRequestStreamContractData(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		AuroraURL: "https://localhost/",
+		HTTP:      hmock,
+	}
+
+	request := ContractDataRequest{ContractID: "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS"}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	hmock.On(
+		"GET",
+		"https://localhost/contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/data?cursor=now",
+	).ReturnString(200, contractDataStreamResponse)
+
+	var entries []hProtocol.ContractDataEntry
+	err := client.StreamContractData(ctx, request, func(entry hProtocol.ContractDataEntry) {
+		entries = append(entries, entry)
+		cancel()
+	})
+
+	if assert.NoError(t, err) {
+		require.Len(t, entries, 1)
+		assert.Equal(t, "temporary", entries[0].Durability)
+	}
+}
+
+var contractDataResponse = `{
+  "_links": {
+    "self": {
+      "href": "https://aurora.hcnet.org/contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/data?cursor=&limit=10&order=asc"
+    },
+    "next": {
+      "href": "https://aurora.hcnet.org/contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/data?cursor=0a2b3e6b4f0ce9bba34ca9fb63dd9c1ec78dc61de8bbd2ef7fa0ee8a5a1d1cc4&limit=10&order=asc"
+    },
+    "prev": {
+      "href": "https://aurora.hcnet.org/contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/data?cursor=0a2b3e6b4f0ce9bba34ca9fb63dd9c1ec78dc61de8bbd2ef7fa0ee8a5a1d1cc4&limit=10&order=desc"
+    }
+  },
+  "_embedded": {
+    "records": [
+      {
+        "id": "0a2b3e6b4f0ce9bba34ca9fb63dd9c1ec78dc61de8bbd2ef7fa0ee8a5a1d1cc4",
+        "paging_token": "0a2b3e6b4f0ce9bba34ca9fb63dd9c1ec78dc61de8bbd2ef7fa0ee8a5a1d1cc4",
+        "contract_id": "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS",
+        "durability": "persistent",
+        "key": "AAAADwAAAAdjcmVhdGVkAA==",
+        "value": "AAAAAwAAAAE=",
+        "last_modified_ledger": 3
+      }
+    ]
+  }
+}`
+
+var contractDataStreamResponse = `data: {"id":"0a2b3e6b4f0ce9bba34ca9fb63dd9c1ec78dc61de8bbd2ef7fa0ee8a5a1d1cc4","paging_token":"0a2b3e6b4f0ce9bba34ca9fb63dd9c1ec78dc61de8bbd2ef7fa0ee8a5a1d1cc4","contract_id":"CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS","durability":"temporary","key":"AAAADwAAAAdjcmVhdGVkAA==","value":"AAAAAwAAAAE=","last_modified_ledger":3}
+`
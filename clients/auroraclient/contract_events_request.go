@@ -0,0 +1,94 @@
+package auroraclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/errors"
+)
+
+// maxContractEventTopics is the maximum number of topics aurora can filter
+// contract events by.
+const maxContractEventTopics = 4
+
+// ContractEventHandler is a function that is called when a new contract event is received
+type ContractEventHandler func(hProtocol.ContractEvent)
+
+// ContractEventsRequest struct contains data for getting the events emitted by
+// a contract from a aurora server. "ContractID" is required.
+// "Topics" filters events by their topics, matched by position. Each topic
+// is the base64 encoded XDR of an ScVal, use "*" or an empty string to match
+// any topic at that position.
+// The query parameters (Order, Cursor and Limit) are optional. All or none can be set.
+type ContractEventsRequest struct {
+	ContractID string
+	Topics     []string
+	Order      Order
+	Cursor     string
+	Limit      uint
+}
+
+// BuildURL creates the endpoint to be queried based on the data in the ContractEventsRequest struct.
+func (r ContractEventsRequest) BuildURL() (endpoint string, err error) {
+	if r.ContractID == "" {
+		return endpoint, errors.New("invalid request: no contract id provided")
+	}
+
+	if len(r.Topics) > maxContractEventTopics {
+		return endpoint, errors.Errorf("invalid request: at most %d topics can be provided", maxContractEventTopics)
+	}
+
+	endpoint = fmt.Sprintf("contracts/%s/events", r.ContractID)
+
+	topics := map[string]string{}
+	for i, topic := range r.Topics {
+		topics[fmt.Sprintf("topic%d", i+1)] = topic
+	}
+
+	queryParams := addQueryParams(cursor(r.Cursor), limit(r.Limit), r.Order, topics)
+	if queryParams != "" {
+		endpoint = fmt.Sprintf("%s?%s", endpoint, queryParams)
+	}
+
+	_, err = url.Parse(endpoint)
+	if err != nil {
+		err = errors.Wrap(err, "failed to parse endpoint")
+	}
+
+	return endpoint, err
+}
+
+// HTTPRequest returns the http request for the contract events endpoint
+func (r ContractEventsRequest) HTTPRequest(auroraURL string) (*http.Request, error) {
+	endpoint, err := r.BuildURL()
+	if err != nil {
+		return nil, err
+	}
+
+	return http.NewRequest("GET", auroraURL+endpoint, nil)
+}
+
+// StreamContractEvents streams the events emitted by a contract.
+// Use context.WithCancel to stop streaming or context.Background() if you want to stream indefinitely.
+// ContractEventHandler is a user-supplied function that is executed for each streamed event received.
+func (r ContractEventsRequest) StreamContractEvents(ctx context.Context, client *Client, handler ContractEventHandler) error {
+	endpoint, err := r.BuildURL()
+	if err != nil {
+		return errors.Wrap(err, "unable to build endpoint for contract events request")
+	}
+
+	url := fmt.Sprintf("%s%s", client.fixAuroraURL(), endpoint)
+	return client.stream(ctx, url, func(data []byte) error {
+		var event hProtocol.ContractEvent
+		err = json.Unmarshal(data, &event)
+		if err != nil {
+			return errors.Wrap(err, "error unmarshaling data for contract events request")
+		}
+		handler(event)
+		return nil
+	})
+}
@@ -0,0 +1,144 @@
+package auroraclient
+
+import (
+	"context"
+	"testing"
+
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/http/httptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContractEventsRequestBuildUrl(t *testing.T) {
+	// It should return an error if no contract id is provided
+	_, err := ContractEventsRequest{}.BuildURL()
+	require.EqualError(t, err, "invalid request: no contract id provided")
+
+	endpoint, err := ContractEventsRequest{
+		ContractID: "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS",
+	}.BuildURL()
+	require.NoError(t, err)
+	assert.Equal(t, "contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/events", endpoint)
+
+	// It should filter by topic position, skipping empty topics
+	endpoint, err = ContractEventsRequest{
+		ContractID: "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS",
+		Topics:     []string{"*", "", "AAAADwAAAAdjcmVhdGVkAA=="},
+		Cursor:     "now",
+		Order:      OrderDesc,
+	}.BuildURL()
+	require.NoError(t, err)
+	assert.Equal(t, "contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/events?cursor=now&order=desc&topic1=%2A&topic3=AAAADwAAAAdjcmVhdGVkAA%3D%3D", endpoint)
+
+	// It should return an error if too many topics are provided
+	_, err = ContractEventsRequest{
+		ContractID: "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS",
+		Topics:     []string{"*", "*", "*", "*", "*"},
+	}.BuildURL()
+	require.EqualError(t, err, "invalid request: at most 4 topics can be provided")
+}
+
+func TestContractEventsRequest(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		AuroraURL: "https://localhost/",
+		HTTP:      hmock,
+	}
+
+	request := ContractEventsRequest{ContractID: "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS"}
+
+	hmock.On(
+		"GET",
+		"https://localhost/contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/events",
+	).ReturnString(200, contractEventsResponse)
+
+	response, err := client.ContractEvents(request)
+	if assert.NoError(t, err) {
+		assert.IsType(t, response, hProtocol.ContractEventsPage{})
+		record := response.Embedded.Records[0]
+		assert.Equal(t, "0000000012884905985-0000000001", record.ID)
+		assert.Equal(t, "12884905985-1", record.PagingToken())
+		assert.Equal(t, int32(3), record.Ledger)
+		assert.Equal(t, []string{"AAAADwAAAAdjcmVhdGVkAA=="}, record.Topics)
+		assert.Equal(t, "AAAAAwAAAAE=", record.Value)
+		assert.Equal(t, "https://aurora.hcnet.org/operations/12884905985", record.Links.Operation.Href)
+	}
+
+	// failure response
+	hmock.On(
+		"GET",
+		"https://localhost/contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/events",
+	).ReturnString(400, badRequestResponse)
+
+	_, err = client.ContractEvents(request)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "aurora error")
+		auroraError, ok := err.(*Error)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, auroraError.Problem.Title, "Bad Request")
+	}
+}
+
+func TestContractEventsRequestStreamContractEvents(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		AuroraURL: "https://localhost/",
+		HTTP:      hmock,
+	}
+
+	request := ContractEventsRequest{ContractID: "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS"}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	hmock.On(
+		"GET",
+		"https://localhost/contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/events?cursor=now",
+	).ReturnString(200, contractEventStreamResponse)
+
+	var events []hProtocol.ContractEvent
+	err := client.StreamContractEvents(ctx, request, func(event hProtocol.ContractEvent) {
+		events = append(events, event)
+		cancel()
+	})
+
+	if assert.NoError(t, err) {
+		require.Len(t, events, 1)
+		assert.Equal(t, "12884905985-1", events[0].PagingToken())
+		assert.Equal(t, "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS", events[0].ContractID)
+	}
+}
+
+var contractEventsResponse = `{
+  "_links": {
+    "self": {
+      "href": "https://aurora.hcnet.org/contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/events?cursor=&limit=10&order=asc"
+    },
+    "next": {
+      "href": "https://aurora.hcnet.org/contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/events?cursor=12884905985-1&limit=10&order=asc"
+    },
+    "prev": {
+      "href": "https://aurora.hcnet.org/contracts/CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS/events?cursor=12884905985-1&limit=10&order=desc"
+    }
+  },
+  "_embedded": {
+    "records": [
+      {
+        "_links": {
+          "operation": {
+            "href": "https://aurora.hcnet.org/operations/12884905985"
+          }
+        },
+        "id": "0000000012884905985-0000000001",
+        "paging_token": "12884905985-1",
+        "contract_id": "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS",
+        "ledger": 3,
+        "ledger_closed_at": "2023-06-01T10:00:00Z",
+        "topics": ["AAAADwAAAAdjcmVhdGVkAA=="],
+        "value": "AAAAAwAAAAE="
+      }
+    ]
+  }
+}`
+
+var contractEventStreamResponse = `data: {"_links":{"operation":{"href":"https://aurora.hcnet.org/operations/12884905985"}},"id":"0000000012884905985-0000000001","paging_token":"12884905985-1","contract_id":"CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS","ledger":3,"ledger_closed_at":"2023-06-01T10:00:00Z","topics":["AAAADwAAAAdjcmVhdGVkAA=="],"value":"AAAAAwAAAAE="}
+`
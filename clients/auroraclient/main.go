@@ -232,6 +232,14 @@ type ClientInterface interface {
 	LiquidityPools(request LiquidityPoolsRequest) (hProtocol.LiquidityPoolsPage, error)
 	NextLiquidityPoolsPage(hProtocol.LiquidityPoolsPage) (hProtocol.LiquidityPoolsPage, error)
 	PrevLiquidityPoolsPage(hProtocol.LiquidityPoolsPage) (hProtocol.LiquidityPoolsPage, error)
+	ContractEvents(request ContractEventsRequest) (hProtocol.ContractEventsPage, error)
+	NextContractEventsPage(hProtocol.ContractEventsPage) (hProtocol.ContractEventsPage, error)
+	PrevContractEventsPage(hProtocol.ContractEventsPage) (hProtocol.ContractEventsPage, error)
+	StreamContractEvents(ctx context.Context, request ContractEventsRequest, handler ContractEventHandler) error
+	ContractData(request ContractDataRequest) (hProtocol.ContractDataPage, error)
+	NextContractDataPage(hProtocol.ContractDataPage) (hProtocol.ContractDataPage, error)
+	PrevContractDataPage(hProtocol.ContractDataPage) (hProtocol.ContractDataPage, error)
+	StreamContractData(ctx context.Context, request ContractDataRequest, handler ContractDataHandler) error
 }
 
 // DefaultTestNetClient is a default client to connect to test network.
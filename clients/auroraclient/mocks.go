@@ -353,6 +353,52 @@ func (m *MockClient) PrevLiquidityPoolsPage(page hProtocol.LiquidityPoolsPage) (
 	return a.Get(0).(hProtocol.LiquidityPoolsPage), a.Error(1)
 }
 
+// ContractEvents is a mocking method
+func (m *MockClient) ContractEvents(request ContractEventsRequest) (hProtocol.ContractEventsPage, error) {
+	a := m.Called(request)
+	return a.Get(0).(hProtocol.ContractEventsPage), a.Error(1)
+}
+
+// NextContractEventsPage is a mocking method
+func (m *MockClient) NextContractEventsPage(page hProtocol.ContractEventsPage) (hProtocol.ContractEventsPage, error) {
+	a := m.Called(page)
+	return a.Get(0).(hProtocol.ContractEventsPage), a.Error(1)
+}
+
+// PrevContractEventsPage is a mocking method
+func (m *MockClient) PrevContractEventsPage(page hProtocol.ContractEventsPage) (hProtocol.ContractEventsPage, error) {
+	a := m.Called(page)
+	return a.Get(0).(hProtocol.ContractEventsPage), a.Error(1)
+}
+
+// StreamContractEvents is a mocking method
+func (m *MockClient) StreamContractEvents(ctx context.Context, request ContractEventsRequest, handler ContractEventHandler) error {
+	return m.Called(ctx, request, handler).Error(0)
+}
+
+// ContractData is a mocking method
+func (m *MockClient) ContractData(request ContractDataRequest) (hProtocol.ContractDataPage, error) {
+	a := m.Called(request)
+	return a.Get(0).(hProtocol.ContractDataPage), a.Error(1)
+}
+
+// NextContractDataPage is a mocking method
+func (m *MockClient) NextContractDataPage(page hProtocol.ContractDataPage) (hProtocol.ContractDataPage, error) {
+	a := m.Called(page)
+	return a.Get(0).(hProtocol.ContractDataPage), a.Error(1)
+}
+
+// PrevContractDataPage is a mocking method
+func (m *MockClient) PrevContractDataPage(page hProtocol.ContractDataPage) (hProtocol.ContractDataPage, error) {
+	a := m.Called(page)
+	return a.Get(0).(hProtocol.ContractDataPage), a.Error(1)
+}
+
+// StreamContractData is a mocking method
+func (m *MockClient) StreamContractData(ctx context.Context, request ContractDataRequest, handler ContractDataHandler) error {
+	return m.Called(ctx, request, handler).Error(0)
+}
+
 func (m *MockAdminClient) GetIngestionAccountFilter() (hProtocol.AccountFilterConfig, error) {
 	a := m.Called()
 	return a.Get(0).(hProtocol.AccountFilterConfig), a.Error(1)
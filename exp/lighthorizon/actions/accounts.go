@@ -2,6 +2,7 @@ package actions
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"strconv"
@@ -12,6 +13,7 @@ import (
 	"github.com/hcnet/go/exp/lightaurora/adapters"
 	"github.com/hcnet/go/exp/lightaurora/services"
 	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/protocols/aurora/effects"
 	"github.com/hcnet/go/protocols/aurora/operations"
 	"github.com/hcnet/go/support/render/hal"
 	supportProblem "github.com/hcnet/go/support/render/problem"
@@ -140,3 +142,94 @@ func NewOpsByAccountHandler(lightAurora services.LightAurora) func(http.Response
 		sendPageResponse(r.Context(), w, page)
 	}
 }
+
+func NewPaymentsByAccountHandler(lightAurora services.LightAurora) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var accountId string
+		var paginate pagination
+		var err error
+
+		if accountId, paginate, err = accountRequestParams(w, r); err != nil {
+			errorMsg := supportProblem.MakeInvalidFieldProblem("account_id", err)
+			sendErrorResponse(r.Context(), w, *errorMsg)
+			return
+		}
+
+		page := hal.Page{
+			Cursor: strconv.FormatInt(paginate.Cursor, 10),
+			Order:  string(paginate.Order),
+			Limit:  uint64(paginate.Limit),
+		}
+		page.Init()
+		page.FullURL = r.URL
+
+		ops, err := lightAurora.Operations.GetPaymentsByAccount(ctx, paginate.Cursor, paginate.Limit, accountId)
+		if err != nil {
+			log.Error(err)
+			sendErrorResponse(r.Context(), w, supportProblem.ServerError)
+			return
+		}
+
+		for _, op := range ops {
+			var response operations.Operation
+			response, err = adapters.PopulateOperation(r, &op)
+			if err != nil {
+				log.Error(err)
+				sendErrorResponse(r.Context(), w, supportProblem.ServerError)
+				return
+			}
+
+			page.Add(response)
+		}
+
+		page.PopulateLinks()
+		sendPageResponse(r.Context(), w, page)
+	}
+}
+
+func NewEffectsByAccountHandler(lightAurora services.LightAurora) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var accountId string
+		var paginate pagination
+		var err error
+
+		if accountId, paginate, err = accountRequestParams(w, r); err != nil {
+			errorMsg := supportProblem.MakeInvalidFieldProblem("account_id", err)
+			sendErrorResponse(r.Context(), w, *errorMsg)
+			return
+		}
+
+		page := hal.Page{
+			Cursor: fmt.Sprintf("%d-%d", paginate.Cursor, paginate.CursorOrder),
+			Order:  string(paginate.Order),
+			Limit:  uint64(paginate.Limit),
+		}
+		page.Init()
+		page.FullURL = r.URL
+
+		accountEffects, err := lightAurora.Effects.GetEffectsByAccount(ctx,
+			paginate.Cursor, paginate.CursorOrder, paginate.Limit, accountId)
+		if err != nil {
+			log.Error(err)
+			sendErrorResponse(r.Context(), w, supportProblem.ServerError)
+			return
+		}
+
+		for _, effect := range accountEffects {
+			var response effects.Effect
+			response, err = adapters.PopulateEffect(r, &effect)
+			if err != nil {
+				log.Error(err)
+				sendErrorResponse(r.Context(), w, supportProblem.ServerError)
+				return
+			}
+
+			page.Add(response)
+		}
+
+		page.PopulateLinks()
+		sendPageResponse(r.Context(), w, page)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -17,7 +18,9 @@ import (
 
 	"github.com/hcnet/go/exp/lightaurora/common"
 	"github.com/hcnet/go/exp/lightaurora/services"
+	"github.com/hcnet/go/protocols/aurora/effects"
 	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
 )
 
 func setupTest() {
@@ -189,3 +192,65 @@ func buildHttpRequest(
 	ctx := context.WithValue(context.Background(), chi.RouteCtxKey, chiRouteContext)
 	return request.WithContext(ctx)
 }
+
+func TestEffectsByAccount(t *testing.T) {
+	setupTest()
+	recorder := httptest.NewRecorder()
+	accountId := "GDCXSQPVE45DVGT2ZRFFIIHSJ2EJED65W6AELGWIDRMPMWNXCEBJ4FKX"
+	request := buildHttpRequest(
+		t,
+		map[string]string{"cursor": "6812212104400897-1", "limit": "5"},
+		map[string]string{"account_id": accountId},
+	)
+
+	op := &common.Operation{
+		LedgerHeader: &xdr.LedgerHeader{LedgerSeq: 1586113},
+		TxIndex:      2,
+	}
+	mockEffectService := &services.MockEffectService{}
+	mockEffectService.
+		On("GetEffectsByAccount", mock.Anything, int64(6812212104400897), uint32(1), uint64(5), accountId).
+		Return([]common.Effect{{
+			Operation: op,
+			Order:     1,
+			Type:      effects.EffectSequenceBumped,
+			Account:   xdr.MustMuxedAddress(accountId),
+			Details:   map[string]interface{}{"new_seq": xdr.SequenceNumber(34)},
+		}}, nil)
+
+	handler := NewEffectsByAccountHandler(services.LightAurora{Effects: mockEffectService})
+	handler(recorder, request)
+
+	resp := recorder.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var page struct {
+		Embedded struct {
+			Records []effects.SequenceBumped `json:"records"`
+		} `json:"_embedded"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+	require.Len(t, page.Embedded.Records, 1)
+
+	effect := page.Embedded.Records[0]
+	assert.Equal(t, fmt.Sprintf("%019d-%010d", op.TOID(), 1), effect.ID)
+	assert.Equal(t, fmt.Sprintf("%d-1", op.TOID()), effect.PT)
+	assert.Equal(t, accountId, effect.Account)
+	assert.Equal(t, "sequence_bumped", effect.Type)
+	assert.Equal(t, int64(34), effect.NewSeq)
+}
+
+func TestEffectsByAccountInvalidCursor(t *testing.T) {
+	setupTest()
+	recorder := httptest.NewRecorder()
+	request := buildHttpRequest(
+		t,
+		map[string]string{"cursor": "123-abc"},
+		map[string]string{"account_id": "G1234"},
+	)
+
+	handler := NewEffectsByAccountHandler(services.LightAurora{Effects: &services.MockEffectService{}})
+	handler(recorder, request)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Result().StatusCode)
+}
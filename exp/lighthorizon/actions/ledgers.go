@@ -0,0 +1,58 @@
+package actions
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/hcnet/go/exp/lightaurora/adapters"
+	"github.com/hcnet/go/exp/lightaurora/services"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+	supportProblem "github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+)
+
+const (
+	urlLedgerId = "ledger_id"
+)
+
+func NewLedgerBySequenceHandler(lightAurora services.LightAurora) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ledgerId, ok := getURLParam(r, urlLedgerId)
+		if !ok {
+			errorMsg := supportProblem.MakeInvalidFieldProblem(urlLedgerId,
+				errors.New("unable to find ledger_id in url path"))
+			sendErrorResponse(r.Context(), w, *errorMsg)
+			return
+		}
+
+		sequence, err := strconv.ParseUint(ledgerId, 10, 32)
+		if err != nil || sequence == 0 {
+			errorMsg := supportProblem.MakeInvalidFieldProblem(urlLedgerId,
+				errors.New("ledger_id must be a positive ledger sequence"))
+			sendErrorResponse(r.Context(), w, *errorMsg)
+			return
+		}
+
+		ledger, err := lightAurora.Ledgers.GetLedgerBySequence(r.Context(), uint32(sequence))
+		if err != nil {
+			log.Error(err)
+			if os.IsNotExist(errors.Cause(err)) {
+				sendErrorResponse(r.Context(), w, supportProblem.NotFound)
+			} else {
+				sendErrorResponse(r.Context(), w, supportProblem.ServerError)
+			}
+			return
+		}
+
+		response, err := adapters.PopulateLedger(r.URL, &ledger, xdr.NewEncodingBuffer())
+		if err != nil {
+			log.Error(err)
+			sendErrorResponse(r.Context(), w, supportProblem.ServerError)
+			return
+		}
+
+		sendResponse(r.Context(), w, response)
+	}
+}
@@ -0,0 +1,95 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/exp/lightaurora/common"
+	"github.com/hcnet/go/exp/lightaurora/services"
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/toid"
+	"github.com/hcnet/go/xdr"
+)
+
+func TestLedgerBySequence(t *testing.T) {
+	setupTest()
+	recorder := httptest.NewRecorder()
+	request := buildHttpRequest(t, map[string]string{}, map[string]string{"ledger_id": "1586113"})
+
+	mockLedgerService := &services.MockLedgerService{}
+	mockLedgerService.On("GetLedgerBySequence", mock.Anything, uint32(1586113)).
+		Return(common.Ledger{
+			Header: xdr.LedgerHeaderHistoryEntry{
+				Hash: xdr.Hash{1},
+				Header: xdr.LedgerHeader{
+					LedgerVersion: 19,
+					LedgerSeq:     1586113,
+					TotalCoins:    1000000000,
+					BaseFee:       100,
+					BaseReserve:   5000000,
+					MaxTxSetSize:  1000,
+				},
+			},
+			SuccessfulTransactionCount: 2,
+			FailedTransactionCount:     1,
+			OperationCount:             3,
+			TxSetOperationCount:        4,
+		}, nil)
+
+	handler := NewLedgerBySequenceHandler(services.LightAurora{Ledgers: mockLedgerService})
+	handler(recorder, request)
+
+	resp := recorder.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var ledger hProtocol.Ledger
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ledger))
+	assert.Equal(t, "0100000000000000000000000000000000000000000000000000000000000000", ledger.Hash)
+	assert.Equal(t, int32(1586113), ledger.Sequence)
+	assert.Equal(t, strconv.FormatInt(toid.New(1586113, 0, 0).ToInt64(), 10), ledger.PT)
+	assert.Equal(t, int32(2), ledger.SuccessfulTransactionCount)
+	assert.Equal(t, int32(1), *ledger.FailedTransactionCount)
+	assert.Equal(t, int32(3), ledger.OperationCount)
+	assert.Equal(t, int32(4), *ledger.TxSetOperationCount)
+	assert.Equal(t, "100.0000000", ledger.TotalCoins)
+	assert.Equal(t, int32(19), ledger.ProtocolVersion)
+	assert.NotEmpty(t, ledger.HeaderXDR)
+}
+
+func TestLedgerBySequenceErrors(t *testing.T) {
+	setupTest()
+
+	for _, testCase := range []struct {
+		name     string
+		ledgerId string
+		err      error
+		status   int
+	}{
+		{"invalid sequence", "abc", nil, http.StatusBadRequest},
+		{"zero sequence", "0", nil, http.StatusBadRequest},
+		{"not found", "10", os.ErrNotExist, http.StatusNotFound},
+		{"server error", "10", assert.AnError, http.StatusInternalServerError},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := buildHttpRequest(t, map[string]string{}, map[string]string{"ledger_id": testCase.ledgerId})
+
+			mockLedgerService := &services.MockLedgerService{}
+			mockLedgerService.On("GetLedgerBySequence", mock.Anything, uint32(10)).
+				Return(common.Ledger{}, testCase.err)
+
+			handler := NewLedgerBySequenceHandler(services.LightAurora{Ledgers: mockLedgerService})
+			handler(recorder, request)
+
+			assert.Equal(t, testCase.status, recorder.Result().StatusCode)
+		})
+	}
+}
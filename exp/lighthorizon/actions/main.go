@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi"
 	"github.com/prometheus/client_golang/prometheus"
@@ -45,7 +46,10 @@ const (
 type pagination struct {
 	Limit  uint64
 	Cursor int64
-	Order  order
+	// CursorOrder is the position within the cursor's operation of the last
+	// effect seen, when paging through effects.
+	CursorOrder uint32
+	Order       order
 }
 
 func sendPageResponse(ctx context.Context, w http.ResponseWriter, page hal.Page) {
@@ -59,6 +63,17 @@ func sendPageResponse(ctx context.Context, w http.ResponseWriter, page hal.Page)
 	}
 }
 
+func sendResponse(ctx context.Context, w http.ResponseWriter, resource interface{}) {
+	w.Header().Set("Content-Type", "application/hal+json; charset=utf-8")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(resource)
+	if err != nil {
+		log.Error(err)
+		sendErrorResponse(ctx, w, supportProblem.ServerError)
+	}
+}
+
 func sendErrorResponse(ctx context.Context, w http.ResponseWriter, problem supportProblem.P) {
 	supportProblem.Render(ctx, w, problem)
 }
@@ -79,10 +94,19 @@ func paging(r *http.Request) (pagination, error) {
 	if cursorRequested, err := requestUnaryParam(r, "cursor"); err != nil {
 		return pagination{}, err
 	} else if cursorRequested != "" {
-		paginate.Cursor, err = strconv.ParseInt(cursorRequested, 10, 64)
+		// Effect cursors are formatted as "<operation id>-<order>".
+		cursor, cursorOrder, hasOrder := strings.Cut(cursorRequested, "-")
+		paginate.Cursor, err = strconv.ParseInt(cursor, 10, 64)
 		if err != nil {
 			return pagination{}, err
 		}
+		if hasOrder {
+			effectOrder, err := strconv.ParseUint(cursorOrder, 10, 32)
+			if err != nil {
+				return pagination{}, err
+			}
+			paginate.CursorOrder = uint32(effectOrder)
+		}
 	}
 
 	if limitRequested, err := requestUnaryParam(r, "limit"); err != nil {
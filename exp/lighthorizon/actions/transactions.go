@@ -0,0 +1,57 @@
+package actions
+
+import (
+	"encoding/hex"
+	"net/http"
+	"os"
+
+	"github.com/hcnet/go/exp/lightaurora/adapters"
+	"github.com/hcnet/go/exp/lightaurora/services"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+	supportProblem "github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+)
+
+const (
+	urlTransactionId = "tx_id"
+)
+
+func NewTransactionByHashHandler(lightAurora services.LightAurora) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hash, ok := getURLParam(r, urlTransactionId)
+		if !ok {
+			errorMsg := supportProblem.MakeInvalidFieldProblem(urlTransactionId,
+				errors.New("unable to find tx_id in url path"))
+			sendErrorResponse(r.Context(), w, *errorMsg)
+			return
+		}
+
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != 32 {
+			errorMsg := supportProblem.MakeInvalidFieldProblem(urlTransactionId,
+				errors.New("tx_id must be a hex-encoded transaction hash"))
+			sendErrorResponse(r.Context(), w, *errorMsg)
+			return
+		}
+
+		tx, err := lightAurora.Transactions.GetTransactionByHash(r.Context(), hash)
+		if err != nil {
+			log.Error(err)
+			if os.IsNotExist(errors.Cause(err)) {
+				sendErrorResponse(r.Context(), w, supportProblem.NotFound)
+			} else {
+				sendErrorResponse(r.Context(), w, supportProblem.ServerError)
+			}
+			return
+		}
+
+		response, err := adapters.PopulateTransaction(r.URL, &tx, xdr.NewEncodingBuffer())
+		if err != nil {
+			log.Error(err)
+			sendErrorResponse(r.Context(), w, supportProblem.ServerError)
+			return
+		}
+
+		sendResponse(r.Context(), w, response)
+	}
+}
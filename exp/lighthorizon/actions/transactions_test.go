@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hcnet/go/exp/lightaurora/common"
+	"github.com/hcnet/go/exp/lightaurora/services"
+	"github.com/hcnet/go/support/errors"
+)
+
+func TestTransactionByHashErrors(t *testing.T) {
+	setupTest()
+	hash := "55d8aa3693489ffc1d70b8ba33b8b5c012ec098f6f104383e3f090048488febd"
+
+	for _, testCase := range []struct {
+		name   string
+		txId   string
+		err    error
+		status int
+	}{
+		{"not hex", "xyz", nil, http.StatusBadRequest},
+		{"too short", hash[:62], nil, http.StatusBadRequest},
+		{"not found", hash, errors.Wrap(os.ErrNotExist, "lookup"), http.StatusNotFound},
+		{"server error", hash, assert.AnError, http.StatusInternalServerError},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := buildHttpRequest(t, map[string]string{}, map[string]string{"tx_id": testCase.txId})
+
+			mockTransactionService := &services.MockTransactionService{}
+			mockTransactionService.On("GetTransactionByHash", mock.Anything, hash).
+				Return(common.Transaction{}, testCase.err)
+
+			handler := NewTransactionByHashHandler(services.LightAurora{Transactions: mockTransactionService})
+			handler(recorder, request)
+
+			assert.Equal(t, testCase.status, recorder.Result().StatusCode)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hcnet/go/exp/lightaurora/common"
+	"github.com/hcnet/go/protocols/aurora/effects"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/hal"
+)
+
+var stringDetails = map[effects.EffectType][]string{
+	effects.EffectSequenceBumped: {"new_seq"},
+	effects.EffectTrade:          {"seller_muxed_id"},
+}
+
+// PopulateEffect converts an effect into its Aurora resource. The details of
+// the effect are merged into the base attributes and decoded into the
+// resource type matching the effect, so the response is shaped exactly like
+// Aurora's.
+func PopulateEffect(r *http.Request, effect *common.Effect) (effects.Effect, error) {
+	typeName, ok := effects.EffectTypeNames[effect.Type]
+	if !ok {
+		return nil, errors.Errorf("unknown effect type %d", effect.Type)
+	}
+
+	base := effects.Base{
+		ID:              effect.ID(),
+		PT:              effect.PagingToken(),
+		Account:         effect.Account.ToAccountId().Address(),
+		Type:            typeName,
+		TypeI:           int32(effect.Type),
+		LedgerCloseTime: time.Unix(int64(effect.Operation.LedgerHeader.ScpValue.CloseTime), 0).UTC(),
+	}
+	if muxed, ok := effect.Account.GetMed25519(); ok {
+		base.AccountMuxed = effect.Account.Address()
+		base.AccountMuxedID = uint64(muxed.Id)
+	}
+
+	lb := hal.LinkBuilder{Base: r.URL}
+	base.Links.Operation = lb.Linkf("/operations/%d", effect.Operation.TOID())
+	base.Links.Succeeds = lb.Linkf("/effects?order=desc&cursor=%s", base.PT)
+	base.Links.Precedes = lb.Linkf("/effects?order=asc&cursor=%s", base.PT)
+
+	baseJSON, err := json.Marshal(base)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal effect")
+	}
+	attributes := map[string]interface{}{}
+	if err = json.Unmarshal(baseJSON, &attributes); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal effect")
+	}
+	for key, value := range effect.Details {
+		attributes[key] = value
+	}
+	// The details are shaped like the ones stored by Aurora, whose resources
+	// encode these integers as strings.
+	for _, key := range stringDetails[effect.Type] {
+		if value, ok := attributes[key]; ok {
+			attributes[key] = fmt.Sprintf("%d", value)
+		}
+	}
+
+	effectJSON, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal effect details")
+	}
+	return effects.UnmarshalEffect(typeName, effectJSON)
+}
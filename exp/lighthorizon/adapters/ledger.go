@@ -0,0 +1,58 @@
+package adapters
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hcnet/go/amount"
+	"github.com/hcnet/go/exp/lightaurora/common"
+	protocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/render/hal"
+	"github.com/hcnet/go/xdr"
+)
+
+// PopulateLedger converts a ledger header read from the archive into the
+// Aurora ledger resource.
+func PopulateLedger(
+	baseUrl *url.URL,
+	ledger *common.Ledger,
+	encoder *xdr.EncodingBuffer,
+) (dest protocol.Ledger, err error) {
+	header := ledger.Header.Header
+
+	dest.ID = ledger.Header.Hash.HexString()
+	dest.PT = strconv.FormatInt(ledger.TOID(), 10)
+	dest.Hash = dest.ID
+	dest.PrevHash = header.PreviousLedgerHash.HexString()
+	dest.Sequence = int32(header.LedgerSeq)
+	dest.SuccessfulTransactionCount = ledger.SuccessfulTransactionCount
+	failedTransactionCount := ledger.FailedTransactionCount
+	dest.FailedTransactionCount = &failedTransactionCount
+	dest.OperationCount = ledger.OperationCount
+	txSetOperationCount := ledger.TxSetOperationCount
+	dest.TxSetOperationCount = &txSetOperationCount
+	dest.ClosedAt = time.Unix(int64(header.ScpValue.CloseTime), 0).UTC()
+	dest.TotalCoins = amount.String(header.TotalCoins)
+	dest.FeePool = amount.String(header.FeePool)
+	dest.BaseFee = int32(header.BaseFee)
+	dest.BaseReserve = int32(header.BaseReserve)
+	dest.MaxTxSetSize = int32(header.MaxTxSetSize)
+	dest.ProtocolVersion = int32(header.LedgerVersion)
+
+	dest.HeaderXDR, err = encoder.MarshalBase64(&header)
+	if err != nil {
+		return
+	}
+
+	self := fmt.Sprintf("/ledgers/%d", header.LedgerSeq)
+	lb := hal.LinkBuilder{Base: baseUrl}
+	dest.Links.Self = lb.Link(self)
+	dest.Links.Transactions = lb.PagedLink(self, "transactions")
+	dest.Links.Operations = lb.PagedLink(self, "operations")
+	dest.Links.Payments = lb.PagedLink(self, "payments")
+	dest.Links.Effects = lb.PagedLink(self, "effects")
+
+	return
+}
@@ -0,0 +1,33 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/hcnet/go/protocols/aurora/effects"
+	"github.com/hcnet/go/xdr"
+)
+
+// Effect is a change an operation made to a single account.
+type Effect struct {
+	Operation *Operation
+	// Order is the 1-based position of the effect within the effects of its
+	// operation.
+	Order   uint32
+	Type    effects.EffectType
+	Account xdr.MuxedAccount
+	// Details holds the attributes specific to the effect type, keyed and
+	// encoded as in the JSON of the corresponding protocols/aurora/effects
+	// resource.
+	Details map[string]interface{}
+}
+
+// ID returns a lexically ordered id for this effect, in the same format as
+// Aurora.
+func (e *Effect) ID() string {
+	return fmt.Sprintf("%019d-%010d", e.Operation.TOID(), e.Order)
+}
+
+// PagingToken returns a cursor for this effect, in the same format as Aurora.
+func (e *Effect) PagingToken() string {
+	return fmt.Sprintf("%d-%d", e.Operation.TOID(), e.Order)
+}
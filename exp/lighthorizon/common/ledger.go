@@ -0,0 +1,23 @@
+package common
+
+import (
+	"github.com/hcnet/go/toid"
+	"github.com/hcnet/go/xdr"
+)
+
+// Ledger is a closed ledger along with the statistics of its transaction set,
+// which Aurora keeps in its history_ledgers table.
+type Ledger struct {
+	Header xdr.LedgerHeaderHistoryEntry
+
+	SuccessfulTransactionCount int32
+	FailedTransactionCount     int32
+	// OperationCount only counts the operations of successful transactions,
+	// while TxSetOperationCount counts all of them.
+	OperationCount      int32
+	TxSetOperationCount int32
+}
+
+func (l *Ledger) TOID() int64 {
+	return toid.New(int32(l.Header.Header.LedgerSeq), 0, 0).ToInt64()
+}
@@ -60,8 +60,13 @@ func lightAuroraHTTPHandler(registry *prometheus.Registry, lightAurora services.
 	router.Route("/accounts/{account_id}", func(r chi.Router) {
 		r.MethodFunc(http.MethodGet, "/transactions", actions.NewTXByAccountHandler(lightAurora))
 		r.MethodFunc(http.MethodGet, "/operations", actions.NewOpsByAccountHandler(lightAurora))
+		r.MethodFunc(http.MethodGet, "/payments", actions.NewPaymentsByAccountHandler(lightAurora))
+		r.MethodFunc(http.MethodGet, "/effects", actions.NewEffectsByAccountHandler(lightAurora))
 	})
 
+	router.MethodFunc(http.MethodGet, "/ledgers/{ledger_id}", actions.NewLedgerBySequenceHandler(lightAurora))
+	router.MethodFunc(http.MethodGet, "/transactions/{tx_id}", actions.NewTransactionByHashHandler(lightAurora))
+
 	router.MethodFunc(http.MethodGet, "/", actions.Root(actions.RootResponse{
 		Version: AuroraLiteVersion,
 		// by default, no other fields are known yet
@@ -50,6 +50,17 @@ func TestRootResponse(t *testing.T) {
 	require.Equal(t, AuroraLiteVersion, root.Version)
 }
 
+func TestInvalidPathParams(t *testing.T) {
+	for _, path := range []string{"/ledgers/latest", "/transactions/1234"} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", path, nil)
+		require.NoError(t, err)
+
+		prepareTestHttpHandler().ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusBadRequest, recorder.Result().StatusCode, path)
+	}
+}
+
 func prepareTestHttpHandler() http.Handler {
 	mockOperationService := &services.MockOperationService{}
 	mockTransactionService := &services.MockTransactionService{}
@@ -58,6 +69,8 @@ func prepareTestHttpHandler() http.Handler {
 	lh := services.LightAurora{
 		Operations:   mockOperationService,
 		Transactions: mockTransactionService,
+		Effects:      &services.MockEffectService{},
+		Ledgers:      &services.MockLedgerService{},
 	}
 
 	return lightAuroraHTTPHandler(registry, lh)
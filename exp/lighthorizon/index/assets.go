@@ -0,0 +1,282 @@
+package index
+
+import (
+	"fmt"
+
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/xdr"
+)
+
+// The asset and liquidity pool indexes live in the same store as the account
+// indexes. Their keys can't be confused with account IDs: asset keys are
+// either "native" or "<code>-<issuer>" and liquidity pool keys are the
+// lowercase hex-encoded pool IDs.
+
+// AssetKey returns the key of the indexes of the given asset.
+func AssetKey(asset xdr.Asset) (string, error) {
+	var assetType, code, issuer string
+	if err := asset.Extract(&assetType, &code, &issuer); err != nil {
+		return "", err
+	}
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		return "native", nil
+	}
+	return code + "-" + issuer, nil
+}
+
+// LiquidityPoolKey returns the key of the indexes of the given liquidity pool.
+func LiquidityPoolKey(poolId xdr.PoolId) string {
+	return xdr.Hash(poolId).HexString()
+}
+
+func ProcessAssetsByCheckpoint(
+	indexStore Store,
+	ledger xdr.LedgerCloseMeta,
+	tx ingest.LedgerTransaction,
+) error {
+	return processKeys(indexStore.AddParticipantsToIndexes, ledger, tx, assetIndexes)
+}
+
+func ProcessAssetsByCheckpointWithoutBackend(
+	indexStore Store,
+	ledger xdr.LedgerCloseMeta,
+	tx ingest.LedgerTransaction,
+) error {
+	return processKeys(indexStore.AddParticipantsToIndexesNoBackend, ledger, tx, assetIndexes)
+}
+
+func ProcessLiquidityPoolsByCheckpoint(
+	indexStore Store,
+	ledger xdr.LedgerCloseMeta,
+	tx ingest.LedgerTransaction,
+) error {
+	return processKeys(indexStore.AddParticipantsToIndexes, ledger, tx, liquidityPoolIndexes)
+}
+
+func ProcessLiquidityPoolsByCheckpointWithoutBackend(
+	indexStore Store,
+	ledger xdr.LedgerCloseMeta,
+	tx ingest.LedgerTransaction,
+) error {
+	return processKeys(indexStore.AddParticipantsToIndexesNoBackend, ledger, tx, liquidityPoolIndexes)
+}
+
+// operationKeysFunc returns the keys an operation should be indexed under.
+type operationKeysFunc func(ingest.LedgerTransaction, xdr.Operation, int) ([]string, error)
+
+type keyIndex struct {
+	name    string
+	getKeys operationKeysFunc
+}
+
+var (
+	assetIndexes = []keyIndex{
+		{"all/payments", GetOperationPaymentAssets},
+		{"all/trades", GetOperationTradeAssets},
+	}
+	liquidityPoolIndexes = []keyIndex{
+		{"all/all", GetOperationLiquidityPools},
+		{"all/trades", GetOperationTradeLiquidityPools},
+	}
+)
+
+func processKeys(
+	addToIndexes func(checkpoint uint32, index string, participants []string) error,
+	ledger xdr.LedgerCloseMeta,
+	tx ingest.LedgerTransaction,
+	indexes []keyIndex,
+) error {
+	checkpoint := getIndex(ledger, ByCheckpoint)
+
+	for _, index := range indexes {
+		keys, err := keysForOperations(tx, index.getKeys)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		if err := addToIndexes(checkpoint, index.name, keys); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func keysForOperations(transaction ingest.LedgerTransaction, getKeys operationKeysFunc) ([]string, error) {
+	var keys []string
+	seen := map[string]struct{}{}
+
+	for opIndex, operation := range transaction.Envelope.Operations() {
+		opKeys, err := getKeys(transaction, operation, opIndex)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range opKeys {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// GetOperationPaymentAssets returns the keys of the assets moved by the
+// operation if it's a payment (see GetPaymentParticipants), or no keys
+// otherwise.
+//
+// transaction - the ledger transaction
+// operation   - the operation within this transaction
+// opIndex     - the 0 based index of the operation within the transaction
+func GetOperationPaymentAssets(transaction ingest.LedgerTransaction, operation xdr.Operation, opIndex int) ([]string, error) {
+	var assets []xdr.Asset
+
+	switch operation.Body.Type {
+	case xdr.OperationTypeCreateAccount, xdr.OperationTypeAccountMerge:
+		assets = append(assets, xdr.MustNewNativeAsset())
+	case xdr.OperationTypePayment:
+		assets = append(assets, operation.Body.MustPaymentOp().Asset)
+	case xdr.OperationTypePathPaymentStrictReceive:
+		op := operation.Body.MustPathPaymentStrictReceiveOp()
+		assets = append(assets, op.SendAsset, op.DestAsset)
+	case xdr.OperationTypePathPaymentStrictSend:
+		op := operation.Body.MustPathPaymentStrictSendOp()
+		assets = append(assets, op.SendAsset, op.DestAsset)
+	}
+
+	return assetKeys(assets...)
+}
+
+// GetOperationTradeAssets returns the keys of the assets traded by the
+// operation, both against offers and liquidity pools.
+//
+// transaction - the ledger transaction
+// operation   - the operation within this transaction
+// opIndex     - the 0 based index of the operation within the transaction
+func GetOperationTradeAssets(transaction ingest.LedgerTransaction, operation xdr.Operation, opIndex int) ([]string, error) {
+	claims, err := operationClaims(transaction, opIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []xdr.Asset
+	for _, claim := range claims {
+		assets = append(assets, claim.AssetSold(), claim.AssetBought())
+	}
+
+	return assetKeys(assets...)
+}
+
+// GetOperationLiquidityPools returns the keys of the liquidity pools the
+// operation deposited into, withdrew from, traded with or trusted.
+//
+// transaction - the ledger transaction
+// operation   - the operation within this transaction
+// opIndex     - the 0 based index of the operation within the transaction
+func GetOperationLiquidityPools(transaction ingest.LedgerTransaction, operation xdr.Operation, opIndex int) ([]string, error) {
+	pools := []string{}
+
+	switch operation.Body.Type {
+	case xdr.OperationTypeLiquidityPoolDeposit:
+		pools = append(pools, LiquidityPoolKey(operation.Body.MustLiquidityPoolDepositOp().LiquidityPoolId))
+	case xdr.OperationTypeLiquidityPoolWithdraw:
+		pools = append(pools, LiquidityPoolKey(operation.Body.MustLiquidityPoolWithdrawOp().LiquidityPoolId))
+	case xdr.OperationTypeChangeTrust:
+		line := operation.Body.MustChangeTrustOp().Line
+		if line.Type == xdr.AssetTypeAssetTypePoolShare {
+			cp := line.LiquidityPool.MustConstantProduct()
+			poolId, err := xdr.NewPoolId(cp.AssetA, cp.AssetB, cp.Fee)
+			if err != nil {
+				return nil, err
+			}
+			pools = append(pools, LiquidityPoolKey(poolId))
+		}
+	}
+
+	tradePools, err := GetOperationTradeLiquidityPools(transaction, operation, opIndex)
+	if err != nil {
+		return nil, err
+	}
+	return append(pools, tradePools...), nil
+}
+
+// GetOperationTradeLiquidityPools returns the keys of the liquidity pools the
+// operation traded with.
+//
+// transaction - the ledger transaction
+// operation   - the operation within this transaction
+// opIndex     - the 0 based index of the operation within the transaction
+func GetOperationTradeLiquidityPools(transaction ingest.LedgerTransaction, operation xdr.Operation, opIndex int) ([]string, error) {
+	claims, err := operationClaims(transaction, opIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	pools := []string{}
+	for _, claim := range claims {
+		if claim.Type == xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool {
+			pools = append(pools, LiquidityPoolKey(claim.LiquidityPool.LiquidityPoolId))
+		}
+	}
+	return pools, nil
+}
+
+// operationClaims returns the offers and liquidity pools the operation traded
+// with, which only successful transactions have.
+func operationClaims(transaction ingest.LedgerTransaction, opIndex int) ([]xdr.ClaimAtom, error) {
+	if !transaction.Result.Successful() {
+		return nil, nil
+	}
+
+	results, ok := transaction.Result.OperationResults()
+	if !ok || opIndex >= len(results) {
+		return nil, fmt.Errorf("missing result of operation %d", opIndex)
+	}
+
+	result, ok := results[opIndex].GetTr()
+	if !ok {
+		return nil, nil
+	}
+
+	switch result.Type {
+	case xdr.OperationTypeManageSellOffer:
+		// hcnet-core also uses this arm for CreatePassiveSellOffer results
+		if success, ok := result.MustManageSellOfferResult().GetSuccess(); ok {
+			return success.OffersClaimed, nil
+		}
+	case xdr.OperationTypeManageBuyOffer:
+		if success, ok := result.MustManageBuyOfferResult().GetSuccess(); ok {
+			return success.OffersClaimed, nil
+		}
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		if success, ok := result.MustCreatePassiveSellOfferResult().GetSuccess(); ok {
+			return success.OffersClaimed, nil
+		}
+	case xdr.OperationTypePathPaymentStrictReceive:
+		if success, ok := result.MustPathPaymentStrictReceiveResult().GetSuccess(); ok {
+			return success.Offers, nil
+		}
+	case xdr.OperationTypePathPaymentStrictSend:
+		if success, ok := result.MustPathPaymentStrictSendResult().GetSuccess(); ok {
+			return success.Offers, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func assetKeys(assets ...xdr.Asset) ([]string, error) {
+	keys := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		key, err := AssetKey(asset)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -0,0 +1,121 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/xdr"
+)
+
+func TestAssetKey(t *testing.T) {
+	key, err := AssetKey(xdr.MustNewNativeAsset())
+	require.NoError(t, err)
+	assert.Equal(t, "native", key)
+
+	issuer := "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+	key, err = AssetKey(xdr.MustNewCreditAsset("USD", issuer))
+	require.NoError(t, err)
+	assert.Equal(t, "USD-"+issuer, key)
+}
+
+func TestLiquidityPoolKeys(t *testing.T) {
+	source := xdr.MustMuxedAddress("GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU")
+	usd := xdr.MustNewCreditAsset("USD", source.Address())
+	tradedPool := xdr.PoolId{1}
+	depositPool := xdr.PoolId{2}
+
+	operations := []xdr.Operation{
+		{
+			Body: xdr.OperationBody{
+				Type: xdr.OperationTypePathPaymentStrictSend,
+				PathPaymentStrictSendOp: &xdr.PathPaymentStrictSendOp{
+					SendAsset:   xdr.MustNewNativeAsset(),
+					SendAmount:  10,
+					Destination: source,
+					DestAsset:   usd,
+					DestMin:     1,
+				},
+			},
+		},
+		{
+			Body: xdr.OperationBody{
+				Type: xdr.OperationTypeLiquidityPoolDeposit,
+				LiquidityPoolDepositOp: &xdr.LiquidityPoolDepositOp{
+					LiquidityPoolId: depositPool,
+				},
+			},
+		},
+	}
+	results := []xdr.OperationResult{
+		{
+			Code: xdr.OperationResultCodeOpInner,
+			Tr: &xdr.OperationResultTr{
+				Type: xdr.OperationTypePathPaymentStrictSend,
+				PathPaymentStrictSendResult: &xdr.PathPaymentStrictSendResult{
+					Code: xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSuccess,
+					Success: &xdr.PathPaymentStrictSendResultSuccess{
+						Offers: []xdr.ClaimAtom{{
+							Type: xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool,
+							LiquidityPool: &xdr.ClaimLiquidityAtom{
+								LiquidityPoolId: tradedPool,
+								AssetSold:       usd,
+								AmountSold:      5,
+								AssetBought:     xdr.MustNewNativeAsset(),
+								AmountBought:    10,
+							},
+						}},
+					},
+				},
+			},
+		},
+		{
+			Code: xdr.OperationResultCodeOpInner,
+			Tr: &xdr.OperationResultTr{
+				Type: xdr.OperationTypeLiquidityPoolDeposit,
+				LiquidityPoolDepositResult: &xdr.LiquidityPoolDepositResult{
+					Code: xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositSuccess,
+				},
+			},
+		},
+	}
+	tx := ingest.LedgerTransaction{
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: source,
+					Operations:    operations,
+				},
+			},
+		},
+		Result: xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{
+					Code:    xdr.TransactionResultCodeTxSuccess,
+					Results: &results,
+				},
+			},
+		},
+	}
+
+	pools, err := keysForOperations(tx, GetOperationLiquidityPools)
+	require.NoError(t, err)
+	assert.Equal(t, []string{LiquidityPoolKey(tradedPool), LiquidityPoolKey(depositPool)}, pools)
+
+	pools, err = keysForOperations(tx, GetOperationTradeLiquidityPools)
+	require.NoError(t, err)
+	assert.Equal(t, []string{LiquidityPoolKey(tradedPool)}, pools)
+
+	assets, err := keysForOperations(tx, GetOperationTradeAssets)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"native", "USD-" + source.Address()}, assets)
+
+	// failed transactions don't trade
+	tx.Result.Result.Result.Code = xdr.TransactionResultCodeTxFailed
+	pools, err = keysForOperations(tx, GetOperationLiquidityPools)
+	require.NoError(t, err)
+	assert.Equal(t, []string{LiquidityPoolKey(depositPool)}, pools)
+}
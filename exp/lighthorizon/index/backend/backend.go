@@ -11,4 +11,7 @@ type Backend interface {
 	ReadAccounts() ([]string, error)
 	FlushTransactions(map[string]*types.TrieIndex) error
 	ReadTransactions(prefix string) (*types.TrieIndex, error)
+	// Compact moves the indices of every key into a single pack, see
+	// FileBackend.Compact.
+	Compact() (CompactStats, error)
 }
@@ -3,10 +3,15 @@ package index
 import (
 	"bufio"
 	"compress/gzip"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	types "github.com/hcnet/go/exp/lightaurora/index/types"
 
@@ -18,6 +23,17 @@ import (
 type FileBackend struct {
 	dir      string
 	parallel uint32
+
+	// packs are the compacted indices under dir/packs, oldest first (see
+	// Compact). A key's own file always takes precedence over its packed
+	// indices, since it's only (re)written after reading them.
+	packMutex sync.RWMutex
+	packs     []*filePack
+}
+
+type filePack struct {
+	*Pack
+	file *os.File
 }
 
 // NewFileBackend connects to indices stored at `dir`, creating the directory if one doesn't
@@ -33,10 +49,49 @@ func NewFileBackend(dir string, parallel uint32) (*FileBackend, error) {
 		return nil, err
 	}
 
-	return &FileBackend{
+	backend := &FileBackend{
 		dir:      dir,
 		parallel: parallel,
-	}, nil
+	}
+	if err := backend.loadPacks(); err != nil {
+		return nil, err
+	}
+	return backend, nil
+}
+
+func (s *FileBackend) loadPacks() error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "packs", "*.pack"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths) // names are timestamps, so this sorts by age
+
+	for _, path := range paths {
+		pack, err := openFilePack(path)
+		if err != nil {
+			return err
+		}
+		s.packs = append(s.packs, pack)
+	}
+	return nil
+}
+
+func openFilePack(path string) (*filePack, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	pack, err := OpenPack(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "unable to open pack %s", path)
+	}
+	return &filePack{Pack: pack, file: f}, nil
 }
 
 func (s *FileBackend) Flush(indexes map[string]types.NamedIndices) error {
@@ -135,7 +190,9 @@ func (s *FileBackend) FlushTransactions(indexes map[string]*types.TrieIndex) err
 func (s *FileBackend) Read(account string) (types.NamedIndices, error) {
 	log.Debugf("Opening index: %s", account)
 	b, err := os.Open(filepath.Join(s.dir, account[:3], account))
-	if err != nil {
+	if os.IsNotExist(err) {
+		return s.readPacked(account)
+	} else if err != nil {
 		return nil, err
 	}
 	defer b.Close()
@@ -148,6 +205,147 @@ func (s *FileBackend) Read(account string) (types.NamedIndices, error) {
 	return indexes, nil
 }
 
+// readPacked reads the indices of the key from the newest pack containing it.
+func (s *FileBackend) readPacked(key string) (types.NamedIndices, error) {
+	s.packMutex.RLock()
+	defer s.packMutex.RUnlock()
+
+	for i := len(s.packs) - 1; i >= 0; i-- {
+		if s.packs[i].Contains(key) {
+			return s.packs[i].Read(key)
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+// Compact moves the indices of every key into a single new pack, replacing
+// the per-key files and any older packs. It must not run concurrently with
+// flushes to the same directory, since indices flushed in the meantime could
+// be removed before making it into the pack.
+func (s *FileBackend) Compact() (CompactStats, error) {
+	looseKeys, err := s.looseKeys()
+	if err != nil {
+		return CompactStats{}, err
+	}
+
+	s.packMutex.RLock()
+	oldPacks := s.packs
+	s.packMutex.RUnlock()
+
+	allKeys := set.NewSet[string](len(looseKeys))
+	allKeys.AddSlice(looseKeys)
+	for _, pack := range oldPacks {
+		allKeys.AddSlice(pack.Keys())
+	}
+	keys := allKeys.Slice()
+	sort.Strings(keys)
+
+	dir := filepath.Join(s.dir, "packs")
+	if err := os.MkdirAll(dir, fs.ModeDir|0755); err != nil {
+		return CompactStats{}, errors.Wrapf(err, "unable to mkdir %s", dir)
+	}
+
+	// Write to a temporary file first so a failed compaction never leaves a
+	// partial pack behind for NewFileBackend to load.
+	tmp, err := os.CreateTemp(dir, "*.pack.tmp")
+	if err != nil {
+		return CompactStats{}, errors.Wrap(err, "unable to create pack")
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	stats, err := Compact(s, keys, w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return stats, errors.Wrap(err, "unable to write pack")
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%020d.pack", time.Now().UnixNano()))
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return stats, errors.Wrap(err, "unable to save pack")
+	}
+
+	pack, err := openFilePack(path)
+	if err != nil {
+		return stats, err
+	}
+
+	// Make sure everything made it into the pack before removing the
+	// originals.
+	if len(pack.Keys()) != stats.Keys {
+		pack.file.Close()
+		return stats, fmt.Errorf("pack %s has %d keys, expected %d",
+			path, len(pack.Keys()), stats.Keys)
+	}
+	for _, key := range pack.Keys() {
+		if _, err := pack.Read(key); err != nil {
+			pack.file.Close()
+			return stats, err
+		}
+	}
+
+	s.packMutex.Lock()
+	s.packs = []*filePack{pack}
+	s.packMutex.Unlock()
+
+	for _, old := range oldPacks {
+		old.file.Close()
+		if err := os.Remove(old.file.Name()); err != nil {
+			log.Warnf("Unable to remove old pack %s: %v", old.file.Name(), err)
+		}
+	}
+
+	for _, key := range looseKeys {
+		path := filepath.Join(s.dir, key[:3], key)
+		if err := os.Remove(path); err != nil {
+			log.Warnf("Unable to remove %s: %v", path, err)
+		}
+	}
+	for _, key := range looseKeys {
+		os.Remove(filepath.Join(s.dir, key[:3])) // only succeeds once empty
+	}
+
+	return stats, nil
+}
+
+// looseKeys lists the keys which have their own file, i.e. which were
+// flushed since the last compaction.
+func (s *FileBackend) looseKeys() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to list %s", s.dir)
+	}
+
+	keys := []string{}
+	for _, entry := range entries {
+		// Key files are grouped in directories named after their first three
+		// characters, which can't be confused with "tx" or "packs".
+		if !entry.IsDir() || len(entry.Name()) != 3 {
+			continue
+		}
+
+		dir := filepath.Join(s.dir, entry.Name())
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to list %s", dir)
+		}
+		for _, file := range files {
+			if !file.IsDir() && strings.HasPrefix(file.Name(), entry.Name()) {
+				keys = append(keys, file.Name())
+			}
+		}
+	}
+	return keys, nil
+}
+
 func (s *FileBackend) ReadAccounts() ([]string, error) {
 	path := filepath.Join(s.dir, "accounts")
 	log.Debugf("Opening accounts list at %s", path)
@@ -0,0 +1,290 @@
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	types "github.com/hcnet/go/exp/lightaurora/index/types"
+	"github.com/hcnet/go/support/errors"
+)
+
+// A pack bundles the indices of many keys (accounts, assets, pools) into a
+// single file so that stores don't need one tiny object per key. It looks like
+//
+//	[gzipped indices of key 1] ... [gzipped indices of key N] [table] [footer]
+//
+// where each entry is exactly what would've been stored as the key's own file
+// (see writeGzippedTo). The table maps each key to the offset and length of
+// its entry and is sorted by key:
+//
+//	uvarint(N) { uvarint(len(key)) key uvarint(offset) uvarint(length) }*N
+//
+// and the footer is the big-endian uint64 offset of the table followed by
+// packMagic.
+const (
+	packMagic      = "LHIPACK1"
+	packFooterSize = 8 + len(packMagic)
+)
+
+// ErrInvalidPack is returned when opening something that isn't a pack.
+var ErrInvalidPack = errors.New("invalid index pack")
+
+type packEntry struct {
+	offset uint64
+	length uint64
+}
+
+// PackWriter writes a pack of indices to an underlying writer. Keys can only
+// be added once and the pack is only usable after Close.
+type PackWriter struct {
+	w      *countingWriter
+	keys   []string
+	table  map[string]packEntry
+	closed bool
+}
+
+func NewPackWriter(w io.Writer) *PackWriter {
+	return &PackWriter{
+		w:     &countingWriter{w: w},
+		table: map[string]packEntry{},
+	}
+}
+
+// Add writes the indices of the given key to the pack.
+func (p *PackWriter) Add(key string, indexes types.NamedIndices) error {
+	if p.closed {
+		return errors.New("pack is closed")
+	}
+	if _, ok := p.table[key]; ok {
+		return fmt.Errorf("duplicate key in pack: %s", key)
+	}
+	if len(indexes) == 0 {
+		return fmt.Errorf("no indices for %s", key)
+	}
+
+	offset := p.w.n
+	if _, err := writeGzippedTo(p.w, indexes); err != nil {
+		return errors.Wrapf(err, "unable to serialize %s", key)
+	}
+
+	p.table[key] = packEntry{offset: offset, length: p.w.n - offset}
+	p.keys = append(p.keys, key)
+	return nil
+}
+
+// Len returns the number of keys added to the pack so far.
+func (p *PackWriter) Len() int {
+	return len(p.keys)
+}
+
+// Close writes the offset table and footer. It doesn't close the underlying
+// writer.
+func (p *PackWriter) Close() error {
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	sort.Strings(p.keys)
+	tableOffset := p.w.n
+
+	var table bytes.Buffer
+	table.Write(binary.AppendUvarint(nil, uint64(len(p.keys))))
+	for _, key := range p.keys {
+		entry := p.table[key]
+		table.Write(binary.AppendUvarint(nil, uint64(len(key))))
+		table.WriteString(key)
+		table.Write(binary.AppendUvarint(nil, entry.offset))
+		table.Write(binary.AppendUvarint(nil, entry.length))
+	}
+
+	footer := binary.BigEndian.AppendUint64(nil, tableOffset)
+	table.Write(append(footer, packMagic...))
+
+	_, err := p.w.Write(table.Bytes())
+	return errors.Wrap(err, "unable to write pack table")
+}
+
+// Pack reads the indices stored in a pack.
+type Pack struct {
+	r     io.ReaderAt
+	keys  []string
+	table map[string]packEntry
+}
+
+// OpenPack reads the offset table of the pack in `r`, which is `size` bytes
+// long. Entries are only read on demand.
+func OpenPack(r io.ReaderAt, size int64) (*Pack, error) {
+	if size < int64(packFooterSize) {
+		return nil, ErrInvalidPack
+	}
+
+	footer := make([]byte, packFooterSize)
+	if _, err := r.ReadAt(footer, size-int64(packFooterSize)); err != nil {
+		return nil, errors.Wrap(err, "unable to read pack footer")
+	}
+	if string(footer[8:]) != packMagic {
+		return nil, ErrInvalidPack
+	}
+
+	tableOffset := binary.BigEndian.Uint64(footer[:8])
+	tableEnd := uint64(size) - uint64(packFooterSize)
+	if tableOffset > tableEnd {
+		return nil, ErrInvalidPack
+	}
+
+	// The table is read in one go since every read may be a request to a
+	// remote store.
+	buf := make([]byte, tableEnd-tableOffset)
+	if err := readFullAt(r, buf, int64(tableOffset)); err != nil {
+		return nil, errors.Wrap(err, "unable to read pack table")
+	}
+	table := bytes.NewReader(buf)
+
+	count, err := binary.ReadUvarint(table)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read pack table")
+	} else if count > uint64(len(buf)) {
+		return nil, ErrInvalidPack
+	}
+
+	pack := &Pack{
+		r:     r,
+		keys:  make([]string, 0, count),
+		table: make(map[string]packEntry, count),
+	}
+	for i := uint64(0); i < count; i++ {
+		keyLen, err := binary.ReadUvarint(table)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read pack table")
+		}
+		key := make([]byte, keyLen)
+		if _, err := io.ReadFull(table, key); err != nil {
+			return nil, errors.Wrap(err, "unable to read pack table")
+		}
+
+		var entry packEntry
+		if entry.offset, err = binary.ReadUvarint(table); err != nil {
+			return nil, errors.Wrap(err, "unable to read pack table")
+		}
+		if entry.length, err = binary.ReadUvarint(table); err != nil {
+			return nil, errors.Wrap(err, "unable to read pack table")
+		}
+		if entry.offset+entry.length > tableOffset {
+			return nil, errors.Wrapf(ErrInvalidPack, "entry for %s out of bounds", key)
+		}
+
+		pack.keys = append(pack.keys, string(key))
+		pack.table[string(key)] = entry
+	}
+
+	return pack, nil
+}
+
+// Keys returns the keys in the pack, sorted.
+func (p *Pack) Keys() []string {
+	return p.keys
+}
+
+// Contains returns whether the pack has indices for the given key.
+func (p *Pack) Contains(key string) bool {
+	_, ok := p.table[key]
+	return ok
+}
+
+// Read returns the indices of the given key, or os.ErrNotExist if the key
+// isn't in the pack.
+func (p *Pack) Read(key string) (types.NamedIndices, error) {
+	entry, ok := p.table[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	buf := make([]byte, entry.length)
+	if err := readFullAt(p.r, buf, int64(entry.offset)); err != nil {
+		return nil, errors.Wrapf(err, "unable to read %s from pack", key)
+	}
+	indexes, _, err := readGzippedFrom(bytes.NewReader(buf))
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse %s from pack", key)
+	}
+	return indexes, nil
+}
+
+// CompactStats summarizes a compaction.
+type CompactStats struct {
+	// Keys is how many keys were written to the pack.
+	Keys int
+	// Missing is how many of the requested keys had no indices.
+	Missing int
+	// Bytes is the size of the resulting pack.
+	Bytes int64
+}
+
+// IndexReader is anything indices can be read from, like a Backend or a
+// Pack.
+type IndexReader interface {
+	Read(key string) (types.NamedIndices, error)
+}
+
+// Compact reads the indices of each key from `src` and writes them as a
+// single pack to `w`. Keys without indices are skipped.
+func Compact(src IndexReader, keys []string, w io.Writer) (CompactStats, error) {
+	stats := CompactStats{}
+	pack := NewPackWriter(w)
+
+	for _, key := range keys {
+		indexes, err := src.Read(key)
+		if os.IsNotExist(err) {
+			stats.Missing++
+			continue
+		} else if err != nil {
+			return stats, errors.Wrapf(err, "unable to read %s", key)
+		}
+
+		if len(indexes) == 0 {
+			stats.Missing++
+			continue
+		}
+
+		if err := pack.Add(key, indexes); err != nil {
+			return stats, err
+		}
+	}
+
+	if err := pack.Close(); err != nil {
+		return stats, err
+	}
+
+	stats.Keys = pack.Len()
+	stats.Bytes = int64(pack.w.n)
+	return stats, nil
+}
+
+// readFullAt fills buf from r at the given offset. Unlike ReadAt, it doesn't
+// fail when the read ends right at EOF.
+func readFullAt(r io.ReaderAt, buf []byte, offset int64) error {
+	n, err := r.ReadAt(buf, offset)
+	if n == len(buf) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	return n, err
+}
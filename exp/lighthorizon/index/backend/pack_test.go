@@ -0,0 +1,209 @@
+package index
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	types "github.com/hcnet/go/exp/lightaurora/index/types"
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/support/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndices(t *testing.T, checkpoints ...uint32) types.NamedIndices {
+	all := &types.BitmapIndex{}
+	payments := &types.BitmapIndex{}
+	for i, checkpoint := range checkpoints {
+		require.NoError(t, all.SetActive(checkpoint))
+		if i%2 == 0 {
+			require.NoError(t, payments.SetActive(checkpoint))
+		}
+	}
+	return types.NamedIndices{"all/all": all, "all/payments": payments}
+}
+
+func requireIndicesEqual(t *testing.T, expected, actual types.NamedIndices) {
+	require.Len(t, actual, len(expected))
+	for name, index := range expected {
+		require.Contains(t, actual, name)
+		require.Equal(t, index.Flush(), actual[name].Flush(), "index %s differs", name)
+	}
+}
+
+func TestPackRoundtrip(t *testing.T) {
+	indices := map[string]types.NamedIndices{
+		keypair.MustRandom().Address(): newTestIndices(t, 1, 5, 70),
+		keypair.MustRandom().Address(): newTestIndices(t, 12345),
+		"native":                       newTestIndices(t, 3, 4),
+	}
+
+	var buf bytes.Buffer
+	pack := NewPackWriter(&buf)
+	for key, named := range indices {
+		require.NoError(t, pack.Add(key, named))
+	}
+	require.Error(t, pack.Add("native", indices["native"]))
+	require.Error(t, pack.Add("empty", types.NamedIndices{}))
+	require.NoError(t, pack.Close())
+
+	read, err := OpenPack(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, read.Keys(), len(indices))
+	require.IsIncreasing(t, read.Keys())
+
+	for key, named := range indices {
+		require.True(t, read.Contains(key))
+		found, err := read.Read(key)
+		require.NoError(t, err)
+		requireIndicesEqual(t, named, found)
+	}
+
+	_, err = read.Read("missing")
+	require.True(t, os.IsNotExist(err))
+
+	// Anything but a complete pack is rejected.
+	truncated := buf.Bytes()[:buf.Len()-1]
+	_, err = OpenPack(bytes.NewReader(truncated), int64(len(truncated)))
+	require.ErrorIs(t, err, ErrInvalidPack)
+}
+
+func TestFileBackendCompact(t *testing.T) {
+	tmpDir := t.TempDir()
+	backend, err := NewFileBackend(tmpDir, 2)
+	require.NoError(t, err)
+
+	indices := map[string]types.NamedIndices{}
+	for i := uint32(1); i <= 20; i++ {
+		indices[keypair.MustRandom().Address()] = newTestIndices(t, i, 2*i, 100+i)
+	}
+	indices["native"] = newTestIndices(t, 1, 2, 3)
+	require.NoError(t, backend.Flush(indices))
+
+	stats, err := backend.Compact()
+	require.NoError(t, err)
+	require.Equal(t, len(indices), stats.Keys)
+	require.Zero(t, stats.Missing)
+
+	// Only the pack remains, but everything can still be read from it.
+	loose, err := backend.looseKeys()
+	require.NoError(t, err)
+	require.Empty(t, loose)
+
+	packs, err := filepath.Glob(filepath.Join(tmpDir, "packs", "*"))
+	require.NoError(t, err)
+	require.Len(t, packs, 1)
+	info, err := os.Stat(packs[0])
+	require.NoError(t, err)
+	require.EqualValues(t, stats.Bytes, info.Size())
+
+	for key, named := range indices {
+		found, err := backend.Read(key)
+		require.NoError(t, err)
+		requireIndicesEqual(t, named, found)
+	}
+	_, err = backend.Read("GMISSING")
+	require.True(t, os.IsNotExist(err))
+
+	// Flushing after compaction supersedes the packed indices.
+	updated := newTestIndices(t, 9999)
+	require.NoError(t, backend.Flush(map[string]types.NamedIndices{"native": updated}))
+
+	reopened, err := NewFileBackend(tmpDir, 1)
+	require.NoError(t, err)
+	found, err := reopened.Read("native")
+	require.NoError(t, err)
+	requireIndicesEqual(t, updated, found)
+
+	// Compacting again folds the old pack and the new file into one pack.
+	stats, err = reopened.Compact()
+	require.NoError(t, err)
+	require.Equal(t, len(indices), stats.Keys)
+
+	packs, err = filepath.Glob(filepath.Join(tmpDir, "packs", "*"))
+	require.NoError(t, err)
+	require.Len(t, packs, 1)
+
+	found, err = reopened.Read("native")
+	require.NoError(t, err)
+	requireIndicesEqual(t, updated, found)
+
+	checkpoint, err := found["all/all"].NextActiveBit(0)
+	require.NoError(t, err)
+	require.EqualValues(t, 9999, checkpoint)
+	_, err = found["all/all"].NextActiveBit(checkpoint + 1)
+	require.Equal(t, io.EOF, err)
+}
+
+// rangeStorage counts the ranged reads made from a storage.
+type rangeStorage struct {
+	*storage.Filesystem
+	ranges int
+}
+
+func (s *rangeStorage) GetFileRange(path string, offset, length int64) (io.ReadCloser, error) {
+	s.ranges++
+	return s.Filesystem.GetFileRange(path, offset, length)
+}
+
+func TestStorageBackendCompact(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := &rangeStorage{Filesystem: storage.NewFilesystemStorage(tmpDir).(*storage.Filesystem)}
+	backend := NewStorageBackend(files, "indices", 2)
+
+	indices := map[string]types.NamedIndices{}
+	for i := uint32(1); i <= 20; i++ {
+		indices[keypair.MustRandom().Address()] = newTestIndices(t, i, 2*i, 100+i)
+	}
+	indices["native"] = newTestIndices(t, 1, 2, 3)
+	require.NoError(t, backend.Flush(indices))
+	require.NoError(t, backend.FlushAccounts([]string{"native"}))
+
+	stats, err := backend.Compact()
+	require.NoError(t, err)
+	require.Equal(t, len(indices), stats.Keys)
+	require.Zero(t, stats.Missing)
+
+	// Only the pack and the accounts list remain, and keys are read from the
+	// pack with ranged reads.
+	var remaining []string
+	require.NoError(t, filepath.Walk(tmpDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(tmpDir, path)
+			remaining = append(remaining, rel)
+		}
+		return err
+	}))
+	require.Len(t, remaining, 2)
+	require.Equal(t, filepath.Join("indices", "accounts"), remaining[0])
+	require.Regexp(t, `^indices/packs/\d{20}\.pack$`, remaining[1])
+
+	reopened := NewStorageBackend(files, "indices", 1)
+	for key, named := range indices {
+		ranges := files.ranges
+		found, err := reopened.Read(key)
+		require.NoError(t, err)
+		requireIndicesEqual(t, named, found)
+		require.Greater(t, files.ranges, ranges)
+	}
+	_, err = reopened.Read("GMISSING")
+	require.True(t, os.IsNotExist(err))
+
+	// Flushing after compaction supersedes the packed indices, and compacting
+	// again folds them into a single pack.
+	updated := newTestIndices(t, 9999)
+	require.NoError(t, reopened.Flush(map[string]types.NamedIndices{"native": updated}))
+	stats, err = reopened.Compact()
+	require.NoError(t, err)
+	require.Equal(t, len(indices), stats.Keys)
+
+	packs, err := filepath.Glob(filepath.Join(tmpDir, "indices", "packs", "*"))
+	require.NoError(t, err)
+	require.Len(t, packs, 1)
+
+	found, err := NewStorageBackend(files, "indices", 1).Read("native")
+	require.NoError(t, err)
+	requireIndicesEqual(t, updated, found)
+}
@@ -0,0 +1,233 @@
+package index
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	types "github.com/hcnet/go/exp/lightaurora/index/types"
+
+	"github.com/hcnet/go/support/collections/set"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+)
+
+// packObjects are the operations a remote store needs to support to keep
+// packs (see remotePacks). Paths are relative to the root of the store, like
+// the ones used for reading and flushing indices.
+type packObjects interface {
+	// list returns the paths of the objects under dir. Some stores prefix
+	// them with their own root, so only their last elements are reliable.
+	list(dir string) ([]string, error)
+	size(path string) (int64, error)
+	readRange(path string, offset, length int64) (io.ReadCloser, error)
+	put(path string, body io.ReadSeeker) error
+	remove(paths []string) error
+}
+
+// objectReaderAt reads an object with one ranged request per ReadAt.
+type objectReaderAt struct {
+	objects packObjects
+	path    string
+}
+
+func (o *objectReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	body, err := o.objects.readRange(o.path, offset, int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	return io.ReadFull(body, p)
+}
+
+// remotePacks are the packs of a remote store, stored under dir just like the
+// FileBackend stores them under dir/packs. They're listed on first use, so
+// packs written by other processes afterwards aren't seen.
+type remotePacks struct {
+	objects packObjects
+	dir     string
+
+	mutex  sync.Mutex
+	loaded bool
+	packs  []*remotePack // oldest first
+}
+
+type remotePack struct {
+	*Pack
+	path string
+}
+
+func newRemotePacks(objects packObjects, dir string) *remotePacks {
+	return &remotePacks{objects: objects, dir: dir}
+}
+
+func (r *remotePacks) load() ([]*remotePack, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.loaded {
+		return r.packs, nil
+	}
+
+	listed, err := r.objects.list(r.dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to list packs in %s", r.dir)
+	}
+	var names []string
+	for _, path := range listed {
+		if name := filepath.Base(path); strings.HasSuffix(name, ".pack") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names) // names are timestamps, so this sorts by age
+
+	var packs []*remotePack
+	for _, name := range names {
+		pack, err := r.open(filepath.Join(r.dir, name))
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, pack)
+	}
+
+	r.packs = packs
+	r.loaded = true
+	return packs, nil
+}
+
+func (r *remotePacks) open(path string) (*remotePack, error) {
+	size, err := r.objects.size(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open pack %s", path)
+	}
+	pack, err := OpenPack(&objectReaderAt{objects: r.objects, path: path}, size)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open pack %s", path)
+	}
+	return &remotePack{Pack: pack, path: path}, nil
+}
+
+// read reads the indices of the key from the newest pack containing it.
+func (r *remotePacks) read(key string) (types.NamedIndices, error) {
+	packs, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(packs) - 1; i >= 0; i-- {
+		if packs[i].Contains(key) {
+			return packs[i].Read(key)
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+// compact works like FileBackend.Compact: the indices of every key are read
+// from `src` into a new pack, which is only uploaded once it has been checked
+// locally. Then the older packs and the objects of `looseKeys`, found at
+// `loosePath(key)`, are removed.
+func (r *remotePacks) compact(src IndexReader, looseKeys []string, loosePath func(key string) string) (CompactStats, error) {
+	oldPacks, err := r.load()
+	if err != nil {
+		return CompactStats{}, err
+	}
+
+	allKeys := set.NewSet[string](len(looseKeys))
+	allKeys.AddSlice(looseKeys)
+	for _, pack := range oldPacks {
+		allKeys.AddSlice(pack.Keys())
+	}
+	keys := allKeys.Slice()
+	sort.Strings(keys)
+
+	tmp, err := os.CreateTemp("", "*.pack")
+	if err != nil {
+		return CompactStats{}, errors.Wrap(err, "unable to create pack")
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w := bufio.NewWriter(tmp)
+	stats, err := Compact(src, keys, w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		return stats, errors.Wrap(err, "unable to write pack")
+	}
+
+	// Make sure everything made it into the pack before uploading it.
+	local, err := OpenPack(tmp, stats.Bytes)
+	if err != nil {
+		return stats, err
+	}
+	if len(local.Keys()) != stats.Keys {
+		return stats, fmt.Errorf("pack has %d keys, expected %d",
+			len(local.Keys()), stats.Keys)
+	}
+	for _, key := range local.Keys() {
+		if _, err := local.Read(key); err != nil {
+			return stats, err
+		}
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return stats, errors.Wrap(err, "unable to upload pack")
+	}
+	path := filepath.Join(r.dir, fmt.Sprintf("%020d.pack", time.Now().UnixNano()))
+	if err := r.objects.put(path, tmp); err != nil {
+		return stats, errors.Wrapf(err, "unable to upload pack %s", path)
+	}
+
+	// ...and before removing the originals, that it was uploaded in full.
+	pack, err := r.open(path)
+	if err != nil {
+		return stats, err
+	}
+	if size, err := r.objects.size(path); err != nil {
+		return stats, errors.Wrapf(err, "unable to check pack %s", path)
+	} else if size != stats.Bytes {
+		return stats, fmt.Errorf("pack %s has %d bytes, expected %d",
+			path, size, stats.Bytes)
+	}
+	if len(pack.Keys()) != stats.Keys {
+		return stats, fmt.Errorf("pack %s has %d keys, expected %d",
+			path, len(pack.Keys()), stats.Keys)
+	}
+
+	r.mutex.Lock()
+	r.packs = []*remotePack{pack}
+	r.mutex.Unlock()
+
+	obsolete := make([]string, 0, len(oldPacks)+len(looseKeys))
+	for _, old := range oldPacks {
+		obsolete = append(obsolete, old.path)
+	}
+	for _, key := range looseKeys {
+		obsolete = append(obsolete, loosePath(key))
+	}
+	if err := r.objects.remove(obsolete); err != nil {
+		log.Warnf("Unable to remove compacted objects: %v", err)
+	}
+
+	return stats, nil
+}
+
+// looseKeysIn returns the keys among the listed paths, i.e. those in a
+// directory named after their first `groupLen` characters.
+func looseKeysIn(paths []string, groupLen int) []string {
+	keys := []string{}
+	for _, path := range paths {
+		key := filepath.Base(path)
+		group := filepath.Base(filepath.Dir(path))
+		if len(group) == groupLen && strings.HasPrefix(key, group) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
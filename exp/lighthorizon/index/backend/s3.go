@@ -3,6 +3,8 @@ package index
 import (
 	"bytes"
 	"compress/gzip"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -21,11 +23,13 @@ import (
 
 type S3Backend struct {
 	s3Session  *session.Session
+	client     *s3.S3
 	downloader *s3manager.Downloader
 	uploader   *s3manager.Uploader
 	parallel   uint32
 	pathPrefix string
 	bucket     string
+	packs      *remotePacks
 }
 
 func NewS3Backend(awsConfig *aws.Config, bucket string, pathPrefix string, parallel uint32) (*S3Backend, error) {
@@ -34,14 +38,17 @@ func NewS3Backend(awsConfig *aws.Config, bucket string, pathPrefix string, paral
 		return nil, err
 	}
 
-	return &S3Backend{
+	backend := &S3Backend{
 		s3Session:  s3Session,
+		client:     s3.New(s3Session),
 		downloader: s3manager.NewDownloader(s3Session),
 		uploader:   s3manager.NewUploader(s3Session),
 		parallel:   parallel,
 		pathPrefix: pathPrefix,
 		bucket:     bucket,
-	}, nil
+	}
+	backend.packs = newRemotePacks(backend, filepath.Join(pathPrefix, "packs"))
+	return backend, nil
 }
 
 func (s *S3Backend) FlushAccounts(accounts []string) error {
@@ -164,7 +171,7 @@ func (s *S3Backend) Read(account string) (types.NamedIndices, error) {
 		})
 		if err != nil {
 			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
-				return nil, os.ErrNotExist
+				return s.packs.read(account)
 			}
 			err = errors.Wrapf(err, "Unable to download %s", account)
 			time.Sleep(100 * time.Millisecond)
@@ -185,6 +192,106 @@ func (s *S3Backend) Read(account string) (types.NamedIndices, error) {
 	return nil, err
 }
 
+// Compact moves the indices of every key into a single new pack under
+// packs/, like FileBackend.Compact does, deleting the per-key objects and any
+// older packs. Packed indices are then read with ranged GETs.
+func (s *S3Backend) Compact() (CompactStats, error) {
+	listed, err := s.list(s.pathPrefix)
+	if err != nil {
+		return CompactStats{}, errors.Wrap(err, "unable to list indices")
+	}
+	return s.packs.compact(s, looseKeysIn(listed, 10), s.path)
+}
+
+func (s *S3Backend) list(dir string) ([]string, error) {
+	prefix := dir
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	var keys []string
+	err := s.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, aws.StringValue(object.Key))
+		}
+		return true
+	})
+	return keys, err
+}
+
+func (s *S3Backend) size(path string) (int64, error) {
+	head, err := s.client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NotFound" {
+			return 0, os.ErrNotExist
+		}
+		return 0, err
+	}
+	return aws.Int64Value(head.ContentLength), nil
+}
+
+func (s *S3Backend) readRange(path string, offset, length int64) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(path),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	return object.Body, nil
+}
+
+func (s *S3Backend) put(path string, body io.ReadSeeker) error {
+	_, err := s.uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(path),
+		Body:   body,
+	})
+	return err
+}
+
+func (s *S3Backend) remove(paths []string) error {
+	var failed []string
+	for len(paths) > 0 {
+		// DeleteObjects takes at most 1000 keys
+		batch := paths
+		if len(batch) > 1000 {
+			batch = batch[:1000]
+		}
+		paths = paths[len(batch):]
+
+		objects := make([]*s3.ObjectIdentifier, 0, len(batch))
+		for _, path := range batch {
+			objects = append(objects, &s3.ObjectIdentifier{Key: aws.String(path)})
+		}
+		out, err := s.client.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &s3.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return err
+		}
+		for _, failure := range out.Errors {
+			failed = append(failed, fmt.Sprintf("%s: %s",
+				aws.StringValue(failure.Key), aws.StringValue(failure.Message)))
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
 func (s *S3Backend) ReadTransactions(prefix string) (*types.TrieIndex, error) {
 	// Check if index exists in S3
 	log.Debugf("Downloading index: %s", prefix)
@@ -0,0 +1,240 @@
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	types "github.com/hcnet/go/exp/lightaurora/index/types"
+
+	"github.com/hcnet/go/support/collections/set"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+	"github.com/hcnet/go/support/storage"
+)
+
+// StorageBackend stores indices in any storage.Storage, ex. a multi://
+// storage replicating them across several buckets. Files are laid out like
+// in the FileBackend so a file store can be copied to any storage.
+type StorageBackend struct {
+	storage    storage.Storage
+	pathPrefix string
+	parallel   uint32
+	packs      *remotePacks
+}
+
+// NewStorageBackend stores indices under `pathPrefix` in `backend` and uses
+// `parallel` to control how many workers to use when flushing.
+func NewStorageBackend(backend storage.Storage, pathPrefix string, parallel uint32) *StorageBackend {
+	if parallel <= 0 {
+		parallel = 1
+	}
+	s := &StorageBackend{
+		storage:    backend,
+		pathPrefix: pathPrefix,
+		parallel:   parallel,
+	}
+	s.packs = newRemotePacks(s, filepath.Join(pathPrefix, "packs"))
+	return s
+}
+
+func (s *StorageBackend) Flush(indexes map[string]types.NamedIndices) error {
+	return parallelFlush(s.parallel, indexes, s.writeBatch)
+}
+
+// FlushAccounts merges the given accounts into the stored account list. Unlike
+// the FileBackend, which appends to the list, the list is rewritten so
+// concurrent flushes from several processes must be avoided.
+func (s *StorageBackend) FlushAccounts(accounts []string) error {
+	existing, err := s.ReadAccounts()
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+		return err
+	}
+
+	accountSet := set.NewSet[string](len(existing) + len(accounts))
+	var buf bytes.Buffer
+	for _, account := range append(existing, accounts...) {
+		if !accountSet.Contains(account) {
+			accountSet.Add(account)
+			buf.WriteString(account + "\n")
+		}
+	}
+
+	if err := s.storage.PutFile(filepath.Join(s.pathPrefix, "accounts"), io.NopCloser(&buf)); err != nil {
+		return errors.Wrap(err, "failed to write accounts list")
+	}
+	return nil
+}
+
+func (s *StorageBackend) writeBatch(b *batch) error {
+	if len(b.indexes) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if _, err := writeGzippedTo(&buf, b.indexes); err != nil {
+		return errors.Wrapf(err, "unable to serialize %s", b.account)
+	}
+
+	if err := s.storage.PutFile(s.path(b.account), io.NopCloser(&buf)); err != nil {
+		return errors.Wrapf(err, "unable to upload %s", b.account)
+	}
+	return nil
+}
+
+func (s *StorageBackend) FlushTransactions(indexes map[string]*types.TrieIndex) error {
+	for key, index := range indexes {
+		path := filepath.Join(s.pathPrefix, "tx", key)
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := index.WriteTo(zw); err != nil {
+			log.Errorf("Unable to serialize %s: %v", path, err)
+			continue
+		}
+		if err := zw.Close(); err != nil {
+			log.Errorf("Unable to serialize %s: %v", path, err)
+			continue
+		}
+
+		if err := s.storage.PutFile(path, io.NopCloser(&buf)); err != nil {
+			log.Errorf("Unable to upload %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+func (s *StorageBackend) Read(account string) (types.NamedIndices, error) {
+	log.Debugf("Opening index: %s", account)
+	b, err := s.storage.GetFile(s.path(account))
+	if os.IsNotExist(errors.Cause(err)) {
+		return s.packs.read(account)
+	} else if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+
+	indexes, _, err := readGzippedFrom(bufio.NewReader(b))
+	if err != nil {
+		log.Errorf("Unable to parse %s: %v", account, err)
+		return nil, os.ErrNotExist
+	}
+	return indexes, nil
+}
+
+func (s *StorageBackend) path(key string) string {
+	return filepath.Join(s.pathPrefix, key[:3], key)
+}
+
+// Compact moves the indices of every key into a single new pack under
+// packs/, like FileBackend.Compact does, removing the per-key files and any
+// older packs. The storage must be able to remove files.
+func (s *StorageBackend) Compact() (CompactStats, error) {
+	if _, ok := s.storage.(storage.FileRemover); !ok {
+		return CompactStats{}, errors.New("storage can't remove files")
+	}
+
+	listed, err := s.list(s.pathPrefix)
+	if err != nil {
+		return CompactStats{}, errors.Wrap(err, "unable to list indices")
+	}
+	return s.packs.compact(s, looseKeysIn(listed, 3), s.path)
+}
+
+func (s *StorageBackend) list(dir string) ([]string, error) {
+	var paths []string
+	files, errs := s.storage.ListFiles(dir)
+	for files != nil || errs != nil {
+		select {
+		case file, ok := <-files:
+			if !ok {
+				files = nil
+				continue
+			}
+			paths = append(paths, file)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return paths, nil
+}
+
+func (s *StorageBackend) size(path string) (int64, error) {
+	return s.storage.Size(path)
+}
+
+func (s *StorageBackend) readRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return storage.GetFileRange(s.storage, path, offset, length)
+}
+
+func (s *StorageBackend) put(path string, body io.ReadSeeker) error {
+	return s.storage.PutFile(path, io.NopCloser(body))
+}
+
+func (s *StorageBackend) remove(paths []string) error {
+	remover := s.storage.(storage.FileRemover)
+	var failed []string
+	for _, path := range paths {
+		if err := remover.RemoveFile(path); err != nil && !os.IsNotExist(errors.Cause(err)) {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func (s *StorageBackend) ReadAccounts() ([]string, error) {
+	log.Debugf("Opening accounts list")
+	b, err := s.storage.GetFile(filepath.Join(s.pathPrefix, "accounts"))
+	if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+
+	body, err := io.ReadAll(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read accounts list")
+	}
+
+	var accounts []string
+	for _, account := range strings.Split(string(body), "\n") {
+		if account != "" {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (s *StorageBackend) ReadTransactions(prefix string) (*types.TrieIndex, error) {
+	log.Debugf("Opening index: %s", prefix)
+	b, err := s.storage.GetFile(filepath.Join(s.pathPrefix, "tx", prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+	zr, err := gzip.NewReader(b)
+	if err != nil {
+		log.Errorf("Unable to parse %s: %v", prefix, err)
+		return nil, os.ErrNotExist
+	}
+	defer zr.Close()
+	var index types.TrieIndex
+	_, err = index.ReadFrom(zr)
+	if err != nil {
+		log.Errorf("Unable to parse %s: %v", prefix, err)
+		return nil, os.ErrNotExist
+	}
+	return &index, nil
+}
@@ -99,6 +99,16 @@ func BuildIndices(
 		case "accounts_by_ledger_unbacked":
 			indexBuilder.RegisterModule(ProcessAccountsByLedgerWithoutBackend)
 			indexStore.ClearMemory(false)
+		case "assets":
+			indexBuilder.RegisterModule(ProcessAssetsByCheckpoint)
+		case "assets_unbacked":
+			indexBuilder.RegisterModule(ProcessAssetsByCheckpointWithoutBackend)
+			indexStore.ClearMemory(false)
+		case "liquidity_pools":
+			indexBuilder.RegisterModule(ProcessLiquidityPoolsByCheckpoint)
+		case "liquidity_pools_unbacked":
+			indexBuilder.RegisterModule(ProcessLiquidityPoolsByCheckpointWithoutBackend)
+			indexStore.ClearMemory(false)
 		default:
 			return indexBuilder, fmt.Errorf("unknown module '%s'", part)
 		}
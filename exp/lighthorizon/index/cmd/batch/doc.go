@@ -19,6 +19,14 @@
 //	                   . - each checkpoint is processed by a free
 //	                       worker (go routine)
 //
+// The MODULES env variable of the map step selects the indexes to build
+// (accounts_unbacked by default). The assets_unbacked and
+// liquidity_pools_unbacked modules index the asset and liquidity pool keys
+// (see index.AssetKey and index.LiquidityPoolKey) which are reduced just like
+// accounts. Since the reduce step merges the map results into the indexes
+// already in the target, history can be indexed incrementally by mapping and
+// reducing only the new checkpoints.
+//
 // reduce step is responsible for merging all indexes created in map step into a
 // final indexes for each account and for entire network history. Each reduce
 // job goes through all map job results (0..MAP_JOBS) and reads all accounts
@@ -238,6 +238,22 @@ func mergeAllIndices(finalIndexStore index.Store, config *ReduceConfig) error {
 						}
 					}
 
+					// Then, fold in the indices already in the target so that
+					// the mapped ledgers extend an existing index rather than
+					// replace it.
+					finalIndices, finalErr := finalIndexStore.Read(account)
+					if finalErr == nil {
+						if finalErr = mergeIndices(mergedIndices, finalIndices); finalErr != nil {
+							accountLog.WithError(finalErr).
+								Errorf("Merge failure for existing index of %s", account)
+							panic(finalErr)
+						}
+					} else if !os.IsNotExist(finalErr) {
+						accountLog.WithError(finalErr).
+							Errorf("Failed to read existing index for %s", account)
+						panic(finalErr)
+					}
+
 					// Finally, save the merged index.
 					finalIndexStore.AddParticipantToIndexesNoBackend(account, mergedIndices)
 
@@ -366,21 +382,24 @@ func (cfg *ReduceConfig) shouldProcessTx(txPrefix byte, routineIndex uint32) boo
 		hashLeft%cfg.Workers == routineIndex
 }
 
-// For every index that exists in `dest`, finds the corresponding index in
-// `source` and merges it into `dest`'s version.
+// For every index that exists in `source`, merges it into `dest`'s version,
+// adding it to `dest` if it's missing there.
+//
+// Indices can be missing from either side: e.g. a map job may only have seen
+// payments of an asset while another one only saw trades of it.
 func mergeIndices(dest, source map[string]*types.BitmapIndex) error {
-	for name, index := range dest {
-		// The source doesn't contain this particular index.
-		//
-		// This probably shouldn't happen, since during the Map step, there's no
-		// way to choose which indices you want, but, strictly-speaking, it's
-		// not an error, so we can just move on.
-		innerIndices, ok := source[name]
-		if !ok || innerIndices == nil {
+	for name, innerIndex := range source {
+		if innerIndex == nil {
+			continue
+		}
+
+		index, ok := dest[name]
+		if !ok || index == nil {
+			dest[name] = innerIndex
 			continue
 		}
 
-		if err := index.Merge(innerIndices); err != nil {
+		if err := index.Merge(innerIndex); err != nil {
 			return errors.Wrapf(err, "failed to merge index for %s", name)
 		}
 	}
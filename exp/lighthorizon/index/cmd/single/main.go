@@ -18,7 +18,7 @@ func main() {
 	networkPassphrase := flag.String("network-passphrase", network.TestNetworkPassphrase, "network passphrase")
 	start := flag.Int("start", 2, "ledger to start at (inclusive, default: 2, the earliest)")
 	end := flag.Int("end", 0, "ledger to end at (inclusive, default: 0, the latest as of start time)")
-	modules := flag.String("modules", "accounts,transactions", "comma-separated list of modules to index (default: all)")
+	modules := flag.String("modules", "accounts,transactions", "comma-separated list of modules to index: accounts, accounts_by_ledger, transactions, assets, liquidity_pools")
 	watch := flag.Bool("watch", false, "whether to watch the `source` for new "+
 		"txmeta files and index them (default: false). "+
 		"note: `-watch` implies a continuous `-end 0` to get to the latest ledger in txmeta files")
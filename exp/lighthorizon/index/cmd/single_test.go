@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -17,6 +18,7 @@ import (
 	"github.com/hcnet/go/network"
 	"github.com/hcnet/go/support/storage"
 	"github.com/hcnet/go/toid"
+	"github.com/hcnet/go/xdr"
 	"github.com/stretchr/testify/require"
 
 	"github.com/hcnet/go/exp/lightaurora/index"
@@ -112,6 +114,144 @@ func testSingleProcess(t *testing.T, ledgerRange historyarchive.Range) {
 	AssertTxsEqual(t, hashes, store)
 }
 
+func TestSingleProcessAssetsAndLiquidityPools(t *testing.T) {
+	firstLedger, lastLedger := GetFixtureLedgerRange(t)
+	tmpDir := filepath.Join("file://", t.TempDir())
+
+	_, err := index.BuildIndices(
+		context.Background(),
+		txmetaSource,
+		tmpDir,
+		network.TestNetworkPassphrase,
+		historyarchive.Range{Low: firstLedger, High: lastLedger},
+		[]string{
+			"assets",
+			"liquidity_pools",
+		},
+		4,
+	)
+	require.NoError(t, err)
+
+	store, err := index.Connect(tmpDir)
+	require.NoError(t, err)
+
+	for _, testCase := range []struct {
+		indexId string
+		getKeys func(ingest.LedgerTransaction, xdr.Operation, int) ([]string, error)
+	}{
+		{"all/payments", index.GetOperationPaymentAssets},
+		{"all/trades", index.GetOperationTradeAssets},
+		{"all/all", index.GetOperationLiquidityPools},
+		{"all/trades", index.GetOperationTradeLiquidityPools},
+	} {
+		expected := IndexKeysInLedgerRange(t, txmetaSource, firstLedger, lastLedger, testCase.getKeys)
+		for key, knownCheckpoints := range expected {
+			require.Equalf(t, knownCheckpoints, activeCheckpoints(t, store, key, testCase.indexId),
+				"incorrect %s checkpoints for %s", testCase.indexId, key)
+		}
+	}
+
+	// The fixtures have payments, at least of the native asset.
+	payments := IndexKeysInLedgerRange(t, txmetaSource, firstLedger, lastLedger,
+		index.GetOperationPaymentAssets)
+	require.Contains(t, payments, "native")
+}
+
+func TestVerifyAndRepair(t *testing.T) {
+	firstLedger, lastLedger := GetFixtureLedgerRange(t)
+	ledgerRange := historyarchive.Range{Low: firstLedger, High: lastLedger}
+	modules := []string{"accounts", "transactions", "assets"}
+
+	tmpDir := t.TempDir()
+	storeUrl := "file://" + tmpDir
+	_, err := index.BuildIndices(context.Background(), txmetaSource, storeUrl,
+		network.TestNetworkPassphrase, ledgerRange, modules, 4)
+	require.NoError(t, err)
+
+	ledgers := newFixtureLedgerBackend(t)
+	config := index.VerifyConfig{
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		Modules:           modules,
+	}
+	verify := func(config index.VerifyConfig) *index.VerifyReport {
+		store, err := index.Connect(storeUrl)
+		require.NoError(t, err)
+		report, err := index.Verify(context.Background(), store, ledgers, ledgerRange, config)
+		require.NoError(t, err)
+		return report
+	}
+
+	report := verify(config)
+	require.True(t, report.OK(), "unexpected gaps: %+v", report.Gaps)
+	require.Len(t, report.Ledgers, int(lastLedger-firstLedger+1))
+
+	sampled := config
+	sampled.SampleSize = 10
+	report = verify(sampled)
+	require.True(t, report.OK())
+	require.Len(t, report.Ledgers, 10)
+	require.IsIncreasing(t, report.Ledgers)
+
+	// Lose the indices of the native asset and the TOIDs of some transactions.
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "nat", "native")))
+	txFiles, err := filepath.Glob(filepath.Join(tmpDir, "tx", "*"))
+	require.NoError(t, err)
+	require.NotEmpty(t, txFiles)
+	require.NoError(t, os.Remove(txFiles[0]))
+
+	report = verify(config)
+	require.False(t, report.OK())
+	require.False(t, report.Repaired)
+	require.NotEmpty(t, report.TransactionGaps)
+	for _, gap := range report.TransactionGaps {
+		require.Zero(t, gap.Actual)
+	}
+
+	payments := IndexKeysInLedgerRange(t, txmetaSource, firstLedger, lastLedger,
+		index.GetOperationPaymentAssets)
+	missing := []uint32{}
+	for _, gap := range report.Gaps {
+		require.Equal(t, "native", gap.Key)
+		if gap.Index == "all/payments" {
+			missing = append(missing, gap.Checkpoint)
+		}
+	}
+	require.Equal(t, payments["native"], missing)
+
+	repairing := config
+	repairing.Repair = true
+	report = verify(repairing)
+	require.True(t, report.Repaired)
+
+	report = verify(config)
+	require.True(t, report.OK(), "unrepaired gaps: %+v", report.Gaps)
+
+	hashes, _ := IndexLedgerRange(t, txmetaSource, firstLedger, lastLedger)
+	store, err := index.Connect(storeUrl)
+	require.NoError(t, err)
+	AssertTxsEqual(t, hashes, store)
+	require.Equal(t, payments["native"], activeCheckpoints(t, store, "native", "all/payments"))
+}
+
+// activeCheckpoints returns all the checkpoints in which the key is active
+// according to its index.
+func activeCheckpoints(t *testing.T, store index.Store, key, indexId string) []uint32 {
+	checkpoints := []uint32{}
+	lastActiveCheckpoint := uint32(0)
+	for {
+		var err error
+		lastActiveCheckpoint, err = store.NextActive(key, indexId, lastActiveCheckpoint)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		checkpoints = append(checkpoints, lastActiveCheckpoint)
+		lastActiveCheckpoint += 1 // hit next active one
+	}
+	return checkpoints
+}
+
 func AssertTxsEqual(t *testing.T, expected map[string]int64, actual index.Store) {
 	for hash, knownTOID := range expected {
 		rawHash, err := hex.DecodeString(hash)
@@ -182,23 +322,74 @@ func IndexLedgerRange(
 	map[string]int64, // map of "tx hash": TOID
 	map[string][]uint32, // map of "account": {checkpoint, checkpoint, ...}
 ) {
-	ctx := context.Background()
-	backend, err := historyarchive.ConnectBackend(
-		txmetaSource,
-		storage.ConnectOptions{
-			Context:  ctx,
-			S3Region: "us-east-1",
-		},
-	)
-	require.NoError(t, err)
+	participation := make(map[string][]uint32)
+	hashes := make(map[string]int64)
 
-	metaArchive := metaarchive.NewMetaArchive(backend)
+	forEachLedgerTransaction(t, txmetaSource, startLedger, endLedger,
+		func(ledgerSeq uint32, tx ingest.LedgerTransaction) {
+			participants, err := index.GetTransactionParticipants(tx)
+			require.NoError(t, err)
+			trackActivity(participation, participants, ledgerSeq)
 
-	ledgerBackend := ledgerbackend.NewHistoryArchiveBackend(metaArchive)
-	defer ledgerBackend.Close()
+			// Track the ledger sequence in which every tx occurred.
+			hash := hex.EncodeToString(tx.Result.TransactionHash[:])
+			hashes[hash] = toid.New(
+				int32(ledgerSeq),
+				int32(tx.Index),
+				0,
+			).ToInt64()
+		})
 
-	participation := make(map[string][]uint32)
-	hashes := make(map[string]int64)
+	return hashes, participation
+}
+
+// IndexKeysInLedgerRange is like IndexLedgerRange, but it tracks the
+// checkpoints in which the keys returned by getKeys for the operations were
+// active, e.g. index.GetOperationPaymentAssets.
+func IndexKeysInLedgerRange(
+	t *testing.T,
+	txmetaSource string,
+	startLedger, endLedger uint32, // inclusive
+	getKeys func(ingest.LedgerTransaction, xdr.Operation, int) ([]string, error),
+) map[string][]uint32 {
+	activity := make(map[string][]uint32)
+
+	forEachLedgerTransaction(t, txmetaSource, startLedger, endLedger,
+		func(ledgerSeq uint32, tx ingest.LedgerTransaction) {
+			for opIndex, op := range tx.Envelope.Operations() {
+				keys, err := getKeys(tx, op, opIndex)
+				require.NoError(t, err)
+				trackActivity(activity, keys, ledgerSeq)
+			}
+		})
+
+	return activity
+}
+
+// trackActivity adds the checkpoint of the ledger to the activity of each
+// key, keeping the lists duplicate-free.
+func trackActivity(activity map[string][]uint32, keys []string, ledgerSeq uint32) {
+	checkpoint := index.GetCheckpointNumber(ledgerSeq)
+	for _, key := range keys {
+		if list, ok := activity[key]; ok {
+			if list[len(list)-1] != checkpoint {
+				activity[key] = append(list, checkpoint)
+			}
+		} else {
+			activity[key] = []uint32{checkpoint}
+		}
+	}
+}
+
+func forEachLedgerTransaction(
+	t *testing.T,
+	txmetaSource string,
+	startLedger, endLedger uint32, // inclusive
+	callback func(ledgerSeq uint32, tx ingest.LedgerTransaction),
+) {
+	ctx := context.Background()
+	ledgerBackend := newFixtureLedgerBackend(t)
+	defer ledgerBackend.Close()
 
 	for ledgerSeq := startLedger; ledgerSeq <= endLedger; ledgerSeq++ {
 		ledger, err := ledgerBackend.GetLedger(ctx, uint32(ledgerSeq))
@@ -216,34 +407,22 @@ func IndexLedgerRange(
 			}
 			require.NoError(t, err)
 
-			participants, err := index.GetTransactionParticipants(tx)
-			require.NoError(t, err)
-
-			for _, participant := range participants {
-				checkpoint := index.GetCheckpointNumber(ledgerSeq)
-
-				// Track the checkpoint in which activity occurred, keeping the
-				// list duplicate-free.
-				if list, ok := participation[participant]; ok {
-					if list[len(list)-1] != checkpoint {
-						participation[participant] = append(list, checkpoint)
-					}
-				} else {
-					participation[participant] = []uint32{checkpoint}
-				}
-			}
-
-			// Track the ledger sequence in which every tx occurred.
-			hash := hex.EncodeToString(tx.Result.TransactionHash[:])
-			hashes[hash] = toid.New(
-				int32(ledger.LedgerSequence()),
-				int32(tx.Index),
-				0,
-			).ToInt64()
+			callback(ledgerSeq, tx)
 		}
 	}
+}
 
-	return hashes, participation
+func newFixtureLedgerBackend(t *testing.T) ledgerbackend.LedgerBackend {
+	backend, err := historyarchive.ConnectBackend(
+		txmetaSource,
+		storage.ConnectOptions{
+			Context:  context.Background(),
+			S3Region: "us-east-1",
+		},
+	)
+	require.NoError(t, err)
+
+	return ledgerbackend.NewHistoryArchiveBackend(metaarchive.NewMetaArchive(backend))
 }
 
 // GetFixtureLedgerRange determines the oldest and latest ledgers w/in the
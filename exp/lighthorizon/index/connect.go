@@ -7,6 +7,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	backend "github.com/hcnet/go/exp/lightaurora/index/backend"
+	"github.com/hcnet/go/support/storage"
 )
 
 func Connect(backendUrl string) (Store, error) {
@@ -45,6 +46,14 @@ func ConnectWithConfig(config StoreConfig) (Store, error) {
 		}
 		return NewFileStore(filepath.Join(parsed.Host, parsed.Path), config)
 
+	case "gcs", "multi":
+		// multi:// urls replicate the indices to all of their backends
+		target, err := storage.ConnectBackend(config.URL, storage.ConnectOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return NewStorageStore(target, config.URLSubPath, config)
+
 	default:
 		return nil, fmt.Errorf("unknown URL scheme: '%s' (from %s)",
 			parsed.Scheme, config.URL)
@@ -66,3 +75,7 @@ func NewS3Store(awsConfig *aws.Config, bucket string, prefix string, indexConfig
 	}
 	return NewStore(backend, indexConfig)
 }
+
+func NewStorageStore(target storage.Storage, prefix string, config StoreConfig) (Store, error) {
+	return NewStore(backend.NewStorageBackend(target, prefix, config.Workers), config)
+}
@@ -2,6 +2,7 @@ package index
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
+	backend "github.com/hcnet/go/exp/lightaurora/index/backend"
 	types "github.com/hcnet/go/exp/lightaurora/index/types"
 	"github.com/stretchr/testify/mock"
 )
@@ -73,6 +74,11 @@ func (m *MockStore) MergeTransactions(prefix string, other *types.TrieIndex) err
 	return args.Error(0)
 }
 
+func (m *MockStore) Compact() (backend.CompactStats, error) {
+	args := m.Called()
+	return args.Get(0).(backend.CompactStats), args.Error(1)
+}
+
 func (m *MockStore) RegisterMetrics(registry *prometheus.Registry) {
 	m.Called(registry)
 }
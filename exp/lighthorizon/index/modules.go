@@ -153,6 +153,17 @@ func GetOperationParticipants(transaction ingest.LedgerTransaction, operation xd
 	return participantsForOperation(transaction, operation, opIndex, false)
 }
 
+// GetPaymentOperationParticipants returns the participants of the operation
+// if it's a payment (see GetPaymentParticipants), or no participants
+// otherwise.
+//
+// transaction - the ledger transaction
+// operation   - the operation within this transaction
+// opIndex     - the 0 based index of the operation within the transaction
+func GetPaymentOperationParticipants(transaction ingest.LedgerTransaction, operation xdr.Operation, opIndex int) ([]string, error) {
+	return participantsForOperation(transaction, operation, opIndex, true)
+}
+
 func participantsForOperations(transaction ingest.LedgerTransaction, onlyPayments bool) ([]string, error) {
 	var participants []string
 
@@ -33,6 +33,9 @@ type Store interface {
 
 	MergeTransactions(prefix string, other *types.TrieIndex) error
 
+	// Compact packs the stored indices in place, see backend.Backend.
+	Compact() (backend.CompactStats, error)
+
 	RegisterMetrics(registry *prometheus.Registry)
 }
 
@@ -102,6 +105,10 @@ func (s *store) Read(account string) (types.NamedIndices, error) {
 	return s.backend.Read(account)
 }
 
+func (s *store) Compact() (backend.CompactStats, error) {
+	return s.backend.Compact()
+}
+
 func (s *store) ReadAccounts() ([]string, error) {
 	return s.backend.ReadAccounts()
 }
@@ -0,0 +1,303 @@
+package index
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/hcnet/go/historyarchive"
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/ingest/ledgerbackend"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// VerifyConfig controls which ledgers Verify checks and what it checks them
+// for.
+type VerifyConfig struct {
+	NetworkPassphrase string
+
+	// Modules are the modules the indices were built with, named like in
+	// BuildIndices. The "_unbacked" variants are checked like the regular
+	// ones since they produce the same indices.
+	Modules []string
+
+	// SampleSize is how many ledgers to pick at random from the range, or 0
+	// to check every ledger.
+	SampleSize uint32
+
+	// Seed seeds the sample, so a run can be reproduced.
+	Seed int64
+
+	// Repair adds whatever is missing from the store and flushes it.
+	Repair bool
+}
+
+// Gap is a checkpoint (or ledger, for the by-ledger account indices) that
+// should be active in the index of a key, but isn't.
+type Gap struct {
+	Key        string
+	Index      string
+	Checkpoint uint32
+	Ledger     uint32 // the first sampled ledger which should've set it
+}
+
+// TransactionGap is a transaction whose TOID is missing from the store
+// (Actual is 0) or is wrong.
+type TransactionGap struct {
+	Hash     string
+	Ledger   uint32
+	Expected int64
+	Actual   int64
+}
+
+type VerifyReport struct {
+	Ledgers         []uint32 // the ledgers that were checked
+	Gaps            []Gap
+	TransactionGaps []TransactionGap
+	Repaired        bool
+}
+
+// OK returns whether the store had everything the sampled ledgers needed.
+func (r *VerifyReport) OK() bool {
+	return len(r.Gaps) == 0 && len(r.TransactionGaps) == 0
+}
+
+var verifiableModules = map[string]Module{
+	"transactions":       ProcessTransaction,
+	"accounts":           ProcessAccountsByCheckpoint,
+	"accounts_by_ledger": ProcessAccountsByLedger,
+	"assets":             ProcessAssetsByCheckpoint,
+	"liquidity_pools":    ProcessLiquidityPoolsByCheckpoint,
+}
+
+// Verify checks the store against a sample of ledgers from the given range: it
+// runs the modules on every transaction of those ledgers and reports whatever
+// they would've added to the store but the store doesn't have.
+//
+// Only missing data is detected, since checking that a bit shouldn't be set
+// would require every ledger of its checkpoint.
+func Verify(
+	ctx context.Context,
+	store Store,
+	ledgers ledgerbackend.LedgerBackend,
+	ledgerRange historyarchive.Range, // inclusive
+	config VerifyConfig,
+) (*VerifyReport, error) {
+	if ledgerRange.Low == 0 || ledgerRange.High < ledgerRange.Low {
+		return nil, fmt.Errorf("invalid ledger range: %s", ledgerRange.String())
+	}
+
+	modules := make([]Module, 0, len(config.Modules))
+	for _, name := range config.Modules {
+		module, ok := verifiableModules[strings.TrimSuffix(name, "_unbacked")]
+		if !ok {
+			return nil, fmt.Errorf("unknown module '%s'", name)
+		}
+		modules = append(modules, module)
+	}
+
+	report := &VerifyReport{
+		Ledgers: sampleLedgers(ledgerRange, config.SampleSize, config.Seed),
+	}
+
+	prepared := ledgerbackend.BoundedRange(
+		report.Ledgers[0], report.Ledgers[len(report.Ledgers)-1])
+	if ok, err := ledgers.IsPrepared(ctx, prepared); err != nil {
+		return nil, errors.Wrap(err, "checking ledger backend failed")
+	} else if !ok {
+		if err := ledgers.PrepareRange(ctx, prepared); err != nil {
+			return nil, errors.Wrap(err, "preparing ledger backend failed")
+		}
+	}
+
+	checked := map[Gap]struct{}{}
+	for _, ledgerSeq := range report.Ledgers {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		expected, err := expectedByLedger(ctx, ledgers, ledgerSeq,
+			config.NetworkPassphrase, modules)
+		if err != nil {
+			return report, err
+		}
+
+		for _, bit := range expected.bits {
+			// Many ledgers share a checkpoint, so don't check bits twice.
+			if _, ok := checked[bit]; ok {
+				continue
+			}
+			checked[bit] = struct{}{}
+
+			next, err := store.NextActive(bit.Key, bit.Index, bit.Checkpoint)
+			if err == nil && next == bit.Checkpoint {
+				continue
+			} else if err != nil && err != io.EOF {
+				return report, errors.Wrapf(err, "reading %s index of %s failed",
+					bit.Index, bit.Key)
+			}
+
+			gap := bit
+			gap.Ledger = ledgerSeq
+			report.Gaps = append(report.Gaps, gap)
+		}
+
+		for _, tx := range expected.txs {
+			actual, err := store.TransactionTOID(tx.hash)
+			if err == nil && actual == tx.toid {
+				continue
+			} else if err != nil && err != io.EOF {
+				return report, errors.Wrapf(err, "reading TOID of %x failed", tx.hash)
+			}
+
+			report.TransactionGaps = append(report.TransactionGaps, TransactionGap{
+				Hash:     hex.EncodeToString(tx.hash[:]),
+				Ledger:   ledgerSeq,
+				Expected: tx.toid,
+				Actual:   actual,
+			})
+		}
+	}
+
+	if !config.Repair || report.OK() {
+		return report, nil
+	}
+
+	if err := repair(store, report); err != nil {
+		return report, err
+	}
+	report.Repaired = true
+	return report, nil
+}
+
+func repair(store Store, report *VerifyReport) error {
+	for _, gap := range report.Gaps {
+		if err := store.AddParticipantsToIndexes(
+			gap.Checkpoint, gap.Index, []string{gap.Key}); err != nil {
+			return errors.Wrapf(err, "repairing %s index of %s failed", gap.Index, gap.Key)
+		}
+	}
+
+	for _, gap := range report.TransactionGaps {
+		var hash [32]byte
+		if _, err := hex.Decode(hash[:], []byte(gap.Hash)); err != nil {
+			return err
+		}
+		if err := store.AddTransactionToIndexes(gap.Expected, hash); err != nil {
+			return errors.Wrapf(err, "repairing TOID of %s failed", gap.Hash)
+		}
+	}
+
+	return errors.Wrap(store.Flush(), "flushing repaired indices failed")
+}
+
+// sampleLedgers picks `size` distinct ledgers from the range at random, or all
+// of them if there aren't more than that, in ascending order.
+func sampleLedgers(ledgerRange historyarchive.Range, size uint32, seed int64) []uint32 {
+	count := ledgerRange.High - ledgerRange.Low + 1
+	if size == 0 || size >= count {
+		size = count
+	}
+
+	picked := make(map[uint32]struct{}, size)
+	sample := make([]uint32, 0, size)
+	if size == count {
+		for ledger := ledgerRange.Low; ledger <= ledgerRange.High; ledger++ {
+			sample = append(sample, ledger)
+		}
+		return sample
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	for uint32(len(sample)) < size {
+		ledger := ledgerRange.Low + uint32(random.Int63n(int64(count)))
+		if _, ok := picked[ledger]; !ok {
+			picked[ledger] = struct{}{}
+			sample = append(sample, ledger)
+		}
+	}
+
+	sort.Slice(sample, func(i, j int) bool { return sample[i] < sample[j] })
+	return sample
+}
+
+type expectedTransaction struct {
+	hash [32]byte
+	toid int64
+}
+
+// recorder collects what modules add to a store instead of adding it. Modules
+// never do anything but add to stores, so the rest of Store is left
+// unimplemented.
+type recorder struct {
+	Store
+
+	bits []Gap
+	txs  []expectedTransaction
+}
+
+func (r *recorder) AddTransactionToIndexes(txnTOID int64, hash [32]byte) error {
+	r.txs = append(r.txs, expectedTransaction{hash: hash, toid: txnTOID})
+	return nil
+}
+
+func (r *recorder) AddParticipantsToIndexes(checkpoint uint32, index string, participants []string) error {
+	for _, participant := range participants {
+		r.bits = append(r.bits, Gap{Key: participant, Index: index, Checkpoint: checkpoint})
+	}
+	return nil
+}
+
+func (r *recorder) AddParticipantsToIndexesNoBackend(checkpoint uint32, index string, participants []string) error {
+	return r.AddParticipantsToIndexes(checkpoint, index, participants)
+}
+
+// expectedByLedger runs the modules on every transaction of the ledger.
+func expectedByLedger(
+	ctx context.Context,
+	ledgers ledgerbackend.LedgerBackend,
+	ledgerSeq uint32,
+	networkPassphrase string,
+	modules []Module,
+) (*recorder, error) {
+	ledger, err := ledgers.GetLedger(ctx, ledgerSeq)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting ledger %d failed", ledgerSeq)
+	}
+
+	reader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(
+		networkPassphrase, ledger)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := &recorder{}
+	for {
+		tx, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "reading transactions of ledger %d failed", ledgerSeq)
+		}
+
+		if err := runModules(modules, expected, ledger, tx); err != nil {
+			return nil, errors.Wrapf(err, "indexing ledger %d failed", ledgerSeq)
+		}
+	}
+
+	return expected, nil
+}
+
+func runModules(modules []Module, store Store, ledger xdr.LedgerCloseMeta, tx ingest.LedgerTransaction) error {
+	for _, module := range modules {
+		if err := module(store, ledger, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
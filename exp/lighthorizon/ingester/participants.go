@@ -33,3 +33,50 @@ func GetOperationParticipants(tx LedgerTransaction, op xdr.Operation, opIndex in
 	set.AddSlice(participants)
 	return set, nil
 }
+
+// GetPaymentOperationParticipants is like GetOperationParticipants, but it
+// returns an empty set when the operation isn't a payment.
+func GetPaymentOperationParticipants(tx LedgerTransaction, op xdr.Operation, opIndex int) (set.Set[string], error) {
+	participants, err := index.GetPaymentOperationParticipants(*tx.LedgerTransaction, op, opIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	set := set.NewSet[string](len(participants))
+	set.AddSlice(participants)
+	return set, nil
+}
+
+// GetPaymentOperationAssets returns the set of index keys (see index.AssetKey)
+// of the assets moved by the operation if it's a payment.
+func GetPaymentOperationAssets(tx LedgerTransaction, op xdr.Operation, opIndex int) (set.Set[string], error) {
+	return keySet(index.GetOperationPaymentAssets(*tx.LedgerTransaction, op, opIndex))
+}
+
+// GetTradeOperationAssets returns the set of index keys of the assets traded
+// by the operation.
+func GetTradeOperationAssets(tx LedgerTransaction, op xdr.Operation, opIndex int) (set.Set[string], error) {
+	return keySet(index.GetOperationTradeAssets(*tx.LedgerTransaction, op, opIndex))
+}
+
+// GetOperationLiquidityPools returns the set of index keys (see
+// index.LiquidityPoolKey) of the liquidity pools the operation involved.
+func GetOperationLiquidityPools(tx LedgerTransaction, op xdr.Operation, opIndex int) (set.Set[string], error) {
+	return keySet(index.GetOperationLiquidityPools(*tx.LedgerTransaction, op, opIndex))
+}
+
+// GetTradeOperationLiquidityPools returns the set of index keys of the
+// liquidity pools the operation traded with.
+func GetTradeOperationLiquidityPools(tx LedgerTransaction, op xdr.Operation, opIndex int) (set.Set[string], error) {
+	return keySet(index.GetOperationTradeLiquidityPools(*tx.LedgerTransaction, op, opIndex))
+}
+
+func keySet(keys []string, err error) (set.Set[string], error) {
+	if err != nil {
+		return nil, err
+	}
+
+	set := set.NewSet[string](len(keys))
+	set.AddSlice(keys)
+	return set, nil
+}
@@ -146,6 +146,12 @@ break down accounts by active ledgers.`,
 				Operations: &services.OperationRepository{
 					Config: Config,
 				},
+				Effects: &services.EffectRepository{
+					Config: Config,
+				},
+				Ledgers: &services.LedgerRepository{
+					Config: Config,
+				},
 			}
 
 			// Inject our config into the root response.
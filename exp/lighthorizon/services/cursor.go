@@ -14,13 +14,21 @@ type CursorManager interface {
 
 type AccountActivityCursorManager struct {
 	AccountId string
+	Index     string
 
 	store      index.Store
 	lastCursor *toid.ID
 }
 
 func NewCursorManagerForAccountActivity(store index.Store, accountId string) *AccountActivityCursorManager {
-	return &AccountActivityCursorManager{AccountId: accountId, store: store}
+	return NewCursorManagerForAccountIndex(store, accountId, allTransactionsIndex)
+}
+
+// NewCursorManagerForAccountIndex returns a cursor manager advancing through
+// the checkpoints in which the account is active in the given index, e.g.
+// allPaymentsIndex.
+func NewCursorManagerForAccountIndex(store index.Store, accountId, indexId string) *AccountActivityCursorManager {
+	return &AccountActivityCursorManager{AccountId: accountId, Index: indexId, store: store}
 }
 
 func (c *AccountActivityCursorManager) Begin(cursor int64) (int64, error) {
@@ -36,7 +44,7 @@ func (c *AccountActivityCursorManager) Begin(cursor int64) (int64, error) {
 	//
 	// For example, someone might say ?cursor=0 but the first active checkpoint
 	// is actually 40M ledgers in.
-	firstCheckpoint, err := c.store.NextActive(c.AccountId, allTransactionsIndex, lastCheckpoint)
+	firstCheckpoint, err := c.store.NextActive(c.AccountId, c.Index, lastCheckpoint)
 	if err != nil {
 		return cursor, err
 	}
@@ -76,7 +84,7 @@ func (c *AccountActivityCursorManager) Advance(times uint) (int64, error) {
 			// is "inclusive" so if the parameter is an active checkpoint it
 			// will return itself.
 			checkpoint := index.GetCheckpointNumber(uint32(c.lastCursor.LedgerSequence))
-			checkpoint, err := c.store.NextActive(c.AccountId, allTransactionsIndex, checkpoint+1)
+			checkpoint, err := c.store.NextActive(c.AccountId, c.Index, checkpoint+1)
 			if err != nil {
 				return c.lastCursor.ToInt64(), err
 			}
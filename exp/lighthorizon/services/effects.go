@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/hcnet/go/exp/lightaurora/common"
+	"github.com/hcnet/go/exp/lightaurora/ingester"
+	"github.com/hcnet/go/ingest/processors/effects"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+type EffectService interface {
+	GetEffectsByAccount(ctx context.Context,
+		cursor int64, cursorOrder uint32, limit uint64,
+		accountId string,
+	) ([]common.Effect, error)
+}
+
+type EffectRepository struct {
+	EffectService
+	Config Config
+}
+
+// GetEffectsByAccount returns the effects on the account following the
+// effect at position cursorOrder of the operation with the cursor TOID.
+//
+// The effects are derived from the operations, their results and their meta
+// by the same code as Aurora's ingestion.
+func (er *EffectRepository) GetEffectsByAccount(ctx context.Context,
+	cursor int64, cursorOrder uint32, limit uint64,
+	accountId string,
+) ([]common.Effect, error) {
+	accountEffects := []common.Effect{}
+
+	effectsCallback := func(tx ingester.LedgerTransaction, ledgerHeader *xdr.LedgerHeader) (bool, error) {
+		// Failed transactions don't have operation effects
+		if !tx.Result.Successful() {
+			return false, nil
+		}
+
+		for operationOrder := range tx.Envelope.Operations() {
+			op := &common.Operation{
+				TransactionEnvelope: &tx.Envelope,
+				TransactionResult:   &tx.Result.Result,
+				LedgerHeader:        ledgerHeader,
+				TxIndex:             int32(tx.Index),
+				OpIndex:             int32(operationOrder),
+			}
+			if op.TOID() < cursor {
+				continue
+			}
+
+			opEffects, err := operationEffects(tx, op, er.Config.Passphrase)
+			if err != nil {
+				return false, errors.Wrapf(err, "failed to compute effects of operation %d", op.TOID())
+			}
+			for _, effect := range opEffects {
+				if op.TOID() == cursor && effect.Order <= cursorOrder {
+					continue
+				}
+				if effect.Account.ToAccountId().Address() != accountId {
+					continue
+				}
+				accountEffects = append(accountEffects, effect)
+
+				if uint64(len(accountEffects)) == limit {
+					return true, nil
+				}
+			}
+		}
+
+		return false, nil
+	}
+
+	err := searchAccountTransactions(ctx, cursor, accountId, allTransactionsIndex, er.Config, effectsCallback)
+	if len(accountEffects) > 0 {
+		ops := make([]common.Operation, 0, len(accountEffects))
+		for _, effect := range accountEffects {
+			ops = append(ops, *effect.Operation)
+		}
+		if age := operationsResponseAgeSeconds(ops); age >= 0 {
+			er.Config.Metrics.ResponseAgeHistogram.With(prometheus.Labels{
+				"request":    "GetEffectsByAccount",
+				"successful": strconv.FormatBool(err == nil),
+			}).Observe(age)
+		}
+	}
+
+	return accountEffects, err
+}
+
+// operationEffects returns the effects of an operation of a successful
+// transaction. The network passphrase is needed to recognize the events of
+// the Hcnet Asset Contract emitted by invoke_host_function operations.
+func operationEffects(tx ingester.LedgerTransaction, op *common.Operation, network string) ([]common.Effect, error) {
+	opEffects, err := effects.FromOperation(
+		*tx.LedgerTransaction,
+		uint32(op.OpIndex),
+		*op.Get(),
+		uint32(op.LedgerHeader.LedgerSeq),
+		network,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]common.Effect, 0, len(opEffects))
+	for _, effect := range opEffects {
+		address := effect.Address
+		if effect.AddressMuxed.Valid {
+			address = effect.AddressMuxed.String
+		}
+		account, err := xdr.AddressToMuxedAccount(address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid account %s", address)
+		}
+		result = append(result, common.Effect{
+			Operation: op,
+			Order:     effect.Order,
+			Type:      effect.Type,
+			Account:   account,
+			Details:   effect.Details,
+		})
+	}
+	return result, nil
+}
+
+var _ EffectService = (*EffectRepository)(nil) // ensure conformity to the interface
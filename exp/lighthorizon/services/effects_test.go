@@ -0,0 +1,544 @@
+package services
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/exp/lightaurora/adapters"
+	"github.com/hcnet/go/exp/lightaurora/common"
+	"github.com/hcnet/go/exp/lightaurora/ingester"
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/protocols/aurora/base"
+	"github.com/hcnet/go/protocols/aurora/effects"
+	"github.com/hcnet/go/strkey"
+	"github.com/hcnet/go/support/contractevents"
+	"github.com/hcnet/go/xdr"
+)
+
+var (
+	sourceAddress  = "GA4O5DLUUTLCTMM2UOWOYPNIH2FTD4NLO6KDZOFQRUISQ3FYKABGJLPC"
+	otherAddress   = "GA5SKSJEB7VWACRNWFGVZBDSZYLGK44A2JPPBWUK3GB7NYEFOOQJAC2B"
+	sponsorAddress = "GAA7AZYCJ65VJSMFAGQLBNCXA43QQ6ZEUR4GL4YSVB2FXUAHLLYUHIO5"
+
+	usd = xdr.MustNewCreditAsset("USD", sourceAddress)
+)
+
+// effectsOf returns the effects of a successful transaction of sourceAddress
+// made of a single operation, whose meta is given. Every effect is rendered
+// too, to make sure its details match the resource of its type.
+func effectsOf(t *testing.T, body xdr.OperationBody, meta xdr.TransactionMeta, network string) ([]common.Effect, error) {
+	results := []xdr.OperationResult{{
+		Code: xdr.OperationResultCodeOpInner,
+		Tr:   &xdr.OperationResultTr{Type: body.Type},
+	}}
+	tx := ingester.LedgerTransaction{LedgerTransaction: &ingest.LedgerTransaction{
+		Index: 1,
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{Tx: xdr.Transaction{
+				SourceAccount: xdr.MustMuxedAddress(sourceAddress),
+				Operations:    []xdr.Operation{{Body: body}},
+			}},
+		},
+		Result: xdr.TransactionResultPair{Result: xdr.TransactionResult{
+			Result: xdr.TransactionResultResult{
+				Code:    xdr.TransactionResultCodeTxSuccess,
+				Results: &results,
+			},
+		}},
+		UnsafeMeta: meta,
+	}}
+	op := &common.Operation{
+		TransactionEnvelope: &tx.Envelope,
+		TransactionResult:   &tx.Result.Result,
+		LedgerHeader:        &xdr.LedgerHeader{LedgerSeq: 100},
+		TxIndex:             1,
+	}
+
+	opEffects, err := operationEffects(tx, op, network)
+	if err != nil {
+		return nil, err
+	}
+	for i := range opEffects {
+		_, err := adapters.PopulateEffect(httptest.NewRequest("GET", "/effects", nil), &opEffects[i])
+		require.NoError(t, err)
+	}
+	return opEffects, nil
+}
+
+func withChanges(changes ...xdr.LedgerEntryChange) xdr.TransactionMeta {
+	return xdr.TransactionMeta{V: 2, V2: &xdr.TransactionMetaV2{
+		Operations: []xdr.OperationMeta{{Changes: changes}},
+	}}
+}
+
+func created(entry xdr.LedgerEntry) []xdr.LedgerEntryChange {
+	return []xdr.LedgerEntryChange{{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &entry}}
+}
+
+func updated(pre, post xdr.LedgerEntry) []xdr.LedgerEntryChange {
+	return []xdr.LedgerEntryChange{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &pre},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &post},
+	}
+}
+
+func removed(entry xdr.LedgerEntry) []xdr.LedgerEntryChange {
+	key, err := entry.LedgerKey()
+	if err != nil {
+		panic(err)
+	}
+	return []xdr.LedgerEntryChange{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &entry},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &key},
+	}
+}
+
+func concat(changes ...[]xdr.LedgerEntryChange) []xdr.LedgerEntryChange {
+	var result []xdr.LedgerEntryChange
+	for _, c := range changes {
+		result = append(result, c...)
+	}
+	return result
+}
+
+func sponsored(entry xdr.LedgerEntry, sponsor string) xdr.LedgerEntry {
+	sponsorID := xdr.MustAddress(sponsor)
+	entry.Ext = xdr.LedgerEntryExt{V: 1, V1: &xdr.LedgerEntryExtensionV1{SponsoringId: &sponsorID}}
+	return entry
+}
+
+func accountEntry(address string, signers ...xdr.Signer) xdr.LedgerEntry {
+	return xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeAccount,
+		Account: &xdr.AccountEntry{
+			AccountId:  xdr.MustAddress(address),
+			Balance:    1000_0000000,
+			SeqNum:     1,
+			Thresholds: xdr.Thresholds{1, 0, 0, 0},
+			Signers:    signers,
+		},
+	}}
+}
+
+func claimableBalanceEntry(id xdr.ClaimableBalanceId) xdr.LedgerEntry {
+	return xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeClaimableBalance,
+		ClaimableBalance: &xdr.ClaimableBalanceEntry{
+			BalanceId: id,
+			Claimants: []xdr.Claimant{{
+				Type: xdr.ClaimantTypeClaimantTypeV0,
+				V0: &xdr.ClaimantV0{
+					Destination: xdr.MustAddress(otherAddress),
+					Predicate:   xdr.ClaimPredicate{Type: xdr.ClaimPredicateTypeClaimPredicateUnconditional},
+				},
+			}},
+			Asset:  usd,
+			Amount: 10_0000000,
+		},
+	}}
+}
+
+func liquidityPoolEntry(t *testing.T, reserveA, reserveB, shares xdr.Int64) xdr.LedgerEntry {
+	poolID, err := xdr.NewPoolId(xdr.MustNewNativeAsset(), usd, xdr.LiquidityPoolFeeV18)
+	require.NoError(t, err)
+	return xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeLiquidityPool,
+		LiquidityPool: &xdr.LiquidityPoolEntry{
+			LiquidityPoolId: poolID,
+			Body: xdr.LiquidityPoolEntryBody{
+				Type: xdr.LiquidityPoolTypeLiquidityPoolConstantProduct,
+				ConstantProduct: &xdr.LiquidityPoolEntryConstantProduct{
+					Params: xdr.LiquidityPoolConstantProductParameters{
+						AssetA: xdr.MustNewNativeAsset(),
+						AssetB: usd,
+						Fee:    xdr.LiquidityPoolFeeV18,
+					},
+					ReserveA:                 reserveA,
+					ReserveB:                 reserveB,
+					TotalPoolShares:          shares,
+					PoolSharesTrustLineCount: 1,
+				},
+			},
+		},
+	}}
+}
+
+func effectTypes(opEffects []common.Effect) []effects.EffectType {
+	result := []effects.EffectType{}
+	for _, effect := range opEffects {
+		result = append(result, effect.Type)
+	}
+	return result
+}
+
+func TestSetOptionsEffects(t *testing.T) {
+	homeDomain := xdr.String32("example.com")
+	threshold := xdr.Uint32(2)
+	setFlags := xdr.Uint32(xdr.AccountFlagsAuthRequiredFlag)
+	clearFlags := xdr.Uint32(xdr.AccountFlagsAuthRevocableFlag)
+	inflationDest := xdr.MustAddress(otherAddress)
+	signer := xdr.Signer{Key: xdr.MustSigner(otherAddress), Weight: 3}
+
+	body := xdr.OperationBody{
+		Type: xdr.OperationTypeSetOptions,
+		SetOptionsOp: &xdr.SetOptionsOp{
+			HomeDomain:    &homeDomain,
+			MedThreshold:  &threshold,
+			SetFlags:      &setFlags,
+			ClearFlags:    &clearFlags,
+			InflationDest: &inflationDest,
+			Signer:        &signer,
+		},
+	}
+	meta := withChanges(updated(
+		accountEntry(sourceAddress),
+		sponsoredSigner(accountEntry(sourceAddress, signer), sponsorAddress),
+	)...)
+
+	opEffects, err := effectsOf(t, body, meta, "")
+	require.NoError(t, err)
+	assert.Equal(t, []effects.EffectType{
+		effects.EffectAccountHomeDomainUpdated,
+		effects.EffectAccountThresholdsUpdated,
+		effects.EffectAccountFlagsUpdated,
+		effects.EffectAccountInflationDestinationUpdated,
+		effects.EffectSignerCreated,
+		effects.EffectSignerSponsorshipCreated,
+	}, effectTypes(opEffects))
+
+	for _, effect := range opEffects {
+		assert.Equal(t, sourceAddress, effect.Account.Address())
+	}
+	assert.Equal(t, "example.com", opEffects[0].Details["home_domain"])
+	assert.Equal(t, map[string]interface{}{"med_threshold": threshold}, opEffects[1].Details)
+	assert.Equal(t, map[string]interface{}{
+		"auth_required_flag":  true,
+		"auth_revocable_flag": false,
+	}, opEffects[2].Details)
+	assert.Equal(t, otherAddress, opEffects[3].Details["inflation_destination"])
+	assert.Equal(t, map[string]interface{}{
+		"public_key": otherAddress,
+		"weight":     int32(3),
+	}, opEffects[4].Details)
+	assert.Equal(t, map[string]interface{}{
+		"sponsor": sponsorAddress,
+		"signer":  otherAddress,
+	}, opEffects[5].Details)
+}
+
+func sponsoredSigner(entry xdr.LedgerEntry, sponsor string) xdr.LedgerEntry {
+	sponsorID := xdr.MustAddress(sponsor)
+	entry.Data.Account.Ext = xdr.AccountEntryExt{V: 1, V1: &xdr.AccountEntryExtensionV1{
+		Ext: xdr.AccountEntryExtensionV1Ext{V: 2, V2: &xdr.AccountEntryExtensionV2{
+			SignerSponsoringIDs: []xdr.SponsorshipDescriptor{&sponsorID},
+		}},
+	}}
+	return entry
+}
+
+func TestAllowTrustEffects(t *testing.T) {
+	body := xdr.OperationBody{
+		Type: xdr.OperationTypeAllowTrust,
+		AllowTrustOp: &xdr.AllowTrustOp{
+			Trustor:   xdr.MustAddress(otherAddress),
+			Asset:     xdr.AssetCode{Type: xdr.AssetTypeAssetTypeCreditAlphanum4, AssetCode4: &xdr.AssetCode4{'U', 'S', 'D'}},
+			Authorize: xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+		},
+	}
+
+	opEffects, err := effectsOf(t, body, withChanges(), "")
+	require.NoError(t, err)
+	assert.Equal(t, []effects.EffectType{
+		effects.EffectTrustlineAuthorized,
+		effects.EffectTrustlineFlagsUpdated,
+	}, effectTypes(opEffects))
+	assert.Equal(t, map[string]interface{}{
+		"trustor":      otherAddress,
+		"asset_type":   "credit_alphanum4",
+		"asset_code":   "USD",
+		"asset_issuer": sourceAddress,
+	}, opEffects[0].Details)
+	assert.Equal(t, true, opEffects[1].Details["authorized_flag"])
+
+	body.AllowTrustOp.Authorize = 0
+	opEffects, err = effectsOf(t, body, withChanges(), "")
+	require.NoError(t, err)
+	assert.Equal(t, []effects.EffectType{
+		effects.EffectTrustlineDeauthorized,
+		effects.EffectTrustlineFlagsUpdated,
+	}, effectTypes(opEffects))
+	assert.Equal(t, false, opEffects[1].Details["authorized_flag"])
+	assert.Equal(t, false, opEffects[1].Details["authorized_to_maintain_liabilites"])
+}
+
+func TestSetTrustLineFlagsEffects(t *testing.T) {
+	body := xdr.OperationBody{
+		Type: xdr.OperationTypeSetTrustLineFlags,
+		SetTrustLineFlagsOp: &xdr.SetTrustLineFlagsOp{
+			Trustor:    xdr.MustAddress(otherAddress),
+			Asset:      usd,
+			ClearFlags: xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+		},
+	}
+
+	// Deauthorizing the trust line revokes the pool shares of the trustor,
+	// whose USD reserve becomes a claimable balance.
+	balanceID := xdr.ClaimableBalanceId{Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0, V0: &xdr.Hash{1}}
+	meta := withChanges(concat(
+		updated(liquidityPoolEntry(t, 100, 200, 50), liquidityPoolEntry(t, 80, 160, 40)),
+		created(claimableBalanceEntry(balanceID)),
+	)...)
+
+	opEffects, err := effectsOf(t, body, meta, "")
+	require.NoError(t, err)
+	assert.Equal(t, []effects.EffectType{
+		effects.EffectTrustlineFlagsUpdated,
+		effects.EffectClaimableBalanceCreated,
+		effects.EffectClaimableBalanceClaimantCreated,
+		effects.EffectLiquidityPoolRevoked,
+	}, effectTypes(opEffects))
+	assert.Equal(t, false, opEffects[0].Details["authorized_flag"])
+	assert.Equal(t, otherAddress, opEffects[2].Account.Address())
+	assert.Equal(t, []map[string]string{{
+		"asset":                usd.StringCanonical(),
+		"amount":               "0.0000040",
+		"claimable_balance_id": mustMarshalHex(balanceID),
+	}}, opEffects[3].Details["reserves_revoked"])
+	assert.Equal(t, "0.0000010", opEffects[3].Details["shares_revoked"])
+}
+
+func TestClaimableBalanceEffects(t *testing.T) {
+	balanceID := xdr.ClaimableBalanceId{Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0, V0: &xdr.Hash{2}}
+	entry := sponsored(claimableBalanceEntry(balanceID), sourceAddress)
+	id := mustMarshalHex(balanceID)
+
+	t.Run("create", func(t *testing.T) {
+		body := xdr.OperationBody{
+			Type: xdr.OperationTypeCreateClaimableBalance,
+			CreateClaimableBalanceOp: &xdr.CreateClaimableBalanceOp{
+				Asset:     usd,
+				Amount:    10_0000000,
+				Claimants: entry.Data.ClaimableBalance.Claimants,
+			},
+		}
+		opEffects, err := effectsOf(t, body, withChanges(created(entry)...), "")
+		require.NoError(t, err)
+		assert.Equal(t, []effects.EffectType{
+			effects.EffectClaimableBalanceCreated,
+			effects.EffectClaimableBalanceClaimantCreated,
+			effects.EffectAccountDebited,
+			effects.EffectClaimableBalanceSponsorshipCreated,
+		}, effectTypes(opEffects))
+		assert.Equal(t, id, opEffects[0].Details["balance_id"])
+		assert.Equal(t, "10.0000000", opEffects[0].Details["amount"])
+		assert.Equal(t, otherAddress, opEffects[1].Account.Address())
+		assert.Equal(t, sourceAddress, opEffects[3].Details["sponsor"])
+	})
+
+	t.Run("claim", func(t *testing.T) {
+		body := xdr.OperationBody{
+			Type:                    xdr.OperationTypeClaimClaimableBalance,
+			ClaimClaimableBalanceOp: &xdr.ClaimClaimableBalanceOp{BalanceId: balanceID},
+		}
+		opEffects, err := effectsOf(t, body, withChanges(removed(entry)...), "")
+		require.NoError(t, err)
+		assert.Equal(t, []effects.EffectType{
+			effects.EffectClaimableBalanceClaimed,
+			effects.EffectAccountCredited,
+			effects.EffectClaimableBalanceSponsorshipRemoved,
+		}, effectTypes(opEffects))
+		assert.Equal(t, id, opEffects[0].Details["balance_id"])
+		assert.Equal(t, "10.0000000", opEffects[1].Details["amount"])
+		assert.Equal(t, sourceAddress, opEffects[2].Details["former_sponsor"])
+
+		_, err = effectsOf(t, body, withChanges(), "")
+		assert.EqualError(t, err, "Change not found for balanceId : "+id)
+	})
+
+	t.Run("clawback", func(t *testing.T) {
+		body := xdr.OperationBody{
+			Type:                       xdr.OperationTypeClawbackClaimableBalance,
+			ClawbackClaimableBalanceOp: &xdr.ClawbackClaimableBalanceOp{BalanceId: balanceID},
+		}
+		opEffects, err := effectsOf(t, body, withChanges(removed(entry)...), "")
+		require.NoError(t, err)
+		assert.Equal(t, []effects.EffectType{
+			effects.EffectClaimableBalanceClawedBack,
+			effects.EffectAccountCredited,
+			effects.EffectClaimableBalanceSponsorshipRemoved,
+		}, effectTypes(opEffects))
+		assert.Equal(t, id, opEffects[0].Details["balance_id"])
+		assert.Equal(t, "USD", opEffects[1].Details["asset_code"])
+	})
+}
+
+func TestSponsorshipEffects(t *testing.T) {
+	otherAccount := accountEntry(otherAddress)
+	key, err := otherAccount.LedgerKey()
+	require.NoError(t, err)
+
+	body := xdr.OperationBody{
+		Type: xdr.OperationTypeRevokeSponsorship,
+		RevokeSponsorshipOp: &xdr.RevokeSponsorshipOp{
+			Type:      xdr.RevokeSponsorshipTypeRevokeSponsorshipLedgerEntry,
+			LedgerKey: &key,
+		},
+	}
+	meta := withChanges(updated(
+		sponsored(otherAccount, sourceAddress),
+		sponsored(otherAccount, sponsorAddress),
+	)...)
+
+	opEffects, err := effectsOf(t, body, meta, "")
+	require.NoError(t, err)
+	require.Equal(t, []effects.EffectType{effects.EffectAccountSponsorshipUpdated}, effectTypes(opEffects))
+	assert.Equal(t, otherAddress, opEffects[0].Account.Address())
+	assert.Equal(t, map[string]interface{}{
+		"former_sponsor": sourceAddress,
+		"new_sponsor":    sponsorAddress,
+	}, opEffects[0].Details)
+
+	for _, body := range []xdr.OperationBody{
+		{
+			Type: xdr.OperationTypeBeginSponsoringFutureReserves,
+			BeginSponsoringFutureReservesOp: &xdr.BeginSponsoringFutureReservesOp{
+				SponsoredId: xdr.MustAddress(otherAddress),
+			},
+		},
+		{Type: xdr.OperationTypeEndSponsoringFutureReserves},
+	} {
+		opEffects, err := effectsOf(t, body, withChanges(), "")
+		require.NoError(t, err)
+		assert.Empty(t, opEffects)
+	}
+}
+
+func TestLiquidityPoolEffects(t *testing.T) {
+	before := liquidityPoolEntry(t, 100, 200, 50)
+	after := liquidityPoolEntry(t, 130, 260, 65)
+	poolID := before.Data.LiquidityPool.LiquidityPoolId
+
+	t.Run("deposit", func(t *testing.T) {
+		body := xdr.OperationBody{
+			Type:                   xdr.OperationTypeLiquidityPoolDeposit,
+			LiquidityPoolDepositOp: &xdr.LiquidityPoolDepositOp{LiquidityPoolId: poolID},
+		}
+		opEffects, err := effectsOf(t, body, withChanges(updated(before, after)...), "")
+		require.NoError(t, err)
+		require.Equal(t, []effects.EffectType{effects.EffectLiquidityPoolDeposited}, effectTypes(opEffects))
+		assert.Equal(t, "0.0000015", opEffects[0].Details["shares_received"])
+		assert.Equal(t, "0.0000130", opEffects[0].Details["liquidity_pool"].(map[string]interface{})["reserves"].([]base.AssetAmount)[0].Amount)
+	})
+
+	t.Run("withdraw", func(t *testing.T) {
+		body := xdr.OperationBody{
+			Type:                    xdr.OperationTypeLiquidityPoolWithdraw,
+			LiquidityPoolWithdrawOp: &xdr.LiquidityPoolWithdrawOp{LiquidityPoolId: poolID},
+		}
+		opEffects, err := effectsOf(t, body, withChanges(updated(after, before)...), "")
+		require.NoError(t, err)
+		require.Equal(t, []effects.EffectType{effects.EffectLiquidityPoolWithdrew}, effectTypes(opEffects))
+		assert.Equal(t, "0.0000015", opEffects[0].Details["shares_redeemed"])
+		assert.Equal(t, []base.AssetAmount{
+			{Asset: "native", Amount: "0.0000030"},
+			{Asset: usd.StringCanonical(), Amount: "0.0000060"},
+		}, opEffects[0].Details["reserves_received"])
+
+		_, err = effectsOf(t, body, withChanges(), "")
+		assert.Error(t, err)
+	})
+
+	t.Run("created and removed", func(t *testing.T) {
+		body := xdr.OperationBody{
+			Type: xdr.OperationTypeChangeTrust,
+			ChangeTrustOp: &xdr.ChangeTrustOp{Line: xdr.ChangeTrustAsset{
+				Type: xdr.AssetTypeAssetTypePoolShare,
+				LiquidityPool: &xdr.LiquidityPoolParameters{
+					Type: xdr.LiquidityPoolTypeLiquidityPoolConstantProduct,
+					ConstantProduct: &xdr.LiquidityPoolConstantProductParameters{
+						AssetA: xdr.MustNewNativeAsset(),
+						AssetB: usd,
+						Fee:    xdr.LiquidityPoolFeeV18,
+					},
+				},
+			}},
+		}
+		opEffects, err := effectsOf(t, body, withChanges(created(before)...), "")
+		require.NoError(t, err)
+		require.Equal(t, []effects.EffectType{effects.EffectLiquidityPoolCreated}, effectTypes(opEffects))
+
+		opEffects, err = effectsOf(t, body, withChanges(removed(before)...), "")
+		require.NoError(t, err)
+		require.Equal(t, []effects.EffectType{effects.EffectLiquidityPoolRemoved}, effectTypes(opEffects))
+		assert.Equal(t, xdr.Hash(poolID).HexString(), opEffects[0].Details["liquidity_pool_id"])
+	})
+}
+
+func TestInvokeHostFunctionEffects(t *testing.T) {
+	network := "Test SDF Network ; September 2015"
+	contractID := xdr.Hash{1}
+	contract := strkey.MustEncode(strkey.VersionByteContract, contractID[:])
+
+	body := xdr.OperationBody{
+		Type:                 xdr.OperationTypeInvokeHostFunction,
+		InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{},
+	}
+	meta := xdr.TransactionMeta{V: 3, V3: &xdr.TransactionMetaV3{
+		SorobanMeta: &xdr.SorobanTransactionMeta{Events: []xdr.ContractEvent{
+			contractevents.GenerateEvent(contractevents.EventTypeTransfer,
+				otherAddress, contract, sourceAddress, usd, big.NewInt(12345), network),
+			contractevents.GenerateEvent(contractevents.EventTypeMint,
+				"", otherAddress, sourceAddress, usd, big.NewInt(100), network),
+		}},
+	}}
+
+	_, err := effectsOf(t, body, meta, "")
+	assert.EqualError(t, err, "invokeHostFunction effects cannot be determined unless network passphrase is set")
+
+	opEffects, err := effectsOf(t, body, meta, network)
+	require.NoError(t, err)
+	assert.Equal(t, []effects.EffectType{
+		effects.EffectAccountDebited,
+		effects.EffectContractCredited,
+		effects.EffectAccountCredited,
+	}, effectTypes(opEffects))
+	assert.Equal(t, otherAddress, opEffects[0].Account.Address())
+	assert.Equal(t, "0.0012345", opEffects[0].Details["amount"])
+	assert.Equal(t, sourceAddress, opEffects[1].Account.Address())
+	assert.Equal(t, contract, opEffects[1].Details["contract"])
+	assert.Equal(t, otherAddress, opEffects[2].Account.Address())
+	assert.Equal(t, "0.0000100", opEffects[2].Details["amount"])
+}
+
+func TestOperationsWithoutEffects(t *testing.T) {
+	for _, opType := range []xdr.OperationType{
+		xdr.OperationTypeExtendFootprintTtl,
+		xdr.OperationTypeRestoreFootprint,
+	} {
+		body := xdr.OperationBody{Type: opType}
+		if opType == xdr.OperationTypeExtendFootprintTtl {
+			body.ExtendFootprintTtlOp = &xdr.ExtendFootprintTtlOp{}
+		} else {
+			body.RestoreFootprintOp = &xdr.RestoreFootprintOp{}
+		}
+		opEffects, err := effectsOf(t, body, withChanges(), "")
+		require.NoError(t, err)
+		assert.Empty(t, opEffects)
+	}
+
+	_, err := effectsOf(t, xdr.OperationBody{Type: 100}, withChanges(), "")
+	assert.EqualError(t, err, "Unknown operation type: ")
+}
+
+func mustMarshalHex(id xdr.ClaimableBalanceId) string {
+	hex, err := xdr.MarshalHex(id)
+	if err != nil {
+		panic(err)
+	}
+	return hex
+}
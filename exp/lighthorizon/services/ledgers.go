@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hcnet/go/exp/lightaurora/common"
+	"github.com/hcnet/go/exp/lightaurora/ingester"
+	"github.com/hcnet/go/xdr"
+)
+
+type LedgerService interface {
+	GetLedgerBySequence(ctx context.Context, sequence uint32) (common.Ledger, error)
+}
+
+type LedgerRepository struct {
+	LedgerService
+	Config Config
+}
+
+// GetLedgerBySequence reads the ledger from the archive and computes the
+// statistics of its transaction set.
+func (lr *LedgerRepository) GetLedgerBySequence(ctx context.Context, sequence uint32) (common.Ledger, error) {
+	ledger := common.Ledger{}
+
+	header, err := searchLedgerTransactions(ctx, sequence, lr.Config,
+		func(tx ingester.LedgerTransaction, _ *xdr.LedgerHeader) (bool, error) {
+			opCount := int32(len(tx.Envelope.Operations()))
+			ledger.TxSetOperationCount += opCount
+			if tx.Result.Successful() {
+				ledger.SuccessfulTransactionCount++
+				ledger.OperationCount += opCount
+			} else {
+				ledger.FailedTransactionCount++
+			}
+			return false, nil
+		})
+	ledger.Header = header
+
+	return ledger, err
+}
+
+var _ LedgerService = (*LedgerRepository)(nil) // ensure conformity to the interface
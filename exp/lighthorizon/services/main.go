@@ -11,6 +11,7 @@ import (
 	"github.com/hcnet/go/exp/lightaurora/index"
 	"github.com/hcnet/go/exp/lightaurora/ingester"
 	"github.com/hcnet/go/historyarchive"
+	"github.com/hcnet/go/support/collections/set"
 	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/support/log"
 	"github.com/hcnet/go/xdr"
@@ -22,6 +23,15 @@ const (
 	slowFetchDurationThreshold = time.Second
 )
 
+// The indexes of the asset and liquidity pool keys, see index.AssetKey and
+// index.LiquidityPoolKey.
+const (
+	assetPaymentsIndex       = "all/payments"
+	assetTradesIndex         = "all/trades"
+	liquidityPoolIndex       = "all/all"
+	liquidityPoolTradesIndex = "all/trades"
+)
+
 var (
 	checkpointManager = historyarchive.NewCheckpointManager(0)
 )
@@ -58,6 +68,8 @@ func NewMetrics(registry *prometheus.Registry) Metrics {
 type LightAurora struct {
 	Operations   OperationService
 	Transactions TransactionService
+	Effects      EffectService
+	Ledgers      LedgerService
 }
 
 type Metrics struct {
@@ -79,10 +91,27 @@ type searchCallback func(ingester.LedgerTransaction, *xdr.LedgerHeader) (finishe
 func searchAccountTransactions(ctx context.Context,
 	cursor int64,
 	accountId string,
+	indexId string,
 	config Config,
 	callback searchCallback,
 ) error {
-	cursorMgr := NewCursorManagerForAccountActivity(config.IndexStore, accountId)
+	return searchTransactions(ctx, cursor, accountId, indexId, config,
+		ingester.GetTransactionParticipants, callback)
+}
+
+// searchTransactions calls the callback for the transactions of the ledgers
+// after the cursor which are active in the index of the key (an account,
+// asset or liquidity pool). When getParticipants isn't nil, only the
+// transactions the key participates in are passed to the callback.
+func searchTransactions(ctx context.Context,
+	cursor int64,
+	key string,
+	indexId string,
+	config Config,
+	getParticipants func(ingester.LedgerTransaction) (set.Set[string], error),
+	callback searchCallback,
+) error {
+	cursorMgr := NewCursorManagerForAccountIndex(config.IndexStore, key, indexId)
 	cursor, err := cursorMgr.Begin(cursor)
 	if err == io.EOF {
 		return nil
@@ -92,8 +121,8 @@ func searchAccountTransactions(ctx context.Context,
 	nextLedger := getLedgerFromCursor(cursor)
 
 	log.WithField("cursor", cursor).
-		Debugf("Searching %s for account %s starting at ledger %d",
-			allTransactionsIndex, accountId, nextLedger)
+		Debugf("Searching %s for %s starting at ledger %d",
+			indexId, key, nextLedger)
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -113,7 +142,7 @@ func searchAccountTransactions(ctx context.Context,
 			WithField("avg-ledger-process", getAverageDuration(processDuration, count)).
 			WithField("avg-index-fetch", getAverageDuration(indexFetchDuration, count)).
 			WithField("total", time.Since(fullStart)).
-			Infof("Fulfilled request for account %s at cursor %d", accountId, cursor)
+			Infof("Fulfilled request for %s at cursor %d", key, cursor)
 	}()
 
 	checkpointMgr := historyarchive.NewCheckpointManager(0)
@@ -172,12 +201,16 @@ func searchAccountTransactions(ctx context.Context,
 			// Note: If we move to ledger-based indices, we don't need this,
 			// since we have a guarantee that the transaction will contain
 			// the account as a participant.
-			participants, participantErr := ingester.GetTransactionParticipants(tx)
-			if participantErr != nil {
-				return participantErr
+			found := true
+			if getParticipants != nil {
+				participants, participantErr := getParticipants(tx)
+				if participantErr != nil {
+					return participantErr
+				}
+				_, found = participants[key]
 			}
 
-			if _, found := participants[accountId]; found {
+			if found {
 				finished, callBackErr := callback(tx, &ledger.V0.V0.LedgerHeader.Header)
 				if callBackErr != nil {
 					return callBackErr
@@ -206,6 +239,52 @@ func searchAccountTransactions(ctx context.Context,
 	return nil
 }
 
+// searchLedgerTransactions calls the callback for every transaction of the
+// given ledger, in application order, until it reports that it's finished.
+// It returns the header of the ledger.
+func searchLedgerTransactions(ctx context.Context,
+	sequence uint32,
+	config Config,
+	callback searchCallback,
+) (xdr.LedgerHeaderHistoryEntry, error) {
+	ledger, err := config.Ingester.GetLedger(ctx, sequence)
+	if err != nil {
+		return xdr.LedgerHeaderHistoryEntry{}, errors.Wrapf(err,
+			"failed to retrieve ledger %d from archive", sequence)
+	}
+	meta, ok := ledger.GetV0()
+	if !ok {
+		return xdr.LedgerHeaderHistoryEntry{}, errors.Errorf(
+			"unsupported ledger %d meta version %d", sequence, ledger.V)
+	}
+	header := meta.LedgerHeaderHistoryEntry()
+
+	reader, err := config.Ingester.NewLedgerTransactionReader(ledger)
+	if err != nil {
+		return header, errors.Wrapf(err, "failed to read ledger %d", sequence)
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return header, ctx.Err()
+		}
+
+		tx, readErr := reader.Read()
+		if readErr == io.EOF {
+			return header, nil
+		} else if readErr != nil {
+			return header, readErr
+		}
+
+		finished, callbackErr := callback(tx, &header.Header)
+		if callbackErr != nil {
+			return header, callbackErr
+		} else if finished {
+			return header, nil
+		}
+	}
+}
+
 func getAverageDuration[
 	T constraints.Signed | constraints.Float,
 ](d time.Duration, count T) time.Duration {
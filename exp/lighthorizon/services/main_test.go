@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"encoding/hex"
 	"io"
+	"os"
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -10,6 +12,7 @@ import (
 	"github.com/hcnet/go/exp/lightaurora/index"
 	"github.com/hcnet/go/exp/lightaurora/ingester"
 	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/protocols/aurora/effects"
 	"github.com/hcnet/go/toid"
 	"github.com/hcnet/go/xdr"
 	"github.com/stretchr/testify/mock"
@@ -95,6 +98,133 @@ func TestItGetsOperationsByAccount(t *testing.T) {
 	})
 }
 
+func TestItGetsPaymentsByAccount(t *testing.T) {
+	ctx := context.Background()
+
+	ledgerSeq := checkpointMgr.PrevCheckpoint(uint32(startLedgerSeq))
+	cursor := toid.New(int32(ledgerSeq), 1, 1).ToInt64()
+
+	opsService := newOperationService(ctx)
+
+	// bumping the sequence isn't a payment
+	ops, err := opsService.GetPaymentsByAccount(ctx, cursor, 5, accountId)
+	require.NoError(t, err)
+	require.Empty(t, ops)
+}
+
+func TestItGetsPaymentsByAsset(t *testing.T) {
+	ctx := context.Background()
+	opsService := newOperationService(ctx)
+	store := opsService.(*OperationRepository).Config.IndexStore.(*index.MockStore)
+
+	activeChk := uint32(index.GetCheckpointNumber(uint32(startLedgerSeq)))
+	store.
+		On("NextActive", "native", "all/payments", uint32(0)).Return(activeChk, nil).
+		On("NextActive", "native", "all/payments", activeChk).Return(activeChk, nil).
+		On("NextActive", "native", "all/payments", activeChk+1).Return(uint32(0), io.EOF)
+
+	// the asset index is searched, but bumping the sequence isn't a payment
+	ops, err := opsService.GetPaymentsByAsset(ctx, 0, 5, xdr.MustNewNativeAsset())
+	require.NoError(t, err)
+	require.Empty(t, ops)
+	store.AssertCalled(t, "NextActive", "native", "all/payments", uint32(0))
+}
+
+func TestItGetsEffectsByAccount(t *testing.T) {
+	ctx := context.Background()
+
+	ledgerSeq := checkpointMgr.PrevCheckpoint(uint32(startLedgerSeq))
+	cursor := toid.New(int32(ledgerSeq), 1, 1).ToInt64()
+
+	t.Run("first", func(tt *testing.T) {
+		effectService := newEffectService(ctx)
+
+		accountEffects, err := effectService.GetEffectsByAccount(ctx, cursor, 0, 1, accountId)
+		require.NoError(tt, err)
+		require.Len(tt, accountEffects, 1)
+		require.Equal(tt, xdr.Uint32(1586113), accountEffects[0].Operation.LedgerHeader.LedgerSeq)
+		require.EqualValues(tt, 2, accountEffects[0].Operation.TxIndex)
+		require.EqualValues(tt, 1, accountEffects[0].Order)
+		require.Equal(tt, effects.EffectSequenceBumped, accountEffects[0].Type)
+		require.Equal(tt, xdr.SequenceNumber(34), accountEffects[0].Details["new_seq"])
+	})
+
+	t.Run("with limit", func(tt *testing.T) {
+		effectService := newEffectService(ctx)
+
+		accountEffects, err := effectService.GetEffectsByAccount(ctx, cursor, 0, 5, accountId)
+		require.NoError(tt, err)
+		require.Len(tt, accountEffects, 2)
+		require.Equal(tt, xdr.Uint32(1586113), accountEffects[0].Operation.LedgerHeader.LedgerSeq)
+		require.Equal(tt, xdr.Uint32(1586114), accountEffects[1].Operation.LedgerHeader.LedgerSeq)
+	})
+
+	t.Run("with cursor order", func(tt *testing.T) {
+		effectService := newEffectService(ctx)
+		accountEffects, err := effectService.GetEffectsByAccount(ctx, cursor, 0, 1, accountId)
+		require.NoError(tt, err)
+		require.Len(tt, accountEffects, 1)
+
+		// continue after the only effect of the first operation
+		effectService = newEffectService(ctx)
+		accountEffects, err = effectService.GetEffectsByAccount(ctx,
+			accountEffects[0].Operation.TOID(), accountEffects[0].Order, 5, accountId)
+		require.NoError(tt, err)
+		require.Len(tt, accountEffects, 1)
+		require.Equal(tt, xdr.Uint32(1586114), accountEffects[0].Operation.LedgerHeader.LedgerSeq)
+	})
+}
+
+func TestItGetsLedgerBySequence(t *testing.T) {
+	ctx := context.Background()
+	ingest, store := mockArchiveAndIndex(ctx)
+	ledgerService := &LedgerRepository{
+		Config: Config{
+			Ingester:   ingest,
+			IndexStore: store,
+			Passphrase: passphrase,
+			Metrics:    NewMetrics(prometheus.NewRegistry()),
+		},
+	}
+
+	ledger, err := ledgerService.GetLedgerBySequence(ctx, uint32(startLedgerSeq))
+	require.NoError(t, err)
+	require.Equal(t, xdr.Uint32(startLedgerSeq), ledger.Header.Header.LedgerSeq)
+	require.EqualValues(t, 2, ledger.SuccessfulTransactionCount)
+	require.EqualValues(t, 0, ledger.FailedTransactionCount)
+	require.EqualValues(t, 3, ledger.OperationCount)
+	require.EqualValues(t, 3, ledger.TxSetOperationCount)
+}
+
+func TestItGetsTransactionByHash(t *testing.T) {
+	ctx := context.Background()
+	hash := "55d8aa3693489ffc1d70b8ba33b8b5c012ec098f6f104383e3f090048488febd"
+	var txHash [32]byte
+	_, err := hex.Decode(txHash[:], []byte(hash))
+	require.NoError(t, err)
+
+	t.Run("indexed", func(tt *testing.T) {
+		txService := newTransactionService(ctx)
+		store := txService.(*TransactionRepository).Config.IndexStore.(*index.MockStore)
+		store.On("TransactionTOID", txHash).
+			Return(toid.New(int32(startLedgerSeq)+1, 2, 0).ToInt64(), nil)
+
+		tx, err := txService.GetTransactionByHash(ctx, hash)
+		require.NoError(tt, err)
+		require.Equal(tt, xdr.Uint32(1586113), tx.LedgerHeader.LedgerSeq)
+		require.EqualValues(tt, 2, tx.TxIndex)
+	})
+
+	t.Run("not indexed", func(tt *testing.T) {
+		txService := newTransactionService(ctx)
+		store := txService.(*TransactionRepository).Config.IndexStore.(*index.MockStore)
+		store.On("TransactionTOID", txHash).Return(int64(0), io.EOF)
+
+		_, err := txService.GetTransactionByHash(ctx, hash)
+		require.ErrorIs(tt, err, os.ErrNotExist)
+	})
+}
+
 func mockArchiveAndIndex(ctx context.Context) (ingester.Ingester, index.Store) {
 	mockArchive := &ingester.MockIngester{}
 	mockReaderLedger1 := &ingester.MockLedgerTransactionReader{}
@@ -182,6 +312,7 @@ func testLedgerTx(source xdr.AccountId, txIndex uint32, bumpTos ...int) ingester
 	code := xdr.TransactionResultCodeTxSuccess
 
 	operations := []xdr.Operation{}
+	operationsMeta := []xdr.OperationMeta{}
 	for _, bumpTo := range bumpTos {
 		operations = append(operations, xdr.Operation{
 			Body: xdr.OperationBody{
@@ -191,6 +322,12 @@ func testLedgerTx(source xdr.AccountId, txIndex uint32, bumpTos ...int) ingester
 				},
 			},
 		})
+		operationsMeta = append(operationsMeta, xdr.OperationMeta{
+			Changes: xdr.LedgerEntryChanges{
+				testAccountChange(xdr.LedgerEntryChangeTypeLedgerEntryState, source, 1),
+				testAccountChange(xdr.LedgerEntryChangeTypeLedgerEntryUpdated, source, bumpTo),
+			},
+		})
 	}
 
 	return ingester.LedgerTransaction{
@@ -217,7 +354,7 @@ func testLedgerTx(source xdr.AccountId, txIndex uint32, bumpTos ...int) ingester
 			UnsafeMeta: xdr.TransactionMeta{
 				V: 2,
 				V2: &xdr.TransactionMetaV2{
-					Operations: make([]xdr.OperationMeta, len(bumpTos)),
+					Operations: operationsMeta,
 				},
 			},
 			Index: txIndex,
@@ -225,6 +362,27 @@ func testLedgerTx(source xdr.AccountId, txIndex uint32, bumpTos ...int) ingester
 	}
 }
 
+func testAccountChange(changeType xdr.LedgerEntryChangeType, account xdr.AccountId, seqNum int) xdr.LedgerEntryChange {
+	entry := &xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{
+				AccountId: account,
+				SeqNum:    xdr.SequenceNumber(seqNum),
+			},
+		},
+	}
+
+	change := xdr.LedgerEntryChange{Type: changeType}
+	switch changeType {
+	case xdr.LedgerEntryChangeTypeLedgerEntryState:
+		change.State = entry
+	case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+		change.Updated = entry
+	}
+	return change
+}
+
 func newTransactionService(ctx context.Context) TransactionService {
 	ingest, store := mockArchiveAndIndex(ctx)
 	return &TransactionRepository{
@@ -237,6 +395,18 @@ func newTransactionService(ctx context.Context) TransactionService {
 	}
 }
 
+func newEffectService(ctx context.Context) EffectService {
+	ingest, store := mockArchiveAndIndex(ctx)
+	return &EffectRepository{
+		Config: Config{
+			Ingester:   ingest,
+			IndexStore: store,
+			Passphrase: passphrase,
+			Metrics:    NewMetrics(prometheus.NewRegistry()),
+		},
+	}
+}
+
 func newOperationService(ctx context.Context) OperationService {
 	ingest, store := mockArchiveAndIndex(ctx)
 	return &OperationRepository{
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/hcnet/go/exp/lightaurora/common"
+	"github.com/hcnet/go/xdr"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -19,6 +20,13 @@ func (m *MockTransactionService) GetTransactionsByAccount(ctx context.Context,
 	return args.Get(0).([]common.Transaction), args.Error(1)
 }
 
+func (m *MockTransactionService) GetTransactionByHash(ctx context.Context,
+	hash string,
+) (common.Transaction, error) {
+	args := m.Called(ctx, hash)
+	return args.Get(0).(common.Transaction), args.Error(1)
+}
+
 type MockOperationService struct {
 	mock.Mock
 }
@@ -30,3 +38,66 @@ func (m *MockOperationService) GetOperationsByAccount(ctx context.Context,
 	args := m.Called(ctx, cursor, limit, accountId)
 	return args.Get(0).([]common.Operation), args.Error(1)
 }
+
+func (m *MockOperationService) GetPaymentsByAccount(ctx context.Context,
+	cursor int64, limit uint64,
+	accountId string,
+) ([]common.Operation, error) {
+	args := m.Called(ctx, cursor, limit, accountId)
+	return args.Get(0).([]common.Operation), args.Error(1)
+}
+
+func (m *MockOperationService) GetPaymentsByAsset(ctx context.Context,
+	cursor int64, limit uint64,
+	asset xdr.Asset,
+) ([]common.Operation, error) {
+	args := m.Called(ctx, cursor, limit, asset)
+	return args.Get(0).([]common.Operation), args.Error(1)
+}
+
+func (m *MockOperationService) GetTradeOperationsByAsset(ctx context.Context,
+	cursor int64, limit uint64,
+	asset xdr.Asset,
+) ([]common.Operation, error) {
+	args := m.Called(ctx, cursor, limit, asset)
+	return args.Get(0).([]common.Operation), args.Error(1)
+}
+
+func (m *MockOperationService) GetOperationsByLiquidityPool(ctx context.Context,
+	cursor int64, limit uint64,
+	poolId xdr.PoolId,
+) ([]common.Operation, error) {
+	args := m.Called(ctx, cursor, limit, poolId)
+	return args.Get(0).([]common.Operation), args.Error(1)
+}
+
+func (m *MockOperationService) GetTradeOperationsByLiquidityPool(ctx context.Context,
+	cursor int64, limit uint64,
+	poolId xdr.PoolId,
+) ([]common.Operation, error) {
+	args := m.Called(ctx, cursor, limit, poolId)
+	return args.Get(0).([]common.Operation), args.Error(1)
+}
+
+type MockEffectService struct {
+	mock.Mock
+}
+
+func (m *MockEffectService) GetEffectsByAccount(ctx context.Context,
+	cursor int64, cursorOrder uint32, limit uint64,
+	accountId string,
+) ([]common.Effect, error) {
+	args := m.Called(ctx, cursor, cursorOrder, limit, accountId)
+	return args.Get(0).([]common.Effect), args.Error(1)
+}
+
+type MockLedgerService struct {
+	mock.Mock
+}
+
+func (m *MockLedgerService) GetLedgerBySequence(ctx context.Context,
+	sequence uint32,
+) (common.Ledger, error) {
+	args := m.Called(ctx, sequence)
+	return args.Get(0).(common.Ledger), args.Error(1)
+}
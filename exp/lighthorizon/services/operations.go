@@ -7,7 +7,9 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/hcnet/go/exp/lightaurora/common"
+	"github.com/hcnet/go/exp/lightaurora/index"
 	"github.com/hcnet/go/exp/lightaurora/ingester"
+	"github.com/hcnet/go/support/collections/set"
 	"github.com/hcnet/go/support/log"
 	"github.com/hcnet/go/xdr"
 )
@@ -17,6 +19,26 @@ type OperationService interface {
 		cursor int64, limit uint64,
 		accountId string,
 	) ([]common.Operation, error)
+	GetPaymentsByAccount(ctx context.Context,
+		cursor int64, limit uint64,
+		accountId string,
+	) ([]common.Operation, error)
+	GetPaymentsByAsset(ctx context.Context,
+		cursor int64, limit uint64,
+		asset xdr.Asset,
+	) ([]common.Operation, error)
+	GetTradeOperationsByAsset(ctx context.Context,
+		cursor int64, limit uint64,
+		asset xdr.Asset,
+	) ([]common.Operation, error)
+	GetOperationsByLiquidityPool(ctx context.Context,
+		cursor int64, limit uint64,
+		poolId xdr.PoolId,
+	) ([]common.Operation, error)
+	GetTradeOperationsByLiquidityPool(ctx context.Context,
+		cursor int64, limit uint64,
+		poolId xdr.PoolId,
+	) ([]common.Operation, error)
 }
 
 type OperationRepository struct {
@@ -27,24 +49,100 @@ type OperationRepository struct {
 func (or *OperationRepository) GetOperationsByAccount(ctx context.Context,
 	cursor int64, limit uint64,
 	accountId string,
+) ([]common.Operation, error) {
+	return or.searchOperations(ctx, "GetOperationsByAccount",
+		cursor, limit, accountId, allTransactionsIndex, ingester.GetOperationParticipants)
+}
+
+// GetPaymentsByAccount returns the payments (create_account, payment, path
+// payments and account_merge operations) the account participated in.
+func (or *OperationRepository) GetPaymentsByAccount(ctx context.Context,
+	cursor int64, limit uint64,
+	accountId string,
+) ([]common.Operation, error) {
+	return or.searchOperations(ctx, "GetPaymentsByAccount",
+		cursor, limit, accountId, allPaymentsIndex, ingester.GetPaymentOperationParticipants)
+}
+
+// GetPaymentsByAsset returns the payments sending or receiving the asset.
+func (or *OperationRepository) GetPaymentsByAsset(ctx context.Context,
+	cursor int64, limit uint64,
+	asset xdr.Asset,
+) ([]common.Operation, error) {
+	key, err := index.AssetKey(asset)
+	if err != nil {
+		return nil, err
+	}
+	return or.searchOperations(ctx, "GetPaymentsByAsset",
+		cursor, limit, key, assetPaymentsIndex, ingester.GetPaymentOperationAssets)
+}
+
+// GetTradeOperationsByAsset returns the operations which bought or sold the
+// asset, from offers or liquidity pools.
+func (or *OperationRepository) GetTradeOperationsByAsset(ctx context.Context,
+	cursor int64, limit uint64,
+	asset xdr.Asset,
+) ([]common.Operation, error) {
+	key, err := index.AssetKey(asset)
+	if err != nil {
+		return nil, err
+	}
+	return or.searchOperations(ctx, "GetTradeOperationsByAsset",
+		cursor, limit, key, assetTradesIndex, ingester.GetTradeOperationAssets)
+}
+
+// GetOperationsByLiquidityPool returns the operations which deposited into,
+// withdrew from, traded with or trusted the liquidity pool.
+func (or *OperationRepository) GetOperationsByLiquidityPool(ctx context.Context,
+	cursor int64, limit uint64,
+	poolId xdr.PoolId,
+) ([]common.Operation, error) {
+	return or.searchOperations(ctx, "GetOperationsByLiquidityPool",
+		cursor, limit, index.LiquidityPoolKey(poolId), liquidityPoolIndex, ingester.GetOperationLiquidityPools)
+}
+
+// GetTradeOperationsByLiquidityPool returns the operations which traded with
+// the liquidity pool.
+func (or *OperationRepository) GetTradeOperationsByLiquidityPool(ctx context.Context,
+	cursor int64, limit uint64,
+	poolId xdr.PoolId,
+) ([]common.Operation, error) {
+	return or.searchOperations(ctx, "GetTradeOperationsByLiquidityPool",
+		cursor, limit, index.LiquidityPoolKey(poolId), liquidityPoolTradesIndex, ingester.GetTradeOperationLiquidityPools)
+}
+
+// searchOperations returns the operations with the given key (an account,
+// asset or liquidity pool) among the participants returned by
+// getParticipants, using the key's index to skip inactive checkpoints.
+func (or *OperationRepository) searchOperations(ctx context.Context,
+	request string,
+	cursor int64, limit uint64,
+	key string,
+	indexId string,
+	getParticipants func(ingester.LedgerTransaction, xdr.Operation, int) (set.Set[string], error),
 ) ([]common.Operation, error) {
 	ops := []common.Operation{}
 
 	opsCallback := func(tx ingester.LedgerTransaction, ledgerHeader *xdr.LedgerHeader) (bool, error) {
 		for operationOrder, op := range tx.Envelope.Operations() {
-			opParticipants, err := ingester.GetOperationParticipants(tx, op, operationOrder)
+			opParticipants, err := getParticipants(tx, op, operationOrder)
 			if err != nil {
 				return false, err
 			}
 
-			if _, foundInOp := opParticipants[accountId]; foundInOp {
-				ops = append(ops, common.Operation{
+			if _, foundInOp := opParticipants[key]; foundInOp {
+				operation := common.Operation{
 					TransactionEnvelope: &tx.Envelope,
 					TransactionResult:   &tx.Result.Result,
 					LedgerHeader:        ledgerHeader,
 					TxIndex:             int32(tx.Index),
 					OpIndex:             int32(operationOrder),
-				})
+				}
+				// skip the operations of the cursor ledger up to the cursor
+				if operation.TOID() <= cursor {
+					continue
+				}
+				ops = append(ops, operation)
 
 				if uint64(len(ops)) == limit {
 					return true, nil
@@ -55,10 +153,12 @@ func (or *OperationRepository) GetOperationsByAccount(ctx context.Context,
 		return false, nil
 	}
 
-	err := searchAccountTransactions(ctx, cursor, accountId, or.Config, opsCallback)
+	// The callback checks the participants of each operation, so there's no
+	// need to filter the transactions first.
+	err := searchTransactions(ctx, cursor, key, indexId, or.Config, nil, opsCallback)
 	if age := operationsResponseAgeSeconds(ops); age >= 0 {
 		or.Config.Metrics.ResponseAgeHistogram.With(prometheus.Labels{
-			"request":    "GetOperationsByAccount",
+			"request":    request,
 			"successful": strconv.FormatBool(err == nil),
 		}).Observe(age)
 	}
@@ -2,13 +2,18 @@ package services
 
 import (
 	"context"
+	"encoding/hex"
+	"io"
+	"os"
 	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/hcnet/go/exp/lightaurora/common"
 	"github.com/hcnet/go/exp/lightaurora/ingester"
+	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/support/log"
+	"github.com/hcnet/go/toid"
 	"github.com/hcnet/go/xdr"
 )
 
@@ -22,6 +27,7 @@ type TransactionService interface {
 		cursor int64, limit uint64,
 		accountId string,
 	) ([]common.Transaction, error)
+	GetTransactionByHash(ctx context.Context, hash string) (common.Transaction, error)
 }
 
 func (tr *TransactionRepository) GetTransactionsByAccount(ctx context.Context,
@@ -31,17 +37,22 @@ func (tr *TransactionRepository) GetTransactionsByAccount(ctx context.Context,
 	txs := []common.Transaction{}
 
 	txsCallback := func(tx ingester.LedgerTransaction, ledgerHeader *xdr.LedgerHeader) (bool, error) {
-		txs = append(txs, common.Transaction{
+		transaction := common.Transaction{
 			LedgerTransaction: &tx,
 			LedgerHeader:      ledgerHeader,
 			TxIndex:           int32(tx.Index),
 			NetworkPassphrase: tr.Config.Passphrase,
-		})
+		}
+		// skip the transactions of the cursor ledger up to the cursor
+		if transaction.TOID() <= cursor {
+			return false, nil
+		}
+		txs = append(txs, transaction)
 
 		return uint64(len(txs)) == limit, nil
 	}
 
-	err := searchAccountTransactions(ctx, cursor, accountId, tr.Config, txsCallback)
+	err := searchAccountTransactions(ctx, cursor, accountId, allTransactionsIndex, tr.Config, txsCallback)
 	if age := transactionsResponseAgeSeconds(txs); age >= 0 {
 		tr.Config.Metrics.ResponseAgeHistogram.With(prometheus.Labels{
 			"request":    "GetTransactionsByAccount",
@@ -52,6 +63,51 @@ func (tr *TransactionRepository) GetTransactionsByAccount(ctx context.Context,
 	return txs, err
 }
 
+// GetTransactionByHash looks up the ledger of the transaction in the
+// transaction index and returns it from that ledger. It returns
+// os.ErrNotExist when the transaction isn't indexed.
+func (tr *TransactionRepository) GetTransactionByHash(ctx context.Context, hash string) (common.Transaction, error) {
+	var txHash [32]byte
+	decoded, err := hex.DecodeString(hash)
+	if err != nil || len(decoded) != len(txHash) {
+		return common.Transaction{}, errors.Errorf("invalid transaction hash %s", hash)
+	}
+	copy(txHash[:], decoded)
+
+	txTOID, err := tr.Config.IndexStore.TransactionTOID(txHash)
+	if err == io.EOF {
+		return common.Transaction{}, os.ErrNotExist
+	} else if err != nil {
+		return common.Transaction{}, errors.Wrap(err, "failed to read transaction index")
+	}
+
+	// The transaction index stores the 1-based application order of the
+	// transaction, like ingest.LedgerTransaction.Index.
+	id := toid.Parse(txTOID)
+	var found *common.Transaction
+	_, err = searchLedgerTransactions(ctx, uint32(id.LedgerSequence), tr.Config,
+		func(tx ingester.LedgerTransaction, ledgerHeader *xdr.LedgerHeader) (bool, error) {
+			if tx.Index != uint32(id.TransactionOrder) {
+				return false, nil
+			}
+			found = &common.Transaction{
+				LedgerTransaction: &tx,
+				LedgerHeader:      ledgerHeader,
+				TxIndex:           int32(tx.Index),
+				NetworkPassphrase: tr.Config.Passphrase,
+			}
+			return true, nil
+		})
+	if err != nil {
+		return common.Transaction{}, err
+	}
+	if found == nil {
+		return common.Transaction{}, errors.Errorf(
+			"transaction %s not found in ledger %d", hash, id.LedgerSequence)
+	}
+	return *found, nil
+}
+
 func transactionsResponseAgeSeconds(txs []common.Transaction) float64 {
 	if len(txs) == 0 {
 		return -1
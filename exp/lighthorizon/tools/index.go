@@ -1,7 +1,9 @@
 package tools
 
 import (
+	"bufio"
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"os/signal"
@@ -13,12 +15,17 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/hcnet/go/exp/lightaurora/index"
+	backend "github.com/hcnet/go/exp/lightaurora/index/backend"
 	"github.com/hcnet/go/historyarchive"
+	"github.com/hcnet/go/ingest/ledgerbackend"
+	"github.com/hcnet/go/metaarchive"
+	"github.com/hcnet/go/network"
 	"github.com/hcnet/go/strkey"
 	"github.com/hcnet/go/support/collections/maps"
 	"github.com/hcnet/go/support/collections/set"
 	"github.com/hcnet/go/support/log"
 	"github.com/hcnet/go/support/ordered"
+	"github.com/hcnet/go/support/storage"
 )
 
 var (
@@ -32,7 +39,9 @@ func AddIndexCommands(parent *cobra.Command) *cobra.Command {
 		Example: `
 index view file:///tmp/indices
 index view file:///tmp/indices GAGJZWQ5QT34VK3U6W6YKRYFIK6YSAXQC6BHIIYLG6X3CE5QW2KAYNJR
-index stats file:///tmp/indices`,
+index stats file:///tmp/indices
+index compact file:///tmp/indices
+index verify file:///tmp/indices file:///tmp/txmeta 1000 2000 --sample=100`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// require a subcommand - this is just a "category"
 			return cmd.Help()
@@ -191,9 +200,84 @@ view gcs://indices --limit=10 GAXLQGKIUAIIUHAX4GJO3J7HFGLBCNF6ZCZSTLJE7EKO5IUHGL
 		},
 	}
 
+	compact := &cobra.Command{
+		Use: "compact <index path>",
+		Long: "Packs the indices of every account, asset and liquidity pool " +
+			"into a single file with an offset table rather than a file per " +
+			"key. Indices are compacted in place, under the packs/ directory " +
+			"of the index path, which must not happen while indices are " +
+			"being built into it. With --output, they are only read and " +
+			"packed into that file instead, which can then be put into the " +
+			"packs/ directory of another index path.",
+		Example: `compact file:///tmp/indices
+compact s3://indices
+compact s3://indices --output=/tmp/indices/packs/remote.pack`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Usage()
+			}
+
+			output, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return cmd.Usage()
+			}
+
+			return compactIndex(args[0], output)
+		},
+	}
+
+	verify := &cobra.Command{
+		Use: "verify <index path> <txmeta source> <start ledger> <end ledger>",
+		Long: "Checks the indices against (a sample of) the ledgers in the " +
+			"given range by indexing them again, reporting whatever is " +
+			"missing. With --repair, the missing bits and transactions are " +
+			"added to the indices.",
+		Example: `verify file:///tmp/indices file:///tmp/txmeta 1000 2000
+verify s3://indices gcs://txmeta 1000 200000 --sample=500 --modules=accounts,transactions --repair`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 4 {
+				return cmd.Usage()
+			}
+
+			start, err := strconv.ParseUint(args[2], 10, 32)
+			if err != nil {
+				return cmd.Usage()
+			}
+			end, err := strconv.ParseUint(args[3], 10, 32)
+			if err != nil {
+				return cmd.Usage()
+			}
+
+			config := index.VerifyConfig{}
+			modules, _ := cmd.Flags().GetString("modules")
+			config.Modules = strings.Split(modules, ",")
+			config.NetworkPassphrase, _ = cmd.Flags().GetString("network-passphrase")
+			config.SampleSize, _ = cmd.Flags().GetUint32("sample")
+			config.Seed, _ = cmd.Flags().GetInt64("seed")
+			config.Repair, _ = cmd.Flags().GetBool("repair")
+
+			report, err := verifyIndex(args[0], args[1],
+				historyarchive.Range{Low: uint32(start), High: uint32(end)}, config)
+			if err != nil {
+				return err
+			}
+			if !report.OK() && !report.Repaired {
+				return fmt.Errorf("found %d missing index entries and %d missing transactions",
+					len(report.Gaps), len(report.TransactionGaps))
+			}
+			return nil
+		},
+	}
+
 	view.Flags().Uint("limit", 10, "a maximum number of accounts or checkpoints to show")
 	view.Flags().String("index-name", "", "filter for a particular index")
-	cmd.AddCommand(stats, view, purge)
+	compact.Flags().String("output", "", "write the pack to this file instead of compacting in place")
+	verify.Flags().String("modules", "accounts,transactions", "comma-separated list of modules the indices were built with")
+	verify.Flags().String("network-passphrase", network.TestNetworkPassphrase, "network passphrase")
+	verify.Flags().Uint32("sample", 0, "number of random ledgers to check (default: 0, every ledger)")
+	verify.Flags().Int64("seed", time.Now().UnixNano(), "seed for picking the sample")
+	verify.Flags().Bool("repair", false, "add the missing entries to the indices")
+	cmd.AddCommand(stats, view, purge, compact, verify)
 
 	if parent == nil {
 		return cmd
@@ -354,3 +438,97 @@ func purgeIndex(path string, r historyarchive.Range) error {
 		purged, len(accounts), path)
 	return nil
 }
+
+func compactIndex(path, output string) error {
+	start := time.Now()
+	store, err := index.Connect(path)
+	if err != nil {
+		return err
+	}
+
+	var stats backend.CompactStats
+	if output == "" {
+		log.Infof("Compacting indices at %s in place.", path)
+		if stats, err = store.Compact(); err != nil {
+			return err
+		}
+	} else {
+		log.Infof("Packing indices at %s into %s.", path, output)
+		keys, err := store.ReadAccounts()
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w := bufio.NewWriter(f)
+		if stats, err = backend.Compact(store, keys, w); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Packed %d keys (%d listed without indices) into %d bytes in %s.",
+		stats.Keys, stats.Missing, stats.Bytes, time.Since(start))
+	return nil
+}
+
+func verifyIndex(
+	path, txmetaSource string,
+	ledgerRange historyarchive.Range,
+	config index.VerifyConfig,
+) (*index.VerifyReport, error) {
+	ctx := context.Background()
+	store, err := index.Connect(path)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := historyarchive.ConnectBackend(txmetaSource, storage.ConnectOptions{
+		Context:  ctx,
+		S3Region: "us-east-1",
+	})
+	if err != nil {
+		return nil, err
+	}
+	ledgers := ledgerbackend.NewHistoryArchiveBackend(metaarchive.NewMetaArchive(source))
+	defer ledgers.Close()
+
+	log.Infof("Verifying indices at %s against ledgers [%d, %d] from %s (seed: %d).",
+		path, ledgerRange.Low, ledgerRange.High, txmetaSource, config.Seed)
+
+	report, err := index.Verify(ctx, store, ledgers, ledgerRange, config)
+	if err != nil {
+		return report, err
+	}
+
+	for _, gap := range report.Gaps {
+		log.WithField("key", gap.Key).WithField("index", gap.Index).
+			Warnf("Missing checkpoint %d (from ledger %d)", gap.Checkpoint, gap.Ledger)
+	}
+	for _, gap := range report.TransactionGaps {
+		if gap.Actual == 0 {
+			log.WithField("hash", gap.Hash).
+				Warnf("Missing transaction (from ledger %d)", gap.Ledger)
+		} else {
+			log.WithField("hash", gap.Hash).
+				Warnf("Wrong TOID %d, expected %d", gap.Actual, gap.Expected)
+		}
+	}
+
+	log.Infof("Checked %d ledgers: %d missing index entries, %d missing or wrong transactions.",
+		len(report.Ledgers), len(report.Gaps), len(report.TransactionGaps))
+	if report.Repaired {
+		log.Infof("Repaired all of them.")
+	}
+	return report, nil
+}
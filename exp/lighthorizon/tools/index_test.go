@@ -1,10 +1,13 @@
 package tools
 
 import (
+	"net/url"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/hcnet/go/exp/lightaurora/index"
+	backend "github.com/hcnet/go/exp/lightaurora/index/backend"
 	"github.com/hcnet/go/historyarchive"
 	"github.com/hcnet/go/keypair"
 	"github.com/hcnet/go/support/log"
@@ -56,3 +59,88 @@ func TestIndexPurge(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, 123, i)
 }
+
+func TestIndexCompact(t *testing.T) {
+	tempDir := filepath.Join(t.TempDir(), "index-store")
+	tempFile := "file://" + tempDir
+	accounts := []string{
+		keypair.MustRandom().Address(),
+		keypair.MustRandom().Address(),
+	}
+
+	idx, err := index.Connect(tempFile)
+	require.NoError(t, err)
+	for _, chk := range []uint32{14, 15, 123} {
+		require.NoError(t, idx.AddParticipantsToIndexes(chk, "test", accounts))
+	}
+	require.NoError(t, idx.Flush())
+
+	// With --output the store is only read.
+	output := filepath.Join(t.TempDir(), "exported.pack")
+	require.NoError(t, compactIndex(tempFile, output))
+	f, err := os.Open(output)
+	require.NoError(t, err)
+	defer f.Close()
+	info, err := f.Stat()
+	require.NoError(t, err)
+	pack, err := backend.OpenPack(f, info.Size())
+	require.NoError(t, err)
+	for _, account := range accounts {
+		require.True(t, pack.Contains(account))
+		_, err := os.Stat(filepath.Join(tempDir, account[:3], account))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, compactIndex(tempFile, ""))
+
+	for _, account := range accounts {
+		_, err := os.Stat(filepath.Join(tempDir, account[:3], account))
+		require.True(t, os.IsNotExist(err))
+	}
+
+	idx, err = index.Connect(tempFile)
+	require.NoError(t, err)
+	for _, account := range accounts {
+		next, err := idx.NextActive(account, "test", 15)
+		require.NoError(t, err)
+		require.EqualValues(t, 15, next)
+
+		next, err = idx.NextActive(account, "test", 16)
+		require.NoError(t, err)
+		require.EqualValues(t, 123, next)
+	}
+}
+
+func TestIndexCompactStorage(t *testing.T) {
+	dirs := []string{t.TempDir(), t.TempDir()}
+	query := url.Values{}
+	for _, dir := range dirs {
+		query.Add("backend", "file://"+dir)
+	}
+	path := "multi://?" + query.Encode()
+	accounts := []string{keypair.MustRandom().Address()}
+
+	idx, err := index.Connect(path)
+	require.NoError(t, err)
+	for _, chk := range []uint32{14, 123} {
+		require.NoError(t, idx.AddParticipantsToIndexes(chk, "test", accounts))
+	}
+	require.NoError(t, idx.Flush())
+
+	require.NoError(t, compactIndex(path, ""))
+
+	// Every replica was compacted.
+	for _, dir := range dirs {
+		_, err := os.Stat(filepath.Join(dir, accounts[0][:3], accounts[0]))
+		require.True(t, os.IsNotExist(err))
+		packs, err := filepath.Glob(filepath.Join(dir, "packs", "*.pack"))
+		require.NoError(t, err)
+		require.Len(t, packs, 1)
+	}
+
+	idx, err = index.Connect(path)
+	require.NoError(t, err)
+	next, err := idx.NextActive(accounts[0], "test", 15)
+	require.NoError(t, err)
+	require.EqualValues(t, 123, next)
+}
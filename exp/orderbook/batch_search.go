@@ -0,0 +1,228 @@
+package orderbook
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// PathsQuery is a single payment path search of a batch evaluated by
+// FindPathsBatch. Strict receive searches (the default) are described by the
+// same parameters as FindPaths, strict send searches, where StrictSend is
+// true, by the same parameters as FindFixedPaths.
+type PathsQuery struct {
+	StrictSend bool
+
+	// strict receive parameters
+	DestinationAsset      xdr.Asset
+	DestinationAmount     xdr.Int64
+	SourceAccountID       *xdr.AccountId
+	SourceAssets          []xdr.Asset
+	SourceAssetBalances   []xdr.Int64
+	ValidateSourceBalance bool
+
+	// strict send parameters
+	SourceAsset       xdr.Asset
+	AmountToSpend     xdr.Int64
+	DestinationAssets []xdr.Asset
+}
+
+// sharedSearchKey identifies the queries which can be answered by the same
+// graph search. The search starting from the destination asset (or from the
+// source asset for strict send queries) explores the graph in the same way
+// whatever the assets at the other end of the path are, so the queries only
+// differ in the paths they keep.
+func (q PathsQuery) sharedSearchKey() string {
+	var b strings.Builder
+	if q.StrictSend {
+		b.WriteString("send|")
+		b.WriteString(q.SourceAsset.String())
+		b.WriteString("|")
+		b.WriteString(strconv.FormatInt(int64(q.AmountToSpend), 10))
+		return b.String()
+	}
+	b.WriteString("receive|")
+	b.WriteString(q.DestinationAsset.String())
+	b.WriteString("|")
+	b.WriteString(strconv.FormatInt(int64(q.DestinationAmount), 10))
+	b.WriteString("|")
+	if q.SourceAccountID != nil {
+		b.WriteString(q.SourceAccountID.Address())
+	}
+	return b.String()
+}
+
+// FindPathsBatch evaluates all the given queries against the same state of the
+// graph and returns the payment paths of each query, in the order of the
+// queries, along with the ledger the graph is accurate up to. The results are
+// the ones FindPaths and FindFixedPaths would return but queries which share
+// their starting point (for example the same destination asset and amount with
+// different source assets) are answered by a single search of the graph.
+func (graph *OrderBookGraph) FindPathsBatch(
+	ctx context.Context,
+	maxPathLength int,
+	queries []PathsQuery,
+	maxAssetsPerPath int,
+	includePools bool,
+) ([][]Path, uint32, error) {
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	groups := map[string][]int{}
+	var order []string
+	for i, q := range queries {
+		key := q.sharedSearchKey()
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	results := make([][]Path, len(queries))
+	for _, key := range order {
+		indexes := groups[key]
+		var err error
+		if queries[indexes[0]].StrictSend {
+			err = graph.findFixedPathsGroup(ctx, maxPathLength, queries, indexes, maxAssetsPerPath, includePools, results)
+		} else {
+			err = graph.findPathsGroup(ctx, maxPathLength, queries, indexes, maxAssetsPerPath, includePools, results)
+		}
+		if err != nil {
+			return nil, graph.lastLedger, errors.Wrap(err, "could not determine paths")
+		}
+	}
+	return results, graph.lastLedger, nil
+}
+
+// findPathsGroup runs a single strict receive search whose targets are the
+// source assets of all the queries in the group and assigns each query the
+// paths starting with one of its source assets. The source balances are
+// validated per query because they only filter the paths found by the search.
+func (graph *OrderBookGraph) findPathsGroup(
+	ctx context.Context,
+	maxPathLength int,
+	queries []PathsQuery,
+	indexes []int,
+	maxAssetsPerPath int,
+	includePools bool,
+	results [][]Path,
+) error {
+	first := queries[indexes[0]]
+	targetAssets := map[int32]xdr.Int64{}
+	for _, i := range indexes {
+		for _, sourceAsset := range queries[i].SourceAssets {
+			if sourceAssetID, ok := graph.assetStringToID[sourceAsset.String()]; ok {
+				targetAssets[sourceAssetID] = 0
+			}
+		}
+	}
+
+	var found []Path
+	destinationAssetString := first.DestinationAsset.String()
+	destinationAssetID, ok := graph.assetStringToID[destinationAssetString]
+	if ok && len(targetAssets) > 0 {
+		searchState := &sellingGraphSearchState{
+			graph:                  graph,
+			destinationAssetString: destinationAssetString,
+			destinationAssetAmount: first.DestinationAmount,
+			ignoreOffersFrom:       first.SourceAccountID,
+			targetAssets:           targetAssets,
+			paths:                  []Path{},
+			includePools:           includePools,
+		}
+		if err := search(ctx, searchState, maxPathLength, destinationAssetID, first.DestinationAmount); err != nil {
+			return err
+		}
+		found = searchState.paths
+	}
+
+	for _, i := range indexes {
+		q := queries[i]
+		balances := make(map[string]xdr.Int64, len(q.SourceAssets))
+		for j, sourceAsset := range q.SourceAssets {
+			balances[sourceAsset.String()] = q.SourceAssetBalances[j]
+		}
+		paths := []Path{}
+		for _, path := range found {
+			balance, ok := balances[path.SourceAsset]
+			if ok && (!q.ValidateSourceBalance || balance >= path.SourceAmount) {
+				paths = append(paths, path)
+			}
+		}
+		var err error
+		if results[i], err = sortAndFilterPaths(paths, maxAssetsPerPath, sortBySourceAsset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findFixedPathsGroup runs a single strict send search whose targets are the
+// destination assets of all the queries in the group and assigns each query
+// the paths ending with one of its destination assets.
+func (graph *OrderBookGraph) findFixedPathsGroup(
+	ctx context.Context,
+	maxPathLength int,
+	queries []PathsQuery,
+	indexes []int,
+	maxAssetsPerPath int,
+	includePools bool,
+	results [][]Path,
+) error {
+	first := queries[indexes[0]]
+	targetAssets := map[int32]bool{}
+	for _, i := range indexes {
+		for _, destinationAsset := range queries[i].DestinationAssets {
+			if destinationAssetID, ok := graph.assetStringToID[destinationAsset.String()]; ok {
+				targetAssets[destinationAssetID] = true
+			}
+		}
+	}
+
+	var found []Path
+	sourceAssetString := first.SourceAsset.String()
+	sourceAssetID, ok := graph.assetStringToID[sourceAssetString]
+	if ok && len(targetAssets) > 0 {
+		searchState := &buyingGraphSearchState{
+			graph:             graph,
+			sourceAssetString: sourceAssetString,
+			sourceAssetAmount: first.AmountToSpend,
+			targetAssets:      targetAssets,
+			paths:             []Path{},
+			includePools:      includePools,
+		}
+		if err := search(ctx, searchState, maxPathLength, sourceAssetID, first.AmountToSpend); err != nil {
+			return err
+		}
+		found = searchState.paths
+	}
+
+	for _, i := range indexes {
+		destinations := make(map[string]bool, len(queries[i].DestinationAssets))
+		for _, destinationAsset := range queries[i].DestinationAssets {
+			destinations[destinationAsset.String()] = true
+		}
+		paths := []Path{}
+		for _, path := range found {
+			if destinations[path.DestinationAsset] {
+				paths = append(paths, path)
+			}
+		}
+		var err error
+		if results[i], err = sortAndFilterPaths(paths, maxAssetsPerPath, sortByDestinationAsset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LastLedger returns the ledger the graph is accurate up to.
+func (graph *OrderBookGraph) LastLedger() uint32 {
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	return graph.lastLedger
+}
@@ -0,0 +1,138 @@
+package orderbook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/xdr"
+)
+
+func TestFindPathsBatch(t *testing.T) {
+	graph := NewOrderBookGraph()
+	graph.AddOffers(dollarOffer, threeEurOffer, eurOffer, twoEurOffer,
+		quarterOffer, fiftyCentsOffer)
+	graph.AddOffers(
+		xdr.OfferEntry{
+			SellerId: issuer,
+			OfferId:  xdr.Int64(9),
+			Buying:   eurAsset,
+			Selling:  usdAsset,
+			Price:    xdr.Price{N: 1, D: 1},
+			Amount:   xdr.Int64(500),
+		},
+		xdr.OfferEntry{
+			SellerId: issuer,
+			OfferId:  xdr.Int64(11),
+			Buying:   usdAsset,
+			Selling:  eurAsset,
+			Price:    xdr.Price{N: 1, D: 3},
+			Amount:   xdr.Int64(500),
+		},
+		xdr.OfferEntry{
+			SellerId: issuer,
+			OfferId:  xdr.Int64(12),
+			Buying:   chfAsset,
+			Selling:  eurAsset,
+			Price:    xdr.Price{N: 1, D: 2},
+			Amount:   xdr.Int64(500),
+		},
+		xdr.OfferEntry{
+			SellerId: issuer,
+			OfferId:  xdr.Int64(13),
+			Buying:   yenAsset,
+			Selling:  chfAsset,
+			Price:    xdr.Price{N: 1, D: 2},
+			Amount:   xdr.Int64(500),
+		},
+	)
+	require.NoError(t, graph.Apply(2))
+	assert.EqualValues(t, 2, graph.LastLedger())
+
+	kp := keypair.MustRandom()
+	ignoreOffersFrom := xdr.MustAddress(kp.Address())
+
+	queries := []PathsQuery{
+		// the first three queries share the same search
+		{
+			DestinationAsset:      nativeAsset,
+			DestinationAmount:     20,
+			SourceAccountID:       &ignoreOffersFrom,
+			SourceAssets:          []xdr.Asset{yenAsset, usdAsset},
+			SourceAssetBalances:   []xdr.Int64{100000, 60000},
+			ValidateSourceBalance: true,
+		},
+		{
+			DestinationAsset:      nativeAsset,
+			DestinationAmount:     20,
+			SourceAccountID:       &ignoreOffersFrom,
+			SourceAssets:          []xdr.Asset{yenAsset, usdAsset},
+			SourceAssetBalances:   []xdr.Int64{0, 0},
+			ValidateSourceBalance: true,
+		},
+		{
+			DestinationAsset:    nativeAsset,
+			DestinationAmount:   20,
+			SourceAccountID:     &ignoreOffersFrom,
+			SourceAssets:        []xdr.Asset{usdAsset, eurAsset},
+			SourceAssetBalances: []xdr.Int64{0, 0},
+		},
+		{
+			DestinationAsset:    usdAsset,
+			DestinationAmount:   10,
+			SourceAssets:        []xdr.Asset{nativeAsset, eurAsset},
+			SourceAssetBalances: []xdr.Int64{0, 0},
+		},
+		{
+			StrictSend:        true,
+			SourceAsset:       usdAsset,
+			AmountToSpend:     5,
+			DestinationAssets: []xdr.Asset{nativeAsset},
+		},
+		{
+			StrictSend:        true,
+			SourceAsset:       usdAsset,
+			AmountToSpend:     5,
+			DestinationAssets: []xdr.Asset{eurAsset, nativeAsset},
+		},
+		{
+			StrictSend:        true,
+			SourceAsset:       yenAsset,
+			AmountToSpend:     5,
+			DestinationAssets: []xdr.Asset{nativeAsset},
+		},
+	}
+
+	results, lastLedger, err := graph.FindPathsBatch(context.Background(), 3, queries, 5, true)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, lastLedger)
+	require.Len(t, results, len(queries))
+
+	// every result is the same as the one of the query evaluated on its own
+	for i, q := range queries {
+		var expected []Path
+		if q.StrictSend {
+			expected, _, err = graph.FindFixedPaths(
+				context.Background(), 3, q.SourceAsset, q.AmountToSpend, q.DestinationAssets, 5, true,
+			)
+		} else {
+			expected, _, err = graph.FindPaths(
+				context.Background(), 3, q.DestinationAsset, q.DestinationAmount, q.SourceAccountID,
+				q.SourceAssets, q.SourceAssetBalances, q.ValidateSourceBalance, 5, true,
+			)
+		}
+		require.NoError(t, err)
+		assertPathEquals(t, results[i], expected)
+	}
+	assert.NotEmpty(t, results[0])
+	assert.Empty(t, results[1])
+	assert.NotEmpty(t, results[5])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = graph.FindPathsBatch(ctx, 3, queries, 5, true)
+	assert.EqualError(t, err, "could not determine paths: context canceled")
+}
@@ -0,0 +1,364 @@
+package orderbook
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/hcnet/go/price"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+const maxBips = 10000
+
+// Fill is the amount of the source asset sold to a venue and the amount of
+// the destination asset received in exchange.
+type Fill struct {
+	SourceAmount      xdr.Int64
+	DestinationAmount xdr.Int64
+}
+
+// Execution describes a trade from a source asset to a destination asset
+// split between the offers and the liquidity pool of the trading pair.
+type Execution struct {
+	Fill
+	// Offers is the part of the trade filled by offers and OffersCount is
+	// the number of offers crossed.
+	Offers      Fill
+	OffersCount int
+	// LiquidityPool is the part of the trade filled by the liquidity pool.
+	LiquidityPool Fill
+	// Price is the average price of the trade in units of the source asset
+	// per unit of the destination asset, it is nil if nothing was received.
+	Price *big.Rat
+	// Slippage is the deviation of Price from the best price of the trading
+	// pair in basis points, it is nil if Price is nil.
+	Slippage *big.Rat
+}
+
+// DepthLevel is the liquidity available in a trading pair until the marginal
+// price deviates from the best price by SlippageBips basis points.
+type DepthLevel struct {
+	SlippageBips uint32
+	// PriceLimit is the marginal price reached at this level.
+	PriceLimit *big.Rat
+	Execution
+}
+
+// Depth contains the depth and price impact analytics of a trading pair.
+type Depth struct {
+	// BestPrice is the lowest price, in units of the source asset per unit
+	// of the destination asset, offered by the offers or the liquidity pool
+	// of the trading pair. It is nil if there is no liquidity.
+	BestPrice *big.Rat
+	// LiquidityPool is the id of the liquidity pool of the trading pair, if
+	// there is one.
+	LiquidityPool *xdr.PoolId
+	// Levels is the cumulative depth curve of the trading pair.
+	Levels []DepthLevel
+	// Execution is the best split between the offers and the liquidity pool
+	// of a trade selling the requested amount of the source asset.
+	// OffersOnly and LiquidityPoolOnly are the executions of the same trade
+	// restricted to each kind of venue. They are nil if no amount was
+	// requested or if the trading pair has no such venue.
+	Execution         *Execution
+	OffersOnly        *Execution
+	LiquidityPoolOnly *Execution
+}
+
+// DepthQuery is a request for the depth and price impact analytics of the
+// trading pair selling SourceAsset for DestinationAsset.
+type DepthQuery struct {
+	SourceAsset      xdr.Asset
+	DestinationAsset xdr.Asset
+	// SlippageBips are the deviations from the best price, in basis points,
+	// at which the depth curve is evaluated.
+	SlippageBips []uint32
+	// SourceAmount is the size of the trade to estimate, no trade is
+	// estimated if it is 0.
+	SourceAmount xdr.Int64
+}
+
+// Depth returns the cumulative depth curve of the trading pair, across offers
+// and the liquidity pool (if includePools is true), along with the expected
+// execution of a trade of the requested size. The trades are routed to the
+// cheapest venue first: offers are consumed in price order and the liquidity
+// pool is used until its marginal price reaches the price of the next offer.
+//
+// Depth also returns the ledger the results are consistent with.
+func (graph *OrderBookGraph) Depth(q DepthQuery, includePools bool) (Depth, uint32, error) {
+	if q.SourceAmount < 0 {
+		return Depth{}, 0, errBadAmount
+	}
+	for _, bips := range q.SlippageBips {
+		if bips > maxBips {
+			return Depth{}, 0, errors.Errorf("slippage of %d bips exceeds %d", bips, maxBips)
+		}
+	}
+
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	venues := graph.venues(q.SourceAsset, q.DestinationAsset)
+	if !includePools {
+		venues.pool = liquidityPool{}
+	}
+	r := newRouter(graph.assetStringToID[q.SourceAsset.String()], venues)
+
+	var depth Depth
+	depth.BestPrice = r.bestPrice()
+	if depth.BestPrice == nil {
+		return depth, graph.lastLedger, nil
+	}
+	if r.hasPool {
+		id := venues.pool.LiquidityPoolId
+		depth.LiquidityPool = &id
+	}
+
+	for _, bips := range q.SlippageBips {
+		limit := new(big.Rat).Mul(
+			depth.BestPrice,
+			big.NewRat(int64(maxBips+bips), maxBips),
+		)
+		execution, err := r.route(math.MaxInt64, limit, true, true)
+		if err != nil {
+			return Depth{}, 0, err
+		}
+		execution.setPrice(depth.BestPrice)
+		depth.Levels = append(depth.Levels, DepthLevel{
+			SlippageBips: bips,
+			PriceLimit:   limit,
+			Execution:    execution,
+		})
+	}
+
+	if q.SourceAmount > 0 {
+		var err error
+		if depth.Execution, err = r.estimate(q.SourceAmount, depth.BestPrice, true, true); err != nil {
+			return Depth{}, 0, err
+		}
+		if len(venues.offers) > 0 {
+			if depth.OffersOnly, err = r.estimate(q.SourceAmount, depth.BestPrice, true, false); err != nil {
+				return Depth{}, 0, err
+			}
+		}
+		if r.hasPool {
+			if depth.LiquidityPoolOnly, err = r.estimate(q.SourceAmount, depth.BestPrice, false, true); err != nil {
+				return Depth{}, 0, err
+			}
+		}
+	}
+
+	return depth, graph.lastLedger, nil
+}
+
+// venues returns the offers selling destinationAsset for sourceAsset and the
+// liquidity pool of the two assets.
+func (graph *OrderBookGraph) venues(sourceAsset, destinationAsset xdr.Asset) Venues {
+	source, ok := graph.assetStringToID[sourceAsset.String()]
+	if !ok {
+		return Venues{}
+	}
+	destination, ok := graph.assetStringToID[destinationAsset.String()]
+	if !ok {
+		return Venues{}
+	}
+	edges := graph.venuesForSellingAsset[destination]
+	if i := edges.find(source); i >= 0 {
+		return edges[i].value
+	}
+	return Venues{}
+}
+
+// router routes trades selling the source asset between the offers and the
+// liquidity pool of a trading pair.
+type router struct {
+	offers  []xdr.OfferEntry
+	hasPool bool
+	// reserveSource and reserveDestination are the pool reserves of the
+	// source and the destination asset.
+	reserveSource      xdr.Int64
+	reserveDestination xdr.Int64
+	fee                xdr.Int32
+}
+
+func newRouter(source int32, venues Venues) router {
+	r := router{offers: venues.offers}
+	if params := venues.pool.Body.ConstantProduct; params != nil &&
+		params.ReserveA > 0 && params.ReserveB > 0 {
+		r.hasPool = true
+		r.reserveSource, r.reserveDestination = params.ReserveA, params.ReserveB
+		if venues.pool.assetA != source {
+			r.reserveSource, r.reserveDestination = params.ReserveB, params.ReserveA
+		}
+		r.fee = params.Params.Fee
+	}
+	return r
+}
+
+// bestPrice returns the lowest price of the offers and the marginal price of
+// the liquidity pool, or nil if there are no venues.
+func (r router) bestPrice() *big.Rat {
+	var best *big.Rat
+	if len(r.offers) > 0 {
+		best = big.NewRat(int64(r.offers[0].Price.N), int64(r.offers[0].Price.D))
+	}
+	if r.hasPool {
+		// X / ((1 - F) Y)
+		spot := new(big.Rat).SetFrac(
+			new(big.Int).Mul(big.NewInt(int64(r.reserveSource)), big.NewInt(maxBips)),
+			new(big.Int).Mul(big.NewInt(int64(r.reserveDestination)), big.NewInt(int64(maxBips-r.fee))),
+		)
+		if best == nil || spot.Cmp(best) < 0 {
+			best = spot
+		}
+	}
+	return best
+}
+
+// poolDepositForPrice returns the amount of the source asset which must be
+// deposited in the liquidity pool for its marginal price to reach limit. The
+// marginal price after depositing x is (X + (1 - F)x)^2 / ((1 - F) XY), so:
+//
+//	x = (sqrt(limit (1 - F) XY) - X) / (1 - F)
+func (r router) poolDepositForPrice(limit *big.Rat) xdr.Int64 {
+	const prec = 256
+	X := new(big.Float).SetPrec(prec).SetInt64(int64(r.reserveSource))
+	Y := new(big.Float).SetPrec(prec).SetInt64(int64(r.reserveDestination))
+	f := new(big.Float).SetPrec(prec).Quo(
+		new(big.Float).SetPrec(prec).SetInt64(int64(maxBips-r.fee)),
+		new(big.Float).SetPrec(prec).SetInt64(maxBips),
+	)
+
+	x := new(big.Float).SetPrec(prec).SetRat(limit)
+	x.Mul(x, f).Mul(x, X).Mul(x, Y)
+	x.Sqrt(x).Sub(x, X).Quo(x, f)
+	if x.Sign() <= 0 {
+		return 0
+	}
+
+	// the deposit cannot overflow the reserve
+	maxDeposit := xdr.Int64(math.MaxInt64) - r.reserveSource
+	if x.Cmp(new(big.Float).SetInt64(int64(maxDeposit))) >= 0 {
+		return maxDeposit
+	}
+	deposit, _ := x.Int64()
+	return xdr.Int64(deposit)
+}
+
+// route sells up to amount of the source asset, without exceeding the
+// marginal price limit if it is not nil. Only the offers or only the
+// liquidity pool are used if useOffers or usePool is false.
+func (r router) route(amount xdr.Int64, limit *big.Rat, useOffers, usePool bool) (Execution, error) {
+	var execution Execution
+	usePool = usePool && r.hasPool
+	remaining := amount
+	var poolDeposit xdr.Int64
+
+	// fillPool deposits in the pool until its marginal price reaches
+	// poolLimit, or all the remaining amount if poolLimit is nil
+	fillPool := func(poolLimit *big.Rat) {
+		if !usePool || remaining == 0 {
+			return
+		}
+		target := xdr.Int64(math.MaxInt64) - r.reserveSource
+		if poolLimit != nil {
+			target = r.poolDepositForPrice(poolLimit)
+		}
+		if add := target - poolDeposit; add > 0 {
+			if add > remaining {
+				add = remaining
+			}
+			poolDeposit += add
+			remaining -= add
+		}
+	}
+
+	if useOffers {
+		for _, offer := range r.offers {
+			offerPrice := big.NewRat(int64(offer.Price.N), int64(offer.Price.D))
+			if limit != nil && offerPrice.Cmp(limit) > 0 {
+				break
+			}
+			fillPool(offerPrice)
+			if remaining == 0 {
+				break
+			}
+
+			n, d := int64(offer.Price.N), int64(offer.Price.D)
+			wanted, err := price.MulFractionRoundDown(int64(remaining), d, n)
+			if err == price.ErrOverflow {
+				wanted = int64(offer.Amount)
+			} else if err != nil {
+				return Execution{}, errors.Wrap(err, "could not cross offer")
+			}
+			if wanted == 0 {
+				// the remaining amount is too small to buy from the offer
+				break
+			}
+			sold, bought, err := price.ConvertToBuyingUnits(int64(offer.Amount), wanted, n, d)
+			if err == price.ErrOverflow {
+				break
+			} else if err != nil {
+				return Execution{}, errors.Wrap(err, "could not cross offer")
+			}
+			if bought == 0 {
+				continue
+			}
+			if xdr.Int64(sold) > remaining ||
+				xdr.Int64(bought) > math.MaxInt64-execution.Offers.DestinationAmount {
+				break
+			}
+
+			remaining -= xdr.Int64(sold)
+			execution.Offers.SourceAmount += xdr.Int64(sold)
+			execution.Offers.DestinationAmount += xdr.Int64(bought)
+			execution.OffersCount++
+			if remaining == 0 {
+				break
+			}
+		}
+	}
+	fillPool(limit)
+
+	if poolDeposit > 0 {
+		payout, _, ok := CalculatePoolPayout(
+			r.reserveSource, r.reserveDestination, poolDeposit, r.fee, false,
+		)
+		if !ok {
+			return Execution{}, errPoolOverflows
+		}
+		execution.LiquidityPool = Fill{SourceAmount: poolDeposit, DestinationAmount: payout}
+	}
+
+	execution.SourceAmount = execution.Offers.SourceAmount + execution.LiquidityPool.SourceAmount
+	execution.DestinationAmount = execution.Offers.DestinationAmount
+	if execution.LiquidityPool.DestinationAmount > math.MaxInt64-execution.DestinationAmount {
+		return Execution{}, errors.New("destination amount overflows")
+	}
+	execution.DestinationAmount += execution.LiquidityPool.DestinationAmount
+	return execution, nil
+}
+
+// estimate returns the execution of a trade selling amount of the source
+// asset. The trade is only partially filled if there is not enough
+// liquidity.
+func (r router) estimate(amount xdr.Int64, bestPrice *big.Rat, useOffers, usePool bool) (*Execution, error) {
+	execution, err := r.route(amount, nil, useOffers, usePool)
+	if err != nil {
+		return nil, err
+	}
+	execution.setPrice(bestPrice)
+	return &execution, nil
+}
+
+// setPrice sets the average price of the execution and its slippage from
+// bestPrice.
+func (e *Execution) setPrice(bestPrice *big.Rat) {
+	if e.DestinationAmount == 0 {
+		return
+	}
+	e.Price = big.NewRat(int64(e.SourceAmount), int64(e.DestinationAmount))
+	e.Slippage = new(big.Rat).Sub(e.Price, bestPrice)
+	e.Slippage.Quo(e.Slippage, bestPrice).Mul(e.Slippage, big.NewRat(maxBips, 1))
+}
@@ -0,0 +1,185 @@
+package orderbook
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/xdr"
+)
+
+func depthTestGraph(t *testing.T, withOffers, withPool bool) *OrderBookGraph {
+	graph := NewOrderBookGraph()
+	if withOffers {
+		graph.AddOffers(
+			xdr.OfferEntry{
+				SellerId: issuer,
+				OfferId:  1,
+				Selling:  nativeAsset,
+				Buying:   usdAsset,
+				Price:    xdr.Price{N: 1, D: 1},
+				Amount:   1000,
+			},
+			xdr.OfferEntry{
+				SellerId: issuer,
+				OfferId:  2,
+				Selling:  nativeAsset,
+				Buying:   usdAsset,
+				Price:    xdr.Price{N: 2, D: 1},
+				Amount:   1000,
+			},
+			// offers in the opposite direction are ignored
+			xdr.OfferEntry{
+				SellerId: issuer,
+				OfferId:  3,
+				Selling:  usdAsset,
+				Buying:   nativeAsset,
+				Price:    xdr.Price{N: 1, D: 1},
+				Amount:   1000,
+			},
+		)
+	}
+	if withPool {
+		graph.AddLiquidityPools(makePool(nativeAsset, usdAsset, 10000, 10000))
+	}
+	require.NoError(t, graph.Apply(10))
+	return graph
+}
+
+func TestDepthExecution(t *testing.T) {
+	graph := depthTestGraph(t, true, true)
+	depth, ledger, err := graph.Depth(DepthQuery{
+		SourceAsset:      usdAsset,
+		DestinationAsset: nativeAsset,
+		SourceAmount:     1500,
+	}, true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), ledger)
+	assert.Equal(t, big.NewRat(1, 1), depth.BestPrice)
+	require.NotNil(t, depth.LiquidityPool)
+
+	// the first offer is cheaper than the pool, then the pool is cheaper
+	// than the second offer
+	require.NotNil(t, depth.Execution)
+	assert.Equal(t, Fill{SourceAmount: 1500, DestinationAmount: 1474}, depth.Execution.Fill)
+	assert.Equal(t, Fill{SourceAmount: 1000, DestinationAmount: 1000}, depth.Execution.Offers)
+	assert.Equal(t, 1, depth.Execution.OffersCount)
+	assert.Equal(t, Fill{SourceAmount: 500, DestinationAmount: 474}, depth.Execution.LiquidityPool)
+	assert.Equal(t, big.NewRat(1500, 1474), depth.Execution.Price)
+	assert.Equal(t, "176.39", depth.Execution.Slippage.FloatString(2))
+
+	require.NotNil(t, depth.OffersOnly)
+	assert.Equal(t, Fill{SourceAmount: 1500, DestinationAmount: 1250}, depth.OffersOnly.Fill)
+	assert.Equal(t, 2, depth.OffersOnly.OffersCount)
+	assert.Equal(t, Fill{}, depth.OffersOnly.LiquidityPool)
+	assert.Equal(t, "2000.00", depth.OffersOnly.Slippage.FloatString(2))
+
+	require.NotNil(t, depth.LiquidityPoolOnly)
+	assert.Equal(t, Fill{SourceAmount: 1500, DestinationAmount: 1300}, depth.LiquidityPoolOnly.Fill)
+	assert.Equal(t, Fill{}, depth.LiquidityPoolOnly.Offers)
+
+	// without pools
+	depth, _, err = graph.Depth(DepthQuery{
+		SourceAsset:      usdAsset,
+		DestinationAsset: nativeAsset,
+		SourceAmount:     1500,
+	}, false)
+	require.NoError(t, err)
+	assert.Nil(t, depth.LiquidityPool)
+	assert.Nil(t, depth.LiquidityPoolOnly)
+	assert.Equal(t, depth.OffersOnly, depth.Execution)
+}
+
+func TestDepthPartialFill(t *testing.T) {
+	graph := depthTestGraph(t, true, false)
+	depth, _, err := graph.Depth(DepthQuery{
+		SourceAsset:      usdAsset,
+		DestinationAsset: nativeAsset,
+		SourceAmount:     10000,
+	}, true)
+	require.NoError(t, err)
+	assert.Equal(t, Fill{SourceAmount: 3000, DestinationAmount: 2000}, depth.Execution.Fill)
+	assert.Equal(t, 2, depth.Execution.OffersCount)
+	assert.Nil(t, depth.LiquidityPoolOnly)
+}
+
+func TestDepthLevels(t *testing.T) {
+	graph := depthTestGraph(t, true, true)
+	depth, _, err := graph.Depth(DepthQuery{
+		SourceAsset:      usdAsset,
+		DestinationAsset: nativeAsset,
+		SlippageBips:     []uint32{0, 100, 10000},
+	}, true)
+	require.NoError(t, err)
+	assert.Nil(t, depth.Execution)
+	require.Len(t, depth.Levels, 3)
+
+	assert.Equal(t, uint32(0), depth.Levels[0].SlippageBips)
+	assert.Equal(t, big.NewRat(1, 1), depth.Levels[0].PriceLimit)
+	assert.Equal(t, Fill{SourceAmount: 1000, DestinationAmount: 1000}, depth.Levels[0].Fill)
+
+	assert.Equal(t, big.NewRat(101, 100), depth.Levels[1].PriceLimit)
+	assert.Equal(t, Fill{SourceAmount: 1000, DestinationAmount: 1000}, depth.Levels[1].Offers)
+	assert.Equal(t, Fill{SourceAmount: 34, DestinationAmount: 33}, depth.Levels[1].LiquidityPool)
+
+	// at twice the best price both offers are consumed and the pool is used
+	// until its marginal price reaches 2
+	assert.Equal(t, big.NewRat(2, 1), depth.Levels[2].PriceLimit)
+	assert.Equal(t, Fill{SourceAmount: 3000, DestinationAmount: 2000}, depth.Levels[2].Offers)
+	assert.Equal(t, 2, depth.Levels[2].OffersCount)
+	assert.Equal(t, xdr.Int64(4133), depth.Levels[2].LiquidityPool.SourceAmount)
+
+	for i := 1; i < len(depth.Levels); i++ {
+		assert.GreaterOrEqual(t, depth.Levels[i].DestinationAmount, depth.Levels[i-1].DestinationAmount)
+	}
+}
+
+func TestDepthPoolBestPrice(t *testing.T) {
+	graph := depthTestGraph(t, false, true)
+	depth, _, err := graph.Depth(DepthQuery{
+		SourceAsset:      nativeAsset,
+		DestinationAsset: usdAsset,
+		SlippageBips:     []uint32{50},
+		SourceAmount:     1000,
+	}, true)
+	require.NoError(t, err)
+	// X / ((1 - F) Y)
+	assert.Equal(t, big.NewRat(10000, 9970), depth.BestPrice)
+	assert.Nil(t, depth.OffersOnly)
+	assert.Equal(t, Fill{SourceAmount: 1000, DestinationAmount: 906}, depth.Execution.Fill)
+	assert.Equal(t, depth.Execution, depth.LiquidityPoolOnly)
+	require.Len(t, depth.Levels, 1)
+	assert.Equal(t, 0, depth.Levels[0].OffersCount)
+	assert.Equal(t, xdr.Int64(25), depth.Levels[0].LiquidityPool.SourceAmount)
+}
+
+func TestDepthNoLiquidity(t *testing.T) {
+	graph := depthTestGraph(t, true, false)
+	depth, _, err := graph.Depth(DepthQuery{
+		SourceAsset:      usdAsset,
+		DestinationAsset: eurAsset,
+		SlippageBips:     []uint32{50},
+		SourceAmount:     1000,
+	}, true)
+	require.NoError(t, err)
+	assert.Equal(t, Depth{}, depth)
+}
+
+func TestDepthInvalidQuery(t *testing.T) {
+	graph := depthTestGraph(t, true, true)
+	_, _, err := graph.Depth(DepthQuery{
+		SourceAsset:      usdAsset,
+		DestinationAsset: nativeAsset,
+		SlippageBips:     []uint32{10001},
+	}, true)
+	assert.EqualError(t, err, "slippage of 10001 bips exceeds 10000")
+
+	_, _, err = graph.Depth(DepthQuery{
+		SourceAsset:      usdAsset,
+		DestinationAsset: nativeAsset,
+		SourceAmount:     -1,
+	}, true)
+	assert.Equal(t, errBadAmount, err)
+}
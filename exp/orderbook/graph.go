@@ -3,6 +3,7 @@ package orderbook
 import (
 	"context"
 	"fmt"
+	"io"
 	"sort"
 	"sync"
 
@@ -46,6 +47,8 @@ type OBGraph interface {
 	RemoveLiquidityPool(pool xdr.LiquidityPoolEntry) OBGraph
 	Verify() ([]xdr.OfferEntry, []xdr.LiquidityPoolEntry, error)
 	Clear()
+	WriteSnapshot(w io.Writer) error
+	LoadSnapshot(r io.Reader) (uint32, error)
 }
 
 // OrderBookGraph is an in-memory graph representation of all the offers in the
@@ -0,0 +1,138 @@
+package orderbook
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+const snapshotVersion = 1
+
+var snapshotMagic = [4]byte{'O', 'B', 'G', 'S'}
+
+// snapshotHeader is the first part of a snapshot, it is followed by the XDR
+// encoded offers and then by the XDR encoded liquidity pools.
+type snapshotHeader struct {
+	Magic          [4]byte
+	Version        uint32
+	Ledger         uint32
+	Offers         uint32
+	LiquidityPools uint32
+}
+
+// WriteSnapshot writes all the offers and liquidity pools in the graph to w
+// in a gzip compressed binary format tagged with the ledger the graph is
+// accurate up to. The snapshot can be loaded back with LoadSnapshot.
+func (graph *OrderBookGraph) WriteSnapshot(w io.Writer) error {
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	if graph.lastLedger == 0 {
+		return errors.New("cannot snapshot an order book graph which was never applied")
+	}
+
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+	header := snapshotHeader{
+		Magic:          snapshotMagic,
+		Version:        snapshotVersion,
+		Ledger:         graph.lastLedger,
+		Offers:         uint32(len(graph.tradingPairForOffer)),
+		LiquidityPools: uint32(len(graph.liquidityPools)),
+	}
+	if err := binary.Write(bw, binary.BigEndian, header); err != nil {
+		return errors.Wrap(err, "could not write snapshot header")
+	}
+
+	for _, edges := range graph.venuesForSellingAsset {
+		for _, edge := range edges {
+			for _, offer := range edge.value.offers {
+				if _, err := xdr.Marshal(bw, offer); err != nil {
+					return errors.Wrapf(err, "could not write offer %v", offer.OfferId)
+				}
+			}
+		}
+	}
+	for _, pool := range graph.liquidityPools {
+		if _, err := xdr.Marshal(bw, pool); err != nil {
+			return errors.Wrap(err, "could not write liquidity pool")
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return errors.Wrap(err, "could not write snapshot")
+	}
+	return errors.Wrap(zw.Close(), "could not write snapshot")
+}
+
+// LoadSnapshot replaces the contents of the graph with the offers and
+// liquidity pools from a snapshot created by WriteSnapshot and returns the
+// ledger of the snapshot. The graph is left empty if the snapshot cannot be
+// read.
+func (graph *OrderBookGraph) LoadSnapshot(r io.Reader) (uint32, error) {
+	graph.Clear()
+	ledger, err := graph.loadSnapshot(r)
+	if err != nil {
+		graph.Clear()
+		return 0, err
+	}
+	return ledger, nil
+}
+
+func (graph *OrderBookGraph) loadSnapshot(r io.Reader) (uint32, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not open snapshot")
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+
+	var header snapshotHeader
+	if err = binary.Read(br, binary.BigEndian, &header); err != nil {
+		return 0, errors.Wrap(err, "could not read snapshot header")
+	}
+	if header.Magic != snapshotMagic {
+		return 0, errors.New("invalid snapshot header")
+	}
+	if header.Version != snapshotVersion {
+		return 0, errors.Errorf("unsupported snapshot version %d", header.Version)
+	}
+	if header.Ledger == 0 {
+		return 0, errors.New("invalid snapshot ledger")
+	}
+
+	defer graph.Discard()
+	for i := uint32(0); i < header.Offers; i++ {
+		var offer xdr.OfferEntry
+		if _, err = xdr.Unmarshal(br, &offer); err != nil {
+			return 0, errors.Wrap(err, "could not read offer")
+		}
+		graph.AddOffers(offer)
+	}
+	for i := uint32(0); i < header.LiquidityPools; i++ {
+		var pool xdr.LiquidityPoolEntry
+		if _, err = xdr.Unmarshal(br, &pool); err != nil {
+			return 0, errors.Wrap(err, "could not read liquidity pool")
+		}
+		if pool.Body.ConstantProduct == nil {
+			return 0, errors.New("invalid liquidity pool in snapshot")
+		}
+		graph.AddLiquidityPools(pool)
+	}
+	// reading until the end of the stream verifies the gzip checksum
+	if _, err = br.ReadByte(); err != io.EOF {
+		if err == nil {
+			err = errors.New("unexpected data after the liquidity pools")
+		}
+		return 0, errors.Wrap(err, "could not read end of snapshot")
+	}
+
+	if err = graph.Apply(header.Ledger); err != nil {
+		return 0, errors.Wrap(err, "could not apply snapshot")
+	}
+	return header.Ledger, nil
+}
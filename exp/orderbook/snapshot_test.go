@@ -0,0 +1,132 @@
+package orderbook
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/xdr"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	graph := NewOrderBookGraph()
+	graph.AddOffers(fiftyCentsOffer, quarterOffer, dollarOffer, eurOffer, twoEurOffer, threeEurOffer)
+	graph.AddLiquidityPools(eurUsdLiquidityPool, usdChfLiquidityPool, nativeEurPool)
+	require.NoError(t, graph.Apply(42))
+
+	var buf bytes.Buffer
+	require.NoError(t, graph.WriteSnapshot(&buf))
+
+	loaded := NewOrderBookGraph()
+	// the snapshot replaces any existing content
+	loaded.AddOffers(xdr.OfferEntry{
+		OfferId: 1000,
+		Buying:  chfAsset,
+		Selling: yenAsset,
+		Price:   xdr.Price{N: 1, D: 1},
+		Amount:  100,
+	})
+	require.NoError(t, loaded.Apply(100))
+
+	ledger, err := loaded.LoadSnapshot(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(42), ledger)
+	assert.Equal(t, uint32(42), loaded.LastLedger())
+	assertGraphEquals(t, graph, loaded)
+
+	_, _, err = loaded.Verify()
+	require.NoError(t, err)
+
+	// the loaded graph accepts updates following the snapshot ledger
+	loaded.RemoveOffer(eurOffer.OfferId)
+	require.NoError(t, loaded.Apply(43))
+	assert.Len(t, loaded.Offers(), 5)
+}
+
+func TestSnapshotEmptyGraph(t *testing.T) {
+	graph := NewOrderBookGraph()
+	assert.EqualError(t, graph.WriteSnapshot(&bytes.Buffer{}),
+		"cannot snapshot an order book graph which was never applied")
+
+	require.NoError(t, graph.Apply(7))
+	var buf bytes.Buffer
+	require.NoError(t, graph.WriteSnapshot(&buf))
+
+	loaded := NewOrderBookGraph()
+	ledger, err := loaded.LoadSnapshot(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(7), ledger)
+	assert.True(t, loaded.IsEmpty())
+}
+
+func TestSnapshotInvalid(t *testing.T) {
+	graph := NewOrderBookGraph()
+	graph.AddOffers(fiftyCentsOffer, quarterOffer, dollarOffer)
+	graph.AddLiquidityPools(nativeEurPool)
+	require.NoError(t, graph.Apply(42))
+
+	var buf bytes.Buffer
+	require.NoError(t, graph.WriteSnapshot(&buf))
+	snapshot := buf.Bytes()
+
+	for _, testCase := range []struct {
+		name     string
+		snapshot []byte
+		err      string
+	}{
+		{
+			name:     "not gzip",
+			snapshot: []byte("not a snapshot"),
+			err:      "could not open snapshot: gzip: invalid header",
+		},
+		{
+			name:     "truncated",
+			snapshot: snapshot[:len(snapshot)-10],
+			err:      "could not read end of snapshot: unexpected EOF",
+		},
+		{
+			name:     "short header",
+			snapshot: compress(t, []byte("ABCD")),
+			err:      "could not read snapshot header: unexpected EOF",
+		},
+		{
+			name:     "invalid header",
+			snapshot: compress(t, make([]byte, 20)),
+			err:      "invalid snapshot header",
+		},
+		{
+			name:     "unsupported version",
+			snapshot: compress(t, []byte{'O', 'B', 'G', 'S', 0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0}),
+			err:      "unsupported snapshot version 2",
+		},
+		{
+			name:     "missing offers",
+			snapshot: compress(t, []byte{'O', 'B', 'G', 'S', 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 0}),
+			err: "could not read offer: decoding AccountId: decoding PublicKey: decoding PublicKeyType: " +
+				"decoding PublicKeyType: xdr:DecodeInt: EOF while decoding 4 bytes - read: '[]'",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			loaded := NewOrderBookGraph()
+			loaded.AddOffers(eurOffer)
+			require.NoError(t, loaded.Apply(1))
+
+			_, err := loaded.LoadSnapshot(bytes.NewReader(testCase.snapshot))
+			assert.EqualError(t, err, testCase.err)
+			assert.True(t, loaded.IsEmpty())
+			assert.Equal(t, uint32(0), loaded.LastLedger())
+		})
+	}
+}
+
+func compress(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
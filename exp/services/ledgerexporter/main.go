@@ -12,6 +12,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/hcnet/go/ingest/ledgerbackend"
+	"github.com/hcnet/go/metaarchive"
 	"github.com/hcnet/go/network"
 	supportlog "github.com/hcnet/go/support/log"
 	"github.com/hcnet/go/support/storage"
@@ -21,7 +22,7 @@ import (
 var logger = supportlog.New()
 
 func main() {
-	targetUrl := flag.String("target", "gcs://aurora-archive-poc", "history archive url to write txmeta files")
+	targetUrl := flag.String("target", "gcs://aurora-archive-poc", "history archive url to write txmeta files, use multi://?backend=<url>&backend=<url> to replicate them to several storages")
 	hcnetCoreBinaryPath := flag.String("hcnet-core-binary-path", os.Getenv("HCNET_CORE_BINARY_PATH"), "path to the hcnet core binary")
 	networkPassphrase := flag.String("network-passphrase", network.TestNetworkPassphrase, "network passphrase")
 	historyArchiveUrls := flag.String("history-archive-urls", "https://history.hcnet.org/prd/core-testnet/core_testnet_001", "comma-separated list of history archive urls to read from")
@@ -31,10 +32,16 @@ func main() {
 	endingLedger := flag.Uint("end-ledger", 0, "ledger at which to stop the export (must be a closed ledger), 0 means no ending")
 	writeLatestPath := flag.Bool("write-latest-path", true, "update the value of the /latest path on the target")
 	captiveCoreUseDb := flag.Bool("captive-core-use-db", true, "configure captive core to store database on disk in working directory rather than in memory")
+	ledgersPerBatch := flag.Uint("ledgers-per-batch", metaarchive.DefaultLedgersPerBatch, "number of ledgers stored in each batch file, metaarchive readers (ex. lighthorizon) expect the default")
 	flag.Parse()
 
 	logger.SetLevel(supportlog.InfoLevel)
 
+	if *ledgersPerBatch == 0 {
+		logger.Fatalf("-ledgers-per-batch must be > 0")
+	}
+	batchConfig := metaarchive.BatchConfig{LedgersPerBatch: uint32(*ledgersPerBatch)}
+
 	params := ledgerbackend.CaptiveCoreTomlParams{
 		NetworkPassphrase:  *networkPassphrase,
 		HistoryArchiveURLs: strings.Split(*historyArchiveUrls, ","),
@@ -78,13 +85,23 @@ func main() {
 		if startLedger != 0 {
 			logger.Fatalf("-start-ledger and -continue cannot both be set")
 		}
-		startLedger = readLatestLedger(target)
+		startLedger = readLatestLedger(target) + 1
 		logger.Infof("continue flag was enabled, next ledger found was %v", startLedger)
 	}
 
 	if startLedger < 2 {
 		logger.Fatalf("-start-ledger must be >= 2")
 	}
+	// Batches are written whole, so export from the first ledger of the
+	// batch. Otherwise the first write would replace the batch (which may
+	// already be complete, or partial when continuing) with fewer ledgers.
+	if batchStart := batchConfig.BatchStart(startLedger); batchStart != startLedger {
+		startLedger = batchStart
+		if startLedger < 2 {
+			startLedger = 2
+		}
+		logger.Infof("exporting from %v, the first ledger of its batch", startLedger)
+	}
 	if endLedger != 0 && endLedger < startLedger {
 		logger.Fatalf("-end-ledger must be >= -start-ledger")
 	}
@@ -100,6 +117,7 @@ func main() {
 	err = core.PrepareRange(context.Background(), ledgerRange)
 	logFatalIf(err, "could not prepare range")
 
+	var batch []xdr.LedgerCloseMeta
 	for nextLedger := startLedger; endLedger < 1 || nextLedger <= endLedger; {
 		ledger, err := core.GetLedger(context.Background(), nextLedger)
 		if err != nil {
@@ -107,17 +125,26 @@ func main() {
 			time.Sleep(time.Second)
 			continue
 		}
+		batch = append(batch, ledger)
+
+		// Flush the batch once it's complete, or when reaching the end of a
+		// bounded range (the partial batch is completed by a later run).
+		if nextLedger == batchConfig.BatchEnd(nextLedger) || nextLedger == endLedger {
+			for {
+				if err = metaarchive.WriteLedgerBatch(target, batchConfig, batch); err == nil {
+					break
+				}
+				logger.WithError(err).Warnf(
+					"could not write ledger batch %v, retrying",
+					batchConfig.BatchPath(nextLedger))
+				time.Sleep(time.Second)
+			}
+			batch = nil
 
-		if err = writeLedger(target, ledger); err != nil {
-			logger.WithError(err).Warnf(
-				"could not write ledger object %v, retrying",
-				uint64(ledger.LedgerSequence()))
-			continue
-		}
-
-		if *writeLatestPath {
-			if err = writeLatestLedger(target, nextLedger); err != nil {
-				logger.WithError(err).Warnf("could not write latest ledger %v", nextLedger)
+			if *writeLatestPath {
+				if err = writeLatestLedger(target, nextLedger); err != nil {
+					logger.WithError(err).Warnf("could not write latest ledger %v", nextLedger)
+				}
 			}
 		}
 
@@ -146,22 +173,6 @@ func readLatestLedger(backend storage.Storage) uint32 {
 	return uint32(parsed)
 }
 
-// writeLedger stores the given LedgerCloseMeta instance as a raw binary at the
-// /ledgers/<seqNum> path. If an error is returned, it may be transient so you
-// should attempt to retry.
-func writeLedger(backend storage.Storage, ledger xdr.LedgerCloseMeta) error {
-	toSerialize := xdr.SerializedLedgerCloseMeta{
-		V:  0,
-		V0: &ledger,
-	}
-	blob, err := toSerialize.MarshalBinary()
-	logFatalIf(err, "could not serialize ledger %v", ledger.LedgerSequence())
-	return backend.PutFile(
-		"ledgers/"+strconv.FormatUint(uint64(ledger.LedgerSequence()), 10),
-		io.NopCloser(bytes.NewReader(blob)),
-	)
-}
-
 func writeLatestLedger(backend storage.Storage, ledger uint32) error {
 	return backend.PutFile(
 		"latest",
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/sha256"
+	"io"
+
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+type changeType string
+
+const (
+	created changeType = "created"
+	updated changeType = "updated"
+	removed changeType = "removed"
+)
+
+// stateChange is a ledger entry which differs between the two checkpoints.
+// Pre is nil for created entries and Post is nil for removed entries.
+type stateChange struct {
+	Type changeType
+	Key  xdr.LedgerKey
+	Pre  *xdr.LedgerEntry
+	Post *xdr.LedgerEntry
+}
+
+// openReader returns a reader streaming the state of a checkpoint ledger.
+type openReader func(sequence uint32) (ingest.ChangeReader, error)
+
+// differ finds the entries which differ between two checkpoints.
+//
+// Buckets are not sorted across the bucket list, so the two states cannot be
+// merged as they are streamed. Instead the hash of every entry of the first
+// checkpoint is kept in memory while the second checkpoint is streamed, and
+// the first checkpoint is streamed a second time to emit the previous
+// version of the updated and removed entries. Memory usage is proportional to
+// the number of entries of the first checkpoint plus the number of updated
+// entries.
+type differ struct {
+	open openReader
+	from uint32
+	to   uint32
+}
+
+func (d differ) forEach(sequence uint32, fn func(id string, key xdr.LedgerKey, entry xdr.LedgerEntry) error) error {
+	reader, err := d.open(sequence)
+	if err != nil {
+		return errors.Wrapf(err, "could not open checkpoint %d", sequence)
+	}
+	defer reader.Close()
+
+	for {
+		change, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "could not read checkpoint %d", sequence)
+		}
+		key, err := change.Post.LedgerKey()
+		if err != nil {
+			return errors.Wrap(err, "could not get ledger key")
+		}
+		b, err := key.MarshalBinary()
+		if err != nil {
+			return errors.Wrap(err, "could not marshal ledger key")
+		}
+		if err = fn(string(b), key, *change.Post); err != nil {
+			return err
+		}
+	}
+}
+
+func entryHash(entry xdr.LedgerEntry) ([sha256.Size]byte, error) {
+	b, err := entry.MarshalBinary()
+	if err != nil {
+		return [sha256.Size]byte{}, errors.Wrap(err, "could not marshal ledger entry")
+	}
+	return sha256.Sum256(b), nil
+}
+
+// run calls emit for every entry created, updated or removed between the two
+// checkpoints. Created entries are emitted while the second checkpoint is
+// streamed, updated and removed entries afterwards.
+func (d differ) run(emit func(stateChange) error) error {
+	if d.from >= d.to {
+		return errors.Errorf("checkpoint %d is not before checkpoint %d", d.from, d.to)
+	}
+
+	hashes := map[string][sha256.Size]byte{}
+	err := d.forEach(d.from, func(id string, _ xdr.LedgerKey, entry xdr.LedgerEntry) error {
+		hash, err := entryHash(entry)
+		hashes[id] = hash
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// after the second checkpoint is streamed hashes only holds the removed
+	// entries
+	updatedEntries := map[string]xdr.LedgerEntry{}
+	err = d.forEach(d.to, func(id string, key xdr.LedgerKey, entry xdr.LedgerEntry) error {
+		previous, ok := hashes[id]
+		if !ok {
+			return emit(stateChange{Type: created, Key: key, Post: &entry})
+		}
+		delete(hashes, id)
+		hash, err := entryHash(entry)
+		if err != nil {
+			return err
+		}
+		if hash != previous {
+			updatedEntries[id] = entry
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(hashes) == 0 && len(updatedEntries) == 0 {
+		return nil
+	}
+	return d.forEach(d.from, func(id string, key xdr.LedgerKey, entry xdr.LedgerEntry) error {
+		if post, ok := updatedEntries[id]; ok {
+			return emit(stateChange{Type: updated, Key: key, Pre: &entry, Post: &post})
+		}
+		if _, ok := hashes[id]; ok {
+			return emit(stateChange{Type: removed, Key: key, Pre: &entry})
+		}
+		return nil
+	})
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/xdr"
+)
+
+type sliceReader struct {
+	entries []xdr.LedgerEntry
+}
+
+func (r *sliceReader) Read() (ingest.Change, error) {
+	if len(r.entries) == 0 {
+		return ingest.Change{}, io.EOF
+	}
+	entry := r.entries[0]
+	r.entries = r.entries[1:]
+	return ingest.Change{Type: entry.Data.Type, Post: &entry}, nil
+}
+
+func (r *sliceReader) Close() error {
+	return nil
+}
+
+func account(address string, balance xdr.Int64) xdr.LedgerEntry {
+	return xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type:    xdr.LedgerEntryTypeAccount,
+		Account: &xdr.AccountEntry{AccountId: xdr.MustAddress(address), Balance: balance},
+	}}
+}
+
+func trustLine(address string, balance xdr.Int64) xdr.LedgerEntry {
+	return xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeTrustline,
+		TrustLine: &xdr.TrustLineEntry{
+			AccountId: xdr.MustAddress(address),
+			Asset:     xdr.MustNewCreditAsset("USD", address).ToTrustLineAsset(),
+			Balance:   balance,
+			Limit:     1000,
+		},
+	}}
+}
+
+func testDiffer(t *testing.T, from, to []xdr.LedgerEntry) differ {
+	states := map[uint32][]xdr.LedgerEntry{63: from, 127: to}
+	return differ{
+		open: func(sequence uint32) (ingest.ChangeReader, error) {
+			entries, ok := states[sequence]
+			require.True(t, ok)
+			return &sliceReader{entries: entries}, nil
+		},
+		from: 63,
+		to:   127,
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := keypair.MustRandom().Address()
+	b := keypair.MustRandom().Address()
+	c := keypair.MustRandom().Address()
+
+	d := testDiffer(t,
+		[]xdr.LedgerEntry{account(a, 10), account(b, 20), trustLine(b, 5)},
+		[]xdr.LedgerEntry{account(c, 30), trustLine(b, 5), account(a, 15)},
+	)
+	changes := map[changeType][]stateChange{}
+	require.NoError(t, d.run(func(change stateChange) error {
+		changes[change.Type] = append(changes[change.Type], change)
+		return nil
+	}))
+
+	require.Len(t, changes[created], 1)
+	assert.Nil(t, changes[created][0].Pre)
+	assert.Equal(t, c, changes[created][0].Post.Data.MustAccount().AccountId.Address())
+
+	require.Len(t, changes[updated], 1)
+	assert.Equal(t, xdr.Int64(10), changes[updated][0].Pre.Data.MustAccount().Balance)
+	assert.Equal(t, xdr.Int64(15), changes[updated][0].Post.Data.MustAccount().Balance)
+
+	require.Len(t, changes[removed], 1)
+	assert.Equal(t, b, changes[removed][0].Pre.Data.MustAccount().AccountId.Address())
+	assert.Nil(t, changes[removed][0].Post)
+}
+
+func TestDiffOrder(t *testing.T) {
+	d := testDiffer(t, nil, nil)
+	d.from, d.to = d.to, d.from
+	assert.EqualError(t, d.run(func(stateChange) error { return nil }), "checkpoint 127 is not before checkpoint 63")
+}
+
+func TestOutputs(t *testing.T) {
+	a := keypair.MustRandom().Address()
+	b := keypair.MustRandom().Address()
+	d := testDiffer(t,
+		[]xdr.LedgerEntry{account(a, 10), trustLine(a, 1)},
+		[]xdr.LedgerEntry{account(a, 11), trustLine(a, 2), account(b, 1)},
+	)
+
+	var out bytes.Buffer
+	writer := newJSONLinesWriter(&out)
+	s := newSummary()
+	require.NoError(t, d.run(func(change stateChange) error {
+		s.add(change)
+		return writer.write(change)
+	}))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	var line jsonChange
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	assert.Equal(t, created, line.Change)
+	assert.Equal(t, "account", line.EntryType)
+	assert.Equal(t, b, line.Account)
+	assert.Empty(t, line.Pre)
+	var post xdr.LedgerEntry
+	require.NoError(t, xdr.SafeUnmarshalBase64(line.Post, &post))
+	assert.Equal(t, b, post.Data.MustAccount().AccountId.Address())
+
+	out.Reset()
+	require.NoError(t, s.write(&out, 1))
+	assert.Equal(t, strings.Join([]string{
+		"ENTRY TYPE  CREATED  UPDATED  REMOVED  TOTAL",
+		"account     1        1        0        2",
+		"trustline   0        1        0        1",
+		"total       1        2        0        3",
+		"",
+		"ACCOUNT                                                   CREATED  UPDATED  REMOVED  TOTAL",
+		a + "  0        2        0        2",
+		"",
+	}, "\n"), out.String())
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hcnet/go/historyarchive"
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+	"github.com/hcnet/go/support/storage"
+)
+
+const (
+	formatJSONLines = "jsonl"
+	formatSummary   = "summary"
+)
+
+func main() {
+	archiveURL := flag.String("archive-url", "https://history.hcnet.org/prd/core-live/core_live_001/", "history archive url")
+	checkpointFrequency := flag.Uint("checkpoint-frequency", uint(historyarchive.DefaultCheckpointFrequency), "checkpoint frequency of the history archive")
+	from := flag.Uint("from", 0, "first checkpoint ledger (required)")
+	to := flag.Uint("to", 0, "second checkpoint ledger (required)")
+	format := flag.String("format", formatSummary, "output format: `jsonl` for a JSON line per changed entry or `summary` for the number of changes by entry type and account")
+	top := flag.Int("top", 20, "number of accounts with the most changes printed in the summary, 0 prints all the accounts")
+	output := flag.String("output", "", "output file, the standard output if empty")
+	flag.Parse()
+
+	if *from == 0 || *to == 0 || (*format != formatJSONLines && *format != formatSummary) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	// the output can be piped, keep the logs out of it
+	log.SetOut(os.Stderr)
+	log.SetLevel(log.InfoLevel)
+
+	if err := run(*archiveURL, uint32(*checkpointFrequency), uint32(*from), uint32(*to), *format, *top, *output); err != nil {
+		log.WithField("err", err).Fatal("could not diff ledger state")
+	}
+}
+
+func run(archiveURL string, checkpointFrequency, from, to uint32, format string, top int, output string) error {
+	archive, err := historyarchive.Connect(archiveURL, historyarchive.ArchiveOptions{
+		CheckpointFrequency: checkpointFrequency,
+		ConnectOptions: storage.ConnectOptions{
+			UserAgent: "diff-ledger-state",
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not connect to history archive")
+	}
+
+	var out io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return errors.Wrapf(err, "could not create %s", output)
+		}
+		defer file.Close()
+		out = file
+	}
+	buffered := bufio.NewWriter(out)
+
+	d := differ{
+		open: func(sequence uint32) (ingest.ChangeReader, error) {
+			log.WithField("ledger", sequence).Info("Streaming checkpoint state")
+			return ingest.NewCheckpointChangeReader(context.Background(), archive, sequence)
+		},
+		from: from,
+		to:   to,
+	}
+
+	switch format {
+	case formatJSONLines:
+		writer := newJSONLinesWriter(buffered)
+		err = d.run(writer.write)
+	case formatSummary:
+		s := newSummary()
+		err = d.run(func(change stateChange) error {
+			s.add(change)
+			return nil
+		})
+		if err == nil {
+			fmt.Fprintf(buffered, "Changes between checkpoints %d and %d\n\n", from, to)
+			err = s.write(buffered, top)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(buffered.Flush(), "could not write output")
+}
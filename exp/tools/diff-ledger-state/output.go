@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+var entryTypeNames = map[xdr.LedgerEntryType]string{
+	xdr.LedgerEntryTypeAccount:          "account",
+	xdr.LedgerEntryTypeTrustline:        "trustline",
+	xdr.LedgerEntryTypeOffer:            "offer",
+	xdr.LedgerEntryTypeData:             "data",
+	xdr.LedgerEntryTypeClaimableBalance: "claimable_balance",
+	xdr.LedgerEntryTypeLiquidityPool:    "liquidity_pool",
+	xdr.LedgerEntryTypeContractData:     "contract_data",
+	xdr.LedgerEntryTypeContractCode:     "contract_code",
+	xdr.LedgerEntryTypeConfigSetting:    "config_setting",
+	xdr.LedgerEntryTypeTtl:              "ttl",
+}
+
+func entryTypeName(typ xdr.LedgerEntryType) string {
+	if name, ok := entryTypeNames[typ]; ok {
+		return name
+	}
+	return typ.String()
+}
+
+// accountOf returns the account owning the entry, or an empty string for
+// entries which do not belong to an account.
+func accountOf(key xdr.LedgerKey) string {
+	switch key.Type {
+	case xdr.LedgerEntryTypeAccount:
+		return key.Account.AccountId.Address()
+	case xdr.LedgerEntryTypeTrustline:
+		return key.TrustLine.AccountId.Address()
+	case xdr.LedgerEntryTypeOffer:
+		return key.Offer.SellerId.Address()
+	case xdr.LedgerEntryTypeData:
+		return key.Data.AccountId.Address()
+	}
+	return ""
+}
+
+// jsonChange is a line of the jsonl output. The key and the entries are
+// base64 encoded XDR.
+type jsonChange struct {
+	Change    changeType `json:"change"`
+	EntryType string     `json:"entry_type"`
+	Account   string     `json:"account,omitempty"`
+	Key       string     `json:"key"`
+	Pre       string     `json:"pre,omitempty"`
+	Post      string     `json:"post,omitempty"`
+}
+
+type jsonLinesWriter struct {
+	encoder *json.Encoder
+}
+
+func newJSONLinesWriter(out io.Writer) jsonLinesWriter {
+	return jsonLinesWriter{encoder: json.NewEncoder(out)}
+}
+
+func (w jsonLinesWriter) write(change stateChange) error {
+	line := jsonChange{
+		Change:    change.Type,
+		EntryType: entryTypeName(change.Key.Type),
+		Account:   accountOf(change.Key),
+	}
+	var err error
+	if line.Key, err = xdr.MarshalBase64(change.Key); err != nil {
+		return errors.Wrap(err, "could not marshal ledger key")
+	}
+	if change.Pre != nil {
+		if line.Pre, err = xdr.MarshalBase64(change.Pre); err != nil {
+			return errors.Wrap(err, "could not marshal ledger entry")
+		}
+	}
+	if change.Post != nil {
+		if line.Post, err = xdr.MarshalBase64(change.Post); err != nil {
+			return errors.Wrap(err, "could not marshal ledger entry")
+		}
+	}
+	return w.encoder.Encode(line)
+}
+
+type counts struct {
+	created int
+	updated int
+	removed int
+}
+
+func (c *counts) add(typ changeType) {
+	switch typ {
+	case created:
+		c.created++
+	case updated:
+		c.updated++
+	case removed:
+		c.removed++
+	}
+}
+
+func (c counts) total() int {
+	return c.created + c.updated + c.removed
+}
+
+// summary counts the changes by entry type and by account.
+type summary struct {
+	byType    map[xdr.LedgerEntryType]*counts
+	byAccount map[string]*counts
+	all       counts
+}
+
+func newSummary() *summary {
+	return &summary{
+		byType:    map[xdr.LedgerEntryType]*counts{},
+		byAccount: map[string]*counts{},
+	}
+}
+
+func (s *summary) add(change stateChange) {
+	s.all.add(change.Type)
+
+	typeCounts, ok := s.byType[change.Key.Type]
+	if !ok {
+		typeCounts = &counts{}
+		s.byType[change.Key.Type] = typeCounts
+	}
+	typeCounts.add(change.Type)
+
+	if account := accountOf(change.Key); account != "" {
+		accountCounts, ok := s.byAccount[account]
+		if !ok {
+			accountCounts = &counts{}
+			s.byAccount[account] = accountCounts
+		}
+		accountCounts.add(change.Type)
+	}
+}
+
+// write prints the counts by entry type followed by the top accounts with the
+// most changes. All the accounts are printed if top is 0.
+func (s *summary) write(out io.Writer, top int) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	types := make([]xdr.LedgerEntryType, 0, len(s.byType))
+	for typ := range s.byType {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	fmt.Fprintln(w, "ENTRY TYPE\tCREATED\tUPDATED\tREMOVED\tTOTAL")
+	for _, typ := range types {
+		c := s.byType[typ]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", entryTypeName(typ), c.created, c.updated, c.removed, c.total())
+	}
+	fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", "total", s.all.created, s.all.updated, s.all.removed, s.all.total())
+
+	accounts := make([]string, 0, len(s.byAccount))
+	for account := range s.byAccount {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		ti, tj := s.byAccount[accounts[i]].total(), s.byAccount[accounts[j]].total()
+		if ti != tj {
+			return ti > tj
+		}
+		return accounts[i] < accounts[j]
+	})
+	if top > 0 && len(accounts) > top {
+		accounts = accounts[:top]
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "ACCOUNT\tCREATED\tUPDATED\tREMOVED\tTOTAL")
+	for _, account := range accounts {
+		c := s.byAccount[account]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", account, c.created, c.updated, c.removed, c.total())
+	}
+	return w.Flush()
+}
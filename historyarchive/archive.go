@@ -36,6 +36,21 @@ type CommandOptions struct {
 	Verify       bool
 	Thorough     bool
 	SkipOptional bool
+
+	// SinceLastRun limits Mirror to the checkpoints newer than the root HAS
+	// of the destination archive.
+	SinceLastRun bool
+	// Progress, when set, persists the progress of Mirror and Repair so an
+	// interrupted run resumes where it left off.
+	Progress ProgressStore
+	// MaxFilesPerSecond limits the number of files copied per second by
+	// Mirror and Repair, 0 means no limit.
+	MaxFilesPerSecond float64
+	// MaxBytesPerSecond limits the bandwidth used by Mirror and Repair to
+	// copy files, 0 means no limit.
+	MaxBytesPerSecond int64
+
+	throttle *throttle
 }
 
 type ArchiveOptions struct {
@@ -13,7 +13,9 @@ import (
 	"github.com/hcnet/go/support/errors"
 )
 
-// Mirror mirrors an archive, it assumes that the source and destination have the same checkpoint ledger frequency
+// Mirror mirrors an archive, it assumes that the source and destination have the same checkpoint ledger frequency.
+// When opts.SinceLastRun is set only the checkpoints newer than the root HAS of the destination are copied and when
+// opts.Progress is set the mirror resumes from the progress saved by an interrupted run.
 func Mirror(src *Archive, dst *Archive, opts *CommandOptions) error {
 	rootHAS, e := src.GetRootHAS()
 	if e != nil {
@@ -21,6 +23,38 @@ func Mirror(src *Archive, dst *Archive, opts *CommandOptions) error {
 	}
 
 	opts.Range = opts.Range.clamp(rootHAS.Range(), src.checkpointManager)
+	opts.throttle = newThrottle(opts)
+
+	if opts.SinceLastRun {
+		exists, err := dst.backend.Exists(rootHASPath)
+		if err != nil {
+			return err
+		}
+		if exists {
+			dstHAS, err := dst.GetRootHAS()
+			if err != nil {
+				return err
+			}
+			if opts.Range.Low <= dstHAS.CurrentLedger {
+				opts.Range.Low = src.checkpointManager.NextCheckpoint(dstHAS.CurrentLedger + 1)
+			}
+		}
+	}
+
+	var progress *progressTracker
+	if opts.Progress != nil {
+		var err error
+		progress, err = newProgressTracker(opts.Progress, "mirror", opts.Range, src.checkpointManager)
+		if err != nil {
+			return err
+		}
+		opts.Range.Low = progress.next()
+	}
+
+	if opts.Range.Low > opts.Range.High {
+		log.Printf("destination archive is up to date")
+		return nil
+	}
 
 	log.Printf("copying range %s\n", opts.Range)
 
@@ -56,6 +90,9 @@ func Mirror(src *Archive, dst *Archive, opts *CommandOptions) error {
 					continue
 				}
 
+				// the checkpoint is only complete if all its files were copied
+				var checkpointErrs uint32
+
 				buckets, err := has.Buckets()
 				if err != nil {
 					panic(errors.Wrap(err, "error getting buckets"))
@@ -72,7 +109,7 @@ func Mirror(src *Archive, dst *Archive, opts *CommandOptions) error {
 					if !alreadyFetching {
 						pth := BucketPath(bucket)
 						err = copyPath(src, dst, pth, opts)
-						atomic.AddUint32(&errs, noteError(err))
+						checkpointErrs += noteError(err)
 					}
 				}
 
@@ -85,7 +122,11 @@ func Mirror(src *Archive, dst *Archive, opts *CommandOptions) error {
 					if err != nil && !categoryRequired(cat) {
 						continue
 					}
-					atomic.AddUint32(&errs, noteError(err))
+					checkpointErrs += noteError(err)
+				}
+				atomic.AddUint32(&errs, checkpointErrs)
+				if progress != nil && checkpointErrs == 0 && !opts.DryRun {
+					progress.complete(ix)
 				}
 				tick <- true
 			}
@@ -97,6 +138,9 @@ func Mirror(src *Archive, dst *Archive, opts *CommandOptions) error {
 	log.Printf("copied %d checkpoints, %d buckets, range %s",
 		opts.Range.SizeInCheckPoints(src.checkpointManager), len(bucketFetch), opts.Range)
 	close(tick)
+	if progress != nil && !opts.DryRun {
+		errs += noteError(progress.save())
+	}
 	if rootHAS.CurrentLedger == opts.Range.High {
 		log.Printf("updating destination archive current-ledger pointer to 0x%8.8x",
 			rootHAS.CurrentLedger)
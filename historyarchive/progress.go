@@ -0,0 +1,218 @@
+// Copyright 2016 Hcnet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// ProgressStatePath is the path of the progress state file when it is stored
+// in the destination archive.
+const ProgressStatePath = ".archivist-progress.json"
+
+// progressSaveInterval is the minimum time between two saves of the progress
+// state during a run.
+const progressSaveInterval = 10 * time.Second
+
+// ProgressState records how far a mirror or repair got so an interrupted run
+// can resume where it left off.
+type ProgressState struct {
+	// Command is the command which recorded the progress, "mirror" or
+	// "repair".
+	Command string `json:"command"`
+	// Low is the first checkpoint of the range requested by the run.
+	Low uint32 `json:"low"`
+	// NextCheckpoint is the first checkpoint which hasn't been completed: all
+	// the checkpoints from Low to the one preceding NextCheckpoint have been
+	// processed without errors.
+	NextCheckpoint uint32    `json:"next_checkpoint"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ProgressStore loads and saves the progress state of a mirror or repair.
+type ProgressStore interface {
+	// Load returns the saved progress state, or false if there is none.
+	Load() (ProgressState, bool, error)
+	Save(state ProgressState) error
+}
+
+// FileProgressStore stores the progress state in a local file.
+type FileProgressStore struct {
+	Path string
+}
+
+// NewFileProgressStore returns a ProgressStore which stores the progress
+// state in the local file at path.
+func NewFileProgressStore(path string) *FileProgressStore {
+	return &FileProgressStore{Path: path}
+}
+
+func (s *FileProgressStore) Load() (ProgressState, bool, error) {
+	var state ProgressState
+	buf, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return state, false, nil
+	} else if err != nil {
+		return state, false, errors.Wrapf(err, "could not read %s", s.Path)
+	}
+	if err = json.Unmarshal(buf, &state); err != nil {
+		return state, false, errors.Wrapf(err, "could not decode %s", s.Path)
+	}
+	return state, true, nil
+}
+
+func (s *FileProgressStore) Save(state ProgressState) error {
+	buf, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so an interruption doesn't leave a
+	// truncated state behind
+	tmp, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return errors.Wrap(err, "could not create temporary state file")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(buf); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "could not write %s", tmp.Name())
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrapf(err, "could not write %s", tmp.Name())
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// ArchiveProgressStore stores the progress state at ProgressStatePath in an
+// archive, usually the destination of the mirror or repair.
+type ArchiveProgressStore struct {
+	archive *Archive
+}
+
+// NewArchiveProgressStore returns a ProgressStore which stores the progress
+// state in the given archive.
+func NewArchiveProgressStore(archive *Archive) *ArchiveProgressStore {
+	return &ArchiveProgressStore{archive: archive}
+}
+
+func (s *ArchiveProgressStore) Load() (ProgressState, bool, error) {
+	var state ProgressState
+	exists, err := s.archive.backend.Exists(ProgressStatePath)
+	if err != nil || !exists {
+		return state, false, err
+	}
+
+	rdr, err := s.archive.backend.GetFile(ProgressStatePath)
+	if err != nil {
+		return state, false, err
+	}
+	defer rdr.Close()
+	if err = json.NewDecoder(rdr).Decode(&state); err != nil {
+		return state, false, errors.Wrapf(err, "could not decode %s", ProgressStatePath)
+	}
+	return state, true, nil
+}
+
+func (s *ArchiveProgressStore) Save(state ProgressState) error {
+	buf, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.archive.backend.PutFile(ProgressStatePath, ioutil.NopCloser(bytes.NewReader(buf)))
+}
+
+// progressTracker advances the progress state as checkpoints complete, in
+// any order, and periodically saves it.
+type progressTracker struct {
+	mutex     sync.Mutex
+	store     ProgressStore
+	state     ProgressState
+	completed map[uint32]bool
+	freq      uint32
+	lastSave  time.Time
+}
+
+// newProgressTracker loads the progress of the previous run of the command.
+// The run is resumed if it requested a range starting at the same checkpoint
+// and stopped before the end of rng, otherwise the progress starts over from
+// the beginning of rng.
+func newProgressTracker(store ProgressStore, command string, rng Range, cManager CheckpointManager) (*progressTracker, error) {
+	state, found, err := store.Load()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load progress state")
+	}
+
+	if found && state.Command == command && state.Low == rng.Low &&
+		state.NextCheckpoint >= rng.Low && state.NextCheckpoint <= rng.High {
+		log.Printf("resuming %s from checkpoint 0x%8.8x", command, state.NextCheckpoint)
+	} else {
+		state = ProgressState{
+			Command:        command,
+			Low:            rng.Low,
+			NextCheckpoint: rng.Low,
+		}
+	}
+
+	return &progressTracker{
+		store:     store,
+		state:     state,
+		completed: make(map[uint32]bool),
+		freq:      cManager.GetCheckpointFrequency(),
+		lastSave:  time.Now(),
+	}, nil
+}
+
+// next returns the first checkpoint which hasn't been completed.
+func (p *progressTracker) next() uint32 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.state.NextCheckpoint
+}
+
+// complete marks a checkpoint as completed. Checkpoints which failed must not
+// be marked, so that the progress never moves past them.
+func (p *progressTracker) complete(chk uint32) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.completed[chk] = true
+	for p.completed[p.state.NextCheckpoint] {
+		delete(p.completed, p.state.NextCheckpoint)
+		next := uint64(p.state.NextCheckpoint) + uint64(p.freq)
+		if next > 0xffffffff {
+			break
+		}
+		p.state.NextCheckpoint = uint32(next)
+	}
+
+	if time.Since(p.lastSave) >= progressSaveInterval {
+		if err := p.saveLocked(); err != nil {
+			log.Warnf("could not save progress state: %v", err)
+		}
+	}
+}
+
+func (p *progressTracker) save() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.saveLocked()
+}
+
+func (p *progressTracker) saveLocked() error {
+	p.state.UpdatedAt = time.Now().UTC()
+	p.lastSave = time.Now()
+	return p.store.Save(p.state)
+}
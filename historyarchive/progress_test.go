@@ -0,0 +1,172 @@
+// Copyright 2016 Hcnet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryProgressStore struct {
+	state *ProgressState
+}
+
+func (s *memoryProgressStore) Load() (ProgressState, bool, error) {
+	if s.state == nil {
+		return ProgressState{}, false, nil
+	}
+	return *s.state, true, nil
+}
+
+func (s *memoryProgressStore) Save(state ProgressState) error {
+	s.state = &state
+	return nil
+}
+
+func TestProgressTrackerOutOfOrder(t *testing.T) {
+	store := &memoryProgressStore{}
+	cm := NewCheckpointManager(64)
+	tracker, err := newProgressTracker(store, "mirror", Range{Low: 63, High: 0x3bf}, cm)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(63), tracker.next())
+
+	tracker.complete(191)
+	assert.Equal(t, uint32(63), tracker.next())
+	tracker.complete(63)
+	assert.Equal(t, uint32(127), tracker.next())
+	tracker.complete(127)
+	assert.Equal(t, uint32(255), tracker.next())
+
+	require.NoError(t, tracker.save())
+	assert.Equal(t, "mirror", store.state.Command)
+	assert.Equal(t, uint32(63), store.state.Low)
+	assert.Equal(t, uint32(255), store.state.NextCheckpoint)
+}
+
+func TestProgressTrackerResume(t *testing.T) {
+	cm := NewCheckpointManager(64)
+	rng := Range{Low: 63, High: 0x3bf}
+	store := &memoryProgressStore{state: &ProgressState{Command: "mirror", Low: 63, NextCheckpoint: 255}}
+
+	tracker, err := newProgressTracker(store, "mirror", rng, cm)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(255), tracker.next())
+
+	// the progress of another command or range is not resumed
+	tracker, err = newProgressTracker(store, "repair", rng, cm)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(63), tracker.next())
+	tracker, err = newProgressTracker(store, "mirror", Range{Low: 127, High: 0x3bf}, cm)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(127), tracker.next())
+
+	// a completed run starts over
+	store.state.NextCheckpoint = 0x3bf + 64
+	tracker, err = newProgressTracker(store, "mirror", rng, cm)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(63), tracker.next())
+}
+
+func TestFileProgressStore(t *testing.T) {
+	store := NewFileProgressStore(filepath.Join(t.TempDir(), "state.json"))
+	_, found, err := store.Load()
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	state := ProgressState{
+		Command:        "repair",
+		Low:            63,
+		NextCheckpoint: 127,
+		UpdatedAt:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.Save(state))
+	loaded, found, err := store.Load()
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, state, loaded)
+}
+
+func TestMirrorResume(t *testing.T) {
+	defer cleanup()
+	src := GetRandomPopulatedArchive()
+	dst := GetTestArchive()
+
+	resumeAt := uint32(0x1ff)
+	opts := testOptions()
+	opts.Progress = NewArchiveProgressStore(dst)
+	require.NoError(t, opts.Progress.Save(ProgressState{
+		Command:        "mirror",
+		Low:            opts.Range.Low,
+		NextCheckpoint: resumeAt,
+	}))
+	require.NoError(t, Mirror(src, dst, opts))
+
+	exists, err := dst.CategoryCheckpointExists("ledger", resumeAt-64)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	exists, err = dst.CategoryCheckpointExists("ledger", resumeAt)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, src.MustGetRootHAS().CurrentLedger, dst.MustGetRootHAS().CurrentLedger)
+
+	state, found, err := opts.Progress.Load()
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, opts.Range.High+64, state.NextCheckpoint)
+}
+
+func TestMirrorSinceLastRun(t *testing.T) {
+	defer cleanup()
+	src := GetRandomPopulatedArchive()
+	dst := GetTestArchive()
+
+	opts := testOptions()
+	require.NoError(t, Mirror(src, dst, opts))
+	oldHigh := dst.MustGetRootHAS().CurrentLedger
+
+	// only the new checkpoint is copied
+	next := src.checkpointManager.NextCheckpoint(oldHigh + 1)
+	require.NoError(t, src.AddRandomCheckpoint(next))
+	opts = testOptions()
+	opts.Range.High = 0xffffffff
+	opts.SinceLastRun = true
+	require.NoError(t, Mirror(src, dst, opts))
+	assert.Equal(t, next, opts.Range.Low)
+	assert.Equal(t, next, dst.MustGetRootHAS().CurrentLedger)
+
+	// nothing left to copy
+	opts = testOptions()
+	opts.Range.High = 0xffffffff
+	opts.SinceLastRun = true
+	require.NoError(t, Mirror(src, dst, opts))
+	assert.Equal(t, next, dst.MustGetRootHAS().CurrentLedger)
+}
+
+func TestRepairWithProgress(t *testing.T) {
+	defer cleanup()
+	opts := testOptions()
+	src := GetRandomPopulatedArchive()
+	dst := GetTestArchive()
+	require.NoError(t, Mirror(src, dst, opts))
+
+	bad := opts.Range.Low + uint32(opts.Range.SizeInCheckPoints(src.checkpointManager)/2)
+	src.AddRandomCheckpoint(bad)
+	copyFile("history", bad, src, dst)
+	assert.NotEqual(t, 0, countMissing(dst, opts))
+
+	store := &memoryProgressStore{}
+	opts.Progress = store
+	opts.MaxFilesPerSecond = 1000
+	opts.MaxBytesPerSecond = 1 << 20
+	require.NoError(t, Repair(src, dst, opts))
+	opts.Progress = nil
+	assert.Equal(t, 0, countMissing(dst, opts))
+	assert.Equal(t, "repair", store.state.Command)
+	assert.Equal(t, opts.Range.High+64, store.state.NextCheckpoint)
+}
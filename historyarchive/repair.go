@@ -6,19 +6,73 @@ package historyarchive
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// repairChunkCheckpoints is the number of checkpoints repaired between two
+// saves of the progress state.
+const repairChunkCheckpoints = 1024
+
 // Repair repairs a destination archive based on a source archive, it assumes that the source and destination have the
-// same checkpoint ledger frequency
+// same checkpoint ledger frequency. When opts.Progress is set the range is repaired in chunks and the progress is saved
+// after each chunk repaired without errors.
 func Repair(src *Archive, dst *Archive, opts *CommandOptions) error {
 	state, e := dst.GetRootHAS()
 	if e != nil {
 		return e
 	}
 	opts.Range = opts.Range.clamp(state.Range(), src.checkpointManager)
+	opts.throttle = newThrottle(opts)
+
+	if opts.Progress == nil {
+		return repairRange(src, dst, opts)
+	}
+
+	progress, err := newProgressTracker(opts.Progress, "repair", opts.Range, src.checkpointManager)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	freq := uint64(src.checkpointManager.GetCheckpointFrequency())
+	for low := uint64(progress.next()); low <= uint64(opts.Range.High); low += repairChunkCheckpoints * freq {
+		high := low + (repairChunkCheckpoints-1)*freq
+		if high > uint64(opts.Range.High) {
+			high = uint64(opts.Range.High)
+		}
+
+		chunkOpts := *opts
+		chunkOpts.Range = Range{Low: uint32(low), High: uint32(high)}
+		log.Printf("Repairing range %s", chunkOpts.Range)
+		dst.ClearCachedInfo()
+		if err = repairRange(src, dst, &chunkOpts); err != nil {
+			log.Error(err)
+			failed++
+			continue
+		}
+
+		if !opts.DryRun {
+			for chk := range chunkOpts.Range.GenerateCheckpoints(src.checkpointManager) {
+				progress.complete(chk)
+			}
+		}
+	}
 
+	if !opts.DryRun {
+		if err = progress.save(); err != nil {
+			return err
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d ranges failed while repairing", failed)
+	}
+	return nil
+}
+
+func repairRange(src *Archive, dst *Archive, opts *CommandOptions) error {
 	log.Printf("Starting scan for repair")
 	var errs uint32
 	errs += noteError(dst.ScanCheckpoints(opts))
@@ -27,6 +81,7 @@ func Repair(src *Archive, dst *Archive, opts *CommandOptions) error {
 	missingCheckpointFiles := dst.CheckCheckpointFilesMissing(opts)
 
 	repairedHistory := false
+	var pths []string
 	for cat, missing := range missingCheckpointFiles {
 		for _, chk := range missing {
 			if opts.SkipOptional && !categoryRequired(cat) {
@@ -41,13 +96,13 @@ func Repair(src *Archive, dst *Archive, opts *CommandOptions) error {
 				log.Warnf("Skipping nonexistent, optional %s file %s", cat, pth)
 				continue
 			}
-			log.Printf("Repairing %s", pth)
-			errs += noteError(copyPath(src, dst, pth, opts))
+			pths = append(pths, pth)
 			if cat == "history" {
 				repairedHistory = true
 			}
 		}
 	}
+	errs += repairPaths(src, dst, pths, opts)
 
 	if repairedHistory {
 		log.Printf("Re-running checkpoing-file scan, for bucket repair")
@@ -60,14 +115,43 @@ func Repair(src *Archive, dst *Archive, opts *CommandOptions) error {
 	log.Printf("Examining buckets referenced by checkpoints")
 	missingBuckets := dst.CheckBucketsMissing()
 
+	pths = pths[:0]
 	for bkt := range missingBuckets {
-		pth := BucketPath(bkt)
-		log.Printf("Repairing %s", pth)
-		errs += noteError(copyPath(src, dst, pth, opts))
+		pths = append(pths, BucketPath(bkt))
 	}
+	errs += repairPaths(src, dst, pths, opts)
 
 	if errs != 0 {
 		return fmt.Errorf("%d errors while repairing", errs)
 	}
 	return nil
 }
+
+// repairPaths copies the given paths from src to dst using opts.Concurrency
+// goroutines and returns the number of errors.
+func repairPaths(src *Archive, dst *Archive, pths []string, opts *CommandOptions) uint32 {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var errs uint32
+	var wg sync.WaitGroup
+	ch := make(chan string)
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for pth := range ch {
+				log.Printf("Repairing %s", pth)
+				atomic.AddUint32(&errs, noteError(copyPath(src, dst, pth, opts)))
+			}
+		}()
+	}
+	for _, pth := range pths {
+		ch <- pth
+	}
+	close(ch)
+	wg.Wait()
+	return errs
+}
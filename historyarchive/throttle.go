@@ -0,0 +1,69 @@
+// Copyright 2016 Hcnet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// throttle limits the rate at which files are copied between archives. It is
+// shared by all the goroutines of a command.
+type throttle struct {
+	files *rate.Limiter
+	bytes *rate.Limiter
+}
+
+func newThrottle(opts *CommandOptions) *throttle {
+	t := &throttle{}
+	if opts.MaxFilesPerSecond > 0 {
+		burst := int(opts.MaxFilesPerSecond)
+		if burst < 1 {
+			burst = 1
+		}
+		t.files = rate.NewLimiter(rate.Limit(opts.MaxFilesPerSecond), burst)
+	}
+	if opts.MaxBytesPerSecond > 0 {
+		t.bytes = rate.NewLimiter(rate.Limit(opts.MaxBytesPerSecond), int(opts.MaxBytesPerSecond))
+	}
+	return t
+}
+
+// waitFile blocks until another file can be copied.
+func (t *throttle) waitFile() {
+	if t == nil || t.files == nil {
+		return
+	}
+	t.files.Wait(context.Background())
+}
+
+// reader returns a reader which is read no faster than the bandwidth limit.
+func (t *throttle) reader(in io.ReadCloser) io.ReadCloser {
+	if t == nil || t.bytes == nil {
+		return in
+	}
+	return &throttledReader{ReadCloser: in, limiter: t.bytes}
+}
+
+type throttledReader struct {
+	io.ReadCloser
+	limiter *rate.Limiter
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	// a single read can't wait for more tokens than the burst size
+	if len(p) > r.limiter.Burst() {
+		p = p[:r.limiter.Burst()]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if werr := r.limiter.WaitN(context.Background(), n); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return n, err
+}
@@ -47,12 +47,13 @@ func copyPath(src *Archive, dst *Archive, pth string, opts *CommandOptions) erro
 		log.Printf("skipping existing " + pth)
 		return nil
 	}
+	opts.throttle.waitFile()
 	rdr, err := src.backend.GetFile(pth)
 	if err != nil {
 		return err
 	}
 	defer rdr.Close()
-	err = dst.backend.PutFile(pth, bufReadCloser(rdr))
+	err = dst.backend.PutFile(pth, bufReadCloser(opts.throttle.reader(rdr)))
 	return err
 }
 
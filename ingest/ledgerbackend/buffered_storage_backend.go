@@ -0,0 +1,236 @@
+package ledgerbackend
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hcnet/go/metaarchive"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/storage"
+	"github.com/hcnet/go/xdr"
+)
+
+// Ensure BufferedStorageBackend implements LedgerBackend
+var _ LedgerBackend = (*BufferedStorageBackend)(nil)
+
+// BufferedStorageBackendConfig configures BufferedStorageBackend.
+type BufferedStorageBackendConfig struct {
+	// LedgerBatchConfig must match the configuration used by the exporter
+	// which wrote the batch files.
+	LedgerBatchConfig metaarchive.BatchConfig
+	// BufferSize is the number of batch files prefetched ahead of the ledger
+	// currently being read.
+	BufferSize uint32
+	// NumWorkers is the number of batch files downloaded in parallel.
+	NumWorkers uint32
+	// RetryLimit is the number of times a failed download is retried before
+	// the backend gives up.
+	RetryLimit uint32
+	// RetryWait is the initial wait between retries. It's doubled after every
+	// attempt up to MaxRetryWait.
+	RetryWait    time.Duration
+	MaxRetryWait time.Duration
+}
+
+// BufferedStorageBackend is a LedgerBackend which reads ledgers from batch
+// files written by ledgerexporter (see metaarchive.WriteLedgerBatch) into a
+// storage.Storage. Batch files are prefetched by a pool of workers so that
+// ledgers are usually available by the time they are requested.
+type BufferedStorageBackend struct {
+	config  BufferedStorageBackendConfig
+	storage storage.Storage
+
+	bsBackendLock sync.RWMutex
+
+	ledgerBuffer *ledgerBuffer
+	prepared     *Range
+	closed       bool
+
+	// lcmBatch is the batch containing the last ledger returned by GetLedger.
+	lcmBatch   []xdr.LedgerCloseMeta
+	nextLedger uint32
+}
+
+// NewBufferedStorageBackend returns a new BufferedStorageBackend instance.
+func NewBufferedStorageBackend(config BufferedStorageBackendConfig, store storage.Storage) (*BufferedStorageBackend, error) {
+	if store == nil {
+		return nil, errors.New("storage is required")
+	}
+	if config.BufferSize == 0 {
+		return nil, errors.New("buffer size must be > 0")
+	}
+	if config.NumWorkers == 0 {
+		return nil, errors.New("number of workers must be > 0")
+	}
+	if config.NumWorkers > config.BufferSize {
+		return nil, errors.New("number of workers must be <= buffer size")
+	}
+	if config.RetryWait <= 0 {
+		config.RetryWait = time.Second
+	}
+	if config.MaxRetryWait < config.RetryWait {
+		config.MaxRetryWait = 30 * config.RetryWait
+	}
+
+	return &BufferedStorageBackend{
+		config:  config,
+		storage: store,
+	}, nil
+}
+
+// GetLatestLedgerSequence returns the sequence of the latest ledger available
+// in the backend. For bounded ranges it's the last ledger of the range, for
+// unbounded ranges it's the latest ledger marked as exported in the storage.
+// This method returns an error if not in a session (start with PrepareRange).
+func (bsb *BufferedStorageBackend) GetLatestLedgerSequence(ctx context.Context) (uint32, error) {
+	bsb.bsBackendLock.RLock()
+	defer bsb.bsBackendLock.RUnlock()
+
+	if bsb.closed {
+		return 0, errors.New("BufferedStorageBackend is closed; cannot GetLatestLedgerSequence")
+	}
+	if bsb.prepared == nil {
+		return 0, errors.New("BufferedStorageBackend must be prepared, call PrepareRange first")
+	}
+	if bsb.prepared.bounded {
+		return bsb.prepared.to, nil
+	}
+	return metaarchive.NewMetaArchive(bsb.storage).GetLatestLedgerSequence(ctx)
+}
+
+// PrepareRange starts prefetching the batch files covering the given range
+// and blocks until the first ledger of the range is available.
+func (bsb *BufferedStorageBackend) PrepareRange(ctx context.Context, ledgerRange Range) error {
+	bsb.bsBackendLock.Lock()
+	defer bsb.bsBackendLock.Unlock()
+
+	if bsb.closed {
+		return errors.New("BufferedStorageBackend is closed; cannot PrepareRange")
+	}
+	if bsb.isPrepared(ledgerRange) {
+		return nil
+	}
+
+	if bsb.ledgerBuffer != nil {
+		bsb.ledgerBuffer.close()
+	}
+	bsb.ledgerBuffer = newLedgerBuffer(context.Background(), bsb.config, bsb.storage, ledgerRange)
+	bsb.prepared = &ledgerRange
+	bsb.lcmBatch = nil
+	bsb.nextLedger = ledgerRange.from
+
+	if _, err := bsb.getLedger(ctx, ledgerRange.from); err != nil {
+		return errors.Wrapf(err, "error fetching first ledger %d", ledgerRange.from)
+	}
+	return nil
+}
+
+// IsPrepared returns true if a given ledgerRange is prepared.
+func (bsb *BufferedStorageBackend) IsPrepared(ctx context.Context, ledgerRange Range) (bool, error) {
+	bsb.bsBackendLock.RLock()
+	defer bsb.bsBackendLock.RUnlock()
+
+	return bsb.isPrepared(ledgerRange), nil
+}
+
+func (bsb *BufferedStorageBackend) isPrepared(ledgerRange Range) bool {
+	if bsb.closed || bsb.prepared == nil || bsb.ledgerBuffer == nil {
+		return false
+	}
+	if bsb.ledgerBuffer.ctx.Err() != nil {
+		return false
+	}
+	if !bsb.prepared.Contains(ledgerRange) {
+		return false
+	}
+	if _, ok := bsb.cachedLedger(ledgerRange.from); ok {
+		return true
+	}
+	return bsb.nextLedger <= ledgerRange.from
+}
+
+// GetLedger blocks until the ledger is available in the storage and returns
+// it. Ledgers must be requested in non-decreasing order; requesting a ledger
+// before the last requested one returns an error unless it belongs to the
+// batch which is currently loaded.
+func (bsb *BufferedStorageBackend) GetLedger(ctx context.Context, sequence uint32) (xdr.LedgerCloseMeta, error) {
+	bsb.bsBackendLock.Lock()
+	defer bsb.bsBackendLock.Unlock()
+
+	return bsb.getLedger(ctx, sequence)
+}
+
+func (bsb *BufferedStorageBackend) getLedger(ctx context.Context, sequence uint32) (xdr.LedgerCloseMeta, error) {
+	if bsb.closed {
+		return xdr.LedgerCloseMeta{}, errors.New("BufferedStorageBackend is closed; cannot GetLedger")
+	}
+	if bsb.prepared == nil {
+		return xdr.LedgerCloseMeta{}, errors.New("session is not prepared, call PrepareRange first")
+	}
+	if sequence < bsb.prepared.from {
+		return xdr.LedgerCloseMeta{}, errors.Errorf(
+			"requested ledger %d is before the prepared range %s", sequence, bsb.prepared,
+		)
+	}
+	if bsb.prepared.bounded && sequence > bsb.prepared.to {
+		return xdr.LedgerCloseMeta{}, errors.Errorf(
+			"reading past bounded range (requested sequence=%d, last ledger in range=%d)",
+			sequence, bsb.prepared.to,
+		)
+	}
+
+	if ledger, ok := bsb.cachedLedger(sequence); ok {
+		return ledger, nil
+	}
+	if sequence < bsb.nextLedger {
+		return xdr.LedgerCloseMeta{}, errors.Errorf(
+			"requested ledger %d is behind the buffered stream (expected=%d)", sequence, bsb.nextLedger,
+		)
+	}
+
+	for {
+		batch, err := bsb.ledgerBuffer.getFromLedgerQueue(ctx)
+		if err != nil {
+			return xdr.LedgerCloseMeta{}, errors.Wrap(err, "error getting ledger batch")
+		}
+		bsb.lcmBatch = batch.ledgers
+		bsb.nextLedger = batch.ledgers[len(batch.ledgers)-1].LedgerSequence() + 1
+
+		if ledger, ok := bsb.cachedLedger(sequence); ok {
+			return ledger, nil
+		}
+		if bsb.lcmBatch[0].LedgerSequence() > sequence {
+			return xdr.LedgerCloseMeta{}, errors.Errorf(
+				"ledger %d is missing from batch %s",
+				sequence, bsb.config.LedgerBatchConfig.BatchPath(sequence),
+			)
+		}
+	}
+}
+
+// cachedLedger returns the ledger with the given sequence if it belongs to
+// the currently loaded batch.
+func (bsb *BufferedStorageBackend) cachedLedger(sequence uint32) (xdr.LedgerCloseMeta, bool) {
+	if len(bsb.lcmBatch) == 0 {
+		return xdr.LedgerCloseMeta{}, false
+	}
+	first := bsb.lcmBatch[0].LedgerSequence()
+	if sequence < first || sequence-first >= uint32(len(bsb.lcmBatch)) {
+		return xdr.LedgerCloseMeta{}, false
+	}
+	return bsb.lcmBatch[sequence-first], true
+}
+
+// Close stops the prefetching workers. Once closed the backend can no longer
+// be used.
+func (bsb *BufferedStorageBackend) Close() error {
+	bsb.bsBackendLock.Lock()
+	defer bsb.bsBackendLock.Unlock()
+
+	bsb.closed = true
+	if bsb.ledgerBuffer != nil {
+		bsb.ledgerBuffer.close()
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package ledgerbackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/metaarchive"
+	"github.com/hcnet/go/support/storage"
+	"github.com/hcnet/go/xdr"
+)
+
+func testLedgerCloseMeta(sequence uint32) xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{LedgerSeq: xdr.Uint32(sequence)},
+			},
+		},
+	}
+}
+
+func writeTestBatches(t *testing.T, store storage.Storage, config metaarchive.BatchConfig, from, to uint32) {
+	var batch []xdr.LedgerCloseMeta
+	for seq := from; seq <= to; seq++ {
+		batch = append(batch, testLedgerCloseMeta(seq))
+		if seq == config.BatchEnd(seq) || seq == to {
+			require.NoError(t, metaarchive.WriteLedgerBatch(store, config, batch))
+			batch = nil
+		}
+	}
+}
+
+func newTestBufferedStorageBackend(t *testing.T, store storage.Storage) *BufferedStorageBackend {
+	backend, err := NewBufferedStorageBackend(BufferedStorageBackendConfig{
+		LedgerBatchConfig: metaarchive.BatchConfig{LedgersPerBatch: 8},
+		BufferSize:        4,
+		NumWorkers:        2,
+		RetryLimit:        1,
+		RetryWait:         10 * time.Millisecond,
+	}, store)
+	require.NoError(t, err)
+	return backend
+}
+
+func TestNewBufferedStorageBackendValidation(t *testing.T) {
+	store := storage.NewFilesystemStorage(t.TempDir())
+
+	_, err := NewBufferedStorageBackend(BufferedStorageBackendConfig{NumWorkers: 1}, store)
+	assert.EqualError(t, err, "buffer size must be > 0")
+
+	_, err = NewBufferedStorageBackend(BufferedStorageBackendConfig{BufferSize: 1}, store)
+	assert.EqualError(t, err, "number of workers must be > 0")
+
+	_, err = NewBufferedStorageBackend(BufferedStorageBackendConfig{BufferSize: 1, NumWorkers: 2}, store)
+	assert.EqualError(t, err, "number of workers must be <= buffer size")
+}
+
+func TestBufferedStorageBackendBoundedRange(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewFilesystemStorage(t.TempDir())
+	writeTestBatches(t, store, metaarchive.BatchConfig{LedgersPerBatch: 8}, 2, 100)
+
+	backend := newTestBufferedStorageBackend(t, store)
+	defer backend.Close()
+
+	_, err := backend.GetLedger(ctx, 5)
+	assert.EqualError(t, err, "session is not prepared, call PrepareRange first")
+
+	ledgerRange := BoundedRange(5, 60)
+	require.NoError(t, backend.PrepareRange(ctx, ledgerRange))
+
+	prepared, err := backend.IsPrepared(ctx, ledgerRange)
+	require.NoError(t, err)
+	assert.True(t, prepared)
+
+	latest, err := backend.GetLatestLedgerSequence(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(60), latest)
+
+	for seq := uint32(5); seq <= 60; seq++ {
+		ledger, err := backend.GetLedger(ctx, seq)
+		require.NoError(t, err)
+		assert.Equal(t, seq, ledger.LedgerSequence())
+
+		// the same ledger can be requested again
+		ledger, err = backend.GetLedger(ctx, seq)
+		require.NoError(t, err)
+		assert.Equal(t, seq, ledger.LedgerSequence())
+	}
+
+	_, err = backend.GetLedger(ctx, 61)
+	assert.EqualError(t, err, "reading past bounded range (requested sequence=61, last ledger in range=60)")
+
+	_, err = backend.GetLedger(ctx, 20)
+	assert.EqualError(t, err, "requested ledger 20 is behind the buffered stream (expected=64)")
+}
+
+func TestBufferedStorageBackendSkipsAhead(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewFilesystemStorage(t.TempDir())
+	writeTestBatches(t, store, metaarchive.BatchConfig{LedgersPerBatch: 8}, 2, 100)
+
+	backend := newTestBufferedStorageBackend(t, store)
+	defer backend.Close()
+
+	require.NoError(t, backend.PrepareRange(ctx, BoundedRange(2, 100)))
+	ledger, err := backend.GetLedger(ctx, 90)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(90), ledger.LedgerSequence())
+}
+
+func TestBufferedStorageBackendMissingBatchInBoundedRange(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewFilesystemStorage(t.TempDir())
+	writeTestBatches(t, store, metaarchive.BatchConfig{LedgersPerBatch: 8}, 2, 20)
+
+	backend := newTestBufferedStorageBackend(t, store)
+	defer backend.Close()
+
+	require.NoError(t, backend.PrepareRange(ctx, BoundedRange(2, 40)))
+	var err error
+	for seq := uint32(2); seq <= 40 && err == nil; seq++ {
+		_, err = backend.GetLedger(ctx, seq)
+	}
+	assert.ErrorContains(t, err, "is missing ledgers in range [2,40]")
+}
+
+func TestBufferedStorageBackendUnboundedRangeWaitsForLedgers(t *testing.T) {
+	ctx := context.Background()
+	config := metaarchive.BatchConfig{LedgersPerBatch: 8}
+	store := storage.NewFilesystemStorage(t.TempDir())
+	writeTestBatches(t, store, config, 2, 15)
+
+	backend := newTestBufferedStorageBackend(t, store)
+	defer backend.Close()
+
+	require.NoError(t, backend.PrepareRange(ctx, UnboundedRange(10)))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		// write a partial batch first, it must not be consumed
+		assert.NoError(t, metaarchive.WriteLedgerBatch(store, config, []xdr.LedgerCloseMeta{
+			testLedgerCloseMeta(16), testLedgerCloseMeta(17),
+		}))
+		time.Sleep(50 * time.Millisecond)
+		var batch []xdr.LedgerCloseMeta
+		for seq := uint32(16); seq <= 23; seq++ {
+			batch = append(batch, testLedgerCloseMeta(seq))
+		}
+		assert.NoError(t, metaarchive.WriteLedgerBatch(store, config, batch))
+	}()
+
+	for seq := uint32(10); seq <= 23; seq++ {
+		ledger, err := backend.GetLedger(ctx, seq)
+		require.NoError(t, err)
+		assert.Equal(t, seq, ledger.LedgerSequence())
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err := backend.GetLedger(timeoutCtx, 24)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBufferedStorageBackendClose(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewFilesystemStorage(t.TempDir())
+	writeTestBatches(t, store, metaarchive.BatchConfig{LedgersPerBatch: 8}, 2, 20)
+
+	backend := newTestBufferedStorageBackend(t, store)
+	require.NoError(t, backend.PrepareRange(ctx, BoundedRange(2, 20)))
+	require.NoError(t, backend.Close())
+
+	_, err := backend.GetLedger(ctx, 3)
+	assert.EqualError(t, err, "BufferedStorageBackend is closed; cannot GetLedger")
+
+	prepared, err := backend.IsPrepared(ctx, BoundedRange(2, 20))
+	require.NoError(t, err)
+	assert.False(t, prepared)
+}
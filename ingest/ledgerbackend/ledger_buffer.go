@@ -0,0 +1,209 @@
+package ledgerbackend
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hcnet/go/metaarchive"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/storage"
+	"github.com/hcnet/go/xdr"
+)
+
+// ledgerBatchObject is a batch file downloaded by one of the ledgerBuffer
+// workers.
+type ledgerBatchObject struct {
+	startLedger uint32
+	ledgers     []xdr.LedgerCloseMeta
+}
+
+// ledgerBuffer downloads ledger batch files in parallel and hands them out in
+// order. At most config.BufferSize batches are downloading, waiting to be
+// ordered or waiting to be consumed at any given time.
+type ledgerBuffer struct {
+	config      BufferedStorageBackendConfig
+	storage     storage.Storage
+	ledgerRange Range
+
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+
+	// taskQueue contains the start ledgers of the batches to download.
+	taskQueue chan uint32
+	// ledgerQueue contains downloaded batches in ascending order.
+	ledgerQueue chan ledgerBatchObject
+
+	priorityQueueLock sync.Mutex
+	// pending contains downloaded batches which can't be pushed to
+	// ledgerQueue yet because a preceding batch is still being downloaded.
+	pending map[uint32]ledgerBatchObject
+	// nextTaskLedger is the start ledger of the next batch to be queued for
+	// download.
+	nextTaskLedger uint32
+	// nextLedgerQueueLedger is the start ledger of the next batch to be pushed
+	// to ledgerQueue.
+	nextLedgerQueueLedger uint32
+}
+
+func newLedgerBuffer(
+	ctx context.Context,
+	config BufferedStorageBackendConfig,
+	store storage.Storage,
+	ledgerRange Range,
+) *ledgerBuffer {
+	ctx, cancel := context.WithCancelCause(ctx)
+	firstBatch := config.LedgerBatchConfig.BatchStart(ledgerRange.from)
+	lb := &ledgerBuffer{
+		config:                config,
+		storage:               store,
+		ledgerRange:           ledgerRange,
+		ctx:                   ctx,
+		cancel:                cancel,
+		taskQueue:             make(chan uint32, config.BufferSize),
+		ledgerQueue:           make(chan ledgerBatchObject, config.BufferSize),
+		pending:               make(map[uint32]ledgerBatchObject),
+		nextTaskLedger:        firstBatch,
+		nextLedgerQueueLedger: firstBatch,
+	}
+
+	for i := uint32(0); i < config.BufferSize; i++ {
+		lb.pushTaskQueue()
+	}
+
+	for i := uint32(0); i < config.NumWorkers; i++ {
+		lb.wg.Add(1)
+		go lb.worker()
+	}
+
+	return lb
+}
+
+// pushTaskQueue queues the next batch for download unless the end of a
+// bounded range has been reached. It never blocks because the number of
+// batches in flight is bounded by the capacity of taskQueue.
+func (lb *ledgerBuffer) pushTaskQueue() {
+	lb.priorityQueueLock.Lock()
+	defer lb.priorityQueueLock.Unlock()
+
+	if lb.ledgerRange.bounded && lb.nextTaskLedger > lb.ledgerRange.to {
+		return
+	}
+	lb.taskQueue <- lb.nextTaskLedger
+	lb.nextTaskLedger = lb.config.LedgerBatchConfig.BatchEnd(lb.nextTaskLedger) + 1
+}
+
+func (lb *ledgerBuffer) worker() {
+	defer lb.wg.Done()
+
+	for {
+		select {
+		case <-lb.ctx.Done():
+			return
+		case sequence := <-lb.taskQueue:
+			ledgers, err := lb.downloadLedgerBatch(sequence)
+			if err != nil {
+				lb.cancel(err)
+				return
+			}
+			lb.storeObject(ledgerBatchObject{startLedger: sequence, ledgers: ledgers})
+		}
+	}
+}
+
+// downloadLedgerBatch downloads the batch starting at sequence. Transient
+// errors are retried up to config.RetryLimit times. For unbounded ranges, a
+// batch which doesn't exist yet (or is only partially written) is waited for
+// indefinitely with an exponential backoff.
+func (lb *ledgerBuffer) downloadLedgerBatch(sequence uint32) ([]xdr.LedgerCloseMeta, error) {
+	batchConfig := lb.config.LedgerBatchConfig
+	wait := lb.config.RetryWait
+	attempts := uint32(0)
+
+	for {
+		ledgers, err := metaarchive.ReadLedgerBatch(lb.storage, batchConfig, sequence)
+		switch {
+		case err == nil && lb.isComplete(sequence, ledgers):
+			return ledgers, nil
+		case err == nil || os.IsNotExist(err):
+			if lb.ledgerRange.bounded {
+				return nil, errors.Errorf(
+					"ledger batch %s is missing ledgers in range %s",
+					batchConfig.BatchPath(sequence),
+					lb.ledgerRange,
+				)
+			}
+			// The exporter hasn't written the batch yet, keep waiting.
+		default:
+			attempts++
+			if attempts > lb.config.RetryLimit {
+				return nil, errors.Wrapf(
+					err, "failed to download ledger batch %s after %d attempts",
+					batchConfig.BatchPath(sequence), attempts,
+				)
+			}
+		}
+
+		select {
+		case <-lb.ctx.Done():
+			return nil, context.Cause(lb.ctx)
+		case <-time.After(wait):
+		}
+
+		wait *= 2
+		if wait > lb.config.MaxRetryWait {
+			wait = lb.config.MaxRetryWait
+		}
+	}
+}
+
+// isComplete returns true if the batch contains every ledger of the range
+// which belongs to it.
+func (lb *ledgerBuffer) isComplete(sequence uint32, ledgers []xdr.LedgerCloseMeta) bool {
+	last := lb.config.LedgerBatchConfig.BatchEnd(sequence)
+	if lb.ledgerRange.bounded && lb.ledgerRange.to < last {
+		last = lb.ledgerRange.to
+	}
+	return ledgers[len(ledgers)-1].LedgerSequence() >= last
+}
+
+// storeObject adds a downloaded batch to the pending set and pushes all the
+// batches which are now in order to ledgerQueue.
+func (lb *ledgerBuffer) storeObject(batch ledgerBatchObject) {
+	lb.priorityQueueLock.Lock()
+	defer lb.priorityQueueLock.Unlock()
+
+	lb.pending[batch.startLedger] = batch
+	for {
+		next, ok := lb.pending[lb.nextLedgerQueueLedger]
+		if !ok {
+			return
+		}
+		delete(lb.pending, lb.nextLedgerQueueLedger)
+		// ledgerQueue has the same capacity as taskQueue so this never blocks.
+		lb.ledgerQueue <- next
+		lb.nextLedgerQueueLedger = lb.config.LedgerBatchConfig.BatchEnd(lb.nextLedgerQueueLedger) + 1
+	}
+}
+
+// getFromLedgerQueue blocks until the next batch is available and queues the
+// download of another batch in its place.
+func (lb *ledgerBuffer) getFromLedgerQueue(ctx context.Context) (ledgerBatchObject, error) {
+	select {
+	case <-ctx.Done():
+		return ledgerBatchObject{}, ctx.Err()
+	case <-lb.ctx.Done():
+		return ledgerBatchObject{}, context.Cause(lb.ctx)
+	case batch := <-lb.ledgerQueue:
+		lb.pushTaskQueue()
+		return batch, nil
+	}
+}
+
+// close stops all workers and waits for them to exit.
+func (lb *ledgerBuffer) close() {
+	lb.cancel(context.Canceled)
+	lb.wg.Wait()
+}
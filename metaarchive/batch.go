@@ -0,0 +1,152 @@
+package metaarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/storage"
+	"github.com/hcnet/go/xdr"
+)
+
+// DefaultLedgersPerBatch is the number of ledgers stored in a single batch
+// file when no explicit value is configured.
+const DefaultLedgersPerBatch = 64
+
+// BatchConfig describes how ledgers are grouped into batch files. Batches are
+// aligned on multiples of LedgersPerBatch, so the batch containing a ledger
+// can be found without listing the archive.
+type BatchConfig struct {
+	LedgersPerBatch uint32
+}
+
+func (c BatchConfig) ledgersPerBatch() uint32 {
+	if c.LedgersPerBatch == 0 {
+		return DefaultLedgersPerBatch
+	}
+	return c.LedgersPerBatch
+}
+
+// BatchStart returns the first ledger of the batch containing sequence.
+func (c BatchConfig) BatchStart(sequence uint32) uint32 {
+	return sequence - sequence%c.ledgersPerBatch()
+}
+
+// BatchEnd returns the last ledger of the batch containing sequence.
+func (c BatchConfig) BatchEnd(sequence uint32) uint32 {
+	return c.BatchStart(sequence) + c.ledgersPerBatch() - 1
+}
+
+// BatchPath returns the path of the batch file containing sequence.
+func (c BatchConfig) BatchPath(sequence uint32) string {
+	return fmt.Sprintf(
+		"batches/%d-%d.xdr.gz",
+		c.BatchStart(sequence),
+		c.BatchEnd(sequence),
+	)
+}
+
+// WriteLedgerBatch serializes ledgers as a gzipped stream of framed
+// SerializedLedgerCloseMeta objects and stores it at the path of the batch
+// containing the first ledger. All ledgers must belong to the same batch and
+// be in consecutive order. A batch may be partial (ex. at the end of a bounded
+// export), in which case it is overwritten when the batch is completed.
+func WriteLedgerBatch(s storage.Storage, config BatchConfig, ledgers []xdr.LedgerCloseMeta) error {
+	if len(ledgers) == 0 {
+		return errors.New("cannot write an empty batch")
+	}
+
+	first := ledgers[0].LedgerSequence()
+	for i, ledger := range ledgers {
+		expected := first + uint32(i)
+		if ledger.LedgerSequence() != expected {
+			return errors.Errorf(
+				"ledgers in batch are not consecutive (expected=%d, actual=%d)",
+				expected, ledger.LedgerSequence(),
+			)
+		}
+		if config.BatchStart(expected) != config.BatchStart(first) {
+			return errors.Errorf("ledger %d does not belong to batch %s", expected, config.BatchPath(first))
+		}
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	for i := range ledgers {
+		serialized := xdr.SerializedLedgerCloseMeta{V: 0, V0: &ledgers[i]}
+		if err := xdr.MarshalFramed(w, serialized); err != nil {
+			return errors.Wrapf(err, "could not serialize ledger %d", ledgers[i].LedgerSequence())
+		}
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "could not compress batch")
+	}
+
+	return s.PutFile(config.BatchPath(first), io.NopCloser(&buf))
+}
+
+// ReadLedgerBatch reads the batch file containing sequence and returns its
+// ledgers in order. If the batch doesn't exist the error returned by the
+// storage is returned unwrapped, so os.IsNotExist can be used to check it.
+func ReadLedgerBatch(s storage.Storage, config BatchConfig, sequence uint32) ([]xdr.LedgerCloseMeta, error) {
+	r, err := s.GetFile(config.BatchPath(sequence))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open batch")
+	}
+	defer gzr.Close()
+
+	var raw bytes.Buffer
+	if _, err = io.Copy(&raw, gzr); err != nil {
+		return nil, errors.Wrap(err, "could not read batch")
+	}
+
+	var ledgers []xdr.LedgerCloseMeta
+	decoder := xdr.NewBytesDecoder()
+	data := raw.Bytes()
+	for len(data) > 0 {
+		var serialized xdr.SerializedLedgerCloseMeta
+		frameLen, err := readFrameLength(data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[4:]
+		if uint32(len(data)) < frameLen {
+			return nil, errors.New("truncated batch frame")
+		}
+		if _, err = decoder.DecodeBytes(&serialized, data[:frameLen]); err != nil {
+			return nil, errors.Wrap(err, "could not unmarshal ledger")
+		}
+		data = data[frameLen:]
+
+		ledger, ok := serialized.GetV0()
+		if !ok {
+			return nil, fmt.Errorf("unexpected serialized ledger version number (0x%x)", serialized.V)
+		}
+		ledgers = append(ledgers, ledger)
+	}
+
+	if len(ledgers) == 0 {
+		return nil, errors.Errorf("batch %s is empty", config.BatchPath(sequence))
+	}
+	return ledgers, nil
+}
+
+func readFrameLength(data []byte) (uint32, error) {
+	if len(data) < 4 {
+		return 0, errors.New("truncated batch frame header")
+	}
+	frameLen := binary.BigEndian.Uint32(data)
+	if frameLen&0x80000000 == 0 {
+		return 0, errors.New("malformed batch frame header")
+	}
+	return frameLen & 0x7fffffff, nil
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"strconv"
 
+	lru "github.com/hashicorp/golang-lru"
+
 	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/support/storage"
 	"github.com/hcnet/go/xdr"
@@ -17,12 +19,26 @@ type MetaArchive interface {
 	GetLedger(ctx context.Context, sequence uint32) (xdr.SerializedLedgerCloseMeta, error)
 }
 
+// batchCacheSize is the number of batches kept in memory, so that reading
+// consecutive ledgers (possibly from several workers) doesn't download their
+// batch again for every ledger.
+const batchCacheSize = 16
+
 type metaArchive struct {
-	s storage.Storage
+	s       storage.Storage
+	batches BatchConfig
+	cache   *lru.Cache
 }
 
+// NewMetaArchive returns an archive reading the ledgers stored in b, either as
+// single ledger files or as batch files (with the default number of ledgers
+// per batch) like the ones written by the ledger exporter.
 func NewMetaArchive(b storage.Storage) MetaArchive {
-	return &metaArchive{s: b}
+	cache, err := lru.New(batchCacheSize)
+	if err != nil {
+		panic(err)
+	}
+	return &metaArchive{s: b, cache: cache}
 }
 
 func (m *metaArchive) GetLatestLedgerSequence(ctx context.Context) (uint32, error) {
@@ -47,7 +63,9 @@ func (m *metaArchive) GetLatestLedgerSequence(ctx context.Context) (uint32, erro
 func (m *metaArchive) GetLedger(ctx context.Context, sequence uint32) (xdr.SerializedLedgerCloseMeta, error) {
 	var ledger xdr.SerializedLedgerCloseMeta
 	r, err := m.s.GetFile("ledgers/" + strconv.FormatUint(uint64(sequence), 10))
-	if err != nil {
+	if os.IsNotExist(err) {
+		return m.getBatchedLedger(sequence)
+	} else if err != nil {
 		return xdr.SerializedLedgerCloseMeta{}, err
 	}
 	defer r.Close()
@@ -60,3 +78,39 @@ func (m *metaArchive) GetLedger(ctx context.Context, sequence uint32) (xdr.Seria
 	}
 	return ledger, nil
 }
+
+// getBatchedLedger returns a ledger from the batch file containing it. When
+// there is no such batch the not-exist error of the storage is returned, as
+// for single ledger files.
+func (m *metaArchive) getBatchedLedger(sequence uint32) (xdr.SerializedLedgerCloseMeta, error) {
+	start := m.batches.BatchStart(sequence)
+	if cached, ok := m.cache.Get(start); ok {
+		if ledger, ok := batchLedger(cached.([]xdr.LedgerCloseMeta), sequence); ok {
+			return ledger, nil
+		}
+	}
+
+	// The batch is read again when it isn't cached, or when the cached batch
+	// was partial (ex. being exported) and may have been completed since.
+	ledgers, err := ReadLedgerBatch(m.s, m.batches, sequence)
+	if err != nil {
+		return xdr.SerializedLedgerCloseMeta{}, err
+	}
+	m.cache.Add(start, ledgers)
+	if ledger, ok := batchLedger(ledgers, sequence); ok {
+		return ledger, nil
+	}
+	return xdr.SerializedLedgerCloseMeta{}, os.ErrNotExist
+}
+
+// batchLedger returns the ledger of the batch with the given sequence. A batch
+// starts at the first ledger exported, which may be after the start of the
+// batch, and only holds the ledgers exported so far.
+func batchLedger(ledgers []xdr.LedgerCloseMeta, sequence uint32) (xdr.SerializedLedgerCloseMeta, bool) {
+	first := ledgers[0].LedgerSequence()
+	if sequence < first || sequence-first >= uint32(len(ledgers)) {
+		return xdr.SerializedLedgerCloseMeta{}, false
+	}
+	ledger := ledgers[sequence-first]
+	return xdr.SerializedLedgerCloseMeta{V: 0, V0: &ledger}, true
+}
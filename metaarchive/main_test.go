@@ -0,0 +1,75 @@
+package metaarchive
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/support/storage"
+	"github.com/hcnet/go/xdr"
+)
+
+func testLedger(sequence uint32) xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{LedgerSeq: xdr.Uint32(sequence)},
+			},
+		},
+	}
+}
+
+func testBatch(from, to uint32) []xdr.LedgerCloseMeta {
+	var ledgers []xdr.LedgerCloseMeta
+	for sequence := from; sequence <= to; sequence++ {
+		ledgers = append(ledgers, testLedger(sequence))
+	}
+	return ledgers
+}
+
+func TestGetLedgerFromBatches(t *testing.T) {
+	store := storage.NewFilesystemStorage(t.TempDir())
+	config := BatchConfig{}
+	require.NoError(t, WriteLedgerBatch(store, config, testBatch(2, 63)))
+	require.NoError(t, WriteLedgerBatch(store, config, testBatch(64, 70)))
+	archive := NewMetaArchive(store)
+
+	for _, sequence := range []uint32{2, 40, 63, 64, 70} {
+		ledger, err := archive.GetLedger(context.Background(), sequence)
+		require.NoError(t, err)
+		assert.Equal(t, sequence, ledger.MustV0().LedgerSequence())
+	}
+
+	for _, sequence := range []uint32{1, 71, 128} {
+		_, err := archive.GetLedger(context.Background(), sequence)
+		assert.True(t, os.IsNotExist(err), "ledger %d", sequence)
+	}
+
+	// Partial batches are read again once completed.
+	require.NoError(t, WriteLedgerBatch(store, config, testBatch(64, 127)))
+	ledger, err := archive.GetLedger(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100), ledger.MustV0().LedgerSequence())
+}
+
+func TestGetLedgerPrefersLedgerFiles(t *testing.T) {
+	store := storage.NewFilesystemStorage(t.TempDir())
+	require.NoError(t, WriteLedgerBatch(store, BatchConfig{}, testBatch(2, 63)))
+
+	// The ledger file of 10 holds ledger 11, so it can be told apart from
+	// the ledger of the batch.
+	ledger := testLedger(11)
+	raw, err := xdr.SerializedLedgerCloseMeta{V: 0, V0: &ledger}.MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, store.PutFile("ledgers/10", io.NopCloser(bytes.NewReader(raw))))
+
+	read, err := NewMetaArchive(store).GetLedger(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(11), read.MustV0().LedgerSequence())
+}
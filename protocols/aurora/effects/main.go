@@ -209,6 +209,18 @@ const (
 	// from SAC events involving transfers, mints, and burns.
 	// https://github.com/hcnet/rs-soroban-env/blob/5695440da452837555d8f7f259cc33341fdf07b0/soroban-env-host/src/native_contract/token/contract.rs#L51-L63
 	EffectContractDebited EffectType = 97
+
+	// EffectContractAllowanceChanged effects occur when the allowance granted
+	// to a spender is increased or decreased through the SAC.
+	EffectContractAllowanceChanged EffectType = 98
+
+	// EffectContractAuthorizationChanged effects occur when the SAC admin
+	// authorizes or deauthorizes an address to hold the asset.
+	EffectContractAuthorizationChanged EffectType = 99
+
+	// EffectContractAdminChanged effects occur when the admin of a SAC is
+	// replaced.
+	EffectContractAdminChanged EffectType = 100
 )
 
 // Peter 30-04-2019: this is copied from the resourcadapter package
@@ -271,6 +283,9 @@ var EffectTypeNames = map[EffectType]string{
 	EffectLiquidityPoolRevoked:               "liquidity_pool_revoked",
 	EffectContractCredited:                   "contract_credited",
 	EffectContractDebited:                    "contract_debited",
+	EffectContractAllowanceChanged:           "contract_allowance_changed",
+	EffectContractAuthorizationChanged:       "contract_authorization_changed",
+	EffectContractAdminChanged:               "contract_admin_changed",
 }
 
 // Base provides the common structure for any effect resource effect.
@@ -327,6 +342,37 @@ type ContractDebited struct {
 	Amount   string `json:"amount"`
 }
 
+// ContractAllowanceChanged is the effect of a SAC incr_allow or decr_allow
+// call. Amount is the absolute change and Direction is either "increase" or
+// "decrease". Contract is set when the owner of the allowance is a contract.
+type ContractAllowanceChanged struct {
+	Base
+	base.Asset
+	Contract  string `json:"contract,omitempty"`
+	From      string `json:"from"`
+	Spender   string `json:"spender"`
+	Amount    string `json:"amount"`
+	Direction string `json:"direction"`
+}
+
+// ContractAuthorizationChanged is the effect of a SAC set_authorized call.
+// Contract is set when the (de)authorized address is a contract.
+type ContractAuthorizationChanged struct {
+	Base
+	base.Asset
+	Contract   string `json:"contract,omitempty"`
+	Admin      string `json:"admin"`
+	Authorized bool   `json:"authorized"`
+}
+
+// ContractAdminChanged is the effect of a SAC set_admin call.
+type ContractAdminChanged struct {
+	Base
+	base.Asset
+	Admin    string `json:"admin"`
+	NewAdmin string `json:"new_admin"`
+}
+
 type AccountThresholdsUpdated struct {
 	Base
 	LowThreshold  int32 `json:"low_threshold"`
@@ -990,6 +1036,24 @@ func UnmarshalEffect(effectType string, dataString []byte) (effects Effect, err
 			return
 		}
 		effects = effect
+	case EffectTypeNames[EffectContractAllowanceChanged]:
+		var effect ContractAllowanceChanged
+		if err = json.Unmarshal(dataString, &effect); err != nil {
+			return
+		}
+		effects = effect
+	case EffectTypeNames[EffectContractAuthorizationChanged]:
+		var effect ContractAuthorizationChanged
+		if err = json.Unmarshal(dataString, &effect); err != nil {
+			return
+		}
+		effects = effect
+	case EffectTypeNames[EffectContractAdminChanged]:
+		var effect ContractAdminChanged
+		if err = json.Unmarshal(dataString, &effect); err != nil {
+			return
+		}
+		effects = effect
 	default:
 		var effect Base
 		if err = json.Unmarshal(dataString, &effect); err != nil {
@@ -302,6 +302,68 @@ func (p Path) PagingToken() string {
 	return ""
 }
 
+// PathsBatchResult represents the payment paths found for a single request of
+// a batch of path finding requests. Type is either strict_receive or
+// strict_send.
+type PathsBatchResult struct {
+	Type     string `json:"type"`
+	Embedded struct {
+		Records []Path `json:"records"`
+	} `json:"_embedded"`
+}
+
+// stub implementation to satisfy pageable interface
+func (res PathsBatchResult) PagingToken() string {
+	return ""
+}
+
+// OrderBookDepth represents the depth and price impact analytics of the
+// trading pair selling the source asset for the destination asset, across
+// offers and the liquidity pool of the pair. Prices are expressed in units of
+// the source asset per unit of the destination asset.
+type OrderBookDepth struct {
+	SourceAssetType        string                `json:"source_asset_type"`
+	SourceAssetCode        string                `json:"source_asset_code,omitempty"`
+	SourceAssetIssuer      string                `json:"source_asset_issuer,omitempty"`
+	DestinationAssetType   string                `json:"destination_asset_type"`
+	DestinationAssetCode   string                `json:"destination_asset_code,omitempty"`
+	DestinationAssetIssuer string                `json:"destination_asset_issuer,omitempty"`
+	BestPrice              string                `json:"best_price,omitempty"`
+	LiquidityPool          string                `json:"liquidity_pool,omitempty"`
+	Levels                 []OrderBookDepthLevel `json:"levels"`
+	Execution              *TradeExecution       `json:"execution,omitempty"`
+	OffersOnly             *TradeExecution       `json:"offers_only,omitempty"`
+	LiquidityPoolOnly      *TradeExecution       `json:"liquidity_pool_only,omitempty"`
+}
+
+// OrderBookDepthLevel is a point of the cumulative depth curve: the liquidity
+// available until the marginal price deviates from the best price by
+// MaxSlippageBps basis points.
+type OrderBookDepthLevel struct {
+	MaxSlippageBps uint32 `json:"max_slippage_bps"`
+	PriceLimit     string `json:"price_limit"`
+	TradeExecution
+}
+
+// TradeExecution represents a trade split between offers and a liquidity
+// pool. Price is the average price of the trade and SlippageBps its deviation
+// from the best price in basis points.
+type TradeExecution struct {
+	SourceAmount      string    `json:"source_amount"`
+	DestinationAmount string    `json:"destination_amount"`
+	Price             string    `json:"price,omitempty"`
+	SlippageBps       string    `json:"slippage_bps,omitempty"`
+	Offers            VenueFill `json:"offers"`
+	OffersCount       int       `json:"offers_count"`
+	LiquidityPool     VenueFill `json:"liquidity_pool"`
+}
+
+// VenueFill is the part of a trade filled by a kind of venue.
+type VenueFill struct {
+	SourceAmount      string `json:"source_amount"`
+	DestinationAmount string `json:"destination_amount"`
+}
+
 // Price represents a price for an offer
 type Price base.Price
 
@@ -478,6 +540,15 @@ type TradeAggregation struct {
 	OpenR         TradePrice `json:"open_r"`
 	Close         string     `json:"close"`
 	CloseR        TradePrice `json:"close_r"`
+
+	// The volumes above split between the trades which exercised offers on
+	// the order book and the trades which exercised liquidity pools.
+	OrderbookTradeCount        int64  `json:"orderbook_trade_count,string"`
+	OrderbookBaseVolume        string `json:"orderbook_base_volume"`
+	OrderbookCounterVolume     string `json:"orderbook_counter_volume"`
+	LiquidityPoolTradeCount    int64  `json:"liquidity_pool_trade_count,string"`
+	LiquidityPoolBaseVolume    string `json:"liquidity_pool_base_volume"`
+	LiquidityPoolCounterVolume string `json:"liquidity_pool_counter_volume"`
 }
 
 // PagingToken implementation for hal.Pageable. Not actually used
@@ -626,6 +697,20 @@ func (t Transaction) PagingToken() string {
 	return t.PT
 }
 
+// AsyncTransactionSubmissionResponse is the response of the asynchronous
+// transaction submission endpoint. TxStatus is the status hcnet-core
+// responded with: PENDING, DUPLICATE, ERROR or TRY_AGAIN_LATER.
+type AsyncTransactionSubmissionResponse struct {
+	// ErrorResultXDR is the TransactionResult returned by hcnet-core when
+	// TxStatus is ERROR.
+	ErrorResultXDR string `json:"error_result_xdr,omitempty"`
+	// DiagnosticEventsXDR contains the diagnostic events returned by
+	// hcnet-core when TxStatus is ERROR, if any.
+	DiagnosticEventsXDR string `json:"diagnostic_events_xdr,omitempty"`
+	TxStatus            string `json:"tx_status"`
+	Hash                string `json:"hash"`
+}
+
 // TransactionResultCodes represent a summary of result codes returned from
 // a single xdr TransactionResult
 type TransactionResultCodes struct {
@@ -842,6 +927,63 @@ type LiquidityPoolReserve struct {
 	Amount string `json:"amount"`
 }
 
+// ContractEvent represents an event emitted by a smart contract
+type ContractEvent struct {
+	Links struct {
+		Operation hal.Link `json:"operation"`
+	} `json:"_links"`
+
+	ID             string    `json:"id"`
+	PT             string    `json:"paging_token"`
+	ContractID     string    `json:"contract_id"`
+	Ledger         int32     `json:"ledger"`
+	LedgerClosedAt time.Time `json:"ledger_closed_at"`
+	// Topics contains the base64 encoded XDR of the event topics (xdr.ScVal)
+	Topics []string `json:"topics"`
+	// Value contains the base64 encoded XDR of the event body (xdr.ScVal)
+	Value string `json:"value"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res ContractEvent) PagingToken() string {
+	return res.PT
+}
+
+// ContractEventsPage returns a list of contract event records
+type ContractEventsPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []ContractEvent `json:"records"`
+	} `json:"_embedded"`
+}
+
+// ContractDataEntry represents an entry in the storage of a smart contract
+type ContractDataEntry struct {
+	ID         string `json:"id"`
+	PT         string `json:"paging_token"`
+	ContractID string `json:"contract_id"`
+	// Durability is either "persistent" or "temporary"
+	Durability string `json:"durability"`
+	// Key contains the base64 encoded XDR of the entry key (xdr.ScVal)
+	Key string `json:"key"`
+	// Value contains the base64 encoded XDR of the entry value (xdr.ScVal)
+	Value              string `json:"value"`
+	LastModifiedLedger uint32 `json:"last_modified_ledger"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res ContractDataEntry) PagingToken() string {
+	return res.PT
+}
+
+// ContractDataPage returns a list of contract data records
+type ContractDataPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []ContractDataEntry `json:"records"`
+	} `json:"_embedded"`
+}
+
 type AssetFilterConfig struct {
 	Whitelist    []string `json:"whitelist"`
 	Enabled      *bool    `json:"enabled"`
@@ -893,3 +1035,53 @@ func (f *AssetFilterConfig) UnmarshalJSON(data []byte) error {
 	*f = AssetFilterConfig(config)
 	return nil
 }
+
+// WebhookSubscription is a subscription of the webhook subsystem, managed
+// over the admin port. Exactly one of AccountID, Asset and LiquidityPoolID is
+// set. The secret is only used when creating a subscription and it is never
+// returned.
+type WebhookSubscription struct {
+	ID              int64     `json:"id,omitempty"`
+	URL             string    `json:"url"`
+	Secret          string    `json:"secret,omitempty"`
+	AccountID       string    `json:"account_id,omitempty"`
+	Asset           string    `json:"asset,omitempty"`
+	LiquidityPoolID string    `json:"liquidity_pool_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at,omitempty"`
+}
+
+// WebhookDeadLetter is a webhook delivery which failed after the maximum
+// number of attempts.
+type WebhookDeadLetter struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	URL            string          `json:"url"`
+	Ledger         uint32          `json:"ledger"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int32           `json:"attempts"`
+	LastError      string          `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// APIKey is a key of the API key quota subsystem, managed over the admin port.
+// Only the hash of the key is stored by aurora, so the key itself is only
+// returned when it is created.
+type APIKey struct {
+	ID        int64        `json:"id,omitempty"`
+	Name      string       `json:"name"`
+	Key       string       `json:"key,omitempty"`
+	Quotas    APIKeyQuotas `json:"quotas"`
+	CreatedAt time.Time    `json:"created_at,omitempty"`
+}
+
+// APIKeyQuotas are the requests per hour allowed to an API key for each class
+// of endpoints. A missing quota means the class is not limited and a quota of
+// 0 means the class cannot be used with the key. Streams are charged for
+// every update.
+type APIKeyQuotas struct {
+	State      *int64 `json:"state,omitempty"`
+	History    *int64 `json:"history,omitempty"`
+	Streams    *int64 `json:"streams,omitempty"`
+	Paths      *int64 `json:"paths,omitempty"`
+	Submission *int64 `json:"submission,omitempty"`
+}
@@ -9,14 +9,18 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 
+	"github.com/hcnet/go/ingest/ledgerbackend"
+	"github.com/hcnet/go/metaarchive"
 	aurora "github.com/hcnet/go/services/aurora/internal"
 	"github.com/hcnet/go/services/aurora/internal/db2/schema"
 	"github.com/hcnet/go/services/aurora/internal/ingest"
+	"github.com/hcnet/go/services/aurora/internal/ingest/sinks"
 	support "github.com/hcnet/go/support/config"
 	"github.com/hcnet/go/support/db"
 	"github.com/hcnet/go/support/errors"
@@ -246,6 +250,12 @@ var (
 	parallelJobSize     uint32
 	retries             uint
 	retryBackoffSeconds uint
+
+	ledgerBackendType        string
+	dataStoreURL             string
+	dataStoreLedgersPerBatch uint32
+	dataStoreBufferSize      uint32
+	dataStoreNumWorkers      uint32
 )
 
 func ingestRangeCmdOpts() support.ConfigOptions {
@@ -291,6 +301,46 @@ func ingestRangeCmdOpts() support.ConfigOptions {
 			FlagDefault: uint(5),
 			Usage:       "[optional] backoff seconds between reingest retries",
 		},
+		{
+			Name:        "ledgerbackend",
+			ConfigKey:   &ledgerBackendType,
+			OptType:     types.String,
+			Required:    false,
+			FlagDefault: "captive-core",
+			Usage:       "[optional] backend used to read ledgers, one of: captive-core, datastore",
+		},
+		{
+			Name:        "datastore-url",
+			ConfigKey:   &dataStoreURL,
+			OptType:     types.String,
+			Required:    false,
+			FlagDefault: "",
+			Usage:       "[optional] URL of the ledger batch files written by ledgerexporter (required with --ledgerbackend=datastore)",
+		},
+		{
+			Name:        "datastore-ledgers-per-file",
+			ConfigKey:   &dataStoreLedgersPerBatch,
+			OptType:     types.Uint32,
+			Required:    false,
+			FlagDefault: uint32(metaarchive.DefaultLedgersPerBatch),
+			Usage:       "[optional] number of ledgers per batch file, must match the value used by ledgerexporter",
+		},
+		{
+			Name:        "datastore-buffer-size",
+			ConfigKey:   &dataStoreBufferSize,
+			OptType:     types.Uint32,
+			Required:    false,
+			FlagDefault: uint32(100),
+			Usage:       "[optional] number of batch files prefetched from the datastore",
+		},
+		{
+			Name:        "datastore-num-workers",
+			ConfigKey:   &dataStoreNumWorkers,
+			OptType:     types.Uint32,
+			Required:    false,
+			FlagDefault: uint32(10),
+			Usage:       "[optional] number of batch files downloaded from the datastore in parallel",
+		},
 	}
 }
 
@@ -404,6 +454,14 @@ func runDBReingestRange(ledgerRanges []history.LedgerRange, reingestForce bool,
 		maxLedgersPerFlush = parallelJobSize
 	}
 
+	backendType, err := ingest.ParseLedgerBackendType(ledgerBackendType)
+	if err != nil {
+		return err
+	}
+	if backendType == ingest.BufferedStorageBackend && dataStoreURL == "" {
+		return errors.New("--datastore-url is required with --ledgerbackend=datastore")
+	}
+
 	ingestConfig := ingest.Config{
 		NetworkPassphrase:           config.NetworkPassphrase,
 		HistoryArchiveURLs:          config.HistoryArchiveURLs,
@@ -421,12 +479,28 @@ func runDBReingestRange(ledgerRanges []history.LedgerRange, reingestForce bool,
 		RoundingSlippageFilter:      config.RoundingSlippageFilter,
 		EnableIngestionFiltering:    config.EnableIngestionFiltering,
 		MaxLedgerPerFlush:           maxLedgersPerFlush,
+		LedgerBackendType:           backendType,
+		DataStoreURL:                dataStoreURL,
+		BufferedStorageBackendConfig: ledgerbackend.BufferedStorageBackendConfig{
+			LedgerBatchConfig: metaarchive.BatchConfig{LedgersPerBatch: dataStoreLedgersPerBatch},
+			BufferSize:        dataStoreBufferSize,
+			NumWorkers:        dataStoreNumWorkers,
+			RetryLimit:        uint32(retries),
+			RetryWait:         time.Duration(retryBackoffSeconds) * time.Second,
+		},
 	}
 
 	if ingestConfig.HistorySession, err = db.Open("postgres", config.DatabaseURL); err != nil {
 		return fmt.Errorf("cannot open Aurora DB: %v", err)
 	}
 
+	if config.IngestionSink != "" {
+		if ingestConfig.Sink, err = sinks.New(config.IngestionSink); err != nil {
+			return fmt.Errorf("cannot open ingestion sink: %v", err)
+		}
+		defer ingestConfig.Sink.Close()
+	}
+
 	if parallelWorkers > 1 {
 		system, systemErr := ingest.NewParallelSystems(ingestConfig, parallelWorkers)
 		if systemErr != nil {
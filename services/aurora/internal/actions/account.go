@@ -23,7 +23,6 @@ func AccountInfo(ctx context.Context, hq *history.Q, addr string) (*protocol.Acc
 		data       []history.Data
 		signers    []history.AccountSigner
 		trustlines []history.TrustLine
-		resource   protocol.Account
 	)
 
 	record, err := hq.GetAccountByID(ctx, addr)
@@ -46,6 +45,35 @@ func AccountInfo(ctx context.Context, hq *history.Q, addr string) (*protocol.Acc
 		return nil, errors.Wrap(err, "getting history trustlines")
 	}
 
+	return populateAccountInfo(ctx, hq, record, data, signers, trustlines)
+}
+
+// AccountInfoAtLedger returns the information about an account identified by
+// addr as it was at the end of the given ledger. The ledger must be in the
+// state history.
+func AccountInfoAtLedger(ctx context.Context, hq *history.Q, addr string, sequence uint32) (*protocol.Account, error) {
+	if err := checkAtLedger(ctx, hq, sequence); err != nil {
+		return nil, err
+	}
+
+	record, data, signers, trustlines, err := accountStateAtLedger(ctx, hq, addr, sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	return populateAccountInfo(ctx, hq, record, data, signers, trustlines)
+}
+
+func populateAccountInfo(
+	ctx context.Context,
+	hq *history.Q,
+	record history.AccountEntry,
+	data []history.Data,
+	signers []history.AccountSigner,
+	trustlines []history.TrustLine,
+) (*protocol.Account, error) {
+	var resource protocol.Account
+
 	ledger, err := getLedgerBySequence(ctx, hq, int32(record.LastModifiedLedger))
 	if err != nil {
 		return nil, err
@@ -285,6 +313,7 @@ func getLedgerBySequence(ctx context.Context, hq *history.Q, sequence int32) (*h
 
 // AccountByIDQuery query struct for accounts/{account_id} end-point
 type AccountByIDQuery struct {
+	AtLedgerQuery
 	AccountID string `schema:"account_id" valid:"accountID,optional"`
 }
 
@@ -316,7 +345,12 @@ func (handler GetAccountByIDHandler) GetResource(
 	if err != nil {
 		return nil, err
 	}
-	account, err := AccountInfo(r.Context(), historyQ, qp.AccountID)
+	var account *protocol.Account
+	if qp.AtLedger > 0 {
+		account, err = AccountInfoAtLedger(r.Context(), historyQ, qp.AccountID, qp.AtLedger)
+	} else {
+		account, err = AccountInfo(r.Context(), historyQ, qp.AccountID)
+	}
 	if err != nil {
 		return Account{}, err
 	}
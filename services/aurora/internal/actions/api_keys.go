@@ -0,0 +1,205 @@
+package actions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/services/aurora/internal/apikeys"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/db/pg"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/problem"
+)
+
+// these admin HTTP endpoints are documented in services/aurora/internal/httpx/static/admin_oapi.yml
+type APIKeysHandler struct{}
+
+func (handler APIKeysHandler) GetKeys(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	keys, err := historyQ.GetAPIKeys(r.Context())
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responsePayload := make([]hProtocol.APIKey, 0, len(keys))
+	for _, key := range keys {
+		responsePayload = append(responsePayload, handler.keyResource(key))
+	}
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler APIKeysHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	key, err := handler.keyRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	raw, hash, err := apikeys.GenerateKey()
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	key.KeyHash = hash
+
+	key, err = historyQ.InsertAPIKey(r.Context(), key)
+	if err != nil {
+		problem.Render(r.Context(), w, handler.storeError(err))
+		return
+	}
+
+	responsePayload := handler.keyResource(key)
+	responsePayload.Key = raw
+	w.WriteHeader(http.StatusCreated)
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler APIKeysHandler) UpdateKey(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	id, err := getInt64Param(r, "id")
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	key, err := handler.keyRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	key.ID = id
+
+	key, err = historyQ.UpdateAPIKey(r.Context(), key)
+	if historyQ.NoRows(err) {
+		problem.Render(r.Context(), w, problem.NotFound)
+		return
+	} else if err != nil {
+		problem.Render(r.Context(), w, handler.storeError(err))
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(handler.keyResource(key)); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler APIKeysHandler) DeleteKey(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	id, err := getInt64Param(r, "id")
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	err = historyQ.DeleteAPIKey(r.Context(), id)
+	if historyQ.NoRows(err) {
+		problem.Render(r.Context(), w, problem.NotFound)
+		return
+	} else if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler APIKeysHandler) keyRequest(r *http.Request) (history.APIKey, error) {
+	var request hProtocol.APIKey
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&request); err != nil {
+		return history.APIKey{}, problem.NewProblemWithInvalidField(
+			problem.BadRequest, "reason", fmt.Errorf("invalid json for api key %v", err.Error()),
+		)
+	}
+
+	if request.Name == "" {
+		return history.APIKey{}, problem.MakeInvalidFieldProblem(
+			"name", errors.New("name cannot be empty"),
+		)
+	}
+
+	key := history.APIKey{Name: request.Name}
+	for _, quota := range []struct {
+		name   string
+		value  *int64
+		column *sql.NullInt64
+	}{
+		{"quotas.state", request.Quotas.State, &key.StateQuota},
+		{"quotas.history", request.Quotas.History, &key.HistoryQuota},
+		{"quotas.streams", request.Quotas.Streams, &key.StreamsQuota},
+		{"quotas.paths", request.Quotas.Paths, &key.PathsQuota},
+		{"quotas.submission", request.Quotas.Submission, &key.SubmissionQuota},
+	} {
+		if quota.value == nil {
+			continue
+		}
+		if *quota.value < 0 || *quota.value > math.MaxInt32 {
+			return history.APIKey{}, problem.MakeInvalidFieldProblem(
+				quota.name, fmt.Errorf("quota must be between 0 and %d", math.MaxInt32),
+			)
+		}
+		*quota.column = sql.NullInt64{Int64: *quota.value, Valid: true}
+	}
+
+	return key, nil
+}
+
+func (handler APIKeysHandler) storeError(err error) error {
+	if pg.IsUniqueViolation(err) {
+		return problem.MakeInvalidFieldProblem("name", errors.New("an api key with this name already exists"))
+	}
+	return err
+}
+
+func (handler APIKeysHandler) keyResource(key history.APIKey) hProtocol.APIKey {
+	quota := func(value sql.NullInt64) *int64 {
+		if !value.Valid {
+			return nil
+		}
+		return &value.Int64
+	}
+	return hProtocol.APIKey{
+		ID:   key.ID,
+		Name: key.Name,
+		Quotas: hProtocol.APIKeyQuotas{
+			State:      quota(key.StateQuota),
+			History:    quota(key.HistoryQuota),
+			Streams:    quota(key.StreamsQuota),
+			Paths:      quota(key.PathsQuota),
+			Submission: quota(key.SubmissionQuota),
+		},
+		CreatedAt: key.CreatedAt,
+	}
+}
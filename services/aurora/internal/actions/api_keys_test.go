@@ -0,0 +1,157 @@
+package actions
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/services/aurora/internal/apikeys"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/test"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+
+	q := &history.Q{SessionInterface: tt.AuroraSession()}
+	handler := &APIKeysHandler{}
+
+	for _, testCase := range []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "quotas",
+			body:           `{"name": "wallet", "quotas": {"history": 1000, "submission": 0}}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "unlimited",
+			body:           `{"name": "explorer"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid json",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing name",
+			body:           `{"quotas": {"history": 1000}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negative quota",
+			body:           `{"name": "negative", "quotas": {"state": -1}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "duplicate name",
+			body:           `{"name": "wallet"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := makeRequest(t, map[string]string{}, map[string]string{}, q)
+			request.Method = http.MethodPost
+			request.Body = ioutil.NopCloser(strings.NewReader(testCase.body))
+
+			recorder := httptest.NewRecorder()
+			handler.CreateKey(recorder, request)
+
+			resp := recorder.Result()
+			tt.Assert.Equal(testCase.expectedStatus, resp.StatusCode)
+			if testCase.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var key hProtocol.APIKey
+			tt.Assert.NoError(json.NewDecoder(resp.Body).Decode(&key))
+			tt.Assert.NotZero(key.ID)
+			tt.Assert.NotEmpty(key.Key)
+
+			stored, err := q.GetAPIKeyByID(tt.Ctx, key.ID)
+			tt.Assert.NoError(err)
+			tt.Assert.Equal(apikeys.HashKey(key.Key), stored.KeyHash)
+		})
+	}
+
+	keys, err := q.GetAPIKeys(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(keys, 2)
+	tt.Assert.Equal(int64(1000), keys[0].HistoryQuota.Int64)
+	tt.Assert.True(keys[0].SubmissionQuota.Valid)
+	tt.Assert.Equal(int64(0), keys[0].SubmissionQuota.Int64)
+	tt.Assert.False(keys[0].StateQuota.Valid)
+}
+
+func TestUpdateAndDeleteAPIKeys(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+
+	q := &history.Q{SessionInterface: tt.AuroraSession()}
+	handler := &APIKeysHandler{}
+
+	key, err := q.InsertAPIKey(tt.Ctx, history.APIKey{Name: "wallet", KeyHash: "hash"})
+	tt.Assert.NoError(err)
+	id := strconv.FormatInt(key.ID, 10)
+
+	for _, testCase := range []struct {
+		id             string
+		body           string
+		expectedStatus int
+	}{
+		{id: "invalid", body: `{"name": "wallet"}`, expectedStatus: http.StatusBadRequest},
+		{id: "1000", body: `{"name": "wallet"}`, expectedStatus: http.StatusNotFound},
+		{id: id, body: `{"quotas": {"state": 10}}`, expectedStatus: http.StatusBadRequest},
+		{id: id, body: `{"name": "mobile wallet", "quotas": {"streams": 3600}}`, expectedStatus: http.StatusOK},
+	} {
+		request := makeRequest(t, map[string]string{}, map[string]string{"id": testCase.id}, q)
+		request.Method = http.MethodPut
+		request.Body = ioutil.NopCloser(strings.NewReader(testCase.body))
+		recorder := httptest.NewRecorder()
+		handler.UpdateKey(recorder, request)
+		tt.Assert.Equal(testCase.expectedStatus, recorder.Result().StatusCode)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.GetKeys(recorder, makeRequest(t, map[string]string{}, map[string]string{}, q))
+	resp := recorder.Result()
+	tt.Assert.Equal(http.StatusOK, resp.StatusCode)
+	var keys []hProtocol.APIKey
+	tt.Assert.NoError(json.NewDecoder(resp.Body).Decode(&keys))
+	tt.Assert.Len(keys, 1)
+	tt.Assert.Equal("mobile wallet", keys[0].Name)
+	tt.Assert.Empty(keys[0].Key)
+	tt.Assert.Nil(keys[0].Quotas.State)
+	tt.Assert.Equal(int64(3600), *keys[0].Quotas.Streams)
+
+	for _, testCase := range []struct {
+		id             string
+		expectedStatus int
+	}{
+		{id: "invalid", expectedStatus: http.StatusBadRequest},
+		{id: "1000", expectedStatus: http.StatusNotFound},
+		{id: id, expectedStatus: http.StatusNoContent},
+	} {
+		recorder = httptest.NewRecorder()
+		handler.DeleteKey(
+			recorder,
+			makeRequest(t, map[string]string{}, map[string]string{"id": testCase.id}, q),
+		)
+		tt.Assert.Equal(testCase.expectedStatus, recorder.Result().StatusCode)
+	}
+
+	remaining, err := q.GetAPIKeys(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(remaining)
+}
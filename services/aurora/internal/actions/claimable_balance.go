@@ -22,6 +22,7 @@ type GetClaimableBalanceByIDHandler struct{}
 
 // ClaimableBalanceQuery query struct for claimables_balances/id end-point
 type ClaimableBalanceQuery struct {
+	AtLedgerQuery
 	ID string `schema:"id" valid:"claimableBalanceID,required"`
 }
 
@@ -38,7 +39,12 @@ func (handler GetClaimableBalanceByIDHandler) GetResource(w HeaderWriter, r *htt
 	if err != nil {
 		return nil, err
 	}
-	cb, err := historyQ.FindClaimableBalanceByID(ctx, qp.ID)
+	var cb history.ClaimableBalance
+	if qp.AtLedger > 0 {
+		cb, err = claimableBalanceAtLedger(ctx, historyQ, qp.ID, qp.AtLedger)
+	} else {
+		cb, err = historyQ.FindClaimableBalanceByID(ctx, qp.ID)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +73,7 @@ type ClaimableBalancesQuery struct {
 	AssetFilter    string `schema:"asset" valid:"asset,optional"`
 	SponsorFilter  string `schema:"sponsor" valid:"accountID,optional"`
 	ClaimantFilter string `schema:"claimant" valid:"accountID,optional"`
+	AtLedgerQuery  `valid:"-"`
 }
 
 func (q ClaimableBalancesQuery) asset() *xdr.Asset {
@@ -144,7 +151,7 @@ func (handler GetClaimableBalancesHandler) GetResourcePage(
 		return nil, err
 	}
 
-	claimableBalances, err := getClaimableBalancesPage(ctx, historyQ, query)
+	claimableBalances, err := getClaimableBalancesPage(ctx, historyQ, query, qp.AtLedger)
 	if err != nil {
 		return nil, err
 	}
@@ -152,8 +159,24 @@ func (handler GetClaimableBalancesHandler) GetResourcePage(
 	return claimableBalances, nil
 }
 
-func getClaimableBalancesPage(ctx context.Context, historyQ *history.Q, query history.ClaimableBalancesQuery) ([]hal.Pageable, error) {
-	records, err := historyQ.GetClaimableBalances(ctx, query)
+// getClaimableBalancesPage loads a page of claimable balances from the state
+// tables, or from the state history when atLedger is set.
+func getClaimableBalancesPage(
+	ctx context.Context,
+	historyQ *history.Q,
+	query history.ClaimableBalancesQuery,
+	atLedger uint32,
+) ([]hal.Pageable, error) {
+	var records []history.ClaimableBalance
+	var err error
+	if atLedger > 0 {
+		if err = checkAtLedger(ctx, historyQ, atLedger); err != nil {
+			return nil, err
+		}
+		records, err = claimableBalancesAtLedger(ctx, historyQ, query, atLedger)
+	} else {
+		records, err = historyQ.GetClaimableBalances(ctx, query)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -569,7 +569,7 @@ func TestCursorAndOrderValidation(t *testing.T) {
 
 func TestClaimableBalancesQueryURLTemplate(t *testing.T) {
 	tt := assert.New(t)
-	expected := "/claimable_balances{?asset,sponsor,claimant,cursor,limit,order}"
+	expected := "/claimable_balances{?asset,sponsor,claimant,at_ledger,cursor,limit,order}"
 	q := ClaimableBalancesQuery{}
 	tt.Equal(expected, q.URITemplate())
 }
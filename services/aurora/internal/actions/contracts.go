@@ -0,0 +1,175 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hcnet/go/protocols/aurora"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/ledger"
+	"github.com/hcnet/go/services/aurora/internal/resourceadapter"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/hal"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+)
+
+// contractEventTopicWildcard matches any topic at the given position.
+const contractEventTopicWildcard = "*"
+
+// ContractEventsQuery query struct for the contract events end-point
+type ContractEventsQuery struct {
+	ContractID string `schema:"contract_id" valid:"contractID,required"`
+	Topic1     string `schema:"topic1" valid:"-"`
+	Topic2     string `schema:"topic2" valid:"-"`
+	Topic3     string `schema:"topic3" valid:"-"`
+	Topic4     string `schema:"topic4" valid:"-"`
+}
+
+// Topics returns the topic filters by position. Wildcards are returned as
+// empty strings.
+func (qp ContractEventsQuery) Topics() []string {
+	topics := []string{qp.Topic1, qp.Topic2, qp.Topic3, qp.Topic4}
+
+	// trim trailing wildcards, they don't restrict the query
+	last := 0
+	for i, topic := range topics {
+		if topic == contractEventTopicWildcard {
+			topics[i] = ""
+		}
+		if topics[i] != "" {
+			last = i + 1
+		}
+	}
+	return topics[:last]
+}
+
+// Validate runs extra validations on query parameters
+func (qp ContractEventsQuery) Validate() error {
+	fields := []string{"topic1", "topic2", "topic3", "topic4"}
+	for i, topic := range []string{qp.Topic1, qp.Topic2, qp.Topic3, qp.Topic4} {
+		if topic == "" || topic == contractEventTopicWildcard {
+			continue
+		}
+		var scVal xdr.ScVal
+		if err := xdr.SafeUnmarshalBase64(topic, &scVal); err != nil {
+			return problem.MakeInvalidFieldProblem(
+				fields[i],
+				errors.New("Topic must be `*` or the base64-encoded XDR representation of an ScVal"),
+			)
+		}
+	}
+	return nil
+}
+
+// GetContractEventsHandler is the action handler for the
+// `/contracts/{contract_id}/events` endpoint.
+type GetContractEventsHandler struct {
+	LedgerState *ledger.State
+}
+
+// GetResourcePage returns a page of events emitted by a contract.
+func (handler GetContractEventsHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
+	pq, err := GetPageQuery(handler.LedgerState, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateCursorWithinHistory(handler.LedgerState, pq)
+	if err != nil {
+		return nil, err
+	}
+
+	qp := ContractEventsQuery{}
+	err = getParams(&qp, r)
+	if err != nil {
+		return nil, err
+	}
+
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []history.ContractEvent
+	err = historyQ.ContractEvents().
+		ForContract(qp.ContractID).
+		ForTopics(qp.Topics()).
+		Page(pq).
+		Select(r.Context(), &records)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading contract events")
+	}
+
+	ledgers, err := loadContractEventLedgers(r.Context(), historyQ, records)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading ledgers")
+	}
+
+	var result []hal.Pageable
+	for _, record := range records {
+		var event aurora.ContractEvent
+		resourceadapter.PopulateContractEvent(r.Context(), &event, record, ledgers[record.LedgerSequence()])
+		result = append(result, event)
+	}
+
+	return result, nil
+}
+
+func loadContractEventLedgers(ctx context.Context, hq *history.Q, events []history.ContractEvent) (map[int32]history.Ledger, error) {
+	ledgers := &history.LedgerCache{}
+
+	for _, e := range events {
+		ledgers.Queue(e.LedgerSequence())
+	}
+
+	if err := ledgers.Load(ctx, hq); err != nil {
+		return nil, err
+	}
+	return ledgers.Records, nil
+}
+
+// ContractDataQuery query struct for the contract data end-point
+type ContractDataQuery struct {
+	ContractID string `schema:"contract_id" valid:"contractID,required"`
+}
+
+// GetContractDataHandler is the action handler for the
+// `/contracts/{contract_id}/data` endpoint.
+type GetContractDataHandler struct {
+	LedgerState *ledger.State
+}
+
+// GetResourcePage returns a page of the current storage entries of a contract.
+func (handler GetContractDataHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
+	pq, err := GetPageQuery(handler.LedgerState, r, DisableCursorValidation)
+	if err != nil {
+		return nil, err
+	}
+
+	qp := ContractDataQuery{}
+	err = getParams(&qp, r)
+	if err != nil {
+		return nil, err
+	}
+
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := historyQ.GetContractData(r.Context(), qp.ContractID, pq)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading contract data")
+	}
+
+	var result []hal.Pageable
+	for _, record := range records {
+		var entry aurora.ContractDataEntry
+		resourceadapter.PopulateContractDataEntry(&entry, record)
+		result = append(result, entry)
+	}
+
+	return result, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/services/aurora/internal/ledger"
 	"github.com/hcnet/go/services/aurora/internal/resourceadapter"
+	"github.com/hcnet/go/services/aurora/internal/ssehub"
 	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/support/render/hal"
 	"github.com/hcnet/go/support/render/problem"
@@ -94,6 +95,35 @@ func (handler GetEffectsHandler) GetResourcePage(w HeaderWriter, r *http.Request
 	return result, nil
 }
 
+// LedgerRenderer returns the renderer of the ledgers published by the SSE hub
+// for the stream of the request, or false if the stream filters effects by
+// something the hub doesn't know about.
+func (handler GetEffectsHandler) LedgerRenderer(r *http.Request) (ssehub.RenderFunc, bool, error) {
+	qp := EffectsQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return nil, false, err
+	}
+	if qp.OperationID > 0 || qp.LiquidityPoolID != "" || qp.TxHash != "" || qp.LedgerID > 0 {
+		return nil, false, nil
+	}
+
+	return func(ctx context.Context, l *ssehub.Ledger) ([]hal.Pageable, error) {
+		var result []hal.Pageable
+		for _, record := range l.Effects {
+			if qp.AccountID != "" && record.Account != qp.AccountID {
+				continue
+			}
+
+			effect, err := resourceadapter.NewEffect(ctx, record, l.Ledger)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not create effect")
+			}
+			result = append(result, effect)
+		}
+		return result, nil
+	}, true, nil
+}
+
 func loadEffectRecords(ctx context.Context, hq *history.Q, qp EffectsQuery, pq db2.PageQuery) ([]history.Effect, error) {
 	effects := hq.Effects()
 
@@ -178,6 +178,21 @@ func getLimit(r *http.Request, name string, def uint64, max uint64) (uint64, err
 	return uint64(asI64), nil
 }
 
+// getInt64Param retrieves a non-negative int64, like the id of a record, from
+// the request at the provided name.
+func getInt64Param(r *http.Request, name string) (int64, error) {
+	value, err := getString(r, name)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, problem.MakeInvalidFieldProblem(name, errors.New("must be a positive integer"))
+	}
+	return id, nil
+}
+
 // GetPageQuery is a helper that returns a new db.PageQuery struct initialized
 // using the results from a call to GetPagingParams()
 func GetPageQuery(ledgerState *ledger.State, r *http.Request, opts ...Opt) (db2.PageQuery, error) {
@@ -399,6 +414,12 @@ func getParams(dst interface{}, r *http.Request) error {
 		}
 	}
 
+	return decodeParams(dst, query)
+}
+
+// decodeParams decodes the given parameters into dst and validates them like
+// getParams does for the parameters of a request.
+func decodeParams(dst interface{}, query url.Values) error {
 	if err := decoder.Decode(dst, query); err != nil {
 		for k, e := range err.(schema.MultiError) {
 			return problem.NewProblemWithInvalidField(
@@ -613,6 +634,16 @@ func countNonEmpty(params ...interface{}) (int, error) {
 	return count, nil
 }
 
+// includesAccount returns whether the account is one of the participants.
+func includesAccount(participants []string, account string) bool {
+	for _, participant := range participants {
+		if participant == account {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	decoder.IgnoreUnknownKeys(true)
 }
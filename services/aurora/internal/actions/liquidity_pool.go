@@ -21,6 +21,7 @@ type GetLiquidityPoolByIDHandler struct{}
 
 // LiquidityPoolQuery query struct for liquidity_pools/id endpoint
 type LiquidityPoolQuery struct {
+	AtLedgerQuery
 	ID string `schema:"liquidity_pool_id" valid:"sha256"`
 }
 
@@ -37,7 +38,12 @@ func (handler GetLiquidityPoolByIDHandler) GetResource(w HeaderWriter, r *http.R
 	if err != nil {
 		return nil, err
 	}
-	cb, err := historyQ.FindLiquidityPoolByID(ctx, qp.ID)
+	var cb history.LiquidityPool
+	if qp.AtLedger > 0 {
+		cb, err = liquidityPoolAtLedger(ctx, historyQ, qp.ID, qp.AtLedger)
+	} else {
+		cb, err = historyQ.FindLiquidityPoolByID(ctx, qp.ID)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -62,6 +68,7 @@ func (handler GetLiquidityPoolByIDHandler) GetResource(w HeaderWriter, r *http.R
 type LiquidityPoolsQuery struct {
 	Reserves string `schema:"reserves" valid:"optional"`
 	Account  string `schema:"account" valid:"optional"`
+	AtLedgerQuery
 
 	reserves []xdr.Asset
 }
@@ -131,7 +138,7 @@ func (handler GetLiquidityPoolsHandler) GetResourcePage(w HeaderWriter, r *http.
 		return nil, err
 	}
 
-	liquidityPools, err := handler.getLiquidityPoolsPage(ctx, historyQ, query)
+	liquidityPools, err := handler.getLiquidityPoolsPage(ctx, historyQ, query, qp.AtLedger)
 	if err != nil {
 		return nil, err
 	}
@@ -139,8 +146,22 @@ func (handler GetLiquidityPoolsHandler) GetResourcePage(w HeaderWriter, r *http.
 	return liquidityPools, nil
 }
 
-func (handler GetLiquidityPoolsHandler) getLiquidityPoolsPage(ctx context.Context, historyQ *history.Q, query history.LiquidityPoolsQuery) ([]hal.Pageable, error) {
-	records, err := historyQ.GetLiquidityPools(ctx, query)
+func (handler GetLiquidityPoolsHandler) getLiquidityPoolsPage(
+	ctx context.Context,
+	historyQ *history.Q,
+	query history.LiquidityPoolsQuery,
+	atLedger uint32,
+) ([]hal.Pageable, error) {
+	var records []history.LiquidityPool
+	var err error
+	if atLedger > 0 {
+		if err = checkAtLedger(ctx, historyQ, atLedger); err != nil {
+			return nil, err
+		}
+		records, err = liquidityPoolsAtLedger(ctx, historyQ, query, atLedger)
+	} else {
+		records, err = historyQ.GetLiquidityPools(ctx, query)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -15,6 +15,7 @@ import (
 
 // AccountOffersQuery query struct for offers end-point
 type OfferByIDQuery struct {
+	AtLedgerQuery
 	OfferID uint64 `schema:"offer_id" valid:"-"`
 }
 
@@ -35,7 +36,12 @@ func (handler GetOfferByID) GetResource(w HeaderWriter, r *http.Request) (interf
 		return nil, err
 	}
 
-	record, err := historyQ.GetOfferByID(r.Context(), int64(qp.OfferID))
+	var record history.Offer
+	if qp.AtLedger > 0 {
+		record, err = offerAtLedger(ctx, historyQ, int64(qp.OfferID), qp.AtLedger)
+	} else {
+		record, err = historyQ.GetOfferByID(r.Context(), int64(qp.OfferID))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +66,7 @@ func (handler GetOfferByID) GetResource(w HeaderWriter, r *http.Request) (interf
 // OffersQuery query struct for offers end-point
 type OffersQuery struct {
 	SellingBuyingAssetQueryParams `valid:"-"`
+	AtLedgerQuery                 `valid:"-"`
 	Seller                        string `schema:"seller" valid:"accountID,optional"`
 	Sponsor                       string `schema:"sponsor" valid:"accountID,optional"`
 }
@@ -67,7 +74,7 @@ type OffersQuery struct {
 // URITemplate returns a rfc6570 URI template the query struct
 func (q OffersQuery) URITemplate() string {
 	// building this manually since we don't want to include all the params in SellingBuyingAssetQueryParams
-	return "/offers{?selling,buying,seller,sponsor,cursor,limit,order}"
+	return "/offers{?selling,buying,seller,sponsor,at_ledger,cursor,limit,order}"
 }
 
 // Validate runs custom validations.
@@ -119,7 +126,7 @@ func (handler GetOffersHandler) GetResourcePage(
 		return nil, err
 	}
 
-	offers, err := getOffersPage(ctx, historyQ, query)
+	offers, err := getOffersPage(ctx, historyQ, query, qp.AtLedger)
 	if err != nil {
 		return nil, err
 	}
@@ -129,6 +136,7 @@ func (handler GetOffersHandler) GetResourcePage(
 
 // AccountOffersQuery query struct for offers end-point
 type AccountOffersQuery struct {
+	AtLedgerQuery
 	AccountID string `schema:"account_id" valid:"accountID,required"`
 }
 
@@ -138,15 +146,15 @@ type GetAccountOffersHandler struct {
 	LedgerState *ledger.State
 }
 
-func (handler GetAccountOffersHandler) parseOffersQuery(r *http.Request) (history.OffersQuery, error) {
+func (handler GetAccountOffersHandler) parseOffersQuery(r *http.Request) (history.OffersQuery, uint32, error) {
 	pq, err := GetPageQuery(handler.LedgerState, r)
 	if err != nil {
-		return history.OffersQuery{}, err
+		return history.OffersQuery{}, 0, err
 	}
 
 	qp := AccountOffersQuery{}
 	if err = getParams(&qp, r); err != nil {
-		return history.OffersQuery{}, err
+		return history.OffersQuery{}, 0, err
 	}
 
 	query := history.OffersQuery{
@@ -154,7 +162,7 @@ func (handler GetAccountOffersHandler) parseOffersQuery(r *http.Request) (histor
 		SellerID:  qp.AccountID,
 	}
 
-	return query, nil
+	return query, qp.AtLedger, nil
 }
 
 // GetResourcePage returns a page of offers for a given account.
@@ -163,7 +171,7 @@ func (handler GetAccountOffersHandler) GetResourcePage(
 	r *http.Request,
 ) ([]hal.Pageable, error) {
 	ctx := r.Context()
-	query, err := handler.parseOffersQuery(r)
+	query, atLedger, err := handler.parseOffersQuery(r)
 	if err != nil {
 		return nil, err
 	}
@@ -173,7 +181,7 @@ func (handler GetAccountOffersHandler) GetResourcePage(
 		return nil, err
 	}
 
-	offers, err := getOffersPage(ctx, historyQ, query)
+	offers, err := getOffersPage(ctx, historyQ, query, atLedger)
 	if err != nil {
 		return nil, err
 	}
@@ -181,8 +189,19 @@ func (handler GetAccountOffersHandler) GetResourcePage(
 	return offers, nil
 }
 
-func getOffersPage(ctx context.Context, historyQ *history.Q, query history.OffersQuery) ([]hal.Pageable, error) {
-	records, err := historyQ.GetOffers(ctx, query)
+// getOffersPage loads a page of offers from the state tables, or from the
+// state history when atLedger is set.
+func getOffersPage(ctx context.Context, historyQ *history.Q, query history.OffersQuery, atLedger uint32) ([]hal.Pageable, error) {
+	var records []history.Offer
+	var err error
+	if atLedger > 0 {
+		if err = checkAtLedger(ctx, historyQ, atLedger); err != nil {
+			return nil, err
+		}
+		records, err = offersAtLedger(ctx, historyQ, query, atLedger)
+	} else {
+		records, err = historyQ.GetOffers(ctx, query)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -518,7 +518,7 @@ func pageableToOffers(t *testing.T, page []hal.Pageable) []aurora.Offer {
 
 func TestOffersQueryURLTemplate(t *testing.T) {
 	tt := assert.New(t)
-	expected := "/offers{?selling,buying,seller,sponsor,cursor,limit,order}"
+	expected := "/offers{?selling,buying,seller,sponsor,at_ledger,cursor,limit,order}"
 	offersQuery := OffersQuery{}
 	tt.Equal(expected, offersQuery.URITemplate())
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/hcnet/go/services/aurora/internal/ledger"
 	"github.com/hcnet/go/services/aurora/internal/render/problem"
 	"github.com/hcnet/go/services/aurora/internal/resourceadapter"
+	"github.com/hcnet/go/services/aurora/internal/ssehub"
 	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/support/render/hal"
 	supportProblem "github.com/hcnet/go/support/render/problem"
@@ -129,6 +130,52 @@ func (handler GetOperationsHandler) GetResourcePage(w HeaderWriter, r *http.Requ
 	return buildOperationsPage(ctx, historyQ, ops, txs, qp.IncludeTransactions())
 }
 
+// LedgerRenderer returns the renderer of the ledgers published by the SSE hub
+// for the stream of the request, or false if the stream filters operations by
+// something the hub doesn't know about.
+func (handler GetOperationsHandler) LedgerRenderer(r *http.Request) (ssehub.RenderFunc, bool, error) {
+	qp := OperationsQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return nil, false, err
+	}
+	if qp.ClaimableBalanceID != "" || qp.LiquidityPoolID != "" || qp.LedgerID > 0 || qp.TransactionHash != "" {
+		return nil, false, nil
+	}
+
+	return func(ctx context.Context, l *ssehub.Ledger) ([]hal.Pageable, error) {
+		var response []hal.Pageable
+		for i := range l.Operations {
+			op := &l.Operations[i]
+			switch {
+			case !qp.IncludeFailedTransactions && !op.TransactionSuccessful:
+				continue
+			case handler.OnlyPayments && !op.InPaymentsClass():
+				continue
+			case qp.AccountID != "" && !includesAccount(op.Participants, qp.AccountID):
+				continue
+			}
+
+			var transactionRecord *history.Transaction
+			if qp.IncludeTransactions() {
+				transactionRecord = &op.Transaction
+			}
+
+			res, err := resourceadapter.NewOperation(
+				ctx,
+				op.Operation,
+				op.TransactionHash,
+				transactionRecord,
+				l.Ledger,
+			)
+			if err != nil {
+				return nil, err
+			}
+			response = append(response, res)
+		}
+		return response, nil
+	}, true, nil
+}
+
 // GetOperationByIDHandler is the action handler for all end-points returning a list of operations.
 type GetOperationByIDHandler struct {
 	LedgerState *ledger.State
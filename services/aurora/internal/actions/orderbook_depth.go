@@ -0,0 +1,188 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hcnet/go/amount"
+	"github.com/hcnet/go/exp/orderbook"
+	protocol "github.com/hcnet/go/protocols/aurora"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/paths"
+	auroraProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/services/aurora/internal/resourceadapter"
+	"github.com/hcnet/go/services/aurora/internal/simplepath"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+)
+
+const (
+	// maxDepthLevels is the maximum number of slippage levels of an order
+	// book depth request.
+	maxDepthLevels = 20
+	// maxSlippageBps is the maximum slippage, in basis points, of an order
+	// book depth level.
+	maxSlippageBps = 10000
+)
+
+// defaultSlippageBps are the levels of the order book depth curve when the
+// slippage_bps parameter is not set.
+var defaultSlippageBps = []uint32{10, 25, 50, 100, 250, 500, 1000}
+
+// OrderBookDepthQuery query struct for the order_book/depth end-point
+type OrderBookDepthQuery struct {
+	SourceAssetType        string `schema:"source_asset_type" valid:"assetType"`
+	SourceAssetIssuer      string `schema:"source_asset_issuer" valid:"accountID,optional"`
+	SourceAssetCode        string `schema:"source_asset_code" valid:"-"`
+	DestinationAssetType   string `schema:"destination_asset_type" valid:"assetType"`
+	DestinationAssetIssuer string `schema:"destination_asset_issuer" valid:"accountID,optional"`
+	DestinationAssetCode   string `schema:"destination_asset_code" valid:"-"`
+	SourceAmount           string `schema:"source_amount" valid:"amount,optional"`
+	SlippageBps            string `schema:"slippage_bps" valid:"-"`
+}
+
+// URITemplate returns a rfc6570 URI template for the query struct
+func (q OrderBookDepthQuery) URITemplate() string {
+	return getURITemplate(&q, "order_book/depth", false)
+}
+
+// Validate runs custom validations.
+func (q OrderBookDepthQuery) Validate() error {
+	if err := validateAssetParams(
+		q.SourceAssetType,
+		q.SourceAssetCode,
+		q.SourceAssetIssuer,
+		"source_",
+	); err != nil {
+		return err
+	}
+	if err := validateAssetParams(
+		q.DestinationAssetType,
+		q.DestinationAssetCode,
+		q.DestinationAssetIssuer,
+		"destination_",
+	); err != nil {
+		return err
+	}
+	if q.SourceAssetType == q.DestinationAssetType &&
+		q.SourceAssetCode == q.DestinationAssetCode &&
+		q.SourceAssetIssuer == q.DestinationAssetIssuer {
+		return problem.MakeInvalidFieldProblem(
+			"destination_asset_type",
+			errors.New("the source and destination assets must be different"),
+		)
+	}
+	if _, err := q.Slippages(); err != nil {
+		return problem.MakeInvalidFieldProblem("slippage_bps", err)
+	}
+	return nil
+}
+
+// SourceAsset returns the asset sold by the trade.
+func (q OrderBookDepthQuery) SourceAsset() xdr.Asset {
+	asset, err := xdr.BuildAsset(q.SourceAssetType, q.SourceAssetIssuer, q.SourceAssetCode)
+	if err != nil {
+		panic(err)
+	}
+	return asset
+}
+
+// DestinationAsset returns the asset bought by the trade.
+func (q OrderBookDepthQuery) DestinationAsset() xdr.Asset {
+	asset, err := xdr.BuildAsset(q.DestinationAssetType, q.DestinationAssetIssuer, q.DestinationAssetCode)
+	if err != nil {
+		panic(err)
+	}
+	return asset
+}
+
+// Amount returns the source amount of the trade, or 0 if it is not set.
+func (q OrderBookDepthQuery) Amount() xdr.Int64 {
+	if q.SourceAmount == "" {
+		return 0
+	}
+	return amount.MustParse(q.SourceAmount)
+}
+
+// Slippages returns the levels of the depth curve in basis points.
+func (q OrderBookDepthQuery) Slippages() ([]uint32, error) {
+	if q.SlippageBps == "" {
+		return defaultSlippageBps, nil
+	}
+	parts := strings.Split(q.SlippageBps, ",")
+	if len(parts) > maxDepthLevels {
+		return nil, fmt.Errorf("list of slippages exceeds maximum length of %d", maxDepthLevels)
+	}
+	slippages := make([]uint32, len(parts))
+	for i, part := range parts {
+		slippage, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil || slippage > maxSlippageBps {
+			return nil, fmt.Errorf("%q is not a slippage between 0 and %d basis points", part, maxSlippageBps)
+		}
+		slippages[i] = uint32(slippage)
+	}
+	return slippages, nil
+}
+
+// OrderBookDepthHandler is the http handler for the order book depth endpoint.
+// It returns the cumulative depth curve of a trading pair across its offers
+// and liquidity pool, computed from the in memory order book used for path
+// finding, along with the expected execution of a trade of a given size.
+type OrderBookDepthHandler struct {
+	SetLastLedgerHeader bool
+	PathFinder          paths.Finder
+}
+
+// GetResource returns the depth analytics of the trading pair.
+func (handler OrderBookDepthHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	ctx := r.Context()
+	qp := OrderBookDepthQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return nil, err
+	}
+
+	// Rollback REPEATABLE READ transaction so that a DB connection is released
+	// to be used by other http requests.
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not obtain historyQ from request")
+	}
+	if err = historyQ.Rollback(); err != nil {
+		return nil, errors.Wrap(err, "error in rollback")
+	}
+
+	slippages, _ := qp.Slippages()
+	sourceAsset, destinationAsset := qp.SourceAsset(), qp.DestinationAsset()
+	depth, lastIngestedLedger, err := handler.PathFinder.Depth(ctx, orderbook.DepthQuery{
+		SourceAsset:      sourceAsset,
+		DestinationAsset: destinationAsset,
+		SlippageBips:     slippages,
+		SourceAmount:     qp.Amount(),
+	})
+	switch err {
+	case simplepath.ErrEmptyInMemoryOrderBook:
+		return nil, auroraProblem.StillIngesting
+	case paths.ErrRateLimitExceeded:
+		return nil, auroraProblem.ServerOverCapacity
+	default:
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if handler.SetLastLedgerHeader {
+		// To make the Last-Ledger header consistent with the response content,
+		// we need to extract it from the ledger and not the DB.
+		// Thus, we overwrite the header if it was previously set.
+		SetLastLedgerHeader(w, lastIngestedLedger)
+	}
+
+	var response protocol.OrderBookDepth
+	if err = resourceadapter.PopulateOrderBookDepth(ctx, &response, sourceAsset, destinationAsset, depth); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
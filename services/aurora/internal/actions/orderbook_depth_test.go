@@ -0,0 +1,187 @@
+package actions
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/exp/orderbook"
+	"github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/services/aurora/internal/paths"
+	auroraProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/services/aurora/internal/simplepath"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+)
+
+const orderBookDepthTestIssuer = "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN"
+
+func orderBookDepthParams(extra map[string]string) map[string]string {
+	params := map[string]string{
+		"source_asset_type":        "credit_alphanum4",
+		"source_asset_code":        "USD",
+		"source_asset_issuer":      orderBookDepthTestIssuer,
+		"destination_asset_type":   "native",
+		"destination_asset_code":   "",
+		"destination_asset_issuer": "",
+	}
+	for key, value := range extra {
+		params[key] = value
+	}
+	return params
+}
+
+func TestOrderBookDepth(t *testing.T) {
+	usd := xdr.MustNewCreditAsset("USD", orderBookDepthTestIssuer)
+	native := xdr.MustNewNativeAsset()
+	finder := &paths.MockFinder{}
+	handler := OrderBookDepthHandler{SetLastLedgerHeader: true, PathFinder: finder}
+
+	session := &db.MockSession{}
+	session.On("Rollback").Return(nil).Once()
+	request := makeRequest(t, orderBookDepthParams(map[string]string{"source_amount": "150"}), nil, session)
+
+	execution := &orderbook.Execution{
+		Fill:          orderbook.Fill{SourceAmount: 1500000000, DestinationAmount: 1474000000},
+		Offers:        orderbook.Fill{SourceAmount: 1000000000, DestinationAmount: 1000000000},
+		OffersCount:   1,
+		LiquidityPool: orderbook.Fill{SourceAmount: 500000000, DestinationAmount: 474000000},
+		Price:         big.NewRat(1500, 1474),
+		Slippage:      big.NewRat(1500*10000-1474*10000, 1474),
+	}
+	poolID := xdr.PoolId{0xca, 0xfe}
+	finder.On("Depth", mock.Anything, orderbook.DepthQuery{
+		SourceAsset:      usd,
+		DestinationAsset: native,
+		SlippageBips:     defaultSlippageBps,
+		SourceAmount:     1500000000,
+	}).Return(orderbook.Depth{
+		BestPrice:     big.NewRat(1, 1),
+		LiquidityPool: &poolID,
+		Levels: []orderbook.DepthLevel{
+			{
+				SlippageBips: 10,
+				PriceLimit:   big.NewRat(1001, 1000),
+				Execution: orderbook.Execution{
+					Fill:        orderbook.Fill{SourceAmount: 1000000000, DestinationAmount: 1000000000},
+					Offers:      orderbook.Fill{SourceAmount: 1000000000, DestinationAmount: 1000000000},
+					OffersCount: 1,
+				},
+			},
+		},
+		Execution: execution,
+	}, uint32(1234), nil).Once()
+
+	w := httptest.NewRecorder()
+	resource, err := handler.GetResource(w, request)
+	require.NoError(t, err)
+	assert.Equal(t, "1234", w.Header().Get(LastLedgerHeaderName))
+
+	depth := resource.(aurora.OrderBookDepth)
+	assert.Equal(t, "credit_alphanum4", depth.SourceAssetType)
+	assert.Equal(t, "USD", depth.SourceAssetCode)
+	assert.Equal(t, "native", depth.DestinationAssetType)
+	assert.Equal(t, "1.0000000", depth.BestPrice)
+	assert.Equal(t, xdr.Hash(poolID).HexString(), depth.LiquidityPool)
+
+	require.Len(t, depth.Levels, 1)
+	assert.Equal(t, uint32(10), depth.Levels[0].MaxSlippageBps)
+	assert.Equal(t, "1.0010000", depth.Levels[0].PriceLimit)
+	assert.Equal(t, "100.0000000", depth.Levels[0].DestinationAmount)
+	assert.Equal(t, "0.0000000", depth.Levels[0].LiquidityPool.SourceAmount)
+
+	require.NotNil(t, depth.Execution)
+	assert.Equal(t, "150.0000000", depth.Execution.SourceAmount)
+	assert.Equal(t, "147.4000000", depth.Execution.DestinationAmount)
+	assert.Equal(t, "1.0176391", depth.Execution.Price)
+	assert.Equal(t, "176.39", depth.Execution.SlippageBps)
+	assert.Equal(t, 1, depth.Execution.OffersCount)
+	assert.Equal(t, "50.0000000", depth.Execution.LiquidityPool.SourceAmount)
+	assert.Nil(t, depth.OffersOnly)
+	assert.Nil(t, depth.LiquidityPoolOnly)
+
+	finder.AssertExpectations(t)
+	session.AssertExpectations(t)
+}
+
+func TestOrderBookDepthSlippages(t *testing.T) {
+	finder := &paths.MockFinder{}
+	handler := OrderBookDepthHandler{PathFinder: finder}
+
+	session := &db.MockSession{}
+	session.On("Rollback").Return(nil).Once()
+	request := makeRequest(t, orderBookDepthParams(map[string]string{"slippage_bps": "0, 50,10000"}), nil, session)
+
+	finder.On("Depth", mock.Anything, mock.MatchedBy(func(q orderbook.DepthQuery) bool {
+		return assert.Equal(t, []uint32{0, 50, 10000}, q.SlippageBips) &&
+			assert.Equal(t, xdr.Int64(0), q.SourceAmount)
+	})).Return(orderbook.Depth{}, uint32(10), nil).Once()
+
+	resource, err := handler.GetResource(httptest.NewRecorder(), request)
+	require.NoError(t, err)
+	depth := resource.(aurora.OrderBookDepth)
+	assert.Empty(t, depth.Levels)
+	assert.Empty(t, depth.BestPrice)
+	assert.Nil(t, depth.Execution)
+
+	finder.AssertExpectations(t)
+}
+
+func TestOrderBookDepthValidation(t *testing.T) {
+	handler := OrderBookDepthHandler{PathFinder: &paths.MockFinder{}}
+
+	for _, testCase := range []struct {
+		name   string
+		params map[string]string
+		field  string
+	}{
+		{
+			"same assets",
+			map[string]string{"source_asset_type": "native", "source_asset_code": "", "source_asset_issuer": ""},
+			"destination_asset_type",
+		},
+		{"invalid amount", map[string]string{"source_amount": "-1"}, "source_amount"},
+		{"invalid slippage", map[string]string{"slippage_bps": "10,abc"}, "slippage_bps"},
+		{"slippage too large", map[string]string{"slippage_bps": "10001"}, "slippage_bps"},
+		{
+			"too many levels",
+			map[string]string{"slippage_bps": "1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20,21"},
+			"slippage_bps",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := makeRequest(t, orderBookDepthParams(testCase.params), nil, &db.MockSession{})
+			_, err := handler.GetResource(httptest.NewRecorder(), request)
+			p, ok := err.(*problem.P)
+			require.True(t, ok, "unexpected error %v", err)
+			assert.Equal(t, http.StatusBadRequest, p.Status)
+			assert.Equal(t, testCase.field, p.Extras["invalid_field"])
+		})
+	}
+}
+
+func TestOrderBookDepthFinderErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		err      error
+		expected error
+	}{
+		{simplepath.ErrEmptyInMemoryOrderBook, auroraProblem.StillIngesting},
+		{paths.ErrRateLimitExceeded, auroraProblem.ServerOverCapacity},
+	} {
+		finder := &paths.MockFinder{}
+		handler := OrderBookDepthHandler{PathFinder: finder}
+		session := &db.MockSession{}
+		session.On("Rollback").Return(nil).Once()
+		finder.On("Depth", mock.Anything, mock.Anything).
+			Return(orderbook.Depth{}, uint32(0), testCase.err).Once()
+
+		_, err := handler.GetResource(httptest.NewRecorder(), makeRequest(t, orderBookDepthParams(nil), nil, session))
+		assert.Equal(t, testCase.expected, err)
+	}
+}
@@ -0,0 +1,255 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hcnet/go/protocols/aurora"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/paths"
+	auroraProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/services/aurora/internal/resourceadapter"
+	"github.com/hcnet/go/services/aurora/internal/simplepath"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/hal"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+)
+
+const (
+	// StrictReceivePathsRequestType is the type of the strict receive requests
+	// of a paths batch.
+	StrictReceivePathsRequestType = "strict_receive"
+	// StrictSendPathsRequestType is the type of the strict send requests of a
+	// paths batch.
+	StrictSendPathsRequestType = "strict_send"
+	// DefaultMaxPathsBatchSize is the maximum number of requests of a paths
+	// batch used when FindPathsBatchHandler.MaxBatchSize is not set.
+	DefaultMaxPathsBatchSize = 20
+)
+
+// FindPathsBatchHandler is the http handler for the batch payment paths
+// endpoint. It finds the payment paths of many strict receive and strict send
+// requests at once, all of them consistent with the same ledger.
+type FindPathsBatchHandler struct {
+	MaxPathLength        uint
+	MaxAssetsParamLength int
+	MaxBatchSize         int
+	SetLastLedgerHeader  bool
+	PathFinder           paths.Finder
+}
+
+// PathsBatchRequest is the body of a paths batch request. Each request
+// contains the query parameters of either the /paths/strict-receive or the
+// /paths/strict-send endpoint along with its type, strict_receive or
+// strict_send.
+type PathsBatchRequest struct {
+	Requests []map[string]string `json:"requests"`
+}
+
+// GetResource returns the payment paths of each request of the batch, in the
+// same order as the requests.
+func (handler FindPathsBatchHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	ctx := r.Context()
+	var body PathsBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, problem.NewProblemWithInvalidField(
+			problem.BadRequest, "requests", fmt.Errorf("invalid json for paths batch request: %v", err),
+		)
+	}
+
+	maxBatchSize := handler.MaxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = DefaultMaxPathsBatchSize
+	}
+	if len(body.Requests) == 0 {
+		return nil, problem.MakeInvalidFieldProblem(
+			"requests", errors.New("the batch must contain at least one request"),
+		)
+	}
+	if len(body.Requests) > maxBatchSize {
+		return nil, problem.MakeInvalidFieldProblem(
+			"requests", fmt.Errorf("the batch exceeds the maximum number of requests of %d", maxBatchSize),
+		)
+	}
+
+	requests := make([]paths.Request, len(body.Requests))
+	for i, params := range body.Requests {
+		request, err := handler.request(r, params)
+		if err != nil {
+			return nil, batchRequestProblem(i, err)
+		}
+		requests[i] = request
+	}
+
+	// Rollback REPEATABLE READ transaction so that a DB connection is released
+	// to be used by other http requests.
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not obtain historyQ from request")
+	}
+
+	err = historyQ.Rollback()
+	if err != nil {
+		return nil, errors.Wrap(err, "error in rollback")
+	}
+
+	// requests without source assets (or destination assets for strict send
+	// requests) have no payment paths and are not sent to the path finder
+	var indexes []int
+	var searched []paths.Request
+	for i, request := range requests {
+		if (request.StrictSend && len(request.DestinationAssets) > 0) ||
+			(!request.StrictSend && len(request.Query.SourceAssets) > 0) {
+			indexes = append(indexes, i)
+			searched = append(searched, request)
+		}
+	}
+
+	results := make([][]paths.Path, len(requests))
+	if len(searched) > 0 {
+		var found [][]paths.Path
+		var lastIngestedLedger uint32
+		found, lastIngestedLedger, err = handler.PathFinder.FindBatch(ctx, searched, handler.MaxPathLength)
+		switch err {
+		case simplepath.ErrEmptyInMemoryOrderBook:
+			return nil, auroraProblem.StillIngesting
+		case paths.ErrRateLimitExceeded:
+			return nil, auroraProblem.ServerOverCapacity
+		default:
+			if err != nil {
+				return nil, err
+			}
+		}
+		for i, index := range indexes {
+			results[index] = found[i]
+		}
+
+		if handler.SetLastLedgerHeader {
+			// To make the Last-Ledger header consistent with the response content,
+			// we need to extract it from the ledger and not the DB.
+			// Thus, we overwrite the header if it was previously set.
+			SetLastLedgerHeader(w, lastIngestedLedger)
+		}
+	}
+
+	var page hal.BasePage
+	page.Init()
+	for i, records := range results {
+		res := aurora.PathsBatchResult{Type: StrictReceivePathsRequestType}
+		if requests[i].StrictSend {
+			res.Type = StrictSendPathsRequestType
+		}
+		res.Embedded.Records = make([]aurora.Path, len(records))
+		for j, record := range records {
+			if err = resourceadapter.PopulatePath(ctx, &res.Embedded.Records[j], record); err != nil {
+				return nil, err
+			}
+		}
+		page.Add(res)
+	}
+	return page, nil
+}
+
+// request validates the parameters of a single request of the batch in the
+// same way as the /paths/strict-receive and /paths/strict-send endpoints.
+func (handler FindPathsBatchHandler) request(r *http.Request, params map[string]string) (paths.Request, error) {
+	query := url.Values{}
+	for key, value := range params {
+		if key != "type" {
+			query.Set(key, value)
+		}
+	}
+
+	switch params["type"] {
+	case StrictReceivePathsRequestType:
+		qp := StrictReceivePathsQuery{}
+		if err := decodeParams(&qp, query); err != nil {
+			return paths.Request{}, err
+		}
+
+		q := paths.Query{
+			DestinationAsset:  qp.DestinationAsset(),
+			DestinationAmount: qp.Amount(),
+		}
+		q.SourceAssets, _ = qp.Assets()
+		if len(q.SourceAssets) > handler.MaxAssetsParamLength {
+			return paths.Request{}, problem.MakeInvalidFieldProblem(
+				"source_assets",
+				fmt.Errorf("list of assets exceeds maximum length of %d", handler.MaxAssetsParamLength),
+			)
+		}
+		if qp.SourceAccount != "" {
+			sourceAccount := xdr.MustAddress(qp.SourceAccount)
+			q.SourceAccount = &sourceAccount
+			q.ValidateSourceBalance = true
+			var err error
+			q.SourceAssets, q.SourceAssetBalances, err = assetsForAddress(r, qp.SourceAccount)
+			if err != nil {
+				return paths.Request{}, err
+			}
+		} else {
+			q.SourceAssetBalances = make([]xdr.Int64, len(q.SourceAssets))
+		}
+		return paths.Request{Query: q}, nil
+	case StrictSendPathsRequestType:
+		qp := FindFixedPathsQuery{}
+		if err := decodeParams(&qp, query); err != nil {
+			return paths.Request{}, err
+		}
+
+		destinationAssets, _ := qp.Assets()
+		if len(destinationAssets) > handler.MaxAssetsParamLength {
+			return paths.Request{}, problem.MakeInvalidFieldProblem(
+				"destination_assets",
+				fmt.Errorf("list of assets exceeds maximum length of %d", handler.MaxAssetsParamLength),
+			)
+		}
+		if qp.DestinationAccount != "" {
+			var err error
+			destinationAssets, _, err = assetsForAddress(r, qp.DestinationAccount)
+			if err != nil {
+				return paths.Request{}, err
+			}
+		}
+		return paths.Request{
+			StrictSend:        true,
+			SourceAsset:       qp.SourceAsset(),
+			AmountToSpend:     qp.Amount(),
+			DestinationAssets: destinationAssets,
+		}, nil
+	default:
+		return paths.Request{}, problem.MakeInvalidFieldProblem(
+			"type",
+			fmt.Errorf("type must be either %s or %s", StrictReceivePathsRequestType, StrictSendPathsRequestType),
+		)
+	}
+}
+
+// batchRequestProblem prefixes the invalid field of a problem caused by a
+// request of the batch with the position of the request.
+func batchRequestProblem(index int, err error) error {
+	var p problem.P
+	switch e := err.(type) {
+	case *problem.P:
+		p = *e
+	case problem.P:
+		p = e
+	default:
+		return err
+	}
+
+	extras := map[string]interface{}{}
+	for key, value := range p.Extras {
+		extras[key] = value
+	}
+	field := fmt.Sprintf("requests[%d]", index)
+	if name, ok := extras["invalid_field"].(string); ok && name != "" {
+		field += "." + name
+	}
+	extras["invalid_field"] = field
+	p.Extras = extras
+	return &p
+}
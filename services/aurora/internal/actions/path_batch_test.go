@@ -0,0 +1,175 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/protocols/aurora"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/paths"
+	auroraProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/render/hal"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+)
+
+const pathsBatchTestIssuer = "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN"
+
+func makePathsBatchRequest(body string) (*http.Request, *db.MockSession) {
+	session := &db.MockSession{}
+	request := httptest.NewRequest(http.MethodPost, "/paths/batch", strings.NewReader(body))
+	ctx := context.WithValue(context.Background(), &auroraContext.SessionContextKey, session)
+	return request.WithContext(ctx), session
+}
+
+func TestFindPathsBatch(t *testing.T) {
+	eur := xdr.MustNewCreditAsset("EUR", pathsBatchTestIssuer)
+	native := xdr.MustNewNativeAsset()
+	finder := &paths.MockFinder{}
+	handler := FindPathsBatchHandler{
+		MaxPathLength:        3,
+		MaxAssetsParamLength: 2,
+		SetLastLedgerHeader:  true,
+		PathFinder:           finder,
+	}
+
+	request, session := makePathsBatchRequest(`{"requests": [
+		{
+			"type": "strict_receive",
+			"source_assets": "native",
+			"destination_asset_type": "credit_alphanum4",
+			"destination_asset_code": "EUR",
+			"destination_asset_issuer": "` + pathsBatchTestIssuer + `",
+			"destination_amount": "10"
+		},
+		{
+			"type": "strict_send",
+			"source_asset_type": "native",
+			"source_amount": "5",
+			"destination_assets": "EUR:` + pathsBatchTestIssuer + `"
+		}
+	]}`)
+	session.On("Rollback").Return(nil).Once()
+
+	expectedRequests := []paths.Request{
+		{Query: paths.Query{
+			DestinationAsset:    eur,
+			DestinationAmount:   100000000,
+			SourceAssets:        []xdr.Asset{native},
+			SourceAssetBalances: []xdr.Int64{0},
+		}},
+		{
+			StrictSend:        true,
+			SourceAsset:       native,
+			AmountToSpend:     50000000,
+			DestinationAssets: []xdr.Asset{eur},
+		},
+	}
+	finder.On("FindBatch", mock.Anything, expectedRequests, uint(3)).Return(
+		[][]paths.Path{
+			{{Path: []string{}, Source: native.String(), SourceAmount: 200000000, Destination: eur.String(), DestinationAmount: 100000000}},
+			{},
+		},
+		uint32(1234),
+		nil,
+	).Once()
+
+	w := httptest.NewRecorder()
+	resource, err := handler.GetResource(w, request)
+	require.NoError(t, err)
+	assert.Equal(t, "1234", w.Header().Get(LastLedgerHeaderName))
+
+	records := resource.(hal.BasePage).Embedded.Records
+	require.Len(t, records, 2)
+	strictReceive := records[0].(aurora.PathsBatchResult)
+	assert.Equal(t, StrictReceivePathsRequestType, strictReceive.Type)
+	require.Len(t, strictReceive.Embedded.Records, 1)
+	assert.Equal(t, "20.0000000", strictReceive.Embedded.Records[0].SourceAmount)
+	assert.Equal(t, "EUR", strictReceive.Embedded.Records[0].DestinationAssetCode)
+	strictSend := records[1].(aurora.PathsBatchResult)
+	assert.Equal(t, StrictSendPathsRequestType, strictSend.Type)
+	assert.Empty(t, strictSend.Embedded.Records)
+
+	finder.AssertExpectations(t)
+	session.AssertExpectations(t)
+}
+
+func TestFindPathsBatchValidation(t *testing.T) {
+	handler := FindPathsBatchHandler{
+		MaxPathLength:        3,
+		MaxAssetsParamLength: 2,
+		MaxBatchSize:         2,
+		PathFinder:           &paths.MockFinder{},
+	}
+
+	for _, testCase := range []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"invalid json", `{"requests": [`, "requests"},
+		{"empty batch", `{"requests": []}`, "requests"},
+		{"too many requests", `{"requests": [{}, {}, {}]}`, "requests"},
+		{"invalid type", `{"requests": [{"type": "strict"}]}`, "requests[0].type"},
+		{
+			"invalid amount",
+			`{"requests": [{"type": "strict_send", "source_asset_type": "native", "source_amount": "-1", "destination_assets": "native"}]}`,
+			"requests[0].source_amount",
+		},
+		{
+			"too many assets",
+			`{"requests": [
+				{"type": "strict_send", "source_asset_type": "native", "source_amount": "1", "destination_assets": "native"},
+				{"type": "strict_receive", "source_assets": "native,native,native", "destination_asset_type": "native", "destination_amount": "1"}
+			]}`,
+			"requests[1].source_assets",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, _ := makePathsBatchRequest(testCase.body)
+			_, err := handler.GetResource(httptest.NewRecorder(), request)
+			p, ok := err.(*problem.P)
+			require.True(t, ok, "unexpected error %v", err)
+			assert.Equal(t, http.StatusBadRequest, p.Status)
+			assert.Equal(t, testCase.field, p.Extras["invalid_field"])
+		})
+	}
+
+	// problems without an invalid field are reported for the whole request
+	request, _ := makePathsBatchRequest(`{"requests": [{
+		"type": "strict_receive",
+		"destination_asset_type": "native",
+		"destination_amount": "1"
+	}]}`)
+	_, err := handler.GetResource(httptest.NewRecorder(), request)
+	p, ok := err.(*problem.P)
+	require.True(t, ok, "unexpected error %v", err)
+	assert.Equal(t, SourceAssetsOrSourceAccountProblem.Detail, p.Detail)
+	assert.Equal(t, "requests[0]", p.Extras["invalid_field"])
+}
+
+func TestFindPathsBatchRateLimited(t *testing.T) {
+	finder := &paths.MockFinder{}
+	handler := FindPathsBatchHandler{MaxPathLength: 3, MaxAssetsParamLength: 2, PathFinder: finder}
+
+	request, session := makePathsBatchRequest(`{"requests": [{
+		"type": "strict_send",
+		"source_asset_type": "native",
+		"source_amount": "5",
+		"destination_assets": "native"
+	}]}`)
+	session.On("Rollback").Return(nil).Once()
+	finder.On("FindBatch", mock.Anything, mock.Anything, uint(3)).
+		Return([][]paths.Path(nil), uint32(0), paths.ErrRateLimitExceeded).Once()
+
+	_, err := handler.GetResource(httptest.NewRecorder(), request)
+	assert.Equal(t, auroraProblem.ServerOverCapacity, err)
+}
@@ -0,0 +1,209 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/ledger"
+	"github.com/hcnet/go/services/aurora/internal/render/sse"
+	"github.com/hcnet/go/services/aurora/internal/ssehub"
+	"github.com/hcnet/go/services/aurora/internal/test"
+	"github.com/hcnet/go/support/render/hal"
+	"github.com/hcnet/go/toid"
+	"github.com/hcnet/go/xdr"
+)
+
+type ledgerRendererPageAction interface {
+	GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error)
+	LedgerRenderer(r *http.Request) (ssehub.RenderFunc, bool, error)
+}
+
+// hubTest streams the requests of a test from an SSE hub running over the
+// ledgers of the database.
+type hubTest struct {
+	tt          *test.T
+	q           *history.Q
+	ledgerState *ledger.State
+	hub         *ssehub.Hub
+}
+
+func startTestHub(tt *test.T, status ledger.Status) *hubTest {
+	ledgerState := &ledger.State{}
+	ledgerState.SetStatus(status)
+	q := &history.Q{tt.AuroraSession()}
+
+	hub := ssehub.New(ssehub.Config{
+		BufferLedgers: 100,
+		PollInterval:  10 * time.Millisecond,
+	}, tt.AuroraSession(), ledgerState)
+	go hub.Run()
+	tt.T.Cleanup(hub.Shutdown)
+
+	latest := uint32(status.HistoryLatest)
+	tt.Require.Eventually(func() bool {
+		return hub.Latest() == latest
+	}, 10*time.Second, 10*time.Millisecond, "the hub did not load the ledgers")
+
+	return &hubTest{tt: tt, q: q, ledgerState: ledgerState, hub: hub}
+}
+
+// assertMatchesPage checks that the events streamed from the hub for the
+// request are the records of its database page, and returns them.
+func (h *hubTest) assertMatchesPage(
+	action ledgerRendererPageAction,
+	queryParams map[string]string,
+	routeParams map[string]string,
+) []hal.Pageable {
+	tt := h.tt
+	cursor := strconv.FormatInt(toid.New(h.ledgerState.CurrentStatus().HistoryElder, 0, 0).ToInt64(), 10)
+	params := map[string]string{"cursor": cursor, "limit": "200"}
+	for key, value := range queryParams {
+		params[key] = value
+	}
+
+	records, err := action.GetResourcePage(
+		httptest.NewRecorder(),
+		makeRequest(tt.T, params, routeParams, h.q),
+	)
+	tt.Require.NoError(err)
+
+	r := makeRequest(tt.T, params, routeParams, h.q)
+	render, ok, err := action.LedgerRenderer(r)
+	tt.Require.NoError(err)
+	tt.Require.True(ok, "the stream can't be served by the hub")
+	generateEvents, err := h.hub.GenerateEvents(r, cursor, render, func() ([]sse.Event, error) {
+		tt.T.Fatal("the stream fell back to the database")
+		return nil, nil
+	})
+	tt.Require.NoError(err)
+	events, err := generateEvents()
+	tt.Require.NoError(err)
+
+	tt.Require.Len(events, len(records), "params: %v", params)
+	for i, record := range records {
+		tt.Assert.Equal(record.PagingToken(), events[i].ID)
+		expected, err := json.Marshal(record)
+		tt.Require.NoError(err)
+		actual, err := json.Marshal(events[i].Data)
+		tt.Require.NoError(err)
+		tt.Assert.JSONEq(string(expected), string(actual))
+	}
+	return records
+}
+
+// failedTransactionSource returns the source account of a failed transaction
+// of the page.
+func failedTransactionSource(tt *test.T, records []hal.Pageable) string {
+	for _, record := range records {
+		if tx := record.(aurora.Transaction); !tx.Successful {
+			return tx.Account
+		}
+	}
+	tt.T.Fatal("no failed transaction")
+	return ""
+}
+
+func TestHubOperationsMatchDatabase(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	h := startTestHub(tt, tt.Scenario("failed_transactions"))
+
+	txs := h.assertMatchesPage(
+		GetTransactionsHandler{LedgerState: h.ledgerState},
+		map[string]string{"include_failed": "true"}, nil,
+	)
+	account := failedTransactionSource(tt, txs)
+
+	for _, onlyPayments := range []bool{false, true} {
+		handler := GetOperationsHandler{LedgerState: h.ledgerState, OnlyPayments: onlyPayments}
+		for _, query := range []map[string]string{
+			{},
+			{"include_failed": "true"},
+			{"join": "transactions"},
+			{"include_failed": "true", "join": "transactions"},
+			{"include_failed": "true", "account_id": account},
+			{"include_failed": "true", "join": "transactions", "account_id": account},
+		} {
+			h.assertMatchesPage(handler, query, nil)
+		}
+	}
+
+	records := h.assertMatchesPage(
+		GetOperationsHandler{LedgerState: h.ledgerState},
+		map[string]string{"include_failed": "true"}, nil,
+	)
+	tt.Assert.Len(records, 9)
+}
+
+func TestHubTransactionsMatchDatabase(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	h := startTestHub(tt, tt.Scenario("failed_transactions"))
+	handler := GetTransactionsHandler{LedgerState: h.ledgerState}
+
+	records := h.assertMatchesPage(handler, nil, nil)
+	tt.Assert.Len(records, 8)
+	records = h.assertMatchesPage(handler, map[string]string{"include_failed": "true"}, nil)
+	tt.Assert.Len(records, 9)
+
+	account := failedTransactionSource(tt, records)
+	h.assertMatchesPage(handler, map[string]string{"account_id": account}, nil)
+	h.assertMatchesPage(handler, map[string]string{"include_failed": "true", "account_id": account}, nil)
+}
+
+func TestHubEffectsMatchDatabase(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	h := startTestHub(tt, tt.Scenario("failed_transactions"))
+	handler := GetEffectsHandler{LedgerState: h.ledgerState}
+
+	records := h.assertMatchesPage(handler, nil, nil)
+	tt.Assert.NotEmpty(records)
+
+	txs := h.assertMatchesPage(
+		GetTransactionsHandler{LedgerState: h.ledgerState},
+		map[string]string{"include_failed": "true"}, nil,
+	)
+	h.assertMatchesPage(handler, map[string]string{"account_id": failedTransactionSource(tt, txs)}, nil)
+}
+
+func TestHubTradesMatchDatabase(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &history.Q{tt.AuroraSession()}
+	fixtures := history.TradeScenario(tt, q)
+
+	// The trades of the scenario are in ledger 3, which the hub only loads
+	// once it's ingested.
+	ledgerBatch := q.NewLedgerBatchInsertBuilder()
+	tt.Require.NoError(ledgerBatch.Add(xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{LedgerSeq: 3},
+	}, 0, 0, 0, 0, 0))
+	tt.Require.NoError(q.Begin(tt.Ctx))
+	tt.Require.NoError(ledgerBatch.Exec(tt.Ctx, q))
+	tt.Require.NoError(q.Commit())
+
+	h := startTestHub(tt, ledger.Status{
+		AuroraStatus: ledger.AuroraStatus{HistoryLatest: 3, HistoryElder: 3},
+	})
+	handler := GetTradesHandler{LedgerState: h.ledgerState}
+
+	records := h.assertMatchesPage(handler, nil, nil)
+	tt.Assert.Len(records, len(fixtures.Trades))
+	for _, query := range []map[string]string{
+		{"trade_type": history.OrderbookTrades},
+		{"trade_type": history.LiquidityPoolTrades},
+		{"account_id": fixtures.Addresses[0]},
+		{"account_id": fixtures.Addresses[1], "trade_type": history.OrderbookTrades},
+		{"liquidity_pool_id": fixtures.LiquidityPools[0]},
+	} {
+		h.assertMatchesPage(handler, query, nil)
+	}
+}
@@ -0,0 +1,449 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/ingest/processors"
+	hProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+)
+
+// AtLedgerQuery is embedded in the query structs of the state endpoints which
+// can be queried at a past ledger. When AtLedger is set the resources are
+// loaded from the state history instead of the state tables.
+type AtLedgerQuery struct {
+	AtLedger uint32 `schema:"at_ledger" valid:"-"`
+}
+
+var stateHistoryNotAvailable = problem.P{
+	Type:   "state_history_not_available",
+	Title:  "State History Not Available",
+	Status: http.StatusNotImplemented,
+	Detail: "The at_ledger parameter requires the state history, which is not " +
+		"available on this server. The state history is recorded when Aurora " +
+		"runs with --enable-state-history, starting at the next state rebuild.",
+}
+
+// checkAtLedger returns an error if the state history can't answer for the
+// given ledger.
+func checkAtLedger(ctx context.Context, historyQ *history.Q, sequence uint32) error {
+	start, last, err := historyQ.GetStateHistoryRange(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case start == 0:
+		return stateHistoryNotAvailable
+	case sequence < start:
+		// the versions of that ledger were reaped or never recorded
+		return hProblem.BeforeHistory
+	case sequence > last:
+		return problem.MakeInvalidFieldProblem(
+			"at_ledger",
+			errors.Errorf("ledger %d has not been ingested yet, the last ingested ledger is %d", sequence, last),
+		)
+	}
+	return nil
+}
+
+// loadStateRowsByKeys loads the entries with the given keys at a past ledger.
+func loadStateRowsByKeys(ctx context.Context, historyQ *history.Q, keys []xdr.LedgerKey, sequence uint32) (processors.StateRows, error) {
+	encoded := make([]string, 0, len(keys))
+	for _, key := range keys {
+		keyString, err := key.MarshalBinaryBase64()
+		if err != nil {
+			return processors.StateRows{}, errors.Wrap(err, "error marshaling ledger key")
+		}
+		encoded = append(encoded, keyString)
+	}
+
+	entries, err := historyQ.GetStateHistoryEntriesByKeys(ctx, encoded, sequence)
+	if err != nil {
+		return processors.StateRows{}, err
+	}
+	return processors.NewStateRows(entries)
+}
+
+// loadStateRowsForAccount loads the entries of the given types involving an
+// account at a past ledger.
+func loadStateRowsForAccount(
+	ctx context.Context,
+	historyQ *history.Q,
+	account string,
+	entryTypes []xdr.LedgerEntryType,
+	sequence uint32,
+) (processors.StateRows, error) {
+	entries, err := historyQ.GetStateHistoryEntriesForAccount(ctx, account, entryTypes, sequence)
+	if err != nil {
+		return processors.StateRows{}, err
+	}
+	return processors.NewStateRows(entries)
+}
+
+// accountStateAtLedger returns the rows of an account, its data entries,
+// signers and trust lines at a past ledger, ordered like the rows loaded from
+// the state tables.
+func accountStateAtLedger(ctx context.Context, historyQ *history.Q, addr string, sequence uint32) (
+	history.AccountEntry, []history.Data, []history.AccountSigner, []history.TrustLine, error,
+) {
+	rows, err := loadStateRowsForAccount(ctx, historyQ, addr, []xdr.LedgerEntryType{
+		xdr.LedgerEntryTypeAccount,
+		xdr.LedgerEntryTypeData,
+		xdr.LedgerEntryTypeTrustline,
+	}, sequence)
+	if err != nil {
+		return history.AccountEntry{}, nil, nil, nil, errors.Wrap(err, "loading state history")
+	}
+
+	// the rows include the entries sponsored by the account
+	var (
+		record     history.AccountEntry
+		found      bool
+		data       []history.Data
+		signers    []history.AccountSigner
+		trustlines []history.TrustLine
+	)
+	for _, account := range rows.Accounts {
+		if account.AccountID == addr {
+			record, found = account, true
+		}
+	}
+	if !found {
+		return record, nil, nil, nil, sql.ErrNoRows
+	}
+	for _, signer := range rows.Signers {
+		if signer.Account == addr {
+			signers = append(signers, signer)
+		}
+	}
+	for _, d := range rows.Data {
+		if d.AccountID == addr {
+			data = append(data, d)
+		}
+	}
+	sort.Slice(data, func(i, j int) bool {
+		return data[i].Name < data[j].Name
+	})
+	for _, trustline := range rows.TrustLines {
+		if trustline.AccountID == addr {
+			trustlines = append(trustlines, trustline)
+		}
+	}
+	sort.Slice(trustlines, func(i, j int) bool {
+		a, b := trustlines[i], trustlines[j]
+		if a.AssetCode != b.AssetCode {
+			return a.AssetCode < b.AssetCode
+		}
+		if a.AssetIssuer != b.AssetIssuer {
+			return a.AssetIssuer < b.AssetIssuer
+		}
+		return a.LiquidityPoolID < b.LiquidityPoolID
+	})
+
+	return record, data, signers, trustlines, nil
+}
+
+// offerAtLedger returns the offer with the given id at a past ledger.
+func offerAtLedger(ctx context.Context, historyQ *history.Q, offerID int64, sequence uint32) (history.Offer, error) {
+	if err := checkAtLedger(ctx, historyQ, sequence); err != nil {
+		return history.Offer{}, err
+	}
+
+	entries, err := historyQ.GetStateHistoryOfferByID(ctx, offerID, sequence)
+	if err != nil {
+		return history.Offer{}, err
+	}
+	rows, err := processors.NewStateRows(entries)
+	if err != nil {
+		return history.Offer{}, err
+	}
+	if len(rows.Offers) == 0 {
+		return history.Offer{}, sql.ErrNoRows
+	}
+	return rows.Offers[0], nil
+}
+
+// offersAtLedger returns a page of the offers matching the query at a past
+// ledger. The query must be filtered by seller or sponsor.
+func offersAtLedger(ctx context.Context, historyQ *history.Q, query history.OffersQuery, sequence uint32) ([]history.Offer, error) {
+	account := query.SellerID
+	if account == "" {
+		account = query.Sponsor
+	}
+	if account == "" {
+		return nil, problem.MakeInvalidFieldProblem(
+			"at_ledger",
+			errors.New("offers can be queried at a past ledger only when filtered by seller or sponsor"),
+		)
+	}
+
+	rows, err := loadStateRowsForAccount(ctx, historyQ, account, []xdr.LedgerEntryType{xdr.LedgerEntryTypeOffer}, sequence)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading state history")
+	}
+
+	var offers []history.Offer
+	for _, offer := range rows.Offers {
+		switch {
+		case query.SellerID != "" && offer.SellerID != query.SellerID:
+		case query.Sponsor != "" && offer.Sponsor.String != query.Sponsor:
+		case query.Selling != nil && !offer.SellingAsset.Equals(*query.Selling):
+		case query.Buying != nil && !offer.BuyingAsset.Equals(*query.Buying):
+		default:
+			offers = append(offers, offer)
+		}
+	}
+	sort.Slice(offers, func(i, j int) bool {
+		return offers[i].OfferID < offers[j].OfferID
+	})
+
+	return pageStateRecords(offers, query.PageQuery, func(offer history.Offer, cursor string) (int, error) {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return 0, problem.MakeInvalidFieldProblem("cursor", errors.New("cursor must be an offer id"))
+		}
+		return compareInt64(offer.OfferID, id), nil
+	})
+}
+
+// claimableBalanceAtLedger returns the claimable balance with the given id at
+// a past ledger.
+func claimableBalanceAtLedger(ctx context.Context, historyQ *history.Q, id string, sequence uint32) (history.ClaimableBalance, error) {
+	if err := checkAtLedger(ctx, historyQ, sequence); err != nil {
+		return history.ClaimableBalance{}, err
+	}
+
+	var balanceID xdr.ClaimableBalanceId
+	if err := xdr.SafeUnmarshalHex(id, &balanceID); err != nil {
+		return history.ClaimableBalance{}, problem.MakeInvalidFieldProblem("id", errors.New("invalid claimable balance id"))
+	}
+	var key xdr.LedgerKey
+	if err := key.SetClaimableBalance(balanceID); err != nil {
+		return history.ClaimableBalance{}, err
+	}
+
+	rows, err := loadStateRowsByKeys(ctx, historyQ, []xdr.LedgerKey{key}, sequence)
+	if err != nil {
+		return history.ClaimableBalance{}, errors.Wrap(err, "loading state history")
+	}
+	if len(rows.ClaimableBalances) == 0 {
+		return history.ClaimableBalance{}, sql.ErrNoRows
+	}
+	return rows.ClaimableBalances[0], nil
+}
+
+// claimableBalancesAtLedger returns a page of the claimable balances matching
+// the query at a past ledger. The query must be filtered by claimant or
+// sponsor.
+func claimableBalancesAtLedger(
+	ctx context.Context,
+	historyQ *history.Q,
+	query history.ClaimableBalancesQuery,
+	sequence uint32,
+) ([]history.ClaimableBalance, error) {
+	var account string
+	switch {
+	case query.Claimant != nil:
+		account = query.Claimant.Address()
+	case query.Sponsor != nil:
+		account = query.Sponsor.Address()
+	default:
+		return nil, problem.MakeInvalidFieldProblem(
+			"at_ledger",
+			errors.New("claimable balances can be queried at a past ledger only when filtered by claimant or sponsor"),
+		)
+	}
+
+	rows, err := loadStateRowsForAccount(ctx, historyQ, account, []xdr.LedgerEntryType{xdr.LedgerEntryTypeClaimableBalance}, sequence)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading state history")
+	}
+
+	var balances []history.ClaimableBalance
+	for _, balance := range rows.ClaimableBalances {
+		switch {
+		case query.Claimant != nil && !hasClaimant(balance, query.Claimant.Address()):
+		case query.Sponsor != nil && balance.Sponsor.String != query.Sponsor.Address():
+		case query.Asset != nil && !balance.Asset.Equals(*query.Asset):
+		default:
+			balances = append(balances, balance)
+		}
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		a, b := balances[i], balances[j]
+		if a.LastModifiedLedger != b.LastModifiedLedger {
+			return a.LastModifiedLedger < b.LastModifiedLedger
+		}
+		return a.BalanceID < b.BalanceID
+	})
+
+	// the cursor was validated by the caller
+	return pageStateRecords(balances, query.PageQuery, func(balance history.ClaimableBalance, cursor string) (int, error) {
+		parts := strings.SplitN(cursor, "-", 2)
+		l, _ := strconv.ParseInt(parts[0], 10, 64)
+		if c := compareInt64(int64(balance.LastModifiedLedger), l); c != 0 {
+			return c, nil
+		}
+		return strings.Compare(balance.BalanceID, parts[1]), nil
+	})
+}
+
+// liquidityPoolAtLedger returns the liquidity pool with the given id at a
+// past ledger.
+func liquidityPoolAtLedger(ctx context.Context, historyQ *history.Q, id string, sequence uint32) (history.LiquidityPool, error) {
+	if err := checkAtLedger(ctx, historyQ, sequence); err != nil {
+		return history.LiquidityPool{}, err
+	}
+
+	key, err := liquidityPoolLedgerKey(id)
+	if err != nil {
+		return history.LiquidityPool{}, err
+	}
+	rows, err := loadStateRowsByKeys(ctx, historyQ, []xdr.LedgerKey{key}, sequence)
+	if err != nil {
+		return history.LiquidityPool{}, errors.Wrap(err, "loading state history")
+	}
+	if len(rows.LiquidityPools) == 0 {
+		return history.LiquidityPool{}, sql.ErrNoRows
+	}
+	return rows.LiquidityPools[0], nil
+}
+
+// liquidityPoolsAtLedger returns a page of the liquidity pools matching the
+// query at a past ledger. The query must be filtered by account.
+func liquidityPoolsAtLedger(
+	ctx context.Context,
+	historyQ *history.Q,
+	query history.LiquidityPoolsQuery,
+	sequence uint32,
+) ([]history.LiquidityPool, error) {
+	if query.Account == "" {
+		return nil, problem.MakeInvalidFieldProblem(
+			"at_ledger",
+			errors.New("liquidity pools can be queried at a past ledger only when filtered by account"),
+		)
+	}
+
+	rows, err := loadStateRowsForAccount(ctx, historyQ, query.Account, []xdr.LedgerEntryType{xdr.LedgerEntryTypeTrustline}, sequence)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading state history")
+	}
+
+	var keys []xdr.LedgerKey
+	for _, trustline := range rows.TrustLines {
+		if trustline.AccountID != query.Account || trustline.LiquidityPoolID == "" {
+			continue
+		}
+		key, err := liquidityPoolLedgerKey(trustline.LiquidityPoolID)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	rows, err = loadStateRowsByKeys(ctx, historyQ, keys, sequence)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading state history")
+	}
+
+	var pools []history.LiquidityPool
+	for _, pool := range rows.LiquidityPools {
+		if hasReserves(pool, query.Assets) {
+			pools = append(pools, pool)
+		}
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].PoolID < pools[j].PoolID
+	})
+
+	return pageStateRecords(pools, query.PageQuery, func(pool history.LiquidityPool, cursor string) (int, error) {
+		return strings.Compare(pool.PoolID, cursor), nil
+	})
+}
+
+// pageStateRecords applies the cursor, order and limit of a page query to
+// records sorted in ascending order. compare compares a record to the cursor.
+func pageStateRecords[T any](records []T, pq db2.PageQuery, compare func(T, string) (int, error)) ([]T, error) {
+	page := make([]T, 0, pq.Limit)
+	for i := range records {
+		record := records[i]
+		if pq.Order == db2.OrderDescending {
+			record = records[len(records)-1-i]
+		}
+
+		if pq.Cursor != "" {
+			c, err := compare(record, pq.Cursor)
+			if err != nil {
+				return nil, err
+			}
+			if (pq.Order == db2.OrderDescending && c >= 0) || (pq.Order != db2.OrderDescending && c <= 0) {
+				continue
+			}
+		}
+
+		page = append(page, record)
+		if uint64(len(page)) == pq.Limit {
+			break
+		}
+	}
+	return page, nil
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func hasClaimant(balance history.ClaimableBalance, account string) bool {
+	for _, claimant := range balance.Claimants {
+		if claimant.Destination == account {
+			return true
+		}
+	}
+	return false
+}
+
+func hasReserves(pool history.LiquidityPool, assets []xdr.Asset) bool {
+	for _, asset := range assets {
+		found := false
+		for _, reserve := range pool.AssetReserves {
+			if reserve.Asset.Equals(asset) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func liquidityPoolLedgerKey(id string) (xdr.LedgerKey, error) {
+	var key xdr.LedgerKey
+	var poolID xdr.PoolId
+	decoded, err := hex.DecodeString(id)
+	if err != nil || len(decoded) != len(poolID) {
+		return key, problem.MakeInvalidFieldProblem("liquidity_pool_id", errors.New("invalid liquidity pool id"))
+	}
+	copy(poolID[:], decoded)
+	err = key.SetLiquidityPool(poolID)
+	return key, err
+}
@@ -0,0 +1,166 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/ingest/processors"
+	hProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/services/aurora/internal/test"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+)
+
+func TestPageStateRecords(t *testing.T) {
+	records := []int64{1, 2, 3, 4, 5}
+	compare := func(record int64, cursor string) (int, error) {
+		c, err := strconv.ParseInt(cursor, 10, 64)
+		return compareInt64(record, c), err
+	}
+
+	page, err := pageStateRecords(records, db2.MustPageQuery("", false, "asc", 2), compare)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, page)
+
+	page, err = pageStateRecords(records, db2.MustPageQuery("2", false, "asc", 2), compare)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 4}, page)
+
+	page, err = pageStateRecords(records, db2.MustPageQuery("", false, "desc", 2), compare)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5, 4}, page)
+
+	page, err = pageStateRecords(records, db2.MustPageQuery("2", false, "desc", 10), compare)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, page)
+
+	page, err = pageStateRecords(records, db2.MustPageQuery("5", false, "asc", 10), compare)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func stateHistoryTestAccount(balance xdr.Int64, sequence xdr.Uint32) *xdr.LedgerEntry {
+	return &xdr.LedgerEntry{
+		LastModifiedLedgerSeq: sequence,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{
+				AccountId:  xdr.MustAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"),
+				Balance:    balance,
+				Thresholds: xdr.Thresholds{1, 0, 0, 0},
+			},
+		},
+	}
+}
+
+func ingestStateHistoryTestLedger(t *testing.T, ctx context.Context, q *history.Q, sequence uint32, fromCheckpoint bool, changes ...ingest.Change) {
+	processor := processors.NewStateHistoryProcessor(q, sequence, fromCheckpoint)
+	for _, change := range changes {
+		require.NoError(t, processor.ProcessChange(ctx, change))
+	}
+	require.NoError(t, processor.Commit(ctx))
+}
+
+func TestAccountInfoAtLedger(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &history.Q{SessionInterface: tt.AuroraSession()}
+
+	address := "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"
+	handler := GetAccountByIDHandler{}
+	request := func(atLedger uint32) (StreamableObjectResponse, error) {
+		return handler.GetResource(httptest.NewRecorder(), makeRequest(
+			t,
+			map[string]string{"at_ledger": strconv.FormatUint(uint64(atLedger), 10)},
+			map[string]string{"account_id": address},
+			q,
+		))
+	}
+
+	// the state history is not available
+	_, err := request(10)
+	tt.Assert.Equal(stateHistoryNotAvailable, err)
+
+	ingestStateHistoryTestLedger(t, tt.Ctx, q, 10, true, ingest.Change{
+		Type: xdr.LedgerEntryTypeAccount,
+		Post: stateHistoryTestAccount(100, 10),
+	})
+	ingestStateHistoryTestLedger(t, tt.Ctx, q, 11, false, ingest.Change{
+		Type: xdr.LedgerEntryTypeAccount,
+		Pre:  stateHistoryTestAccount(100, 10),
+		Post: stateHistoryTestAccount(250, 11),
+	})
+	ingestStateHistoryTestLedger(t, tt.Ctx, q, 12, false)
+	ingestStateHistoryTestLedger(t, tt.Ctx, q, 13, false, ingest.Change{
+		Type: xdr.LedgerEntryTypeAccount,
+		Pre:  stateHistoryTestAccount(250, 11),
+	})
+
+	response, err := request(10)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("0.0000100", response.(Account).Balances[0].Balance)
+	tt.Assert.Equal(uint32(10), response.(Account).LastModifiedLedger)
+
+	response, err = request(12)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("0.0000250", response.(Account).Balances[0].Balance)
+	tt.Assert.Len(response.(Account).Signers, 1)
+
+	// the account was removed
+	_, err = request(13)
+	tt.Assert.True(q.NoRows(errors.Cause(err)))
+
+	_, err = request(9)
+	tt.Assert.Equal(hProblem.BeforeHistory, err)
+
+	_, err = request(14)
+	p := err.(*problem.P)
+	tt.Assert.Equal("at_ledger", p.Extras["invalid_field"])
+
+	// reaping keeps the versions needed to answer for the remaining ledgers
+	removed, err := q.ReapStateHistory(tt.Ctx, 12)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(1), removed)
+
+	_, err = request(11)
+	tt.Assert.Equal(hProblem.BeforeHistory, err)
+	response, err = request(12)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("0.0000250", response.(Account).Balances[0].Balance)
+}
+
+func TestOffersAtLedgerRequiresAccount(t *testing.T) {
+	_, err := offersAtLedger(context.Background(), nil, history.OffersQuery{}, 10)
+	p := err.(*problem.P)
+	assert.Equal(t, "at_ledger", p.Extras["invalid_field"])
+
+	_, err = claimableBalancesAtLedger(context.Background(), nil, history.ClaimableBalancesQuery{}, 10)
+	p = err.(*problem.P)
+	assert.Equal(t, "at_ledger", p.Extras["invalid_field"])
+
+	_, err = liquidityPoolsAtLedger(context.Background(), nil, history.LiquidityPoolsQuery{}, 10)
+	p = err.(*problem.P)
+	assert.Equal(t, "at_ledger", p.Extras["invalid_field"])
+}
+
+func TestOfferAtLedgerNotFound(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &history.Q{SessionInterface: tt.AuroraSession()}
+
+	tt.Assert.NoError(q.UpdateStateHistoryRange(tt.Ctx, 10, 10))
+	_, err := offerAtLedger(tt.Ctx, q, 7, 10)
+	tt.Assert.Equal(sql.ErrNoRows, err)
+}
@@ -36,7 +36,7 @@ type envelopeInfo struct {
 	parsed    xdr.TransactionEnvelope
 }
 
-func (handler SubmitTransactionHandler) extractEnvelopeInfo(raw string, passphrase string) (envelopeInfo, error) {
+func extractEnvelopeInfo(raw string, passphrase string) (envelopeInfo, error) {
 	result := envelopeInfo{raw: raw}
 	err := xdr.SafeUnmarshalBase64(raw, &result.parsed)
 	if err != nil {
@@ -59,7 +59,7 @@ func (handler SubmitTransactionHandler) extractEnvelopeInfo(raw string, passphra
 	return result, nil
 }
 
-func (handler SubmitTransactionHandler) validateBodyType(r *http.Request) error {
+func validateBodyType(r *http.Request) error {
 	c := r.Header.Get("Content-Type")
 	if c == "" {
 		return nil
@@ -136,13 +136,15 @@ func (handler SubmitTransactionHandler) response(r *http.Request, info envelopeI
 	return nil, result.Err
 }
 
-func (handler SubmitTransactionHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
-	if err := handler.validateBodyType(r); err != nil {
-		return nil, err
+// submittedEnvelope validates a transaction submission request and decodes
+// the submitted transaction envelope.
+func submittedEnvelope(r *http.Request, passphrase string, disableTxSub bool) (envelopeInfo, error) {
+	if err := validateBodyType(r); err != nil {
+		return envelopeInfo{}, err
 	}
 
-	if handler.DisableTxSub {
-		return nil, &problem.P{
+	if disableTxSub {
+		return envelopeInfo{}, &problem.P{
 			Type:   "transaction_submission_disabled",
 			Title:  "Transaction Submission Disabled",
 			Status: http.StatusMethodNotAllowed,
@@ -154,12 +156,12 @@ func (handler SubmitTransactionHandler) GetResource(w HeaderWriter, r *http.Requ
 
 	raw, err := getString(r, "tx")
 	if err != nil {
-		return nil, err
+		return envelopeInfo{}, err
 	}
 
-	info, err := handler.extractEnvelopeInfo(raw, handler.NetworkPassphrase)
+	info, err := extractEnvelopeInfo(raw, passphrase)
 	if err != nil {
-		return nil, &problem.P{
+		return info, &problem.P{
 			Type:   "transaction_malformed",
 			Title:  "Transaction Malformed",
 			Status: http.StatusBadRequest,
@@ -173,6 +175,14 @@ func (handler SubmitTransactionHandler) GetResource(w HeaderWriter, r *http.Requ
 			},
 		}
 	}
+	return info, nil
+}
+
+func (handler SubmitTransactionHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	info, err := submittedEnvelope(r, handler.NetworkPassphrase, handler.DisableTxSub)
+	if err != nil {
+		return nil, err
+	}
 
 	coreState := handler.GetCoreState()
 	if !coreState.Synced {
@@ -0,0 +1,119 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hcnet/go/protocols/aurora"
+	proto "github.com/hcnet/go/protocols/hcnetcore"
+	hProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/services/aurora/internal/resourceadapter"
+	"github.com/hcnet/go/services/aurora/internal/txsub"
+	"github.com/hcnet/go/xdr"
+)
+
+type AsyncNetworkSubmitter interface {
+	SubmitAsync(ctx context.Context, rawTx string, envelope xdr.TransactionEnvelope, hash string) txsub.SubmissionResult
+}
+
+// asyncSubmissionStatusCodes are the status codes of the responses of the
+// asynchronous submission endpoint by hcnet-core status. Only PENDING means
+// that the transaction was accepted.
+var asyncSubmissionStatusCodes = map[string]int{
+	proto.TXStatusPending:       http.StatusCreated,
+	proto.TXStatusDuplicate:     http.StatusConflict,
+	proto.TXStatusTryAgainLater: http.StatusServiceUnavailable,
+	proto.TXStatusError:         http.StatusBadRequest,
+}
+
+// AsyncSubmissionResponse is the resource returned by the asynchronous
+// submission endpoint.
+type AsyncSubmissionResponse struct {
+	aurora.AsyncTransactionSubmissionResponse
+}
+
+// StatusCode returns the status code the response is rendered with, which
+// depends on the status hcnet-core responded with.
+func (response AsyncSubmissionResponse) StatusCode() int {
+	if code, ok := asyncSubmissionStatusCodes[response.TxStatus]; ok {
+		return code
+	}
+	return http.StatusInternalServerError
+}
+
+// AsyncSubmitTransactionHandler is the action handler for the end-point
+// submitting a transaction without waiting for it to be included in a ledger.
+type AsyncSubmitTransactionHandler struct {
+	Submitter         AsyncNetworkSubmitter
+	NetworkPassphrase string
+	DisableTxSub      bool
+	CoreStateGetter
+}
+
+// GetResource submits the transaction to hcnet-core and returns the status
+// it responded with, see AsyncSubmissionResponse.StatusCode.
+func (handler AsyncSubmitTransactionHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	info, err := submittedEnvelope(r, handler.NetworkPassphrase, handler.DisableTxSub)
+	if err != nil {
+		return nil, err
+	}
+
+	coreState := handler.GetCoreState()
+	if !coreState.Synced {
+		return nil, hProblem.StaleHistory
+	}
+
+	result := handler.Submitter.SubmitAsync(r.Context(), info.raw, info.parsed, info.hash)
+	response := AsyncSubmissionResponse{aurora.AsyncTransactionSubmissionResponse{
+		TxStatus: result.Status,
+		Hash:     info.hash,
+	}}
+	if result.Err == nil {
+		return response, nil
+	}
+
+	if failedErr, ok := result.Err.(*txsub.FailedTransactionError); ok {
+		// transactions rejected before they reach hcnet-core, for example
+		// because of an invalid sequence number, are reported the same way
+		response.TxStatus = proto.TXStatusError
+		response.ErrorResultXDR = failedErr.ResultXDR
+		response.DiagnosticEventsXDR = failedErr.DiagnosticEventsXDR
+		return response, nil
+	}
+
+	return nil, result.Err
+}
+
+type TransactionListener interface {
+	Listen(ctx context.Context, hash string) <-chan txsub.Result
+}
+
+// StreamTransactionByHashHandler is the action handler for the stream
+// returning the result of a transaction once it is included in a ledger.
+type StreamTransactionByHashHandler struct {
+	Listener TransactionListener
+}
+
+// WaitResource blocks until the transaction is ingested and returns it. It
+// returns txsub.ErrTimeout if the transaction is not ingested within the
+// submission timeout.
+func (handler StreamTransactionByHashHandler) WaitResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	qp := TransactionQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return nil, err
+	}
+
+	select {
+	case result := <-handler.Listener.Listen(r.Context(), qp.TransactionHash):
+		if _, ok := result.Err.(*txsub.FailedTransactionError); result.Err != nil && !ok {
+			return nil, result.Err
+		}
+		// failed transactions are ingested too, they are returned with
+		// successful set to false
+		var resource aurora.Transaction
+		err := resourceadapter.PopulateTransaction(r.Context(), qp.TransactionHash, &resource, result.Transaction)
+		return resource, err
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	}
+}
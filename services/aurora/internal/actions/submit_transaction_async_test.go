@@ -0,0 +1,176 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/protocols/aurora"
+	proto "github.com/hcnet/go/protocols/hcnetcore"
+	"github.com/hcnet/go/services/aurora/internal/corestate"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/txsub"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+)
+
+const asyncTestTx = "AAAAAAGUcmKO5465JxTSLQOQljwk2SfqAJmZSG6JH6wtqpwhAAABLAAAAAAAAAABAAAAAAAAAAEAAAALaGVsbG8gd29ybGQAAAAAAwAAAAAAAAAAAAAAABbxCy3mLg3hiTqX4VUEEp60pFOrJNxYM1JtxXTwXhY2AAAAAAvrwgAAAAAAAAAAAQAAAAAW8Qst5i4N4Yk6l+FVBBKetKRTqyTcWDNSbcV08F4WNgAAAAAN4Lazj4x61AAAAAAAAAAFAAAAAAAAAAAAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABLaqcIQAAAEBKwqWy3TaOxoGnfm9eUjfTRBvPf34dvDA0Nf+B8z4zBob90UXtuCqmQqwMCyH+okOI3c05br3khkH0yP4kCwcE"
+
+type asyncNetworkSubmitterMock struct {
+	mock.Mock
+}
+
+func (m *asyncNetworkSubmitterMock) SubmitAsync(ctx context.Context, rawTx string, envelope xdr.TransactionEnvelope, hash string) txsub.SubmissionResult {
+	a := m.Called(hash)
+	return a.Get(0).(txsub.SubmissionResult)
+}
+
+func newAsyncSubmission(t *testing.T, result txsub.SubmissionResult) (interface{}, string, error) {
+	info, err := extractEnvelopeInfo(asyncTestTx, network.PublicNetworkPassphrase)
+	require.NoError(t, err)
+
+	coreState := &coreStateGetterMock{}
+	coreState.On("GetCoreState").Return(corestate.State{Synced: true})
+	submitter := &asyncNetworkSubmitterMock{}
+	submitter.On("SubmitAsync", info.hash).Return(result).Once()
+
+	handler := AsyncSubmitTransactionHandler{
+		Submitter:         submitter,
+		NetworkPassphrase: network.PublicNetworkPassphrase,
+		CoreStateGetter:   coreState,
+	}
+
+	form := url.Values{}
+	form.Set("tx", asyncTestTx)
+	request, err := http.NewRequest(
+		"POST",
+		"https://aurora.hcnet.org/transactions_async",
+		strings.NewReader(form.Encode()),
+	)
+	require.NoError(t, err)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	resource, err := handler.GetResource(httptest.NewRecorder(), request)
+	submitter.AssertExpectations(t)
+	return resource, info.hash, err
+}
+
+func TestAsyncSubmissionStatuses(t *testing.T) {
+	for status, code := range map[string]int{
+		proto.TXStatusPending:       http.StatusCreated,
+		proto.TXStatusDuplicate:     http.StatusConflict,
+		proto.TXStatusTryAgainLater: http.StatusServiceUnavailable,
+	} {
+		resource, hash, err := newAsyncSubmission(t, txsub.SubmissionResult{Status: status})
+		require.NoError(t, err)
+		assert.Equal(t, AsyncSubmissionResponse{aurora.AsyncTransactionSubmissionResponse{
+			TxStatus: status,
+			Hash:     hash,
+		}}, resource)
+		assert.Equal(t, code, resource.(AsyncSubmissionResponse).StatusCode())
+	}
+}
+
+func TestAsyncSubmissionError(t *testing.T) {
+	resource, hash, err := newAsyncSubmission(t, txsub.SubmissionResult{
+		Status: proto.TXStatusError,
+		Err:    &txsub.FailedTransactionError{ResultXDR: "AAAAAAAAAGT////7AAAAAA=="},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, AsyncSubmissionResponse{aurora.AsyncTransactionSubmissionResponse{
+		ErrorResultXDR: "AAAAAAAAAGT////7AAAAAA==",
+		TxStatus:       proto.TXStatusError,
+		Hash:           hash,
+	}}, resource)
+	assert.Equal(t, http.StatusBadRequest, resource.(AsyncSubmissionResponse).StatusCode())
+
+	// transactions rejected by aurora are reported like hcnet-core errors
+	resource, _, err = newAsyncSubmission(t, txsub.SubmissionResult{Err: txsub.ErrBadSequence})
+	require.NoError(t, err)
+	assert.Equal(t, proto.TXStatusError, resource.(AsyncSubmissionResponse).TxStatus)
+	assert.Equal(t, txsub.ErrBadSequence.ResultXDR, resource.(AsyncSubmissionResponse).ErrorResultXDR)
+	assert.Equal(t, http.StatusBadRequest, resource.(AsyncSubmissionResponse).StatusCode())
+}
+
+func TestAsyncSubmissionCoreUnavailable(t *testing.T) {
+	coreErr := errors.New("could not connect to hcnet-core")
+	_, _, err := newAsyncSubmission(t, txsub.SubmissionResult{Err: coreErr})
+	assert.Equal(t, coreErr, err)
+}
+
+func TestAsyncSubmissionDisabled(t *testing.T) {
+	handler := AsyncSubmitTransactionHandler{
+		NetworkPassphrase: network.PublicNetworkPassphrase,
+		DisableTxSub:      true,
+	}
+	request := httptest.NewRequest("POST", "https://aurora.hcnet.org/transactions_async", nil)
+	_, err := handler.GetResource(httptest.NewRecorder(), request)
+	require.Error(t, err)
+	assert.Equal(t, "transaction_submission_disabled", err.(*problem.P).Type)
+}
+
+type transactionListenerMock struct {
+	mock.Mock
+}
+
+func (m *transactionListenerMock) Listen(ctx context.Context, hash string) <-chan txsub.Result {
+	a := m.Called(hash)
+	return a.Get(0).(chan txsub.Result)
+}
+
+func TestStreamTransactionByHash(t *testing.T) {
+	hash := "3389e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c8889"
+	results := make(chan txsub.Result, 1)
+	results <- txsub.Result{
+		Transaction: history.Transaction{
+			TransactionWithoutLedger: history.TransactionWithoutLedger{
+				TransactionHash: hash,
+				Successful:      false,
+			},
+		},
+		Err: &txsub.FailedTransactionError{ResultXDR: "AAAAAAAAAGT////7AAAAAA=="},
+	}
+	listener := &transactionListenerMock{}
+	listener.On("Listen", hash).Return(results).Once()
+
+	handler := StreamTransactionByHashHandler{Listener: listener}
+	resource, err := handler.WaitResource(
+		httptest.NewRecorder(),
+		makeRequest(t, map[string]string{}, map[string]string{"tx_id": hash}, nil),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, hash, resource.(aurora.Transaction).Hash)
+	assert.False(t, resource.(aurora.Transaction).Successful)
+	listener.AssertExpectations(t)
+}
+
+func TestStreamTransactionByHashTimeout(t *testing.T) {
+	hash := "3389e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c8889"
+	results := make(chan txsub.Result, 1)
+	results <- txsub.Result{Err: txsub.ErrTimeout}
+	listener := &transactionListenerMock{}
+	listener.On("Listen", hash).Return(results).Once()
+
+	handler := StreamTransactionByHashHandler{Listener: listener}
+	_, err := handler.WaitResource(
+		httptest.NewRecorder(),
+		makeRequest(t, map[string]string{}, map[string]string{"tx_id": hash}, nil),
+	)
+	assert.Equal(t, txsub.ErrTimeout, err)
+
+	_, err = handler.WaitResource(
+		httptest.NewRecorder(),
+		makeRequest(t, map[string]string{}, map[string]string{"tx_id": "invalid"}, nil),
+	)
+	assert.Error(t, err)
+	listener.AssertExpectations(t)
+}
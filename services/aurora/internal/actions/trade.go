@@ -13,6 +13,7 @@ import (
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/services/aurora/internal/ledger"
 	"github.com/hcnet/go/services/aurora/internal/resourceadapter"
+	"github.com/hcnet/go/services/aurora/internal/ssehub"
 	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/support/render/hal"
 	"github.com/hcnet/go/support/render/problem"
@@ -217,6 +218,54 @@ func (handler GetTradesHandler) GetResourcePage(w HeaderWriter, r *http.Request)
 	return response, nil
 }
 
+// LedgerRenderer returns the renderer of the ledgers published by the SSE hub
+// for the stream of the request, or false if the stream filters trades by
+// something the hub doesn't know about.
+func (handler GetTradesHandler) LedgerRenderer(r *http.Request) (ssehub.RenderFunc, bool, error) {
+	qp := TradesQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return nil, false, err
+	}
+	if qp.TradeType == "" {
+		qp.TradeType = history.AllTrades
+	}
+
+	baseAsset, err := qp.Base()
+	if err != nil {
+		return nil, false, err
+	}
+	if baseAsset != nil || qp.OfferID != 0 {
+		return nil, false, nil
+	}
+
+	return func(ctx context.Context, l *ssehub.Ledger) ([]hal.Pageable, error) {
+		var response []hal.Pageable
+		for _, record := range l.Trades {
+			switch {
+			case qp.PoolID != "":
+				if record.BaseLiquidityPoolID.String != qp.PoolID &&
+					record.CounterLiquidityPoolID.String != qp.PoolID {
+					continue
+				}
+			case qp.TradeType == history.OrderbookTrades && record.Type != history.OrderbookTradeType:
+				continue
+			case qp.TradeType == history.LiquidityPoolTrades && record.Type != history.LiquidityPoolTradeType:
+				continue
+			}
+			if qp.AccountID != "" && qp.PoolID == "" &&
+				record.BaseAccount.String != qp.AccountID &&
+				record.CounterAccount.String != qp.AccountID {
+				continue
+			}
+
+			var res aurora.Trade
+			resourceadapter.PopulateTrade(ctx, &res, record)
+			response = append(response, res)
+		}
+		return response, nil
+	}, true, nil
+}
+
 // TradeAggregationsQuery query struct for trade_aggregations end-point
 type TradeAggregationsQuery struct {
 	OffsetFilter           uint64      `schema:"offset" valid:"-"`
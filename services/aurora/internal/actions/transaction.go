@@ -10,6 +10,7 @@ import (
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/services/aurora/internal/ledger"
 	"github.com/hcnet/go/services/aurora/internal/resourceadapter"
+	"github.com/hcnet/go/services/aurora/internal/ssehub"
 	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/support/render/hal"
 	supportProblem "github.com/hcnet/go/support/render/problem"
@@ -136,6 +137,39 @@ func (handler GetTransactionsHandler) GetResourcePage(w HeaderWriter, r *http.Re
 	return response, nil
 }
 
+// LedgerRenderer returns the renderer of the ledgers published by the SSE hub
+// for the stream of the request, or false if the stream filters transactions
+// by something the hub doesn't know about.
+func (handler GetTransactionsHandler) LedgerRenderer(r *http.Request) (ssehub.RenderFunc, bool, error) {
+	qp := TransactionsQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return nil, false, err
+	}
+	if qp.ClaimableBalanceID != "" || qp.LiquidityPoolID != "" || qp.LedgerID > 0 {
+		return nil, false, nil
+	}
+
+	return func(ctx context.Context, l *ssehub.Ledger) ([]hal.Pageable, error) {
+		var response []hal.Pageable
+		for _, record := range l.Transactions {
+			if !qp.IncludeFailedTransactions && !record.Successful {
+				continue
+			}
+			if qp.AccountID != "" && !includesAccount(record.Participants, qp.AccountID) {
+				continue
+			}
+
+			var res aurora.Transaction
+			err := resourceadapter.PopulateTransaction(ctx, record.TransactionHash, &res, record.Transaction)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not populate transaction")
+			}
+			response = append(response, res)
+		}
+		return response, nil
+	}, true, nil
+}
+
 // loadTransactionRecords returns a slice of transaction records of an
 // account/ledger identified by accountID/ledgerID based on pq and
 // includeFailedTx.
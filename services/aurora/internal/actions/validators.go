@@ -10,6 +10,7 @@ import (
 
 	"github.com/hcnet/go/amount"
 	"github.com/hcnet/go/services/aurora/internal/assets"
+	"github.com/hcnet/go/strkey"
 	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/xdr"
 )
@@ -25,6 +26,7 @@ func init() {
 	govalidator.TagMap["assetType"] = isAssetType
 	govalidator.TagMap["asset"] = isAsset
 	govalidator.TagMap["claimableBalanceID"] = isClaimableBalanceID
+	govalidator.TagMap["contractID"] = isContractID
 	govalidator.TagMap["transactionHash"] = isTransactionHash
 	govalidator.TagMap["sha256"] = govalidator.IsSHA256
 	govalidator.TagMap["tradeType"] = isTradeType
@@ -37,6 +39,7 @@ var customTagsErrorMessages = map[string]string{
 	"assetType":            "Asset type must be native, credit_alphanum4 or credit_alphanum12",
 	"bool":                 "Filter should be true or false",
 	"claimable_balance_id": "Claimable Balance ID must be the hex-encoded XDR representation of a Claimable Balance ID",
+	"contractID":           "Contract ID must start with `C` and contain 56 alphanum characters",
 	"ledger_id":            "Ledger ID must be an integer higher than 0",
 	"offer_id":             "Offer ID must be an integer higher than 0",
 	"op_id":                "Operation ID must be an integer higher than 0",
@@ -139,6 +142,14 @@ func isAccountID(str string) bool {
 	return true
 }
 
+func isContractID(str string) bool {
+	if _, err := strkey.Decode(strkey.VersionByteContract, str); err != nil {
+		return false
+	}
+
+	return true
+}
+
 func isTransactionHash(str string) bool {
 	decoded, err := hex.DecodeString(str)
 	if err != nil {
@@ -100,6 +100,50 @@ func TestAccountIDValidator(t *testing.T) {
 	}
 }
 
+func TestContractIDValidator(t *testing.T) {
+	type Query struct {
+		Contract string `valid:"contractID,optional"`
+	}
+
+	for _, testCase := range []struct {
+		name          string
+		value         string
+		expectedError string
+	}{
+		{
+			"account address",
+			"GAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQPZW",
+			"Contract: GAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQPZW does not validate as contractID",
+		},
+		{
+			"valid contract address",
+			"CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS",
+			"",
+		},
+		{
+			"empty contract address should not be validated",
+			"",
+			"",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			tt := assert.New(t)
+
+			q := Query{
+				Contract: testCase.value,
+			}
+
+			result, err := govalidator.ValidateStruct(q)
+			if testCase.expectedError == "" {
+				tt.NoError(err)
+				tt.True(result)
+			} else {
+				tt.Equal(testCase.expectedError, err.Error())
+			}
+		})
+	}
+}
+
 func TestAssetValidator(t *testing.T) {
 	type Query struct {
 		Asset string `valid:"asset"`
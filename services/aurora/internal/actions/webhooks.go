@@ -0,0 +1,224 @@
+package actions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/asaskevich/govalidator"
+
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+)
+
+const maxWebhookDeadLettersLimit = 200
+
+// these admin HTTP endpoints are documented in services/aurora/internal/httpx/static/admin_oapi.yml
+type WebhooksHandler struct{}
+
+func (handler WebhooksHandler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	subscriptions, err := historyQ.GetWebhookSubscriptions(r.Context())
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responsePayload := make([]hProtocol.WebhookSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		responsePayload = append(responsePayload, handler.subscriptionResource(subscription))
+	}
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler WebhooksHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	subscription, err := handler.subscriptionRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	subscription, err = historyQ.InsertWebhookSubscription(r.Context(), subscription)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(handler.subscriptionResource(subscription)); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler WebhooksHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	id, err := getInt64Param(r, "id")
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	err = historyQ.DeleteWebhookSubscription(r.Context(), id)
+	if errors.Cause(err) == sql.ErrNoRows {
+		problem.Render(r.Context(), w, problem.NotFound)
+		return
+	} else if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler WebhooksHandler) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	var subscriptionID, cursor int64
+	if r.URL.Query().Get("subscription_id") != "" {
+		if subscriptionID, err = getInt64Param(r, "subscription_id"); err != nil {
+			problem.Render(r.Context(), w, err)
+			return
+		}
+	}
+	if r.URL.Query().Get("cursor") != "" {
+		if cursor, err = getInt64Param(r, "cursor"); err != nil {
+			problem.Render(r.Context(), w, err)
+			return
+		}
+	}
+	limit, err := getLimit(r, "limit", db2.DefaultPageSize, maxWebhookDeadLettersLimit)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	deadLetters, err := historyQ.GetWebhookDeadLetters(r.Context(), subscriptionID, cursor, limit)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responsePayload := make([]hProtocol.WebhookDeadLetter, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		responsePayload = append(responsePayload, hProtocol.WebhookDeadLetter{
+			ID:             deadLetter.ID,
+			SubscriptionID: deadLetter.SubscriptionID,
+			URL:            deadLetter.URL,
+			Ledger:         deadLetter.LedgerSequence,
+			Payload:        json.RawMessage(deadLetter.Payload),
+			Attempts:       deadLetter.Attempts,
+			LastError:      deadLetter.LastError,
+			CreatedAt:      deadLetter.CreatedAt,
+		})
+	}
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler WebhooksHandler) subscriptionRequest(r *http.Request) (history.WebhookSubscription, error) {
+	var request hProtocol.WebhookSubscription
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&request); err != nil {
+		return history.WebhookSubscription{}, problem.NewProblemWithInvalidField(
+			problem.BadRequest, "reason", fmt.Errorf("invalid json for webhook subscription %v", err.Error()),
+		)
+	}
+
+	parsedURL, err := url.Parse(request.URL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return history.WebhookSubscription{}, problem.MakeInvalidFieldProblem(
+			"url", errors.New("url must be an absolute http or https url"),
+		)
+	}
+	if request.Secret == "" {
+		return history.WebhookSubscription{}, problem.MakeInvalidFieldProblem(
+			"secret", errors.New("secret cannot be empty"),
+		)
+	}
+
+	filters, err := countNonEmpty(request.AccountID, request.Asset, request.LiquidityPoolID)
+	if err != nil {
+		return history.WebhookSubscription{}, err
+	}
+	if filters != 1 {
+		return history.WebhookSubscription{}, problem.MakeInvalidFieldProblem(
+			"account_id,asset,liquidity_pool_id",
+			errors.New("exactly one of account_id, asset and liquidity_pool_id must be provided"),
+		)
+	}
+
+	subscription := history.WebhookSubscription{
+		URL:    request.URL,
+		Secret: request.Secret,
+	}
+	switch {
+	case request.AccountID != "":
+		if !isAccountID(request.AccountID) {
+			return history.WebhookSubscription{}, problem.MakeInvalidFieldProblem(
+				"account_id", errors.New("Account ID must start with `G` and contain 56 alphanum characters"),
+			)
+		}
+		subscription.AccountID = sql.NullString{String: request.AccountID, Valid: true}
+	case request.Asset != "":
+		assets, err := xdr.BuildAssets(request.Asset)
+		if err != nil || len(assets) != 1 {
+			return history.WebhookSubscription{}, problem.MakeInvalidFieldProblem(
+				"asset", errors.New("asset must be `native` or in the `code:issuer` format"),
+			)
+		}
+		subscription.Asset = sql.NullString{String: assets[0].StringCanonical(), Valid: true}
+	case request.LiquidityPoolID != "":
+		if !govalidator.IsSHA256(request.LiquidityPoolID) {
+			return history.WebhookSubscription{}, problem.MakeInvalidFieldProblem(
+				"liquidity_pool_id", errors.New("liquidity_pool_id must be a hex encoded sha256 hash"),
+			)
+		}
+		subscription.LiquidityPoolID = sql.NullString{String: request.LiquidityPoolID, Valid: true}
+	}
+
+	return subscription, nil
+}
+
+func (handler WebhooksHandler) subscriptionResource(subscription history.WebhookSubscription) hProtocol.WebhookSubscription {
+	return hProtocol.WebhookSubscription{
+		ID:              subscription.ID,
+		URL:             subscription.URL,
+		AccountID:       subscription.AccountID.String,
+		Asset:           subscription.Asset.String,
+		LiquidityPoolID: subscription.LiquidityPoolID.String,
+		CreatedAt:       subscription.CreatedAt,
+	}
+}
@@ -0,0 +1,217 @@
+package actions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/test"
+)
+
+func TestCreateWebhookSubscription(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+
+	q := &history.Q{SessionInterface: tt.AuroraSession()}
+	handler := &WebhooksHandler{}
+
+	for _, testCase := range []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedAsset  string
+	}{
+		{
+			name:           "account",
+			body:           `{"url": "https://example.com/hook", "secret": "s3cret", "account_id": "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "asset",
+			body:           `{"url": "https://example.com/hook", "secret": "s3cret", "asset": "native"}`,
+			expectedStatus: http.StatusCreated,
+			expectedAsset:  "native",
+		},
+		{
+			name:           "liquidity pool",
+			body:           `{"url": "https://example.com/hook", "secret": "s3cret", "liquidity_pool_id": "ea4e3e63a95fd840c1394f195722ffdcb2d0d4f0a26589c6ab557d81e6b0bf9d"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid json",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid url",
+			body:           `{"url": "example.com", "secret": "s3cret", "asset": "native"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing secret",
+			body:           `{"url": "https://example.com/hook", "asset": "native"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "no filter",
+			body:           `{"url": "https://example.com/hook", "secret": "s3cret"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "several filters",
+			body:           `{"url": "https://example.com/hook", "secret": "s3cret", "asset": "native", "account_id": "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid asset",
+			body:           `{"url": "https://example.com/hook", "secret": "s3cret", "asset": "USD"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid liquidity pool",
+			body:           `{"url": "https://example.com/hook", "secret": "s3cret", "liquidity_pool_id": "pool"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := makeRequest(t, map[string]string{}, map[string]string{}, q)
+			request.Method = http.MethodPost
+			request.Body = ioutil.NopCloser(strings.NewReader(testCase.body))
+
+			recorder := httptest.NewRecorder()
+			handler.CreateSubscription(recorder, request)
+
+			resp := recorder.Result()
+			tt.Assert.Equal(testCase.expectedStatus, resp.StatusCode)
+			if testCase.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var subscription hProtocol.WebhookSubscription
+			tt.Assert.NoError(json.NewDecoder(resp.Body).Decode(&subscription))
+			tt.Assert.NotZero(subscription.ID)
+			tt.Assert.Equal("https://example.com/hook", subscription.URL)
+			tt.Assert.Empty(subscription.Secret)
+			tt.Assert.Equal(testCase.expectedAsset, subscription.Asset)
+		})
+	}
+
+	subscriptions, err := q.GetWebhookSubscriptions(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(subscriptions, 3)
+	tt.Assert.Equal("s3cret", subscriptions[0].Secret)
+}
+
+func TestGetAndDeleteWebhookSubscriptions(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+
+	q := &history.Q{SessionInterface: tt.AuroraSession()}
+	handler := &WebhooksHandler{}
+
+	subscription, err := q.InsertWebhookSubscription(tt.Ctx, history.WebhookSubscription{
+		URL:    "https://example.com/hook",
+		Secret: "s3cret",
+		Asset:  sql.NullString{String: "native", Valid: true},
+	})
+	tt.Assert.NoError(err)
+
+	recorder := httptest.NewRecorder()
+	handler.GetSubscriptions(recorder, makeRequest(t, map[string]string{}, map[string]string{}, q))
+	resp := recorder.Result()
+	tt.Assert.Equal(http.StatusOK, resp.StatusCode)
+	var subscriptions []hProtocol.WebhookSubscription
+	tt.Assert.NoError(json.NewDecoder(resp.Body).Decode(&subscriptions))
+	tt.Assert.Len(subscriptions, 1)
+	tt.Assert.Equal(subscription.ID, subscriptions[0].ID)
+	tt.Assert.Equal("native", subscriptions[0].Asset)
+	tt.Assert.Empty(subscriptions[0].Secret)
+
+	for _, testCase := range []struct {
+		id             string
+		expectedStatus int
+	}{
+		{id: "invalid", expectedStatus: http.StatusBadRequest},
+		{id: "1000", expectedStatus: http.StatusNotFound},
+		{id: strconv.FormatInt(subscription.ID, 10), expectedStatus: http.StatusNoContent},
+	} {
+		recorder = httptest.NewRecorder()
+		handler.DeleteSubscription(
+			recorder,
+			makeRequest(t, map[string]string{}, map[string]string{"id": testCase.id}, q),
+		)
+		tt.Assert.Equal(testCase.expectedStatus, recorder.Result().StatusCode)
+	}
+
+	remaining, err := q.GetWebhookSubscriptions(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(remaining)
+}
+
+func TestGetWebhookDeadLetters(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+
+	q := &history.Q{SessionInterface: tt.AuroraSession()}
+	handler := &WebhooksHandler{}
+
+	subscription, err := q.InsertWebhookSubscription(tt.Ctx, history.WebhookSubscription{
+		URL:    "https://example.com/hook",
+		Secret: "s3cret",
+		Asset:  sql.NullString{String: "native", Valid: true},
+	})
+	tt.Assert.NoError(err)
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tt.Assert.NoError(q.InsertWebhookDeliveries(tt.Ctx, []history.WebhookDelivery{
+		{SubscriptionID: subscription.ID, LedgerSequence: 10, Payload: `{"ledger":10}`, NextAttemptAt: now, CreatedAt: now},
+	}))
+	deliveries, err := q.ClaimWebhookDeliveries(tt.Ctx, now, time.Minute, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(deliveries, 1)
+	deliveries[0].Attempts = 10
+	deliveries[0].LastError = "unexpected status code 500"
+	tt.Assert.NoError(q.DeadLetterWebhookDelivery(tt.Ctx, deliveries[0], subscription.URL, now))
+
+	recorder := httptest.NewRecorder()
+	handler.GetDeadLetters(recorder, makeRequest(
+		t,
+		map[string]string{"subscription_id": strconv.FormatInt(subscription.ID, 10)},
+		map[string]string{},
+		q,
+	))
+	resp := recorder.Result()
+	tt.Assert.Equal(http.StatusOK, resp.StatusCode)
+
+	var deadLetters []hProtocol.WebhookDeadLetter
+	tt.Assert.NoError(json.NewDecoder(resp.Body).Decode(&deadLetters))
+	tt.Assert.Len(deadLetters, 1)
+	tt.Assert.Equal(subscription.ID, deadLetters[0].SubscriptionID)
+	tt.Assert.Equal(uint32(10), deadLetters[0].Ledger)
+	tt.Assert.JSONEq(`{"ledger":10}`, string(deadLetters[0].Payload))
+	tt.Assert.Equal(int32(10), deadLetters[0].Attempts)
+	tt.Assert.Equal("unexpected status code 500", deadLetters[0].LastError)
+
+	recorder = httptest.NewRecorder()
+	handler.GetDeadLetters(recorder, makeRequest(
+		t,
+		map[string]string{"cursor": strconv.FormatInt(deadLetters[0].ID, 10)},
+		map[string]string{},
+		q,
+	))
+	resp = recorder.Result()
+	tt.Assert.Equal(http.StatusOK, resp.StatusCode)
+	tt.Assert.NoError(json.NewDecoder(resp.Body).Decode(&deadLetters))
+	tt.Assert.Empty(deadLetters)
+}
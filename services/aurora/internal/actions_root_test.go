@@ -52,7 +52,7 @@ func TestRootAction(t *testing.T) {
 			actual.Links.Accounts.Href,
 		)
 		ht.Assert.Equal(
-			"http://localhost/offers{?selling,buying,seller,sponsor,cursor,limit,order}",
+			"http://localhost/offers{?selling,buying,seller,sponsor,at_ledger,cursor,limit,order}",
 			actual.Links.Offers.Href,
 		)
 
@@ -0,0 +1,208 @@
+package apikeys
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stellar/throttled"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+)
+
+const (
+	// refreshInterval is how often the keys are reloaded from the database,
+	// so that changes made over the admin port are picked up.
+	refreshInterval = 30 * time.Second
+	// minReloadInterval is the minimum delay between two reloads caused by
+	// unknown keys, so that invalid keys cannot be used to flood the
+	// database with queries.
+	minReloadInterval = time.Second
+)
+
+// unlimited is the result of a class without a quota. Negative values are
+// not reported in the response headers.
+var unlimited = throttled.RateLimitResult{Limit: -1, Remaining: -1, ResetAfter: -1, RetryAfter: -1}
+
+// classLimit is the quota of a key for an endpoint class. Its rate limiter is
+// kept across reloads as long as the quota does not change.
+type classLimit struct {
+	quota       int64
+	rateLimiter *throttled.GCRARateLimiter
+}
+
+// Key is an API key known to the Limiter.
+type Key struct {
+	ID   int64
+	Name string
+
+	limits map[Class]*classLimit
+	usage  *prometheus.CounterVec
+}
+
+func newKey(row history.APIKey, previous *Key, usage *prometheus.CounterVec) (*Key, error) {
+	key := &Key{
+		ID:     row.ID,
+		Name:   row.Name,
+		limits: map[Class]*classLimit{},
+		usage:  usage,
+	}
+	for class, quota := range Quotas(row) {
+		if !quota.Valid {
+			continue
+		}
+		if previous != nil {
+			if limit, ok := previous.limits[class]; ok && limit.quota == quota.Int64 {
+				key.limits[class] = limit
+				continue
+			}
+		}
+
+		limit := &classLimit{quota: quota.Int64}
+		if quota.Int64 > 0 {
+			var err error
+			limit.rateLimiter, err = throttled.NewGCRARateLimiter(1, throttled.RateQuota{
+				MaxRate:  throttled.PerHour(int(quota.Int64)),
+				MaxBurst: int(quota.Int64) - 1,
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not create rate limiter of api key %d", row.ID)
+			}
+		}
+		key.limits[class] = limit
+	}
+	return key, nil
+}
+
+// Allowed returns false if the quota of the key for class is 0.
+func (k *Key) Allowed(class Class) bool {
+	limit, ok := k.limits[class]
+	return !ok || limit.quota > 0
+}
+
+// RateLimit charges quantity requests to the quota of the key for class. It
+// returns true if the quota is exceeded. A quantity of 0 returns the state of
+// the quota without charging it.
+func (k *Key) RateLimit(class Class, quantity int) (bool, throttled.RateLimitResult, error) {
+	limit, ok := k.limits[class]
+	if !ok {
+		k.record(class, quantity, false)
+		return false, unlimited, nil
+	}
+	if limit.rateLimiter == nil {
+		k.record(class, quantity, true)
+		return true, throttled.RateLimitResult{Limit: 0, Remaining: 0, ResetAfter: -1, RetryAfter: -1}, nil
+	}
+
+	limited, result, err := limit.rateLimiter.RateLimit("", quantity)
+	if err != nil {
+		return false, result, err
+	}
+	k.record(class, quantity, limited)
+	return limited, result, nil
+}
+
+func (k *Key) record(class Class, quantity int, limited bool) {
+	if k.usage == nil || quantity == 0 {
+		return
+	}
+	result := "allowed"
+	if limited {
+		result = "limited"
+	}
+	k.usage.WithLabelValues(k.Name, string(class), result).Add(float64(quantity))
+}
+
+// Limiter looks up the API keys of requests and enforces their quotas. The
+// keys are cached in memory and reloaded from the database every
+// refreshInterval.
+type Limiter struct {
+	q     history.QAPIKeys
+	usage *prometheus.CounterVec
+	now   func() time.Time
+
+	reloadLock sync.Mutex
+	lock       sync.RWMutex
+	keys       map[string]*Key
+	loadedAt   time.Time
+}
+
+// NewLimiter returns a Limiter loading the keys with q. The usage of the keys
+// is counted in usage, by key name, endpoint class and result. usage is
+// optional.
+func NewLimiter(q history.QAPIKeys, usage *prometheus.CounterVec) *Limiter {
+	return &Limiter{
+		q:     q,
+		usage: usage,
+		now:   time.Now,
+		keys:  map[string]*Key{},
+	}
+}
+
+// Lookup returns the key matching raw, or nil if the key is unknown.
+func (l *Limiter) Lookup(ctx context.Context, raw string) (*Key, error) {
+	hash := HashKey(raw)
+
+	l.lock.RLock()
+	key, ok := l.keys[hash]
+	loadedAt := l.loadedAt
+	l.lock.RUnlock()
+
+	age := l.now().Sub(loadedAt)
+	if age < refreshInterval && (ok || age < minReloadInterval) {
+		return key, nil
+	}
+
+	if err := l.reload(ctx, loadedAt); err != nil {
+		if ok {
+			log.Ctx(ctx).WithError(err).Warn("Could not reload api keys, using cached keys")
+			return key, nil
+		}
+		return nil, err
+	}
+
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return l.keys[hash], nil
+}
+
+// reload loads the keys from the database unless they were reloaded by
+// another request since loadedAt.
+func (l *Limiter) reload(ctx context.Context, loadedAt time.Time) error {
+	l.reloadLock.Lock()
+	defer l.reloadLock.Unlock()
+
+	l.lock.RLock()
+	previousKeys, reloaded := l.keys, !l.loadedAt.Equal(loadedAt)
+	l.lock.RUnlock()
+	if reloaded {
+		return nil
+	}
+
+	rows, err := l.q.GetAPIKeys(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not load api keys")
+	}
+
+	previousByID := map[int64]*Key{}
+	for _, key := range previousKeys {
+		previousByID[key.ID] = key
+	}
+	keys := make(map[string]*Key, len(rows))
+	for _, row := range rows {
+		key, err := newKey(row, previousByID[row.ID], l.usage)
+		if err != nil {
+			return err
+		}
+		keys[row.KeyHash] = key
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.keys = keys
+	l.loadedAt = l.now()
+	return nil
+}
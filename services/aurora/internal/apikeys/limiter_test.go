@@ -0,0 +1,204 @@
+package apikeys
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/errors"
+)
+
+func newTestLimiter(q history.QAPIKeys) (*Limiter, *prometheus.CounterVec, *time.Time) {
+	usage := prometheus.NewCounterVec(
+		prometheus.CounterOpts{Namespace: "aurora", Subsystem: "api_keys", Name: "usage_total"},
+		[]string{"key", "class", "result"},
+	)
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(q, usage)
+	limiter.now = func() time.Time { return now }
+	return limiter, usage, &now
+}
+
+func quota(value int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: value, Valid: true}
+}
+
+func TestLimiterQuotas(t *testing.T) {
+	q := &history.MockQAPIKeys{}
+	q.On("GetAPIKeys", mock.Anything).Return([]history.APIKey{{
+		ID:              1,
+		Name:            "wallet",
+		KeyHash:         HashKey("key"),
+		HistoryQuota:    quota(2),
+		SubmissionQuota: quota(0),
+	}}, nil).Once()
+	limiter, usage, _ := newTestLimiter(q)
+
+	key, err := limiter.Lookup(context.Background(), "key")
+	require.NoError(t, err)
+	require.NotNil(t, key)
+	assert.Equal(t, "wallet", key.Name)
+
+	for i, expectedRemaining := range []int{1, 0} {
+		limited, result, err := key.RateLimit(History, 1)
+		require.NoError(t, err)
+		assert.False(t, limited, "request %d", i)
+		assert.Equal(t, 2, result.Limit)
+		assert.Equal(t, expectedRemaining, result.Remaining)
+	}
+	limited, result, err := key.RateLimit(History, 1)
+	require.NoError(t, err)
+	assert.True(t, limited)
+	assert.Positive(t, result.RetryAfter)
+
+	// classes without a quota are not limited
+	limited, result, err = key.RateLimit(State, 1)
+	require.NoError(t, err)
+	assert.False(t, limited)
+	assert.Equal(t, -1, result.Limit)
+	assert.True(t, key.Allowed(State))
+
+	assert.False(t, key.Allowed(Submission))
+	limited, _, err = key.RateLimit(Submission, 1)
+	require.NoError(t, err)
+	assert.True(t, limited)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(usage.WithLabelValues("wallet", "history", "allowed")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(usage.WithLabelValues("wallet", "history", "limited")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(usage.WithLabelValues("wallet", "state", "allowed")))
+	q.AssertExpectations(t)
+}
+
+func TestLimiterReload(t *testing.T) {
+	q := &history.MockQAPIKeys{}
+	wallet := history.APIKey{ID: 1, Name: "wallet", KeyHash: HashKey("wallet"), HistoryQuota: quota(1)}
+	explorer := history.APIKey{ID: 2, Name: "explorer", KeyHash: HashKey("explorer"), StateQuota: quota(1)}
+	q.On("GetAPIKeys", mock.Anything).Return([]history.APIKey{wallet}, nil).Once()
+	limiter, _, now := newTestLimiter(q)
+	ctx := context.Background()
+
+	key, err := limiter.Lookup(ctx, "wallet")
+	require.NoError(t, err)
+	limited, _, err := key.RateLimit(History, 1)
+	require.NoError(t, err)
+	assert.False(t, limited)
+
+	// unknown keys are cached until minReloadInterval
+	key, err = limiter.Lookup(ctx, "explorer")
+	require.NoError(t, err)
+	assert.Nil(t, key)
+
+	*now = now.Add(minReloadInterval)
+	q.On("GetAPIKeys", mock.Anything).Return([]history.APIKey{wallet, explorer}, nil).Once()
+	key, err = limiter.Lookup(ctx, "explorer")
+	require.NoError(t, err)
+	require.NotNil(t, key)
+	assert.Equal(t, "explorer", key.Name)
+
+	// known keys are reloaded after refreshInterval, the state of unchanged
+	// quotas is kept
+	*now = now.Add(refreshInterval)
+	wallet.Name = "mobile wallet"
+	explorer.StateQuota = quota(2)
+	q.On("GetAPIKeys", mock.Anything).Return([]history.APIKey{wallet, explorer}, nil).Once()
+	key, err = limiter.Lookup(ctx, "wallet")
+	require.NoError(t, err)
+	assert.Equal(t, "mobile wallet", key.Name)
+	limited, _, err = key.RateLimit(History, 1)
+	require.NoError(t, err)
+	assert.True(t, limited)
+
+	key, err = limiter.Lookup(ctx, "explorer")
+	require.NoError(t, err)
+	_, result, err := key.RateLimit(State, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Limit)
+
+	// cached keys are used when the database cannot be reached
+	*now = now.Add(refreshInterval)
+	q.On("GetAPIKeys", mock.Anything).Return([]history.APIKey(nil), errors.New("connection refused")).Twice()
+	key, err = limiter.Lookup(ctx, "wallet")
+	require.NoError(t, err)
+	assert.NotNil(t, key)
+	_, err = limiter.Lookup(ctx, "unknown")
+	assert.EqualError(t, err, "could not load api keys: connection refused")
+	q.AssertExpectations(t)
+}
+
+func TestMiddleware(t *testing.T) {
+	q := &history.MockQAPIKeys{}
+	q.On("GetAPIKeys", mock.Anything).Return([]history.APIKey{{
+		ID:              1,
+		Name:            "wallet",
+		KeyHash:         HashKey("key"),
+		StateQuota:      quota(1),
+		StreamsQuota:    quota(10),
+		SubmissionQuota: quota(0),
+	}}, nil)
+	limiter, _, _ := newTestLimiter(q)
+
+	var requestKey *Key
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestKey, _ = FromContext(r.Context())
+	}))
+	serve := func(method, target, apiKey, accept string) *httptest.ResponseRecorder {
+		requestKey = nil
+		r := httptest.NewRequest(method, target, nil)
+		if apiKey != "" {
+			r.Header.Set(Header, apiKey)
+		}
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// requests without a key are not limited
+	w := serve(http.MethodGet, "/accounts/GABC", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, requestKey)
+	assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+
+	w = serve(http.MethodGet, "/accounts/GABC", "unknown", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = serve(http.MethodGet, "/accounts/GABC?api_key=key", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, requestKey)
+	assert.Equal(t, "wallet", requestKey.Name)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = serve(http.MethodGet, "/accounts/GABC", "key", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Nil(t, requestKey)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	w = serve(http.MethodPost, "/transactions", "key", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// history requests are not limited
+	w = serve(http.MethodGet, "/ledgers", "key", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+
+	// streams are charged by the stream handler
+	for i := 0; i < 2; i++ {
+		w = serve(http.MethodGet, "/accounts/GABC", "key", "text/event-stream")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "10", w.Header().Get("X-RateLimit-Remaining"))
+		assert.NotNil(t, requestKey)
+	}
+}
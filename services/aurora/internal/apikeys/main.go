@@ -0,0 +1,136 @@
+// Package apikeys enforces per API key quotas in aurora. Keys are stored in
+// the aurora database, as sha256 hashes, and managed over the admin port. Each
+// key has a quota of requests per hour for every endpoint class. Requests
+// made with a key are charged to the quota of the key instead of the rate
+// limit of the client IP address, and the usage of every key is exported to
+// prometheus.
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/render"
+	"github.com/hcnet/go/support/errors"
+)
+
+const (
+	// Header is the request header holding the API key.
+	Header = "X-API-Key"
+	// QueryParam is the query parameter holding the API key when the header
+	// is not set. EventSource clients cannot set request headers.
+	QueryParam = "api_key"
+
+	redactedKey = "REDACTED"
+
+	keyLength = 32
+)
+
+// Class is a class of endpoints sharing a quota.
+type Class string
+
+const (
+	// State is the class of the endpoints serving the current ledger state,
+	// ex. accounts, offers and liquidity pools.
+	State Class = "state"
+	// History is the class of the endpoints serving historical data, ex.
+	// ledgers, transactions, operations and effects.
+	History Class = "history"
+	// Streams is the class of all streaming requests. Streams are charged
+	// for every update instead of once per request.
+	Streams Class = "streams"
+	// Paths is the class of the path finding and order book analytics
+	// endpoints.
+	Paths Class = "paths"
+	// Submission is the class of the transaction submission endpoints.
+	Submission Class = "submission"
+)
+
+// Classes are all the endpoint classes.
+var Classes = []Class{State, History, Streams, Paths, Submission}
+
+var historyResources = map[string]bool{
+	"ledgers":            true,
+	"transactions":       true,
+	"operations":         true,
+	"payments":           true,
+	"effects":            true,
+	"trades":             true,
+	"trade_aggregations": true,
+	"events":             true,
+}
+
+// Classify returns the endpoint class of a request.
+func Classify(r *http.Request) Class {
+	if render.Negotiate(r) == render.MimeEventStream {
+		return Streams
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	if r.Method == http.MethodPost && (path == "transactions" || path == "transactions_async") {
+		return Submission
+	}
+	if path == "paths" || strings.HasPrefix(path, "paths/") || path == "order_book/depth" {
+		return Paths
+	}
+
+	segments := strings.Split(path, "/")
+	if historyResources[segments[0]] || historyResources[segments[len(segments)-1]] {
+		return History
+	}
+	return State
+}
+
+// GenerateKey returns a new random API key and its hash.
+func GenerateKey() (string, string, error) {
+	raw := make([]byte, keyLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", errors.Wrap(err, "could not generate api key")
+	}
+	key := hex.EncodeToString(raw)
+	return key, HashKey(key), nil
+}
+
+// HashKey returns the hash of an API key as stored in the database.
+func HashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// Redact returns a copy of the request with its API key, either in the query
+// or in the header, replaced so that the request can be logged or reported.
+// The request itself is returned when it has no key.
+func Redact(r *http.Request) *http.Request {
+	query := r.URL.Query()
+	_, inQuery := query[QueryParam]
+	inHeader := r.Header.Get(Header) != ""
+	if !inQuery && !inHeader {
+		return r
+	}
+
+	redacted := r.Clone(r.Context())
+	if inQuery {
+		query.Set(QueryParam, redactedKey)
+		redacted.URL.RawQuery = query.Encode()
+	}
+	if inHeader {
+		redacted.Header.Set(Header, redactedKey)
+	}
+	return redacted
+}
+
+// Quotas returns the quotas of a key by endpoint class.
+func Quotas(key history.APIKey) map[Class]sql.NullInt64 {
+	return map[Class]sql.NullInt64{
+		State:      key.StateQuota,
+		History:    key.HistoryQuota,
+		Streams:    key.StreamsQuota,
+		Paths:      key.PathsQuota,
+		Submission: key.SubmissionQuota,
+	}
+}
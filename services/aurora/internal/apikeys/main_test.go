@@ -0,0 +1,58 @@
+package apikeys
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	for _, testCase := range []struct {
+		method string
+		path   string
+		accept string
+		class  Class
+	}{
+		{http.MethodGet, "/", "", State},
+		{http.MethodGet, "/accounts/GABC", "", State},
+		{http.MethodGet, "/accounts/GABC/offers", "", State},
+		{http.MethodGet, "/order_book", "", State},
+		{http.MethodGet, "/fee_stats", "", State},
+		{http.MethodGet, "/accounts/GABC/payments", "", History},
+		{http.MethodGet, "/ledgers/10", "", History},
+		{http.MethodGet, "/transactions/abcd", "", History},
+		{http.MethodGet, "/liquidity_pools/abcd/trades", "", History},
+		{http.MethodGet, "/contracts/CABC/events", "", History},
+		{http.MethodGet, "/trade_aggregations", "", History},
+		{http.MethodGet, "/paths/strict-send", "", Paths},
+		{http.MethodPost, "/paths/batch", "", Paths},
+		{http.MethodGet, "/order_book/depth", "", Paths},
+		{http.MethodPost, "/transactions", "", Submission},
+		{http.MethodPost, "/transactions_async", "", Submission},
+		{http.MethodGet, "/accounts/GABC/payments", "text/event-stream", Streams},
+		{http.MethodGet, "/order_book", "text/event-stream", Streams},
+	} {
+		t.Run(testCase.method+" "+testCase.path, func(t *testing.T) {
+			r := httptest.NewRequest(testCase.method, testCase.path, nil)
+			if testCase.accept != "" {
+				r.Header.Set("Accept", testCase.accept)
+			}
+			assert.Equal(t, testCase.class, Classify(r))
+		})
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	key, hash, err := GenerateKey()
+	require.NoError(t, err)
+	assert.Len(t, key, 2*keyLength)
+	assert.Equal(t, HashKey(key), hash)
+	assert.NotEqual(t, key, hash)
+
+	other, _, err := GenerateKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
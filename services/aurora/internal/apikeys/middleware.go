@@ -0,0 +1,95 @@
+package apikeys
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/stellar/throttled"
+
+	hProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/support/render/problem"
+)
+
+type ctxKey string
+
+var keyContextKey = ctxKey("api_key")
+
+// NewContext returns a context holding the API key of a request.
+func NewContext(ctx context.Context, key *Key) context.Context {
+	return context.WithValue(ctx, keyContextKey, key)
+}
+
+// FromContext returns the API key of the request of ctx, if it has one.
+func FromContext(ctx context.Context) (*Key, bool) {
+	key, ok := ctx.Value(keyContextKey).(*Key)
+	return key, ok
+}
+
+// Middleware charges requests made with an API key to the quota of the key
+// for their endpoint class and adds the key to the request context. Requests
+// without a key are passed through unchanged, so they are subject to the rate
+// limit of the client IP address. Streaming requests are not charged here,
+// the stream handler charges every update instead.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := r.Header.Get(Header)
+		if raw == "" {
+			raw = r.URL.Query().Get(QueryParam)
+		}
+		if raw == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		key, err := l.Lookup(ctx, raw)
+		if err != nil {
+			problem.Render(ctx, w, err)
+			return
+		}
+		if key == nil {
+			problem.Render(ctx, w, hProblem.InvalidAPIKey)
+			return
+		}
+
+		class := Classify(r)
+		if !key.Allowed(class) {
+			problem.Render(ctx, w, hProblem.APIKeyForbidden)
+			return
+		}
+		quantity := 1
+		if class == Streams {
+			quantity = 0
+		}
+		limited, result, err := key.RateLimit(class, quantity)
+		if err != nil {
+			problem.Render(ctx, w, err)
+			return
+		}
+		setRateLimitHeaders(w, result)
+		if limited {
+			problem.Render(ctx, w, hProblem.APIKeyQuotaExceeded)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(NewContext(ctx, key)))
+	})
+}
+
+// setRateLimitHeaders sets the same headers as the IP address rate limiter.
+func setRateLimitHeaders(w http.ResponseWriter, result throttled.RateLimitResult) {
+	if result.Limit >= 0 {
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	}
+	if result.Remaining >= 0 {
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	}
+	if result.ResetAfter >= 0 {
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.ResetAfter.Seconds()))))
+	}
+	if result.RetryAfter >= 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+	}
+}
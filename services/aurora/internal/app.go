@@ -19,11 +19,14 @@ import (
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/services/aurora/internal/httpx"
 	"github.com/hcnet/go/services/aurora/internal/ingest"
+	"github.com/hcnet/go/services/aurora/internal/ingest/sinks"
 	"github.com/hcnet/go/services/aurora/internal/ledger"
 	"github.com/hcnet/go/services/aurora/internal/operationfeestats"
 	"github.com/hcnet/go/services/aurora/internal/paths"
 	"github.com/hcnet/go/services/aurora/internal/reap"
+	"github.com/hcnet/go/services/aurora/internal/ssehub"
 	"github.com/hcnet/go/services/aurora/internal/txsub"
+	"github.com/hcnet/go/services/aurora/internal/webhooks"
 	"github.com/hcnet/go/support/app"
 	"github.com/hcnet/go/support/db"
 	"github.com/hcnet/go/support/errors"
@@ -47,7 +50,10 @@ type App struct {
 	submitter       *txsub.System
 	paths           paths.Finder
 	ingester        ingest.System
+	ingestionSink   sinks.Sink
 	reaper          *reap.System
+	webhooks        *webhooks.System
+	sseHub          *ssehub.Hub
 	ticks           *time.Ticker
 	ledgerState     *ledger.State
 
@@ -115,6 +121,22 @@ func (a *App) Serve() error {
 		}()
 	}
 
+	if a.webhooks != nil {
+		wg.Add(1)
+		go func() {
+			a.webhooks.Run()
+			wg.Done()
+		}()
+	}
+
+	if a.sseHub != nil {
+		wg.Add(1)
+		go func() {
+			a.sseHub.Run()
+			wg.Done()
+		}()
+	}
+
 	// configure shutdown signal handler
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
@@ -163,9 +185,20 @@ func (a *App) waitForDone() {
 	if a.ingester != nil {
 		a.ingester.Shutdown()
 	}
+	if a.ingestionSink != nil {
+		if err := a.ingestionSink.Close(); err != nil {
+			log.Warnf("could not close ingestion sink: %s", err)
+		}
+	}
 	if a.reaper != nil {
 		a.reaper.Shutdown()
 	}
+	if a.webhooks != nil {
+		a.webhooks.Shutdown()
+	}
+	if a.sseHub != nil {
+		a.sseHub.Shutdown()
+	}
 	a.ticks.Stop()
 }
 
@@ -508,6 +541,27 @@ func (a *App) init() error {
 	// reaper
 	a.reaper = reap.New(a.config.HistoryRetentionCount, a.AuroraSession(), a.ledgerState)
 
+	// webhooks
+	if a.config.EnableWebhooks {
+		a.webhooks = webhooks.New(
+			webhooks.Config{MaxAttempts: int32(a.config.WebhookMaxAttempts)},
+			a.AuroraSession(),
+			a.ledgerState,
+		)
+	}
+
+	// sse hub
+	if a.config.EnableSSEHub {
+		a.sseHub = ssehub.New(
+			ssehub.Config{
+				BufferLedgers: uint32(a.config.SSEReplayBufferLedgers),
+				PollInterval:  a.config.SSEUpdateFrequency,
+			},
+			a.AuroraSession(),
+			a.ledgerState,
+		)
+	}
+
 	// go metrics
 	initGoMetrics(a)
 
@@ -542,7 +596,12 @@ func (a *App) init() error {
 		AuroraVersion:           a.auroraVersion,
 		FriendbotURL:             a.config.FriendbotURL,
 		EnableIngestionFiltering: a.config.EnableIngestionFiltering,
+		EnableWebhooks:           a.config.EnableWebhooks,
+		EnableAPIKeys:            a.config.EnableAPIKeys,
+		EnableGraphQL:            a.config.EnableGraphQL,
+		GraphQLMaxQueryCost:      a.config.GraphQLMaxQueryCost,
 		DisableTxSub:             a.config.DisableTxSub,
+		SSEHub:                   a.sseHub,
 		HealthCheck: healthCheck{
 			session: a.historyQ.SessionInterface,
 			ctx:     a.ctx,
@@ -57,6 +57,10 @@ type Config struct {
 	// MaxPathFindingRequests is the maximum number of path finding requests aurora will allow
 	// in a 1-second period. A value of 0 disables the limit.
 	MaxPathFindingRequests uint
+	// OrderBookSnapshotPath is the file where the in memory order book graph used for path
+	// finding is persisted so that it does not need to be rebuilt from the DB on restart.
+	// An empty value disables snapshots.
+	OrderBookSnapshotPath string
 
 	NetworkPassphrase string
 	SentryDSN         string
@@ -78,6 +82,13 @@ type Config struct {
 	// determining a "retention duration", each ledger roughly corresponds to 10
 	// seconds of real time.
 	HistoryRetentionCount uint
+	// EnableStateHistory records the versions of the ledger entries so the
+	// state endpoints can be queried at past ledgers with `at_ledger`. The
+	// state history is bounded by HistoryRetentionCount.
+	EnableStateHistory bool
+	// IngestionSink is the url of the sink receiving the data of the ingested
+	// ledgers in addition to the database. Empty disables the sink.
+	IngestionSink string
 	// StaleThreshold represents the number of ledgers a history database may be
 	// out-of-date by before aurora begins to respond with an error to history
 	// requests.
@@ -117,4 +128,28 @@ type Config struct {
 	Network string
 	// DisableTxSub disables transaction submission functionality for Aurora.
 	DisableTxSub bool
+	// EnableWebhooks enables the webhook delivery subsystem and the admin
+	// endpoints used to manage webhook subscriptions.
+	EnableWebhooks bool
+	// WebhookMaxAttempts is the number of failed attempts after which a webhook
+	// delivery is moved to the dead letter table.
+	WebhookMaxAttempts uint
+	// EnableAPIKeys enables the per API key quotas and the admin endpoints
+	// used to manage the keys.
+	EnableAPIKeys bool
+	// EnableGraphQL enables the GraphQL endpoint.
+	EnableGraphQL bool
+	// GraphQLMaxQueryCost is the maximum cost of a GraphQL query, 0 means
+	// unlimited.
+	GraphQLMaxQueryCost uint
+	// EnableSSEHub makes the history streams share the queries of every new
+	// ledger and replay the latest ledgers from memory.
+	EnableSSEHub bool
+	// SSEReplayBufferLedgers is the number of ledgers kept in memory by the
+	// SSE hub.
+	SSEReplayBufferLedgers uint
+	// LedgerBackend, if set, is used by ingestion instead of captive core. It
+	// cannot be configured with flags, it is used to run Aurora against an
+	// in-process network in tests.
+	LedgerBackend ledgerbackend.LedgerBackend
 }
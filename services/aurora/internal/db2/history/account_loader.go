@@ -31,6 +31,11 @@ func (a FutureAccountID) Value() (driver.Value, error) {
 	return a.loader.GetNow(a.address)
 }
 
+// Address returns the address of the account.
+func (a FutureAccountID) Address() string {
+	return a.address
+}
+
 // AccountLoader will map account addresses to their history
 // account ids. If there is no existing mapping for a given address,
 // the AccountLoader will insert into the history_accounts table to
@@ -0,0 +1,105 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const apiKeysTableName = "api_keys"
+
+// APIKey is a row of data from the `api_keys` table. Only the hash of the key
+// is stored. The quotas are the number of requests allowed per hour for each
+// endpoint class, a NULL quota means the class is not limited.
+type APIKey struct {
+	ID              int64         `db:"id"`
+	Name            string        `db:"name"`
+	KeyHash         string        `db:"key_hash"`
+	StateQuota      sql.NullInt64 `db:"state_quota"`
+	HistoryQuota    sql.NullInt64 `db:"history_quota"`
+	StreamsQuota    sql.NullInt64 `db:"streams_quota"`
+	PathsQuota      sql.NullInt64 `db:"paths_quota"`
+	SubmissionQuota sql.NullInt64 `db:"submission_quota"`
+	CreatedAt       time.Time     `db:"created_at"`
+}
+
+// QAPIKeys defines API key related queries.
+type QAPIKeys interface {
+	GetAPIKeys(ctx context.Context) ([]APIKey, error)
+	GetAPIKeyByID(ctx context.Context, id int64) (APIKey, error)
+	InsertAPIKey(ctx context.Context, key APIKey) (APIKey, error)
+	UpdateAPIKey(ctx context.Context, key APIKey) (APIKey, error)
+	DeleteAPIKey(ctx context.Context, id int64) error
+}
+
+func (key APIKey) quotasMap() map[string]interface{} {
+	return map[string]interface{}{
+		"name":             key.Name,
+		"state_quota":      key.StateQuota,
+		"history_quota":    key.HistoryQuota,
+		"streams_quota":    key.StreamsQuota,
+		"paths_quota":      key.PathsQuota,
+		"submission_quota": key.SubmissionQuota,
+	}
+}
+
+// GetAPIKeys returns all the API keys ordered by id.
+func (q *Q) GetAPIKeys(ctx context.Context) ([]APIKey, error) {
+	var keys []APIKey
+	sql := sq.Select("*").From(apiKeysTableName).OrderBy("id asc")
+	err := q.Select(ctx, &keys, sql)
+	return keys, err
+}
+
+// GetAPIKeyByID returns the API key with the given id.
+func (q *Q) GetAPIKeyByID(ctx context.Context, id int64) (APIKey, error) {
+	var key APIKey
+	sql := sq.Select("*").From(apiKeysTableName).Where("id = ?", id)
+	err := q.Get(ctx, &key, sql)
+	return key, err
+}
+
+// InsertAPIKey stores a new API key and returns it with its id and creation
+// time populated.
+func (q *Q) InsertAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	values := key.quotasMap()
+	values["key_hash"] = key.KeyHash
+	values["created_at"] = sq.Expr("now() at time zone 'utc'")
+	sql := sq.Insert(apiKeysTableName).SetMap(values).Suffix("RETURNING *")
+
+	var inserted APIKey
+	err := q.Get(ctx, &inserted, sql)
+	return inserted, err
+}
+
+// UpdateAPIKey updates the name and the quotas of an API key. The hash of the
+// key cannot be changed. sql.ErrNoRows is returned if the key does not exist.
+func (q *Q) UpdateAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	sql := sq.Update(apiKeysTableName).
+		SetMap(key.quotasMap()).
+		Where("id = ?", key.ID).
+		Suffix("RETURNING *")
+
+	var updated APIKey
+	err := q.Get(ctx, &updated, sql)
+	return updated, err
+}
+
+// DeleteAPIKey removes an API key. sql.ErrNoRows is returned if the key does
+// not exist.
+func (q *Q) DeleteAPIKey(ctx context.Context, id int64) error {
+	rowCnt, err := q.checkForError(
+		sq.Delete(apiKeysTableName).Where("id = ?", id),
+		ctx,
+	)
+	if err != nil {
+		return err
+	}
+
+	if rowCnt < 1 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
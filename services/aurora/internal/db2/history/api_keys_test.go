@@ -0,0 +1,69 @@
+package history
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hcnet/go/services/aurora/internal/test"
+)
+
+func TestAPIKeys(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &Q{tt.AuroraSession()}
+
+	keys, err := q.GetAPIKeys(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(keys)
+
+	wallet, err := q.InsertAPIKey(tt.Ctx, APIKey{
+		Name:         "wallet",
+		KeyHash:      "hash1",
+		HistoryQuota: sql.NullInt64{Int64: 1000, Valid: true},
+	})
+	tt.Assert.NoError(err)
+	tt.Assert.NotZero(wallet.ID)
+	tt.Assert.False(wallet.CreatedAt.IsZero())
+	tt.Assert.Equal(int64(1000), wallet.HistoryQuota.Int64)
+	tt.Assert.False(wallet.StateQuota.Valid)
+
+	explorer, err := q.InsertAPIKey(tt.Ctx, APIKey{Name: "explorer", KeyHash: "hash2"})
+	tt.Assert.NoError(err)
+
+	// names and key hashes are unique
+	_, err = q.InsertAPIKey(tt.Ctx, APIKey{Name: "explorer", KeyHash: "hash3"})
+	tt.Assert.Error(err)
+	_, err = q.InsertAPIKey(tt.Ctx, APIKey{Name: "duplicate", KeyHash: "hash2"})
+	tt.Assert.Error(err)
+
+	keys, err = q.GetAPIKeys(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(keys, 2)
+	tt.Assert.Equal(wallet.ID, keys[0].ID)
+	tt.Assert.Equal(explorer.ID, keys[1].ID)
+
+	wallet.Name = "mobile wallet"
+	wallet.HistoryQuota = sql.NullInt64{}
+	wallet.StreamsQuota = sql.NullInt64{Int64: 0, Valid: true}
+	wallet.KeyHash = "ignored"
+	updated, err := q.UpdateAPIKey(tt.Ctx, wallet)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("mobile wallet", updated.Name)
+	tt.Assert.Equal("hash1", updated.KeyHash)
+	tt.Assert.False(updated.HistoryQuota.Valid)
+	tt.Assert.True(updated.StreamsQuota.Valid)
+
+	fetched, err := q.GetAPIKeyByID(tt.Ctx, wallet.ID)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(updated, fetched)
+
+	tt.Assert.NoError(q.DeleteAPIKey(tt.Ctx, wallet.ID))
+	tt.Assert.Equal(sql.ErrNoRows, q.DeleteAPIKey(tt.Ctx, wallet.ID))
+	_, err = q.UpdateAPIKey(tt.Ctx, wallet)
+	tt.Assert.True(q.NoRows(err))
+
+	keys, err = q.GetAPIKeys(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(keys, 1)
+}
@@ -0,0 +1,148 @@
+package history
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/errors"
+)
+
+// ContractDataEntry is a row of data from the `contract_data` table
+type ContractDataEntry struct {
+	KeyHash            string `db:"key_hash"`
+	ContractID         string `db:"contract_id"`
+	Durability         int32  `db:"durability"`
+	Key                string `db:"key"`
+	Value              string `db:"value"`
+	LastModifiedLedger uint32 `db:"last_modified_ledger"`
+}
+
+// PagingToken returns a cursor for this contract data entry
+func (e ContractDataEntry) PagingToken() string {
+	return e.KeyHash
+}
+
+// QContractData defines contract_data related queries.
+type QContractData interface {
+	CountContractData(ctx context.Context) (int, error)
+	GetContractDataByKeyHashes(ctx context.Context, keyHashes []string) ([]ContractDataEntry, error)
+	UpsertContractData(ctx context.Context, entries []ContractDataEntry) error
+	RemoveContractData(ctx context.Context, keyHashes []string) (int64, error)
+	NewContractDataBatchInsertBuilder() ContractDataBatchInsertBuilder
+}
+
+// CountContractData returns the number of entries in the contract_data table.
+func (q *Q) CountContractData(ctx context.Context) (int, error) {
+	sql := sq.Select("count(*)").From("contract_data")
+
+	var count int
+	if err := q.Get(ctx, &count, sql); err != nil {
+		return 0, errors.Wrap(err, "could not run select query")
+	}
+
+	return count, nil
+}
+
+// GetContractData returns the current storage entries of the given contract.
+func (q *Q) GetContractData(ctx context.Context, contractID string, page db2.PageQuery) ([]ContractDataEntry, error) {
+	sql, err := page.ApplyRawTo(selectContractData.Where("contract_id = ?", contractID), "key_hash")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not apply query to page")
+	}
+
+	var entries []ContractDataEntry
+	err = q.Select(ctx, &entries, sql)
+	return entries, err
+}
+
+// GetContractDataByKeyHashes loads rows from the `contract_data` table,
+// selected by their key hashes.
+func (q *Q) GetContractDataByKeyHashes(ctx context.Context, keyHashes []string) ([]ContractDataEntry, error) {
+	var entries []ContractDataEntry
+	sql := selectContractData.Where(map[string]interface{}{"key_hash": keyHashes})
+	err := q.Select(ctx, &entries, sql)
+	return entries, err
+}
+
+// UpsertContractData upserts a batch of entries in the contract_data table.
+func (q *Q) UpsertContractData(ctx context.Context, entries []ContractDataEntry) error {
+	var keyHash, contractID, durability, key, value, lastModifiedLedger []interface{}
+
+	for _, entry := range entries {
+		keyHash = append(keyHash, entry.KeyHash)
+		contractID = append(contractID, entry.ContractID)
+		durability = append(durability, entry.Durability)
+		key = append(key, entry.Key)
+		value = append(value, entry.Value)
+		lastModifiedLedger = append(lastModifiedLedger, entry.LastModifiedLedger)
+	}
+
+	upsertFields := []upsertField{
+		{"key_hash", "text", keyHash},
+		{"contract_id", "text", contractID},
+		{"durability", "int", durability},
+		{"key", "text", key},
+		{"value", "text", value},
+		{"last_modified_ledger", "int", lastModifiedLedger},
+	}
+
+	return q.upsertRows(ctx, "contract_data", "key_hash", upsertFields)
+}
+
+// RemoveContractData deletes rows in the contract_data table.
+// Returns number of rows affected and error.
+func (q *Q) RemoveContractData(ctx context.Context, keyHashes []string) (int64, error) {
+	sql := sq.Delete("contract_data").
+		Where(map[string]interface{}{"key_hash": keyHashes})
+	result, err := q.Exec(ctx, sql)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+var selectContractData = sq.Select(`
+	key_hash,
+	contract_id,
+	durability,
+	key,
+	value,
+	last_modified_ledger
+`).From("contract_data")
+
+// ContractDataBatchInsertBuilder is used to insert entries into the
+// contract_data table
+type ContractDataBatchInsertBuilder interface {
+	Add(entry ContractDataEntry) error
+	Exec(ctx context.Context) error
+}
+
+// contractDataBatchInsertBuilder is a simple wrapper around db.FastBatchInsertBuilder
+type contractDataBatchInsertBuilder struct {
+	session db.SessionInterface
+	builder db.FastBatchInsertBuilder
+	table   string
+}
+
+// NewContractDataBatchInsertBuilder constructs a new ContractDataBatchInsertBuilder instance
+func (q *Q) NewContractDataBatchInsertBuilder() ContractDataBatchInsertBuilder {
+	return &contractDataBatchInsertBuilder{
+		session: q,
+		builder: db.FastBatchInsertBuilder{},
+		table:   "contract_data",
+	}
+}
+
+// Add adds a new contract data entry to the batch
+func (i *contractDataBatchInsertBuilder) Add(entry ContractDataEntry) error {
+	return i.builder.RowStruct(entry)
+}
+
+// Exec writes the batch of contract data entries to the database.
+func (i *contractDataBatchInsertBuilder) Exec(ctx context.Context) error {
+	return i.builder.Exec(ctx, i.session, i.table)
+}
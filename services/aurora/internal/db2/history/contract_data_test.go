@@ -0,0 +1,61 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/services/aurora/internal/test"
+)
+
+func TestContractDataQueries(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &Q{tt.AuroraSession()}
+	tt.Assert.NoError(q.Begin(tt.Ctx))
+
+	contractA := "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS"
+	contractB := "CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAD2KM"
+	entries := []ContractDataEntry{
+		{KeyHash: "aa", ContractID: contractA, Durability: 1, Key: "key1", Value: "value1", LastModifiedLedger: 10},
+		{KeyHash: "bb", ContractID: contractA, Durability: 0, Key: "key2", Value: "value2", LastModifiedLedger: 11},
+		{KeyHash: "cc", ContractID: contractB, Durability: 1, Key: "key3", Value: "value3", LastModifiedLedger: 12},
+	}
+
+	builder := q.NewContractDataBatchInsertBuilder()
+	for _, entry := range entries {
+		tt.Assert.NoError(builder.Add(entry))
+	}
+	tt.Assert.NoError(builder.Exec(tt.Ctx))
+
+	count, err := q.CountContractData(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(3, count)
+
+	updated := entries[1]
+	updated.Value = "value2-updated"
+	updated.LastModifiedLedger = 13
+	tt.Assert.NoError(q.UpsertContractData(tt.Ctx, []ContractDataEntry{updated}))
+
+	rows, err := q.GetContractDataByKeyHashes(tt.Ctx, []string{"bb"})
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]ContractDataEntry{updated}, rows)
+
+	rows, err = q.GetContractData(tt.Ctx, contractA, db2.PageQuery{Order: "asc", Limit: 10})
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]ContractDataEntry{entries[0], updated}, rows)
+
+	rows, err = q.GetContractData(tt.Ctx, contractA, db2.PageQuery{Order: "desc", Limit: 10, Cursor: "bb"})
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]ContractDataEntry{entries[0]}, rows)
+
+	removed, err := q.RemoveContractData(tt.Ctx, []string{"aa", "cc"})
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(2), removed)
+
+	count, err = q.CountContractData(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(1, count)
+
+	tt.Assert.NoError(q.Rollback())
+}
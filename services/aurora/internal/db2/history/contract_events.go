@@ -0,0 +1,173 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/toid"
+)
+
+// MaxContractEventTopics is the maximum number of topics a contract event can
+// be filtered by.
+const MaxContractEventTopics = 4
+
+// ContractEvent is a row of data from the `history_contract_events` table
+type ContractEvent struct {
+	HistoryOperationID int64          `db:"history_operation_id"`
+	Order              int32          `db:"order"`
+	ContractID         string         `db:"contract_id"`
+	Topics             pq.StringArray `db:"topics"`
+	Value              string         `db:"value"`
+}
+
+// ID returns a lexically ordered id for this contract event record
+func (r ContractEvent) ID() string {
+	return fmt.Sprintf("%019d-%010d", r.HistoryOperationID, r.Order)
+}
+
+// LedgerSequence return the ledger in which the contract event was emitted.
+func (r ContractEvent) LedgerSequence() int32 {
+	return toid.Parse(r.HistoryOperationID).LedgerSequence
+}
+
+// PagingToken returns a cursor for this contract event
+func (r ContractEvent) PagingToken() string {
+	return fmt.Sprintf("%d-%d", r.HistoryOperationID, r.Order)
+}
+
+// ContractEventsQ is a helper struct to aid in configuring queries that load
+// slices of ContractEvent structs.
+type ContractEventsQ struct {
+	Err    error
+	parent *Q
+	sql    sq.SelectBuilder
+}
+
+// QContractEvents defines history_contract_events related queries.
+type QContractEvents interface {
+	NewContractEventBatchInsertBuilder() ContractEventBatchInsertBuilder
+}
+
+// ContractEvents provides a helper to filter rows from the
+// `history_contract_events` table with pre-defined filters.
+func (q *Q) ContractEvents() *ContractEventsQ {
+	return &ContractEventsQ{
+		parent: q,
+		sql:    selectContractEvent,
+	}
+}
+
+// ForContract filters the query to only events emitted by the given contract.
+func (q *ContractEventsQ) ForContract(contractID string) *ContractEventsQ {
+	q.sql = q.sql.Where("hce.contract_id = ?", contractID)
+	return q
+}
+
+// ForTopics filters the query to only events whose topics match the given
+// ones. Topics are matched by position, an empty string matches any topic.
+func (q *ContractEventsQ) ForTopics(topics []string) *ContractEventsQ {
+	if len(topics) > MaxContractEventTopics {
+		q.Err = fmt.Errorf("at most %d topics can be filtered", MaxContractEventTopics)
+		return q
+	}
+
+	for i, topic := range topics {
+		if topic == "" {
+			continue
+		}
+		// postgres arrays are 1-indexed
+		q.sql = q.sql.Where(fmt.Sprintf("hce.topics[%d] = ?", i+1), topic)
+	}
+	return q
+}
+
+// Page specifies the paging constraints for the query being built by `q`.
+func (q *ContractEventsQ) Page(page db2.PageQuery) *ContractEventsQ {
+	if q.Err != nil {
+		return q
+	}
+
+	op, idx, err := page.CursorInt64Pair(db2.DefaultPairSep)
+	if err != nil {
+		q.Err = err
+		return q
+	}
+
+	if idx > math.MaxInt32 {
+		idx = math.MaxInt32
+	}
+
+	switch page.Order {
+	case "asc":
+		q.sql = q.sql.
+			Where(`(
+					 hce.history_operation_id >= ?
+				AND (
+					 hce.history_operation_id > ? OR
+					(hce.history_operation_id = ? AND hce.order > ?)
+				))`, op, op, op, idx).
+			OrderBy("hce.history_operation_id asc, hce.order asc")
+	case "desc":
+		q.sql = q.sql.
+			Where(`(
+					 hce.history_operation_id <= ?
+				AND (
+					 hce.history_operation_id < ? OR
+					(hce.history_operation_id = ? AND hce.order < ?)
+				))`, op, op, op, idx).
+			OrderBy("hce.history_operation_id desc, hce.order desc")
+	}
+
+	q.sql = q.sql.Limit(page.Limit)
+	return q
+}
+
+// Select loads the results of the query specified by `q` into `dest`.
+func (q *ContractEventsQ) Select(ctx context.Context, dest interface{}) error {
+	if q.Err != nil {
+		return q.Err
+	}
+
+	q.Err = q.parent.Select(ctx, dest, q.sql)
+	return q.Err
+}
+
+var selectContractEvent = sq.Select("hce.*").
+	From("history_contract_events hce")
+
+// ContractEventBatchInsertBuilder is used to insert contract events into the
+// history_contract_events table
+type ContractEventBatchInsertBuilder interface {
+	Add(event ContractEvent) error
+	Exec(ctx context.Context, session db.SessionInterface) error
+}
+
+// contractEventBatchInsertBuilder is a simple wrapper around db.FastBatchInsertBuilder
+type contractEventBatchInsertBuilder struct {
+	table   string
+	builder db.FastBatchInsertBuilder
+}
+
+// NewContractEventBatchInsertBuilder constructs a new ContractEventBatchInsertBuilder instance
+func (q *Q) NewContractEventBatchInsertBuilder() ContractEventBatchInsertBuilder {
+	return &contractEventBatchInsertBuilder{
+		table:   "history_contract_events",
+		builder: db.FastBatchInsertBuilder{},
+	}
+}
+
+// Add adds a contract event to the batch
+func (i *contractEventBatchInsertBuilder) Add(event ContractEvent) error {
+	return i.builder.RowStruct(event)
+}
+
+// Exec writes the batch of contract events to the database.
+func (i *contractEventBatchInsertBuilder) Exec(ctx context.Context, session db.SessionInterface) error {
+	return i.builder.Exec(ctx, session, i.table)
+}
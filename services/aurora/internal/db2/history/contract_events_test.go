@@ -0,0 +1,74 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/services/aurora/internal/test"
+	"github.com/hcnet/go/toid"
+)
+
+func TestContractEventsQueries(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &Q{tt.AuroraSession()}
+	tt.Assert.NoError(q.Begin(tt.Ctx))
+
+	contractA := "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS"
+	contractB := "CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAD2KM"
+	events := []ContractEvent{
+		{
+			HistoryOperationID: toid.New(10, 1, 1).ToInt64(),
+			Order:              1,
+			ContractID:         contractA,
+			Topics:             []string{"transfer", "from", "to"},
+			Value:              "value1",
+		},
+		{
+			HistoryOperationID: toid.New(10, 1, 1).ToInt64(),
+			Order:              2,
+			ContractID:         contractB,
+			Topics:             []string{"transfer", "from", "to"},
+			Value:              "value2",
+		},
+		{
+			HistoryOperationID: toid.New(11, 1, 1).ToInt64(),
+			Order:              1,
+			ContractID:         contractA,
+			Topics:             []string{"mint", "admin", "to"},
+			Value:              "value3",
+		},
+	}
+
+	builder := q.NewContractEventBatchInsertBuilder()
+	for _, event := range events {
+		tt.Assert.NoError(builder.Add(event))
+	}
+	tt.Assert.NoError(builder.Exec(tt.Ctx, q))
+	tt.Assert.NoError(q.Commit())
+
+	page := db2.PageQuery{Order: "asc", Limit: 10}
+
+	var result []ContractEvent
+	tt.Assert.NoError(q.ContractEvents().ForContract(contractA).Page(page).Select(tt.Ctx, &result))
+	tt.Assert.Equal([]ContractEvent{events[0], events[2]}, result)
+
+	result = nil
+	tt.Assert.NoError(q.ContractEvents().ForContract(contractA).ForTopics([]string{"", "admin"}).
+		Page(page).Select(tt.Ctx, &result))
+	tt.Assert.Equal([]ContractEvent{events[2]}, result)
+
+	result = nil
+	page.Cursor = events[0].PagingToken()
+	tt.Assert.NoError(q.ContractEvents().ForContract(contractA).Page(page).Select(tt.Ctx, &result))
+	tt.Assert.Equal([]ContractEvent{events[2]}, result)
+
+	result = nil
+	page = db2.PageQuery{Order: "desc", Limit: 1}
+	tt.Assert.NoError(q.ContractEvents().ForContract(contractA).Page(page).Select(tt.Ctx, &result))
+	tt.Assert.Equal([]ContractEvent{events[2]}, result)
+
+	err := q.ContractEvents().ForTopics([]string{"a", "b", "c", "d", "e"}).Select(tt.Ctx, &result)
+	tt.Assert.EqualError(err, "at most 4 topics can be filtered")
+}
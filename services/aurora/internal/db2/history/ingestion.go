@@ -16,6 +16,7 @@ func (q *Q) TruncateIngestStateTables(ctx context.Context) error {
 		"accounts_signers",
 		"claimable_balances",
 		"claimable_balance_claimants",
+		"contract_data",
 		"exp_asset_stats",
 		"liquidity_pools",
 		"offers",
@@ -228,6 +228,19 @@ const (
 	// from SAC events involving transfers, mints, and burns.
 	// https://github.com/hcnet/rs-soroban-env/blob/5695440da452837555d8f7f259cc33341fdf07b0/soroban-env-host/src/native_contract/token/contract.rs#L51-L63
 	EffectContractDebited EffectType = 97
+
+	// EffectContractAllowanceChanged effects occur when the allowance granted
+	// to a spender is increased or decreased through SAC incr_allow and
+	// decr_allow events.
+	EffectContractAllowanceChanged EffectType = 98
+
+	// EffectContractAuthorizationChanged effects occur when the SAC admin
+	// authorizes or deauthorizes an address through set_authorized events.
+	EffectContractAuthorizationChanged EffectType = 99
+
+	// EffectContractAdminChanged effects occur when the admin of a SAC is
+	// replaced through set_admin events.
+	EffectContractAdminChanged EffectType = 100
 )
 
 // Account is a row of data from the `history_accounts` table
@@ -265,6 +278,8 @@ type IngestionQ interface {
 	QAssetStats
 	QClaimableBalances
 	QHistoryClaimableBalances
+	QContractData
+	QContractEvents
 	QData
 	QEffects
 	QLedgers
@@ -278,6 +293,7 @@ type IngestionQ interface {
 	NewTransactionParticipantsBatchInsertBuilder() TransactionParticipantsBatchInsertBuilder
 	NewOperationParticipantBatchInsertBuilder() OperationParticipantBatchInsertBuilder
 	QSigners
+	QStateHistory
 	//QTrades
 	NewTradeBatchInsertBuilder() TradeBatchInsertBuilder
 	RebuildTradeAggregationTimes(ctx context.Context, from, to strtime.Millis, roundingSlippageFilter int) error
@@ -1035,6 +1051,14 @@ func (q Q) ReapLookupTables(ctx context.Context, offsets map[string]int64) (
 				name:        "history_trades_60000",
 				objectField: "counter_asset_id",
 			},
+			{
+				name:        "history_trades_rollups",
+				objectField: "base_asset_id",
+			},
+			{
+				name:        "history_trades_rollups",
+				objectField: "counter_asset_id",
+			},
 		},
 		"history_claimable_balances": {
 			{
@@ -1161,7 +1185,13 @@ func constructReapLookupTablesQuery(table string, historyTables []tableObjectFie
 // DeleteRangeAll deletes a range of rows from all history tables between
 // `start` and `end` (exclusive).
 func (q *Q) DeleteRangeAll(ctx context.Context, start, end int64) error {
+	// The 1 minute trade buckets are deleted separately, as the rollups
+	// containing them need to be rebuilt.
+	if err := q.deleteTradeAggregationsRange(ctx, start, end); err != nil {
+		return errors.Wrapf(err, "Error clearing %s", HistoryTradesTableName)
+	}
 	for table, column := range map[string]string{
+		"history_contract_events":                "history_operation_id",
 		"history_effects":                        "history_operation_id",
 		"history_ledgers":                        "id",
 		"history_operation_claimable_balances":   "history_operation_id",
@@ -1169,7 +1199,6 @@ func (q *Q) DeleteRangeAll(ctx context.Context, start, end int64) error {
 		"history_operation_liquidity_pools":      "history_operation_id",
 		"history_operations":                     "id",
 		"history_trades":                         "history_operation_id",
-		"history_trades_60000":                   "open_ledger_toid",
 		"history_transaction_claimable_balances": "history_transaction_id",
 		"history_transaction_participants":       "history_transaction_id",
 		"history_transaction_liquidity_pools":    "history_transaction_id",
@@ -0,0 +1,21 @@
+package history
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockContractDataBatchInsertBuilder struct {
+	mock.Mock
+}
+
+func (m *MockContractDataBatchInsertBuilder) Add(entry ContractDataEntry) error {
+	a := m.Called(entry)
+	return a.Error(0)
+}
+
+func (m *MockContractDataBatchInsertBuilder) Exec(ctx context.Context) error {
+	a := m.Called(ctx)
+	return a.Error(0)
+}
@@ -0,0 +1,26 @@
+package history
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hcnet/go/support/db"
+)
+
+// MockContractEventBatchInsertBuilder mock ContractEventBatchInsertBuilder
+type MockContractEventBatchInsertBuilder struct {
+	mock.Mock
+}
+
+// Add mock
+func (m *MockContractEventBatchInsertBuilder) Add(event ContractEvent) error {
+	a := m.Called(event)
+	return a.Error(0)
+}
+
+// Exec mock
+func (m *MockContractEventBatchInsertBuilder) Exec(ctx context.Context, session db.SessionInterface) error {
+	a := m.Called(ctx, session)
+	return a.Error(0)
+}
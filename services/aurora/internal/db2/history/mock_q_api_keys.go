@@ -0,0 +1,37 @@
+package history
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQAPIKeys is a mock implementation of the QAPIKeys interface
+type MockQAPIKeys struct {
+	mock.Mock
+}
+
+func (m *MockQAPIKeys) GetAPIKeys(ctx context.Context) ([]APIKey, error) {
+	a := m.Called(ctx)
+	return a.Get(0).([]APIKey), a.Error(1)
+}
+
+func (m *MockQAPIKeys) GetAPIKeyByID(ctx context.Context, id int64) (APIKey, error) {
+	a := m.Called(ctx, id)
+	return a.Get(0).(APIKey), a.Error(1)
+}
+
+func (m *MockQAPIKeys) InsertAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	a := m.Called(ctx, key)
+	return a.Get(0).(APIKey), a.Error(1)
+}
+
+func (m *MockQAPIKeys) UpdateAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	a := m.Called(ctx, key)
+	return a.Get(0).(APIKey), a.Error(1)
+}
+
+func (m *MockQAPIKeys) DeleteAPIKey(ctx context.Context, id int64) error {
+	a := m.Called(ctx, id)
+	return a.Error(0)
+}
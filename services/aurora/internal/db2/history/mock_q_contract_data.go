@@ -0,0 +1,37 @@
+package history
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQContractData is a mock implementation of the QContractData interface
+type MockQContractData struct {
+	mock.Mock
+}
+
+func (m *MockQContractData) CountContractData(ctx context.Context) (int, error) {
+	a := m.Called(ctx)
+	return a.Get(0).(int), a.Error(1)
+}
+
+func (m *MockQContractData) GetContractDataByKeyHashes(ctx context.Context, keyHashes []string) ([]ContractDataEntry, error) {
+	a := m.Called(ctx, keyHashes)
+	return a.Get(0).([]ContractDataEntry), a.Error(1)
+}
+
+func (m *MockQContractData) UpsertContractData(ctx context.Context, entries []ContractDataEntry) error {
+	a := m.Called(ctx, entries)
+	return a.Error(0)
+}
+
+func (m *MockQContractData) RemoveContractData(ctx context.Context, keyHashes []string) (int64, error) {
+	a := m.Called(ctx, keyHashes)
+	return a.Get(0).(int64), a.Error(1)
+}
+
+func (m *MockQContractData) NewContractDataBatchInsertBuilder() ContractDataBatchInsertBuilder {
+	a := m.Called()
+	return a.Get(0).(ContractDataBatchInsertBuilder)
+}
@@ -0,0 +1,54 @@
+package history
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hcnet/go/xdr"
+)
+
+// MockQStateHistory is a mock implementation of the QStateHistory interface
+type MockQStateHistory struct {
+	mock.Mock
+}
+
+func (m *MockQStateHistory) UpsertStateHistoryEntries(ctx context.Context, entries []StateHistoryEntry) error {
+	a := m.Called(ctx, entries)
+	return a.Error(0)
+}
+
+func (m *MockQStateHistory) TruncateStateHistory(ctx context.Context) error {
+	a := m.Called(ctx)
+	return a.Error(0)
+}
+
+func (m *MockQStateHistory) GetStateHistoryRange(ctx context.Context) (uint32, uint32, error) {
+	a := m.Called(ctx)
+	return a.Get(0).(uint32), a.Get(1).(uint32), a.Error(2)
+}
+
+func (m *MockQStateHistory) UpdateStateHistoryRange(ctx context.Context, start, last uint32) error {
+	a := m.Called(ctx, start, last)
+	return a.Error(0)
+}
+
+func (m *MockQStateHistory) GetStateHistoryEntriesByKeys(ctx context.Context, ledgerKeys []string, ledger uint32) ([]StateHistoryEntry, error) {
+	a := m.Called(ctx, ledgerKeys, ledger)
+	return a.Get(0).([]StateHistoryEntry), a.Error(1)
+}
+
+func (m *MockQStateHistory) GetStateHistoryEntriesForAccount(ctx context.Context, account string, entryTypes []xdr.LedgerEntryType, ledger uint32) ([]StateHistoryEntry, error) {
+	a := m.Called(ctx, account, entryTypes, ledger)
+	return a.Get(0).([]StateHistoryEntry), a.Error(1)
+}
+
+func (m *MockQStateHistory) GetStateHistoryOfferByID(ctx context.Context, offerID int64, ledger uint32) ([]StateHistoryEntry, error) {
+	a := m.Called(ctx, offerID, ledger)
+	return a.Get(0).([]StateHistoryEntry), a.Error(1)
+}
+
+func (m *MockQStateHistory) ReapStateHistory(ctx context.Context, cutoff uint32) (int64, error) {
+	a := m.Called(ctx, cutoff)
+	return a.Get(0).(int64), a.Error(1)
+}
@@ -0,0 +1,72 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQWebhooks is a mock implementation of the QWebhooks interface
+type MockQWebhooks struct {
+	mock.Mock
+}
+
+func (m *MockQWebhooks) GetWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	a := m.Called(ctx)
+	return a.Get(0).([]WebhookSubscription), a.Error(1)
+}
+
+func (m *MockQWebhooks) InsertWebhookSubscription(ctx context.Context, subscription WebhookSubscription) (WebhookSubscription, error) {
+	a := m.Called(ctx, subscription)
+	return a.Get(0).(WebhookSubscription), a.Error(1)
+}
+
+func (m *MockQWebhooks) DeleteWebhookSubscription(ctx context.Context, id int64) error {
+	a := m.Called(ctx, id)
+	return a.Error(0)
+}
+
+func (m *MockQWebhooks) InsertWebhookDeliveries(ctx context.Context, deliveries []WebhookDelivery) error {
+	a := m.Called(ctx, deliveries)
+	return a.Error(0)
+}
+
+func (m *MockQWebhooks) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error) {
+	a := m.Called(ctx, now, lease, limit)
+	return a.Get(0).([]WebhookDelivery), a.Error(1)
+}
+
+func (m *MockQWebhooks) DeleteWebhookDelivery(ctx context.Context, id int64) error {
+	a := m.Called(ctx, id)
+	return a.Error(0)
+}
+
+func (m *MockQWebhooks) RescheduleWebhookDelivery(
+	ctx context.Context, id int64, attempts int32, nextAttemptAt time.Time, lastError string,
+) error {
+	a := m.Called(ctx, id, attempts, nextAttemptAt, lastError)
+	return a.Error(0)
+}
+
+func (m *MockQWebhooks) DeadLetterWebhookDelivery(ctx context.Context, delivery WebhookDelivery, url string, now time.Time) error {
+	a := m.Called(ctx, delivery, url, now)
+	return a.Error(0)
+}
+
+func (m *MockQWebhooks) GetWebhookDeadLetters(
+	ctx context.Context, subscriptionID int64, cursor int64, limit uint64,
+) ([]WebhookDeadLetter, error) {
+	a := m.Called(ctx, subscriptionID, cursor, limit)
+	return a.Get(0).([]WebhookDeadLetter), a.Error(1)
+}
+
+func (m *MockQWebhooks) GetLastLedgerWebhooks(ctx context.Context, forUpdate bool) (uint32, error) {
+	a := m.Called(ctx, forUpdate)
+	return a.Get(0).(uint32), a.Error(1)
+}
+
+func (m *MockQWebhooks) UpdateLastLedgerWebhooks(ctx context.Context, sequence uint32) error {
+	a := m.Called(ctx, sequence)
+	return a.Error(0)
+}
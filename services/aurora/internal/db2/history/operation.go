@@ -38,6 +38,20 @@ func (r *Operation) UnmarshalDetails(dest interface{}) error {
 	return nil
 }
 
+// InPaymentsClass returns whether the operation is one of those OnlyPayments
+// filters for.
+func (r *Operation) InPaymentsClass() bool {
+	if r.IsPayment {
+		return true
+	}
+	for _, paymentType := range paymentOperationTypes {
+		if r.Type == paymentType {
+			return true
+		}
+	}
+	return false
+}
+
 func preprocessDetails(details string) ([]byte, error) {
 	var dest map[string]interface{}
 	// Create a decoder using Number instead of float64 when decoding
@@ -241,18 +255,20 @@ func (q *OperationsQ) ForTransaction(ctx context.Context, hash string) *Operatio
 // on the history operations table.
 func (q *OperationsQ) OnlyPayments() *OperationsQ {
 	q.sql = q.sql.Where(sq.Or{
-		sq.Eq{"hop.type": []xdr.OperationType{
-			xdr.OperationTypeCreateAccount,
-			xdr.OperationTypePayment,
-			xdr.OperationTypePathPaymentStrictReceive,
-			xdr.OperationTypePathPaymentStrictSend,
-			xdr.OperationTypeAccountMerge,
-		}},
+		sq.Eq{"hop.type": paymentOperationTypes},
 		sq.Eq{"hop.is_payment": true}})
 
 	return q
 }
 
+var paymentOperationTypes = []xdr.OperationType{
+	xdr.OperationTypeCreateAccount,
+	xdr.OperationTypePayment,
+	xdr.OperationTypePathPaymentStrictReceive,
+	xdr.OperationTypePathPaymentStrictSend,
+	xdr.OperationTypeAccountMerge,
+}
+
 // IncludeFailed changes the query to include failed transactions.
 func (q *OperationsQ) IncludeFailed() *OperationsQ {
 	q.includeFailed = true
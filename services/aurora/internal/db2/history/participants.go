@@ -3,6 +3,8 @@ package history
 import (
 	"context"
 
+	sq "github.com/Masterminds/squirrel"
+
 	"github.com/hcnet/go/support/db"
 )
 
@@ -45,3 +47,42 @@ func (i *transactionParticipantsBatchInsertBuilder) Add(transactionID int64, acc
 func (i *transactionParticipantsBatchInsertBuilder) Exec(ctx context.Context, session db.SessionInterface) error {
 	return i.builder.Exec(ctx, session, i.tableName)
 }
+
+type participantRow struct {
+	ID      int64  `db:"id"`
+	Address string `db:"address"`
+}
+
+// OperationParticipantsByIDs returns the addresses of the participants of the
+// given operations, keyed by operation id.
+func (q *Q) OperationParticipantsByIDs(ctx context.Context, ids ...int64) (map[int64][]string, error) {
+	return q.participantsByIDs(ctx, "history_operation_participants", "history_operation_id", ids)
+}
+
+// TransactionParticipantsByIDs returns the addresses of the participants of
+// the given transactions, keyed by transaction id.
+func (q *Q) TransactionParticipantsByIDs(ctx context.Context, ids ...int64) (map[int64][]string, error) {
+	return q.participantsByIDs(ctx, "history_transaction_participants", "history_transaction_id", ids)
+}
+
+func (q *Q) participantsByIDs(ctx context.Context, table, idColumn string, ids []int64) (map[int64][]string, error) {
+	byID := map[int64][]string{}
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	sql := sq.Select("p."+idColumn+" AS id", "ha.address").
+		From(table + " p").
+		Join("history_accounts ha ON ha.id = p.history_account_id").
+		Where(sq.Eq{"p." + idColumn: ids})
+
+	var rows []participantRow
+	if err := q.Select(ctx, &rows, sql); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		byID[row.ID] = append(byID[row.ID], row.Address)
+	}
+	return byID, nil
+}
@@ -69,3 +69,40 @@ func TestTransactionParticipantsBatch(t *testing.T) {
 	}
 	tt.Assert.ElementsMatch(expected, participants)
 }
+
+func TestParticipantsByIDs(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &Q{tt.AuroraSession()}
+
+	accountLoader := NewAccountLoader()
+	transactions := q.NewTransactionParticipantsBatchInsertBuilder()
+	operations := q.NewOperationParticipantBatchInsertBuilder()
+
+	first := keypair.MustRandom().Address()
+	second := keypair.MustRandom().Address()
+	tt.Assert.NoError(transactions.Add(1, accountLoader.GetFuture(first)))
+	tt.Assert.NoError(transactions.Add(1, accountLoader.GetFuture(second)))
+	tt.Assert.NoError(transactions.Add(2, accountLoader.GetFuture(second)))
+	tt.Assert.NoError(operations.Add(11, accountLoader.GetFuture(first)))
+
+	tt.Assert.NoError(q.Begin(tt.Ctx))
+	tt.Assert.NoError(accountLoader.Exec(tt.Ctx, q))
+	tt.Assert.NoError(transactions.Exec(tt.Ctx, q))
+	tt.Assert.NoError(operations.Exec(tt.Ctx, q))
+	tt.Assert.NoError(q.Commit())
+
+	byTransaction, err := q.TransactionParticipantsByIDs(tt.Ctx, 1, 3)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(byTransaction, 1)
+	tt.Assert.ElementsMatch([]string{first, second}, byTransaction[1])
+
+	byOperation, err := q.OperationParticipantsByIDs(tt.Ctx, 11)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(map[int64][]string{11: {first}}, byOperation)
+
+	none, err := q.OperationParticipantsByIDs(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(none)
+}
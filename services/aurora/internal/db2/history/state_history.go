@@ -0,0 +1,250 @@
+package history
+
+import (
+	"context"
+	"strconv"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+	"github.com/lib/pq"
+
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+const (
+	stateHistoryTableName = "history_state_entries"
+	// stateHistoryStartLedgerKey is the first ledger for which the state
+	// history is complete. 0 means the state history is not available.
+	stateHistoryStartLedgerKey = "state_history_start_ledger"
+	// stateHistoryLastLedgerKey is the last ledger recorded in the state
+	// history.
+	stateHistoryLastLedgerKey = "state_history_last_ledger"
+	// stateHistoryInsertBatchSize is the maximum number of rows inserted by a
+	// single query.
+	stateHistoryInsertBatchSize = 1000
+)
+
+// StateHistoryEntry is a row of data from the `history_state_entries` table.
+// Each row is a version of a ledger entry which was created or updated in
+// LedgerSequence. LedgerEntry is not valid when the entry was removed in
+// LedgerSequence.
+type StateHistoryEntry struct {
+	LedgerKey      string              `db:"ledger_key"`
+	LedgerSequence uint32              `db:"ledger_sequence"`
+	EntryType      xdr.LedgerEntryType `db:"entry_type"`
+	Accounts       pq.StringArray      `db:"accounts"`
+	OfferID        null.Int            `db:"offer_id"`
+	LedgerEntry    string              `db:"ledger_entry"`
+	Removed        bool                `db:"removed"`
+}
+
+// QStateHistory defines state history related queries.
+type QStateHistory interface {
+	UpsertStateHistoryEntries(ctx context.Context, entries []StateHistoryEntry) error
+	TruncateStateHistory(ctx context.Context) error
+	GetStateHistoryRange(ctx context.Context) (uint32, uint32, error)
+	UpdateStateHistoryRange(ctx context.Context, start, last uint32) error
+	GetStateHistoryEntriesByKeys(ctx context.Context, ledgerKeys []string, ledger uint32) ([]StateHistoryEntry, error)
+	GetStateHistoryEntriesForAccount(ctx context.Context, account string, entryTypes []xdr.LedgerEntryType, ledger uint32) ([]StateHistoryEntry, error)
+	GetStateHistoryOfferByID(ctx context.Context, offerID int64, ledger uint32) ([]StateHistoryEntry, error)
+	ReapStateHistory(ctx context.Context, cutoff uint32) (int64, error)
+}
+
+// UpsertStateHistoryEntries inserts a batch of entry versions. A version
+// already recorded for the same key and ledger is replaced, which happens when
+// a ledger is ingested again.
+func (q *Q) UpsertStateHistoryEntries(ctx context.Context, entries []StateHistoryEntry) error {
+	for len(entries) > 0 {
+		batch := entries
+		if len(batch) > stateHistoryInsertBatchSize {
+			batch = batch[:stateHistoryInsertBatchSize]
+		}
+		entries = entries[len(batch):]
+
+		sql := sq.Insert(stateHistoryTableName).
+			Columns("ledger_key", "ledger_sequence", "entry_type", "accounts", "offer_id", "ledger_entry")
+		for _, entry := range batch {
+			var ledgerEntry interface{}
+			if !entry.Removed {
+				ledgerEntry = entry.LedgerEntry
+			}
+			accounts := entry.Accounts
+			if accounts == nil {
+				accounts = pq.StringArray{}
+			}
+			sql = sql.Values(entry.LedgerKey, entry.LedgerSequence, int32(entry.EntryType), accounts, entry.OfferID, ledgerEntry)
+		}
+		sql = sql.Suffix(`ON CONFLICT (ledger_key, ledger_sequence) DO UPDATE SET
+			entry_type = excluded.entry_type,
+			accounts = excluded.accounts,
+			offer_id = excluded.offer_id,
+			ledger_entry = excluded.ledger_entry`)
+
+		_, err := q.Exec(context.WithValue(ctx, &db.QueryTypeContextKey, db.UpsertQueryType), sql)
+		if err != nil {
+			return errors.Wrap(err, "could not upsert state history entries")
+		}
+	}
+	return nil
+}
+
+// TruncateStateHistory removes all the entry versions and marks the state
+// history as not available.
+func (q *Q) TruncateStateHistory(ctx context.Context) error {
+	if _, err := q.ExecRaw(ctx, "TRUNCATE TABLE "+stateHistoryTableName); err != nil {
+		return errors.Wrap(err, "could not truncate state history")
+	}
+	return q.UpdateStateHistoryRange(ctx, 0, 0)
+}
+
+// GetStateHistoryRange returns the first and the last ledger for which the
+// state history is complete. The start ledger is 0 when the state history is
+// not available.
+func (q *Q) GetStateHistoryRange(ctx context.Context) (uint32, uint32, error) {
+	start, err := q.getIntValueFromStore(ctx, stateHistoryStartLedgerKey, 32)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "could not get state history start ledger")
+	}
+	last, err := q.getIntValueFromStore(ctx, stateHistoryLastLedgerKey, 32)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "could not get state history last ledger")
+	}
+	return uint32(start), uint32(last), nil
+}
+
+// UpdateStateHistoryRange sets the range of ledgers for which the state
+// history is complete.
+func (q *Q) UpdateStateHistoryRange(ctx context.Context, start, last uint32) error {
+	err := q.updateValueInStore(ctx, stateHistoryStartLedgerKey, strconv.FormatUint(uint64(start), 10))
+	if err != nil {
+		return errors.Wrap(err, "could not update state history start ledger")
+	}
+	err = q.updateValueInStore(ctx, stateHistoryLastLedgerKey, strconv.FormatUint(uint64(last), 10))
+	if err != nil {
+		return errors.Wrap(err, "could not update state history last ledger")
+	}
+	return nil
+}
+
+// latestStateHistoryEntries selects the latest version, up to the given
+// ledger, of each entry matching the keys filter. Entries which were removed
+// at that ledger are skipped.
+func (q *Q) latestStateHistoryEntries(ctx context.Context, keys sq.Sqlizer, ledger uint32, extra sq.Sqlizer) ([]StateHistoryEntry, error) {
+	latest := sq.Select(
+		"DISTINCT ON (ledger_key) ledger_key",
+		"ledger_sequence",
+		"entry_type",
+		"accounts",
+		"offer_id",
+		"COALESCE(ledger_entry, '') AS ledger_entry",
+		"ledger_entry IS NULL AS removed",
+	).
+		From(stateHistoryTableName).
+		Where(keys).
+		Where(sq.LtOrEq{"ledger_sequence": ledger}).
+		OrderBy("ledger_key", "ledger_sequence DESC")
+
+	sql := sq.Select("*").
+		FromSelect(latest, "latest").
+		Where("NOT latest.removed").
+		OrderBy("latest.ledger_key")
+	if extra != nil {
+		sql = sql.Where(extra)
+	}
+
+	var entries []StateHistoryEntry
+	if err := q.Select(ctx, &entries, sql); err != nil {
+		return nil, errors.Wrap(err, "could not select state history entries")
+	}
+	return entries, nil
+}
+
+// GetStateHistoryEntriesByKeys returns the entries with the given keys as they
+// were at the end of the given ledger.
+func (q *Q) GetStateHistoryEntriesByKeys(ctx context.Context, ledgerKeys []string, ledger uint32) ([]StateHistoryEntry, error) {
+	return q.latestStateHistoryEntries(ctx, sq.Expr("ledger_key = ANY(?)", pq.Array(ledgerKeys)), ledger, nil)
+}
+
+// GetStateHistoryEntriesForAccount returns the entries of the given types which
+// involved the account at the end of the given ledger.
+func (q *Q) GetStateHistoryEntriesForAccount(
+	ctx context.Context,
+	account string,
+	entryTypes []xdr.LedgerEntryType,
+	ledger uint32,
+) ([]StateHistoryEntry, error) {
+	types := make([]int32, len(entryTypes))
+	for i, entryType := range entryTypes {
+		types[i] = int32(entryType)
+	}
+	accounts := pq.Array([]string{account})
+
+	// the accounts involved in an entry can change between versions (ex. the
+	// sponsor of a claimable balance) so the latest version must be selected
+	// among all the versions of the keys before filtering it by account.
+	keys := sq.Expr(
+		"ledger_key IN (SELECT ledger_key FROM "+stateHistoryTableName+" WHERE accounts @> ? AND entry_type = ANY(?))",
+		accounts, pq.Array(types),
+	)
+	return q.latestStateHistoryEntries(ctx, keys, ledger, sq.Expr("latest.accounts @> ?", accounts))
+}
+
+// GetStateHistoryOfferByID returns the offer with the given id as it was at the
+// end of the given ledger. The result is empty if the offer didn't exist.
+func (q *Q) GetStateHistoryOfferByID(ctx context.Context, offerID int64, ledger uint32) ([]StateHistoryEntry, error) {
+	keys := sq.Expr("ledger_key IN (SELECT ledger_key FROM "+stateHistoryTableName+" WHERE offer_id = ?)", offerID)
+	return q.latestStateHistoryEntries(ctx, keys, ledger, nil)
+}
+
+// ReapStateHistory removes the entry versions which are not needed to answer
+// queries for ledgers from cutoff onwards and moves the start of the state
+// history to cutoff. It returns the number of removed rows.
+func (q *Q) ReapStateHistory(ctx context.Context, cutoff uint32) (int64, error) {
+	start, last, err := q.GetStateHistoryRange(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if start == 0 || cutoff <= start {
+		return 0, nil
+	}
+	if cutoff > last {
+		cutoff = last
+	}
+
+	// versions superseded by a newer version at or before the cutoff
+	result, err := q.ExecRaw(ctx, `DELETE FROM `+stateHistoryTableName+` h
+		WHERE h.ledger_sequence < ? AND EXISTS (
+			SELECT 1 FROM `+stateHistoryTableName+` n
+			WHERE n.ledger_key = h.ledger_key
+			AND n.ledger_sequence > h.ledger_sequence
+			AND n.ledger_sequence <= ?
+		)`, cutoff, cutoff)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not remove superseded state history entries")
+	}
+	superseded, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	// removals which are now the only version of the entry before the cutoff
+	result, err = q.ExecRaw(ctx,
+		`DELETE FROM `+stateHistoryTableName+` WHERE ledger_sequence <= ? AND ledger_entry IS NULL`,
+		cutoff,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not remove state history removals")
+	}
+	removals, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	err = q.updateValueInStore(ctx, stateHistoryStartLedgerKey, strconv.FormatUint(uint64(cutoff), 10))
+	if err != nil {
+		return 0, errors.Wrap(err, "could not update state history start ledger")
+	}
+	return superseded + removals, nil
+}
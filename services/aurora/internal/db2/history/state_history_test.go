@@ -0,0 +1,127 @@
+package history
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/lib/pq"
+
+	"github.com/hcnet/go/services/aurora/internal/test"
+	"github.com/hcnet/go/xdr"
+)
+
+func TestStateHistoryQueries(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &Q{tt.AuroraSession()}
+	tt.Assert.NoError(q.BeginTx(tt.Ctx, &sql.TxOptions{}))
+	defer func() {
+		_ = q.Rollback()
+	}()
+
+	seller := "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"
+	sponsor := "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"
+
+	start, last, err := q.GetStateHistoryRange(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(0), start)
+	tt.Assert.Equal(uint32(0), last)
+
+	tt.Assert.NoError(q.UpsertStateHistoryEntries(tt.Ctx, []StateHistoryEntry{
+		{
+			LedgerKey:      "offer",
+			LedgerSequence: 10,
+			EntryType:      xdr.LedgerEntryTypeOffer,
+			Accounts:       pq.StringArray{seller},
+			OfferID:        null.IntFrom(7),
+			LedgerEntry:    "v10",
+		},
+		{
+			LedgerKey:      "balance",
+			LedgerSequence: 10,
+			EntryType:      xdr.LedgerEntryTypeClaimableBalance,
+			Accounts:       pq.StringArray{seller, sponsor},
+			LedgerEntry:    "b10",
+		},
+	}))
+	tt.Assert.NoError(q.UpsertStateHistoryEntries(tt.Ctx, []StateHistoryEntry{
+		{
+			LedgerKey:      "offer",
+			LedgerSequence: 11,
+			EntryType:      xdr.LedgerEntryTypeOffer,
+			Accounts:       pq.StringArray{seller},
+			OfferID:        null.IntFrom(7),
+			LedgerEntry:    "v11",
+		},
+		// the sponsorship of the balance was revoked
+		{
+			LedgerKey:      "balance",
+			LedgerSequence: 11,
+			EntryType:      xdr.LedgerEntryTypeClaimableBalance,
+			Accounts:       pq.StringArray{seller},
+			LedgerEntry:    "b11",
+		},
+	}))
+	tt.Assert.NoError(q.UpsertStateHistoryEntries(tt.Ctx, []StateHistoryEntry{
+		{
+			LedgerKey:      "offer",
+			LedgerSequence: 12,
+			EntryType:      xdr.LedgerEntryTypeOffer,
+			Accounts:       pq.StringArray{seller},
+			OfferID:        null.IntFrom(7),
+			Removed:        true,
+		},
+	}))
+	tt.Assert.NoError(q.UpdateStateHistoryRange(tt.Ctx, 10, 12))
+
+	entries, err := q.GetStateHistoryEntriesByKeys(tt.Ctx, []string{"offer"}, 11)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(entries, 1)
+	tt.Assert.Equal("v11", entries[0].LedgerEntry)
+	tt.Assert.Equal(uint32(11), entries[0].LedgerSequence)
+
+	entries, err = q.GetStateHistoryOfferByID(tt.Ctx, 7, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(entries, 1)
+	tt.Assert.Equal("v10", entries[0].LedgerEntry)
+
+	entries, err = q.GetStateHistoryOfferByID(tt.Ctx, 7, 12)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(entries)
+
+	entries, err = q.GetStateHistoryEntriesForAccount(tt.Ctx, sponsor, []xdr.LedgerEntryType{xdr.LedgerEntryTypeClaimableBalance}, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(entries, 1)
+	tt.Assert.Equal("b10", entries[0].LedgerEntry)
+
+	entries, err = q.GetStateHistoryEntriesForAccount(tt.Ctx, sponsor, []xdr.LedgerEntryType{xdr.LedgerEntryTypeClaimableBalance}, 11)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(entries)
+
+	entries, err = q.GetStateHistoryEntriesForAccount(tt.Ctx, seller, []xdr.LedgerEntryType{xdr.LedgerEntryTypeOffer}, 11)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(entries, 1)
+	tt.Assert.Equal("offer", entries[0].LedgerKey)
+
+	removed, err := q.ReapStateHistory(tt.Ctx, 12)
+	tt.Assert.NoError(err)
+	// all the versions of the removed offer and the first version of the balance
+	tt.Assert.Equal(int64(4), removed)
+
+	start, last, err = q.GetStateHistoryRange(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(12), start)
+	tt.Assert.Equal(uint32(12), last)
+
+	entries, err = q.GetStateHistoryEntriesForAccount(tt.Ctx, seller, []xdr.LedgerEntryType{xdr.LedgerEntryTypeClaimableBalance}, 12)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(entries, 1)
+	tt.Assert.Equal("b11", entries[0].LedgerEntry)
+
+	tt.Assert.NoError(q.TruncateStateHistory(tt.Ctx))
+	start, _, err = q.GetStateHistoryRange(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(0), start)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
 
 	"github.com/hcnet/go/services/aurora/internal/db2"
 	"github.com/hcnet/go/support/errors"
@@ -43,10 +44,28 @@ type TradeAggregation struct {
 	OpenD         int64   `db:"open_d"`
 	CloseN        int64   `db:"close_n"`
 	CloseD        int64   `db:"close_d"`
+
+	LiquidityPoolTradeCount    int64  `db:"pool_count"`
+	LiquidityPoolBaseVolume    string `db:"pool_base_volume"`
+	LiquidityPoolCounterVolume string `db:"pool_counter_volume"`
 }
 
 const HistoryTradesTableName = "history_trades_60000"
 
+// HistoryTradesRollupsTableName is the table containing the 1 minute buckets
+// of HistoryTradesTableName rolled up to the coarser rollupResolutions.
+const HistoryTradesRollupsTableName = "history_trades_rollups"
+
+// rollupResolutions are the resolutions, in milliseconds, maintained in
+// HistoryTradesRollupsTableName: every AllowedResolutions except 1 minute.
+var rollupResolutions = []int64{
+	300_000,     //5 minutes
+	900_000,     //15 minutes
+	3_600_000,   //1 hour
+	86_400_000,  //day
+	604_800_000, //week
+}
+
 // TradeAggregationsQ is a helper struct to aid in configuring queries to
 // bucket and aggregate trades
 type TradeAggregationsQ struct {
@@ -125,6 +144,30 @@ func (q *TradeAggregationsQ) WithEndTime(endTime strtime.Millis) (*TradeAggregat
 	}
 }
 
+// sourceResolution returns the resolution of the precomputed buckets the
+// aggregation is computed from: the coarsest buckets which fit evenly in the
+// requested buckets, falling back to the 1 minute buckets.
+func (q *TradeAggregationsQ) sourceResolution() int64 {
+	source := int64(60_000)
+	for _, resolution := range rollupResolutions {
+		if q.resolution%resolution == 0 && q.offset%resolution == 0 && resolution > source {
+			source = resolution
+		}
+	}
+	return source
+}
+
+// fromTradeBuckets selects the precomputed buckets of the given resolution.
+func fromTradeBuckets(s sq.SelectBuilder, resolution int64, alias string) sq.SelectBuilder {
+	if alias != "" {
+		alias = " AS " + alias
+	}
+	if resolution == 60_000 {
+		return s.From(HistoryTradesTableName + alias)
+	}
+	return s.From(HistoryTradesRollupsTableName + alias).Where(sq.Eq{"resolution": resolution})
+}
+
 func (q *TradeAggregationsQ) getRawTradesSql(orderPreserved bool) sq.SelectBuilder {
 	var rawTradesSQL sq.SelectBuilder
 	if orderPreserved {
@@ -133,9 +176,8 @@ func (q *TradeAggregationsQ) getRawTradesSql(orderPreserved bool) sq.SelectBuild
 		rawTradesSQL = reverseBucketTrades(q.resolution, q.offset)
 	}
 
-	rawTradesSQL = rawTradesSQL.
-		Join("timestamp_range r ON 1=1").
-		From(fmt.Sprintf("%s AS tr", HistoryTradesTableName)).
+	source := q.sourceResolution()
+	rawTradesSQL = fromTradeBuckets(rawTradesSQL.Join("timestamp_range r ON 1=1"), source, "tr").
 		Where(sq.Eq{"base_asset_id": q.baseAssetID, "counter_asset_id": q.counterAssetID})
 
 	//adjust time range and apply time filters
@@ -144,7 +186,7 @@ func (q *TradeAggregationsQ) getRawTradesSql(orderPreserved bool) sq.SelectBuild
 		Where(fmt.Sprintf("r.max_ts >= %s", bucketTs)).
 		Where(fmt.Sprintf("r.min_ts <= %s", bucketTs))
 
-	if q.resolution != 60000 {
+	if q.resolution != source {
 		//ensure open/close order for cases when multiple trades occur in the same ledger
 		rawTradesSQL = rawTradesSQL.OrderBy("timestamp ASC", "open_ledger_toid ASC")
 		// Do on-the-fly aggregation for higher resolutions.
@@ -162,7 +204,7 @@ func (q *TradeAggregationsQ) GetSql() sq.SelectBuilder {
 		OrderBy("timestamp "+q.pagingParams.Order).
 		Prefix("WITH last_range_ts AS (?),",
 			lastRangeTs(
-				q.baseAssetID, q.counterAssetID, q.resolution, q.offset, q.startTime, q.endTime,
+				q.baseAssetID, q.counterAssetID, q.resolution, q.offset, q.sourceResolution(), q.startTime, q.endTime,
 				q.pagingParams.Order, q.pagingParams.Limit)).
 		Prefix("timestamp_range AS (?),",
 			timestampRange()).
@@ -187,12 +229,10 @@ func formatBucketTimestampSelect(resolution int64, offset int64, tsPrefix string
 	return fmt.Sprintf("%s AS timestamp", formatBucketTimestamp(resolution, offset, tsPrefix))
 }
 
-func lastRangeTs(baseAssetID, counterAssetID, resolution, offset int64, startTime, endTime strtime.Millis, order string, limit uint64) sq.SelectBuilder {
-	s := sq.Select(
+func lastRangeTs(baseAssetID, counterAssetID, resolution, offset, source int64, startTime, endTime strtime.Millis, order string, limit uint64) sq.SelectBuilder {
+	s := fromTradeBuckets(sq.Select(
 		formatBucketTimestampSelect(resolution, offset, ""),
-	).From(
-		HistoryTradesTableName,
-	).Where(
+	), source, "").Where(
 		sq.Eq{"base_asset_id": baseAssetID, "counter_asset_id": counterAssetID},
 	).Where(sq.GtOrEq{"timestamp": startTime})
 	if !endTime.IsNil() {
@@ -231,6 +271,9 @@ func bucketTrades(resolution int64, offset int64) sq.SelectBuilder {
 		"open_d",
 		"close_n",
 		"close_d",
+		"pool_count",
+		"pool_base_volume",
+		"pool_counter_volume",
 	)
 }
 
@@ -251,6 +294,9 @@ func reverseBucketTrades(resolution int64, offset int64) sq.SelectBuilder {
 		"open_d as open_n",
 		"close_n as close_d",
 		"close_d as close_n",
+		"pool_count",
+		"pool_base_volume as pool_counter_volume",
+		"pool_counter_volume as pool_base_volume",
 	)
 }
 
@@ -269,6 +315,9 @@ func aggregate(rawTradesTable string) sq.SelectBuilder {
 		"(first(ARRAY[open_n, open_d]))[2] as open_d",
 		"(last(ARRAY[close_n, close_d]))[1] as close_n",
 		"(last(ARRAY[close_n, close_d]))[2] as close_d",
+		"sum(pool_count) as pool_count",
+		"sum(pool_base_volume) as pool_base_volume",
+		"sum(pool_counter_volume) as pool_counter_volume",
 	).From(rawTradesTable).GroupBy("timestamp")
 }
 
@@ -278,8 +327,24 @@ func aggregate(rawTradesTable string) sq.SelectBuilder {
 func (q Q) RebuildTradeAggregationTimes(ctx context.Context, from, to strtime.Millis, roundingSlippageFilter int) error {
 	from = from.RoundDown(60_000)
 	to = to.RoundDown(60_000)
+
+	// Find the asset pairs whose rollups need to be rebuilt, before and after
+	// the rebuild.
+	var pairs []tradeAssetPair
+	err := q.Select(ctx, &pairs, sq.Select("base_asset_id", "counter_asset_id").
+		From(HistoryTradesTableName).
+		Where(sq.GtOrEq{"timestamp": from}).
+		Where(sq.LtOrEq{"timestamp": to}).
+		Suffix("UNION ?", sq.Select("base_asset_id", "counter_asset_id").
+			From("history_trades").
+			Where(sq.GtOrEq{"to_millis(ledger_closed_at, 60000)": from}).
+			Where(sq.LtOrEq{"to_millis(ledger_closed_at, 60000)": to})))
+	if err != nil {
+		return errors.Wrap(err, "could not rebuild trade aggregation bucket")
+	}
+
 	// Clear out the old bucket values.
-	_, err := q.Exec(ctx, sq.Delete(HistoryTradesTableName).Where(
+	_, err = q.Exec(ctx, sq.Delete(HistoryTradesTableName).Where(
 		sq.GtOrEq{"timestamp": from},
 	).Where(
 		sq.LtOrEq{"timestamp": to},
@@ -298,6 +363,7 @@ func (q Q) RebuildTradeAggregationTimes(ctx context.Context, from, to strtime.Mi
 		"counter_asset_id",
 		"counter_amount",
 		"ARRAY[price_n, price_d] as price",
+		"trade_type",
 	).From("history_trades").Where(
 		// db rounding is stored as bips. so 0.95% = 95
 		sq.Lt{"coalesce(rounding_slippage, 0)": roundingSlippageFilter},
@@ -326,6 +392,9 @@ func (q Q) RebuildTradeAggregationTimes(ctx context.Context, from, to strtime.Mi
 		"last(history_operation_id) as close_ledger_toid",
 		"(last(price))[1] as close_n",
 		"(last(price))[2] as close_d",
+		fmt.Sprintf("count(*) FILTER (WHERE trade_type = %d) as pool_count", LiquidityPoolTradeType),
+		fmt.Sprintf("coalesce(sum(base_amount) FILTER (WHERE trade_type = %d), 0) as pool_base_volume", LiquidityPoolTradeType),
+		fmt.Sprintf("coalesce(sum(counter_amount) FILTER (WHERE trade_type = %d), 0) as pool_counter_volume", LiquidityPoolTradeType),
 	).FromSelect(trades, "trades").GroupBy("base_asset_id", "counter_asset_id", "timestamp")
 
 	// Insert the new bucket values.
@@ -333,9 +402,120 @@ func (q Q) RebuildTradeAggregationTimes(ctx context.Context, from, to strtime.Mi
 	if err != nil {
 		return errors.Wrap(err, "could not rebuild trade aggregation bucket")
 	}
+	return q.rebuildTradeRollups(ctx, pairs, from, to)
+}
+
+type tradeAssetPair struct {
+	BaseAssetID    int64 `db:"base_asset_id"`
+	CounterAssetID int64 `db:"counter_asset_id"`
+}
+
+// rebuildTradeRollups rebuilds the rollups of the given asset pairs
+// containing the 1 minute buckets between from and to (inclusive). Each
+// rollup is built from the next finer one, so a week is summed up from 7 days
+// rather than from the 10080 minutes in it.
+func (q Q) rebuildTradeRollups(ctx context.Context, pairs []tradeAssetPair, from, to strtime.Millis) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+	baseAssetIDs := make([]int64, 0, len(pairs))
+	counterAssetIDs := make([]int64, 0, len(pairs))
+	for _, pair := range pairs {
+		baseAssetIDs = append(baseAssetIDs, pair.BaseAssetID)
+		counterAssetIDs = append(counterAssetIDs, pair.CounterAssetID)
+	}
+	inPairs := sq.Expr(
+		"(base_asset_id, counter_asset_id) IN (SELECT unnest(?::bigint[]), unnest(?::bigint[]))",
+		pq.Array(baseAssetIDs), pq.Array(counterAssetIDs),
+	)
+
+	// rollupResolutions are sorted and each of them is a multiple of the
+	// previous one.
+	source := int64(60_000)
+	for _, resolution := range rollupResolutions {
+		start := from.RoundDown(resolution)
+		end := to.RoundDown(resolution) + strtime.MillisFromInt64(resolution)
+
+		_, err := q.Exec(ctx, sq.Delete(HistoryTradesRollupsTableName).
+			Where(sq.Eq{"resolution": resolution}).
+			Where(inPairs).
+			Where(sq.GtOrEq{"timestamp": start}).
+			Where(sq.Lt{"timestamp": end}))
+		if err != nil {
+			return errors.Wrapf(err, "could not rebuild trade aggregation rollup %d", resolution)
+		}
+
+		rebuilt := sq.Select(
+			fmt.Sprintf("%d as resolution", resolution),
+			fmt.Sprintf("(timestamp / %d) * %d as timestamp", resolution, resolution),
+			"base_asset_id",
+			"counter_asset_id",
+			"sum(count) as count",
+			"sum(base_volume) as base_volume",
+			"sum(counter_volume) as counter_volume",
+			"sum(counter_volume)/sum(base_volume) as avg",
+			"(max_price(ARRAY[high_n, high_d]))[1] as high_n",
+			"(max_price(ARRAY[high_n, high_d]))[2] as high_d",
+			"(min_price(ARRAY[low_n, low_d]))[1] as low_n",
+			"(min_price(ARRAY[low_n, low_d]))[2] as low_d",
+			"min(open_ledger_toid) as open_ledger_toid",
+			"(first(ARRAY[open_n, open_d] ORDER BY timestamp))[1] as open_n",
+			"(first(ARRAY[open_n, open_d] ORDER BY timestamp))[2] as open_d",
+			"max(close_ledger_toid) as close_ledger_toid",
+			"(last(ARRAY[close_n, close_d] ORDER BY timestamp))[1] as close_n",
+			"(last(ARRAY[close_n, close_d] ORDER BY timestamp))[2] as close_d",
+			"sum(pool_count) as pool_count",
+			"sum(pool_base_volume) as pool_base_volume",
+			"sum(pool_counter_volume) as pool_counter_volume",
+		)
+		rebuilt = fromTradeBuckets(rebuilt, source, "").
+			Where(inPairs).
+			Where(sq.GtOrEq{"timestamp": start}).
+			Where(sq.Lt{"timestamp": end}).
+			GroupBy("base_asset_id", "counter_asset_id", "2")
+
+		_, err = q.Exec(ctx, sq.Insert(HistoryTradesRollupsTableName).Select(rebuilt))
+		if err != nil {
+			return errors.Wrapf(err, "could not rebuild trade aggregation rollup %d", resolution)
+		}
+		source = resolution
+	}
 	return nil
 }
 
+// deleteTradeAggregationsRange deletes the 1 minute buckets opened between
+// start and end (exclusive) and rebuilds the rollups containing them.
+func (q *Q) deleteTradeAggregationsRange(ctx context.Context, start, end int64) error {
+	var pairs []tradeAssetPair
+	err := q.Select(ctx, &pairs, sq.Select("DISTINCT base_asset_id", "counter_asset_id").
+		From(HistoryTradesTableName).
+		Where(sq.GtOrEq{"open_ledger_toid": start}).
+		Where(sq.Lt{"open_ledger_toid": end}))
+	if err != nil {
+		return err
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	var span struct {
+		From strtime.Millis `db:"min"`
+		To   strtime.Millis `db:"max"`
+	}
+	err = q.Get(ctx, &span, sq.Select("min(timestamp)", "max(timestamp)").
+		From(HistoryTradesTableName).
+		Where(sq.GtOrEq{"open_ledger_toid": start}).
+		Where(sq.Lt{"open_ledger_toid": end}))
+	if err != nil {
+		return err
+	}
+
+	if err = q.DeleteRange(ctx, start, end, HistoryTradesTableName, "open_ledger_toid"); err != nil {
+		return err
+	}
+	return q.rebuildTradeRollups(ctx, pairs, span.From, span.To)
+}
+
 // RebuildTradeAggregationBuckets rebuilds a specific set of trade aggregation
 // buckets, (specified by start and end ledger seq) to ensure complete data in
 // case of partial reingestion.
@@ -0,0 +1,40 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/services/aurora/internal/db2"
+)
+
+func TestTradeAggregationsSourceResolution(t *testing.T) {
+	for _, testCase := range []struct {
+		resolution int64
+		offset     int64
+		source     int64
+	}{
+		{60_000, 0, 60_000},
+		{300_000, 0, 300_000},
+		{3_600_000, 0, 3_600_000},
+		{86_400_000, 0, 86_400_000},
+		{86_400_000, 3_600_000, 3_600_000},
+		{604_800_000, 0, 604_800_000},
+		{604_800_000, 7_200_000, 3_600_000},
+	} {
+		q, err := Q{}.GetTradeAggregationsQ(1, 2, testCase.resolution, testCase.offset, db2.PageQuery{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, testCase.source, q.sourceResolution(), "resolution %d offset %d", testCase.resolution, testCase.offset)
+
+		sql, _, err := q.GetSql().ToSql()
+		require.NoError(t, err)
+		if testCase.source == 60_000 {
+			assert.Contains(t, sql, "FROM "+HistoryTradesTableName)
+			assert.NotContains(t, sql, HistoryTradesRollupsTableName)
+		} else {
+			assert.Contains(t, sql, "FROM "+HistoryTradesRollupsTableName)
+			assert.NotContains(t, sql, HistoryTradesTableName)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+const (
+	webhookSubscriptionsTableName = "webhook_subscriptions"
+	webhookDeliveriesTableName    = "webhook_deliveries"
+	webhookDeadLettersTableName   = "webhook_dead_letters"
+	// webhooksLastLedgerKey is the last ledger for which webhook deliveries
+	// have been enqueued.
+	webhooksLastLedgerKey = "webhooks_last_ledger"
+)
+
+// WebhookSubscription is a row of data from the `webhook_subscriptions` table.
+// Exactly one of AccountID, Asset and LiquidityPoolID is set.
+type WebhookSubscription struct {
+	ID              int64          `db:"id"`
+	URL             string         `db:"url"`
+	Secret          string         `db:"secret"`
+	AccountID       sql.NullString `db:"account_id"`
+	Asset           sql.NullString `db:"asset"`
+	LiquidityPoolID sql.NullString `db:"liquidity_pool_id"`
+	CreatedAt       time.Time      `db:"created_at"`
+}
+
+// WebhookDelivery is a row of data from the `webhook_deliveries` table.
+type WebhookDelivery struct {
+	ID             int64     `db:"id"`
+	SubscriptionID int64     `db:"subscription_id"`
+	LedgerSequence uint32    `db:"ledger_sequence"`
+	Payload        string    `db:"payload"`
+	Attempts       int32     `db:"attempts"`
+	NextAttemptAt  time.Time `db:"next_attempt_at"`
+	LastError      string    `db:"last_error"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// WebhookDeadLetter is a row of data from the `webhook_dead_letters` table.
+// It holds a delivery which could not be completed after the maximum number of
+// attempts.
+type WebhookDeadLetter struct {
+	ID             int64     `db:"id"`
+	SubscriptionID int64     `db:"subscription_id"`
+	URL            string    `db:"url"`
+	LedgerSequence uint32    `db:"ledger_sequence"`
+	Payload        string    `db:"payload"`
+	Attempts       int32     `db:"attempts"`
+	LastError      string    `db:"last_error"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// QWebhooks defines webhook related queries.
+type QWebhooks interface {
+	GetWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	InsertWebhookSubscription(ctx context.Context, subscription WebhookSubscription) (WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id int64) error
+	InsertWebhookDeliveries(ctx context.Context, deliveries []WebhookDelivery) error
+	ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error)
+	DeleteWebhookDelivery(ctx context.Context, id int64) error
+	RescheduleWebhookDelivery(ctx context.Context, id int64, attempts int32, nextAttemptAt time.Time, lastError string) error
+	DeadLetterWebhookDelivery(ctx context.Context, delivery WebhookDelivery, url string, now time.Time) error
+	GetWebhookDeadLetters(ctx context.Context, subscriptionID int64, cursor int64, limit uint64) ([]WebhookDeadLetter, error)
+	GetLastLedgerWebhooks(ctx context.Context, forUpdate bool) (uint32, error)
+	UpdateLastLedgerWebhooks(ctx context.Context, sequence uint32) error
+}
+
+// GetWebhookSubscriptions returns all the webhook subscriptions ordered by id.
+func (q *Q) GetWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	var subscriptions []WebhookSubscription
+	sql := sq.Select("*").From(webhookSubscriptionsTableName).OrderBy("id asc")
+	err := q.Select(ctx, &subscriptions, sql)
+	return subscriptions, err
+}
+
+// InsertWebhookSubscription stores a new webhook subscription and returns it
+// with its id and creation time populated.
+func (q *Q) InsertWebhookSubscription(ctx context.Context, subscription WebhookSubscription) (WebhookSubscription, error) {
+	sql := sq.Insert(webhookSubscriptionsTableName).
+		SetMap(map[string]interface{}{
+			"url":               subscription.URL,
+			"secret":            subscription.Secret,
+			"account_id":        subscription.AccountID,
+			"asset":             subscription.Asset,
+			"liquidity_pool_id": subscription.LiquidityPoolID,
+			"created_at":        sq.Expr("now() at time zone 'utc'"),
+		}).
+		Suffix("RETURNING *")
+
+	var inserted WebhookSubscription
+	err := q.Get(ctx, &inserted, sql)
+	return inserted, err
+}
+
+// DeleteWebhookSubscription removes a webhook subscription together with its
+// pending deliveries. sql.ErrNoRows is returned if the subscription does not
+// exist.
+func (q *Q) DeleteWebhookSubscription(ctx context.Context, id int64) error {
+	rowCnt, err := q.checkForError(
+		sq.Delete(webhookSubscriptionsTableName).Where("id = ?", id),
+		ctx,
+	)
+	if err != nil {
+		return err
+	}
+
+	if rowCnt < 1 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// InsertWebhookDeliveries enqueues the given deliveries.
+func (q *Q) InsertWebhookDeliveries(ctx context.Context, deliveries []WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	sql := sq.Insert(webhookDeliveriesTableName).
+		Columns("subscription_id", "ledger_sequence", "payload", "attempts", "next_attempt_at", "last_error", "created_at")
+	for _, delivery := range deliveries {
+		sql = sql.Values(
+			delivery.SubscriptionID,
+			delivery.LedgerSequence,
+			delivery.Payload,
+			delivery.Attempts,
+			delivery.NextAttemptAt,
+			delivery.LastError,
+			delivery.CreatedAt,
+		)
+	}
+
+	_, err := q.Exec(ctx, sql)
+	return err
+}
+
+// ClaimWebhookDeliveries returns up to limit deliveries which are due at now
+// and postpones their next attempt by lease, so that they are not claimed again
+// while they are being delivered. Rows locked by other sessions are skipped
+// which makes it safe to run several delivery workers against the same DB.
+func (q *Q) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error) {
+	due := sq.Select("id").
+		From(webhookDeliveriesTableName).
+		Where("next_attempt_at <= ?", now).
+		OrderBy("next_attempt_at asc", "id asc").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+	dueSQL, dueArgs, err := due.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not build due deliveries query")
+	}
+
+	sql := sq.Update(webhookDeliveriesTableName).
+		Set("next_attempt_at", now.Add(lease)).
+		Where("id IN ("+dueSQL+")", dueArgs...).
+		Suffix("RETURNING *")
+
+	var deliveries []WebhookDelivery
+	err = q.Select(ctx, &deliveries, sql)
+	return deliveries, err
+}
+
+// DeleteWebhookDelivery removes a delivery once it has been completed.
+func (q *Q) DeleteWebhookDelivery(ctx context.Context, id int64) error {
+	_, err := q.Exec(ctx, sq.Delete(webhookDeliveriesTableName).Where("id = ?", id))
+	return err
+}
+
+// RescheduleWebhookDelivery records a failed attempt of a delivery and the time
+// of its next attempt.
+func (q *Q) RescheduleWebhookDelivery(
+	ctx context.Context, id int64, attempts int32, nextAttemptAt time.Time, lastError string,
+) error {
+	sql := sq.Update(webhookDeliveriesTableName).
+		SetMap(map[string]interface{}{
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+		}).
+		Where("id = ?", id)
+
+	_, err := q.Exec(ctx, sql)
+	return err
+}
+
+// DeadLetterWebhookDelivery moves a delivery, which will not be attempted
+// again, to the dead letter table. The attempts and last error are taken from
+// the given delivery.
+func (q *Q) DeadLetterWebhookDelivery(ctx context.Context, delivery WebhookDelivery, url string, now time.Time) error {
+	_, err := q.ExecRaw(ctx, `WITH moved AS (
+			DELETE FROM `+webhookDeliveriesTableName+` WHERE id = ?
+			RETURNING subscription_id, ledger_sequence, payload
+		)
+		INSERT INTO `+webhookDeadLettersTableName+`
+			(subscription_id, url, ledger_sequence, payload, attempts, last_error, created_at)
+		SELECT subscription_id, ?, ledger_sequence, payload, ?, ?, ? FROM moved`,
+		delivery.ID, url, delivery.Attempts, delivery.LastError, now,
+	)
+	return err
+}
+
+// GetWebhookDeadLetters returns up to limit dead letters with an id greater
+// than cursor. If subscriptionID is not zero only the dead letters of that
+// subscription are returned.
+func (q *Q) GetWebhookDeadLetters(
+	ctx context.Context, subscriptionID int64, cursor int64, limit uint64,
+) ([]WebhookDeadLetter, error) {
+	sql := sq.Select("*").
+		From(webhookDeadLettersTableName).
+		Where("id > ?", cursor).
+		OrderBy("id asc").
+		Limit(limit)
+	if subscriptionID != 0 {
+		sql = sql.Where("subscription_id = ?", subscriptionID)
+	}
+
+	var deadLetters []WebhookDeadLetter
+	err := q.Select(ctx, &deadLetters, sql)
+	return deadLetters, err
+}
+
+// GetLastLedgerWebhooks returns the last ledger for which webhook deliveries
+// have been enqueued or 0 if webhooks have never run. When forUpdate is true
+// the value is locked until the end of the current transaction.
+func (q *Q) GetLastLedgerWebhooks(ctx context.Context, forUpdate bool) (uint32, error) {
+	value, err := q.getValueFromStore(ctx, webhooksLastLedgerKey, forUpdate)
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	ledgerSequence, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, errors.Wrap(err, "error converting webhooks last ledger value")
+	}
+	return uint32(ledgerSequence), nil
+}
+
+// UpdateLastLedgerWebhooks updates the last ledger for which webhook
+// deliveries have been enqueued.
+func (q *Q) UpdateLastLedgerWebhooks(ctx context.Context, sequence uint32) error {
+	return q.updateValueInStore(
+		ctx,
+		webhooksLastLedgerKey,
+		strconv.FormatUint(uint64(sequence), 10),
+	)
+}
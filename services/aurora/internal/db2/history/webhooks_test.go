@@ -0,0 +1,134 @@
+package history
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/hcnet/go/services/aurora/internal/test"
+)
+
+func TestWebhookSubscriptions(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &Q{tt.AuroraSession()}
+
+	subscriptions, err := q.GetWebhookSubscriptions(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(subscriptions)
+
+	account, err := q.InsertWebhookSubscription(tt.Ctx, WebhookSubscription{
+		URL:       "https://example.com/hook",
+		Secret:    "secret",
+		AccountID: sql.NullString{String: "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB", Valid: true},
+	})
+	tt.Assert.NoError(err)
+	tt.Assert.NotZero(account.ID)
+	tt.Assert.False(account.CreatedAt.IsZero())
+
+	asset, err := q.InsertWebhookSubscription(tt.Ctx, WebhookSubscription{
+		URL:    "https://example.com/hook",
+		Secret: "secret",
+		Asset:  sql.NullString{String: "native", Valid: true},
+	})
+	tt.Assert.NoError(err)
+
+	// exactly one filter must be set
+	_, err = q.InsertWebhookSubscription(tt.Ctx, WebhookSubscription{
+		URL:    "https://example.com/hook",
+		Secret: "secret",
+	})
+	tt.Assert.Error(err)
+
+	subscriptions, err = q.GetWebhookSubscriptions(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(subscriptions, 2)
+	tt.Assert.Equal(account.ID, subscriptions[0].ID)
+	tt.Assert.Equal(asset.ID, subscriptions[1].ID)
+
+	tt.Assert.NoError(q.DeleteWebhookSubscription(tt.Ctx, account.ID))
+	tt.Assert.Equal(sql.ErrNoRows, q.DeleteWebhookSubscription(tt.Ctx, account.ID))
+
+	subscriptions, err = q.GetWebhookSubscriptions(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(subscriptions, 1)
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &Q{tt.AuroraSession()}
+
+	subscription, err := q.InsertWebhookSubscription(tt.Ctx, WebhookSubscription{
+		URL:             "https://example.com/hook",
+		Secret:          "secret",
+		LiquidityPoolID: sql.NullString{String: "cafebabe", Valid: true},
+	})
+	tt.Assert.NoError(err)
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tt.Assert.NoError(q.InsertWebhookDeliveries(tt.Ctx, []WebhookDelivery{
+		{SubscriptionID: subscription.ID, LedgerSequence: 10, Payload: `{"ledger":10}`, NextAttemptAt: now, CreatedAt: now},
+		{SubscriptionID: subscription.ID, LedgerSequence: 11, Payload: `{"ledger":11}`, NextAttemptAt: now.Add(time.Minute), CreatedAt: now},
+	}))
+
+	claimed, err := q.ClaimWebhookDeliveries(tt.Ctx, now, time.Minute, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(claimed, 1)
+	tt.Assert.Equal(uint32(10), claimed[0].LedgerSequence)
+	tt.Assert.Equal(now.Add(time.Minute), claimed[0].NextAttemptAt.UTC())
+
+	// claimed deliveries are leased
+	claimed, err = q.ClaimWebhookDeliveries(tt.Ctx, now, time.Minute, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(claimed)
+
+	claimed, err = q.ClaimWebhookDeliveries(tt.Ctx, now.Add(time.Minute), time.Minute, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(claimed, 2)
+
+	tt.Assert.NoError(q.DeleteWebhookDelivery(tt.Ctx, claimed[0].ID))
+
+	failed := claimed[1]
+	tt.Assert.NoError(q.RescheduleWebhookDelivery(tt.Ctx, failed.ID, 3, now.Add(time.Hour), "timeout"))
+	claimed, err = q.ClaimWebhookDeliveries(tt.Ctx, now.Add(time.Hour), time.Minute, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(claimed, 1)
+	tt.Assert.Equal(int32(3), claimed[0].Attempts)
+	tt.Assert.Equal("timeout", claimed[0].LastError)
+
+	tt.Assert.NoError(q.DeadLetterWebhookDelivery(tt.Ctx, claimed[0], subscription.URL, now))
+
+	claimed, err = q.ClaimWebhookDeliveries(tt.Ctx, now.Add(24*time.Hour), time.Minute, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(claimed)
+
+	deadLetters, err := q.GetWebhookDeadLetters(tt.Ctx, subscription.ID, 0, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(deadLetters, 1)
+	tt.Assert.Equal(subscription.URL, deadLetters[0].URL)
+	tt.Assert.Equal(`{"ledger":11}`, deadLetters[0].Payload)
+	tt.Assert.Equal("timeout", deadLetters[0].LastError)
+
+	deadLetters, err = q.GetWebhookDeadLetters(tt.Ctx, subscription.ID, deadLetters[0].ID, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(deadLetters)
+}
+
+func TestLastLedgerWebhooks(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &Q{tt.AuroraSession()}
+
+	sequence, err := q.GetLastLedgerWebhooks(tt.Ctx, false)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(0), sequence)
+
+	tt.Assert.NoError(q.UpdateLastLedgerWebhooks(tt.Ctx, 100))
+	sequence, err = q.GetLastLedgerWebhooks(tt.Ctx, false)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(100), sequence)
+}
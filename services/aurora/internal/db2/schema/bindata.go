@@ -9,7 +9,7 @@
 // migrations/16_ingest_failed_transactions.sql (509B)
 // migrations/17_transaction_fee_paid.sql (287B)
 // migrations/18_account_for_signers.sql (481B)
-// migrations/19_offers.sql (1.064kB)
+// migrations/19_offers.sql (1.063kB)
 // migrations/1_initial_schema.sql (9.977kB)
 // migrations/20_account_for_signer_index.sql (140B)
 // migrations/21_trades_remove_zero_amount_constraints.sql (765B)
@@ -61,10 +61,15 @@
 // migrations/62_claimable_balance_claimants.sql (1.428kB)
 // migrations/63_add_contract_id_to_asset_stats.sql (153B)
 // migrations/64_add_payment_flag_history_ops.sql (145B)
-// migrations/65_drop_payment_index.sql (260B)
+// migrations/65_drop_payment_index.sql (258B)
 // migrations/66_contract_asset_stats.sql (583B)
 // migrations/67_remove_unused_indexes.sql (2.897kB)
+// migrations/68_contract_events_and_data.sql (983B)
+// migrations/69_webhooks.sql (1.673kB)
 // migrations/6_create_assets_table.sql (366B)
+// migrations/70_state_history.sql (922B)
+// migrations/71_api_keys.sql (490B)
+// migrations/72_trade_aggregation_rollups.sql (3.629kB)
 // migrations/7_modify_trades_table.sql (2.303kB)
 // migrations/8_add_aggregators.sql (907B)
 // migrations/8_create_asset_stats_table.sql (441B)
@@ -317,7 +322,7 @@ func migrations18_account_for_signersSql() (*asset, error) {
 	return a, nil
 }
 
-var _migrations19_offersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x93\x41\x6f\xda\x4c\x10\x86\xef\xfe\x15\xef\x0d\xd0\x07\xd1\x77\x69\x2f\x39\x91\xd8\x4d\xac\x52\x3b\x32\xa6\x6d\x4e\xd6\x62\x8f\xcd\x88\x65\x17\xed\xae\x43\xdc\x5f\x5f\xad\x0d\xa8\x89\x80\xf3\xfb\xce\x73\x78\x66\x66\x36\xc3\x7f\x3b\x6e\x8c\x70\x84\xd5\x3e\x08\x1e\xb3\x68\x9e\x47\xc8\xe7\x0f\x8b\x08\xba\xae\xc9\x58\x8c\x03\x00\xb0\x24\x25\x19\xae\x50\x6e\x84\x11\xa5\x23\x83\x37\x61\x3a\x56\xcd\xf8\xcb\xd7\x09\x92\x34\x47\xb2\x5a\x2c\xa6\x7d\xb9\x9f\xe4\x0a\x6b\x6e\x58\x39\xbc\x64\xf1\x8f\x79\xf6\x8a\xef\xd1\xeb\xf4\x0c\x63\xd5\x08\x6b\xc9\xc1\xd1\xbb\xfb\x34\xbf\x6e\xbb\x5b\xb1\xd8\xe9\x56\xb9\x13\xfd\x63\xb6\x37\x5c\x92\x02\x2b\x47\x0d\x99\x4b\x61\x75\x2b\x44\xa5\xdb\xb5\x24\xec\x0d\x95\x6c\x59\xab\x4f\xa5\x5a\x8a\xc6\x5e\x01\x48\x61\x5d\xb1\xd3\x15\xd7\x4c\x55\x21\xa9\xf2\x95\x38\xc9\xcf\xb5\x60\x72\x7f\x56\x1c\x27\x61\xf4\xfb\xa8\xb8\x58\x77\xc5\xe0\x17\x69\x72\xd2\xbe\x5a\xc6\xc9\x13\x1e\xf2\x2c\x8a\xc6\x27\xf9\x93\xfb\x5b\xe3\xac\x9a\x62\x70\x76\x9d\x72\xd2\x7a\x9d\x34\xb8\xbf\x09\xfa\x67\x3d\xd7\x39\x17\x6d\x5c\xe6\x5d\xaa\x7a\x55\xb3\x19\x42\xb6\xce\xf0\xba\x75\xfd\xda\x1a\xb2\xce\xef\xc4\x90\x64\xb2\xd0\x0a\x02\x96\x55\x23\x09\x6f\x42\xb6\x04\xa9\xcb\x2d\x55\xa8\xb5\x41\xbb\xaf\x84\x63\xd5\x78\x0a\xfb\x62\xf8\x70\x87\x5f\x1b\x52\x78\xd6\x86\xff\x68\x05\xeb\x84\x71\x16\xa5\x24\x61\xe0\x36\x64\x08\x6c\xa1\xf4\x91\x65\x35\x0e\x84\xd2\x90\x7f\x0e\x76\x9e\xe3\x3b\x77\x88\xeb\xa1\x3d\xb2\x10\x28\xb5\xaa\x25\x97\x0e\xec\xb0\x23\xa1\xac\xcf\x8e\x04\xb6\x10\xd2\x90\xa8\xba\x23\xde\x6a\x4f\x39\xf8\x2b\x83\xd2\x6e\xc3\xaa\xb9\x0b\xe2\x64\x19\x65\xb9\x3f\x94\x14\x5b\xea\x8a\x7e\xb6\xb0\x4e\x1b\xc2\x78\x4b\xdd\x74\xa0\x4d\xfa\x1b\xfb\x39\x5f\xac\xa2\x25\xc6\x23\x7a\xdf\x17\x83\x90\xc1\xf4\x60\x6d\x34\xc5\xe8\xff\xd1\x50\x4d\x13\x3c\xa6\xc9\xb7\x45\xfc\x98\xf7\x9c\x09\xc2\xd4\x9f\xe2\x73\x9c\x3c\x0d\x72\xcf\xaf\x1f\xea\x83\x0a\x82\x30\x4b\x5f\x3e\xbe\x7e\x29\x6c\x29\x2a\xba\x0f\xfe\x06\x00\x00\xff\xff\x14\xd1\xb7\xec\x28\x04\x00\x00")
+var _migrations19_offersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\x03\x7d\x93\xc1\x72\xda\x30\x10\x86\xef\x7e\x8a\xbd\x01\x53\x60\x7a\x69\x2f\x39\x19\xec\xa4\x9e\x52\x3b\x63\x4c\xdb\x9c\x3c\x42\x5e\x1b\x4d\x84\xc5\x48\x72\x88\xdf\xbe\x2b\x0b\x98\x26\x03\x70\x02\xef\xff\x7f\x78\x3e\xad\x66\x33\xf8\xb2\x17\x8d\x66\x16\x61\x73\x08\x82\x65\x1e\x87\x45\x0c\x45\xb8\x58\xc5\xa0\xea\x1a\xb5\x81\x71\x00\xf4\x31\x28\x25\x6a\x51\x01\xdf\x31\xcd\xb8\x45\x0d\x6f\x4c\xf7\xa2\x6d\xc6\xdf\xbe\x4f\x20\xcd\x0a\x48\x37\xab\xd5\x74\x08\x0f\x4d\xca\x6e\x45\x23\x5a\x0b\xcf\x79\xf2\x2b\xcc\x5f\xe0\x67\xfc\x32\xbd\xc0\xa8\xc9\x8c\x41\x0b\x16\xdf\xed\xa7\xfe\xb6\xeb\xef\x8d\xd9\x5e\x75\x84\x3d\xd1\x3f\xce\x0e\x5a\x70\x6c\x81\x06\xd8\xd0\x3b\x5e\x19\x56\xf7\x86\x50\xa9\x6e\x2b\x91\x7e\x20\x17\x46\xa8\xf6\x53\xa8\x96\xac\x31\x37\x00\x92\x19\x5b\xee\x55\x25\x6a\x81\x55\x29\xb1\x72\x91\x24\x2d\x2e\xb1\x60\xf2\x70\x51\x9c\xa4\x51\xfc\xf7\xa4\xb8\xdc\xf6\xa5\xf7\x0b\x59\x7a\xd6\xbe\x59\x27\xe9\x13\x2c\x8a\x3c\x8e\xc7\x67\xf9\xd4\xbf\x53\x27\x65\xa5\x77\x76\x9b\x72\xd6\x7a\x9b\xe4\xdd\xdf\x05\xfd\x77\x3c\xb7\x39\x57\x6d\x5c\xe7\x5d\x8b\x3a\x55\xb3\x19\x44\xc2\x58\x2d\xb6\x9d\x1d\x8e\xad\x41\x63\xdd\x99\x68\x94\x02\x0d\xd0\x37\x06\x86\x1e\xd3\x81\xbd\x31\xd9\x21\x48\xc5\x5f\x29\x59\x2b\x0d\xdd\xa1\x62\x96\x66\x8e\x22\x5c\x30\x5a\xcc\xe1\xcf\x8e\x76\x23\xec\xb4\xd2\x54\xb4\x4c\x5b\x03\x5c\x22\xd3\x60\x77\xa8\x11\x84\x81\x56\x9d\x50\x46\xc1\x11\x81\x6b\x74\x77\x43\x58\x87\x71\x99\x39\x24\xb5\x4f\x8f\x0c\x41\xb9\x6a\x6b\x29\xb8\xa5\x04\xec\x91\xb5\xc6\xcd\x4e\x04\xa2\x31\x49\xfd\xaa\x3f\xe1\x8d\x72\x94\xa3\x5b\x32\xfa\x1f\xbb\xa3\xb7\x9b\x07\x49\xba\x8e\xf3\xc2\xed\x49\x06\xaf\xd8\x97\x43\xb7\x34\x56\x51\x61\x4c\x0f\xa6\x9e\x36\x19\x56\xec\x77\xb8\xda\xc4\x6b\x18\x8f\xf0\xfd\x50\x7a\x1f\x5e\xb4\x97\x36\x9a\xc2\xe8\xeb\xc8\x47\x49\xf5\x32\x4b\x1f\x57\xc9\xb2\x18\x38\x13\x88\x32\xb7\x89\x3f\xc8\xbb\x77\x7b\xb9\xf9\x91\x3a\xb6\x41\x10\xe5\xd9\xf3\xc7\x9b\xcf\x99\xe1\xac\xc2\x87\xe0\x1f\xb8\x90\xe7\xe4\x27\x04\x00\x00")
 
 func migrations19_offersSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -333,7 +338,7 @@ func migrations19_offersSql() (*asset, error) {
 	}
 
 	info := bindataFileInfo{name: "migrations/19_offers.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xbd, 0xff, 0xef, 0xfe, 0xb0, 0x5b, 0xa3, 0x92, 0xac, 0x9d, 0x98, 0x6c, 0xd4, 0x90, 0x9c, 0xe9, 0xae, 0x89, 0xfc, 0x54, 0x2b, 0xe3, 0x33, 0x1f, 0x0, 0xd6, 0x24, 0x9a, 0x1, 0xe1, 0xa6, 0x39}}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd6, 0x50, 0xfa, 0xa8, 0x7a, 0xff, 0xbf, 0x83, 0x35, 0x8, 0x73, 0x98, 0x50, 0x84, 0xa3, 0xb, 0xc6, 0x9d, 0x2a, 0x78, 0x82, 0x2a, 0x85, 0xef, 0xe9, 0xe1, 0xce, 0xae, 0x1d, 0x8c, 0xc1, 0xb0}}
 	return a, nil
 }
 
@@ -1357,7 +1362,7 @@ func migrations64_add_payment_flag_history_opsSql() (*asset, error) {
 	return a, nil
 }
 
-var _migrations65_drop_payment_indexSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\xcf\xc1\x4a\xc3\x40\x10\xc6\xf1\x7b\x9e\xe2\xa3\x57\x8d\xeb\xc1\x0a\xea\x35\x15\x72\x51\xb1\x0a\xbd\x2d\xdb\x66\x48\x06\x9a\x99\x65\x67\x96\x9a\xb7\x17\x5b\xf0\xdc\x07\xf8\x7e\x7c\xff\xb6\xc5\xcd\xcc\x63\x49\x4e\xf8\xce\x4d\xd3\xb6\x60\x19\xe8\x27\x4e\x6c\xae\x65\x89\x9a\xa9\x24\x67\x15\x8b\x2a\x91\x2d\xe6\xb4\xcc\x24\x8e\x53\x32\xa4\x61\xa0\x01\x2c\xb8\x10\xac\x82\xc7\x07\xec\xab\x83\x1d\x5e\x8b\x18\xb4\xfa\x1f\xea\x13\x5d\xe0\xf3\x4e\xd4\x21\x74\x20\xb3\x54\x96\x5b\x18\x11\x26\xf7\x6c\xcf\x21\x8c\xec\x53\xdd\xdf\x1d\x74\x0e\xe6\x74\x3c\xa6\x12\x46\x0d\x6c\x56\xc9\xc2\xfa\x7e\xfd\xd4\x74\x9f\xef\x1f\xe8\xdf\xba\xcd\x0e\xfd\x2b\x36\xbb\x7e\xfb\xb5\xc5\xea\xba\xd7\xab\x97\x73\xe2\x7f\x72\xa7\x27\x69\x7e\x03\x00\x00\xff\xff\x15\xa3\x47\xcb\x04\x01\x00\x00")
+var _migrations65_drop_payment_indexSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\x03\x8d\x8f\xb1\x6e\xc2\x40\x10\x44\x7b\x7f\xc5\x88\x36\x31\x97\x22\x20\x01\xad\x41\x72\x93\x44\x81\x48\x74\xa7\xc3\x5e\xf9\xae\xf0\xae\xe5\xdd\x13\xf1\xdf\xc7\x80\x44\x9d\x7a\x66\x9e\xe6\x95\x25\x5e\xfa\xd4\x8d\xc1\x08\x3f\x43\x51\x94\x25\x12\xb7\xf4\xeb\x63\x52\x93\x71\xf2\x32\xd0\x1c\x26\x61\xf5\xc2\x3e\xa9\x1f\xc2\xd4\x13\x1b\xae\x41\x11\xda\x96\xda\x79\x80\x07\x62\x6e\x61\xfd\x8e\x4b\x36\x24\x83\xe5\x91\x15\x92\xed\x06\xb5\x48\x0f\xf0\x7d\xc7\x62\x60\x6a\x48\x35\x8c\xd3\x2b\x94\x08\xd1\x6c\xd0\xad\x73\x5d\xb2\x98\x2f\xcb\x46\x7a\x17\x1b\x26\x73\x9d\xb8\xa4\x9a\x49\xdd\xea\x6d\xb5\x29\xaa\xef\xcf\x2f\xd4\x1f\xd5\xfe\x8c\xfa\x80\xfd\xb9\x3e\x9e\x8e\x58\xfc\xef\xf3\x62\x77\x17\x7c\x0a\x57\x72\xe5\xe2\x0f\x1f\xa0\xf3\x92\x02\x01\x00\x00")
 
 func migrations65_drop_payment_indexSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -1373,7 +1378,7 @@ func migrations65_drop_payment_indexSql() (*asset, error) {
 	}
 
 	info := bindataFileInfo{name: "migrations/65_drop_payment_index.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf2, 0xe4, 0xb0, 0xad, 0x98, 0x6a, 0x5, 0xb8, 0x1e, 0xbb, 0xf6, 0x3d, 0x63, 0x93, 0xd5, 0x45, 0x29, 0xd7, 0x23, 0x23, 0x9b, 0xfc, 0x27, 0xb5, 0x8f, 0x37, 0x20, 0x66, 0x6c, 0x97, 0x45, 0x4a}}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x9d, 0x26, 0xe0, 0x43, 0x54, 0x8, 0x44, 0x0, 0x72, 0x59, 0x47, 0xce, 0xe1, 0x9e, 0x53, 0x3, 0x94, 0x22, 0x8c, 0x9f, 0x3, 0x2, 0x2, 0x39, 0xd0, 0x6, 0xc7, 0xfd, 0x39, 0x93, 0x71, 0xa9}}
 	return a, nil
 }
 
@@ -1417,6 +1422,46 @@ func migrations67_remove_unused_indexesSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations68_contract_events_and_dataSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\x03\x9d\x93\xcd\x6e\x82\x40\x14\x85\xf7\x3c\xc5\x0d\x2b\x4d\x65\xd3\xb4\x6e\x5c\xd9\x42\x1a\x53\x8b\x06\xb5\xd1\x34\xcd\x64\x98\xb9\xca\xa4\xc8\x98\x99\xd1\xea\xdb\x17\xa5\x20\x58\x88\x6d\x59\x72\xee\xb9\x3f\xe7\xcb\x38\x0e\xdc\xac\xc5\x4a\x51\x83\x30\xdb\x58\x8f\x81\xd7\x9f\x7a\x30\xed\x3f\x0c\x3d\x88\x84\x36\x52\x1d\x08\x93\x89\x51\x94\x19\x82\x3b\x4c\x8c\x86\x96\x05\xc7\x2f\x97\xe5\x06\x53\xbb\x90\x09\x11\x1c\x42\xb1\x12\x89\x01\x7f\x34\x05\x7f\x36\x1c\x76\xb2\x52\x5b\x2a\x8e\xca\x86\x54\xc2\x15\xaa\x4b\xb9\x18\x90\x36\x30\xb8\xff\x61\x37\x72\x23\x98\x3e\x49\x6f\xef\x67\x11\x1c\x07\x42\xaa\xb1\x7b\x07\x98\x30\xc9\x91\xc3\x84\xbd\xd2\x18\xe6\x6e\x90\x19\x77\x34\xde\xe2\x45\xcb\xeb\xae\x71\x30\x78\xe9\x07\x0b\x78\xf6\x16\xd0\xaa\xbb\xb2\x93\x1f\xd4\xb6\xda\x3d\x2b\x0f\x6d\xe0\xbb\xde\x1c\xec\x86\xd4\x48\x58\xfa\x25\xb8\x0d\x23\xbf\x31\xe0\xd9\x64\xe0\x3f\x41\x68\x14\x22\xb4\x4a\xa6\x4e\x6d\xe6\xe7\x6d\xce\xbb\x64\x00\x0b\x2b\xa7\x86\xe6\xd8\x3e\xf0\x40\x22\xaa\xa3\x2c\x96\xd2\xad\xa7\x64\x22\xdc\x17\xb1\xe8\x88\xde\xde\x77\xe1\x54\x2c\x97\x60\x22\x84\x18\xf9\x91\x5f\xda\xe3\x77\xe4\xf8\x56\xd1\x50\xc4\xc2\x1c\x9a\xd8\xa7\xad\xfe\x0c\xe8\x7f\x58\x63\xaa\x0d\x59\x4b\x2e\x96\x02\x39\xf9\xbe\xe4\x72\xab\x1a\xa0\x95\x14\xeb\x30\x56\x63\x6e\x86\x97\x27\x7f\x1c\xe1\x94\xde\x9d\x2b\x3f\x13\xcb\x0d\x46\xe3\x2b\xef\x8e\x51\xcd\x28\xc7\x5e\xb9\xb6\x3a\xbb\xa8\xf8\x02\xa2\x28\xf1\x7b\xd7\x03\x00\x00")
+
+func migrations68_contract_events_and_dataSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations68_contract_events_and_dataSql,
+		"migrations/68_contract_events_and_data.sql",
+	)
+}
+
+func migrations68_contract_events_and_dataSql() (*asset, error) {
+	bytes, err := migrations68_contract_events_and_dataSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/68_contract_events_and_data.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x55, 0xe2, 0x24, 0x23, 0x81, 0x68, 0xd2, 0xca, 0x46, 0x16, 0x3b, 0x50, 0xda, 0x99, 0xfc, 0xac, 0x84, 0x2b, 0x25, 0xf6, 0x4b, 0x43, 0x90, 0xfa, 0x2d, 0x5b, 0x9e, 0x9c, 0xb3, 0xa8, 0x21, 0xe8}}
+	return a, nil
+}
+
+var _migrations69_webhooksSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\x03\xb5\x54\x4d\x73\xda\x30\x10\xbd\xf3\x2b\x76\x72\x09\x4c\x80\x69\xaf\x64\x7a\x70\x6c\x25\xa5\xa1\x26\x63\x9b\x99\xe6\xa4\x11\xf6\x16\x34\x15\x92\x23\xc9\x25\xf4\xd7\x47\x8e\x0d\x05\xe3\x42\xfa\xe5\xa3\xf4\x76\xdf\xdb\x7d\xcf\x1a\x0c\xe0\x6a\xc5\x17\x9a\x59\x84\x59\xde\xf1\x23\xe2\x25\x04\x12\xef\x66\x42\x60\x8d\xf3\xa5\x52\xdf\xa8\x29\xe6\x26\xd5\x3c\xb7\x5c\x49\x03\xdd\x0e\x94\x1f\xcf\x60\xce\x17\x06\x35\x67\x02\x1e\xa2\xf1\x67\x2f\x7a\x84\x7b\xf2\xd8\xaf\xae\x0b\x2d\xc0\xe2\xb3\x85\x70\x9a\x40\x38\x9b\x4c\xea\x73\x83\xa9\x46\xdb\x7a\xc5\xd2\x54\x15\xd2\x52\xd7\xb9\xbc\xde\x9e\x1a\x53\xe3\xfb\x30\x18\x40\xca\xa4\x92\x3c\x75\x9c\xd5\x85\xb1\x9a\xcb\x45\x1f\xf0\x79\x08\x92\x59\xfe\x1d\x41\x69\x98\xc5\xc1\xe8\xce\xbb\xf1\x87\xc3\x61\xd5\x45\xf0\xa7\x82\x67\xdc\x6e\x68\xae\x94\x68\x50\x38\x45\x6e\xfc\x8c\x32\xc7\xc3\x57\x68\x2c\x5b\xe5\xb0\xe6\x76\xa9\x8a\xea\x04\x7e\x28\x89\x4d\xb9\xfe\x34\x8c\x93\xc8\x1b\x87\x49\xfb\xa2\xa8\x71\xc2\x04\xd2\xaf\x5c\x58\xd4\xe0\x7f\x24\xfe\xfd\x76\x79\xaf\x5f\x77\x6f\xe0\x71\xbc\x6b\xdf\x1b\x8d\xb8\xb4\xb8\x70\x35\x57\x07\xe8\xd7\x79\xdf\x00\x3c\x9e\xb5\xbd\xe8\x03\xbc\xaf\xca\x7a\x9d\xde\x75\xa7\xdd\xfa\x0c\x85\x5b\xa9\xe6\xf8\x56\xdf\xf7\x57\x40\x2b\xac\xe3\xdb\xd1\x43\x44\x6e\x49\x44\x42\x9f\xc4\xbf\x8a\x17\xcf\x7a\x30\x0d\x21\x20\x13\xe2\xe4\xf8\x5e\xec\x7b\x01\xa9\xbb\x0b\xcc\x9c\x72\x6a\xf0\xa9\x40\x99\x22\x6c\x47\x69\x58\x93\xb3\x8d\x50\x2c\x6b\xa4\xac\x8c\xcf\xa7\xd8\xb5\xce\x54\x5a\xac\xd0\xa9\x7a\x98\xc6\x09\x3a\x98\x02\xbb\xc4\x03\xe9\x65\x7e\xeb\xfc\x59\x8b\xab\xdc\x9a\x23\x2e\xa7\xf0\xd6\x9b\x4d\x12\x78\x57\xb3\x4a\xc7\x46\x6b\xf8\x6f\x66\x49\x30\x63\x29\x6a\xed\xa2\x7b\xa0\x79\xc7\x71\x79\xf9\x67\x59\xdd\x77\x76\x1c\x06\xe4\x0b\x5c\x1c\x5b\x4b\xe7\x1b\xda\x10\x7f\x51\x5a\xd0\x12\x82\x59\x3c\x0e\xef\x60\x6e\x35\x22\x74\x1b\x35\x27\x42\xc4\x32\x2a\xd0\x01\xf5\x3f\x8a\xd1\x99\x47\xe6\xaf\x62\x72\xc6\xf6\x33\x8e\xfd\x07\x9b\x7e\x2e\xaf\x34\xaa\xb1\x99\x86\x51\x7b\x8b\x3e\xb0\xaa\x51\xd5\x77\xfb\x2f\x29\x07\x7b\xaf\x7f\xa0\xd6\xb2\x13\x44\xd3\x87\x53\xee\xa5\xcc\xa4\x2c\xc3\xeb\x76\xe0\x2e\x26\xa7\x60\x87\xbf\xfb\x0e\xf9\x02\x3a\x8b\xfe\xb5\x89\x06\x00\x00")
+
+func migrations69_webhooksSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations69_webhooksSql,
+		"migrations/69_webhooks.sql",
+	)
+}
+
+func migrations69_webhooksSql() (*asset, error) {
+	bytes, err := migrations69_webhooksSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/69_webhooks.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa1, 0x1, 0xa6, 0x1a, 0x87, 0xd5, 0xc, 0x58, 0xf2, 0xb0, 0xce, 0xe7, 0x97, 0x57, 0x76, 0xe6, 0xd1, 0xee, 0xbd, 0xda, 0x3a, 0xd3, 0x70, 0x6c, 0x88, 0xf, 0x24, 0x24, 0xa, 0x44, 0x24, 0x6b}}
+	return a, nil
+}
+
 var _migrations6_create_assets_tableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x3d\x4f\xc3\x30\x18\x84\x77\xff\x8a\x1b\x1d\x91\x0e\x20\xe8\x92\xc9\x34\x16\x58\x18\xa7\xb8\x31\xa2\x53\xe5\x26\x16\x78\x80\x54\xb6\x11\xca\xbf\x47\xaa\x28\xf9\x50\xe6\x7b\xf4\xbc\xef\xdd\x6a\x85\xab\x4f\xff\x1e\x6c\x72\x30\x27\xb2\xd1\x9c\xd5\x1c\x35\xbb\x97\x1c\x1f\x3e\xa6\x2e\xf4\x07\x1b\xa3\x4b\x11\x94\x00\x80\x6f\xb1\xe3\x5a\x30\x89\xad\x16\xcf\x4c\xef\xf1\xc4\xf7\xc8\xcf\xd9\x19\x3c\xa4\xfe\xe4\xf0\xca\xf4\xe6\x91\x69\xba\xbe\xcd\xa0\xaa\x1a\xca\x48\x39\x86\x9a\xae\x1d\xa0\xeb\x9b\x65\xc8\xc7\xf8\xed\xc2\x3f\x76\xb7\x9e\x63\x46\x89\x17\xc3\xe9\xa0\xcc\x47\x3f\xe4\x13\x4b\x46\xb2\x82\x5c\xfa\x09\x55\xf2\xb7\xbf\xf8\xd8\x5f\xee\x54\x6a\x5e\xd9\xec\x84\x7a\xc0\x31\x05\xe7\x40\x27\xb6\x82\x90\xf1\x74\x65\xf7\xf3\x45\x4a\x5d\x6d\x97\xa7\x6b\x6c\x6c\x6c\xeb\x8a\xdf\x00\x00\x00\xff\xff\xfb\x53\x3e\x81\x6e\x01\x00\x00")
 
 func migrations6_create_assets_tableSqlBytes() ([]byte, error) {
@@ -1437,6 +1482,66 @@ func migrations6_create_assets_tableSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations70_state_historySql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\x03\x8d\x53\xcb\x4e\xc3\x30\x10\xbc\xe7\x2b\x46\xbd\xd0\x8a\x84\x13\xe2\xc2\xa9\xd0\x08\x2a\x4a\x8a\xd2\x56\x80\x10\x8a\x9c\x64\xdb\x58\xa4\x76\xb1\x5d\xda\xfc\x3d\x4e\xa2\x10\x1a\xf1\xa8\x8f\xde\xf1\xce\xec\xec\xd8\xf3\x70\xba\xe6\x2b\xc5\x0c\x61\xb1\x71\xae\x43\x7f\x38\xf7\x31\x1f\x5e\x4d\x7c\x64\x5c\x1b\xa9\x8a\x48\x1b\x5b\x8d\x48\x18\xc5\x49\xa3\xef\xa0\x3c\x39\xa5\x2b\x52\xd1\x1b\x15\x30\xb4\x37\x08\xa6\x73\x04\x8b\xc9\xc4\x85\xe7\x21\x66\x9a\x2e\xce\x41\x22\x91\x29\xa5\xd8\xa7\xea\x6c\x52\xe1\xef\xa8\x38\x78\xae\xe9\x7d\x6b\x51\x04\x2e\x0c\xd9\x8b\xb6\x4d\x0d\x2b\x49\x8b\xc8\x14\x1b\x82\x5e\xb3\x3c\xb7\xb0\x2e\x84\x25\x89\xdc\x0a\xa3\x2b\x19\x2f\xaf\x87\x42\xda\x62\x46\x75\x33\x24\x4c\x20\x26\xe4\x52\xbe\x59\x69\xdb\x0d\xe2\xc2\x05\xed\xcf\x20\x77\x82\x94\x8b\x24\x67\x7c\xcd\xca\x37\x4c\xa4\xd0\x1b\x29\xb4\x54\x35\x95\x5c\x2e\xad\x66\x9e\x22\xe6\x2b\xab\xa4\x62\xd0\x64\xb0\x94\xaa\xae\x69\xec\x32\x9e\x64\x25\xc7\x89\xe9\xb2\xa0\xf4\x6a\xc7\x4d\x26\xb7\xa6\xd2\xa3\x29\xcf\x49\x1d\xf8\x51\x4b\x2c\x27\xf9\xdb\x47\xbf\xc4\xb9\xd5\x9c\x96\x92\xc4\xb7\xf9\x76\x4c\x43\xd1\x5a\x7e\x50\x5a\xb7\x7e\x08\xc7\xf7\xc3\xf0\x19\x77\xfe\x33\xfa\xed\xda\xdc\xee\x0e\x06\xce\xe0\xd2\x69\x12\x30\x0e\x46\xfe\x13\x7a\x3f\x46\x20\x8a\x8b\xa8\x31\xb6\x87\x69\xf0\x4b\x50\x16\xb3\x71\x70\x03\x6b\x14\xfa\x0d\xda\x12\x1c\xd9\xbf\xb1\xfa\xdf\xfe\xb1\x51\x44\xe8\x37\xf8\x01\x1e\x6f\xfd\xd0\x6f\x57\x35\x9e\x7d\x25\xe2\x68\xf2\x8e\x31\xc7\x6a\xe8\xfa\x69\xdd\xf4\xbe\x7d\xaf\x91\x0d\x98\x33\x0a\xa7\x0f\x7f\x7e\xaf\x84\xe9\x84\xa5\x74\xe9\x7c\x02\x51\x62\xb3\x41\x9a\x03\x00\x00")
+
+func migrations70_state_historySqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations70_state_historySql,
+		"migrations/70_state_history.sql",
+	)
+}
+
+func migrations70_state_historySql() (*asset, error) {
+	bytes, err := migrations70_state_historySqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/70_state_history.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x80, 0xa8, 0xdb, 0x66, 0xc6, 0x6a, 0x1c, 0x42, 0x7, 0xa0, 0x7d, 0x31, 0x46, 0x85, 0xad, 0xb6, 0xbb, 0xe4, 0xfa, 0x68, 0xa0, 0xbf, 0x53, 0x9a, 0x7, 0x48, 0x4b, 0x13, 0x9a, 0xef, 0xd0, 0xd9}}
+	return a, nil
+}
+
+var _migrations71_api_keysSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\x03\x6d\x91\xb1\x6e\xc2\x30\x10\x86\x77\x3f\xc5\x3f\xb6\x2a\x59\x2a\xb5\x0b\x13\x2d\x19\x50\x29\xd0\x88\x0c\x4c\xd1\x91\x1c\xd8\x2a\xb6\x83\xcf\x11\xd0\xa7\xaf\x69\x50\x55\x55\xf1\xe8\xef\xee\xf3\xfd\xbe\x2c\xc3\x83\x35\xfb\x40\x91\x51\xb6\xea\xb5\xc8\x27\xeb\x1c\xeb\xc9\xcb\x3c\x07\xb5\xa6\xfa\xe4\x8b\xe0\x4e\xe1\x7a\x4c\x83\xad\xd9\x0b\x07\x43\x07\xac\x8a\xd9\xfb\xa4\xd8\xe0\x2d\xdf\x8c\x7a\xec\xc8\x32\x22\x9f\x23\x16\xcb\x35\x16\xe5\x7c\x8e\x72\x31\xfb\x28\xf3\x1b\x4f\xaa\x4a\x93\xe8\xe1\x1a\x64\x19\x34\x9f\xc1\xae\xf6\x0d\x37\x10\x4d\x8f\x4f\xcf\xf8\x69\xf0\x3b\x44\xcd\x57\x41\x6f\x4a\xa5\x81\x8f\x1d\x4b\x14\xb4\x1c\xa0\x7d\x17\xb0\xf3\x01\x4c\xb5\x4e\x86\xa6\xf5\xc6\x45\xd4\x07\x12\x19\xf5\xaf\x58\x26\x27\xe8\xdc\xc1\x58\x13\xb9\xe9\x3d\x12\x53\xec\xea\xd8\xf9\x48\x48\x0d\xbc\xe7\x70\x9b\x55\x1b\x89\x3e\x5c\x06\x99\xc4\xc0\x64\x65\x90\xb5\x14\xf5\x30\x91\x6e\x6b\x8d\x88\xf1\x6e\x10\xd7\xc9\x99\xe6\xaa\x28\x22\x1a\x9b\x92\x91\x6d\x71\x32\x31\x45\xeb\x6f\xf0\xe5\x1d\xff\xfe\x9a\xba\x1f\x2b\x95\xfd\xd9\xdd\xd4\x9f\x9c\x9a\x16\xcb\xd5\xff\xdd\xd5\x24\x35\x35\x3c\x56\xdf\x34\xdc\xc9\xcf\xea\x01\x00\x00")
+
+func migrations71_api_keysSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations71_api_keysSql,
+		"migrations/71_api_keys.sql",
+	)
+}
+
+func migrations71_api_keysSql() (*asset, error) {
+	bytes, err := migrations71_api_keysSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/71_api_keys.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x11, 0xd8, 0x44, 0x7, 0x84, 0xc0, 0x66, 0xae, 0x53, 0x40, 0xc7, 0x69, 0x4, 0x36, 0xd3, 0xc, 0xe3, 0x53, 0x39, 0x7b, 0xe2, 0xbb, 0xfc, 0xab, 0x15, 0x89, 0x6b, 0x5b, 0x25, 0x8f, 0x23, 0x26}}
+	return a, nil
+}
+
+var _migrations72_trade_aggregation_rollupsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\x03\xa5\x57\x5b\x73\xda\x38\x14\x7e\xf7\xaf\x38\x8f\xa6\x05\x42\x69\x9b\x69\x27\xdb\x07\x27\xb8\x2d\xbb\x04\x32\x06\xb6\xcd\x74\x32\x1e\x81\x85\xd1\xd4\xb7\xb5\xe4\xb4\xec\xaf\xef\x91\x7c\x37\x82\xa4\xd3\xbc\xc4\x3e\x97\xef\x58\x9f\xce\xf9\x24\x06\x03\x78\x19\x32\x3f\x25\x82\xc2\x3a\x31\x0c\x6b\xb6\xb2\x1d\x58\x59\xd7\x33\x1b\xf6\x8c\x8b\x38\x3d\xb8\x22\x25\x1e\xe5\xee\xe5\x08\xff\x0c\x00\x6b\x32\x81\x9b\xc5\x6c\x7d\x3b\x87\x24\x8e\x03\x77\x1b\x67\x91\x00\x16\x09\xea\xd3\x14\xa2\x58\x40\x94\x05\x01\x78\x74\x47\xb2\x40\xc0\xa8\xaf\xc9\xd9\x10\x4e\xdd\xc7\x38\xc8\x42\x8a\xd1\x21\x4d\xd9\xf6\x99\x99\xaa\x1a\x4d\x9f\x4e\xbe\x32\x8c\xc1\x00\xae\xc9\xf6\xfb\x8e\xa1\x5d\xec\x29\x04\xec\xbf\x8c\x79\x4c\x1c\x14\x14\xe4\x10\xbc\x0f\x19\x67\x91\xaf\x22\xca\xec\x14\xab\x78\xd2\xc8\x03\x96\x24\xc4\xa7\x12\x0b\x71\xb0\x32\xc4\x3b\x78\x85\x4c\xc0\x86\x25\x7c\x68\x7c\x99\xae\x3e\xe7\x5f\x96\xd3\x04\xd6\x12\x4c\xfc\xee\xa5\x3d\xb3\x6f\x56\xf8\x00\x20\x62\x37\xc4\x4f\x60\xdc\x0c\xa8\x87\x1c\xb9\xdb\x20\xe6\xd4\x73\x89\xe8\x83\x22\xb5\x07\x84\x83\x60\xf8\x2d\x82\x84\x49\x5f\x25\x29\x86\x08\xe7\x54\xb8\xcc\xcb\x4d\xe5\xd2\x35\x56\xf3\x85\xc2\x50\xcf\xb9\x99\x67\xa1\x99\x63\x84\xd2\xa8\xdc\x0d\xd6\xeb\xa0\x0a\xb5\x8e\x6b\x73\x8c\x91\x1f\x9d\xc5\x6d\xa7\x1d\xd0\xfa\xe5\xb3\xed\xd8\xa0\x5e\x5d\x71\x48\x28\x7c\x80\x31\x58\xf3\x09\xe6\x93\x80\xf2\x2d\x35\x4b\x1a\xdd\x92\xc6\x3e\xe0\x62\xff\x52\xfc\x21\xc0\x27\x67\xb1\xbe\x83\xeb\xfb\xce\x62\x8f\x17\x5a\x93\x63\xf4\x8c\xf5\xdd\xc4\x5a\xe9\xbb\x13\xf6\x22\xf5\x90\x7a\xc9\x7b\xa3\x37\x3f\x34\x37\x68\x58\x91\x74\xd4\x89\xed\xb8\x0e\x5b\xba\xee\xd3\x00\xd7\xb4\x29\xd2\x1a\x7e\x23\xe7\x4b\x7e\xe2\xb0\xb5\x60\x5d\xdd\xd2\x27\x27\x00\x19\x55\x49\x5d\x5a\x4e\x94\xd7\xa5\x56\xfc\x75\x72\x2a\x7b\x3e\x2d\x1d\x4e\xd3\x38\x08\xb2\x44\xf6\x43\x24\x08\x8b\xb8\x1a\x91\x4d\xb6\xfd\x4e\x05\x97\x63\xa0\xdd\x02\x99\x44\x3d\x89\x96\x25\xd8\xfa\x2a\x07\x1b\x22\xe5\x38\x3a\x29\xe5\xc8\x8d\x60\x31\x62\x91\x20\x88\x7f\x50\x0f\x36\x07\xb8\xc8\x7b\x88\xf8\x7e\x4a\x7d\xa2\xdc\x43\xe3\xc6\xb1\xe5\x36\x6b\xa5\xa8\xfc\x30\x39\x68\x35\x26\x4e\xa4\x8f\x32\x54\x69\x81\xdc\xb5\x7a\xe1\x1a\x67\x7b\x17\x34\x01\x47\x8c\x9f\x8a\x39\x92\xbf\x0a\xfe\x84\x4e\x35\xd1\xcf\x84\x90\x47\x5f\x6b\xdf\x33\x7f\xef\x46\xa7\x5d\x9e\xd6\x85\x84\x9f\x48\x92\x1e\x7d\x4e\x9c\xd0\xc8\x2d\x74\x4b\xc4\x7a\x0a\x54\x8c\x1e\x58\xb9\xf4\xc8\x4a\x05\x9f\x82\xce\x83\xf4\xd8\xb9\x4f\x0f\x7e\xe6\x64\xd2\x8e\xfe\x79\x8c\x73\x9b\x84\x81\x77\xce\xf4\xd6\x72\xee\xe1\x1f\xfb\xde\x7c\x52\xca\xea\x7e\x6d\xc8\x5a\xcf\xe8\xe1\x04\x16\x2d\x3f\x9d\x4f\xec\xaf\x6a\x6e\xcb\x46\x77\x5b\x30\xb0\x98\x9f\x1a\x88\xf5\x72\x3a\xff\x04\x1b\x91\x52\x0a\x66\xb7\xb6\x2c\x41\xd4\x11\x26\xc8\x26\xa0\xa7\x30\x64\x05\x39\x58\x24\x13\xf1\x23\xd9\x66\x59\xe8\x16\xff\xf8\x16\x75\xdd\xdd\x91\x2d\xa6\xa1\x90\x8c\x86\xa3\xb7\xfd\x76\x20\x89\x48\x70\xf8\x9f\x6a\x22\xc7\x6f\xd5\x12\xa7\xf3\xa5\xed\xac\x70\x89\xab\xc5\xb9\xa1\x6e\x9c\x9e\xe9\xb0\xc1\x98\xb2\x98\x61\x43\xcf\x2e\x5a\x01\x3d\x78\xd1\x7a\xd7\x1c\xac\xe1\x50\x73\xb4\x86\x47\xea\x59\x1f\x90\x85\x4f\x77\xc0\x86\xcd\x43\xe2\xf4\x11\x1b\x76\x8e\x06\xcd\x21\x7b\x26\xf8\x42\x5f\x09\xa5\xa1\xa4\x83\xfc\x74\x13\x6c\x4a\x6a\x5a\x8e\x63\xdd\x7f\x0b\x87\xb9\x3c\xf4\xa1\x78\xf2\x1e\x7a\xbd\x6f\xaf\x1e\x64\x56\xe1\xf9\x9d\xc4\x71\x9d\xe8\x95\x89\x2c\xea\x24\x2a\x69\x91\x79\x4a\x49\xea\x7a\xb9\xfd\xf9\x59\xe3\x2a\xab\xdc\x19\x16\xe1\xe2\xbb\x2a\xa4\x18\xe8\x1a\x8b\x32\x3b\x96\x72\x51\x95\xc8\xb5\x49\xd6\xc8\xa5\xe8\x01\x16\xce\x04\xaf\xb8\x78\xeb\x68\xb4\x51\xf5\xbd\x45\xf8\x1f\x21\x8d\x6b\xa4\x72\x11\xe4\xa7\xdc\xd7\xae\xe0\xe5\x7d\xd0\xb5\x16\xc5\x03\xd2\xa8\x5d\xc8\xa0\x2c\x5e\xa8\xde\x13\xeb\x28\x13\xfe\x0c\x6b\xdc\xc0\x6a\x4d\x44\x2d\x8d\x6a\x0d\xf5\xeb\x51\x50\xb7\x6d\xbb\x46\x3d\x6a\x7b\x54\x34\x76\xfd\xa5\xb4\xb8\x82\x84\xe8\xbd\x71\x16\xcb\x25\xfc\xbd\x98\xce\xc1\xfc\xd7\x9a\xad\x6d\xbc\x96\xbf\x1e\xa9\xdb\x76\x1f\xcc\xf7\xd5\xd3\xeb\xcb\xea\xf1\xdd\xe5\x9b\xea\xf9\x72\xf4\xe6\x5d\xfe\xd2\x93\x37\xfa\xd4\x6c\x68\x4c\xf3\xe6\xda\x52\xa7\x23\x6d\xd1\xe9\x0a\x8c\x95\x10\x0e\x1a\x3f\xbc\x26\xf1\x8f\xc8\x30\x26\xce\xe2\xee\xec\x75\xe7\xea\x59\x3f\xcf\x14\xcc\xd1\x2f\xa6\xbe\xce\xd3\xd9\x06\x7d\x66\x45\xf9\x95\xf1\x0b\x00\xaf\xc5\xc9\x2d\x0e\x00\x00")
+
+func migrations72_trade_aggregation_rollupsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations72_trade_aggregation_rollupsSql,
+		"migrations/72_trade_aggregation_rollups.sql",
+	)
+}
+
+func migrations72_trade_aggregation_rollupsSql() (*asset, error) {
+	bytes, err := migrations72_trade_aggregation_rollupsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/72_trade_aggregation_rollups.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x30, 0x99, 0x19, 0x9d, 0x6d, 0x24, 0xc8, 0x3c, 0xca, 0x4a, 0x8d, 0xad, 0x78, 0x5, 0xf1, 0xfb, 0xa0, 0xd9, 0x9c, 0xa8, 0x2c, 0x0, 0xc6, 0xbb, 0x8f, 0x5b, 0x8c, 0x83, 0x6, 0x36, 0x60, 0xcf}}
+	return a, nil
+}
+
 var _migrations7_modify_trades_tableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xc4\x54\x4d\x8f\xda\x30\x14\xbc\xe7\x57\x3c\xed\x29\x51\xc3\xaa\xad\xda\xbd\x6c\x55\x09\x58\x97\x46\x65\xc3\x36\x04\xa9\xb7\xc8\x89\xdf\x06\xab\xc1\x8e\x6c\xa7\x88\x7f\x5f\x05\x08\xcd\x27\xb0\xbb\x87\x5e\x93\x99\x79\x6f\xec\xf1\x8c\x46\xf0\x6e\xc3\x53\x45\x0d\xc2\x2a\xb7\x46\x23\x60\x4a\xe6\x60\xd6\x08\x32\x63\x60\x14\x65\xa8\xc1\xd0\x38\xc3\x5b\xc8\x0b\x03\x14\x04\x6e\x41\x0a\x04\x2e\x20\xcf\x68\x82\xd6\x43\xb0\x78\x82\x70\x3c\x99\x13\x58\x73\x6d\xa4\xda\x45\x07\xde\xbd\x35\x0d\xc8\x38\x24\xbd\x3f\xc1\xb6\x00\xe0\xf4\x51\xe6\xa8\xa8\xe1\x52\x44\x9c\xc1\xc4\x9b\x79\x7e\x08\xfe\x22\x04\x7f\x35\x9f\xbb\x7b\xe4\x8d\x54\x0c\xd5\x0d\x78\x7e\x48\x66\x24\x68\xfd\xcd\x90\xa5\xa8\xa2\x24\x93\x1a\x59\x44\x0d\x84\xde\x23\x59\x86\xe3\xc7\xa7\x16\x50\x3e\x3f\xa3\x1a\x1c\x12\x53\x8d\x11\x4d\x12\x59\x08\xd3\x03\x82\x80\x7c\x23\x01\xf1\xa7\x64\x79\xda\xfc\x88\xd6\x36\x67\x4e\x5d\x44\x6b\xbc\x5a\xa2\xc4\x76\x04\x36\xa5\x6c\x87\x3e\xfd\x4e\xa6\x3f\xc0\xae\x43\xbe\xc2\xfb\x23\x71\xbf\x09\xaa\x37\x3b\x38\xe9\xbc\xc1\xc4\x49\xe3\xac\x8f\x16\xea\x9f\x95\xbd\x41\xae\x23\x8d\x59\x86\x0a\x26\x8b\xc5\x9c\x8c\xfd\xc3\xbf\x3d\xd7\x6e\x1e\xf3\x97\xce\xd2\x8e\xe5\xdc\x5b\x55\x04\x57\xbe\xf7\x73\x45\xc0\xf3\x1f\xc8\x2f\x58\x1b\xc5\xa2\x9c\x33\x58\xf8\xed\x54\xae\x96\x9e\x3f\x83\xd8\x28\x44\xb0\xfb\xc2\xe9\x56\x41\x74\x4e\xf1\xae\x8b\x52\xae\x22\xc3\x37\x18\x65\x52\xfe\x2e\xf2\xc1\x09\x93\x30\x20\xa4\x69\xc1\xed\x38\x70\x3b\xb1\xee\x1d\x5a\xd1\xae\x1a\xd9\x39\xa5\x3e\xc5\xeb\x1d\x5c\xb5\x60\xbc\x8b\xf6\xcf\xee\xd2\x79\x57\x6f\xb3\xbc\x37\xab\x5e\x4d\x0f\x72\x2b\x1a\xe5\x24\x70\x8b\xaa\xea\x25\x85\x5c\x68\x53\xe2\xaa\xde\x92\x02\x6f\x87\x7b\x09\x12\xaa\x13\xca\xf0\xd5\xfd\x14\xf3\x94\x0b\x33\xd0\x4f\x5c\x18\x4c\x51\x0d\xd5\x4e\x2f\xf7\x10\xf2\xc1\xdf\x71\xb1\x3b\x47\x96\x19\x3b\x5e\xa7\xd9\xe5\x08\xc9\x9a\x2a\x9a\x18\x54\xf0\x87\xaa\x1d\x17\xa9\x7d\xf7\xc9\x19\xe6\x70\xad\x0b\x54\x3d\xac\xcf\x77\x67\x58\x89\x64\x7d\x93\x3e\x7c\xec\xe7\x1c\x5e\x77\x6b\xfd\xaa\x03\xea\x90\x5a\x01\xc8\x22\x5d\x9b\x97\x1a\x6b\xb0\x5e\x60\xad\xc1\xbb\xda\x5c\xc5\x3a\x6b\xaf\x09\x2a\x0d\xfe\x87\x62\x7a\xc5\x13\x6c\x8b\x94\x1a\xe5\x55\x5d\x92\x68\xe5\xd1\x6d\xc7\xc6\xed\xa6\x6f\x60\xda\xe1\xe4\x2e\xcd\xeb\x04\xc5\xed\xde\xa6\xdb\x17\x0c\xe7\xfe\x6f\x00\x00\x00\xff\xff\x2a\xff\xe8\x4a\xff\x08\x00\x00")
 
 func migrations7_modify_trades_tableSqlBytes() ([]byte, error) {
@@ -1672,7 +1777,12 @@ var _bindata = map[string]func() (*asset, error){
 	"migrations/65_drop_payment_index.sql":                               migrations65_drop_payment_indexSql,
 	"migrations/66_contract_asset_stats.sql":                             migrations66_contract_asset_statsSql,
 	"migrations/67_remove_unused_indexes.sql":                            migrations67_remove_unused_indexesSql,
+	"migrations/68_contract_events_and_data.sql":                         migrations68_contract_events_and_dataSql,
+	"migrations/69_webhooks.sql":                                         migrations69_webhooksSql,
 	"migrations/6_create_assets_table.sql":                               migrations6_create_assets_tableSql,
+	"migrations/70_state_history.sql":                                    migrations70_state_historySql,
+	"migrations/71_api_keys.sql":                                         migrations71_api_keysSql,
+	"migrations/72_trade_aggregation_rollups.sql":                        migrations72_trade_aggregation_rollupsSql,
 	"migrations/7_modify_trades_table.sql":                               migrations7_modify_trades_tableSql,
 	"migrations/8_add_aggregators.sql":                                   migrations8_add_aggregatorsSql,
 	"migrations/8_create_asset_stats_table.sql":                          migrations8_create_asset_stats_tableSql,
@@ -1787,7 +1897,12 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"65_drop_payment_index.sql":                               {migrations65_drop_payment_indexSql, map[string]*bintree{}},
 		"66_contract_asset_stats.sql":                             {migrations66_contract_asset_statsSql, map[string]*bintree{}},
 		"67_remove_unused_indexes.sql":                            {migrations67_remove_unused_indexesSql, map[string]*bintree{}},
+		"68_contract_events_and_data.sql":                         {migrations68_contract_events_and_dataSql, map[string]*bintree{}},
+		"69_webhooks.sql":                                         {migrations69_webhooksSql, map[string]*bintree{}},
 		"6_create_assets_table.sql":                               {migrations6_create_assets_tableSql, map[string]*bintree{}},
+		"70_state_history.sql":                                    {migrations70_state_historySql, map[string]*bintree{}},
+		"71_api_keys.sql":                                         {migrations71_api_keysSql, map[string]*bintree{}},
+		"72_trade_aggregation_rollups.sql":                        {migrations72_trade_aggregation_rollupsSql, map[string]*bintree{}},
 		"7_modify_trades_table.sql":                               {migrations7_modify_trades_tableSql, map[string]*bintree{}},
 		"8_add_aggregators.sql":                                   {migrations8_add_aggregatorsSql, map[string]*bintree{}},
 		"8_create_asset_stats_table.sql":                          {migrations8_create_asset_stats_tableSql, map[string]*bintree{}},
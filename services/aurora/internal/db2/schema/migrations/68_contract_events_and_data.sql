@@ -0,0 +1,26 @@
+-- +migrate Up
+CREATE TABLE history_contract_events (
+     history_operation_id bigint NOT NULL,
+     "order" integer NOT NULL,
+     contract_id text NOT NULL,
+     topics text[] NOT NULL, -- base64 encoded ScVal XDR
+     value text NOT NULL, -- base64 encoded ScVal XDR
+     PRIMARY KEY (history_operation_id, "order")
+);
+
+CREATE INDEX "history_contract_events_by_contract_id" ON history_contract_events USING btree (contract_id, history_operation_id, "order");
+
+CREATE TABLE contract_data (
+     key_hash text PRIMARY KEY, -- hex encoded sha256 hash of the ledger key
+     contract_id text NOT NULL,
+     durability integer NOT NULL,
+     key text NOT NULL, -- base64 encoded ScVal XDR
+     value text NOT NULL, -- base64 encoded ScVal XDR
+     last_modified_ledger integer NOT NULL
+);
+
+CREATE INDEX "contract_data_by_contract_id" ON contract_data USING btree (contract_id, key_hash);
+
+-- +migrate Down
+DROP TABLE history_contract_events cascade;
+DROP TABLE contract_data cascade;
@@ -0,0 +1,46 @@
+-- +migrate Up
+CREATE TABLE webhook_subscriptions (
+     id bigserial PRIMARY KEY,
+     url text NOT NULL,
+     secret text NOT NULL,
+     account_id text,
+     asset text, -- canonical asset string, ex. native or USD:GABC...
+     liquidity_pool_id text,
+     created_at timestamp without time zone NOT NULL,
+     CONSTRAINT webhook_subscriptions_single_filter CHECK (
+          (account_id IS NOT NULL)::integer +
+          (asset IS NOT NULL)::integer +
+          (liquidity_pool_id IS NOT NULL)::integer = 1
+     )
+);
+
+CREATE TABLE webhook_deliveries (
+     id bigserial PRIMARY KEY,
+     subscription_id bigint NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+     ledger_sequence integer NOT NULL,
+     payload text NOT NULL, -- JSON document POSTed to the subscription url
+     attempts integer NOT NULL DEFAULT 0,
+     next_attempt_at timestamp without time zone NOT NULL,
+     last_error text NOT NULL DEFAULT '',
+     created_at timestamp without time zone NOT NULL
+);
+
+CREATE INDEX "webhook_deliveries_by_next_attempt_at" ON webhook_deliveries USING btree (next_attempt_at);
+
+CREATE TABLE webhook_dead_letters (
+     id bigserial PRIMARY KEY,
+     subscription_id bigint NOT NULL,
+     url text NOT NULL,
+     ledger_sequence integer NOT NULL,
+     payload text NOT NULL,
+     attempts integer NOT NULL,
+     last_error text NOT NULL,
+     created_at timestamp without time zone NOT NULL
+);
+
+CREATE INDEX "webhook_dead_letters_by_subscription_id" ON webhook_dead_letters USING btree (subscription_id, id);
+
+-- +migrate Down
+DROP TABLE webhook_dead_letters cascade;
+DROP TABLE webhook_deliveries cascade;
+DROP TABLE webhook_subscriptions cascade;
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE history_state_entries (
+     ledger_key text NOT NULL, -- base64 encoded xdr.LedgerKey
+     ledger_sequence integer NOT NULL,
+     entry_type smallint NOT NULL,
+     accounts text[] NOT NULL, -- accounts the entry can be looked up by, ex. owner, claimants and sponsor
+     offer_id bigint, -- set for offers which can't be looked up by key without the seller
+     ledger_entry text, -- base64 encoded xdr.LedgerEntry, NULL when the entry was removed
+     PRIMARY KEY (ledger_key, ledger_sequence)
+);
+
+CREATE INDEX "history_state_entries_by_accounts" ON history_state_entries USING gin (accounts);
+CREATE INDEX "history_state_entries_by_offer_id" ON history_state_entries USING btree (offer_id) WHERE offer_id IS NOT NULL;
+CREATE INDEX "history_state_entries_by_ledger_sequence" ON history_state_entries USING btree (ledger_sequence);
+
+-- +migrate Down
+DROP TABLE history_state_entries cascade;
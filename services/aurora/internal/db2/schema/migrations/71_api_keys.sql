@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE api_keys (
+     id bigserial PRIMARY KEY,
+     name text NOT NULL UNIQUE,
+     key_hash text NOT NULL UNIQUE, -- hex encoded sha256 hash of the key
+     -- requests per hour for each endpoint class, NULL means unlimited
+     state_quota integer,
+     history_quota integer,
+     streams_quota integer,
+     paths_quota integer,
+     submission_quota integer,
+     created_at timestamp without time zone NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE api_keys cascade;
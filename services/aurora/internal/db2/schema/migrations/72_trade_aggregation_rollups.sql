@@ -0,0 +1,101 @@
+-- +migrate Up
+
+ALTER TABLE history_trades_60000
+  ADD COLUMN pool_count integer not null default 0,
+  ADD COLUMN pool_base_volume numeric not null default 0,
+  ADD COLUMN pool_counter_volume numeric not null default 0;
+
+-- Backfill the liquidity pool volumes, using the default rounding slippage
+-- filter of 1000 bips.
+WITH pool_trades AS (
+  SELECT
+    to_millis(ledger_closed_at, 60000) as timestamp,
+    base_asset_id,
+    counter_asset_id,
+    count(*) as count,
+    sum(base_amount) as base_volume,
+    sum(counter_amount) as counter_volume
+  FROM history_trades
+  WHERE trade_type = 2 AND coalesce(rounding_slippage, 0) < 1000
+  GROUP BY base_asset_id, counter_asset_id, timestamp
+)
+UPDATE history_trades_60000 htrd SET
+  pool_count = pool_trades.count,
+  pool_base_volume = pool_trades.base_volume,
+  pool_counter_volume = pool_trades.counter_volume
+FROM pool_trades
+WHERE htrd.base_asset_id = pool_trades.base_asset_id
+  AND htrd.counter_asset_id = pool_trades.counter_asset_id
+  AND htrd.timestamp = pool_trades.timestamp;
+
+-- history_trades_rollups contains the buckets of history_trades_60000 rolled
+-- up to the coarser resolutions allowed by /trade_aggregations.
+CREATE TABLE history_trades_rollups (
+  resolution bigint not null,
+  timestamp bigint not null,
+  base_asset_id bigint not null,
+  counter_asset_id bigint not null,
+  count integer not null,
+  base_volume numeric not null,
+  counter_volume numeric not null,
+  avg numeric not null,
+  high_n numeric not null,
+  high_d numeric not null,
+  low_n numeric not null,
+  low_d numeric not null,
+  open_ledger_toid bigint not null,
+  open_n numeric not null,
+  open_d numeric not null,
+  close_ledger_toid bigint not null,
+  close_n numeric not null,
+  close_d numeric not null,
+  pool_count integer not null,
+  pool_base_volume numeric not null,
+  pool_counter_volume numeric not null,
+
+  PRIMARY KEY(base_asset_id, counter_asset_id, resolution, timestamp)
+);
+
+CREATE INDEX htrd_rollups_counter_asset ON history_trades_rollups USING btree (counter_asset_id);
+
+alter table history_trades_rollups set (
+  autovacuum_vacuum_scale_factor = 0.05,
+  autovacuum_analyze_scale_factor = 0.025
+);
+
+INSERT INTO history_trades_rollups (
+  SELECT
+    r.resolution,
+    (m.timestamp / r.resolution) * r.resolution as timestamp,
+    m.base_asset_id,
+    m.counter_asset_id,
+    sum(m.count) as count,
+    sum(m.base_volume) as base_volume,
+    sum(m.counter_volume) as counter_volume,
+    sum(m.counter_volume)/sum(m.base_volume) as avg,
+    (max_price(ARRAY[m.high_n, m.high_d]))[1] as high_n,
+    (max_price(ARRAY[m.high_n, m.high_d]))[2] as high_d,
+    (min_price(ARRAY[m.low_n, m.low_d]))[1] as low_n,
+    (min_price(ARRAY[m.low_n, m.low_d]))[2] as low_d,
+    min(m.open_ledger_toid) as open_ledger_toid,
+    (first(ARRAY[m.open_n, m.open_d] ORDER BY m.timestamp))[1] as open_n,
+    (first(ARRAY[m.open_n, m.open_d] ORDER BY m.timestamp))[2] as open_d,
+    max(m.close_ledger_toid) as close_ledger_toid,
+    (last(ARRAY[m.close_n, m.close_d] ORDER BY m.timestamp))[1] as close_n,
+    (last(ARRAY[m.close_n, m.close_d] ORDER BY m.timestamp))[2] as close_d,
+    sum(m.pool_count) as pool_count,
+    sum(m.pool_base_volume) as pool_base_volume,
+    sum(m.pool_counter_volume) as pool_counter_volume
+  FROM history_trades_60000 m
+  CROSS JOIN (VALUES (300000), (900000), (3600000), (86400000), (604800000)) AS r(resolution)
+  GROUP BY r.resolution, m.base_asset_id, m.counter_asset_id, 2
+);
+
+-- +migrate Down
+
+DROP TABLE history_trades_rollups;
+
+ALTER TABLE history_trades_60000
+  DROP COLUMN pool_count,
+  DROP COLUMN pool_base_volume,
+  DROP COLUMN pool_counter_volume;
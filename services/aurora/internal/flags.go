@@ -17,6 +17,8 @@ import (
 	"github.com/hcnet/go/ingest/ledgerbackend"
 	"github.com/hcnet/go/network"
 	"github.com/hcnet/go/services/aurora/internal/db2/schema"
+	"github.com/hcnet/go/services/aurora/internal/gql"
+	"github.com/hcnet/go/services/aurora/internal/ssehub"
 	apkg "github.com/hcnet/go/support/app"
 	support "github.com/hcnet/go/support/config"
 	"github.com/hcnet/go/support/db"
@@ -57,6 +59,18 @@ const (
 	EnableIngestionFilteringFlagName = "exp-enable-ingestion-filtering"
 	// DisableTxSubFlagName is the command line flag for disabling transaction submission feature of Aurora
 	DisableTxSubFlagName = "disable-tx-sub"
+	// EnableWebhooksFlagName is the command line flag for enabling the webhook delivery subsystem of Aurora
+	EnableWebhooksFlagName = "enable-webhooks"
+	// EnableStateHistoryFlagName is the command line flag for recording the state history used by `at_ledger` queries
+	EnableStateHistoryFlagName = "enable-state-history"
+	// EnableAPIKeysFlagName is the command line flag for enabling the per API key quotas of Aurora
+	EnableAPIKeysFlagName = "enable-api-keys"
+	// EnableGraphQLFlagName is the command line flag for enabling the GraphQL endpoint
+	EnableGraphQLFlagName = "enable-graphql"
+	// EnableSSEHubFlagName is the command line flag for sharing the queries of the history streams
+	EnableSSEHubFlagName = "enable-sse-hub"
+	// IngestionSinkFlagName is the command line flag for the sink receiving the data of the ingested ledgers
+	IngestionSinkFlagName = "ingestion-sink"
 
 	// HcnetPubnet is a constant representing the Hcnet public network
 	HcnetPubnet = "pubnet"
@@ -190,6 +204,68 @@ func Flags() (*Config, support.ConfigOptions) {
 			Hidden:         false,
 			UsedInCommands: ApiServerCommands,
 		},
+		&support.ConfigOption{
+			Name:           EnableWebhooksFlagName,
+			OptType:        types.Bool,
+			FlagDefault:    false,
+			Required:       false,
+			Usage:          "enables the webhook delivery subsystem, webhook subscriptions are managed with the admin API (requires --admin-port).",
+			ConfigKey:      &config.EnableWebhooks,
+			UsedInCommands: ApiServerCommands,
+		},
+		&support.ConfigOption{
+			Name:           "webhook-max-attempts",
+			ConfigKey:      &config.WebhookMaxAttempts,
+			OptType:        types.Uint,
+			FlagDefault:    uint(10),
+			Usage:          "number of failed attempts after which a webhook delivery is moved to the dead letter table",
+			UsedInCommands: ApiServerCommands,
+		},
+		&support.ConfigOption{
+			Name:           EnableAPIKeysFlagName,
+			OptType:        types.Bool,
+			FlagDefault:    false,
+			Required:       false,
+			Usage:          "enables per API key quotas, requests made with a key in the X-API-Key header or the api_key parameter are charged to the quotas of the key instead of the rate limit of the client IP address, keys are managed with the admin API (requires --admin-port).",
+			ConfigKey:      &config.EnableAPIKeys,
+			UsedInCommands: ApiServerCommands,
+		},
+		&support.ConfigOption{
+			Name:           EnableGraphQLFlagName,
+			OptType:        types.Bool,
+			FlagDefault:    false,
+			Required:       false,
+			Usage:          "enables the /graphql endpoint, a GraphQL API over the account, ledger and payment resources",
+			ConfigKey:      &config.EnableGraphQL,
+			UsedInCommands: ApiServerCommands,
+		},
+		&support.ConfigOption{
+			Name:        "graphql-max-query-cost",
+			ConfigKey:   &config.GraphQLMaxQueryCost,
+			OptType:     types.Uint,
+			FlagDefault: uint(gql.DefaultMaxQueryCost),
+			Usage: "maximum cost of a GraphQL query, every field loading a page of up to 10 records from the database" +
+				" costs one unit which is also charged to the rate limit of the client, 0 means unlimited",
+			UsedInCommands: ApiServerCommands,
+		},
+		&support.ConfigOption{
+			Name:        EnableSSEHubFlagName,
+			OptType:     types.Bool,
+			FlagDefault: false,
+			Required:    false,
+			Usage: "loads the operations, transactions, effects and trades of every new ledger once for all the" +
+				" streams of those resources instead of once per stream, and replays the latest ledgers to reconnecting streams from memory",
+			ConfigKey:      &config.EnableSSEHub,
+			UsedInCommands: ApiServerCommands,
+		},
+		&support.ConfigOption{
+			Name:           "sse-replay-buffer-ledgers",
+			ConfigKey:      &config.SSEReplayBufferLedgers,
+			OptType:        types.Uint,
+			FlagDefault:    uint(ssehub.DefaultConfig.BufferLedgers),
+			Usage:          "number of ledgers kept in memory by the SSE hub to be replayed to streams (see --" + EnableSSEHubFlagName + ")",
+			UsedInCommands: ApiServerCommands,
+		},
 		&support.ConfigOption{
 			Name:        captiveCoreConfigAppendPathName,
 			OptType:     types.String,
@@ -542,6 +618,16 @@ func Flags() (*Config, support.ConfigOptions) {
 				" A value of zero (the default) disables the limit.",
 			UsedInCommands: ApiServerCommands,
 		},
+		&support.ConfigOption{
+			Name:        "orderbook-snapshot-path",
+			ConfigKey:   &config.OrderBookSnapshotPath,
+			OptType:     types.String,
+			FlagDefault: "",
+			Required:    false,
+			Usage: "file where the path finding order book is persisted, aurora loads it on startup" +
+				" and catches up from its ledger instead of rebuilding the order book from the database",
+			UsedInCommands: ApiServerCommands,
+		},
 		&support.ConfigOption{
 			Name:           NetworkPassphraseFlagName,
 			ConfigKey:      &config.NetworkPassphrase,
@@ -609,6 +695,22 @@ func Flags() (*Config, support.ConfigOptions) {
 			Usage:          "the minimum number of ledgers to maintain within aurora's history tables.  0 signifies an unlimited number of ledgers will be retained",
 			UsedInCommands: IngestionCommands,
 		},
+		&support.ConfigOption{
+			Name:           EnableStateHistoryFlagName,
+			ConfigKey:      &config.EnableStateHistory,
+			OptType:        types.Bool,
+			FlagDefault:    false,
+			Usage:          "records the versions of accounts, trustlines, offers, data, claimable balances and liquidity pools so they can be queried at past ledgers with the at_ledger parameter. The state history starts at the next state rebuild (see `aurora ingest trigger-state-rebuild`) and is bounded by --history-retention-count",
+			UsedInCommands: IngestionCommands,
+		},
+		&support.ConfigOption{
+			Name:           IngestionSinkFlagName,
+			ConfigKey:      &config.IngestionSink,
+			OptType:        types.String,
+			FlagDefault:    "",
+			Usage:          "url of a sink receiving the operations, effects, trades and ledger entry changes of every ingested ledger, in addition to the database. file:///path appends a JSON line per ledger to a file or named pipe, http(s):// urls publish a message per ledger to a message broker HTTP endpoint. Ledgers can be delivered more than once, consumers should deduplicate them by sequence",
+			UsedInCommands: IngestionCommands,
+		},
 		&support.ConfigOption{
 			Name:           "history-stale-threshold",
 			ConfigKey:      &config.StaleThreshold,
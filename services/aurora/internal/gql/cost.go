@@ -0,0 +1,92 @@
+package gql
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/stellar/throttled"
+
+	"github.com/hcnet/go/services/aurora/internal/apikeys"
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/services/aurora/internal/render/sse"
+	"github.com/hcnet/go/support/errors"
+)
+
+type ctxKey string
+
+var costContextKey = ctxKey("query_cost")
+
+// queryCost tracks the cost of a GraphQL request. Every field which queries
+// the database costs one unit per page of db2.DefaultPageSize records. Units
+// are charged to the rate limiter of the client, or to the quota of its API
+// key, on top of the token taken for the HTTP request, so a single query
+// cannot be used to work around the rate limit.
+type queryCost struct {
+	lock        sync.Mutex
+	spent       uint
+	limit       uint
+	rateLimiter *throttled.HTTPRateLimiter
+	key         string
+	apiKey      *apikeys.Key
+	class       apikeys.Class
+}
+
+func newQueryCost(r *http.Request, limit uint, rateLimiter *throttled.HTTPRateLimiter) *queryCost {
+	cost := &queryCost{limit: limit, rateLimiter: rateLimiter}
+	if apiKey, ok := apikeys.FromContext(r.Context()); ok {
+		cost.apiKey = apiKey
+		cost.class = apikeys.Classify(r)
+	} else if rateLimiter != nil {
+		cost.key = rateLimiter.VaryBy.Key(r)
+	}
+	return cost
+}
+
+func withQueryCost(ctx context.Context, cost *queryCost) context.Context {
+	return context.WithValue(ctx, costContextKey, cost)
+}
+
+// pageCost returns the cost of loading a page of the given size.
+func pageCost(limit uint64) uint {
+	return uint((limit + db2.DefaultPageSize - 1) / db2.DefaultPageSize)
+}
+
+// charge adds units to the cost of the request in ctx. It returns an error
+// if the request exceeds its maximum cost or the rate limit of the client.
+func charge(ctx context.Context, units uint) error {
+	cost, ok := ctx.Value(costContextKey).(*queryCost)
+	if !ok {
+		return errors.New("missing query cost in context")
+	}
+
+	cost.lock.Lock()
+	cost.spent += units
+	exceeded := cost.limit > 0 && cost.spent > cost.limit
+	cost.lock.Unlock()
+	if exceeded {
+		return errors.Errorf("query cost exceeds the maximum of %d", cost.limit)
+	}
+
+	return cost.rateLimit(units)
+}
+
+// rateLimit charges units to the rate limiter without counting them towards
+// the maximum cost of the request. It is used by subscriptions which run for
+// an unbounded number of ledgers.
+func (c *queryCost) rateLimit(units uint) error {
+	var limited bool
+	var err error
+	if c.apiKey != nil {
+		limited, _, err = c.apiKey.RateLimit(c.class, int(units))
+	} else if c.rateLimiter != nil {
+		limited, _, err = c.rateLimiter.RateLimiter.RateLimit(c.key, int(units))
+	}
+	if err != nil {
+		return errors.Wrap(err, "RateLimiter error")
+	}
+	if limited {
+		return sse.ErrRateLimited
+	}
+	return nil
+}
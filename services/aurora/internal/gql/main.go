@@ -0,0 +1,152 @@
+// Package gql serves a GraphQL API over the Aurora resources. Queries resolve
+// against the same history.Q queries and resource adapters as the REST
+// endpoints, so clients can join an account with its offers, payments and
+// claimable balances in a single request. Subscriptions are streamed with
+// Server Sent Events and follow the ingested ledgers like the streaming REST
+// endpoints.
+package gql
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/stellar/throttled"
+
+	"github.com/hcnet/go/services/aurora/internal/render"
+	hProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/services/aurora/internal/render/sse"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/problem"
+)
+
+//go:embed schema.graphql
+var schema string
+
+const (
+	// maxQueryDepth is the maximum nesting of the fields of a query.
+	maxQueryDepth = 10
+	// DefaultMaxQueryCost is the default maximum cost of a query.
+	DefaultMaxQueryCost = 100
+)
+
+// HandlerConfig configures the GraphQL handler.
+type HandlerConfig struct {
+	// LedgerSourceFactory creates the ledger sources followed by
+	// subscriptions.
+	LedgerSourceFactory sse.LedgerSourceFactory
+	// RateLimiter is charged with the cost of every query. It is optional.
+	RateLimiter *throttled.HTTPRateLimiter
+	// MaxQueryCost is the maximum cost of a query, 0 means unlimited.
+	MaxQueryCost uint
+}
+
+// Handler is the http handler of the GraphQL endpoint. Queries are accepted as
+// a JSON body in POST requests, or in the query, operationName and variables
+// parameters of GET requests. Subscriptions require the text/event-stream
+// Accept header.
+type Handler struct {
+	schema *graphql.Schema
+	config HandlerConfig
+}
+
+// NewHandler returns a GraphQL handler. It panics if the schema does not
+// match the resolvers.
+func NewHandler(config HandlerConfig) *Handler {
+	return &Handler{
+		schema: graphql.MustParseSchema(
+			schema,
+			&resolver{ledgerSourceFactory: config.LedgerSourceFactory},
+			graphql.UseFieldResolvers(),
+			graphql.MaxDepth(maxQueryDepth),
+			// All the resolvers of a request share its database session
+			// which does not support concurrent queries.
+			graphql.MaxParallelism(1),
+		),
+		config: config,
+	}
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func parseRequest(r *http.Request) (request, error) {
+	var params request
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		params.Query = query.Get("query")
+		params.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &params.Variables); err != nil {
+				return params, problem.MakeInvalidFieldProblem("variables", err)
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			return params, problem.MakeInvalidFieldProblem("body", err)
+		}
+	}
+	if params.Query == "" {
+		return params, problem.MakeInvalidFieldProblem("query", errors.New("query is required"))
+	}
+	return params, nil
+}
+
+// ServeHTTP executes the GraphQL request.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	params, err := parseRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	ctx := withQueryCost(r.Context(), newQueryCost(r, h.config.MaxQueryCost, h.config.RateLimiter))
+
+	switch render.Negotiate(r) {
+	case render.MimeHal, render.MimeJSON:
+		response := h.schema.Exec(ctx, params.Query, params.OperationName, params.Variables)
+		for _, responseErr := range response.Errors {
+			// the default message refers to a websocket protocol we do not
+			// support
+			if responseErr.Message == "graphql-ws protocol header is missing" {
+				responseErr.Message = "subscriptions require the text/event-stream Accept header"
+			}
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			problem.Render(ctx, w, err)
+		}
+	case render.MimeEventStream:
+		h.serveStream(w, r.WithContext(ctx), params)
+	default:
+		problem.Render(ctx, w, hProblem.NotAcceptable)
+	}
+}
+
+// serveStream sends every response of a subscription as an event. Queries are
+// sent as a single event.
+func (h *Handler) serveStream(w http.ResponseWriter, r *http.Request, params request) {
+	subscriptionErr := &subscriptionError{}
+	ctx := withSubscriptionError(r.Context(), subscriptionErr)
+	stream := sse.NewStream(ctx, w)
+
+	responses, err := h.schema.Subscribe(ctx, params.Query, params.OperationName, params.Variables)
+	if err != nil {
+		stream.Err(err)
+		return
+	}
+	stream.Init()
+	for response := range responses {
+		stream.Send(sse.Event{Data: response})
+	}
+
+	if err := subscriptionErr.get(); err != nil {
+		stream.Err(err)
+		return
+	}
+	stream.Done()
+}
@@ -0,0 +1,228 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stellar/throttled"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/services/aurora/internal/apikeys"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/ledger"
+	"github.com/hcnet/go/services/aurora/internal/render/sse"
+	"github.com/hcnet/go/support/db"
+)
+
+type testingFactory struct {
+	ledgerSource ledger.Source
+}
+
+func (f *testingFactory) Get() ledger.Source {
+	return f.ledgerSource
+}
+
+func makeRequest(method, body string, session db.SessionInterface) *http.Request {
+	var request *http.Request
+	if method == http.MethodGet {
+		request = httptest.NewRequest(method, "/graphql?query="+url.QueryEscape(body), nil)
+	} else {
+		request = httptest.NewRequest(method, "/graphql", strings.NewReader(body))
+	}
+	ctx := context.WithValue(context.Background(), &auroraContext.SessionContextKey, session)
+	return request.WithContext(ctx)
+}
+
+type response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message string        `json:"message"`
+		Path    []interface{} `json:"path"`
+	} `json:"errors"`
+}
+
+func TestInvalidRequests(t *testing.T) {
+	handler := NewHandler(HandlerConfig{LedgerSourceFactory: &testingFactory{ledger.NewTestingSource(1)}})
+
+	for _, testCase := range []struct {
+		name    string
+		request *http.Request
+		status  int
+	}{
+		{"missing query", makeRequest(http.MethodGet, "", &db.MockSession{}), http.StatusBadRequest},
+		{"invalid body", makeRequest(http.MethodPost, "{", &db.MockSession{}), http.StatusBadRequest},
+		{"invalid variables", func() *http.Request {
+			r := makeRequest(http.MethodGet, "{ ledger(sequence: 1) { hash } }", &db.MockSession{})
+			r.URL.RawQuery += "&variables=" + url.QueryEscape("[")
+			return r
+		}(), http.StatusBadRequest},
+		{"not acceptable", func() *http.Request {
+			r := makeRequest(http.MethodPost, `{"query": "{ ledger(sequence: 1) { hash } }"}`, &db.MockSession{})
+			r.Header.Set("Accept", "text/plain")
+			return r
+		}(), http.StatusNotAcceptable},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, testCase.request)
+			assert.Equal(t, testCase.status, w.Code)
+		})
+	}
+
+	// validation errors are reported in the GraphQL response
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, makeRequest(http.MethodPost, `{"query": "{ ledger { hash } }"}`, &db.MockSession{}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var result response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, `argument "sequence" of type "Int!" is required`)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, makeRequest(http.MethodPost, `{"query": "subscription { ledgers { hash } }"}`, &db.MockSession{}))
+	result = response{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "subscriptions require the text/event-stream Accept header", result.Errors[0].Message)
+}
+
+func TestLedgerNotFound(t *testing.T) {
+	handler := NewHandler(HandlerConfig{LedgerSourceFactory: &testingFactory{ledger.NewTestingSource(1)}})
+	session := &db.MockSession{}
+	session.On("Get", mock.Anything, mock.AnythingOfType("*history.Ledger"), mock.Anything).
+		Return(sql.ErrNoRows).Once()
+	session.On("NoRows", sql.ErrNoRows).Return(true).Once()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, makeRequest(http.MethodGet, "{ ledger(sequence: 7) { hash } }", session))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": {"ledger": null}}`, w.Body.String())
+	session.AssertExpectations(t)
+}
+
+func TestMaxQueryCost(t *testing.T) {
+	handler := NewHandler(HandlerConfig{
+		LedgerSourceFactory: &testingFactory{ledger.NewTestingSource(1)},
+		MaxQueryCost:        1,
+	})
+	session := &db.MockSession{}
+	session.On("Get", mock.Anything, mock.AnythingOfType("*history.Ledger"), mock.Anything).
+		Return(sql.ErrNoRows).Once()
+	session.On("NoRows", sql.ErrNoRows).Return(true).Once()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, makeRequest(
+		http.MethodPost,
+		`{"query": "{ a: ledger(sequence: 7) { hash } b: ledger(sequence: 8) { hash } }"}`,
+		session,
+	))
+	var result response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "query cost exceeds the maximum of 1", result.Errors[0].Message)
+	assert.Nil(t, result.Data["a"])
+	assert.Nil(t, result.Data["b"])
+	session.AssertExpectations(t)
+}
+
+func TestQueryCostRateLimit(t *testing.T) {
+	rateLimiter, err := throttled.NewGCRARateLimiter(10, throttled.RateQuota{
+		MaxRate:  throttled.PerHour(1),
+		MaxBurst: 4,
+	})
+	require.NoError(t, err)
+	httpRateLimiter := &throttled.HTTPRateLimiter{
+		RateLimiter: rateLimiter,
+		VaryBy:      &throttled.VaryBy{RemoteAddr: true},
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	ctx := withQueryCost(context.Background(), newQueryCost(r, 0, httpRateLimiter))
+	assert.NoError(t, charge(ctx, 3))
+	assert.NoError(t, charge(ctx, 2))
+	assert.Equal(t, sse.ErrRateLimited, charge(ctx, 1))
+
+	// the quota is tracked per client
+	r.RemoteAddr = "10.0.0.1:1234"
+	ctx = withQueryCost(context.Background(), newQueryCost(r, 0, httpRateLimiter))
+	assert.NoError(t, charge(ctx, 1))
+}
+
+func TestQueryCostAPIKey(t *testing.T) {
+	q := &history.MockQAPIKeys{}
+	q.On("GetAPIKeys", mock.Anything).Return([]history.APIKey{{
+		ID:         1,
+		Name:       "wallet",
+		KeyHash:    apikeys.HashKey("key"),
+		StateQuota: sql.NullInt64{Int64: 3, Valid: true},
+	}}, nil)
+	key, err := apikeys.NewLimiter(q, nil).Lookup(context.Background(), "key")
+	require.NoError(t, err)
+
+	// the cost is charged to the quota of the key instead of the client
+	r := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	r = r.WithContext(apikeys.NewContext(r.Context(), key))
+	ctx := withQueryCost(context.Background(), newQueryCost(r, 0, nil))
+	assert.NoError(t, charge(ctx, 2))
+	assert.Equal(t, sse.ErrRateLimited, charge(ctx, 2))
+}
+
+func TestPageCost(t *testing.T) {
+	assert.Equal(t, uint(1), pageCost(1))
+	assert.Equal(t, uint(1), pageCost(10))
+	assert.Equal(t, uint(2), pageCost(11))
+	assert.Equal(t, uint(20), pageCost(200))
+}
+
+func TestLedgersSubscription(t *testing.T) {
+	ledgerSource := ledger.NewTestingSource(10)
+	handler := NewHandler(HandlerConfig{LedgerSourceFactory: &testingFactory{ledgerSource}})
+
+	session := &db.MockSession{}
+	session.On("BeginTx", mock.Anything, mock.Anything).Return(nil)
+	session.On("Rollback").Return(nil)
+	for _, sequences := range [][]int32{{11, 12}, {13}, {14}} {
+		ledgers := make([]history.Ledger, len(sequences))
+		for i, sequence := range sequences {
+			ledgers[i] = history.Ledger{Sequence: sequence, LedgerHash: "hash"}
+		}
+		session.On("Select", mock.Anything, mock.AnythingOfType("*[]history.Ledger"), mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args.Get(1).(*[]history.Ledger) = ledgers
+			}).
+			Return(nil).Once()
+	}
+
+	request := makeRequest(http.MethodGet, "subscription { ledgers { sequence hash } }", session)
+	request.Header.Set("Accept", "text/event-stream")
+	ctx, cancel := context.WithCancel(request.Context())
+	w := httptest.NewRecorder()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		handler.ServeHTTP(w, request.WithContext(ctx))
+		wg.Done()
+	}()
+
+	// ledger 14 is only read once the events of ledgers 11 and 12 are sent
+	ledgerSource.AddLedger(12)
+	ledgerSource.AddLedger(13)
+	ledgerSource.AddLedger(14)
+	cancel()
+	wg.Wait()
+
+	body := w.Body.String()
+	assert.Contains(t, body, `data: {"data":{"ledgers":{"sequence":11,"hash":"hash"}}}`)
+	assert.Contains(t, body, `data: {"data":{"ledgers":{"sequence":12,"hash":"hash"}}}`)
+	assert.Contains(t, body, "event: close")
+}
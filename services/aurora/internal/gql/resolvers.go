@@ -0,0 +1,591 @@
+package gql
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+
+	protocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/protocols/aurora/base"
+	"github.com/hcnet/go/protocols/aurora/operations"
+	"github.com/hcnet/go/services/aurora/internal/actions"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/render/sse"
+	"github.com/hcnet/go/services/aurora/internal/resourceadapter"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/hal"
+	"github.com/hcnet/go/xdr"
+)
+
+// resolver is the root resolver of the schema. Request scoped values, like
+// the database session and the query cost, are read from the context.
+type resolver struct {
+	ledgerSourceFactory sse.LedgerSourceFactory
+}
+
+func historyQFromContext(ctx context.Context) (*history.Q, error) {
+	session, ok := ctx.Value(&auroraContext.SessionContextKey).(db.SessionInterface)
+	if !ok {
+		return nil, errors.New("missing session in request context")
+	}
+	return &history.Q{SessionInterface: session}, nil
+}
+
+// Account resolves the account with the given id.
+func (r *resolver) Account(ctx context.Context, args struct{ ID graphql.ID }) (*accountResolver, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	q, err := historyQFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := actions.AccountInfo(ctx, q, string(args.ID))
+	if q.NoRows(errors.Cause(err)) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &accountResolver{account: *account}, nil
+}
+
+// Ledger resolves the ledger with the given sequence.
+func (r *resolver) Ledger(ctx context.Context, args struct{ Sequence int32 }) (*ledgerResolver, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	q, err := historyQFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var row history.Ledger
+	err = q.LedgerBySequence(ctx, &row, args.Sequence)
+	if q.NoRows(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not load ledger")
+	}
+	return newLedgerResolver(ctx, row), nil
+}
+
+type pageInfo struct {
+	HasNextPage bool
+	EndCursor   *string
+}
+
+// connectionArgs are the arguments of the fields returning a connection.
+type connectionArgs struct {
+	First *int32
+	After *string
+	Order *string
+}
+
+// pageQuery returns the page query of a connection. The limit of the page is
+// one more than the requested number of records, so the connection knows if
+// there is a next page.
+func (args connectionArgs) pageQuery() (db2.PageQuery, error) {
+	limit := uint64(db2.DefaultPageSize)
+	if args.First != nil {
+		if *args.First <= 0 {
+			return db2.PageQuery{}, errors.New("first must be a positive number")
+		}
+		limit = uint64(*args.First)
+	}
+	var cursor string
+	if args.After != nil {
+		cursor = *args.After
+	}
+	order := db2.OrderAscending
+	if args.Order != nil && *args.Order == "DESC" {
+		order = db2.OrderDescending
+	}
+
+	pq, err := db2.NewPageQuery(cursor, false, order, limit)
+	if err != nil {
+		return db2.PageQuery{}, err
+	}
+	pq.Limit++
+	return pq, nil
+}
+
+// newPageInfo trims records to the requested page size and returns the page
+// info of the connection.
+func newPageInfo(records []hal.Pageable, pq db2.PageQuery) ([]hal.Pageable, pageInfo) {
+	var info pageInfo
+	if uint64(len(records)) == pq.Limit {
+		records = records[:len(records)-1]
+		info.HasNextPage = true
+	}
+	if len(records) > 0 {
+		cursor := records[len(records)-1].PagingToken()
+		info.EndCursor = &cursor
+	}
+	return records, info
+}
+
+type assetResolver struct {
+	Type   string
+	Code   *string
+	Issuer *string
+}
+
+func newAssetResolver(asset base.Asset) *assetResolver {
+	return &assetResolver{
+		Type:   asset.Type,
+		Code:   optional(asset.Code),
+		Issuer: optional(asset.Issuer),
+	}
+}
+
+// optional returns nil for empty strings, which are omitted from the JSON
+// resources.
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+type accountResolver struct {
+	account protocol.Account
+}
+
+func (r *accountResolver) ID() graphql.ID {
+	return graphql.ID(r.account.ID)
+}
+
+func (r *accountResolver) Sequence() string {
+	return strconv.FormatInt(r.account.Sequence, 10)
+}
+
+func (r *accountResolver) SubentryCount() int32 {
+	return r.account.SubentryCount
+}
+
+func (r *accountResolver) HomeDomain() *string {
+	return optional(r.account.HomeDomain)
+}
+
+func (r *accountResolver) LastModifiedLedger() int32 {
+	return int32(r.account.LastModifiedLedger)
+}
+
+func (r *accountResolver) NumSponsoring() int32 {
+	return int32(r.account.NumSponsoring)
+}
+
+func (r *accountResolver) NumSponsored() int32 {
+	return int32(r.account.NumSponsored)
+}
+
+func (r *accountResolver) Sponsor() *string {
+	return optional(r.account.Sponsor)
+}
+
+type balanceResolver struct {
+	Asset              *assetResolver
+	LiquidityPoolID    *string
+	Balance            string
+	Limit              *string
+	BuyingLiabilities  *string
+	SellingLiabilities *string
+	IsAuthorized       *bool
+	Sponsor            *string
+}
+
+func (r *accountResolver) Balances() []*balanceResolver {
+	balances := make([]*balanceResolver, len(r.account.Balances))
+	for i, balance := range r.account.Balances {
+		balances[i] = &balanceResolver{
+			Asset:              newAssetResolver(balance.Asset),
+			LiquidityPoolID:    optional(balance.LiquidityPoolId),
+			Balance:            balance.Balance,
+			Limit:              optional(balance.Limit),
+			BuyingLiabilities:  optional(balance.BuyingLiabilities),
+			SellingLiabilities: optional(balance.SellingLiabilities),
+			IsAuthorized:       balance.IsAuthorized,
+			Sponsor:            optional(balance.Sponsor),
+		}
+	}
+	return balances
+}
+
+type offerEdge struct {
+	Cursor string
+	Node   *offerResolver
+}
+
+type offerConnection struct {
+	Edges    []*offerEdge
+	PageInfo pageInfo
+}
+
+// Offers resolves a page of the offers of the account.
+func (r *accountResolver) Offers(ctx context.Context, args connectionArgs) (*offerConnection, error) {
+	pq, err := args.pageQuery()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = pq.CursorInt64(); err != nil {
+		return nil, err
+	}
+	if err = charge(ctx, pageCost(pq.Limit-1)); err != nil {
+		return nil, err
+	}
+	q, err := historyQFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.GetOffers(ctx, history.OffersQuery{PageQuery: pq, SellerID: r.account.ID})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load offers")
+	}
+	ledgers, err := loadLedgers(ctx, q, len(rows), func(i int) int32 {
+		return int32(rows[i].LastModifiedLedger)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]hal.Pageable, len(rows))
+	for i, row := range rows {
+		var offer protocol.Offer
+		resourceadapter.PopulateOffer(ctx, &offer, row, ledgers[int32(row.LastModifiedLedger)])
+		records[i] = offer
+	}
+	records, info := newPageInfo(records, pq)
+
+	connection := &offerConnection{Edges: make([]*offerEdge, len(records)), PageInfo: info}
+	for i, record := range records {
+		offer := record.(protocol.Offer)
+		connection.Edges[i] = &offerEdge{Cursor: offer.PT, Node: &offerResolver{offer: offer}}
+	}
+	return connection, nil
+}
+
+type offerResolver struct {
+	offer protocol.Offer
+}
+
+func (r *offerResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(r.offer.ID, 10))
+}
+
+func (r *offerResolver) Seller() string {
+	return r.offer.Seller
+}
+
+func (r *offerResolver) Selling() *assetResolver {
+	return newAssetResolver(base.Asset(r.offer.Selling))
+}
+
+func (r *offerResolver) Buying() *assetResolver {
+	return newAssetResolver(base.Asset(r.offer.Buying))
+}
+
+func (r *offerResolver) Amount() string {
+	return r.offer.Amount
+}
+
+func (r *offerResolver) Price() string {
+	return r.offer.Price
+}
+
+func (r *offerResolver) LastModifiedLedger() int32 {
+	return r.offer.LastModifiedLedger
+}
+
+func (r *offerResolver) Sponsor() *string {
+	return optional(r.offer.Sponsor)
+}
+
+type paymentEdge struct {
+	Cursor string
+	Node   *paymentResolver
+}
+
+type paymentConnection struct {
+	Edges    []*paymentEdge
+	PageInfo pageInfo
+}
+
+// Payments resolves a page of the payments of the account.
+func (r *accountResolver) Payments(ctx context.Context, args connectionArgs) (*paymentConnection, error) {
+	pq, err := args.pageQuery()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = pq.CursorInt64(); err != nil {
+		return nil, err
+	}
+	if err = charge(ctx, pageCost(pq.Limit-1)); err != nil {
+		return nil, err
+	}
+	q, err := historyQFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := loadPayments(ctx, q, r.account.ID, pq)
+	if err != nil {
+		return nil, err
+	}
+	records, info := newPageInfo(records, pq)
+
+	connection := &paymentConnection{Edges: make([]*paymentEdge, len(records)), PageInfo: info}
+	for i, record := range records {
+		connection.Edges[i] = &paymentEdge{
+			Cursor: record.PagingToken(),
+			Node:   newPaymentResolver(record.(operations.Operation)),
+		}
+	}
+	return connection, nil
+}
+
+// loadPayments loads a page of the payments of the account from the history
+// tables.
+func loadPayments(ctx context.Context, q *history.Q, account string, pq db2.PageQuery) ([]hal.Pageable, error) {
+	rows, _, err := q.Operations().ForAccount(ctx, account).OnlyPayments().Page(pq).Fetch(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load payments")
+	}
+	ledgers, err := loadLedgers(ctx, q, len(rows), func(i int) int32 {
+		return rows[i].LedgerSequence()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]hal.Pageable, len(rows))
+	for i, row := range rows {
+		ledger, ok := ledgers[row.LedgerSequence()]
+		if !ok {
+			return nil, errors.Errorf("could not find ledger data for sequence %d", row.LedgerSequence())
+		}
+		records[i], err = resourceadapter.NewOperation(ctx, row, row.TransactionHash, nil, *ledger)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// paymentResolver flattens the payment operations into a single type. Fields
+// which do not apply to the type of the operation are null.
+type paymentResolver struct {
+	ID                    graphql.ID
+	Type                  string
+	TransactionHash       string
+	TransactionSuccessful bool
+	SourceAccount         string
+	CreatedAt             string
+	From                  *string
+	To                    *string
+	Asset                 *assetResolver
+	Amount                *string
+	SourceAsset           *assetResolver
+	SourceAmount          *string
+}
+
+func newPaymentResolver(operation operations.Operation) *paymentResolver {
+	operationBase := operation.GetBase()
+	payment := &paymentResolver{
+		ID:                    graphql.ID(operationBase.ID),
+		Type:                  operationBase.Type,
+		TransactionHash:       operationBase.TransactionHash,
+		TransactionSuccessful: operationBase.TransactionSuccessful,
+		SourceAccount:         operationBase.SourceAccount,
+		CreatedAt:             operationBase.LedgerCloseTime.Format(time.RFC3339),
+	}
+
+	switch op := operation.(type) {
+	case operations.CreateAccount:
+		payment.From = optional(op.Funder)
+		payment.To = optional(op.Account)
+		payment.Asset = &assetResolver{Type: "native"}
+		payment.Amount = optional(op.StartingBalance)
+	case operations.Payment:
+		payment.setPayment(op)
+	case operations.PathPayment:
+		payment.setPayment(op.Payment)
+		payment.SourceAsset = newAssetResolver(base.Asset{
+			Type: op.SourceAssetType, Code: op.SourceAssetCode, Issuer: op.SourceAssetIssuer,
+		})
+		payment.SourceAmount = optional(op.SourceAmount)
+	case operations.PathPaymentStrictSend:
+		payment.setPayment(op.Payment)
+		payment.SourceAsset = newAssetResolver(base.Asset{
+			Type: op.SourceAssetType, Code: op.SourceAssetCode, Issuer: op.SourceAssetIssuer,
+		})
+		payment.SourceAmount = optional(op.SourceAmount)
+	case operations.AccountMerge:
+		payment.From = optional(op.Account)
+		payment.To = optional(op.Into)
+	}
+	return payment
+}
+
+func (r *paymentResolver) setPayment(op operations.Payment) {
+	r.From = optional(op.From)
+	r.To = optional(op.To)
+	r.Asset = newAssetResolver(op.Asset)
+	r.Amount = optional(op.Amount)
+}
+
+type claimableBalanceEdge struct {
+	Cursor string
+	Node   *claimableBalanceResolver
+}
+
+type claimableBalanceConnection struct {
+	Edges    []*claimableBalanceEdge
+	PageInfo pageInfo
+}
+
+// ClaimableBalances resolves a page of the claimable balances which can be
+// claimed by the account.
+func (r *accountResolver) ClaimableBalances(ctx context.Context, args connectionArgs) (*claimableBalanceConnection, error) {
+	pq, err := args.pageQuery()
+	if err != nil {
+		return nil, err
+	}
+	claimant, err := xdr.AddressToAccountId(r.account.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid account id")
+	}
+	query := history.ClaimableBalancesQuery{PageQuery: pq, Claimant: &claimant}
+	if _, _, err = query.Cursor(); err != nil {
+		return nil, errors.New("invalid cursor, the first part should be a number higher than 0 and " +
+			"the second part should be a valid claimable balance ID")
+	}
+	if err = charge(ctx, pageCost(pq.Limit-1)); err != nil {
+		return nil, err
+	}
+	q, err := historyQFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.GetClaimableBalances(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load claimable balances")
+	}
+	ledgers, err := loadLedgers(ctx, q, len(rows), func(i int) int32 {
+		return int32(rows[i].LastModifiedLedger)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]hal.Pageable, len(rows))
+	for i, row := range rows {
+		var balance protocol.ClaimableBalance
+		err = resourceadapter.PopulateClaimableBalance(ctx, &balance, row, ledgers[int32(row.LastModifiedLedger)])
+		if err != nil {
+			return nil, err
+		}
+		records[i] = balance
+	}
+	records, info := newPageInfo(records, pq)
+
+	connection := &claimableBalanceConnection{
+		Edges:    make([]*claimableBalanceEdge, len(records)),
+		PageInfo: info,
+	}
+	for i, record := range records {
+		balance := record.(protocol.ClaimableBalance)
+		connection.Edges[i] = &claimableBalanceEdge{
+			Cursor: balance.PT,
+			Node:   &claimableBalanceResolver{balance: balance},
+		}
+	}
+	return connection, nil
+}
+
+type claimableBalanceResolver struct {
+	balance protocol.ClaimableBalance
+}
+
+func (r *claimableBalanceResolver) ID() graphql.ID {
+	return graphql.ID(r.balance.BalanceID)
+}
+
+func (r *claimableBalanceResolver) Asset() string {
+	return r.balance.Asset
+}
+
+func (r *claimableBalanceResolver) Amount() string {
+	return r.balance.Amount
+}
+
+func (r *claimableBalanceResolver) Sponsor() *string {
+	return optional(r.balance.Sponsor)
+}
+
+func (r *claimableBalanceResolver) LastModifiedLedger() int32 {
+	return int32(r.balance.LastModifiedLedger)
+}
+
+func (r *claimableBalanceResolver) Claimants() []string {
+	claimants := make([]string, len(r.balance.Claimants))
+	for i, claimant := range r.balance.Claimants {
+		claimants[i] = claimant.Destination
+	}
+	return claimants
+}
+
+type ledgerResolver struct {
+	Sequence                   int32
+	Hash                       string
+	PrevHash                   *string
+	ClosedAt                   string
+	SuccessfulTransactionCount int32
+	FailedTransactionCount     *int32
+	OperationCount             int32
+	BaseFee                    int32
+	BaseReserve                int32
+	ProtocolVersion            int32
+}
+
+func newLedgerResolver(ctx context.Context, row history.Ledger) *ledgerResolver {
+	var ledger protocol.Ledger
+	resourceadapter.PopulateLedger(ctx, &ledger, row)
+	return &ledgerResolver{
+		Sequence:                   ledger.Sequence,
+		Hash:                       ledger.Hash,
+		PrevHash:                   optional(ledger.PrevHash),
+		ClosedAt:                   ledger.ClosedAt.Format(time.RFC3339),
+		SuccessfulTransactionCount: ledger.SuccessfulTransactionCount,
+		FailedTransactionCount:     ledger.FailedTransactionCount,
+		OperationCount:             ledger.OperationCount,
+		BaseFee:                    ledger.BaseFee,
+		BaseReserve:                ledger.BaseReserve,
+		ProtocolVersion:            ledger.ProtocolVersion,
+	}
+}
+
+// loadLedgers loads the ledgers of n records in a single batch.
+func loadLedgers(ctx context.Context, q *history.Q, n int, sequence func(int) int32) (map[int32]*history.Ledger, error) {
+	ledgerCache := history.LedgerCache{}
+	for i := 0; i < n; i++ {
+		ledgerCache.Queue(sequence(i))
+	}
+	if err := ledgerCache.Load(ctx, q); err != nil {
+		return nil, errors.Wrap(err, "failed to load ledger batch")
+	}
+
+	ledgers := make(map[int32]*history.Ledger, len(ledgerCache.Records))
+	for sequence := range ledgerCache.Records {
+		ledger := ledgerCache.Records[sequence]
+		ledgers[sequence] = &ledger
+	}
+	return ledgers, nil
+}
@@ -0,0 +1,181 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/hcnet/go/protocols/aurora/operations"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/toid"
+)
+
+var subscriptionErrorContextKey = ctxKey("subscription_error")
+
+// subscriptionError records the error which ended a subscription, so it can
+// be sent to the client after the last event.
+type subscriptionError struct {
+	lock sync.Mutex
+	err  error
+}
+
+func (s *subscriptionError) set(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *subscriptionError) get() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
+
+func withSubscriptionError(ctx context.Context, subscriptionErr *subscriptionError) context.Context {
+	return context.WithValue(ctx, subscriptionErrorContextKey, subscriptionErr)
+}
+
+func failSubscription(ctx context.Context, err error) {
+	if subscriptionErr, ok := ctx.Value(subscriptionErrorContextKey).(*subscriptionError); ok {
+		subscriptionErr.set(err)
+	}
+}
+
+// followLedgers calls load with every range of ledgers ingested after the
+// subscription started, and with the current ledger first when catchUp is
+// set. Each call runs in a repeatable read transaction and costs one unit of
+// the rate limit of the client. It returns when ctx is done or load fails.
+func (r *resolver) followLedgers(ctx context.Context, catchUp bool, load func(q *history.Q, from, to uint32) error) {
+	session, ok := ctx.Value(&auroraContext.SessionContextKey).(db.SessionInterface)
+	if !ok {
+		failSubscription(ctx, errors.New("missing session in request context"))
+		return
+	}
+	cost, ok := ctx.Value(costContextKey).(*queryCost)
+	if !ok {
+		failSubscription(ctx, errors.New("missing query cost in context"))
+		return
+	}
+
+	ledgerSource := r.ledgerSourceFactory.Get()
+	defer ledgerSource.Close()
+
+	loadRange := func(from, to uint32) error {
+		if err := cost.rateLimit(1); err != nil {
+			return err
+		}
+		err := session.BeginTx(ctx, &sql.TxOptions{
+			Isolation: sql.LevelRepeatableRead,
+			ReadOnly:  true,
+		})
+		if err != nil {
+			return errors.Wrap(err, "Error starting repeatable read transaction")
+		}
+		defer session.Rollback()
+		return load(&history.Q{SessionInterface: session}, from, to)
+	}
+
+	current := ledgerSource.CurrentLedger()
+	if catchUp {
+		if err := loadRange(current, current); err != nil {
+			failSubscription(ctx, err)
+			return
+		}
+	}
+	for {
+		select {
+		case next := <-ledgerSource.NextLedger(current):
+			if err := loadRange(current+1, next); err != nil {
+				failSubscription(ctx, err)
+				return
+			}
+			current = next
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Ledgers sends every ledger ingested after the subscription started.
+func (r *resolver) Ledgers(ctx context.Context) <-chan *ledgerResolver {
+	c := make(chan *ledgerResolver)
+	go func() {
+		defer close(c)
+		r.followLedgers(ctx, false, func(q *history.Q, from, to uint32) error {
+			ledgers, err := loadLedgers(ctx, q, int(to-from+1), func(i int) int32 {
+				return int32(from) + int32(i)
+			})
+			if err != nil {
+				return err
+			}
+			for sequence := int32(from); sequence <= int32(to); sequence++ {
+				ledger, ok := ledgers[sequence]
+				if !ok {
+					return errors.Errorf("could not find ledger data for sequence %d", sequence)
+				}
+				select {
+				case c <- newLedgerResolver(ctx, *ledger):
+				case <-ctx.Done():
+					return nil
+				}
+			}
+			return nil
+		})
+	}()
+	return c
+}
+
+// AccountPayments sends the payments of the account following the given
+// cursor, or ingested after the subscription started.
+func (r *resolver) AccountPayments(ctx context.Context, args struct {
+	Account graphql.ID
+	After   *string
+}) (<-chan *paymentResolver, error) {
+	pq, err := db2.NewPageQuery("", false, db2.OrderAscending, db2.MaxPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if args.After != nil {
+		pq.Cursor = *args.After
+		if _, err = pq.CursorInt64(); err != nil {
+			return nil, err
+		}
+	} else {
+		ledgerSource := r.ledgerSourceFactory.Get()
+		pq.Cursor = toid.AfterLedger(int32(ledgerSource.CurrentLedger())).String()
+		ledgerSource.Close()
+	}
+
+	c := make(chan *paymentResolver)
+	go func() {
+		defer close(c)
+		r.followLedgers(ctx, args.After != nil, func(q *history.Q, from, to uint32) error {
+			for {
+				records, err := loadPayments(ctx, q, string(args.Account), pq)
+				if err != nil {
+					return err
+				}
+				for _, record := range records {
+					select {
+					case c <- newPaymentResolver(record.(operations.Operation)):
+						pq.Cursor = record.PagingToken()
+					case <-ctx.Done():
+						return nil
+					}
+				}
+				if uint64(len(records)) < pq.Limit {
+					return nil
+				}
+			}
+		})
+	}()
+	return c, nil
+}
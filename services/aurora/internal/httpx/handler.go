@@ -7,10 +7,12 @@ import (
 
 	"github.com/hcnet/go/services/aurora/internal/actions"
 	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/db2"
 	"github.com/hcnet/go/services/aurora/internal/ledger"
 	"github.com/hcnet/go/services/aurora/internal/render"
 	hProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
 	"github.com/hcnet/go/services/aurora/internal/render/sse"
+	"github.com/hcnet/go/services/aurora/internal/ssehub"
 	"github.com/hcnet/go/support/db"
 	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/support/render/hal"
@@ -25,6 +27,12 @@ type objectAction interface {
 	) (interface{}, error)
 }
 
+// statusCoder is implemented by resources which are rendered with a status
+// code other than 200.
+type statusCoder interface {
+	StatusCode() int
+}
+
 type ObjectActionHandler struct {
 	Action objectAction
 }
@@ -41,8 +49,13 @@ func (handler ObjectActionHandler) ServeHTTP(
 			return
 		}
 
-		httpjson.Render(
+		statusCode := http.StatusOK
+		if coder, ok := response.(statusCoder); ok {
+			statusCode = coder.StatusCode()
+		}
+		httpjson.RenderStatus(
 			w,
+			statusCode,
 			response,
 			httpjson.HALJSON,
 		)
@@ -52,6 +65,41 @@ func (handler ObjectActionHandler) ServeHTTP(
 	problem.Render(r.Context(), w, hProblem.NotAcceptable)
 }
 
+type waitableObjectAction interface {
+	WaitResource(
+		w actions.HeaderWriter,
+		r *http.Request,
+	) (interface{}, error)
+}
+
+// waitableObjectActionHandler renders the current resource for JSON requests.
+// Event stream requests receive a single event with the resource returned by
+// the waitable action once it is available, and the stream is then closed.
+type waitableObjectActionHandler struct {
+	action   objectAction
+	waitable waitableObjectAction
+}
+
+func (handler waitableObjectActionHandler) ServeHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	if render.Negotiate(r) != render.MimeEventStream {
+		ObjectActionHandler{handler.action}.ServeHTTP(w, r)
+		return
+	}
+
+	stream := sse.NewStream(r.Context(), w)
+	stream.Init()
+	response, err := handler.waitable.WaitResource(w, r)
+	if err != nil {
+		stream.Err(err)
+		return
+	}
+	stream.Send(sse.Event{Data: response})
+	stream.Done()
+}
+
 const defaultObjectStreamLimit = 10
 
 type streamableObjectAction interface {
@@ -151,10 +199,24 @@ type pageAction interface {
 	GetResourcePage(w actions.HeaderWriter, r *http.Request) ([]hal.Pageable, error)
 }
 
+// ledgerRendererAction is implemented by page actions whose streams can be
+// served from the ledgers published by the SSE hub.
+type ledgerRendererAction interface {
+	LedgerRenderer(r *http.Request) (ssehub.RenderFunc, bool, error)
+}
+
+// historyStreamHandler streams history, sharing the queries of the streams
+// through the SSE hub when there is one.
+type historyStreamHandler struct {
+	sse.StreamHandler
+	hub *ssehub.Hub
+}
+
 type pageActionHandler struct {
 	action         pageAction
 	streamable     bool
 	streamHandler  sse.StreamHandler
+	sseHub         *ssehub.Hub
 	repeatableRead bool
 	ledgerState    *ledger.State
 }
@@ -184,13 +246,14 @@ func streamableStatePageHandler(
 func streamableHistoryPageHandler(
 	ledgerState *ledger.State,
 	action pageAction,
-	streamHandler sse.StreamHandler,
+	streamHandler historyStreamHandler,
 ) pageActionHandler {
 	return pageActionHandler{
 		action:         action,
 		ledgerState:    ledgerState,
 		streamable:     true,
-		streamHandler:  streamHandler,
+		streamHandler:  streamHandler.StreamHandler,
+		sseHub:         streamHandler.hub,
 		repeatableRead: false,
 	}
 }
@@ -256,7 +319,17 @@ func (handler pageActionHandler) renderStream(w http.ResponseWriter, r *http.Req
 		generateEvents = repeatableReadStream(r, generateEvents)
 	}
 
-	handler.streamHandler.ServeStream(
+	streamHandler := handler.streamHandler
+	if hubEvents, ok, err := handler.hubStream(r, pq, generateEvents); err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	} else if ok {
+		// New ledgers are only rendered once the hub has published them.
+		streamHandler.LedgerSourceFactory = handler.sseHub
+		generateEvents = hubEvents
+	}
+
+	streamHandler.ServeStream(
 		w,
 		r,
 		int(pq.Limit),
@@ -264,6 +337,30 @@ func (handler pageActionHandler) renderStream(w http.ResponseWriter, r *http.Req
 	)
 }
 
+// hubStream returns the events generator of the stream when it can be served
+// from the SSE hub, falling back to generateEvents to catch up with it.
+func (handler pageActionHandler) hubStream(
+	r *http.Request,
+	pq db2.PageQuery,
+	generateEvents sse.GenerateEventsFunc,
+) (sse.GenerateEventsFunc, bool, error) {
+	action, ok := handler.action.(ledgerRendererAction)
+	if handler.sseHub == nil || !ok || pq.Order != db2.OrderAscending {
+		return nil, false, nil
+	}
+
+	render, ok, err := action.LedgerRenderer(r)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	hubEvents, err := handler.sseHub.GenerateEvents(r, pq.Cursor, render, generateEvents)
+	if err != nil {
+		return nil, false, err
+	}
+	return hubEvents, true, nil
+}
+
 func (handler pageActionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch render.Negotiate(r) {
 	case render.MimeHal, render.MimeJSON:
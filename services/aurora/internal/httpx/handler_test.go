@@ -0,0 +1,45 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/protocols/aurora"
+	proto "github.com/hcnet/go/protocols/hcnetcore"
+	"github.com/hcnet/go/services/aurora/internal/actions"
+)
+
+type staticObjectAction struct {
+	resource interface{}
+}
+
+func (action staticObjectAction) GetResource(w actions.HeaderWriter, r *http.Request) (interface{}, error) {
+	return action.resource, nil
+}
+
+func TestObjectActionHandlerStatusCode(t *testing.T) {
+	response := aurora.AsyncTransactionSubmissionResponse{
+		TxStatus: proto.TXStatusDuplicate,
+		Hash:     "3389e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c8889",
+	}
+
+	w := httptest.NewRecorder()
+	ObjectActionHandler{staticObjectAction{actions.AsyncSubmissionResponse{response}}}.ServeHTTP(
+		w, httptest.NewRequest(http.MethodPost, "/transactions_async", nil),
+	)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var rendered aurora.AsyncTransactionSubmissionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rendered))
+	assert.Equal(t, response, rendered)
+
+	w = httptest.NewRecorder()
+	ObjectActionHandler{staticObjectAction{response}}.ServeHTTP(
+		w, httptest.NewRequest(http.MethodGet, "/", nil),
+	)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
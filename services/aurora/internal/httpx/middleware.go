@@ -12,6 +12,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/hcnet/go/services/aurora/internal/actions"
+	"github.com/hcnet/go/services/aurora/internal/apikeys"
 	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/services/aurora/internal/errors"
@@ -131,6 +132,8 @@ func getClientData(r *http.Request, headerName string) string {
 
 func logEndOfRequest(ctx context.Context, r *http.Request, requestDurationSummary *prometheus.SummaryVec, duration time.Duration, mw middleware.WrapResponseWriter, streaming bool) {
 	route := supportHttp.GetChiRoutePattern(r)
+	// API keys must not end up in the logs.
+	redacted := apikeys.Redact(r)
 
 	referer := r.Referer()
 	if referer == "" {
@@ -152,7 +155,7 @@ func logEndOfRequest(ctx context.Context, r *http.Request, requestDurationSummar
 		"ip":              remoteAddrIP(r),
 		"ip_port":         r.RemoteAddr,
 		"method":          r.Method,
-		"path":            r.URL.String(),
+		"path":            redacted.URL.String(),
 		"route":           route,
 		"status":          mw.Status(),
 		"streaming":       streaming,
@@ -176,7 +179,7 @@ func recoverMiddleware(h http.Handler) http.Handler {
 		defer func() {
 			if rec := recover(); rec != nil {
 				err := errors.FromPanic(rec)
-				errors.ReportToSentry(err, r)
+				errors.ReportToSentry(err, apikeys.Redact(r))
 				problem.Render(ctx, w, err)
 			}
 		}()
@@ -0,0 +1,49 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/services/aurora/internal/apikeys"
+	"github.com/hcnet/go/support/log"
+)
+
+func TestLogEndOfRequestRedactsAPIKey(t *testing.T) {
+	const key = "0123456789abcdef"
+	summary := prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{Namespace: "aurora", Subsystem: "http", Name: "requests_duration_seconds"},
+		[]string{"status", "route", "streaming", "method"},
+	)
+
+	logger := log.New()
+	done := logger.StartTest(logrus.InfoLevel)
+	router := chi.NewRouter()
+	router.Get("/accounts", func(w http.ResponseWriter, r *http.Request) {
+		mw := newWrapResponseWriter(w, r)
+		mw.WriteHeader(http.StatusOK)
+		logEndOfRequest(log.Set(r.Context(), logger), r, summary, time.Second, mw, false)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/accounts?limit=10&"+apikeys.QueryParam+"="+key, nil)
+	r.Header.Set(apikeys.Header, key)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	logged := done()
+
+	require.Len(t, logged, 1)
+	path := logged[0].Data["path"].(string)
+	assert.NotContains(t, path, key)
+	assert.Contains(t, path, "limit=10")
+	assert.Contains(t, path, apikeys.QueryParam+"=REDACTED")
+
+	// The request itself is left untouched.
+	assert.Equal(t, key, r.URL.Query().Get(apikeys.QueryParam))
+	assert.Equal(t, key, r.Header.Get(apikeys.Header))
+}
@@ -7,8 +7,10 @@ import (
 
 	"github.com/stellar/throttled"
 
+	"github.com/hcnet/go/services/aurora/internal/apikeys"
 	"github.com/hcnet/go/services/aurora/internal/ledger"
 	hProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/services/aurora/internal/render/sse"
 	"github.com/hcnet/go/support/render/problem"
 )
 
@@ -54,3 +56,20 @@ func newRateLimiter(rateQuota *throttled.RateQuota) (*throttled.HTTPRateLimiter,
 	}
 	return result, nil
 }
+
+// streamRateLimit charges every update of a stream to the quota of the API key
+// of the request or, for requests without a key, to the rate limit of the
+// client IP address.
+func streamRateLimit(rateLimiter *throttled.HTTPRateLimiter) sse.RateLimitFunc {
+	return func(r *http.Request) (bool, error) {
+		if key, ok := apikeys.FromContext(r.Context()); ok {
+			limited, _, err := key.RateLimit(apikeys.Streams, 1)
+			return limited, err
+		}
+		if rateLimiter == nil {
+			return false, nil
+		}
+		limited, _, err := rateLimiter.RateLimiter.RateLimit(rateLimiter.VaryBy.Key(r), 1)
+		return limited, err
+	}
+}
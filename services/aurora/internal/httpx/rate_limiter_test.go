@@ -0,0 +1,54 @@
+package httpx
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/services/aurora/internal/apikeys"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/ledger"
+	"github.com/hcnet/go/services/aurora/internal/render/sse"
+)
+
+func TestStreamChargedToAPIKey(t *testing.T) {
+	q := &history.MockQAPIKeys{}
+	q.On("GetAPIKeys", mock.Anything).Return([]history.APIKey{{
+		ID:           1,
+		Name:         "wallet",
+		KeyHash:      apikeys.HashKey("key"),
+		StreamsQuota: sql.NullInt64{Int64: 1, Valid: true},
+	}}, nil)
+	key, err := apikeys.NewLimiter(q, nil).Lookup(context.Background(), "key")
+	require.NoError(t, err)
+
+	ledgerSource := ledger.NewTestingSource(1)
+	handler := sse.StreamHandler{
+		LedgerSourceFactory: &testingFactory{ledgerSource},
+		RateLimit:           streamRateLimit(nil),
+	}
+	r := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	r = r.WithContext(apikeys.NewContext(context.Background(), key))
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeStream(w, r, 10, func() ([]sse.Event, error) {
+			return []sse.Event{{Data: "update"}}, nil
+		})
+		close(done)
+	}()
+
+	// the second update exceeds the quota of the key
+	ledgerSource.AddLedger(2)
+	<-done
+	assert.Equal(t, 1, strings.Count(w.Body.String(), `data: "update"`))
+	assert.Contains(t, w.Body.String(), "event: error")
+}
@@ -16,11 +16,14 @@ import (
 	"github.com/stellar/throttled"
 
 	"github.com/hcnet/go/services/aurora/internal/actions"
+	"github.com/hcnet/go/services/aurora/internal/apikeys"
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/gql"
 	"github.com/hcnet/go/services/aurora/internal/ledger"
 	"github.com/hcnet/go/services/aurora/internal/paths"
 	"github.com/hcnet/go/services/aurora/internal/render"
 	"github.com/hcnet/go/services/aurora/internal/render/sse"
+	"github.com/hcnet/go/services/aurora/internal/ssehub"
 	"github.com/hcnet/go/services/aurora/internal/txsub"
 	"github.com/hcnet/go/support/db"
 	supporthttp "github.com/hcnet/go/support/http"
@@ -49,7 +52,12 @@ type RouterConfig struct {
 	FriendbotURL             *url.URL
 	HealthCheck              http.Handler
 	EnableIngestionFiltering bool
+	EnableWebhooks           bool
+	EnableAPIKeys            bool
+	EnableGraphQL            bool
+	GraphQLMaxQueryCost      uint
 	DisableTxSub             bool
+	SSEHub                   *ssehub.Hub
 }
 
 type Router struct {
@@ -70,13 +78,21 @@ func NewRouter(config *RouterConfig, serverMetrics *ServerMetrics, ledgerState *
 			return nil, fmt.Errorf("unable to create RateLimiter: %v", err)
 		}
 	}
-	result.addMiddleware(config, rateLimiter, serverMetrics)
+	var apiKeyLimiter *apikeys.Limiter
+	if config.EnableAPIKeys {
+		apiKeyLimiter = apikeys.NewLimiter(
+			&history.Q{SessionInterface: config.DBSession},
+			serverMetrics.APIKeyUsageCounter,
+		)
+	}
+	result.addMiddleware(config, rateLimiter, apiKeyLimiter, serverMetrics)
 	result.addRoutes(config, rateLimiter, ledgerState)
 	return &result, nil
 }
 
 func (r *Router) addMiddleware(config *RouterConfig,
 	rateLimitter *throttled.HTTPRateLimiter,
+	apiKeyLimiter *apikeys.Limiter,
 	serverMetrics *ServerMetrics) {
 
 	r.Use(chimiddleware.StripSlashes)
@@ -102,10 +118,14 @@ func (r *Router) addMiddleware(config *RouterConfig,
 		AllowedOrigins:         []string{},
 		AllowOriginRequestFunc: func(*http.Request, string) bool { return true },
 		AllowedHeaders:         []string{"*"},
-		ExposedHeaders:         []string{"Date", "Latest-Ledger"},
+		ExposedHeaders:         []string{"Date", "Latest-Ledger", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
 	})
 	r.Use(c.Handler)
 
+	if apiKeyLimiter != nil {
+		r.Use(apiKeyLimiter.Middleware)
+	}
+
 	if rateLimitter != nil {
 		r.Use(func(handler http.Handler) http.Handler {
 			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -116,6 +136,11 @@ func (r *Router) addMiddleware(config *RouterConfig,
 					handler.ServeHTTP(w, r)
 					return
 				}
+				// Requests made with an API key are charged to the quota of the key.
+				if _, ok := apikeys.FromContext(r.Context()); ok {
+					handler.ServeHTTP(w, r)
+					return
+				}
 				rateLimitter.RateLimit(handler).ServeHTTP(w, r)
 			})
 		})
@@ -153,9 +178,12 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 
 	streamHandler := sse.StreamHandler{
 		RateLimiter:         rateLimiter,
+		RateLimit:           streamRateLimit(rateLimiter),
 		LedgerSourceFactory: historyLedgerSourceFactory{ledgerState: ledgerState, updateFrequency: config.SSEUpdateFrequency},
 	}
 
+	historyStreams := historyStreamHandler{StreamHandler: streamHandler, hub: config.SSEHub}
+
 	historyMiddleware := NewHistoryMiddleware(ledgerState, int32(config.StaleThreshold), config.DBSession)
 	// State endpoints behind stateMiddleware
 	r.Group(func(r chi.Router) {
@@ -191,13 +219,18 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 				r.With(historyMiddleware).Method(http.MethodGet, "/operations", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
 					LedgerState:  ledgerState,
 					OnlyPayments: false,
-				}, streamHandler))
-				r.With(historyMiddleware).Method(http.MethodGet, "/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, streamHandler))
-				r.With(historyMiddleware).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, streamHandler))
-				r.With(historyMiddleware).Method(http.MethodGet, "/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, streamHandler))
+				}, historyStreams))
+				r.With(historyMiddleware).Method(http.MethodGet, "/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, historyStreams))
+				r.With(historyMiddleware).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, historyStreams))
+				r.With(historyMiddleware).Method(http.MethodGet, "/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, historyStreams))
 			})
 		})
 
+		r.Route("/contracts/{contract_id:\\w+}", func(r chi.Router) {
+			r.With(historyMiddleware).Method(http.MethodGet, "/events", streamableHistoryPageHandler(ledgerState, actions.GetContractEventsHandler{LedgerState: ledgerState}, historyStreams))
+			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/data", streamableStatePageHandler(ledgerState, actions.GetContractDataHandler{LedgerState: ledgerState}, streamHandler))
+		})
+
 		r.Route("/offers", func(r chi.Router) {
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/", restPageHandler(ledgerState, actions.GetOffersHandler{LedgerState: ledgerState}))
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/{offer_id}", ObjectActionHandler{actions.GetOfferByID{}})
@@ -205,6 +238,16 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 
 		r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/assets", restPageHandler(ledgerState, actions.AssetStatsHandler{LedgerState: ledgerState}))
 
+		if config.EnableGraphQL {
+			graphqlHandler := gql.NewHandler(gql.HandlerConfig{
+				LedgerSourceFactory: streamHandler.LedgerSourceFactory,
+				RateLimiter:         rateLimiter,
+				MaxQueryCost:        config.GraphQLMaxQueryCost,
+			})
+			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/graphql", graphqlHandler)
+			r.With(stateMiddleware.Wrap).Method(http.MethodPost, "/graphql", graphqlHandler)
+		}
+
 		if config.PathFinder != nil {
 			findPaths := ObjectActionHandler{actions.FindPathsHandler{
 				StaleThreshold:       config.StaleThreshold,
@@ -222,6 +265,16 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/paths", findPaths)
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/paths/strict-receive", findPaths)
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/paths/strict-send", findFixedPaths)
+			r.With(stateMiddleware.Wrap).Method(http.MethodPost, "/paths/batch", ObjectActionHandler{actions.FindPathsBatchHandler{
+				MaxPathLength:        config.MaxPathLength,
+				SetLastLedgerHeader:  true,
+				MaxAssetsParamLength: config.MaxAssetsPerPathRequest,
+				PathFinder:           config.PathFinder,
+			}})
+			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/order_book/depth", ObjectActionHandler{actions.OrderBookDepthHandler{
+				SetLastLedgerHeader: true,
+				PathFinder:          config.PathFinder,
+			}})
 		}
 		r.With(stateMiddleware.Wrap).Method(
 			http.MethodGet,
@@ -237,34 +290,34 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 	// need to use absolute routes here. Make sure we use regexp check here for
 	// emptiness. Without it, requesting `/accounts//payments` return all payments!
 	r.Group(func(r chi.Router) {
-		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, historyStreams))
 		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/operations", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
 			LedgerState:  ledgerState,
 			OnlyPayments: false,
-		}, streamHandler))
+		}, historyStreams))
 		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/payments", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
 			LedgerState:  ledgerState,
 			OnlyPayments: true,
-		}, streamHandler))
-		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, streamHandler))
-		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, streamHandler))
+		}, historyStreams))
+		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, historyStreams))
+		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, historyStreams))
 	})
 	// ledger actions
 	r.Route("/ledgers", func(r chi.Router) {
-		r.With(historyMiddleware).Method(http.MethodGet, "/", streamableHistoryPageHandler(ledgerState, actions.GetLedgersHandler{LedgerState: ledgerState}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/", streamableHistoryPageHandler(ledgerState, actions.GetLedgersHandler{LedgerState: ledgerState}, historyStreams))
 		r.Route("/{ledger_id}", func(r chi.Router) {
 			r.With(historyMiddleware).Method(http.MethodGet, "/", ObjectActionHandler{actions.GetLedgerByIDHandler{LedgerState: ledgerState}})
-			r.With(historyMiddleware).Method(http.MethodGet, "/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, streamHandler))
+			r.With(historyMiddleware).Method(http.MethodGet, "/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, historyStreams))
 			r.Group(func(r chi.Router) {
-				r.With(historyMiddleware).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, streamHandler))
+				r.With(historyMiddleware).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, historyStreams))
 				r.With(historyMiddleware).Method(http.MethodGet, "/operations", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
 					LedgerState:  ledgerState,
 					OnlyPayments: false,
-				}, streamHandler))
+				}, historyStreams))
 				r.With(historyMiddleware).Method(http.MethodGet, "/payments", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
 					LedgerState:  ledgerState,
 					OnlyPayments: true,
-				}, streamHandler))
+				}, historyStreams))
 			})
 		})
 	})
@@ -274,24 +327,27 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		r.With(historyMiddleware).Method(http.MethodGet, "/claimable_balances/{claimable_balance_id:\\w+}/operations", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
 			LedgerState:  ledgerState,
 			OnlyPayments: false,
-		}, streamHandler))
-		r.With(historyMiddleware).Method(http.MethodGet, "/claimable_balances/{claimable_balance_id:\\w+}/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, streamHandler))
+		}, historyStreams))
+		r.With(historyMiddleware).Method(http.MethodGet, "/claimable_balances/{claimable_balance_id:\\w+}/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, historyStreams))
 	})
 
 	// transaction history actions
 	r.Route("/transactions", func(r chi.Router) {
-		r.With(historyMiddleware).Method(http.MethodGet, "/", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, historyStreams))
 		r.Route("/{tx_id}", func(r chi.Router) {
-			r.With(historyMiddleware).Method(http.MethodGet, "/", ObjectActionHandler{actions.GetTransactionByHashHandler{}})
-			r.With(historyMiddleware).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, streamHandler))
+			r.With(historyMiddleware).Method(http.MethodGet, "/", waitableObjectActionHandler{
+				action:   actions.GetTransactionByHashHandler{},
+				waitable: actions.StreamTransactionByHashHandler{Listener: config.TxSubmitter},
+			})
+			r.With(historyMiddleware).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, historyStreams))
 			r.With(historyMiddleware).Method(http.MethodGet, "/operations", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
 				LedgerState:  ledgerState,
 				OnlyPayments: false,
-			}, streamHandler))
+			}, historyStreams))
 			r.With(historyMiddleware).Method(http.MethodGet, "/payments", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
 				LedgerState:  ledgerState,
 				OnlyPayments: true,
-			}, streamHandler))
+			}, historyStreams))
 		})
 	})
 
@@ -300,9 +356,9 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		r.With(historyMiddleware).Method(http.MethodGet, "/", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
 			LedgerState:  ledgerState,
 			OnlyPayments: false,
-		}, streamHandler))
+		}, historyStreams))
 		r.With(historyMiddleware).Method(http.MethodGet, "/{id}", ObjectActionHandler{actions.GetOperationByIDHandler{LedgerState: ledgerState}})
-		r.With(historyMiddleware).Method(http.MethodGet, "/{op_id}/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/{op_id}/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, historyStreams))
 	})
 
 	r.Group(func(r chi.Router) {
@@ -310,17 +366,17 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		r.With(historyMiddleware).Method(http.MethodGet, "/payments", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
 			LedgerState:  ledgerState,
 			OnlyPayments: true,
-		}, streamHandler))
+		}, historyStreams))
 
 		// effect actions
-		r.With(historyMiddleware).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, historyStreams))
 
 		// trading related endpoints
-		r.With(historyMiddleware).Method(http.MethodGet, "/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, historyStreams))
 		r.With(historyMiddleware).Method(http.MethodGet, "/trade_aggregations", ObjectActionHandler{actions.GetTradeAggregationsHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}})
 		// /offers/{offer_id} has been created above so we need to use absolute
 		// routes here.
-		r.With(historyMiddleware).Method(http.MethodGet, "/offers/{offer_id}/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/offers/{offer_id}/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, historyStreams))
 	})
 
 	// Transaction submission API
@@ -330,6 +386,12 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		DisableTxSub:      config.DisableTxSub,
 		CoreStateGetter:   config.CoreGetter,
 	}})
+	r.Method(http.MethodPost, "/transactions_async", ObjectActionHandler{actions.AsyncSubmitTransactionHandler{
+		Submitter:         config.TxSubmitter,
+		NetworkPassphrase: config.NetworkPassphrase,
+		DisableTxSub:      config.DisableTxSub,
+		CoreStateGetter:   config.CoreGetter,
+	}})
 
 	// Network state related endpoints
 	r.Method(http.MethodGet, "/fee_stats", ObjectActionHandler{actions.FeeStatsHandler{}})
@@ -370,4 +432,22 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 			r.With(historyMiddleware).Get("/account", handler.GetAccountConfig)
 		})
 	}
+	if config.EnableWebhooks {
+		r.Internal.Route("/webhooks", func(r chi.Router) {
+			handler := actions.WebhooksHandler{}
+			r.With(historyMiddleware).Get("/subscriptions", handler.GetSubscriptions)
+			r.With(historyMiddleware).Post("/subscriptions", handler.CreateSubscription)
+			r.With(historyMiddleware).Delete("/subscriptions/{id}", handler.DeleteSubscription)
+			r.With(historyMiddleware).Get("/dead_letters", handler.GetDeadLetters)
+		})
+	}
+	if config.EnableAPIKeys {
+		r.Internal.Route("/api_keys", func(r chi.Router) {
+			handler := actions.APIKeysHandler{}
+			r.With(historyMiddleware).Get("/", handler.GetKeys)
+			r.With(historyMiddleware).Post("/", handler.CreateKey)
+			r.With(historyMiddleware).Put("/{id}", handler.UpdateKey)
+			r.With(historyMiddleware).Delete("/{id}", handler.DeleteKey)
+		})
+	}
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/hcnet/go/services/aurora/internal/ledger"
 	hProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
 	"github.com/hcnet/go/services/aurora/internal/render/sse"
+	"github.com/hcnet/go/services/aurora/internal/txsub"
 	"github.com/hcnet/go/support/db"
 	"github.com/hcnet/go/support/log"
 	"github.com/hcnet/go/support/render/problem"
@@ -23,6 +24,7 @@ import (
 type ServerMetrics struct {
 	RequestDurationSummary  *prometheus.SummaryVec
 	ReplicaLagErrorsCounter prometheus.Counter
+	APIKeyUsageCounter      *prometheus.CounterVec
 }
 
 type TLSConfig struct {
@@ -59,6 +61,8 @@ func init() {
 	problem.RegisterError(db.ErrStatementTimeout, hProblem.ServiceUnavailable)
 	problem.RegisterError(db.ErrConflictWithRecovery, hProblem.ServiceUnavailable)
 	problem.RegisterError(db.ErrBadConnection, hProblem.ServiceUnavailable)
+	problem.RegisterError(txsub.ErrTimeout, hProblem.Timeout)
+	problem.RegisterError(txsub.ErrCanceled, hProblem.ClientDisconnected)
 }
 
 func NewServer(serverConfig ServerConfig, routerConfig RouterConfig, ledgerState *ledger.State) (*Server, error) {
@@ -77,6 +81,13 @@ func NewServer(serverConfig ServerConfig, routerConfig RouterConfig, ledgerState
 				Help: "Count of HTTP errors returned due to replica lag",
 			},
 		),
+		APIKeyUsageCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "aurora", Subsystem: "api_keys", Name: "usage_total",
+				Help: "Requests, and updates of streams, made with each API key by endpoint class",
+			},
+			[]string{"key", "class", "result"},
+		),
 	}
 	router, err := NewRouter(&routerConfig, sm, ledgerState)
 	if err != nil {
@@ -109,6 +120,7 @@ func NewServer(serverConfig ServerConfig, routerConfig RouterConfig, ledgerState
 func (s *Server) RegisterMetrics(registry *prometheus.Registry) {
 	registry.MustRegister(s.Metrics.RequestDurationSummary)
 	registry.MustRegister(s.Metrics.ReplicaLagErrorsCounter)
+	registry.MustRegister(s.Metrics.APIKeyUsageCounter)
 }
 
 func (s *Server) Serve() error {
@@ -18,6 +18,7 @@ import (
 	"github.com/hcnet/go/ingest/ledgerbackend"
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/services/aurora/internal/ingest/filters"
+	"github.com/hcnet/go/services/aurora/internal/ingest/sinks"
 	apkg "github.com/hcnet/go/support/app"
 	"github.com/hcnet/go/support/db"
 	"github.com/hcnet/go/support/errors"
@@ -61,7 +62,9 @@ const (
 	//       contract data ledger entries.
 	// - 18: Ingest contract asset balances so we can keep track of expired / restore asset
 	//       balances for asset stats.
-	CurrentVersion = 18
+	// - 19: Ingest contract data ledger entries to serve the current storage
+	//       of contracts.
+	CurrentVersion = 19
 
 	// MaxDBConnections is the size of the postgres connection pool dedicated to Aurora ingestion:
 	//  * Ledger ingestion,
@@ -110,6 +113,46 @@ type Config struct {
 
 	EnableIngestionFiltering bool
 	MaxLedgerPerFlush        uint32
+
+	// EnableStateHistory records a version of the ledger entries changed in
+	// every ledger so the state can be queried at past ledgers.
+	EnableStateHistory bool
+
+	// Sink, if set, receives the operations, effects, trades and ledger entry
+	// changes of every ledger processed by the transaction processors. The
+	// sink is not closed by the system.
+	Sink sinks.Sink
+
+	// LedgerBackendType selects where ledgers are read from. By default
+	// captive core (or the core DB) is used.
+	LedgerBackendType LedgerBackendType
+	// DataStoreURL is the storage.Storage URL of the ledger batch files
+	// written by ledgerexporter. Used only by BufferedStorageBackend.
+	DataStoreURL                 string
+	BufferedStorageBackendConfig ledgerbackend.BufferedStorageBackendConfig
+	// LedgerBackend, if set, is used instead of the backend selected by
+	// LedgerBackendType. The system closes it when it shuts down.
+	LedgerBackend ledgerbackend.LedgerBackend
+}
+
+// LedgerBackendType is the type of the ledger backend used by ingestion.
+type LedgerBackendType int
+
+const (
+	CaptiveCoreBackend LedgerBackendType = iota
+	BufferedStorageBackend
+)
+
+// ParseLedgerBackendType parses the value of the --ledgerbackend flag.
+func ParseLedgerBackendType(value string) (LedgerBackendType, error) {
+	switch value {
+	case "", "captive-core":
+		return CaptiveCoreBackend, nil
+	case "datastore":
+		return BufferedStorageBackend, nil
+	default:
+		return CaptiveCoreBackend, errors.Errorf("invalid ledger backend type %q, expected captive-core or datastore", value)
+	}
 }
 
 // LocalCaptiveCoreEnabled returns true if configured to run
@@ -249,7 +292,26 @@ func NewSystem(config Config) (System, error) {
 	}
 
 	var ledgerBackend ledgerbackend.LedgerBackend
-	if config.RemoteCaptiveCoreEnabled() {
+	if config.LedgerBackend != nil {
+		ledgerBackend = config.LedgerBackend
+	} else if config.LedgerBackendType == BufferedStorageBackend {
+		dataStore, connectErr := storage.ConnectBackend(
+			config.DataStoreURL,
+			storage.ConnectOptions{
+				Context:   ctx,
+				UserAgent: fmt.Sprintf("aurora/%s golang/%s", apkg.Version(), runtime.Version()),
+			},
+		)
+		if connectErr != nil {
+			cancel()
+			return nil, errors.Wrap(connectErr, "error connecting to datastore")
+		}
+		ledgerBackend, err = ledgerbackend.NewBufferedStorageBackend(config.BufferedStorageBackendConfig, dataStore)
+		if err != nil {
+			cancel()
+			return nil, errors.Wrap(err, "error creating buffered storage backend")
+		}
+	} else if config.RemoteCaptiveCoreEnabled() {
 		ledgerBackend, err = ledgerbackend.NewRemoteCaptive(config.RemoteCaptiveCoreURL)
 		if err != nil {
 			cancel()
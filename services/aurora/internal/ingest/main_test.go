@@ -294,12 +294,15 @@ type mockDBQ struct {
 	history.MockQLiquidityPools
 	history.MockQHistoryLiquidityPools
 	history.MockQAssetStats
+	history.MockQContractData
+	history.MockQContractEvents
 	history.MockQData
 	history.MockQEffects
 	history.MockQLedgers
 	history.MockQOffers
 	history.MockQOperations
 	history.MockQSigners
+	history.MockQStateHistory
 	history.MockQTransactions
 	history.MockQTrustLines
 }
@@ -1,6 +1,8 @@
 package ingest
 
 import (
+	"io"
+
 	"github.com/hcnet/go/exp/orderbook"
 	"github.com/hcnet/go/xdr"
 	"github.com/stretchr/testify/mock"
@@ -58,3 +60,13 @@ func (m *mockOrderBookGraph) Verify() ([]xdr.OfferEntry, []xdr.LiquidityPoolEntr
 	args := m.Called()
 	return args.Get(0).([]xdr.OfferEntry), args.Get(1).([]xdr.LiquidityPoolEntry), args.Error(2)
 }
+
+func (m *mockOrderBookGraph) WriteSnapshot(w io.Writer) error {
+	args := m.Called(w)
+	return args.Error(0)
+}
+
+func (m *mockOrderBookGraph) LoadSnapshot(r io.Reader) (uint32, error) {
+	args := m.Called(r)
+	return args.Get(0).(uint32), args.Error(1)
+}
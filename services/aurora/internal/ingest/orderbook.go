@@ -1,9 +1,11 @@
 package ingest
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
 	"math/rand"
+	"os"
 	"sort"
 	"time"
 
@@ -19,6 +21,7 @@ import (
 const (
 	verificationFrequency = time.Hour
 	updateFrequency       = 2 * time.Second
+	snapshotFrequency     = 10 * time.Minute
 )
 
 // OrderBookStream updates an in memory graph to be consistent with
@@ -33,9 +36,15 @@ type OrderBookStream struct {
 	// LatestLedgerGauge exposes the local (order book graph)
 	// latest processed ledger
 	LatestLedgerGauge prometheus.Gauge
-	lastLedger        uint32
-	lastVerification  time.Time
-	encodingBuffer    *xdr.EncodingBuffer
+	// SnapshotPath is the file where the order book graph is periodically
+	// persisted. When it is set, Run() loads the graph from the snapshot
+	// and catches up from the snapshot ledger instead of loading all
+	// offers and liquidity pools from the DB.
+	SnapshotPath     string
+	lastLedger       uint32
+	lastVerification time.Time
+	lastSnapshot     time.Time
+	encodingBuffer   *xdr.EncodingBuffer
 }
 
 // NewOrderBookStream constructs and initializes an OrderBookStream instance
@@ -375,8 +384,100 @@ func (o *OrderBookStream) Update(ctx context.Context) error {
 	return nil
 }
 
-// Run will call Update() every 30 seconds until the given context is terminated.
+// loadSnapshot populates the order book graph from the snapshot file, if
+// there is one. The graph is verified against the DB on the first Update()
+// after it has caught up with ingestion, and it is reset from the DB if the
+// verification fails.
+func (o *OrderBookStream) loadSnapshot() {
+	if o.SnapshotPath == "" {
+		return
+	}
+
+	file, err := os.Open(o.SnapshotPath)
+	if os.IsNotExist(err) {
+		log.WithField("path", o.SnapshotPath).Info("order book snapshot not found")
+		return
+	} else if err != nil {
+		log.WithError(err).Warn("could not open order book snapshot")
+		return
+	}
+	defer file.Close()
+
+	start := time.Now()
+	ledger, err := o.graph.LoadSnapshot(bufio.NewReader(file))
+	if err != nil {
+		log.WithError(err).Warn("could not load order book snapshot")
+		return
+	}
+	log.WithField("ledger", ledger).
+		WithField("duration", time.Since(start).Seconds()).
+		Info("loaded order book snapshot")
+
+	o.lastLedger = ledger
+	o.LatestLedgerGauge.Set(float64(ledger))
+	o.lastSnapshot = time.Now()
+	// force a verification against the DB on the next Update()
+	o.lastVerification = time.Time{}
+}
+
+// writeSnapshot persists the order book graph to the snapshot file. The
+// snapshot is written to a temporary file first so that a crash never leaves
+// a partial snapshot behind.
+func (o *OrderBookStream) writeSnapshot() error {
+	tmpPath := o.SnapshotPath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return errors.Wrap(err, "Error creating snapshot file")
+	}
+	defer os.Remove(tmpPath)
+
+	w := bufio.NewWriter(file)
+	if err = o.graph.WriteSnapshot(w); err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "Error writing snapshot file")
+	}
+
+	if err = os.Rename(tmpPath, o.SnapshotPath); err != nil {
+		return errors.Wrap(err, "Error renaming snapshot file")
+	}
+	o.lastSnapshot = time.Now()
+	return nil
+}
+
+// maybeWriteSnapshot persists the order book graph if it is in sync with the
+// DB and the last snapshot is older than snapshotFrequency (or force is true).
+func (o *OrderBookStream) maybeWriteSnapshot(force bool) {
+	if o.SnapshotPath == "" || o.lastLedger == 0 {
+		return
+	}
+	if !force && time.Since(o.lastSnapshot) < snapshotFrequency {
+		return
+	}
+
+	start := time.Now()
+	if err := o.writeSnapshot(); err != nil {
+		log.WithError(err).Error("could not write order book snapshot")
+		return
+	}
+	log.WithField("ledger", o.lastLedger).
+		WithField("duration", time.Since(start).Seconds()).
+		Info("wrote order book snapshot")
+}
+
+// Run will call Update() every 2 seconds until the given context is terminated.
+// If SnapshotPath is set, the order book graph is loaded from the snapshot
+// before the first update and it is persisted every 10 minutes and on shutdown.
 func (o *OrderBookStream) Run(ctx context.Context) {
+	o.loadSnapshot()
+
 	ticker := time.NewTicker(updateFrequency)
 	defer ticker.Stop()
 
@@ -385,8 +486,11 @@ func (o *OrderBookStream) Run(ctx context.Context) {
 		case <-ticker.C:
 			if err := o.Update(ctx); err != nil && !isCancelledError(ctx, err) {
 				log.WithError(err).Error("could not apply updates from order book stream")
+			} else if err == nil {
+				o.maybeWriteSnapshot(false)
 			}
 		case <-ctx.Done():
+			o.maybeWriteSnapshot(true)
 			log.Info("shutting down OrderBookStream")
 			return
 		}
@@ -5,8 +5,11 @@ package ingest
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/hcnet/go/exp/orderbook"
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/services/aurora/internal/ingest/processors"
 	"github.com/hcnet/go/xdr"
@@ -1017,3 +1020,127 @@ func (t *VerifyLiquidityPoolsStreamTestSuite) TestSuccess() {
 	t.Assert().NoError(err)
 	t.Assert().True(offersOk)
 }
+
+type SnapshotOrderBookStreamTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	historyQ *mockDBQ
+	graph    *orderbook.OrderBookGraph
+	stream   *OrderBookStream
+}
+
+func TestSnapshotOrderBookStreamTestSuite(t *testing.T) {
+	suite.Run(t, new(SnapshotOrderBookStreamTestSuite))
+}
+
+func (t *SnapshotOrderBookStreamTestSuite) SetupTest() {
+	t.ctx = context.Background()
+	t.historyQ = &mockDBQ{}
+	t.graph = orderbook.NewOrderBookGraph()
+	t.stream = NewOrderBookStream(t.historyQ, t.graph)
+	t.stream.SnapshotPath = filepath.Join(t.T().TempDir(), "orderbook.snapshot")
+}
+
+func (t *SnapshotOrderBookStreamTestSuite) TearDownTest() {
+	t.historyQ.AssertExpectations(t.T())
+}
+
+func (t *SnapshotOrderBookStreamTestSuite) offer(id xdr.Int64) xdr.OfferEntry {
+	sellerID := "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"
+	return xdr.OfferEntry{
+		SellerId: xdr.MustAddress(sellerID),
+		OfferId:  id,
+		Selling:  xdr.MustNewNativeAsset(),
+		Buying:   xdr.MustNewCreditAsset("USD", sellerID),
+		Amount:   100,
+		Price:    xdr.Price{N: 1, D: 2},
+	}
+}
+
+func (t *SnapshotOrderBookStreamTestSuite) TestMissingSnapshot() {
+	t.stream.loadSnapshot()
+	t.Assert().Equal(uint32(0), t.stream.lastLedger)
+	t.Assert().True(t.graph.IsEmpty())
+}
+
+func (t *SnapshotOrderBookStreamTestSuite) TestInvalidSnapshot() {
+	t.Require().NoError(os.WriteFile(t.stream.SnapshotPath, []byte("invalid"), 0600))
+	t.stream.loadSnapshot()
+	t.Assert().Equal(uint32(0), t.stream.lastLedger)
+	t.Assert().True(t.graph.IsEmpty())
+}
+
+func (t *SnapshotOrderBookStreamTestSuite) TestSkipSnapshotBeforeUpdate() {
+	t.stream.maybeWriteSnapshot(true)
+	_, err := os.Stat(t.stream.SnapshotPath)
+	t.Assert().True(os.IsNotExist(err))
+}
+
+func (t *SnapshotOrderBookStreamTestSuite) TestSnapshotRestore() {
+	t.graph.AddOffers(t.offer(1), t.offer(2))
+	t.Require().NoError(t.graph.Apply(150))
+	t.stream.lastLedger = 150
+	t.stream.maybeWriteSnapshot(true)
+
+	graph := orderbook.NewOrderBookGraph()
+	stream := NewOrderBookStream(t.historyQ, graph)
+	stream.SnapshotPath = t.stream.SnapshotPath
+	stream.loadSnapshot()
+	t.Assert().Equal(uint32(150), stream.lastLedger)
+	t.Assert().Equal(uint32(150), graph.LastLedger())
+	t.Assert().Len(graph.Offers(), 2)
+	t.Assert().True(stream.lastVerification.IsZero())
+
+	// the restored graph catches up from the snapshot ledger
+	sellerID := "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"
+	t.historyQ.MockQOffers.On("GetUpdatedOffers", t.ctx, uint32(150)).
+		Return([]history.Offer{{OfferID: 1, SellerID: sellerID, LastModifiedLedger: 160, Deleted: true}}, nil).
+		Once()
+	t.historyQ.MockQLiquidityPools.On("GetUpdatedLiquidityPools", t.ctx, uint32(150)).
+		Return([]history.LiquidityPool{}, nil).
+		Once()
+
+	reset, err := stream.update(t.ctx, ingestionStatus{
+		HistoryConsistentWithState:        true,
+		LastIngestedLedger:                201,
+		LastOfferCompactionLedger:         100,
+		LastLiquidityPoolCompactionLedger: 100,
+	})
+	t.Assert().NoError(err)
+	t.Assert().False(reset)
+	t.Assert().Equal(uint32(201), stream.lastLedger)
+	t.Assert().Equal([]xdr.OfferEntry{t.offer(2)}, graph.Offers())
+}
+
+func (t *SnapshotOrderBookStreamTestSuite) TestStaleSnapshot() {
+	t.graph.AddOffers(t.offer(1))
+	t.Require().NoError(t.graph.Apply(50))
+	t.stream.lastLedger = 50
+	t.stream.maybeWriteSnapshot(true)
+
+	graph := orderbook.NewOrderBookGraph()
+	stream := NewOrderBookStream(t.historyQ, graph)
+	stream.SnapshotPath = t.stream.SnapshotPath
+	stream.loadSnapshot()
+	t.Assert().Equal(uint32(50), stream.lastLedger)
+
+	// offers removed before the last compaction are no longer in the DB so
+	// the graph has to be rebuilt
+	t.historyQ.On("StreamAllOffers", t.ctx, mock.Anything).
+		Return(nil).
+		Once()
+	t.historyQ.MockQLiquidityPools.On("StreamAllLiquidityPools", t.ctx, mock.Anything).
+		Return(nil).
+		Once()
+
+	reset, err := stream.update(t.ctx, ingestionStatus{
+		HistoryConsistentWithState:        true,
+		LastIngestedLedger:                201,
+		LastOfferCompactionLedger:         100,
+		LastLiquidityPoolCompactionLedger: 100,
+	})
+	t.Assert().NoError(err)
+	t.Assert().True(reset)
+	t.Assert().Equal(uint32(201), stream.lastLedger)
+	t.Assert().True(graph.IsEmpty())
+}
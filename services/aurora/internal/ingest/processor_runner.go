@@ -111,13 +111,14 @@ func buildChangeProcessor(
 	source ingestionSource,
 	ledgerSequence uint32,
 	networkPassphrase string,
+	enableStateHistory bool,
 ) *groupChangeProcessors {
 	statsChangeProcessor := &statsChangeProcessor{
 		StatsChangeProcessor: changeStats,
 	}
 
 	useLedgerCache := source == ledgerSource
-	return newGroupChangeProcessors([]auroraChangeProcessor{
+	changeProcessors := []auroraChangeProcessor{
 		statsChangeProcessor,
 		processors.NewAccountDataProcessor(historyQ),
 		processors.NewAccountsProcessor(historyQ),
@@ -127,10 +128,28 @@ func buildChangeProcessor(
 		processors.NewTrustLinesProcessor(historyQ),
 		processors.NewClaimableBalancesChangeProcessor(historyQ),
 		processors.NewLiquidityPoolsChangeProcessor(historyQ, ledgerSequence),
-	})
+		processors.NewContractDataProcessor(historyQ),
+	}
+	if enableStateHistory {
+		changeProcessors = append(changeProcessors,
+			processors.NewStateHistoryProcessor(historyQ, ledgerSequence, source == historyArchiveSource))
+	}
+	return newGroupChangeProcessors(changeProcessors)
+}
+
+// newSinkProcessor returns the processor writing to the configured sink or
+// nil if there is no sink.
+func (s *ProcessorRunner) newSinkProcessor() *processors.SinkProcessor {
+	if s.config.Sink == nil {
+		return nil
+	}
+	return processors.NewSinkProcessor(s.config.Sink, s.config.NetworkPassphrase)
 }
 
-func (s *ProcessorRunner) buildTransactionProcessor(ledgersProcessor *processors.LedgersProcessor) *groupTransactionProcessors {
+func (s *ProcessorRunner) buildTransactionProcessor(
+	ledgersProcessor *processors.LedgersProcessor,
+	sinkProcessor *processors.SinkProcessor,
+) *groupTransactionProcessors {
 	accountLoader := history.NewAccountLoader()
 	assetLoader := history.NewAssetLoader()
 	lpLoader := history.NewLiquidityPoolLoader()
@@ -154,7 +173,14 @@ func (s *ProcessorRunner) buildTransactionProcessor(ledgersProcessor *processors
 		processors.NewClaimableBalancesTransactionProcessor(cbLoader,
 			s.historyQ.NewTransactionClaimableBalanceBatchInsertBuilder(), s.historyQ.NewOperationClaimableBalanceBatchInsertBuilder()),
 		processors.NewLiquidityPoolsTransactionProcessor(lpLoader,
-			s.historyQ.NewTransactionLiquidityPoolBatchInsertBuilder(), s.historyQ.NewOperationLiquidityPoolBatchInsertBuilder())}
+			s.historyQ.NewTransactionLiquidityPoolBatchInsertBuilder(), s.historyQ.NewOperationLiquidityPoolBatchInsertBuilder()),
+		processors.NewContractEventsProcessor(s.historyQ.NewContractEventBatchInsertBuilder())}
+
+	// the sink is flushed last so ledgers are written to it only after they
+	// were successfully added to the database transaction
+	if sinkProcessor != nil {
+		processors = append(processors, sinkProcessor)
+	}
 
 	return newGroupTransactionProcessors(processors, lazyLoaders, statsLedgerTransactionProcessor, tradeProcessor)
 }
@@ -235,6 +261,7 @@ func (s *ProcessorRunner) RunHistoryArchiveIngestion(
 		historyArchiveSource,
 		checkpointLedger,
 		s.config.NetworkPassphrase,
+		s.config.EnableStateHistory,
 	)
 
 	if checkpointLedger == 1 {
@@ -363,14 +390,26 @@ func (s *ProcessorRunner) RunTransactionProcessorsOnLedger(ledger xdr.LedgerClos
 	transactionDurations processorsRunDurations,
 	tradeStats processors.TradeStats,
 	err error,
+) {
+	return s.runTransactionProcessorsOnLedger(ledger, s.newSinkProcessor())
+}
+
+func (s *ProcessorRunner) runTransactionProcessorsOnLedger(ledger xdr.LedgerCloseMeta, sinkProcessor *processors.SinkProcessor) (
+	transactionStats processors.StatsLedgerTransactionProcessorResults,
+	transactionDurations processorsRunDurations,
+	tradeStats processors.TradeStats,
+	err error,
 ) {
 	// ensure capture of the ledger to history regardless of whether it has transactions.
 	ledgersProcessor := processors.NewLedgerProcessor(s.historyQ.NewLedgerBatchInsertBuilder(), CurrentVersion)
 	ledgersProcessor.ProcessLedger(ledger)
+	if sinkProcessor != nil {
+		sinkProcessor.ProcessLedger(ledger)
+	}
 
 	groupTransactionFilterers := s.buildTransactionFilterer()
 	groupFilteredOutProcessors := s.buildFilteredOutProcessor()
-	groupTransactionProcessors := s.buildTransactionProcessor(ledgersProcessor)
+	groupTransactionProcessors := s.buildTransactionProcessor(ledgersProcessor, sinkProcessor)
 
 	err = s.streamLedger(ledger,
 		groupTransactionFilterers,
@@ -405,10 +444,11 @@ func (s *ProcessorRunner) RunTransactionProcessorsOnLedger(ledger xdr.LedgerClos
 
 func (s *ProcessorRunner) RunTransactionProcessorsOnLedgers(ledgers []xdr.LedgerCloseMeta) (err error) {
 	ledgersProcessor := processors.NewLedgerProcessor(s.historyQ.NewLedgerBatchInsertBuilder(), CurrentVersion)
+	sinkProcessor := s.newSinkProcessor()
 
 	groupTransactionFilterers := s.buildTransactionFilterer()
 	groupFilteredOutProcessors := s.buildFilteredOutProcessor()
-	groupTransactionProcessors := s.buildTransactionProcessor(ledgersProcessor)
+	groupTransactionProcessors := s.buildTransactionProcessor(ledgersProcessor, sinkProcessor)
 
 	startTime := time.Now()
 	curHeap, sysHeap := getMemStats()
@@ -424,6 +464,9 @@ func (s *ProcessorRunner) RunTransactionProcessorsOnLedgers(ledgers []xdr.Ledger
 	for _, ledger := range ledgers {
 		// ensure capture of the ledger to history regardless of whether it has transactions.
 		ledgersProcessor.ProcessLedger(ledger)
+		if sinkProcessor != nil {
+			sinkProcessor.ProcessLedger(ledger)
+		}
 
 		err = s.streamLedger(ledger,
 			groupTransactionFilterers,
@@ -493,13 +536,21 @@ func (s *ProcessorRunner) RunAllProcessorsOnLedger(ledger xdr.LedgerCloseMeta) (
 		ledgerSource,
 		ledger.LedgerSequence(),
 		s.config.NetworkPassphrase,
+		s.config.EnableStateHistory,
 	)
+	// the changes and the transactions of the ledger are written to the sink
+	// together when the transaction processors are flushed
+	sinkProcessor := s.newSinkProcessor()
+	if sinkProcessor != nil {
+		sinkProcessor.ProcessLedger(ledger)
+		groupChangeProcessors.processors = append(groupChangeProcessors.processors, sinkProcessor)
+	}
 	err = s.runChangeProcessorOnLedger(groupChangeProcessors, ledger)
 	if err != nil {
 		return
 	}
 
-	transactionStats, transactionDurations, tradeStats, err := s.RunTransactionProcessorsOnLedger(ledger)
+	transactionStats, transactionDurations, tradeStats, err := s.runTransactionProcessorsOnLedger(ledger, sinkProcessor)
 
 	stats.changeStats = changeStatsProcessor.GetResults()
 	stats.changeDurations = groupChangeProcessors.processorsRunDurations
@@ -1,6 +1,7 @@
 package ingest
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -16,6 +17,7 @@ import (
 	"github.com/hcnet/go/network"
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/services/aurora/internal/ingest/processors"
+	"github.com/hcnet/go/services/aurora/internal/ingest/sinks"
 	"github.com/hcnet/go/support/db"
 	"github.com/hcnet/go/xdr"
 )
@@ -180,7 +182,7 @@ func TestProcessorRunnerBuildChangeProcessor(t *testing.T) {
 	}
 
 	stats := &ingest.StatsChangeProcessor{}
-	processor := buildChangeProcessor(runner.historyQ, stats, ledgerSource, 123, "")
+	processor := buildChangeProcessor(runner.historyQ, stats, ledgerSource, 123, "", false)
 	assert.IsType(t, &groupChangeProcessors{}, processor)
 
 	assert.IsType(t, &statsChangeProcessor{}, processor.processors[0])
@@ -194,6 +196,8 @@ func TestProcessorRunnerBuildChangeProcessor(t *testing.T) {
 	assert.True(t, reflect.ValueOf(processor.processors[5]).
 		Elem().FieldByName("useLedgerEntryCache").Bool())
 	assert.IsType(t, &processors.TrustLinesProcessor{}, processor.processors[6])
+	assert.IsType(t, &processors.ContractDataProcessor{}, processor.processors[9])
+	assert.Len(t, processor.processors, 10)
 
 	runner = ProcessorRunner{
 		ctx:      ctx,
@@ -201,7 +205,7 @@ func TestProcessorRunnerBuildChangeProcessor(t *testing.T) {
 		filters:  &MockFilters{},
 	}
 
-	processor = buildChangeProcessor(runner.historyQ, stats, historyArchiveSource, 456, "")
+	processor = buildChangeProcessor(runner.historyQ, stats, historyArchiveSource, 456, "", true)
 	assert.IsType(t, &groupChangeProcessors{}, processor)
 
 	assert.IsType(t, &statsChangeProcessor{}, processor.processors[0])
@@ -215,6 +219,9 @@ func TestProcessorRunnerBuildChangeProcessor(t *testing.T) {
 	assert.False(t, reflect.ValueOf(processor.processors[5]).
 		Elem().FieldByName("useLedgerEntryCache").Bool())
 	assert.IsType(t, &processors.TrustLinesProcessor{}, processor.processors[6])
+	assert.IsType(t, &processors.StateHistoryProcessor{}, processor.processors[10])
+	assert.True(t, reflect.ValueOf(processor.processors[10]).
+		Elem().FieldByName("fromCheckpoint").Bool())
 }
 
 func TestProcessorRunnerBuildTransactionProcessor(t *testing.T) {
@@ -244,6 +251,8 @@ func TestProcessorRunnerBuildTransactionProcessor(t *testing.T) {
 		Return(&history.MockTransactionLiquidityPoolBatchInsertBuilder{})
 	q.MockQHistoryLiquidityPools.On("NewOperationLiquidityPoolBatchInsertBuilder").
 		Return(&history.MockOperationLiquidityPoolBatchInsertBuilder{})
+	q.MockQContractEvents.On("NewContractEventBatchInsertBuilder").
+		Return(&history.MockContractEventBatchInsertBuilder{})
 
 	runner := ProcessorRunner{
 		ctx:      ctx,
@@ -253,7 +262,7 @@ func TestProcessorRunnerBuildTransactionProcessor(t *testing.T) {
 
 	ledgersProcessor := &processors.LedgersProcessor{}
 
-	processor := runner.buildTransactionProcessor(ledgersProcessor)
+	processor := runner.buildTransactionProcessor(ledgersProcessor, nil)
 	assert.IsType(t, &groupTransactionProcessors{}, processor)
 	assert.IsType(t, &processors.StatsLedgerTransactionProcessor{}, processor.processors[0])
 	assert.IsType(t, &processors.EffectProcessor{}, processor.processors[1])
@@ -263,6 +272,13 @@ func TestProcessorRunnerBuildTransactionProcessor(t *testing.T) {
 	assert.IsType(t, &processors.ParticipantsProcessor{}, processor.processors[5])
 	assert.IsType(t, &processors.ClaimableBalancesTransactionProcessor{}, processor.processors[7])
 	assert.IsType(t, &processors.LiquidityPoolsTransactionProcessor{}, processor.processors[8])
+	assert.IsType(t, &processors.ContractEventsProcessor{}, processor.processors[9])
+	assert.Len(t, processor.processors, 10)
+
+	runner.config.Sink = sinks.NewWriterSink(&bytes.Buffer{})
+	processor = runner.buildTransactionProcessor(ledgersProcessor, runner.newSinkProcessor())
+	assert.Len(t, processor.processors, 11)
+	assert.IsType(t, &processors.SinkProcessor{}, processor.processors[10])
 }
 
 func TestProcessorRunnerWithFilterEnabled(t *testing.T) {
@@ -591,6 +607,11 @@ func mockTxProcessorBatchBuilders(q *mockDBQ, mockSession *db.MockSession, ctx c
 	q.MockQHistoryLiquidityPools.On("NewOperationLiquidityPoolBatchInsertBuilder").
 		Return(mockOperationLiquidityPoolBatchInsertBuilder).Once()
 
+	mockContractEventBatchInsertBuilder := &history.MockContractEventBatchInsertBuilder{}
+	mockContractEventBatchInsertBuilder.On("Exec", ctx, mockSession).Return(nil).Once()
+	q.MockQContractEvents.On("NewContractEventBatchInsertBuilder").
+		Return(mockContractEventBatchInsertBuilder).Once()
+
 	return []interface{}{mockTradeBatchInsertBuilder,
 		mockTransactionsBatchInsertBuilder,
 		mockOperationsBatchInsertBuilder,
@@ -600,7 +621,8 @@ func mockTxProcessorBatchBuilders(q *mockDBQ, mockSession *db.MockSession, ctx c
 		mockTransactionClaimableBalanceBatchInsertBuilder,
 		mockOperationClaimableBalanceBatchInsertBuilder,
 		mockTransactionLiquidityPoolBatchInsertBuilder,
-		mockOperationLiquidityPoolBatchInsertBuilder}
+		mockOperationLiquidityPoolBatchInsertBuilder,
+		mockContractEventBatchInsertBuilder}
 }
 
 func mockChangeProcessorBatchBuilders(q *mockDBQ, ctx context.Context, mockExec bool) []interface{} {
@@ -654,6 +676,13 @@ func mockChangeProcessorBatchBuilders(q *mockDBQ, ctx context.Context, mockExec
 	q.MockQTrustLines.On("NewTrustLinesBatchInsertBuilder").
 		Return(mockTrustLinesBatchInsertBuilder)
 
+	mockContractDataBatchInsertBuilder := &history.MockContractDataBatchInsertBuilder{}
+	if mockExec {
+		mockContractDataBatchInsertBuilder.On("Exec", ctx).Return(nil).Once()
+	}
+	q.MockQContractData.On("NewContractDataBatchInsertBuilder").
+		Return(mockContractDataBatchInsertBuilder)
+
 	return []interface{}{mockAccountSignersBatchInsertBuilder,
 		mockAccountsBatchInsertBuilder,
 		mockClaimableBalanceBatchInsertBuilder,
@@ -661,5 +690,6 @@ func mockChangeProcessorBatchBuilders(q *mockDBQ, ctx context.Context, mockExec
 		mockOfferBatchInsertBuilder,
 		mockAccountDataBatchInsertBuilder,
 		mockTrustLinesBatchInsertBuilder,
+		mockContractDataBatchInsertBuilder,
 	}
 }
@@ -0,0 +1,160 @@
+package processors
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// ContractDataProcessor keeps the contract_data table in sync with the
+// contract storage entries in the ledger.
+type ContractDataProcessor struct {
+	contractDataQ history.QContractData
+
+	cache              *ingest.ChangeCompactor
+	batchInsertBuilder history.ContractDataBatchInsertBuilder
+}
+
+func NewContractDataProcessor(contractDataQ history.QContractData) *ContractDataProcessor {
+	p := &ContractDataProcessor{contractDataQ: contractDataQ}
+	p.reset()
+	return p
+}
+
+func (p *ContractDataProcessor) reset() {
+	p.cache = ingest.NewChangeCompactor()
+	p.batchInsertBuilder = p.contractDataQ.NewContractDataBatchInsertBuilder()
+}
+
+func (p *ContractDataProcessor) ProcessChange(ctx context.Context, change ingest.Change) error {
+	if change.Type != xdr.LedgerEntryTypeContractData {
+		return nil
+	}
+
+	err := p.cache.AddChange(change)
+	if err != nil {
+		return errors.Wrap(err, "error adding to ledgerCache")
+	}
+
+	if p.cache.Size() > maxBatchSize {
+		err = p.Commit(ctx)
+		if err != nil {
+			return errors.Wrap(err, "error in Commit")
+		}
+	}
+
+	return nil
+}
+
+// ContractDataKeyHash returns the hex encoded hash of the ledger key of the
+// given contract data entry.
+func ContractDataKeyHash(ledgerEntry xdr.LedgerEntry) (string, error) {
+	keyHash, err := getKeyHash(ledgerEntry)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(keyHash[:]), nil
+}
+
+func xdrToContractData(ledgerEntry xdr.LedgerEntry) (history.ContractDataEntry, error) {
+	contractData := ledgerEntry.Data.MustContractData()
+	keyHash, err := ContractDataKeyHash(ledgerEntry)
+	if err != nil {
+		return history.ContractDataEntry{}, errors.Wrap(err, "Error extracting ledger key")
+	}
+
+	contractID, err := contractData.Contract.String()
+	if err != nil {
+		return history.ContractDataEntry{}, errors.Wrap(err, "Error encoding contract address")
+	}
+	key, err := xdr.MarshalBase64(contractData.Key)
+	if err != nil {
+		return history.ContractDataEntry{}, errors.Wrap(err, "Error marshaling contract data key")
+	}
+	value, err := xdr.MarshalBase64(contractData.Val)
+	if err != nil {
+		return history.ContractDataEntry{}, errors.Wrap(err, "Error marshaling contract data value")
+	}
+
+	return history.ContractDataEntry{
+		KeyHash:            keyHash,
+		ContractID:         contractID,
+		Durability:         int32(contractData.Durability),
+		Key:                key,
+		Value:              value,
+		LastModifiedLedger: uint32(ledgerEntry.LastModifiedLedgerSeq),
+	}, nil
+}
+
+func (p *ContractDataProcessor) Commit(ctx context.Context) error {
+	defer p.reset()
+
+	var batchUpsertContractData []history.ContractDataEntry
+	var batchRemoveKeyHashes []string
+
+	changes := p.cache.GetChanges()
+	for _, change := range changes {
+		switch {
+		case change.Pre == nil && change.Post != nil:
+			// Created
+			entry, err := xdrToContractData(*change.Post)
+			if err != nil {
+				return errors.Wrap(err, "Error extracting contract data")
+			}
+
+			err = p.batchInsertBuilder.Add(entry)
+			if err != nil {
+				return errors.Wrap(err, "Error adding to ContractDataBatchInsertBuilder")
+			}
+		case change.Pre != nil && change.Post != nil:
+			// Updated
+			entry, err := xdrToContractData(*change.Post)
+			if err != nil {
+				return errors.Wrap(err, "Error extracting contract data")
+			}
+			batchUpsertContractData = append(batchUpsertContractData, entry)
+		case change.Pre != nil && change.Post == nil:
+			// Removed
+			keyHash, err := ContractDataKeyHash(*change.Pre)
+			if err != nil {
+				return errors.Wrap(err, "Error extracting ledger key")
+			}
+			batchRemoveKeyHashes = append(batchRemoveKeyHashes, keyHash)
+		default:
+			return errors.New("Invalid io.Change: change.Pre == nil && change.Post == nil")
+		}
+	}
+
+	err := p.batchInsertBuilder.Exec(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Error executing ContractDataBatchInsertBuilder")
+	}
+
+	if len(batchUpsertContractData) > 0 {
+		err := p.contractDataQ.UpsertContractData(ctx, batchUpsertContractData)
+		if err != nil {
+			return errors.Wrap(err, "errors in UpsertContractData")
+		}
+	}
+
+	if len(batchRemoveKeyHashes) > 0 {
+		rowsAffected, err := p.contractDataQ.RemoveContractData(ctx, batchRemoveKeyHashes)
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected != int64(len(batchRemoveKeyHashes)) {
+			return ingest.NewStateError(errors.Errorf(
+				"%d rows affected when removing %d contract data entries",
+				rowsAffected,
+				len(batchRemoveKeyHashes),
+			))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/xdr"
+)
+
+func makeContractDataEntry(key string, value uint32, lastModifiedLedger uint32) xdr.LedgerEntry {
+	keySym := xdr.ScSymbol(key)
+	val := xdr.Uint32(value)
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: xdr.Uint32(lastModifiedLedger),
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeContractData,
+			ContractData: &xdr.ContractDataEntry{
+				Contract: xdr.ScAddress{
+					Type:       xdr.ScAddressTypeScAddressTypeContract,
+					ContractId: &xdr.Hash{1, 2, 3},
+				},
+				Key:        xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &keySym},
+				Durability: xdr.ContractDataDurabilityPersistent,
+				Val:        xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &val},
+			},
+		},
+	}
+}
+
+func TestContractDataProcessor(t *testing.T) {
+	ctx := context.Background()
+	mockQ := &history.MockQContractData{}
+	mockBatchInsertBuilder := &history.MockContractDataBatchInsertBuilder{}
+	mockQ.On("NewContractDataBatchInsertBuilder").Return(mockBatchInsertBuilder).Twice()
+
+	processor := NewContractDataProcessor(mockQ)
+
+	created := makeContractDataEntry("created", 1, 10)
+	updatedPre := makeContractDataEntry("updated", 1, 10)
+	updatedPost := makeContractDataEntry("updated", 2, 11)
+	removed := makeContractDataEntry("removed", 1, 10)
+
+	assert.NoError(t, processor.ProcessChange(ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeContractData,
+		Post: &created,
+	}))
+	assert.NoError(t, processor.ProcessChange(ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeContractData,
+		Pre:  &updatedPre,
+		Post: &updatedPost,
+	}))
+	assert.NoError(t, processor.ProcessChange(ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeContractData,
+		Pre:  &removed,
+	}))
+	// other ledger entry types are ignored
+	assert.NoError(t, processor.ProcessChange(ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeTtl,
+		Post: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeTtl, Ttl: &xdr.TtlEntry{}},
+		},
+	}))
+
+	createdRow, err := xdrToContractData(created)
+	assert.NoError(t, err)
+	assert.Equal(t, "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS", createdRow.ContractID)
+	assert.Equal(t, "AAAADwAAAAdjcmVhdGVkAA==", createdRow.Key)
+	assert.Equal(t, "AAAAAwAAAAE=", createdRow.Value)
+	assert.Equal(t, int32(xdr.ContractDataDurabilityPersistent), createdRow.Durability)
+	assert.Equal(t, uint32(10), createdRow.LastModifiedLedger)
+
+	updatedRow, err := xdrToContractData(updatedPost)
+	assert.NoError(t, err)
+	removedKeyHash, err := ContractDataKeyHash(removed)
+	assert.NoError(t, err)
+
+	mockBatchInsertBuilder.On("Add", createdRow).Return(nil).Once()
+	mockBatchInsertBuilder.On("Exec", ctx).Return(nil).Once()
+	mockQ.On("UpsertContractData", ctx, []history.ContractDataEntry{updatedRow}).Return(nil).Once()
+	mockQ.On("RemoveContractData", ctx, []string{removedKeyHash}).Return(int64(1), nil).Once()
+
+	assert.NoError(t, processor.Commit(ctx))
+	mockQ.AssertExpectations(t)
+	mockBatchInsertBuilder.AssertExpectations(t)
+}
+
+func TestContractDataProcessorRemoveStateError(t *testing.T) {
+	ctx := context.Background()
+	mockQ := &history.MockQContractData{}
+	mockBatchInsertBuilder := &history.MockContractDataBatchInsertBuilder{}
+	mockQ.On("NewContractDataBatchInsertBuilder").Return(mockBatchInsertBuilder).Twice()
+
+	processor := NewContractDataProcessor(mockQ)
+	removed := makeContractDataEntry("removed", 1, 10)
+	assert.NoError(t, processor.ProcessChange(ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeContractData,
+		Pre:  &removed,
+	}))
+
+	keyHash, err := ContractDataKeyHash(removed)
+	assert.NoError(t, err)
+	mockBatchInsertBuilder.On("Exec", ctx).Return(nil).Once()
+	mockQ.On("RemoveContractData", ctx, []string{keyHash}).Return(int64(0), nil).Once()
+
+	err = processor.Commit(ctx)
+	assert.IsType(t, ingest.StateError{}, err)
+	assert.EqualError(t, err, "0 rows affected when removing 1 contract data entries")
+}
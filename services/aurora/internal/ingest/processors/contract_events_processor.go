@@ -0,0 +1,101 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/strkey"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/toid"
+	"github.com/hcnet/go/xdr"
+)
+
+// ContractEventsProcessor ingests the contract events emitted by successful
+// invoke host function operations.
+type ContractEventsProcessor struct {
+	batch history.ContractEventBatchInsertBuilder
+}
+
+func NewContractEventsProcessor(batch history.ContractEventBatchInsertBuilder) *ContractEventsProcessor {
+	return &ContractEventsProcessor{
+		batch: batch,
+	}
+}
+
+func (p *ContractEventsProcessor) ProcessTransaction(lcm xdr.LedgerCloseMeta, transaction ingest.LedgerTransaction) error {
+	if !transaction.Result.Successful() {
+		return nil
+	}
+
+	for opi, op := range transaction.Envelope.Operations() {
+		if op.Body.Type != xdr.OperationTypeInvokeHostFunction {
+			continue
+		}
+
+		// Soroban transactions contain a single operation so all the events
+		// in the meta belong to it.
+		diagnosticEvents, err := transaction.GetDiagnosticEvents()
+		if err != nil {
+			return err
+		}
+
+		operationID := toid.New(
+			int32(lcm.LedgerSequence()),
+			int32(transaction.Index),
+			int32(opi+1),
+		).ToInt64()
+
+		order := int32(1)
+		for _, event := range filterEvents(diagnosticEvents) {
+			if event.ContractId == nil {
+				continue
+			}
+			row, err := contractEventToRow(operationID, order, event)
+			if err != nil {
+				return errors.Wrapf(err, "reading operation %v contract events", operationID)
+			}
+			if err := p.batch.Add(row); err != nil {
+				return errors.Wrap(err, "Error batch inserting contract event rows")
+			}
+			order++
+		}
+	}
+
+	return nil
+}
+
+func contractEventToRow(operationID int64, order int32, event xdr.ContractEvent) (history.ContractEvent, error) {
+	contractID, err := strkey.Encode(strkey.VersionByteContract, event.ContractId[:])
+	if err != nil {
+		return history.ContractEvent{}, errors.Wrap(err, "Error encoding contract id")
+	}
+
+	body := event.Body.MustV0()
+	topics := make([]string, 0, len(body.Topics))
+	for _, topic := range body.Topics {
+		encoded, err := xdr.MarshalBase64(topic)
+		if err != nil {
+			return history.ContractEvent{}, errors.Wrap(err, "Error marshaling event topic")
+		}
+		topics = append(topics, encoded)
+	}
+
+	value, err := xdr.MarshalBase64(body.Data)
+	if err != nil {
+		return history.ContractEvent{}, errors.Wrap(err, "Error marshaling event value")
+	}
+
+	return history.ContractEvent{
+		HistoryOperationID: operationID,
+		Order:              order,
+		ContractID:         contractID,
+		Topics:             topics,
+		Value:              value,
+	}, nil
+}
+
+func (p *ContractEventsProcessor) Flush(ctx context.Context, session db.SessionInterface) error {
+	return p.batch.Exec(ctx, session)
+}
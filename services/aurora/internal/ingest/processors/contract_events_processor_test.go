@@ -0,0 +1,90 @@
+package processors
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/strkey"
+	"github.com/hcnet/go/support/contractevents"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/toid"
+	"github.com/hcnet/go/xdr"
+)
+
+func TestContractEventsProcessor(t *testing.T) {
+	admin := keypair.MustRandom().Address()
+	from, to := keypair.MustRandom().Address(), keypair.MustRandom().Address()
+	asset := xdr.MustNewCreditAsset("TESTER", admin)
+
+	tx := makeInvocationTransaction(
+		from, to, admin, asset, big.NewInt(12345),
+		contractevents.EventTypeTransfer, contractevents.EventTypeMint,
+	)
+	lcm := xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{LedgerSeq: 20},
+			},
+		},
+	}
+
+	batch := &history.MockContractEventBatchInsertBuilder{}
+	processor := NewContractEventsProcessor(batch)
+
+	events := tx.UnsafeMeta.MustV3().SorobanMeta.Events
+	for i, event := range events {
+		body := event.Body.MustV0()
+		var topics []string
+		for _, topic := range body.Topics {
+			encoded, err := xdr.MarshalBase64(topic)
+			assert.NoError(t, err)
+			topics = append(topics, encoded)
+		}
+		value, err := xdr.MarshalBase64(body.Data)
+		assert.NoError(t, err)
+
+		batch.On("Add", history.ContractEvent{
+			HistoryOperationID: toid.New(20, 0, 1).ToInt64(),
+			Order:              int32(i + 1),
+			ContractID:         strkey.MustEncode(strkey.VersionByteContract, event.ContractId[:]),
+			Topics:             topics,
+			Value:              value,
+		}).Return(nil).Once()
+	}
+
+	assert.NoError(t, processor.ProcessTransaction(lcm, tx))
+
+	session := &db.MockSession{}
+	batch.On("Exec", context.Background(), session).Return(nil).Once()
+	assert.NoError(t, processor.Flush(context.Background(), session))
+
+	batch.AssertExpectations(t)
+}
+
+func TestContractEventsProcessorIgnoresFailedTransactions(t *testing.T) {
+	admin := keypair.MustRandom().Address()
+	tx := makeInvocationTransaction(
+		admin, admin, admin, xdr.MustNewNativeAsset(), big.NewInt(1),
+		contractevents.EventTypeTransfer,
+	)
+	tx.Result.Result.Result.Code = xdr.TransactionResultCodeTxFailed
+
+	batch := &history.MockContractEventBatchInsertBuilder{}
+	processor := NewContractEventsProcessor(batch)
+	assert.NoError(t, processor.ProcessTransaction(xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{LedgerSeq: 20},
+			},
+		},
+	}, tx))
+	batch.AssertNotCalled(t, "Add", mock.Anything)
+}
@@ -3698,6 +3698,110 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 					},
 				},
 			},
+		}, {
+			desc:      "incr_allow",
+			asset:     asset,
+			eventType: contractevents.EventTypeIncrAllow,
+			expected: []effect{
+				{
+					order:       1,
+					address:     from,
+					effectType:  history.EffectContractAllowanceChanged,
+					operationID: toid.New(1, 0, 1).ToInt64(),
+					details: map[string]interface{}{
+						"amount":       "0.0012345",
+						"asset_code":   strings.Trim(asset.GetCode(), "\x00"),
+						"asset_issuer": asset.GetIssuer(),
+						"asset_type":   "credit_alphanum12",
+						"direction":    "increase",
+						"from":         from,
+						"spender":      to,
+					},
+				},
+			},
+		}, {
+			desc:      "decr_allow from contract",
+			asset:     asset,
+			from:      fromContract,
+			eventType: contractevents.EventTypeDecrAllow,
+			expected: []effect{
+				{
+					order:       1,
+					address:     admin,
+					effectType:  history.EffectContractAllowanceChanged,
+					operationID: toid.New(1, 0, 1).ToInt64(),
+					details: map[string]interface{}{
+						"amount":       "0.0012345",
+						"asset_code":   strings.Trim(asset.GetCode(), "\x00"),
+						"asset_issuer": asset.GetIssuer(),
+						"asset_type":   "credit_alphanum12",
+						"contract":     fromContract,
+						"direction":    "decrease",
+						"from":         fromContract,
+						"spender":      to,
+					},
+				},
+			},
+		}, {
+			desc:      "set_authorized",
+			asset:     asset,
+			eventType: contractevents.EventTypeSetAuthorized,
+			expected: []effect{
+				{
+					order:       1,
+					address:     to,
+					effectType:  history.EffectContractAuthorizationChanged,
+					operationID: toid.New(1, 0, 1).ToInt64(),
+					details: map[string]interface{}{
+						"admin":        admin,
+						"asset_code":   strings.Trim(asset.GetCode(), "\x00"),
+						"asset_issuer": asset.GetIssuer(),
+						"asset_type":   "credit_alphanum12",
+						"authorized":   true,
+					},
+				},
+			},
+		}, {
+			desc:      "set_authorized contract",
+			asset:     asset,
+			to:        toContract,
+			eventType: contractevents.EventTypeSetAuthorized,
+			expected: []effect{
+				{
+					order:       1,
+					address:     admin,
+					effectType:  history.EffectContractAuthorizationChanged,
+					operationID: toid.New(1, 0, 1).ToInt64(),
+					details: map[string]interface{}{
+						"admin":        admin,
+						"asset_code":   strings.Trim(asset.GetCode(), "\x00"),
+						"asset_issuer": asset.GetIssuer(),
+						"asset_type":   "credit_alphanum12",
+						"authorized":   true,
+						"contract":     toContract,
+					},
+				},
+			},
+		}, {
+			desc:      "set_admin",
+			asset:     asset,
+			to:        toContract,
+			eventType: contractevents.EventTypeSetAdmin,
+			expected: []effect{
+				{
+					order:       1,
+					address:     admin,
+					effectType:  history.EffectContractAdminChanged,
+					operationID: toid.New(1, 0, 1).ToInt64(),
+					details: map[string]interface{}{
+						"admin":        admin,
+						"asset_code":   strings.Trim(asset.GetCode(), "\x00"),
+						"asset_issuer": asset.GetIssuer(),
+						"asset_type":   "credit_alphanum12",
+						"new_admin":    toContract,
+					},
+				},
+			},
 		},
 	}
 
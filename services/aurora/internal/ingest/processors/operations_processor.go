@@ -11,6 +11,7 @@ import (
 
 	"github.com/hcnet/go/amount"
 	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/ingest/processors/effects"
 	"github.com/hcnet/go/protocols/aurora/base"
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/support/contractevents"
@@ -188,61 +189,12 @@ func (operation *transactionOperationWrapper) getSponsor() (*xdr.AccountId, erro
 	return nil, nil
 }
 
-type liquidityPoolDelta struct {
-	ReserveA        xdr.Int64
-	ReserveB        xdr.Int64
-	TotalPoolShares xdr.Int64
-}
-
-var errLiquidityPoolChangeNotFound = errors.New("liquidity pool change not found")
-
-func (operation *transactionOperationWrapper) getLiquidityPoolAndProductDelta(lpID *xdr.PoolId) (*xdr.LiquidityPoolEntry, *liquidityPoolDelta, error) {
+func (operation *transactionOperationWrapper) getLiquidityPoolAndProductDelta(lpID *xdr.PoolId) (*xdr.LiquidityPoolEntry, *effects.LiquidityPoolDelta, error) {
 	changes, err := operation.transaction.GetOperationChanges(operation.index)
 	if err != nil {
 		return nil, nil, err
 	}
-
-	for _, c := range changes {
-		if c.Type != xdr.LedgerEntryTypeLiquidityPool {
-			continue
-		}
-		// The delta can be caused by a full removal or full creation of the liquidity pool
-		var lp *xdr.LiquidityPoolEntry
-		var preA, preB, preShares xdr.Int64
-		if c.Pre != nil {
-			if lpID != nil && c.Pre.Data.LiquidityPool.LiquidityPoolId != *lpID {
-				// if we were looking for specific pool id, then check on it
-				continue
-			}
-			lp = c.Pre.Data.LiquidityPool
-			if c.Pre.Data.LiquidityPool.Body.Type != xdr.LiquidityPoolTypeLiquidityPoolConstantProduct {
-				return nil, nil, fmt.Errorf("unexpected liquity pool body type %d", c.Pre.Data.LiquidityPool.Body.Type)
-			}
-			cpPre := c.Pre.Data.LiquidityPool.Body.ConstantProduct
-			preA, preB, preShares = cpPre.ReserveA, cpPre.ReserveB, cpPre.TotalPoolShares
-		}
-		var postA, postB, postShares xdr.Int64
-		if c.Post != nil {
-			if lpID != nil && c.Post.Data.LiquidityPool.LiquidityPoolId != *lpID {
-				// if we were looking for specific pool id, then check on it
-				continue
-			}
-			lp = c.Post.Data.LiquidityPool
-			if c.Post.Data.LiquidityPool.Body.Type != xdr.LiquidityPoolTypeLiquidityPoolConstantProduct {
-				return nil, nil, fmt.Errorf("unexpected liquity pool body type %d", c.Post.Data.LiquidityPool.Body.Type)
-			}
-			cpPost := c.Post.Data.LiquidityPool.Body.ConstantProduct
-			postA, postB, postShares = cpPost.ReserveA, cpPost.ReserveB, cpPost.TotalPoolShares
-		}
-		delta := &liquidityPoolDelta{
-			ReserveA:        postA - preA,
-			ReserveB:        postB - preB,
-			TotalPoolShares: postShares - preShares,
-		}
-		return lp, delta, nil
-	}
-
-	return nil, nil, errLiquidityPoolChangeNotFound
+	return effects.LiquidityPoolAndProductDelta(changes, lpID)
 }
 
 // OperationResult returns the operation's result record
@@ -2,6 +2,7 @@ package processors
 
 import (
 	"context"
+	"sort"
 
 	"github.com/guregu/null"
 
@@ -122,6 +123,18 @@ func (p *SignersProcessor) removeAccountSigners(ctx context.Context, accountEntr
 }
 
 func (p *SignersProcessor) addAccountSigners(accountEntry xdr.AccountEntry) error {
+	for _, signer := range accountSignerRows(accountEntry) {
+		if err := p.batchInsertBuilder.Add(signer); err != nil {
+			return errors.Wrapf(err, "Error adding signer (%s) to AccountSignersBatchInsertBuilder", signer.Signer)
+		}
+	}
+	return nil
+}
+
+// accountSignerRows returns the rows of the accounts_signers table of an
+// account ordered by signer.
+func accountSignerRows(accountEntry xdr.AccountEntry) []history.AccountSigner {
+	var signers []history.AccountSigner
 	sponsorsPerSigner := accountEntry.SponsorPerSigner()
 	for signer, weight := range accountEntry.SignerSummary() {
 		// Ignore master key
@@ -131,15 +144,15 @@ func (p *SignersProcessor) addAccountSigners(accountEntry xdr.AccountEntry) erro
 				sponsor = null.StringFrom(sponsorDesc.Address())
 			}
 		}
-
-		if err := p.batchInsertBuilder.Add(history.AccountSigner{
+		signers = append(signers, history.AccountSigner{
 			Account: accountEntry.AccountId.Address(),
 			Signer:  signer,
 			Weight:  weight,
 			Sponsor: sponsor,
-		}); err != nil {
-			return errors.Wrapf(err, "Error adding signer (%s) to AccountSignersBatchInsertBuilder", signer)
-		}
+		})
 	}
-	return nil
+	sort.Slice(signers, func(i, j int) bool {
+		return signers[i].Signer < signers[j].Signer
+	})
+	return signers
 }
@@ -0,0 +1,265 @@
+package processors
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/protocols/aurora/effects"
+	"github.com/hcnet/go/protocols/aurora/operations"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/ingest/sinks"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+var sinkEntryTypeNames = map[xdr.LedgerEntryType]string{
+	xdr.LedgerEntryTypeAccount:          "account",
+	xdr.LedgerEntryTypeTrustline:        "trustline",
+	xdr.LedgerEntryTypeOffer:            "offer",
+	xdr.LedgerEntryTypeData:             "data",
+	xdr.LedgerEntryTypeClaimableBalance: "claimable_balance",
+	xdr.LedgerEntryTypeLiquidityPool:    "liquidity_pool",
+	xdr.LedgerEntryTypeContractData:     "contract_data",
+	xdr.LedgerEntryTypeContractCode:     "contract_code",
+	xdr.LedgerEntryTypeConfigSetting:    "config_setting",
+	xdr.LedgerEntryTypeTtl:              "ttl",
+}
+
+// SinkProcessor builds the operations, effects, trades and ledger entry
+// changes of each ledger and writes them to a sink when it's flushed. It can
+// be used both as a change processor and as a transaction processor, the
+// changes and the transactions of a ledger are written together.
+type SinkProcessor struct {
+	sink    sinks.Sink
+	network string
+	ledgers []*sinks.Ledger
+	changes *ingest.ChangeCompactor
+}
+
+func NewSinkProcessor(sink sinks.Sink, network string) *SinkProcessor {
+	return &SinkProcessor{
+		sink:    sink,
+		network: network,
+	}
+}
+
+// ProcessLedger starts the ledger. It must be called for every ledger, even
+// the ledgers without transactions, before their changes are processed.
+func (p *SinkProcessor) ProcessLedger(lcm xdr.LedgerCloseMeta) {
+	if n := len(p.ledgers); n > 0 && p.ledgers[n-1].Sequence == lcm.LedgerSequence() {
+		return
+	}
+	p.ledgers = append(p.ledgers, &sinks.Ledger{
+		Sequence:   lcm.LedgerSequence(),
+		Hash:       lcm.LedgerHash().HexString(),
+		ClosedAt:   time.Unix(int64(lcm.LedgerHeaderHistoryEntry().Header.ScpValue.CloseTime), 0).UTC(),
+		Operations: []sinks.Operation{},
+		Effects:    []sinks.Effect{},
+		Trades:     []sinks.Trade{},
+		Changes:    []sinks.Change{},
+	})
+	p.changes = ingest.NewChangeCompactor()
+}
+
+func (p *SinkProcessor) ProcessChange(ctx context.Context, change ingest.Change) error {
+	if p.changes == nil {
+		return errors.New("ProcessLedger must be called before ProcessChange")
+	}
+	return p.changes.AddChange(change)
+}
+
+// Commit adds the changes processed since the ledger was started to the
+// ledger. The ledger is written to the sink by Flush.
+func (p *SinkProcessor) Commit(ctx context.Context) error {
+	if p.changes == nil {
+		return nil
+	}
+	ledger := p.ledgers[len(p.ledgers)-1]
+	for _, change := range p.changes.GetChanges() {
+		row, err := sinkChange(change)
+		if err != nil {
+			return err
+		}
+		ledger.Changes = append(ledger.Changes, row)
+	}
+	sort.Slice(ledger.Changes, func(i, j int) bool {
+		return ledger.Changes[i].Key < ledger.Changes[j].Key
+	})
+	p.changes = ingest.NewChangeCompactor()
+	return nil
+}
+
+func (p *SinkProcessor) ProcessTransaction(lcm xdr.LedgerCloseMeta, transaction ingest.LedgerTransaction) error {
+	p.ProcessLedger(lcm)
+	ledger := p.ledgers[len(p.ledgers)-1]
+
+	for i, op := range transaction.Envelope.Operations() {
+		operation := transactionOperationWrapper{
+			index:          uint32(i),
+			transaction:    transaction,
+			operation:      op,
+			ledgerSequence: lcm.LedgerSequence(),
+			network:        p.network,
+		}
+		row, err := p.operation(operation)
+		if err != nil {
+			return err
+		}
+		ledger.Operations = append(ledger.Operations, row)
+
+		// the recorder collects the effects like the batch of EffectProcessor
+		recorder := &sinkEffectRecorder{}
+		if err = operation.ingestEffects(history.NewAccountLoader(), recorder); err != nil {
+			return errors.Wrapf(err, "reading operation %v effects", operation.ID())
+		}
+		ledger.Effects = append(ledger.Effects, recorder.effects...)
+	}
+
+	if !transaction.Result.Successful() {
+		return nil
+	}
+	trades, err := (&TradeProcessor{}).extractTrades(lcm.LedgerHeaderHistoryEntry(), transaction)
+	if err != nil {
+		return err
+	}
+	for _, trade := range trades {
+		ledger.Trades = append(ledger.Trades, sinkTrade(trade))
+	}
+	return nil
+}
+
+// Flush writes the ledgers processed since the last flush to the sink.
+func (p *SinkProcessor) Flush(ctx context.Context, session db.SessionInterface) error {
+	if err := p.Commit(ctx); err != nil {
+		return err
+	}
+	for _, ledger := range p.ledgers {
+		if err := p.sink.Write(ctx, *ledger); err != nil {
+			return errors.Wrapf(err, "could not write ledger %d to sink", ledger.Sequence)
+		}
+	}
+	p.ledgers = nil
+	p.changes = nil
+	return nil
+}
+
+func (p *SinkProcessor) operation(operation transactionOperationWrapper) (sinks.Operation, error) {
+	details, err := operation.Details()
+	if err != nil {
+		return sinks.Operation{}, errors.Wrapf(err, "Error obtaining details for operation %v", operation.ID())
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return sinks.Operation{}, errors.Wrapf(err, "Error marshaling details for operation %v", operation.ID())
+	}
+
+	source := operation.SourceAccount()
+	sourceID := source.ToAccountId()
+	row := sinks.Operation{
+		ID:                    operation.ID(),
+		TransactionID:         operation.TransactionID(),
+		TransactionHash:       operation.transaction.Result.TransactionHash.HexString(),
+		TransactionSuccessful: operation.transaction.Result.Successful(),
+		ApplicationOrder:      operation.Order(),
+		Type:                  operations.TypeNames[operation.OperationType()],
+		TypeI:                 int32(operation.OperationType()),
+		SourceAccount:         sourceID.Address(),
+		IsPayment:             operation.IsPayment(),
+		Details:               detailsJSON,
+	}
+	if source.Type == xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
+		row.SourceAccountMuxed = source.Address()
+	}
+	return row, nil
+}
+
+// sinkEffectRecorder implements history.EffectBatchInsertBuilder to collect
+// the effects of an operation.
+type sinkEffectRecorder struct {
+	effects []sinks.Effect
+}
+
+func (r *sinkEffectRecorder) Add(
+	accountID history.FutureAccountID,
+	muxedAccount null.String,
+	operationID int64,
+	order uint32,
+	effectType history.EffectType,
+	details []byte,
+) error {
+	r.effects = append(r.effects, sinks.Effect{
+		OperationID:  operationID,
+		Order:        order,
+		Type:         effects.EffectTypeNames[effects.EffectType(effectType)],
+		TypeI:        int32(effectType),
+		Account:      accountID.Address(),
+		AccountMuxed: muxedAccount.String,
+		Details:      details,
+	})
+	return nil
+}
+
+func (r *sinkEffectRecorder) Exec(ctx context.Context, session db.SessionInterface) error {
+	return nil
+}
+
+func sinkTrade(trade ingestTrade) sinks.Trade {
+	row := trade.row
+	result := sinks.Trade{
+		OperationID:     row.HistoryOperationID,
+		Order:           row.Order,
+		SellerAccount:   trade.sellerAccount,
+		SellerOfferID:   row.BaseOfferID.Int64,
+		LiquidityPoolID: trade.liquidityPoolID,
+		BuyerAccount:    trade.buyerAccount,
+		BuyerOfferID:    row.CounterOfferID.Int64,
+		SoldAsset:       trade.soldAsset.StringCanonical(),
+		SoldAmount:      row.BaseAmount,
+		BoughtAsset:     trade.boughtAsset.StringCanonical(),
+		BoughtAmount:    row.CounterAmount,
+		PriceN:          row.PriceN,
+		PriceD:          row.PriceD,
+	}
+	switch row.Type {
+	case history.LiquidityPoolTradeType:
+		result.Type = history.LiquidityPoolTrades
+		result.LiquidityPoolFee = uint32(row.LiquidityPoolFee.Int64)
+	default:
+		result.Type = history.OrderbookTrades
+	}
+	return result
+}
+
+func sinkChange(change ingest.Change) (sinks.Change, error) {
+	entry := change.Post
+	if entry == nil {
+		entry = change.Pre
+	}
+	key, err := entry.LedgerKey()
+	if err != nil {
+		return sinks.Change{}, errors.Wrap(err, "error getting ledger key")
+	}
+
+	var row sinks.Change
+	row.Type = sinkEntryTypeNames[change.Type]
+	if row.Key, err = key.MarshalBinaryBase64(); err != nil {
+		return sinks.Change{}, errors.Wrap(err, "error marshaling ledger key")
+	}
+	if change.Pre != nil {
+		if row.Pre, err = xdr.MarshalBase64(change.Pre); err != nil {
+			return sinks.Change{}, errors.Wrap(err, "error marshaling ledger entry")
+		}
+	}
+	if change.Post != nil {
+		if row.Post, err = xdr.MarshalBase64(change.Post); err != nil {
+			return sinks.Change{}, errors.Wrap(err, "error marshaling ledger entry")
+		}
+	}
+	return row, nil
+}
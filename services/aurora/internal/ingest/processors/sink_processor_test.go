@@ -0,0 +1,98 @@
+package processors
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/services/aurora/internal/ingest/sinks"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+func sinkTestLedger(sequence uint32) xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Hash: xdr.Hash{byte(sequence)},
+				Header: xdr.LedgerHeader{
+					LedgerSeq: xdr.Uint32(sequence),
+					ScpValue:  xdr.HcnetValue{CloseTime: 1000},
+				},
+			},
+		},
+	}
+}
+
+func TestSinkProcessor(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	processor := NewSinkProcessor(sinks.NewWriterSink(&buf), network.TestNetworkPassphrase)
+
+	first := sinkTestLedger(20)
+	processor.ProcessLedger(first)
+	require.NoError(t, processor.ProcessChange(ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeOffer,
+		Post: stateHistoryTestOffer(100),
+	}))
+	require.NoError(t, processor.Commit(ctx))
+	require.NoError(t, processor.ProcessTransaction(first, createTransaction(true, 1)))
+	require.NoError(t, processor.ProcessTransaction(first, createTransaction(false, 2)))
+
+	// ledgers without transactions are written too
+	processor.ProcessLedger(sinkTestLedger(21))
+	require.NoError(t, processor.Flush(ctx, nil))
+
+	var ledgers []sinks.Ledger
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var ledger sinks.Ledger
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ledger))
+		ledgers = append(ledgers, ledger)
+	}
+	require.Len(t, ledgers, 2)
+
+	assert.Equal(t, uint32(20), ledgers[0].Sequence)
+	assert.Equal(t, int64(1000), ledgers[0].ClosedAt.Unix())
+	require.Len(t, ledgers[0].Operations, 3)
+	assert.Equal(t, "bump_sequence", ledgers[0].Operations[0].Type)
+	assert.True(t, ledgers[0].Operations[0].TransactionSuccessful)
+	assert.False(t, ledgers[0].Operations[1].TransactionSuccessful)
+	assert.Equal(t, "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY", ledgers[0].Operations[2].SourceAccount)
+	require.Len(t, ledgers[0].Changes, 1)
+	assert.Equal(t, "offer", ledgers[0].Changes[0].Type)
+	assert.Empty(t, ledgers[0].Changes[0].Pre)
+	assert.NotEmpty(t, ledgers[0].Changes[0].Post)
+
+	assert.Equal(t, uint32(21), ledgers[1].Sequence)
+	assert.Empty(t, ledgers[1].Operations)
+	assert.Empty(t, ledgers[1].Changes)
+
+	// the ledgers are written only once
+	require.NoError(t, processor.Flush(ctx, nil))
+	assert.Zero(t, buf.Len())
+}
+
+type failingSink struct{}
+
+func (failingSink) Write(ctx context.Context, ledger sinks.Ledger) error {
+	return errors.New("sink is down")
+}
+
+func (failingSink) Close() error {
+	return nil
+}
+
+func TestSinkProcessorWriteError(t *testing.T) {
+	processor := NewSinkProcessor(failingSink{}, network.TestNetworkPassphrase)
+	processor.ProcessLedger(sinkTestLedger(20))
+	err := processor.Flush(context.Background(), nil)
+	assert.EqualError(t, err, "could not write ledger 20 to sink: sink is down")
+}
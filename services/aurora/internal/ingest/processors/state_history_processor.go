@@ -0,0 +1,270 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/guregu/null"
+	"github.com/lib/pq"
+
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// StateHistoryEntryTypes are the ledger entry types recorded in the state
+// history.
+var StateHistoryEntryTypes = []xdr.LedgerEntryType{
+	xdr.LedgerEntryTypeAccount,
+	xdr.LedgerEntryTypeTrustline,
+	xdr.LedgerEntryTypeOffer,
+	xdr.LedgerEntryTypeData,
+	xdr.LedgerEntryTypeClaimableBalance,
+	xdr.LedgerEntryTypeLiquidityPool,
+}
+
+// StateHistoryProcessor records a version of every ledger entry changed in a
+// ledger so the state can be queried at past ledgers. When run on a
+// checkpoint it starts a new state history, when run on a ledger it extends
+// the existing one, unless a ledger is missing in which case the state history
+// is marked as not available until the state is rebuilt.
+type StateHistoryProcessor struct {
+	qStateHistory  history.QStateHistory
+	cache          *ingest.ChangeCompactor
+	sequence       uint32
+	fromCheckpoint bool
+	initialized    bool
+	active         bool
+}
+
+func NewStateHistoryProcessor(Q history.QStateHistory, sequence uint32, fromCheckpoint bool) *StateHistoryProcessor {
+	p := &StateHistoryProcessor{
+		qStateHistory:  Q,
+		sequence:       sequence,
+		fromCheckpoint: fromCheckpoint,
+	}
+	p.reset()
+	return p
+}
+
+func (p *StateHistoryProcessor) reset() {
+	p.cache = ingest.NewChangeCompactor()
+}
+
+// init updates the range of the state history, it's done once per ledger in
+// the ingestion transaction.
+func (p *StateHistoryProcessor) init(ctx context.Context) error {
+	if p.initialized {
+		return nil
+	}
+	p.initialized = true
+
+	if p.fromCheckpoint {
+		if err := p.qStateHistory.TruncateStateHistory(ctx); err != nil {
+			return errors.Wrap(err, "error truncating state history")
+		}
+		if err := p.qStateHistory.UpdateStateHistoryRange(ctx, p.sequence, p.sequence); err != nil {
+			return errors.Wrap(err, "error updating state history range")
+		}
+		p.active = true
+		return nil
+	}
+
+	start, last, err := p.qStateHistory.GetStateHistoryRange(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error getting state history range")
+	}
+
+	switch {
+	case start == 0:
+		// the state history was enabled after the state was ingested
+		return nil
+	case p.sequence <= last:
+		// the ledger was already recorded, versions are replaced
+		p.active = true
+	case p.sequence != last+1:
+		log.WithField("last_ledger", last).
+			WithField("ledger", p.sequence).
+			Warn("State history is missing ledgers and is no longer available, " +
+				"run `aurora ingest trigger-state-rebuild` to start a new state history")
+		if err := p.qStateHistory.UpdateStateHistoryRange(ctx, 0, 0); err != nil {
+			return errors.Wrap(err, "error updating state history range")
+		}
+	default:
+		if err := p.qStateHistory.UpdateStateHistoryRange(ctx, start, p.sequence); err != nil {
+			return errors.Wrap(err, "error updating state history range")
+		}
+		p.active = true
+	}
+	return nil
+}
+
+func (p *StateHistoryProcessor) ProcessChange(ctx context.Context, change ingest.Change) error {
+	if !isStateHistoryEntryType(change.Type) {
+		return nil
+	}
+
+	if err := p.init(ctx); err != nil {
+		return err
+	}
+	if !p.active {
+		return nil
+	}
+
+	err := p.cache.AddChange(change)
+	if err != nil {
+		return errors.Wrap(err, "error adding to ledgerCache")
+	}
+
+	if p.cache.Size() > maxBatchSize {
+		err = p.Commit(ctx)
+		if err != nil {
+			return errors.Wrap(err, "error in Commit")
+		}
+		p.reset()
+	}
+
+	return nil
+}
+
+func (p *StateHistoryProcessor) Commit(ctx context.Context) error {
+	if err := p.init(ctx); err != nil {
+		return err
+	}
+	if !p.active {
+		return nil
+	}
+
+	changes := p.cache.GetChanges()
+	entries := make([]history.StateHistoryEntry, 0, len(changes))
+	for _, change := range changes {
+		entry, err := p.changeToRow(change)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) > 0 {
+		if err := p.qStateHistory.UpsertStateHistoryEntries(ctx, entries); err != nil {
+			return errors.Wrap(err, "error upserting state history entries")
+		}
+	}
+	return nil
+}
+
+func (p *StateHistoryProcessor) changeToRow(change ingest.Change) (history.StateHistoryEntry, error) {
+	entry, removed := change.Post, false
+	if entry == nil {
+		entry, removed = change.Pre, true
+	}
+
+	key, err := entry.LedgerKey()
+	if err != nil {
+		return history.StateHistoryEntry{}, errors.Wrap(err, "error getting ledger key")
+	}
+	keyString, err := key.MarshalBinaryBase64()
+	if err != nil {
+		return history.StateHistoryEntry{}, errors.Wrap(err, "error marshaling ledger key")
+	}
+
+	row := history.StateHistoryEntry{
+		LedgerKey:      keyString,
+		LedgerSequence: p.sequence,
+		EntryType:      entry.Data.Type,
+		Accounts:       stateHistoryAccounts(*entry),
+		Removed:        removed,
+	}
+	if offer, ok := entry.Data.GetOffer(); ok {
+		row.OfferID = null.IntFrom(int64(offer.OfferId))
+	}
+	if !removed {
+		if row.LedgerEntry, err = xdr.MarshalBase64(entry); err != nil {
+			return history.StateHistoryEntry{}, errors.Wrap(err, "error marshaling ledger entry")
+		}
+	}
+	return row, nil
+}
+
+func isStateHistoryEntryType(entryType xdr.LedgerEntryType) bool {
+	for _, t := range StateHistoryEntryTypes {
+		if t == entryType {
+			return true
+		}
+	}
+	return false
+}
+
+// stateHistoryAccounts returns the accounts an entry can be looked up by: its
+// owner, the claimants of a claimable balance and its sponsor.
+func stateHistoryAccounts(entry xdr.LedgerEntry) pq.StringArray {
+	var accounts pq.StringArray
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeAccount:
+		accounts = append(accounts, entry.Data.MustAccount().AccountId.Address())
+	case xdr.LedgerEntryTypeTrustline:
+		accounts = append(accounts, entry.Data.MustTrustLine().AccountId.Address())
+	case xdr.LedgerEntryTypeOffer:
+		accounts = append(accounts, entry.Data.MustOffer().SellerId.Address())
+	case xdr.LedgerEntryTypeData:
+		accounts = append(accounts, entry.Data.MustData().AccountId.Address())
+	case xdr.LedgerEntryTypeClaimableBalance:
+		for _, claimant := range entry.Data.MustClaimableBalance().Claimants {
+			accounts = append(accounts, claimant.MustV0().Destination.Address())
+		}
+	}
+	if sponsor := entry.SponsoringID(); sponsor != nil {
+		accounts = append(accounts, sponsor.Address())
+	}
+	return accounts
+}
+
+// StateRows holds the rows of the state tables built from ledger entries
+// loaded from the state history.
+type StateRows struct {
+	Accounts          []history.AccountEntry
+	Signers           []history.AccountSigner
+	Data              []history.Data
+	TrustLines        []history.TrustLine
+	Offers            []history.Offer
+	ClaimableBalances []history.ClaimableBalance
+	LiquidityPools    []history.LiquidityPool
+}
+
+// NewStateRows decodes state history entries and converts them to the rows
+// ingested in the state tables, in the same order as the entries.
+func NewStateRows(entries []history.StateHistoryEntry) (StateRows, error) {
+	var rows StateRows
+	for _, row := range entries {
+		var entry xdr.LedgerEntry
+		if err := xdr.SafeUnmarshalBase64(row.LedgerEntry, &entry); err != nil {
+			return rows, errors.Wrapf(err, "error decoding state history entry %s", row.LedgerKey)
+		}
+
+		switch entry.Data.Type {
+		case xdr.LedgerEntryTypeAccount:
+			account := entry.Data.MustAccount()
+			rows.Accounts = append(rows.Accounts, (&AccountsProcessor{}).ledgerEntryToRow(entry))
+			rows.Signers = append(rows.Signers, accountSignerRows(account)...)
+		case xdr.LedgerEntryTypeTrustline:
+			trustLine, err := xdrToTrustline(entry)
+			if err != nil {
+				return rows, err
+			}
+			rows.TrustLines = append(rows.TrustLines, trustLine)
+		case xdr.LedgerEntryTypeOffer:
+			rows.Offers = append(rows.Offers, (&OffersProcessor{}).ledgerEntryToRow(&entry))
+		case xdr.LedgerEntryTypeData:
+			rows.Data = append(rows.Data, (&AccountDataProcessor{}).ledgerEntryToRow(&entry))
+		case xdr.LedgerEntryTypeClaimableBalance:
+			cBalance, err := (&ClaimableBalancesChangeProcessor{}).ledgerEntryToRow(&entry)
+			if err != nil {
+				return rows, err
+			}
+			rows.ClaimableBalances = append(rows.ClaimableBalances, cBalance)
+		case xdr.LedgerEntryTypeLiquidityPool:
+			rows.LiquidityPools = append(rows.LiquidityPools, (&LiquidityPoolsChangeProcessor{}).ledgerEntryToRow(&entry))
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,135 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/xdr"
+)
+
+func stateHistoryTestOffer(amount xdr.Int64) *xdr.LedgerEntry {
+	return &xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 123,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeOffer,
+			Offer: &xdr.OfferEntry{
+				SellerId: xdr.MustAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"),
+				OfferId:  7,
+				Selling:  xdr.MustNewNativeAsset(),
+				Buying:   xdr.MustNewCreditAsset("USD", "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"),
+				Amount:   amount,
+				Price:    xdr.Price{N: 1, D: 2},
+			},
+		},
+	}
+}
+
+func TestStateHistoryProcessorFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	q := &history.MockQStateHistory{}
+	defer q.AssertExpectations(t)
+
+	offer := stateHistoryTestOffer(100)
+	q.On("TruncateStateHistory", ctx).Return(nil).Once()
+	q.On("UpdateStateHistoryRange", ctx, uint32(63), uint32(63)).Return(nil).Once()
+	q.On("UpsertStateHistoryEntries", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		entries := args.Get(1).([]history.StateHistoryEntry)
+		require.Len(t, entries, 1)
+		assert.Equal(t, uint32(63), entries[0].LedgerSequence)
+		assert.Equal(t, xdr.LedgerEntryTypeOffer, entries[0].EntryType)
+		assert.Equal(t, null.IntFrom(7), entries[0].OfferID)
+		assert.Equal(t, []string{"GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"}, []string(entries[0].Accounts))
+		assert.False(t, entries[0].Removed)
+
+		rows, err := NewStateRows(entries)
+		require.NoError(t, err)
+		require.Len(t, rows.Offers, 1)
+		assert.Equal(t, int64(7), rows.Offers[0].OfferID)
+		assert.Equal(t, int64(100), rows.Offers[0].Amount)
+	}).Return(nil).Once()
+
+	processor := NewStateHistoryProcessor(q, 63, true)
+	require.NoError(t, processor.ProcessChange(ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeOffer,
+		Post: offer,
+	}))
+	// entries which aren't recorded are ignored
+	require.NoError(t, processor.ProcessChange(ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeContractCode,
+	}))
+	require.NoError(t, processor.Commit(ctx))
+}
+
+func TestStateHistoryProcessorFromLedger(t *testing.T) {
+	ctx := context.Background()
+	q := &history.MockQStateHistory{}
+	defer q.AssertExpectations(t)
+
+	q.On("GetStateHistoryRange", ctx).Return(uint32(63), uint32(99), nil).Once()
+	q.On("UpdateStateHistoryRange", ctx, uint32(63), uint32(100)).Return(nil).Once()
+	q.On("UpsertStateHistoryEntries", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		entries := args.Get(1).([]history.StateHistoryEntry)
+		require.Len(t, entries, 1)
+		assert.Equal(t, uint32(100), entries[0].LedgerSequence)
+		assert.True(t, entries[0].Removed)
+		assert.Empty(t, entries[0].LedgerEntry)
+	}).Return(nil).Once()
+
+	processor := NewStateHistoryProcessor(q, 100, false)
+	require.NoError(t, processor.ProcessChange(ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeOffer,
+		Pre:  stateHistoryTestOffer(100),
+	}))
+	require.NoError(t, processor.Commit(ctx))
+}
+
+func TestStateHistoryProcessorEmptyLedger(t *testing.T) {
+	ctx := context.Background()
+	q := &history.MockQStateHistory{}
+	defer q.AssertExpectations(t)
+
+	// the range is extended even if no entries changed
+	q.On("GetStateHistoryRange", ctx).Return(uint32(63), uint32(99), nil).Once()
+	q.On("UpdateStateHistoryRange", ctx, uint32(63), uint32(100)).Return(nil).Once()
+
+	processor := NewStateHistoryProcessor(q, 100, false)
+	require.NoError(t, processor.Commit(ctx))
+}
+
+func TestStateHistoryProcessorNotAvailable(t *testing.T) {
+	ctx := context.Background()
+	q := &history.MockQStateHistory{}
+	defer q.AssertExpectations(t)
+
+	q.On("GetStateHistoryRange", ctx).Return(uint32(0), uint32(0), nil).Once()
+
+	processor := NewStateHistoryProcessor(q, 100, false)
+	require.NoError(t, processor.ProcessChange(ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeOffer,
+		Post: stateHistoryTestOffer(100),
+	}))
+	require.NoError(t, processor.Commit(ctx))
+}
+
+func TestStateHistoryProcessorGap(t *testing.T) {
+	ctx := context.Background()
+	q := &history.MockQStateHistory{}
+	defer q.AssertExpectations(t)
+
+	q.On("GetStateHistoryRange", ctx).Return(uint32(63), uint32(90), nil).Once()
+	q.On("UpdateStateHistoryRange", ctx, uint32(0), uint32(0)).Return(nil).Once()
+
+	processor := NewStateHistoryProcessor(q, 100, false)
+	require.NoError(t, processor.ProcessChange(ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeOffer,
+		Post: stateHistoryTestOffer(100),
+	}))
+	require.NoError(t, processor.Commit(ctx))
+}
@@ -0,0 +1,136 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+)
+
+// Publisher publishes messages to a message broker, for example a Kafka topic
+// or a NATS subject. Publish must return only once the broker acknowledged the
+// message.
+type Publisher interface {
+	Publish(ctx context.Context, key string, value []byte) error
+	Close() error
+}
+
+// BrokerConfig configures the retries of a BrokerSink.
+type BrokerConfig struct {
+	// MaxAttempts is the number of attempts to publish a ledger before Write
+	// returns an error. It defaults to 5.
+	MaxAttempts int
+	// InitialBackoff is the delay after the first failed attempt, it doubles
+	// after each following attempt. It defaults to 1 second.
+	InitialBackoff time.Duration
+}
+
+// BrokerSink publishes a message per ledger using a Publisher. The messages
+// are delivered at least once: a ledger is published again when ingestion of
+// the ledger is retried. The key of each message is the ledger sequence so
+// brokers which partition or deduplicate by key keep the messages of a ledger
+// together.
+type BrokerSink struct {
+	publisher Publisher
+	config    BrokerConfig
+}
+
+// NewBrokerSink returns a BrokerSink publishing with publisher.
+func NewBrokerSink(publisher Publisher, config BrokerConfig) *BrokerSink {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 5
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = time.Second
+	}
+	return &BrokerSink{publisher: publisher, config: config}
+}
+
+// Write publishes the ledger, retrying failed attempts.
+func (s *BrokerSink) Write(ctx context.Context, ledger Ledger) error {
+	value, err := json.Marshal(ledger)
+	if err != nil {
+		return errors.Wrapf(err, "could not encode ledger %d", ledger.Sequence)
+	}
+	key := strconv.FormatUint(uint64(ledger.Sequence), 10)
+
+	delay := s.config.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err = s.publisher.Publish(ctx, key, value)
+		if err == nil {
+			return nil
+		}
+		if attempt >= s.config.MaxAttempts {
+			return errors.Wrapf(err, "could not publish ledger %d after %d attempts", ledger.Sequence, attempt)
+		}
+		log.WithField("ledger", ledger.Sequence).
+			WithField("attempt", attempt).
+			WithError(err).
+			Warn("Could not publish ledger, retrying")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// Close closes the publisher.
+func (s *BrokerSink) Close() error {
+	return s.publisher.Close()
+}
+
+// HTTPPublisher publishes messages with HTTP POST requests, which is supported
+// by the HTTP bridges of most message brokers. The body of the request is the
+// message and the key is sent in the Idempotency-Key header.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPPublisher returns a HTTPPublisher posting to url. If client is nil a
+// client with a 30 seconds timeout is used.
+func NewHTTPPublisher(url string, client *http.Client) *HTTPPublisher {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &HTTPPublisher{url: url, client: client}
+}
+
+// Publish posts the message. Responses with a status other than 2xx are
+// errors.
+func (p *HTTPPublisher) Publish(ctx context.Context, key string, value []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(value))
+	if err != nil {
+		return errors.Wrap(err, "could not create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "could not send request")
+	}
+	defer resp.Body.Close()
+	// drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return nil
+}
+
+// Close does nothing, it's defined to implement Publisher.
+func (p *HTTPPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,77 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// FileSink writes each ledger as a line of JSON.
+type FileSink struct {
+	mutex  sync.Mutex
+	writer io.Writer
+	file   *os.File
+	sync   bool
+}
+
+// NewFileSink opens the file at path for appending, creating it if needed.
+// The path can be a named pipe in which case the call blocks until a reader
+// opens the other end of the pipe.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", path)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "could not stat %s", path)
+	}
+	return &FileSink{
+		writer: file,
+		file:   file,
+		// pipes and character devices can't be synced
+		sync: info.Mode().IsRegular(),
+	}, nil
+}
+
+// NewWriterSink returns a FileSink writing to w. Closing the sink doesn't
+// close w.
+func NewWriterSink(w io.Writer) *FileSink {
+	return &FileSink{writer: w}
+}
+
+// Write appends the ledger to the file. Regular files are synced before Write
+// returns so a ledger which was written can't be lost.
+func (s *FileSink) Write(ctx context.Context, ledger Ledger) error {
+	line, err := json.Marshal(ledger)
+	if err != nil {
+		return errors.Wrapf(err, "could not encode ledger %d", ledger.Sequence)
+	}
+	line = append(line, '\n')
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err = s.writer.Write(line); err != nil {
+		return errors.Wrapf(err, "could not write ledger %d", ledger.Sequence)
+	}
+	if s.sync {
+		if err = s.file.Sync(); err != nil {
+			return errors.Wrapf(err, "could not sync ledger %d", ledger.Sequence)
+		}
+	}
+	return nil
+}
+
+// Close closes the file opened by NewFileSink.
+func (s *FileSink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
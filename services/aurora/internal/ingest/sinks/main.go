@@ -0,0 +1,121 @@
+// Package sinks contains the destinations, other than the aurora database, to
+// which ingestion can deliver the data it processed. A sink receives a single
+// Ledger value per ingested ledger containing the operations, effects and
+// trades of the ledger along with the ledger entries it changed.
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// Sink receives the data of the ingested ledgers.
+//
+// Ledgers are written in order but the same ledger can be written more than
+// once, for example when the ingestion of a ledger is retried after a failure
+// or when a range of ledgers is reingested. Consumers must use the ledger
+// sequence to detect duplicates.
+type Sink interface {
+	// Write delivers the data of a ledger. Ingestion of the ledger fails, and
+	// is retried, if Write returns an error.
+	Write(ctx context.Context, ledger Ledger) error
+	Close() error
+}
+
+// Ledger contains the data of a single ledger as processed by ingestion.
+type Ledger struct {
+	Sequence   uint32      `json:"sequence"`
+	Hash       string      `json:"hash"`
+	ClosedAt   time.Time   `json:"closed_at"`
+	Operations []Operation `json:"operations"`
+	Effects    []Effect    `json:"effects"`
+	Trades     []Trade     `json:"trades"`
+	Changes    []Change    `json:"changes"`
+}
+
+// Operation is an operation of a transaction included in the ledger. The
+// details are the same as the ones stored in the history_operations table.
+type Operation struct {
+	ID                    int64           `json:"id,string"`
+	TransactionID         int64           `json:"transaction_id,string"`
+	TransactionHash       string          `json:"transaction_hash"`
+	TransactionSuccessful bool            `json:"transaction_successful"`
+	ApplicationOrder      uint32          `json:"application_order"`
+	Type                  string          `json:"type"`
+	TypeI                 int32           `json:"type_i"`
+	SourceAccount         string          `json:"source_account"`
+	SourceAccountMuxed    string          `json:"source_account_muxed,omitempty"`
+	IsPayment             bool            `json:"is_payment"`
+	Details               json.RawMessage `json:"details"`
+}
+
+// Effect is an effect of a successful operation.
+type Effect struct {
+	OperationID  int64           `json:"operation_id,string"`
+	Order        uint32          `json:"order"`
+	Type         string          `json:"type"`
+	TypeI        int32           `json:"type_i"`
+	Account      string          `json:"account"`
+	AccountMuxed string          `json:"account_muxed,omitempty"`
+	Details      json.RawMessage `json:"details"`
+}
+
+// Trade is an exchange between the source account of an operation (the
+// buyer) and an offer or a liquidity pool (the seller). Amounts are in
+// stroops and the price is the price of the sold asset in terms of the bought
+// asset.
+type Trade struct {
+	OperationID      int64  `json:"operation_id,string"`
+	Order            int32  `json:"order"`
+	Type             string `json:"type"`
+	SellerAccount    string `json:"seller_account,omitempty"`
+	SellerOfferID    int64  `json:"seller_offer_id,string,omitempty"`
+	LiquidityPoolID  string `json:"liquidity_pool_id,omitempty"`
+	LiquidityPoolFee uint32 `json:"liquidity_pool_fee,omitempty"`
+	BuyerAccount     string `json:"buyer_account"`
+	BuyerOfferID     int64  `json:"buyer_offer_id,string"`
+	SoldAsset        string `json:"sold_asset"`
+	SoldAmount       int64  `json:"sold_amount,string"`
+	BoughtAsset      string `json:"bought_asset"`
+	BoughtAmount     int64  `json:"bought_amount,string"`
+	PriceN           int64  `json:"price_n,string"`
+	PriceD           int64  `json:"price_d,string"`
+}
+
+// Change is a change of a ledger entry. The key and the entries are base64
+// encoded XDR. Pre is empty when the entry was created and Post is empty when
+// the entry was removed.
+type Change struct {
+	Type string `json:"type"`
+	Key  string `json:"key"`
+	Pre  string `json:"pre,omitempty"`
+	Post string `json:"post,omitempty"`
+}
+
+// New returns the sink configured by the given url:
+//
+//   - file:///path/to/file appends a JSON line per ledger to the file, which
+//     can also be a named pipe (see NewFileSink),
+//   - http://host/path and https://host/path publish a message per ledger
+//     with an HTTP POST request (see NewHTTPPublisher).
+func New(rawURL string) (Sink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid sink url")
+	}
+	switch u.Scheme {
+	case "file":
+		if u.Path == "" {
+			return nil, errors.New("file sink url must contain a path")
+		}
+		return NewFileSink(u.Path)
+	case "http", "https":
+		return NewBrokerSink(NewHTTPPublisher(rawURL, nil), BrokerConfig{}), nil
+	default:
+		return nil, errors.Errorf("unsupported sink url scheme: %q", u.Scheme)
+	}
+}
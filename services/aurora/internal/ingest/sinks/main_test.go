@@ -0,0 +1,129 @@
+package sinks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledgers.jsonl")
+	sink, err := New("file://" + path)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, sink.Write(ctx, Ledger{Sequence: 10, Operations: []Operation{{ID: 42, Type: "payment"}}}))
+	require.NoError(t, sink.Write(ctx, Ledger{Sequence: 11}))
+	require.NoError(t, sink.Close())
+
+	// ledgers are appended to the existing file
+	sink, err = New("file://" + path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(ctx, Ledger{Sequence: 11}))
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var sequences []uint32
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var ledger Ledger
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ledger))
+		sequences = append(sequences, ledger.Sequence)
+		if ledger.Sequence == 10 {
+			assert.Equal(t, int64(42), ledger.Operations[0].ID)
+		}
+	}
+	assert.Equal(t, []uint32{10, 11, 11}, sequences)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("ftp://example.com")
+	assert.EqualError(t, err, `unsupported sink url scheme: "ftp"`)
+
+	_, err = New("file://")
+	assert.EqualError(t, err, "file sink url must contain a path")
+
+	sink, err := New("https://example.com/topics/ledgers")
+	require.NoError(t, err)
+	assert.IsType(t, &BrokerSink{}, sink)
+}
+
+type testPublisher struct {
+	failures int
+	keys     []string
+	values   [][]byte
+}
+
+func (p *testPublisher) Publish(ctx context.Context, key string, value []byte) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("broker unavailable")
+	}
+	p.keys = append(p.keys, key)
+	p.values = append(p.values, value)
+	return nil
+}
+
+func (p *testPublisher) Close() error {
+	return nil
+}
+
+func TestBrokerSinkRetries(t *testing.T) {
+	publisher := &testPublisher{failures: 2}
+	sink := NewBrokerSink(publisher, BrokerConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+
+	require.NoError(t, sink.Write(context.Background(), Ledger{Sequence: 100}))
+	assert.Equal(t, []string{"100"}, publisher.keys)
+
+	var ledger Ledger
+	require.NoError(t, json.Unmarshal(publisher.values[0], &ledger))
+	assert.Equal(t, uint32(100), ledger.Sequence)
+}
+
+func TestBrokerSinkMaxAttempts(t *testing.T) {
+	publisher := &testPublisher{failures: 3}
+	sink := NewBrokerSink(publisher, BrokerConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+
+	err := sink.Write(context.Background(), Ledger{Sequence: 100})
+	assert.EqualError(t, err, "could not publish ledger 100 after 3 attempts: broker unavailable")
+	assert.Empty(t, publisher.keys)
+}
+
+func TestHTTPPublisher(t *testing.T) {
+	var keys []string
+	var bodies []string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		bodies = append(bodies, string(body))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	publisher := NewHTTPPublisher(server.URL, nil)
+	require.NoError(t, publisher.Publish(context.Background(), "7", []byte(`{"sequence":7}`)))
+	assert.Equal(t, []string{"7"}, keys)
+	assert.Equal(t, []string{`{"sequence":7}`}, bodies)
+
+	status = http.StatusServiceUnavailable
+	err := publisher.Publish(context.Background(), "8", []byte(`{"sequence":8}`))
+	assert.EqualError(t, err, "unexpected response status: 503 Service Unavailable")
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/hcnet/go/services/aurora/internal/db2"
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/services/aurora/internal/ingest/processors"
+	"github.com/hcnet/go/strkey"
 	"github.com/hcnet/go/support/errors"
 	logpkg "github.com/hcnet/go/support/log"
 	"github.com/hcnet/go/xdr"
@@ -30,7 +31,7 @@ const assetStatsBatchSize = 500
 // check them.
 // There is a test that checks it, to fix it: update the actual `verifyState`
 // method instead of just updating this value!
-const stateVerifierExpectedIngestionVersion = 18
+const stateVerifierExpectedIngestionVersion = 19
 
 // verifyState is called as a go routine from pipeline post hook every 64
 // ledgers. It checks if the state is correct. If another go routine is already
@@ -171,9 +172,9 @@ func (s *system) verifyState(verifyAgainstLatestCheckpoint bool) error {
 
 	verifier := verify.NewStateVerifier(stateReader, func(entry xdr.LedgerEntry) (bool, xdr.LedgerEntry) {
 		entryType := entry.Data.Type
-		// Won't be persisting protocol 20 ContractData ledger entries (except for Hcnet Asset Contract
-		// ledger entries) to the history db, therefore must not allow it
-		// to be counted in history state-verifier accumulators.
+		// Won't be persisting protocol 20 ConfigSetting and ContractCode ledger entries
+		// to the history db, therefore must not allow it to be counted in history
+		// state-verifier accumulators.
 		if entryType == xdr.LedgerEntryTypeConfigSetting || entryType == xdr.LedgerEntryTypeContractCode {
 			return true, entry
 		}
@@ -202,6 +203,7 @@ func (s *system) verifyState(verifyAgainstLatestCheckpoint bool) error {
 		trustLines := make([]xdr.LedgerKeyTrustLine, 0, verifyBatchSize)
 		cBalances := make([]xdr.ClaimableBalanceId, 0, verifyBatchSize)
 		lPools := make([]xdr.PoolId, 0, verifyBatchSize)
+		contractData := make([]string, 0, verifyBatchSize)
 		for _, entry := range entries {
 			switch entry.Data.Type {
 			case xdr.LedgerEntryTypeAccount:
@@ -231,12 +233,12 @@ func (s *system) verifyState(verifyAgainstLatestCheckpoint bool) error {
 				lPools = append(lPools, entry.Data.MustLiquidityPool().LiquidityPoolId)
 				totalByType["liquidity_pools"]++
 			case xdr.LedgerEntryTypeContractData:
-				// contract data is a special case.
-				// we don't store contract data entries in the db,
-				// however, we ingest contract data entries for asset stats.
-				if err = verifier.Write(entry); err != nil {
-					return err
+				keyHash, keyErr := processors.ContractDataKeyHash(entry)
+				if keyErr != nil {
+					return errors.Wrap(keyErr, "ContractDataEntry.LedgerKey")
 				}
+				contractData = append(contractData, keyHash)
+				// contract data entries are also ingested for asset stats.
 				contractDataEntries = append(contractDataEntries, entry)
 				totalByType["contract_data"]++
 			case xdr.LedgerEntryTypeTtl:
@@ -284,6 +286,11 @@ func (s *system) verifyState(verifyAgainstLatestCheckpoint bool) error {
 			return errors.Wrap(err, "addLiquidityPoolsToStateVerifier failed")
 		}
 
+		err = addContractDataToStateVerifier(ctx, verifier, historyQ, contractData)
+		if err != nil {
+			return errors.Wrap(err, "addContractDataToStateVerifier failed")
+		}
+
 		total += int64(len(entries))
 		localLog.WithField("total", total).Info("Batch added to StateVerifier")
 	}
@@ -338,9 +345,14 @@ func (s *system) verifyState(verifyAgainstLatestCheckpoint bool) error {
 		return errors.Wrap(err, "Error running historyQ.CountLiquidityPools")
 	}
 
+	countContractData, err := historyQ.CountContractData(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Error running historyQ.CountContractData")
+	}
+
 	err = verifier.Verify(
 		countAccounts + countData + countOffers + countTrustLines + countClaimableBalances +
-			countLiquidityPools + int(totalByType["contract_data"]) + int(totalByType["ttl"]),
+			countLiquidityPools + countContractData + int(totalByType["ttl"]),
 	)
 	if err != nil {
 		return errors.Wrap(err, "verifier.Verify failed")
@@ -1063,6 +1075,67 @@ func addLiquidityPoolsToStateVerifier(
 	return nil
 }
 
+func addContractDataToStateVerifier(
+	ctx context.Context,
+	verifier *verify.StateVerifier,
+	q history.IngestionQ,
+	keyHashes []string,
+) error {
+	if len(keyHashes) == 0 {
+		return nil
+	}
+
+	rows, err := q.GetContractDataByKeyHashes(ctx, keyHashes)
+	if err != nil {
+		return errors.Wrap(err, "Error running history.Q.GetContractDataByKeyHashes")
+	}
+
+	for _, row := range rows {
+		entry, err := contractDataToXDR(row)
+		if err != nil {
+			return errors.Wrap(err, "Invalid contract data row")
+		}
+		if err := verifier.Write(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func contractDataToXDR(row history.ContractDataEntry) (xdr.LedgerEntry, error) {
+	contractID, err := strkey.Decode(strkey.VersionByteContract, row.ContractID)
+	if err != nil {
+		return xdr.LedgerEntry{}, errors.Wrap(err, "Error decoding contract id")
+	}
+	var contractHash xdr.Hash
+	copy(contractHash[:], contractID)
+
+	var key, val xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(row.Key, &key); err != nil {
+		return xdr.LedgerEntry{}, errors.Wrap(err, "Error decoding key")
+	}
+	if err := xdr.SafeUnmarshalBase64(row.Value, &val); err != nil {
+		return xdr.LedgerEntry{}, errors.Wrap(err, "Error decoding value")
+	}
+
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: xdr.Uint32(row.LastModifiedLedger),
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeContractData,
+			ContractData: &xdr.ContractDataEntry{
+				Contract: xdr.ScAddress{
+					Type:       xdr.ScAddressTypeScAddressTypeContract,
+					ContractId: &contractHash,
+				},
+				Key:        key,
+				Durability: xdr.ContractDataDurability(row.Durability),
+				Val:        val,
+			},
+		},
+	}, nil
+}
+
 func liquidityPoolToXDR(row history.LiquidityPool) (xdr.LiquidityPoolEntry, error) {
 	if len(row.AssetReserves) != 2 {
 		return xdr.LiquidityPoolEntry{}, fmt.Errorf("unexpected number of asset reserves (%d), expected %d", len(row.AssetReserves), 2)
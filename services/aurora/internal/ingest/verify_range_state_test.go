@@ -476,10 +476,47 @@ func (s *VerifyRangeStateTestSuite) TestSuccessWithVerify() {
 		},
 	}
 
+	counterSym := xdr.ScSymbol("counter")
+	counterVal := xdr.Uint32(7)
+	contractDataChange := ingest.Change{
+		Type: xdr.LedgerEntryTypeContractData,
+		Pre:  nil,
+		Post: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeContractData,
+				ContractData: &xdr.ContractDataEntry{
+					Contract: xdr.ScAddress{
+						Type:       xdr.ScAddressTypeScAddressTypeContract,
+						ContractId: &xdr.Hash{0xca, 0xfe},
+					},
+					Key: xdr.ScVal{
+						Type: xdr.ScValTypeScvSymbol,
+						Sym:  &counterSym,
+					},
+					Durability: xdr.ContractDataDurabilityPersistent,
+					Val: xdr.ScVal{
+						Type: xdr.ScValTypeScvU32,
+						U32:  &counterVal,
+					},
+				},
+			},
+			LastModifiedLedgerSeq: xdr.Uint32(62),
+		},
+	}
+	contractDataKeyHash, err := processors.ContractDataKeyHash(*contractDataChange.Post)
+	s.Assert().NoError(err)
+	contractDataID, err := contractDataChange.Post.Data.ContractData.Contract.String()
+	s.Assert().NoError(err)
+	contractDataKey, err := xdr.MarshalBase64(contractDataChange.Post.Data.ContractData.Key)
+	s.Assert().NoError(err)
+	contractDataValue, err := xdr.MarshalBase64(contractDataChange.Post.Data.ContractData.Val)
+	s.Assert().NoError(err)
+
 	mockChangeReader.On("Read").Return(accountChange, nil).Once()
 	mockChangeReader.On("Read").Return(offerChange, nil).Once()
 	mockChangeReader.On("Read").Return(claimableBalanceChange, nil).Once()
 	mockChangeReader.On("Read").Return(liquidityPoolChange, nil).Once()
+	mockChangeReader.On("Read").Return(contractDataChange, nil).Once()
 	mockChangeReader.On("Read").Return(ingest.Change{}, io.EOF).Once()
 	mockChangeReader.On("Read").Return(ingest.Change{}, io.EOF).Once()
 	s.historyAdapter.On("GetState", s.ctx, uint32(63)).Return(mockChangeReader, nil).Once()
@@ -594,6 +631,20 @@ func (s *VerifyRangeStateTestSuite) TestSuccessWithVerify() {
 		On("GetLiquidityPoolsByID", s.ctx, []string{liquidityPool.PoolID}).
 		Return([]history.LiquidityPool{liquidityPool}, nil).Once()
 
+	clonedQ.MockQContractData.On("CountContractData", s.ctx).Return(1, nil).Once()
+	clonedQ.MockQContractData.
+		On("GetContractDataByKeyHashes", s.ctx, []string{contractDataKeyHash}).
+		Return([]history.ContractDataEntry{
+			{
+				KeyHash:            contractDataKeyHash,
+				ContractID:         contractDataID,
+				Durability:         int32(xdr.ContractDataDurabilityPersistent),
+				Key:                contractDataKey,
+				Value:              contractDataValue,
+				LastModifiedLedger: 62,
+			},
+		}, nil).Once()
+
 	next, err := verifyRangeState{
 		fromLedger: 100, toLedger: 110, verifyState: true,
 	}.run(s.system)
@@ -11,6 +11,7 @@ import (
 	"github.com/hcnet/go/exp/orderbook"
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/services/aurora/internal/ingest"
+	"github.com/hcnet/go/services/aurora/internal/ingest/sinks"
 	"github.com/hcnet/go/services/aurora/internal/paths"
 	"github.com/hcnet/go/services/aurora/internal/simplepath"
 	"github.com/hcnet/go/services/aurora/internal/txsub"
@@ -92,6 +93,12 @@ func mustInitAuroraDB(app *App) {
 func initIngester(app *App) {
 	var err error
 	var coreSession db.SessionInterface
+	if app.config.IngestionSink != "" {
+		app.ingestionSink, err = sinks.New(app.config.IngestionSink)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 	app.ingester, err = ingest.NewSystem(ingest.Config{
 		CoreSession: coreSession,
 		HistorySession: mustNewDBSession(
@@ -114,6 +121,9 @@ func initIngester(app *App) {
 		EnableExtendedLogLedgerStats:         app.config.IngestEnableExtendedLogLedgerStats,
 		RoundingSlippageFilter:               app.config.RoundingSlippageFilter,
 		EnableIngestionFiltering:             app.config.EnableIngestionFiltering,
+		EnableStateHistory:                   app.config.EnableStateHistory,
+		Sink:                                 app.ingestionSink,
+		LedgerBackend:                        app.config.LedgerBackend,
 	})
 
 	if err != nil {
@@ -130,6 +140,7 @@ func initPathFinder(app *App) {
 		&history.Q{app.AuroraSession()},
 		orderBookGraph,
 	)
+	app.orderBookStream.SnapshotPath = app.config.OrderBookSnapshotPath
 
 	var finder paths.Finder = simplepath.NewInMemoryFinder(orderBookGraph, !app.config.DisablePoolPathFinding)
 	if app.config.MaxPathFindingRequests != 0 {
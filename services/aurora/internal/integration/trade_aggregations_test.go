@@ -95,6 +95,9 @@ func TestTradeAggregations(t *testing.T) {
 					OpenD:         10000,
 					CloseN:        23456,
 					CloseD:        10000,
+
+					LiquidityPoolBaseVolume:    "0",
+					LiquidityPoolCounterVolume: "0",
 				},
 			},
 		},
@@ -151,6 +154,9 @@ func TestTradeAggregations(t *testing.T) {
 					OpenD:         10000,
 					CloseN:        13456,
 					CloseD:        10000,
+
+					LiquidityPoolBaseVolume:    "0",
+					LiquidityPoolCounterVolume: "0",
 				},
 			},
 		},
@@ -207,6 +213,9 @@ func TestTradeAggregations(t *testing.T) {
 					OpenD:         10000,
 					CloseN:        13456,
 					CloseD:        10000,
+
+					LiquidityPoolBaseVolume:    "0",
+					LiquidityPoolCounterVolume: "0",
 				},
 			},
 		},
@@ -265,6 +274,72 @@ func TestTradeAggregations(t *testing.T) {
 					OpenD:         10000,
 					CloseN:        23456,
 					CloseD:        10000,
+
+					LiquidityPoolBaseVolume:    "0",
+					LiquidityPoolCounterVolume: "0",
+				},
+			},
+		},
+		{
+			name: "liquidity pool volumes with offset",
+			trades: []history.InsertTrade{
+				{
+					HistoryOperationID: 0,
+					Order:              0,
+					LedgerCloseTime:    now.ToTime().Add(5 * time.Second),
+					BaseAccountID:      null.IntFrom(accounts[itest.Master().Address()]),
+					CounterAccountID:   null.IntFrom(accounts[itest.Master().Address()]),
+					BaseAssetID:        baseAssetId,
+					BaseAmount:         int64(200),
+					BaseOfferID:        null.IntFrom(int64(400)),
+					BaseIsSeller:       true,
+					CounterAmount:      int64(100),
+					CounterAssetID:     counterAssetId,
+					PriceN:             1,
+					PriceD:             2,
+					Type:               history.OrderbookTradeType,
+				},
+				{
+					HistoryOperationID:  0,
+					Order:               1,
+					LedgerCloseTime:     now.ToTime().Add(5 * time.Second),
+					BaseAccountID:       null.IntFrom(accounts[itest.Master().Address()]),
+					CounterAccountID:    null.IntFrom(accounts[itest.Master().Address()]),
+					BaseAssetID:         baseAssetId,
+					BaseAmount:          int64(100),
+					BaseLiquidityPoolID: null.IntFrom(int64(500)),
+					LiquidityPoolFee:    null.IntFrom(30),
+					BaseIsSeller:        true,
+					CounterAmount:       int64(200),
+					CounterAssetID:      counterAssetId,
+					PriceN:              2,
+					PriceD:              1,
+					Type:                history.LiquidityPoolTradeType,
+					RoundingSlippage:    null.IntFrom(10),
+				},
+			},
+			resolution: 86_400_000,
+			offset:     3_600_000,
+			pq:         db2.PageQuery{Limit: 100},
+			expected: []history.TradeAggregation{
+				{
+					Timestamp:     (now - 3_600_000).RoundDown(86_400_000).ToInt64() + 3_600_000,
+					TradeCount:    2,
+					BaseVolume:    "300",
+					CounterVolume: "300",
+					Average:       1,
+					HighN:         2,
+					HighD:         1,
+					LowN:          1,
+					LowD:          2,
+					OpenN:         1,
+					OpenD:         2,
+					CloseN:        2,
+					CloseD:        1,
+
+					LiquidityPoolTradeCount:    1,
+					LiquidityPoolBaseVolume:    "100",
+					LiquidityPoolCounterVolume: "200",
 				},
 			},
 		},
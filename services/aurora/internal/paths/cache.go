@@ -0,0 +1,114 @@
+package paths
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// DefaultQuoteCacheSize is the number of requests a QuoteCache keeps the paths
+// of when no size is given.
+const DefaultQuoteCacheSize = 1000
+
+// QuoteCache caches the payment paths found for requests. The paths found for
+// a request are only valid for the ledger they were found at so the cache only
+// keeps the paths of the most recent ledger: all the cached paths are dropped
+// as soon as paths for a more recent ledger are looked up or added, i.e. once
+// the order book applied a new ledger.
+//
+// The cached payment paths are shared between callers and must not be
+// modified.
+type QuoteCache struct {
+	lock   sync.Mutex
+	ledger uint32
+	quotes *lru.Cache
+}
+
+// NewQuoteCache constructs a new QuoteCache which keeps the paths of at most
+// size requests, or DefaultQuoteCacheSize requests if size is not positive.
+func NewQuoteCache(size int) *QuoteCache {
+	if size <= 0 {
+		size = DefaultQuoteCacheSize
+	}
+	// lru.New only fails when the size is not positive
+	quotes, _ := lru.New(size)
+	return &QuoteCache{quotes: quotes}
+}
+
+// Get returns the paths of request found at the given ledger.
+func (c *QuoteCache) Get(ledger uint32, request Request, maxLength uint) ([]Path, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.setLedger(ledger)
+	if c.ledger != ledger {
+		return nil, false
+	}
+	cached, ok := c.quotes.Get(requestKey(request, maxLength))
+	if !ok {
+		return nil, false
+	}
+	return cached.([]Path), true
+}
+
+// Add caches the paths of request found at the given ledger. Paths found at a
+// ledger older than the ledger of the cached paths are ignored.
+func (c *QuoteCache) Add(ledger uint32, request Request, maxLength uint, paths []Path) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.setLedger(ledger)
+	if c.ledger == ledger {
+		c.quotes.Add(requestKey(request, maxLength), paths)
+	}
+}
+
+// setLedger drops the cached paths if ledger is more recent than the ledger
+// they were found at. It must be called with the lock held.
+func (c *QuoteCache) setLedger(ledger uint32) {
+	if ledger > c.ledger {
+		c.quotes.Purge()
+		c.ledger = ledger
+	}
+}
+
+// requestKey returns the cache key of a request. Requests with the same key
+// have the same payment paths in a given ledger.
+func requestKey(request Request, maxLength uint) string {
+	var b strings.Builder
+	b.WriteString(strconv.FormatUint(uint64(maxLength), 10))
+	if request.StrictSend {
+		b.WriteString("|send|")
+		b.WriteString(request.SourceAsset.String())
+		b.WriteString("|")
+		b.WriteString(strconv.FormatInt(int64(request.AmountToSpend), 10))
+		for _, asset := range request.DestinationAssets {
+			b.WriteString("|")
+			b.WriteString(asset.String())
+		}
+		return b.String()
+	}
+
+	q := request.Query
+	b.WriteString("|receive|")
+	b.WriteString(q.DestinationAsset.String())
+	b.WriteString("|")
+	b.WriteString(strconv.FormatInt(int64(q.DestinationAmount), 10))
+	b.WriteString("|")
+	if q.SourceAccount != nil {
+		b.WriteString(q.SourceAccount.Address())
+	}
+	b.WriteString("|")
+	b.WriteString(strconv.FormatBool(q.ValidateSourceBalance))
+	for i, asset := range q.SourceAssets {
+		b.WriteString("|")
+		b.WriteString(asset.String())
+		if q.ValidateSourceBalance && i < len(q.SourceAssetBalances) {
+			b.WriteString(":")
+			b.WriteString(strconv.FormatInt(int64(q.SourceAssetBalances[i]), 10))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package paths
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hcnet/go/xdr"
+)
+
+func TestQuoteCache(t *testing.T) {
+	usd := xdr.MustNewCreditAsset("USD", "GDUKMGUGDZQK6YHYA5Z6AY2G4XDSZPSZ3SW5UN3ARVMO6QSRDWP5YLEX")
+	native := xdr.MustNewNativeAsset()
+	strictReceive := Request{Query: Query{
+		DestinationAsset:    usd,
+		DestinationAmount:   10,
+		SourceAssets:        []xdr.Asset{native},
+		SourceAssetBalances: []xdr.Int64{100},
+	}}
+	strictSend := Request{
+		StrictSend:        true,
+		SourceAsset:       native,
+		AmountToSpend:     10,
+		DestinationAssets: []xdr.Asset{usd},
+	}
+	receivePaths := []Path{{Source: native.String(), SourceAmount: 5, Destination: usd.String(), DestinationAmount: 10}}
+	sendPaths := []Path{{Source: native.String(), SourceAmount: 10, Destination: usd.String(), DestinationAmount: 20}}
+
+	cache := NewQuoteCache(10)
+	_, ok := cache.Get(10, strictReceive, 3)
+	assert.False(t, ok)
+
+	cache.Add(10, strictReceive, 3, receivePaths)
+	cache.Add(10, strictSend, 3, sendPaths)
+	cached, ok := cache.Get(10, strictReceive, 3)
+	assert.True(t, ok)
+	assert.Equal(t, receivePaths, cached)
+	cached, ok = cache.Get(10, strictSend, 3)
+	assert.True(t, ok)
+	assert.Equal(t, sendPaths, cached)
+
+	// the max length is part of the cache key
+	_, ok = cache.Get(10, strictSend, 4)
+	assert.False(t, ok)
+
+	// paths of older ledgers are not cached
+	cache.Add(9, strictSend, 4, sendPaths)
+	_, ok = cache.Get(10, strictSend, 4)
+	assert.False(t, ok)
+
+	// a new ledger invalidates the cache
+	_, ok = cache.Get(11, strictReceive, 3)
+	assert.False(t, ok)
+	_, ok = cache.Get(10, strictReceive, 3)
+	assert.False(t, ok)
+	cache.Add(11, strictReceive, 3, []Path{})
+	cached, ok = cache.Get(11, strictReceive, 3)
+	assert.True(t, ok)
+	assert.Empty(t, cached)
+}
+
+func TestQuoteCacheSize(t *testing.T) {
+	native := xdr.MustNewNativeAsset()
+	cache := NewQuoteCache(1)
+	first := Request{StrictSend: true, SourceAsset: native, AmountToSpend: 10}
+	second := Request{StrictSend: true, SourceAsset: native, AmountToSpend: 20}
+
+	cache.Add(1, first, 3, []Path{})
+	cache.Add(1, second, 3, []Path{})
+	_, ok := cache.Get(1, first, 3)
+	assert.False(t, ok)
+	_, ok = cache.Get(1, second, 3)
+	assert.True(t, ok)
+}
+
+func TestRequestKey(t *testing.T) {
+	native := xdr.MustNewNativeAsset()
+	request := Request{Query: Query{
+		DestinationAsset:    native,
+		DestinationAmount:   10,
+		SourceAssets:        []xdr.Asset{native},
+		SourceAssetBalances: []xdr.Int64{100},
+	}}
+	other := request
+	other.Query.SourceAssetBalances = []xdr.Int64{200}
+	// balances are ignored unless they are validated
+	assert.Equal(t, requestKey(request, 3), requestKey(other, 3))
+
+	request.Query.ValidateSourceBalance = true
+	other.Query.ValidateSourceBalance = true
+	assert.NotEqual(t, requestKey(request, 3), requestKey(other, 3))
+	assert.NotEqual(t, requestKey(request, 3), requestKey(request, 4))
+}
@@ -3,6 +3,7 @@ package paths
 import (
 	"context"
 
+	"github.com/hcnet/go/exp/orderbook"
 	"github.com/hcnet/go/xdr"
 )
 
@@ -27,6 +28,18 @@ type Path struct {
 	DestinationAmount xdr.Int64
 }
 
+// Request is a single request of a batch of path finding requests. It's a
+// strict receive request described by Query unless StrictSend is true, in
+// which case it's a strict send request spending AmountToSpend of SourceAsset.
+type Request struct {
+	StrictSend bool
+	Query      Query
+
+	SourceAsset       xdr.Asset
+	AmountToSpend     xdr.Int64
+	DestinationAssets []xdr.Asset
+}
+
 // Finder finds paths.
 type Finder interface {
 	// Find returns a list of payment paths and the most recent ledger
@@ -44,4 +57,12 @@ type Finder interface {
 		destinationAssets []xdr.Asset,
 		maxLength uint,
 	) ([]Path, uint32, error)
+	// FindBatch returns the payment paths of each of the requests, in the same
+	// order as the requests, and the most recent ledger. All the payment paths
+	// are consistent with the returned ledger sequence number.
+	FindBatch(ctx context.Context, requests []Request, maxLength uint) ([][]Path, uint32, error)
+	// Depth returns the depth curve and the price impact of a trade for a
+	// trading pair, along with the most recent ledger. The results are
+	// consistent with the returned ledger sequence number.
+	Depth(ctx context.Context, q orderbook.DepthQuery) (orderbook.Depth, uint32, error)
 }
@@ -3,6 +3,7 @@ package paths
 import (
 	"context"
 
+	"github.com/hcnet/go/exp/orderbook"
 	"github.com/hcnet/go/xdr"
 	"github.com/stretchr/testify/mock"
 )
@@ -31,3 +32,15 @@ func (m *MockFinder) FindFixedPaths(
 
 	return args.Get(0).([]Path), args.Get(1).(uint32), args.Error(2)
 }
+
+func (m *MockFinder) FindBatch(ctx context.Context, requests []Request, maxLength uint) ([][]Path, uint32, error) {
+	args := m.Called(ctx, requests, maxLength)
+
+	return args.Get(0).([][]Path), args.Get(1).(uint32), args.Error(2)
+}
+
+func (m *MockFinder) Depth(ctx context.Context, q orderbook.DepthQuery) (orderbook.Depth, uint32, error) {
+	args := m.Called(ctx, q)
+
+	return args.Get(0).(orderbook.Depth), args.Get(1).(uint32), args.Error(2)
+}
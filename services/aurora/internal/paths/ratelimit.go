@@ -2,9 +2,11 @@ package paths
 
 import (
 	"context"
+	"time"
 
 	"golang.org/x/time/rate"
 
+	"github.com/hcnet/go/exp/orderbook"
 	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/xdr"
 )
@@ -57,3 +59,22 @@ func (f *RateLimitedFinder) FindFixedPaths(
 	}
 	return f.finder.FindFixedPaths(ctx, sourceAsset, amountToSpend, destinationAssets, maxLength)
 }
+
+// FindBatch implements the Finder interface. Each request of the batch counts
+// towards the limit and ErrRateLimitExceeded is returned if the RateLimitedFinder
+// is unable to complete all of them.
+func (f *RateLimitedFinder) FindBatch(ctx context.Context, requests []Request, maxLength uint) ([][]Path, uint32, error) {
+	if !f.limiter.AllowN(time.Now(), len(requests)) {
+		return nil, 0, ErrRateLimitExceeded
+	}
+	return f.finder.FindBatch(ctx, requests, maxLength)
+}
+
+// Depth implements the Finder interface and returns ErrRateLimitExceeded if the
+// RateLimitedFinder is unable to complete the request due to rate limits.
+func (f *RateLimitedFinder) Depth(ctx context.Context, q orderbook.DepthQuery) (orderbook.Depth, uint32, error) {
+	if !f.limiter.Allow() {
+		return orderbook.Depth{}, 0, ErrRateLimitExceeded
+	}
+	return f.finder.Depth(ctx, q)
+}
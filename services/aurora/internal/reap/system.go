@@ -31,6 +31,11 @@ func (r *System) DeleteUnretainedHistory(ctx context.Context) error {
 		return err
 	}
 
+	err = r.clearStateHistoryBefore(ctx, targetElder)
+	if err != nil {
+		return err
+	}
+
 	log.
 		WithField("new_elder", targetElder).
 		Info("reaper succeeded")
@@ -38,6 +43,35 @@ func (r *System) DeleteUnretainedHistory(ctx context.Context) error {
 	return nil
 }
 
+// clearStateHistoryBefore removes the versions of ledger entries which are
+// only needed to query the state before the new elder ledger.
+func (r *System) clearStateHistoryBefore(ctx context.Context, elder int32) error {
+	if elder <= 0 {
+		return nil
+	}
+
+	err := r.HistoryQ.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Error in begin")
+	}
+	defer r.HistoryQ.Rollback()
+
+	removed, err := r.HistoryQ.ReapStateHistory(ctx, uint32(elder))
+	if err != nil {
+		return errors.Wrap(err, "Error in ReapStateHistory")
+	}
+
+	err = r.HistoryQ.Commit()
+	if err != nil {
+		return errors.Wrap(err, "Error in commit")
+	}
+
+	if removed > 0 {
+		log.WithField("rows_removed", removed).Info("reaper: cleared state history")
+	}
+	return nil
+}
+
 // Run triggers the reaper system to update itself, deleted unretained history
 // if it is the appropriate time.
 func (r *System) Run() {
@@ -40,6 +40,37 @@ var (
 			"headers.",
 	}
 
+	// APIKeyQuotaExceeded is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	APIKeyQuotaExceeded = problem.P{
+		Type:   "api_key_quota_exceeded",
+		Title:  "API Key Quota Exceeded",
+		Status: 429,
+		Detail: "The API key of the request is over its alloted quota for this " +
+			"class of endpoints.  The allowed limit and requests left per time " +
+			"period are communicated to clients via the http response headers " +
+			"'X-RateLimit-*' headers.",
+	}
+
+	// InvalidAPIKey is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	InvalidAPIKey = problem.P{
+		Type:   "invalid_api_key",
+		Title:  "Invalid API Key",
+		Status: http.StatusUnauthorized,
+		Detail: "The API key of the request is unknown or has been revoked.",
+	}
+
+	// APIKeyForbidden is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	APIKeyForbidden = problem.P{
+		Type:   "api_key_forbidden",
+		Title:  "Forbidden For API Key",
+		Status: http.StatusForbidden,
+		Detail: "The API key of the request is not allowed to use this class " +
+			"of endpoints.",
+	}
+
 	// NotImplemented is a well-known problem type.  Use it as a shortcut
 	// in your actions.
 	NotImplemented = problem.P{
@@ -12,9 +12,16 @@ type LedgerSourceFactory interface {
 	Get() ledger.Source
 }
 
+// RateLimitFunc charges an update of the stream of a request, returning true
+// when the stream is rate limited.
+type RateLimitFunc func(r *http.Request) (bool, error)
+
 // StreamHandler represents a stream handling action
 type StreamHandler struct {
-	RateLimiter         *throttled.HTTPRateLimiter
+	RateLimiter *throttled.HTTPRateLimiter
+	// RateLimit, when set, is charged with every update instead of
+	// RateLimiter.
+	RateLimit           RateLimitFunc
 	LedgerSourceFactory LedgerSourceFactory
 }
 
@@ -41,17 +48,9 @@ func (handler StreamHandler) ServeStream(
 	for {
 		// Rate limit the request if it's a call to stream since it queries the DB every second. See
 		// https://github.com/hcnet/go/issues/715 for more details.
-		rateLimiter := handler.RateLimiter
-		if rateLimiter != nil {
-			limited, _, err := rateLimiter.RateLimiter.RateLimit(rateLimiter.VaryBy.Key(r), 1)
-			if err != nil {
-				stream.Err(errors.Wrap(err, "RateLimiter error"))
-				return
-			}
-			if limited {
-				stream.Err(ErrRateLimited)
-				return
-			}
+		if err := handler.rateLimit(r); err != nil {
+			stream.Err(err)
+			return
 		}
 
 		events, err := generateEvents()
@@ -86,3 +85,21 @@ func (handler StreamHandler) ServeStream(
 		}
 	}
 }
+
+// rateLimit charges an update of the stream of the request.
+func (handler StreamHandler) rateLimit(r *http.Request) error {
+	var limited bool
+	var err error
+	if handler.RateLimit != nil {
+		limited, err = handler.RateLimit(r)
+	} else if rateLimiter := handler.RateLimiter; rateLimiter != nil {
+		limited, _, err = rateLimiter.RateLimiter.RateLimit(rateLimiter.VaryBy.Key(r), 1)
+	}
+	if err != nil {
+		return errors.Wrap(err, "RateLimiter error")
+	}
+	if limited {
+		return ErrRateLimited
+	}
+	return nil
+}
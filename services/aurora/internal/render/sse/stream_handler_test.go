@@ -4,8 +4,11 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/hcnet/go/services/aurora/internal/ledger"
 )
 
@@ -42,3 +45,32 @@ func TestSendByeByeOnContextDone(t *testing.T) {
 		t.Fatalf("expected '%v' but got '%v'", expected, got)
 	}
 }
+
+func TestStreamRateLimit(t *testing.T) {
+	ledgerSource := ledger.NewTestingSource(1)
+	updates := 0
+	handler := StreamHandler{
+		LedgerSourceFactory: &testingFactory{ledgerSource},
+		RateLimit: func(r *http.Request) (bool, error) {
+			updates++
+			return updates > 1, nil
+		},
+	}
+	r := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeStream(w, r, 10, func() ([]Event, error) {
+			return []Event{{Data: "update"}}, nil
+		})
+		close(done)
+	}()
+
+	// the second update is rate limited
+	ledgerSource.AddLedger(2)
+	<-done
+	assert.Equal(t, 2, updates)
+	assert.Equal(t, 1, strings.Count(w.Body.String(), `data: "update"`))
+	assert.Contains(t, w.Body.String(), "event: error")
+}
@@ -0,0 +1,55 @@
+package resourceadapter
+
+import (
+	"context"
+
+	protocol "github.com/hcnet/go/protocols/aurora"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/render/hal"
+	"github.com/hcnet/go/xdr"
+)
+
+// PopulateContractEvent fills out the resource's fields
+func PopulateContractEvent(
+	ctx context.Context,
+	dest *protocol.ContractEvent,
+	row history.ContractEvent,
+	ledger history.Ledger,
+) {
+	dest.ID = row.ID()
+	dest.PT = row.PagingToken()
+	dest.ContractID = row.ContractID
+	dest.Ledger = row.LedgerSequence()
+	dest.LedgerClosedAt = ledger.ClosedAt
+	dest.Topics = []string(row.Topics)
+	if dest.Topics == nil {
+		dest.Topics = []string{}
+	}
+	dest.Value = row.Value
+
+	lb := hal.LinkBuilder{Base: auroraContext.BaseURL(ctx)}
+	dest.Links.Operation = lb.Linkf("/operations/%d", row.HistoryOperationID)
+}
+
+// PopulateContractDataEntry fills out the resource's fields
+func PopulateContractDataEntry(dest *protocol.ContractDataEntry, row history.ContractDataEntry) {
+	dest.ID = row.KeyHash
+	dest.PT = row.PagingToken()
+	dest.ContractID = row.ContractID
+	dest.Durability = contractDataDurabilityName(xdr.ContractDataDurability(row.Durability))
+	dest.Key = row.Key
+	dest.Value = row.Value
+	dest.LastModifiedLedger = row.LastModifiedLedger
+}
+
+func contractDataDurabilityName(durability xdr.ContractDataDurability) string {
+	switch durability {
+	case xdr.ContractDataDurabilityPersistent:
+		return "persistent"
+	case xdr.ContractDataDurabilityTemporary:
+		return "temporary"
+	default:
+		return "unknown"
+	}
+}
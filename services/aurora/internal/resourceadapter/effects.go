@@ -66,6 +66,9 @@ var EffectTypeNames = map[history.EffectType]string{
 	history.EffectLiquidityPoolRevoked:               "liquidity_pool_revoked",
 	history.EffectContractCredited:                   "contract_credited",
 	history.EffectContractDebited:                    "contract_debited",
+	history.EffectContractAllowanceChanged:           "contract_allowance_changed",
+	history.EffectContractAuthorizationChanged:       "contract_authorization_changed",
+	history.EffectContractAdminChanged:               "contract_admin_changed",
 }
 
 // NewEffect creates a new effect resource from the provided database representation
@@ -291,6 +294,18 @@ func NewEffect(
 		e := effects.ContractDebited{Base: basev}
 		err = row.UnmarshalDetails(&e)
 		result = e
+	case history.EffectContractAllowanceChanged:
+		e := effects.ContractAllowanceChanged{Base: basev}
+		err = row.UnmarshalDetails(&e)
+		result = e
+	case history.EffectContractAuthorizationChanged:
+		e := effects.ContractAuthorizationChanged{Base: basev}
+		err = row.UnmarshalDetails(&e)
+		result = e
+	case history.EffectContractAdminChanged:
+		e := effects.ContractAdminChanged{Base: basev}
+		err = row.UnmarshalDetails(&e)
+		result = e
 	case history.EffectAccountRemoved:
 		// there is no explicit data structure for account removed
 		fallthrough
@@ -0,0 +1,89 @@
+package resourceadapter
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/hcnet/go/amount"
+	"github.com/hcnet/go/exp/orderbook"
+	protocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// PopulateOrderBookDepth fills out the depth analytics of the trading pair
+// selling sourceAsset for destinationAsset.
+func PopulateOrderBookDepth(
+	ctx context.Context,
+	dest *protocol.OrderBookDepth,
+	sourceAsset, destinationAsset xdr.Asset,
+	depth orderbook.Depth,
+) error {
+	if err := sourceAsset.Extract(
+		&dest.SourceAssetType, &dest.SourceAssetCode, &dest.SourceAssetIssuer,
+	); err != nil {
+		return errors.Wrap(err, "could not extract source asset")
+	}
+	if err := destinationAsset.Extract(
+		&dest.DestinationAssetType, &dest.DestinationAssetCode, &dest.DestinationAssetIssuer,
+	); err != nil {
+		return errors.Wrap(err, "could not extract destination asset")
+	}
+
+	dest.BestPrice = formatPrice(depth.BestPrice)
+	if depth.LiquidityPool != nil {
+		dest.LiquidityPool = xdr.Hash(*depth.LiquidityPool).HexString()
+	}
+
+	dest.Levels = make([]protocol.OrderBookDepthLevel, len(depth.Levels))
+	for i, level := range depth.Levels {
+		dest.Levels[i] = protocol.OrderBookDepthLevel{
+			MaxSlippageBps: level.SlippageBips,
+			PriceLimit:     formatPrice(level.PriceLimit),
+			TradeExecution: tradeExecution(level.Execution),
+		}
+	}
+
+	for _, execution := range []struct {
+		dest   **protocol.TradeExecution
+		source *orderbook.Execution
+	}{
+		{&dest.Execution, depth.Execution},
+		{&dest.OffersOnly, depth.OffersOnly},
+		{&dest.LiquidityPoolOnly, depth.LiquidityPoolOnly},
+	} {
+		if execution.source != nil {
+			populated := tradeExecution(*execution.source)
+			*execution.dest = &populated
+		}
+	}
+	return nil
+}
+
+func tradeExecution(execution orderbook.Execution) protocol.TradeExecution {
+	result := protocol.TradeExecution{
+		SourceAmount:      amount.String(execution.SourceAmount),
+		DestinationAmount: amount.String(execution.DestinationAmount),
+		Price:             formatPrice(execution.Price),
+		Offers: protocol.VenueFill{
+			SourceAmount:      amount.String(execution.Offers.SourceAmount),
+			DestinationAmount: amount.String(execution.Offers.DestinationAmount),
+		},
+		OffersCount: execution.OffersCount,
+		LiquidityPool: protocol.VenueFill{
+			SourceAmount:      amount.String(execution.LiquidityPool.SourceAmount),
+			DestinationAmount: amount.String(execution.LiquidityPool.DestinationAmount),
+		},
+	}
+	if execution.Slippage != nil {
+		result.SlippageBps = execution.Slippage.FloatString(2)
+	}
+	return result
+}
+
+func formatPrice(price *big.Rat) string {
+	if price == nil {
+		return ""
+	}
+	return price.FloatString(7)
+}
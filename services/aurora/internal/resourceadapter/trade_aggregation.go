@@ -2,11 +2,13 @@ package resourceadapter
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/hcnet/go/amount"
 	"github.com/hcnet/go/price"
 	protocol "github.com/hcnet/go/protocols/aurora"
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/errors"
 )
 
 // PopulateTradeAggregation fills out the details of a trade aggregation using a row from the trade aggregations
@@ -48,5 +50,38 @@ func PopulateTradeAggregation(
 		D: row.CloseD,
 	}
 	dest.Close = dest.CloseR.String()
+
+	dest.LiquidityPoolTradeCount = row.LiquidityPoolTradeCount
+	dest.LiquidityPoolBaseVolume, err = amount.IntStringToAmount(row.LiquidityPoolBaseVolume)
+	if err != nil {
+		return err
+	}
+	dest.LiquidityPoolCounterVolume, err = amount.IntStringToAmount(row.LiquidityPoolCounterVolume)
+	if err != nil {
+		return err
+	}
+	dest.OrderbookTradeCount = row.TradeCount - row.LiquidityPoolTradeCount
+	dest.OrderbookBaseVolume, err = volumeDifference(row.BaseVolume, row.LiquidityPoolBaseVolume)
+	if err != nil {
+		return err
+	}
+	dest.OrderbookCounterVolume, err = volumeDifference(row.CounterVolume, row.LiquidityPoolCounterVolume)
+	if err != nil {
+		return err
+	}
 	return nil
 }
+
+// volumeDifference returns the amount of total - part, both being integer
+// strings as stored in the trade aggregation tables.
+func volumeDifference(total, part string) (string, error) {
+	t, ok := new(big.Int).SetString(total, 10)
+	if !ok {
+		return "", errors.Errorf("invalid volume %s", total)
+	}
+	p, ok := new(big.Int).SetString(part, 10)
+	if !ok {
+		return "", errors.Errorf("invalid volume %s", part)
+	}
+	return amount.IntStringToAmount(t.Sub(t, p).String())
+}
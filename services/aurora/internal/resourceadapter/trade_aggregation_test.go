@@ -0,0 +1,48 @@
+package resourceadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	protocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+)
+
+func TestPopulateTradeAggregation(t *testing.T) {
+	row := history.TradeAggregation{
+		Timestamp:     1_600_000_000_000,
+		TradeCount:    3,
+		BaseVolume:    "30000000",
+		CounterVolume: "60000000",
+		Average:       2,
+		HighN:         3,
+		HighD:         1,
+		LowN:          1,
+		LowD:          1,
+		OpenN:         1,
+		OpenD:         1,
+		CloseN:        3,
+		CloseD:        1,
+
+		LiquidityPoolTradeCount:    1,
+		LiquidityPoolBaseVolume:    "10000000",
+		LiquidityPoolCounterVolume: "25000000",
+	}
+
+	var dest protocol.TradeAggregation
+	assert.NoError(t, PopulateTradeAggregation(context.Background(), &dest, row))
+	assert.Equal(t, "3.0000000", dest.BaseVolume)
+	assert.Equal(t, "6.0000000", dest.CounterVolume)
+	assert.Equal(t, "2.0000000", dest.Average)
+	assert.Equal(t, int64(2), dest.OrderbookTradeCount)
+	assert.Equal(t, "2.0000000", dest.OrderbookBaseVolume)
+	assert.Equal(t, "3.5000000", dest.OrderbookCounterVolume)
+	assert.Equal(t, int64(1), dest.LiquidityPoolTradeCount)
+	assert.Equal(t, "1.0000000", dest.LiquidityPoolBaseVolume)
+	assert.Equal(t, "2.5000000", dest.LiquidityPoolCounterVolume)
+
+	row.LiquidityPoolBaseVolume = "invalid"
+	assert.Error(t, PopulateTradeAggregation(context.Background(), &dest, row))
+}
@@ -25,6 +25,7 @@ var (
 type InMemoryFinder struct {
 	graph        *orderbook.OrderBookGraph
 	includePools bool
+	quotes       *paths.QuoteCache
 }
 
 // NewInMemoryFinder constructs a new InMemoryFinder instance
@@ -32,6 +33,7 @@ func NewInMemoryFinder(graph *orderbook.OrderBookGraph, includePools bool) InMem
 	return InMemoryFinder{
 		graph:        graph,
 		includePools: includePools,
+		quotes:       paths.NewQuoteCache(paths.DefaultQuoteCacheSize),
 	}
 }
 
@@ -117,3 +119,117 @@ func (finder InMemoryFinder) FindFixedPaths(
 	}
 	return results, lastLedger, err
 }
+
+// FindBatch returns the payment paths of each of the requests. All the
+// requests are evaluated against the same state of the in memory orderbook and
+// the requests sharing the same starting point are answered by a single
+// search. The paths are cached until the orderbook applies a new ledger.
+func (finder InMemoryFinder) FindBatch(
+	ctx context.Context,
+	requests []paths.Request,
+	maxLength uint,
+) ([][]paths.Path, uint32, error) {
+	if finder.graph.IsEmpty() {
+		return nil, 0, ErrEmptyInMemoryOrderBook
+	}
+
+	if maxLength == 0 {
+		maxLength = MaxInMemoryPathLength
+	}
+	if maxLength > MaxInMemoryPathLength {
+		return nil, 0, errors.New("invalid value of maxLength")
+	}
+
+	ledger := finder.graph.LastLedger()
+	results := make([][]paths.Path, len(requests))
+	var missing []int
+	for i, request := range requests {
+		if cached, ok := finder.quotes.Get(ledger, request, maxLength); ok {
+			results[i] = cached
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return results, ledger, nil
+	}
+
+	found, foundLedger, err := finder.findBatch(ctx, requests, missing, maxLength)
+	if err != nil {
+		return nil, foundLedger, err
+	}
+	if foundLedger != ledger && len(missing) < len(requests) {
+		// the orderbook applied a new ledger after the cache was read so the
+		// cached paths are not consistent with the paths which were found
+		missing = missing[:0]
+		for i := range requests {
+			missing = append(missing, i)
+		}
+		if found, foundLedger, err = finder.findBatch(ctx, requests, missing, maxLength); err != nil {
+			return nil, foundLedger, err
+		}
+	}
+
+	for i, index := range missing {
+		results[index] = found[i]
+		finder.quotes.Add(foundLedger, requests[index], maxLength, found[i])
+	}
+	return results, foundLedger, nil
+}
+
+// findBatch searches the paths of the requests at the given indexes.
+func (finder InMemoryFinder) findBatch(
+	ctx context.Context,
+	requests []paths.Request,
+	indexes []int,
+	maxLength uint,
+) ([][]paths.Path, uint32, error) {
+	queries := make([]orderbook.PathsQuery, len(indexes))
+	for i, index := range indexes {
+		request := requests[index]
+		queries[i] = orderbook.PathsQuery{
+			StrictSend:            request.StrictSend,
+			DestinationAsset:      request.Query.DestinationAsset,
+			DestinationAmount:     request.Query.DestinationAmount,
+			SourceAccountID:       request.Query.SourceAccount,
+			SourceAssets:          request.Query.SourceAssets,
+			SourceAssetBalances:   request.Query.SourceAssetBalances,
+			ValidateSourceBalance: request.Query.ValidateSourceBalance,
+			SourceAsset:           request.SourceAsset,
+			AmountToSpend:         request.AmountToSpend,
+			DestinationAssets:     request.DestinationAssets,
+		}
+	}
+
+	orderbookResults, lastLedger, err := finder.graph.FindPathsBatch(
+		ctx,
+		int(maxLength),
+		queries,
+		maxAssetsPerPath,
+		finder.includePools,
+	)
+	results := make([][]paths.Path, len(orderbookResults))
+	for i, orderbookPaths := range orderbookResults {
+		results[i] = make([]paths.Path, len(orderbookPaths))
+		for j, path := range orderbookPaths {
+			results[i][j] = paths.Path{
+				Path:              path.InteriorNodes,
+				Source:            path.SourceAsset,
+				SourceAmount:      path.SourceAmount,
+				Destination:       path.DestinationAsset,
+				DestinationAmount: path.DestinationAmount,
+			}
+		}
+	}
+	return results, lastLedger, err
+}
+
+// Depth returns the depth curve of the trading pair and the expected execution
+// of a trade across the offers and, unless pools are excluded from path
+// finding, the liquidity pool of the trading pair.
+func (finder InMemoryFinder) Depth(ctx context.Context, q orderbook.DepthQuery) (orderbook.Depth, uint32, error) {
+	if finder.graph.IsEmpty() {
+		return orderbook.Depth{}, 0, ErrEmptyInMemoryOrderBook
+	}
+	return finder.graph.Depth(q, finder.includePools)
+}
@@ -0,0 +1,128 @@
+package aurora
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	protocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/services/aurora/internal/test"
+	tdb "github.com/hcnet/go/services/aurora/internal/test/db"
+	"github.com/hcnet/go/services/aurora/internal/test/simulator"
+	"github.com/hcnet/go/txnbuild"
+)
+
+// TestSimulatedNetworkIngestion runs Aurora against the in-memory network:
+// ingestion builds its state from the network's history archive and then
+// streams its ledgers, while transactions are submitted to it as hcnet-core.
+func TestSimulatedNetworkIngestion(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+
+	archiveDir := t.TempDir()
+	sim, err := simulator.New(simulator.Config{
+		NetworkPassphrase:   network.TestNetworkPassphrase,
+		CheckpointFrequency: 8,
+		HistoryArchiveURL:   "file://" + archiveDir,
+	})
+	tt.Require.NoError(err)
+
+	// Publish the first checkpoint so ingestion has a state to start from.
+	for sequence := uint32(0); sequence < 7; {
+		sequence, err = sim.CloseLedger()
+		tt.Require.NoError(err)
+	}
+
+	archive := httptest.NewServer(http.FileServer(http.Dir(archiveDir)))
+	defer archive.Close()
+	core := httptest.NewServer(sim)
+	defer core.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sim.Run(ctx, 100*time.Millisecond)
+
+	config := NewTestConfig(tdb.AuroraURL())
+	config.Ingest = true
+	config.HistoryArchiveURLs = []string{archive.URL}
+	config.CheckpointFrequency = 8
+	config.HcnetCoreURL = core.URL
+	config.LedgerBackend = sim.NewLedgerBackend()
+	config.DisablePathFinding = true
+	app, err := NewApp(config)
+	tt.Require.NoError(err)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- app.Serve()
+	}()
+	defer func() {
+		app.Close()
+		tt.Assert.NoError(<-served)
+	}()
+	rh := NewRequestHelper(app)
+
+	root := sim.Root()
+	var account protocol.Account
+	tt.Require.Eventually(func() bool {
+		w := rh.Get("/accounts/" + root.Address())
+		return w.Code == http.StatusOK && json.Unmarshal(w.Body.Bytes(), &account) == nil
+	}, time.Minute, 100*time.Millisecond, "the root account was not ingested")
+
+	destination := keypair.MustRandom()
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &account,
+		IncrementSequenceNum: true,
+		Operations: []txnbuild.Operation{
+			&txnbuild.CreateAccount{Destination: destination.Address(), Amount: "100"},
+			&txnbuild.Payment{Destination: destination.Address(), Amount: "12.5", Asset: txnbuild.NativeAsset{}},
+		},
+		BaseFee:       txnbuild.MinBaseFee,
+		Preconditions: txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()},
+	})
+	tt.Require.NoError(err)
+	tx, err = tx.Sign(network.TestNetworkPassphrase, root)
+	tt.Require.NoError(err)
+	envelope, err := tx.Base64()
+	tt.Require.NoError(err)
+
+	// Submission only returns once the transaction is ingested.
+	w := rh.Post("/transactions", url.Values{"tx": {envelope}})
+	tt.Require.Equal(http.StatusOK, w.Code, w.Body.String())
+	var submitted protocol.Transaction
+	tt.Require.NoError(json.Unmarshal(w.Body.Bytes(), &submitted))
+	tt.Assert.True(submitted.Successful)
+
+	w = rh.Get("/accounts/" + destination.Address() + "/payments")
+	tt.Require.Equal(http.StatusOK, w.Code, w.Body.String())
+	var payments []struct {
+		Type            string `json:"type"`
+		TransactionHash string `json:"transaction_hash"`
+		From            string `json:"from"`
+		To              string `json:"to"`
+		Amount          string `json:"amount"`
+		AssetType       string `json:"asset_type"`
+	}
+	tt.UnmarshalPage(w.Body, &payments)
+	tt.Require.Len(payments, 2)
+	tt.Assert.Equal("create_account", payments[0].Type)
+	payment := payments[1]
+	tt.Assert.Equal("payment", payment.Type)
+	tt.Assert.Equal(submitted.Hash, payment.TransactionHash)
+	tt.Assert.Equal(root.Address(), payment.From)
+	tt.Assert.Equal(destination.Address(), payment.To)
+	tt.Assert.Equal("12.5000000", payment.Amount)
+	tt.Assert.Equal("native", payment.AssetType)
+
+	w = rh.Get("/accounts/" + destination.Address())
+	tt.Require.Equal(http.StatusOK, w.Code, w.Body.String())
+	tt.Require.NoError(json.Unmarshal(w.Body.Bytes(), &account))
+	tt.Assert.Equal("112.5000000", account.Balances[0].Balance)
+}
@@ -0,0 +1,130 @@
+package ssehub
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/toid"
+)
+
+// tradesPageSize is the number of trades loaded by every query, ledgers
+// rarely have more.
+const tradesPageSize = 200
+
+type historyLoader struct {
+	q *history.Q
+}
+
+// Load loads the history of the ledger with one query per kind of record
+// (and one for the participants of operations and transactions), regardless
+// of the number of streams.
+func (l historyLoader) Load(ctx context.Context, sequence uint32) (*Ledger, error) {
+	seq := int32(sequence)
+	result := &Ledger{}
+	if err := l.q.LedgerBySequence(ctx, &result.Ledger, seq); err != nil {
+		return nil, errors.Wrap(err, "could not load ledger")
+	}
+
+	operations, operationTransactions, err := l.q.Operations().
+		ForLedger(ctx, seq).
+		IncludeFailed().
+		IncludeTransactions().
+		Fetch(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load operations")
+	}
+
+	var transactions []history.Transaction
+	if err = l.q.Transactions().ForLedger(ctx, seq).IncludeFailed().Select(ctx, &transactions); err != nil {
+		return nil, errors.Wrap(err, "could not load transactions")
+	}
+
+	if err = l.q.Effects().ForLedger(ctx, seq).Select(ctx, &result.Effects); err != nil {
+		return nil, errors.Wrap(err, "could not load effects")
+	}
+
+	if result.Trades, err = l.loadTrades(ctx, sequence); err != nil {
+		return nil, errors.Wrap(err, "could not load trades")
+	}
+
+	operationIDs := make([]int64, len(operations))
+	for i := range operations {
+		operationIDs[i] = operations[i].ID
+	}
+	operationParticipants, err := l.q.OperationParticipantsByIDs(ctx, operationIDs...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load operation participants")
+	}
+
+	transactionIDs := make([]int64, len(transactions))
+	for i := range transactions {
+		transactionIDs[i] = transactions[i].ID
+	}
+	transactionParticipants, err := l.q.TransactionParticipantsByIDs(ctx, transactionIDs...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load transaction participants")
+	}
+
+	for i, operation := range operations {
+		result.Operations = append(result.Operations, Operation{
+			Operation:    operation,
+			Transaction:  operationTransactions[i],
+			Participants: operationParticipants[operation.ID],
+		})
+	}
+	for _, transaction := range transactions {
+		result.Transactions = append(result.Transactions, Transaction{
+			Transaction:  transaction,
+			Participants: transactionParticipants[transaction.ID],
+		})
+	}
+
+	// The queries by ledger aren't ordered.
+	sort.Slice(result.Operations, func(i, j int) bool {
+		return result.Operations[i].ID < result.Operations[j].ID
+	})
+	sort.Slice(result.Transactions, func(i, j int) bool {
+		return result.Transactions[i].ID < result.Transactions[j].ID
+	})
+	sort.Slice(result.Effects, func(i, j int) bool {
+		a, b := result.Effects[i], result.Effects[j]
+		return a.HistoryOperationID < b.HistoryOperationID ||
+			(a.HistoryOperationID == b.HistoryOperationID && a.Order < b.Order)
+	})
+
+	return result, nil
+}
+
+// loadTrades pages through the trades following the previous ledger until
+// reaching the next one.
+func (l historyLoader) loadTrades(ctx context.Context, sequence uint32) ([]history.Trade, error) {
+	page := db2.PageQuery{
+		Cursor: strconv.FormatInt(toid.New(int32(sequence), 0, 0).ToInt64(), 10),
+		Order:  db2.OrderAscending,
+		Limit:  tradesPageSize,
+	}
+
+	var trades []history.Trade
+	for {
+		records, err := l.q.GetTrades(ctx, page, "", history.AllTrades)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, trade := range records {
+			if toid.Parse(trade.HistoryOperationID).LedgerSequence != int32(sequence) {
+				return trades, nil
+			}
+			trades = append(trades, trade)
+		}
+
+		if len(records) < tradesPageSize {
+			return trades, nil
+		}
+		page.Cursor = records[len(records)-1].PagingToken()
+	}
+}
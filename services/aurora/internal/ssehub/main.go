@@ -0,0 +1,271 @@
+// Package ssehub shares the work of streaming history to SSE clients. Instead
+// of every stream querying the database after every ledger, the hub loads the
+// operations, transactions, effects and trades of each new ledger once and
+// every stream picks the records it is interested in from them. The latest
+// ledgers are kept in memory so that clients reconnecting with a Last-Event-ID
+// (or a cursor) are replayed what they missed without querying the database.
+// Streams which are too far behind the hub catch up from the database first.
+package ssehub
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	herrors "github.com/hcnet/go/services/aurora/internal/errors"
+	"github.com/hcnet/go/services/aurora/internal/ledger"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/log"
+)
+
+// Config configures the hub.
+type Config struct {
+	// BufferLedgers is the number of ledgers kept in memory to be replayed
+	// to streams.
+	BufferLedgers uint32
+	// PollInterval is how often new ledgers are checked.
+	PollInterval time.Duration
+}
+
+// DefaultConfig is the configuration used when a field of Config is not set.
+var DefaultConfig = Config{
+	BufferLedgers: 60,
+	PollInterval:  time.Second,
+}
+
+// Ledger is the history of a ledger published by the hub. Records are sorted
+// by paging token and include those of failed transactions.
+type Ledger struct {
+	Ledger       history.Ledger
+	Operations   []Operation
+	Transactions []Transaction
+	Effects      []history.Effect
+	Trades       []history.Trade
+}
+
+// Sequence returns the sequence of the ledger.
+func (l *Ledger) Sequence() uint32 {
+	return uint32(l.Ledger.Sequence)
+}
+
+// Operation is an operation along with its transaction and the accounts
+// participating in it.
+type Operation struct {
+	history.Operation
+	Transaction  history.Transaction
+	Participants []string
+}
+
+// Transaction is a transaction along with the accounts participating in it.
+type Transaction struct {
+	history.Transaction
+	Participants []string
+}
+
+// loader loads the history of ledgers.
+type loader interface {
+	Load(ctx context.Context, sequence uint32) (*Ledger, error)
+}
+
+// Hub publishes the history of every new ledger to the SSE streams.
+type Hub struct {
+	loader      loader
+	ledgerState *ledger.State
+	config      Config
+	ctx         context.Context
+	cancel      context.CancelFunc
+
+	lock sync.RWMutex
+	// ledgers are the latest consecutive ledgers published, oldest first
+	ledgers []*Ledger
+	// published is closed and replaced every time a ledger is published
+	published chan struct{}
+}
+
+// New initializes the hub. It starts publishing from the latest ingested
+// ledgers once it runs.
+func New(config Config, dbSession db.SessionInterface, ledgerState *ledger.State) *Hub {
+	return newHub(config, historyLoader{&history.Q{SessionInterface: dbSession.Clone()}}, ledgerState)
+}
+
+func newHub(config Config, loader loader, ledgerState *ledger.State) *Hub {
+	if config.BufferLedgers == 0 {
+		config.BufferLedgers = DefaultConfig.BufferLedgers
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = DefaultConfig.PollInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Hub{
+		loader:      loader,
+		ledgerState: ledgerState,
+		config:      config,
+		ctx:         ctx,
+		cancel:      cancel,
+		published:   make(chan struct{}),
+	}
+}
+
+// Run publishes newly ingested ledgers until the hub is shut down.
+func (h *Hub) Run() {
+	ticker := time.NewTicker(h.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.runOnce(h.ctx)
+		case <-h.ctx.Done():
+			return
+		}
+	}
+}
+
+// Shutdown stops the hub.
+func (h *Hub) Shutdown() {
+	h.cancel()
+}
+
+func (h *Hub) runOnce(ctx context.Context) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err := herrors.FromPanic(rec)
+			log.Errorf("sse hub panicked: %s", err)
+			herrors.ReportToSentry(err, nil)
+		}
+	}()
+
+	status := h.ledgerState.CurrentStatus()
+	if status.HistoryLatest <= 0 {
+		return
+	}
+	latest := uint32(status.HistoryLatest)
+
+	// There's no point loading more ledgers than can be kept, the older
+	// ones would be dropped right away.
+	from := h.Latest() + 1
+	if from > latest {
+		return
+	}
+	if from == 1 || latest-from >= h.config.BufferLedgers {
+		from = 1
+		if latest >= h.config.BufferLedgers {
+			from = latest - h.config.BufferLedgers + 1
+		}
+	}
+	if elder := uint32(status.HistoryElder); from < elder {
+		from = elder
+	}
+
+	for sequence := from; sequence <= latest; sequence++ {
+		l, err := h.loader.Load(ctx, sequence)
+		if err != nil {
+			log.WithField("ledger", sequence).Errorf("sse hub could not load ledger: %s", err)
+			return
+		}
+		h.publish(l)
+	}
+}
+
+// publish adds the ledger to the buffer and wakes up the streams waiting for
+// it.
+func (h *Hub) publish(l *Ledger) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	// The buffer only holds consecutive ledgers so that a stream can tell
+	// whether it missed any.
+	if n := len(h.ledgers); n > 0 && h.ledgers[n-1].Sequence()+1 != l.Sequence() {
+		h.ledgers = nil
+	}
+	h.ledgers = append(h.ledgers, l)
+	if extra := len(h.ledgers) - int(h.config.BufferLedgers); extra > 0 {
+		h.ledgers = append([]*Ledger(nil), h.ledgers[extra:]...)
+	}
+
+	close(h.published)
+	h.published = make(chan struct{})
+}
+
+// Latest returns the sequence of the latest ledger published, or 0 if none
+// was.
+func (h *Hub) Latest() uint32 {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.latest()
+}
+
+func (h *Hub) latest() uint32 {
+	if len(h.ledgers) == 0 {
+		return 0
+	}
+	return h.ledgers[len(h.ledgers)-1].Sequence()
+}
+
+// Since returns the ledgers published after the given ledger. It returns false
+// when some of them are no longer (or not yet) in the buffer.
+func (h *Hub) Since(after uint32) ([]*Ledger, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.ledgers) == 0 {
+		return nil, false
+	}
+	if after >= h.latest() {
+		return nil, true
+	}
+
+	oldest := h.ledgers[0].Sequence()
+	if after+1 < oldest {
+		return nil, false
+	}
+	return h.ledgers[after+1-oldest:], true
+}
+
+// Get returns a ledger.Source yielding the ledgers published by the hub, so it
+// can be used as the sse.LedgerSourceFactory of the hub streams.
+func (h *Hub) Get() ledger.Source {
+	return &source{hub: h, closed: make(chan struct{})}
+}
+
+type source struct {
+	hub       *Hub
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (s *source) CurrentLedger() uint32 {
+	return s.hub.Latest()
+}
+
+func (s *source) NextLedger(currentSequence uint32) chan uint32 {
+	// Buffered so the goroutine below never blocks if nobody reads it.
+	next := make(chan uint32, 1)
+	go func() {
+		for {
+			s.hub.lock.RLock()
+			latest, published := s.hub.latest(), s.hub.published
+			s.hub.lock.RUnlock()
+
+			if latest > currentSequence {
+				next <- latest
+				return
+			}
+
+			select {
+			case <-published:
+			case <-s.closed:
+				return
+			case <-s.hub.ctx.Done():
+				return
+			}
+		}
+	}()
+	return next
+}
+
+func (s *source) Close() {
+	s.closeOnce.Do(func() { close(s.closed) })
+}
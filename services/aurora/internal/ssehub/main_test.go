@@ -0,0 +1,236 @@
+package ssehub
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/ledger"
+	"github.com/hcnet/go/services/aurora/internal/render/sse"
+	"github.com/hcnet/go/support/render/hal"
+	"github.com/hcnet/go/toid"
+)
+
+// fakeLoader loads ledgers with two operations each.
+type fakeLoader struct {
+	loaded []uint32
+}
+
+func (l *fakeLoader) Load(ctx context.Context, sequence uint32) (*Ledger, error) {
+	l.loaded = append(l.loaded, sequence)
+	result := &Ledger{Ledger: history.Ledger{Sequence: int32(sequence)}}
+	for i := int32(1); i <= 2; i++ {
+		var op Operation
+		op.ID = toid.New(int32(sequence), 1, i).ToInt64()
+		result.Operations = append(result.Operations, op)
+	}
+	return result, nil
+}
+
+type record string
+
+func (r record) PagingToken() string {
+	return string(r)
+}
+
+func renderOperations(ctx context.Context, l *Ledger) ([]hal.Pageable, error) {
+	var records []hal.Pageable
+	for _, op := range l.Operations {
+		records = append(records, record(strconv.FormatInt(op.ID, 10)))
+	}
+	return records, nil
+}
+
+func operationID(sequence uint32, index int32) string {
+	return strconv.FormatInt(toid.New(int32(sequence), 1, index).ToInt64(), 10)
+}
+
+func eventIDs(events []sse.Event) []string {
+	ids := []string{}
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func newTestHub(bufferLedgers uint32) (*Hub, *fakeLoader, *ledger.State) {
+	loader := &fakeLoader{}
+	ledgerState := &ledger.State{}
+	hub := newHub(Config{BufferLedgers: bufferLedgers}, loader, ledgerState)
+	return hub, loader, ledgerState
+}
+
+func ingest(hub *Hub, ledgerState *ledger.State, latest int32) {
+	ledgerState.SetAuroraStatus(ledger.AuroraStatus{HistoryLatest: latest, HistoryElder: 1})
+	hub.runOnce(context.Background())
+}
+
+func sequences(ledgers []*Ledger) []uint32 {
+	result := []uint32{}
+	for _, l := range ledgers {
+		result = append(result, l.Sequence())
+	}
+	return result
+}
+
+func TestHubBuffer(t *testing.T) {
+	hub, loader, ledgerState := newTestHub(3)
+
+	_, ok := hub.Since(0)
+	assert.False(t, ok)
+
+	// Only the ledgers which fit in the buffer are loaded.
+	ingest(hub, ledgerState, 5)
+	assert.Equal(t, []uint32{3, 4, 5}, loader.loaded)
+	assert.EqualValues(t, 5, hub.Latest())
+
+	_, ok = hub.Since(1)
+	assert.False(t, ok)
+	ledgers, ok := hub.Since(2)
+	assert.True(t, ok)
+	assert.Equal(t, []uint32{3, 4, 5}, sequences(ledgers))
+	ledgers, ok = hub.Since(4)
+	assert.True(t, ok)
+	assert.Equal(t, []uint32{5}, sequences(ledgers))
+	ledgers, ok = hub.Since(5)
+	assert.True(t, ok)
+	assert.Empty(t, ledgers)
+
+	// Nothing is loaded twice.
+	ingest(hub, ledgerState, 5)
+	ingest(hub, ledgerState, 6)
+	assert.Equal(t, []uint32{3, 4, 5, 6}, loader.loaded)
+	ledgers, ok = hub.Since(3)
+	assert.True(t, ok)
+	assert.Equal(t, []uint32{4, 5, 6}, sequences(ledgers))
+
+	// Falling behind skips to the latest ledgers.
+	ingest(hub, ledgerState, 20)
+	assert.Equal(t, []uint32{3, 4, 5, 6, 18, 19, 20}, loader.loaded)
+	_, ok = hub.Since(16)
+	assert.False(t, ok)
+	ledgers, ok = hub.Since(17)
+	assert.True(t, ok)
+	assert.Equal(t, []uint32{18, 19, 20}, sequences(ledgers))
+}
+
+func TestHubSource(t *testing.T) {
+	hub, _, ledgerState := newTestHub(3)
+	ingest(hub, ledgerState, 5)
+
+	source := hub.Get()
+	defer source.Close()
+	assert.EqualValues(t, 5, source.CurrentLedger())
+
+	// A ledger already published is returned right away.
+	select {
+	case sequence := <-source.NextLedger(4):
+		assert.EqualValues(t, 5, sequence)
+	case <-time.After(time.Second):
+		t.Fatal("ledger 5 was not returned")
+	}
+
+	next := source.NextLedger(5)
+	select {
+	case <-next:
+		t.Fatal("ledger returned before being published")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	ingest(hub, ledgerState, 6)
+	select {
+	case sequence := <-next:
+		assert.EqualValues(t, 6, sequence)
+	case <-time.After(time.Second):
+		t.Fatal("ledger 6 was not returned")
+	}
+}
+
+func TestGenerateEventsFromBuffer(t *testing.T) {
+	hub, _, ledgerState := newTestHub(3)
+	ingest(hub, ledgerState, 5)
+
+	fallback := func() ([]sse.Event, error) {
+		t.Fatal("unexpected fallback")
+		return nil, nil
+	}
+
+	// Reconnecting in the middle of ledger 3 replays what follows.
+	r := httptest.NewRequest("GET", "/operations", nil)
+	generateEvents, err := hub.GenerateEvents(r, operationID(3, 1), renderOperations, fallback)
+	require.NoError(t, err)
+
+	events, err := generateEvents()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		operationID(3, 2),
+		operationID(4, 1), operationID(4, 2),
+		operationID(5, 1), operationID(5, 2),
+	}, eventIDs(events))
+	assert.Equal(t, operationID(5, 2), r.Header.Get("Last-Event-ID"))
+
+	events, err = generateEvents()
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	ingest(hub, ledgerState, 6)
+	events, err = generateEvents()
+	require.NoError(t, err)
+	assert.Equal(t, []string{operationID(6, 1), operationID(6, 2)}, eventIDs(events))
+
+	// A cursor past the latest ledger of the hub waits for it.
+	r = httptest.NewRequest("GET", "/operations", nil)
+	cursor := toid.AfterLedger(6).String()
+	generateEvents, err = hub.GenerateEvents(r, cursor, renderOperations, fallback)
+	require.NoError(t, err)
+	events, err = generateEvents()
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Equal(t, cursor, r.Header.Get("Last-Event-ID"))
+
+	ingest(hub, ledgerState, 7)
+	events, err = generateEvents()
+	require.NoError(t, err)
+	assert.Equal(t, []string{operationID(7, 1), operationID(7, 2)}, eventIDs(events))
+}
+
+func TestGenerateEventsFallback(t *testing.T) {
+	hub, _, ledgerState := newTestHub(3)
+	ingest(hub, ledgerState, 5)
+
+	r := httptest.NewRequest("GET", "/operations", nil)
+	calls := 0
+	fallback := func() ([]sse.Event, error) {
+		calls++
+		// The database query returns everything up to the latest ledger.
+		r.Header.Set("Last-Event-ID", operationID(5, 2))
+		return []sse.Event{
+			{ID: operationID(1, 1)},
+			{ID: operationID(5, 2)},
+		}, nil
+	}
+
+	generateEvents, err := hub.GenerateEvents(r, "", renderOperations, fallback)
+	require.NoError(t, err)
+
+	events, err := generateEvents()
+	require.NoError(t, err)
+	assert.Equal(t, []string{operationID(1, 1), operationID(5, 2)}, eventIDs(events))
+	assert.Equal(t, 1, calls)
+
+	// Once caught up, the stream is served from the buffer.
+	ingest(hub, ledgerState, 6)
+	events, err = generateEvents()
+	require.NoError(t, err)
+	assert.Equal(t, []string{operationID(6, 1), operationID(6, 2)}, eventIDs(events))
+	assert.Equal(t, 1, calls)
+
+	_, err = hub.GenerateEvents(r, "invalid", renderOperations, fallback)
+	assert.Error(t, err)
+}
@@ -0,0 +1,115 @@
+package ssehub
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/services/aurora/internal/render/sse"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/hal"
+	"github.com/hcnet/go/toid"
+)
+
+// RenderFunc renders the records of a ledger a stream is interested in,
+// sorted by paging token.
+type RenderFunc func(ctx context.Context, l *Ledger) ([]hal.Pageable, error)
+
+// GenerateEvents returns the events generator of a stream starting after the
+// given cursor (which already accounts for Last-Event-ID) and ordered
+// ascending.
+//
+// Ledgers still in the buffer are rendered from it. Otherwise, like when the
+// cursor is older than the buffer, events are generated by `fallback` (the
+// database query of the stream) until the stream catches up with the hub.
+// Either way, the Last-Event-ID header of the request is kept pointing at the
+// last event sent, which is what `fallback` resumes from.
+func (h *Hub) GenerateEvents(
+	r *http.Request,
+	cursor string,
+	render RenderFunc,
+	fallback sse.GenerateEventsFunc,
+) (sse.GenerateEventsFunc, error) {
+	last, err := parseToken(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// The records of the ledger of the cursor which follow it have to be
+	// sent, so start from the previous ledger.
+	var after uint32
+	if sequence := toid.Parse(last.id).LedgerSequence; sequence > 1 {
+		after = uint32(sequence) - 1
+	}
+
+	return func() ([]sse.Event, error) {
+		ledgers, ok := h.Since(after)
+		if !ok {
+			// Everything ingested before the query is returned by it, unless
+			// there's more than a page of it in which case the stream ends.
+			latest := h.ledgerState.CurrentStatus().HistoryLatest
+			events, err := fallback()
+			if err != nil {
+				return nil, err
+			}
+			if len(events) > 0 {
+				if last, err = parseToken(events[len(events)-1].ID); err != nil {
+					return nil, err
+				}
+			}
+			if latest > 0 && uint32(latest) > after {
+				after = uint32(latest)
+			}
+			return events, nil
+		}
+
+		var events []sse.Event
+		for _, l := range ledgers {
+			records, err := render(r.Context(), l)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, record := range records {
+				id := record.PagingToken()
+				current, err := parseToken(id)
+				if err != nil {
+					return nil, err
+				}
+				if !last.before(current) {
+					continue
+				}
+				last = current
+				events = append(events, sse.Event{ID: id, Data: record})
+			}
+			after = l.Sequence()
+		}
+
+		if len(events) > 0 {
+			r.Header.Set("Last-Event-ID", events[len(events)-1].ID)
+		} else if len(r.Header.Get("Last-Event-ID")) == 0 {
+			r.Header.Set("Last-Event-ID", cursor)
+		}
+		return events, nil
+	}, nil
+}
+
+// token is a parsed paging token. Tokens made of a single number are ordered
+// after every token sharing the same first number, just like cursors.
+type token struct {
+	id    int64
+	order int64
+}
+
+func parseToken(pagingToken string) (token, error) {
+	query := db2.PageQuery{Cursor: pagingToken, Order: db2.OrderAscending}
+	id, order, err := query.CursorInt64Pair(db2.DefaultPairSep)
+	if err != nil {
+		return token{}, errors.Wrapf(err, "invalid paging token %s", pagingToken)
+	}
+	return token{id: id, order: order}, nil
+}
+
+func (t token) before(other token) bool {
+	return t.id < other.id || (t.id == other.id && t.order < other.order)
+}
@@ -0,0 +1,435 @@
+package simulator
+
+import (
+	"math"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/xdr"
+)
+
+type pendingTransaction struct {
+	envelope xdr.TransactionEnvelope
+	hash     xdr.Hash
+	// innerHash is the hash of the inner transaction of a fee bump
+	// transaction.
+	innerHash xdr.Hash
+}
+
+func newPendingTransaction(envelope xdr.TransactionEnvelope, passphrase string) (pendingTransaction, error) {
+	tx := pendingTransaction{envelope: envelope}
+	hash, err := network.HashTransactionInEnvelope(envelope, passphrase)
+	if err != nil {
+		return tx, err
+	}
+	tx.hash = hash
+	tx.innerHash = hash
+	if envelope.IsFeeBump() {
+		tx.innerHash, err = network.HashTransaction(envelope.FeeBump.Tx.InnerTx.V1.Tx, passphrase)
+	}
+	return tx, err
+}
+
+func feeSource(envelope xdr.TransactionEnvelope) xdr.AccountId {
+	if envelope.IsFeeBump() {
+		return envelope.FeeBumpAccount().ToAccountId()
+	}
+	return envelope.SourceAccount().ToAccountId()
+}
+
+// minFee is the fee of the transaction at the base fee of the network.
+func (n *Network) minFee(envelope xdr.TransactionEnvelope) int64 {
+	operations := int64(len(envelope.Operations()))
+	if envelope.IsFeeBump() {
+		operations++
+	}
+	return operations * int64(n.config.BaseFee)
+}
+
+// maxFee is the fee the fee source is willing to pay.
+func maxFee(envelope xdr.TransactionEnvelope) int64 {
+	if envelope.IsFeeBump() {
+		return envelope.FeeBumpFee()
+	}
+	return int64(envelope.Fee())
+}
+
+// chargeFee charges the fee of the transaction to its fee source. There is
+// no surge pricing, transactions are charged the base fee of the network.
+func (n *Network) chargeFee(envelope xdr.TransactionEnvelope) (xdr.Int64, xdr.LedgerEntryChanges) {
+	v := newView(n.state)
+	fee := xdr.Int64(n.minFee(envelope))
+	account, ok := v.loadAccount(feeSource(envelope))
+	if !ok {
+		return 0, xdr.LedgerEntryChanges{}
+	}
+	if fee > account.Balance {
+		fee = account.Balance
+	}
+	account.Balance -= fee
+	v.storeAccount(account)
+	changes := v.changes()
+	v.commit()
+	return fee, changes
+}
+
+// checkPreconditions returns the code of a transaction which cannot be
+// applied at the given close time, or txSUCCESS. pendingSeq is the sequence
+// number of the last pending transaction of the source account, if any.
+func checkPreconditions(v *view, envelope xdr.TransactionEnvelope, closeTime int64, pendingSeq int64) xdr.TransactionResultCode {
+	if len(envelope.Operations()) == 0 {
+		return xdr.TransactionResultCodeTxMissingOperation
+	}
+	if tb := envelope.TimeBounds(); tb != nil {
+		if closeTime < int64(tb.MinTime) {
+			return xdr.TransactionResultCodeTxTooEarly
+		}
+		if tb.MaxTime != 0 && closeTime > int64(tb.MaxTime) {
+			return xdr.TransactionResultCodeTxTooLate
+		}
+	}
+
+	account, ok := v.loadAccount(envelope.SourceAccount().ToAccountId())
+	if !ok {
+		return xdr.TransactionResultCodeTxNoAccount
+	}
+	current := int64(account.SeqNum)
+	if pendingSeq > current {
+		current = pendingSeq
+	}
+	seq := envelope.SeqNum()
+	if minSeq := envelope.MinSeqNum(); minSeq != nil {
+		if current < *minSeq || seq <= current {
+			return xdr.TransactionResultCodeTxBadSeq
+		}
+	} else if seq != current+1 {
+		return xdr.TransactionResultCodeTxBadSeq
+	}
+	return xdr.TransactionResultCodeTxSuccess
+}
+
+// thresholdIndex returns the index in the account thresholds of the
+// threshold required by the operation.
+func thresholdIndex(op xdr.Operation) int {
+	switch op.Body.Type {
+	case xdr.OperationTypeAllowTrust, xdr.OperationTypeSetTrustLineFlags, xdr.OperationTypeBumpSequence,
+		xdr.OperationTypeClaimClaimableBalance, xdr.OperationTypeInflation:
+		return int(xdr.ThresholdIndexesThresholdLow)
+	case xdr.OperationTypeAccountMerge:
+		return int(xdr.ThresholdIndexesThresholdHigh)
+	case xdr.OperationTypeSetOptions:
+		options := op.Body.MustSetOptionsOp()
+		if options.MasterWeight != nil || options.LowThreshold != nil || options.MedThreshold != nil ||
+			options.HighThreshold != nil || options.Signer != nil {
+			return int(xdr.ThresholdIndexesThresholdHigh)
+		}
+	}
+	return int(xdr.ThresholdIndexesThresholdMed)
+}
+
+// signedWeight returns the total weight of the ed25519 signers of the account
+// which signed the hash.
+func signedWeight(account xdr.AccountEntry, hash xdr.Hash, signatures []xdr.DecoratedSignature) int {
+	weight := 0
+	add := func(address string, signerWeight int) {
+		kp, err := keypair.ParseAddress(address)
+		if err != nil || signerWeight == 0 {
+			return
+		}
+		hint := kp.Hint()
+		for _, signature := range signatures {
+			if signature.Hint == hint && kp.Verify(hash[:], signature.Signature) == nil {
+				weight += signerWeight
+				return
+			}
+		}
+	}
+
+	add(account.AccountId.Address(), int(account.MasterKeyWeight()))
+	for _, signer := range account.Signers {
+		if signer.Key.Type == xdr.SignerKeyTypeSignerKeyTypeEd25519 {
+			add(signer.Key.Address(), int(signer.Weight))
+		}
+	}
+	return weight
+}
+
+// authorized returns true if the signatures meet the thresholds of the
+// accounts used by the transaction. Operation source accounts which do not
+// exist yet are checked when the operation is applied.
+func authorized(v *view, tx pendingTransaction) bool {
+	envelope := tx.envelope
+	source := envelope.SourceAccount().ToAccountId()
+	required := map[string]int{source.Address(): int(xdr.ThresholdIndexesThresholdLow)}
+	for _, op := range envelope.Operations() {
+		opSource := source
+		if op.SourceAccount != nil {
+			opSource = op.SourceAccount.ToAccountId()
+		}
+		if index := thresholdIndex(op); index > required[opSource.Address()] {
+			required[opSource.Address()] = index
+		}
+	}
+
+	for address, index := range required {
+		account, ok := v.loadAccount(xdr.MustAddress(address))
+		if !ok {
+			continue
+		}
+		if signedWeight(account, tx.innerHash, envelope.Signatures()) < neededWeight(account, index) {
+			return false
+		}
+	}
+
+	if envelope.IsFeeBump() {
+		account, ok := v.loadAccount(feeSource(envelope))
+		if !ok {
+			return false
+		}
+		index := int(xdr.ThresholdIndexesThresholdLow)
+		if signedWeight(account, tx.hash, envelope.FeeBumpSignatures()) < neededWeight(account, index) {
+			return false
+		}
+	}
+	return true
+}
+
+func neededWeight(account xdr.AccountEntry, index int) int {
+	if weight := int(account.Thresholds[index]); weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+// applier applies a transaction to the ledger state of a network.
+type applier struct {
+	network *Network
+	header  *xdr.LedgerHeader
+	tx      pendingTransaction
+	// opIndex is the index of the operation being applied.
+	opIndex int
+}
+
+func (a *applier) closeTime() int64 {
+	return int64(a.header.ScpValue.CloseTime)
+}
+
+func (a *applier) baseReserve() uint32 {
+	return uint32(a.header.BaseReserve)
+}
+
+func (a *applier) applyTransaction(tx pendingTransaction, fee xdr.Int64) (xdr.TransactionResultPair, xdr.TransactionMeta) {
+	a.tx = tx
+	state := a.network.state
+	envelope := tx.envelope
+	meta := xdr.TransactionMeta{
+		V: 2,
+		V2: &xdr.TransactionMetaV2{
+			TxChangesBefore: xdr.LedgerEntryChanges{},
+			Operations:      []xdr.OperationMeta{},
+			TxChangesAfter:  xdr.LedgerEntryChanges{},
+		},
+	}
+
+	var results []xdr.OperationResult
+	code := checkPreconditions(state, envelope, a.closeTime(), 0)
+	if code == xdr.TransactionResultCodeTxSuccess {
+		seqView := newView(state)
+		account, _ := seqView.loadAccount(envelope.SourceAccount().ToAccountId())
+		a.setSequence(&account, xdr.SequenceNumber(envelope.SeqNum()))
+		seqView.storeAccount(account)
+		meta.V2.TxChangesBefore = seqView.changes()
+		seqView.commit()
+
+		opsView := newView(state)
+		var operations []xdr.OperationMeta
+		for i, op := range envelope.Operations() {
+			a.opIndex = i
+			opView := newView(opsView)
+			result, ok := a.applyOperation(opView, op)
+			results = append(results, result)
+			if !ok {
+				code = xdr.TransactionResultCodeTxFailed
+				continue
+			}
+			operations = append(operations, xdr.OperationMeta{Changes: opView.changes()})
+			opView.commit()
+		}
+		if code == xdr.TransactionResultCodeTxSuccess {
+			opsView.commit()
+			meta.V2.Operations = operations
+		}
+	}
+
+	result := xdr.TransactionResult{FeeCharged: fee}
+	if envelope.IsFeeBump() {
+		inner := xdr.InnerTransactionResultPair{
+			TransactionHash: tx.innerHash,
+			Result: xdr.InnerTransactionResult{
+				Result: xdr.InnerTransactionResultResult{Code: code},
+			},
+		}
+		if code == xdr.TransactionResultCodeTxSuccess || code == xdr.TransactionResultCodeTxFailed {
+			inner.Result.Result.Results = &results
+		}
+		result.Result.Code = xdr.TransactionResultCodeTxFeeBumpInnerFailed
+		if code == xdr.TransactionResultCodeTxSuccess {
+			result.Result.Code = xdr.TransactionResultCodeTxFeeBumpInnerSuccess
+		}
+		result.Result.InnerResultPair = &inner
+	} else {
+		result.Result.Code = code
+		if code == xdr.TransactionResultCodeTxSuccess || code == xdr.TransactionResultCodeTxFailed {
+			result.Result.Results = &results
+		}
+	}
+	return xdr.TransactionResultPair{TransactionHash: tx.hash, Result: result}, meta
+}
+
+func (a *applier) setSequence(account *xdr.AccountEntry, seq xdr.SequenceNumber) {
+	account.SeqNum = seq
+	if a.header.LedgerVersion >= 19 {
+		ext := accountExtV3(account)
+		ext.SeqLedger = a.header.LedgerSeq
+		ext.SeqTime = xdr.TimePoint(a.closeTime())
+	}
+}
+
+// applyOperation applies the operation to the view and returns its result.
+// The view must be dropped if the operation fails.
+func (a *applier) applyOperation(v *view, op xdr.Operation) (xdr.OperationResult, bool) {
+	source := a.tx.envelope.SourceAccount().ToAccountId()
+	if op.SourceAccount != nil {
+		source = op.SourceAccount.ToAccountId()
+	}
+	if _, ok := v.loadAccount(source); !ok {
+		return xdr.OperationResult{Code: xdr.OperationResultCodeOpNoAccount}, false
+	}
+
+	var result interface{}
+	var ok bool
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		result, ok = a.createAccount(v, source, op.Body.MustCreateAccountOp())
+	case xdr.OperationTypePayment:
+		result, ok = a.payment(v, source, op.Body.MustPaymentOp())
+	case xdr.OperationTypeChangeTrust:
+		result, ok = a.changeTrust(v, source, op.Body.MustChangeTrustOp())
+	case xdr.OperationTypeSetOptions:
+		result, ok = a.setOptions(v, source, op.Body.MustSetOptionsOp())
+	case xdr.OperationTypeBumpSequence:
+		result, ok = a.bumpSequence(v, source, op.Body.MustBumpSequenceOp())
+	case xdr.OperationTypeManageSellOffer:
+		result, ok = a.manageSellOffer(v, source, op.Body.MustManageSellOfferOp())
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		result, ok = a.createPassiveSellOffer(v, source, op.Body.MustCreatePassiveSellOfferOp())
+	case xdr.OperationTypeManageBuyOffer:
+		result, ok = a.manageBuyOffer(v, source, op.Body.MustManageBuyOfferOp())
+	case xdr.OperationTypeCreateClaimableBalance:
+		result, ok = a.createClaimableBalance(v, source, op.Body.MustCreateClaimableBalanceOp())
+	case xdr.OperationTypeClaimClaimableBalance:
+		result, ok = a.claimClaimableBalance(v, source, op.Body.MustClaimClaimableBalanceOp())
+	case xdr.OperationTypeLiquidityPoolDeposit:
+		result, ok = a.liquidityPoolDeposit(v, source, op.Body.MustLiquidityPoolDepositOp())
+	case xdr.OperationTypeLiquidityPoolWithdraw:
+		result, ok = a.liquidityPoolWithdraw(v, source, op.Body.MustLiquidityPoolWithdrawOp())
+	default:
+		return xdr.OperationResult{Code: xdr.OperationResultCodeOpNotSupported}, false
+	}
+
+	tr, err := xdr.NewOperationResultTr(op.Body.Type, result)
+	if err != nil {
+		panic(err)
+	}
+	return xdr.OperationResult{Code: xdr.OperationResultCodeOpInner, Tr: &tr}, ok
+}
+
+// balanceError is the reason why the balance of an account could not be
+// changed.
+type balanceError int
+
+const (
+	balanceChanged balanceError = iota
+	balanceNoTrust
+	balanceNotAuthorized
+	balanceUnderfunded
+	balanceLineFull
+)
+
+func isIssuer(account xdr.AccountId, asset xdr.Asset) bool {
+	return asset.Type != xdr.AssetTypeAssetTypeNative && asset.GetIssuer() == account.Address()
+}
+
+// changeBalance adds amount, which can be negative, to the balance of the
+// asset held by the account. Issuers have an unlimited balance of their own
+// assets.
+func (a *applier) changeBalance(v *view, account xdr.AccountId, asset xdr.Asset, amount xdr.Int64) balanceError {
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		entry, ok := v.loadAccount(account)
+		if !ok {
+			return balanceNoTrust
+		}
+		if amount < 0 && availableBalance(entry, a.baseReserve()) < -amount {
+			return balanceUnderfunded
+		}
+		if amount > 0 && entry.Balance > math.MaxInt64-amount {
+			return balanceLineFull
+		}
+		entry.Balance += amount
+		v.storeAccount(entry)
+		return balanceChanged
+	}
+
+	if isIssuer(account, asset) {
+		return balanceChanged
+	}
+	entry, ok := v.loadTrustLine(account, asset.ToTrustLineAsset())
+	if !ok {
+		return balanceNoTrust
+	}
+	line := entry.Data.MustTrustLine()
+	if !trustLineAuthorized(line) {
+		return balanceNotAuthorized
+	}
+	if amount < 0 && line.Balance < -amount {
+		return balanceUnderfunded
+	}
+	if amount > 0 && line.Balance > line.Limit-amount {
+		return balanceLineFull
+	}
+	line.Balance += amount
+	entry.Data.TrustLine = &line
+	v.store(entry)
+	return balanceChanged
+}
+
+// balance returns the balance of the asset the account can spend.
+func (a *applier) balance(v *view, account xdr.AccountId, asset xdr.Asset) xdr.Int64 {
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		entry, _ := v.loadAccount(account)
+		if available := availableBalance(entry, a.baseReserve()); available > 0 {
+			return available
+		}
+		return 0
+	}
+	if isIssuer(account, asset) {
+		return math.MaxInt64
+	}
+	entry, ok := v.loadTrustLine(account, asset.ToTrustLineAsset())
+	if !ok {
+		return 0
+	}
+	return entry.Data.MustTrustLine().Balance
+}
+
+// addSubEntries changes the number of sub entries of the account. It returns
+// false if the account cannot afford the reserve of the new sub entries.
+func (a *applier) addSubEntries(v *view, id xdr.AccountId, delta int) bool {
+	account, _ := v.loadAccount(id)
+	account.NumSubEntries = xdr.Uint32(int(account.NumSubEntries) + delta)
+	if delta > 0 && availableBalance(account, a.baseReserve()) < 0 {
+		return false
+	}
+	v.storeAccount(account)
+	return true
+}
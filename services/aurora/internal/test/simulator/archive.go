@@ -0,0 +1,102 @@
+package simulator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+
+	"github.com/hcnet/go/historyarchive"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+var zeroHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// bucket returns the contents of a bucket holding the whole ledger state,
+// sorted by ledger key. The simulator keeps the state in a single bucket at
+// the top level of the bucket list instead of merging buckets like
+// hcnet-core does.
+func (n *Network) bucket() ([]byte, xdr.Hash, error) {
+	keys := make([]string, 0, len(n.state.entries))
+	for key := range n.state.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	meta := xdr.BucketEntry{
+		Type:      xdr.BucketEntryTypeMetaentry,
+		MetaEntry: &xdr.BucketMetadata{LedgerVersion: xdr.Uint32(n.config.ProtocolVersion)},
+	}
+	if err := xdr.MarshalFramed(&buf, meta); err != nil {
+		return nil, xdr.Hash{}, errors.Wrap(err, "could not marshal bucket meta entry")
+	}
+	for _, key := range keys {
+		entry := xdr.BucketEntry{
+			Type:      xdr.BucketEntryTypeLiveentry,
+			LiveEntry: n.state.entries[key],
+		}
+		if err := xdr.MarshalFramed(&buf, entry); err != nil {
+			return nil, xdr.Hash{}, errors.Wrap(err, "could not marshal bucket entry")
+		}
+	}
+	return buf.Bytes(), sha256.Sum256(buf.Bytes()), nil
+}
+
+func (n *Network) historyArchiveState(sequence uint32, bucketHash xdr.Hash) historyarchive.HistoryArchiveState {
+	has := historyarchive.HistoryArchiveState{
+		Version:           1,
+		Server:            "aurora network simulator",
+		CurrentLedger:     sequence,
+		NetworkPassphrase: n.config.NetworkPassphrase,
+	}
+	for i := range has.CurrentBuckets {
+		has.CurrentBuckets[i].Curr = zeroHash
+		has.CurrentBuckets[i].Snap = zeroHash
+	}
+	has.CurrentBuckets[0].Curr = hex.EncodeToString(bucketHash[:])
+	return has
+}
+
+func (n *Network) bucketListHash() (xdr.Hash, error) {
+	_, hash, err := n.bucket()
+	if err != nil {
+		return xdr.Hash{}, err
+	}
+	has := n.historyArchiveState(0, hash)
+	return has.BucketListHash()
+}
+
+// publishCheckpoint writes the bucket and the history archive state of the
+// checkpoint ledger to the history archive.
+func (n *Network) publishCheckpoint(sequence uint32) error {
+	contents, hash, err := n.bucket()
+	if err != nil {
+		return err
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err = writer.Write(contents); err != nil {
+		return errors.Wrap(err, "could not compress bucket")
+	}
+	if err = writer.Close(); err != nil {
+		return errors.Wrap(err, "could not compress bucket")
+	}
+	path := n.archive.GetBucketPathForHash(historyarchive.Hash(hash))
+	if err = n.archiveStorage.PutFile(path, io.NopCloser(&compressed)); err != nil {
+		return errors.Wrapf(err, "could not write bucket %s", path)
+	}
+
+	has := n.historyArchiveState(sequence, hash)
+	if err = n.archive.PutCheckpointHAS(sequence, has, &historyarchive.CommandOptions{Force: true}); err != nil {
+		return errors.Wrapf(err, "could not write history archive state of checkpoint %d", sequence)
+	}
+	if err = n.archive.PutRootHAS(has, &historyarchive.CommandOptions{}); err != nil {
+		return errors.Wrap(err, "could not write root history archive state")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package simulator
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hcnet/go/ingest/ledgerbackend"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// ledgerBackend streams the ledgers closed by a network.
+type ledgerBackend struct {
+	network *Network
+
+	lock     sync.Mutex
+	prepared *ledgerbackend.Range
+	closed   bool
+}
+
+var _ ledgerbackend.LedgerBackend = (*ledgerBackend)(nil)
+
+// NewLedgerBackend returns a ledger backend serving the ledgers closed by the
+// network. GetLedger blocks until the requested ledger is closed, so ledgers
+// are only streamed when CloseLedger is called.
+func (n *Network) NewLedgerBackend() ledgerbackend.LedgerBackend {
+	return &ledgerBackend{network: n}
+}
+
+func (b *ledgerBackend) checkClosed() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.closed {
+		return errors.New("ledger backend is closed")
+	}
+	return nil
+}
+
+func (b *ledgerBackend) GetLatestLedgerSequence(ctx context.Context) (uint32, error) {
+	if err := b.checkClosed(); err != nil {
+		return 0, err
+	}
+	return uint32(b.network.LatestLedger().Header.LedgerSeq), nil
+}
+
+func (b *ledgerBackend) GetLedger(ctx context.Context, sequence uint32) (xdr.LedgerCloseMeta, error) {
+	if err := b.checkClosed(); err != nil {
+		return xdr.LedgerCloseMeta{}, err
+	}
+	if sequence < 2 {
+		return xdr.LedgerCloseMeta{}, errors.Errorf("ledger %d is not available, the first closed ledger is 2", sequence)
+	}
+	for {
+		b.network.lock.Lock()
+		ledger, ok := b.network.ledgers[sequence]
+		closed := b.network.closed
+		b.network.lock.Unlock()
+		if ok {
+			return ledger, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return xdr.LedgerCloseMeta{}, ctx.Err()
+		case <-closed:
+		}
+	}
+}
+
+func (b *ledgerBackend) PrepareRange(ctx context.Context, ledgerRange ledgerbackend.Range) error {
+	if err := b.checkClosed(); err != nil {
+		return err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.prepared = &ledgerRange
+	return nil
+}
+
+func (b *ledgerBackend) IsPrepared(ctx context.Context, ledgerRange ledgerbackend.Range) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.prepared != nil && b.prepared.Contains(ledgerRange), nil
+}
+
+func (b *ledgerBackend) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.closed = true
+	return nil
+}
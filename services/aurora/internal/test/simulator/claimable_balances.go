@@ -0,0 +1,213 @@
+package simulator
+
+import (
+	"crypto/sha256"
+
+	"github.com/hcnet/go/xdr"
+)
+
+// balanceID returns the id of the claimable balance created by the current
+// operation.
+func (a *applier) balanceID() (xdr.ClaimableBalanceId, error) {
+	preimage := xdr.HashIdPreimage{
+		Type: xdr.EnvelopeTypeEnvelopeTypeOpId,
+		OperationId: &xdr.HashIdPreimageOperationId{
+			SourceAccount: a.tx.envelope.SourceAccount().ToAccountId(),
+			SeqNum:        xdr.SequenceNumber(a.tx.envelope.SeqNum()),
+			OpNum:         xdr.Uint32(a.opIndex),
+		},
+	}
+	b, err := preimage.MarshalBinary()
+	if err != nil {
+		return xdr.ClaimableBalanceId{}, err
+	}
+	hash := xdr.Hash(sha256.Sum256(b))
+	return xdr.ClaimableBalanceId{Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0, V0: &hash}, nil
+}
+
+// absolutePredicate replaces the relative time predicates with absolute time
+// predicates, like hcnet-core does when the balance is created.
+func absolutePredicate(predicate xdr.ClaimPredicate, closeTime int64) xdr.ClaimPredicate {
+	switch predicate.Type {
+	case xdr.ClaimPredicateTypeClaimPredicateAnd, xdr.ClaimPredicateTypeClaimPredicateOr:
+		children := predicate.AndPredicates
+		if predicate.Type == xdr.ClaimPredicateTypeClaimPredicateOr {
+			children = predicate.OrPredicates
+		}
+		converted := make([]xdr.ClaimPredicate, len(*children))
+		for i, child := range *children {
+			converted[i] = absolutePredicate(child, closeTime)
+		}
+		if predicate.Type == xdr.ClaimPredicateTypeClaimPredicateOr {
+			return xdr.ClaimPredicate{Type: predicate.Type, OrPredicates: &converted}
+		}
+		return xdr.ClaimPredicate{Type: predicate.Type, AndPredicates: &converted}
+	case xdr.ClaimPredicateTypeClaimPredicateNot:
+		child := absolutePredicate(**predicate.NotPredicate, closeTime)
+		childPtr := &child
+		return xdr.ClaimPredicate{Type: predicate.Type, NotPredicate: &childPtr}
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeRelativeTime:
+		absBefore := xdr.Int64(closeTime) + *predicate.RelBefore
+		if absBefore < xdr.Int64(closeTime) {
+			// overflow
+			absBefore = xdr.Int64(^uint64(0) >> 1)
+		}
+		return xdr.ClaimPredicate{Type: xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime, AbsBefore: &absBefore}
+	default:
+		return predicate
+	}
+}
+
+func validPredicate(predicate xdr.ClaimPredicate) bool {
+	switch predicate.Type {
+	case xdr.ClaimPredicateTypeClaimPredicateUnconditional:
+		return true
+	case xdr.ClaimPredicateTypeClaimPredicateAnd, xdr.ClaimPredicateTypeClaimPredicateOr:
+		children := predicate.AndPredicates
+		if predicate.Type == xdr.ClaimPredicateTypeClaimPredicateOr {
+			children = predicate.OrPredicates
+		}
+		if children == nil || len(*children) != 2 {
+			return false
+		}
+		return validPredicate((*children)[0]) && validPredicate((*children)[1])
+	case xdr.ClaimPredicateTypeClaimPredicateNot:
+		return predicate.NotPredicate != nil && *predicate.NotPredicate != nil && validPredicate(**predicate.NotPredicate)
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime:
+		return *predicate.AbsBefore >= 0
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeRelativeTime:
+		return *predicate.RelBefore >= 0
+	}
+	return false
+}
+
+func predicateHolds(predicate xdr.ClaimPredicate, closeTime int64) bool {
+	switch predicate.Type {
+	case xdr.ClaimPredicateTypeClaimPredicateUnconditional:
+		return true
+	case xdr.ClaimPredicateTypeClaimPredicateAnd:
+		for _, child := range *predicate.AndPredicates {
+			if !predicateHolds(child, closeTime) {
+				return false
+			}
+		}
+		return true
+	case xdr.ClaimPredicateTypeClaimPredicateOr:
+		for _, child := range *predicate.OrPredicates {
+			if predicateHolds(child, closeTime) {
+				return true
+			}
+		}
+		return false
+	case xdr.ClaimPredicateTypeClaimPredicateNot:
+		return !predicateHolds(**predicate.NotPredicate, closeTime)
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime:
+		return closeTime < int64(*predicate.AbsBefore)
+	}
+	return false
+}
+
+func (a *applier) createClaimableBalance(v *view, source xdr.AccountId, op xdr.CreateClaimableBalanceOp) (xdr.CreateClaimableBalanceResult, bool) {
+	result := func(code xdr.CreateClaimableBalanceResultCode) (xdr.CreateClaimableBalanceResult, bool) {
+		return xdr.CreateClaimableBalanceResult{Code: code}, false
+	}
+
+	if !validAsset(op.Asset) || op.Amount <= 0 || len(op.Claimants) == 0 {
+		return result(xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceMalformed)
+	}
+	seen := map[string]bool{}
+	claimants := make([]xdr.Claimant, len(op.Claimants))
+	for i, claimant := range op.Claimants {
+		destination := claimant.MustV0().Destination
+		if seen[destination.Address()] || !validPredicate(claimant.MustV0().Predicate) {
+			return result(xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceMalformed)
+		}
+		seen[destination.Address()] = true
+		claimants[i] = xdr.Claimant{
+			Type: xdr.ClaimantTypeClaimantTypeV0,
+			V0: &xdr.ClaimantV0{
+				Destination: destination,
+				Predicate:   absolutePredicate(claimant.MustV0().Predicate, a.closeTime()),
+			},
+		}
+	}
+
+	switch a.changeBalance(v, source, op.Asset, -op.Amount) {
+	case balanceNoTrust:
+		return result(xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceNoTrust)
+	case balanceNotAuthorized:
+		return result(xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceNotAuthorized)
+	case balanceUnderfunded:
+		return result(xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceUnderfunded)
+	}
+
+	// the source sponsors the reserve of every claimant
+	account, _ := v.loadAccount(source)
+	addSponsoring(&account, len(claimants))
+	if availableBalance(account, a.baseReserve()) < 0 {
+		return result(xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceLowReserve)
+	}
+	v.storeAccount(account)
+
+	id, err := a.balanceID()
+	if err != nil {
+		return result(xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceMalformed)
+	}
+	entry := xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeClaimableBalance,
+		ClaimableBalance: &xdr.ClaimableBalanceEntry{
+			BalanceId: id,
+			Claimants: claimants,
+			Asset:     op.Asset,
+			Amount:    op.Amount,
+		},
+	}}
+	sponsoredBy(&entry, source)
+	v.store(entry)
+	return xdr.CreateClaimableBalanceResult{
+		Code:      xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceSuccess,
+		BalanceId: &id,
+	}, true
+}
+
+func (a *applier) claimClaimableBalance(v *view, source xdr.AccountId, op xdr.ClaimClaimableBalanceOp) (xdr.ClaimClaimableBalanceResult, bool) {
+	result := func(code xdr.ClaimClaimableBalanceResultCode) (xdr.ClaimClaimableBalanceResult, bool) {
+		return xdr.ClaimClaimableBalanceResult{Code: code}, code == xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceSuccess
+	}
+
+	key := claimableBalanceKey(op.BalanceId)
+	entry, ok := v.load(key)
+	if !ok {
+		return result(xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceDoesNotExist)
+	}
+	balance := entry.Data.MustClaimableBalance()
+
+	claimable := false
+	for _, claimant := range balance.Claimants {
+		if destination := claimant.MustV0().Destination; destination.Equals(source) {
+			claimable = predicateHolds(claimant.MustV0().Predicate, a.closeTime())
+			break
+		}
+	}
+	if !claimable {
+		return result(xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceCannotClaim)
+	}
+
+	switch a.changeBalance(v, source, balance.Asset, balance.Amount) {
+	case balanceNoTrust:
+		return result(xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceNoTrust)
+	case balanceNotAuthorized:
+		return result(xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceNotAuthorized)
+	case balanceLineFull:
+		return result(xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceLineFull)
+	}
+
+	v.remove(key)
+	if sponsor := entry.SponsoringID(); sponsor != nil {
+		if account, ok := v.loadAccount(*sponsor); ok {
+			addSponsoring(&account, -len(balance.Claimants))
+			v.storeAccount(account)
+		}
+	}
+	return result(xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceSuccess)
+}
@@ -0,0 +1,125 @@
+package simulator
+
+import (
+	"github.com/hcnet/go/xdr"
+)
+
+func accountKey(account xdr.AccountId) xdr.LedgerKey {
+	return xdr.LedgerKey{
+		Type:    xdr.LedgerEntryTypeAccount,
+		Account: &xdr.LedgerKeyAccount{AccountId: account},
+	}
+}
+
+func trustLineKey(account xdr.AccountId, asset xdr.TrustLineAsset) xdr.LedgerKey {
+	return xdr.LedgerKey{
+		Type:      xdr.LedgerEntryTypeTrustline,
+		TrustLine: &xdr.LedgerKeyTrustLine{AccountId: account, Asset: asset},
+	}
+}
+
+func offerKey(seller xdr.AccountId, offerID xdr.Int64) xdr.LedgerKey {
+	return xdr.LedgerKey{
+		Type:  xdr.LedgerEntryTypeOffer,
+		Offer: &xdr.LedgerKeyOffer{SellerId: seller, OfferId: offerID},
+	}
+}
+
+func claimableBalanceKey(id xdr.ClaimableBalanceId) xdr.LedgerKey {
+	return xdr.LedgerKey{
+		Type:             xdr.LedgerEntryTypeClaimableBalance,
+		ClaimableBalance: &xdr.LedgerKeyClaimableBalance{BalanceId: id},
+	}
+}
+
+func liquidityPoolKey(id xdr.PoolId) xdr.LedgerKey {
+	return xdr.LedgerKey{
+		Type:          xdr.LedgerEntryTypeLiquidityPool,
+		LiquidityPool: &xdr.LedgerKeyLiquidityPool{LiquidityPoolId: id},
+	}
+}
+
+func accountEntry(account xdr.AccountEntry) xdr.LedgerEntry {
+	return xdr.LedgerEntry{Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeAccount, Account: &account}}
+}
+
+func (v *view) loadAccount(id xdr.AccountId) (xdr.AccountEntry, bool) {
+	entry, ok := v.load(accountKey(id))
+	if !ok {
+		return xdr.AccountEntry{}, false
+	}
+	return entry.Data.MustAccount(), true
+}
+
+// storeAccount updates the account, keeping the extension of the stored
+// ledger entry.
+func (v *view) storeAccount(account xdr.AccountEntry) {
+	entry, ok := v.load(accountKey(account.AccountId))
+	if !ok {
+		entry = accountEntry(account)
+	}
+	entry.Data.Account = &account
+	v.store(entry)
+}
+
+func (v *view) loadTrustLine(account xdr.AccountId, asset xdr.TrustLineAsset) (xdr.LedgerEntry, bool) {
+	return v.load(trustLineKey(account, asset))
+}
+
+func accountExtV2(account *xdr.AccountEntry) *xdr.AccountEntryExtensionV2 {
+	if account.Ext.V == 0 {
+		account.Ext = xdr.AccountEntryExt{V: 1, V1: &xdr.AccountEntryExtensionV1{}}
+	}
+	if account.Ext.V1.Ext.V == 0 {
+		account.Ext.V1.Ext = xdr.AccountEntryExtensionV1Ext{
+			V: 2,
+			V2: &xdr.AccountEntryExtensionV2{
+				SignerSponsoringIDs: make([]xdr.SponsorshipDescriptor, len(account.Signers)),
+			},
+		}
+	}
+	return account.Ext.V1.Ext.V2
+}
+
+func accountExtV3(account *xdr.AccountEntry) *xdr.AccountEntryExtensionV3 {
+	ext := accountExtV2(account)
+	if ext.Ext.V == 0 {
+		ext.Ext = xdr.AccountEntryExtensionV2Ext{V: 3, V3: &xdr.AccountEntryExtensionV3{}}
+	}
+	return ext.Ext.V3
+}
+
+func addSponsoring(account *xdr.AccountEntry, delta int) {
+	ext := accountExtV2(account)
+	ext.NumSponsoring = xdr.Uint32(int(ext.NumSponsoring) + delta)
+}
+
+// minBalance is the balance the account must keep to pay for the reserve of
+// its sub entries and of the entries it sponsors.
+func minBalance(account xdr.AccountEntry, baseReserve uint32) xdr.Int64 {
+	count := 2 + int64(account.NumSubEntries) + int64(account.NumSponsoring()) - int64(account.NumSponsored())
+	return xdr.Int64(count * int64(baseReserve))
+}
+
+// availableBalance is the native balance the account can spend.
+func availableBalance(account xdr.AccountEntry, baseReserve uint32) xdr.Int64 {
+	return account.Balance - minBalance(account, baseReserve)
+}
+
+func trustLineAuthorized(line xdr.TrustLineEntry) bool {
+	return xdr.TrustLineFlags(line.Flags).IsAuthorized()
+}
+
+func trustLineExtV2(line *xdr.TrustLineEntry) *xdr.TrustLineEntryExtensionV2 {
+	if line.Ext.V == 0 {
+		line.Ext = xdr.TrustLineEntryExt{V: 1, V1: &xdr.TrustLineEntryV1{}}
+	}
+	if line.Ext.V1.Ext.V == 0 {
+		line.Ext.V1.Ext = xdr.TrustLineEntryV1Ext{V: 2, V2: &xdr.TrustLineEntryExtensionV2{}}
+	}
+	return line.Ext.V1.Ext.V2
+}
+
+func sponsoredBy(entry *xdr.LedgerEntry, sponsor xdr.AccountId) {
+	entry.Ext = xdr.LedgerEntryExt{V: 1, V1: &xdr.LedgerEntryExtensionV1{SponsoringId: &sponsor}}
+}
@@ -0,0 +1,156 @@
+package simulator
+
+import (
+	"math/big"
+
+	"github.com/hcnet/go/xdr"
+)
+
+// poolShares loads the liquidity pool and the trust line of the account to
+// its shares.
+func poolShares(v *view, account xdr.AccountId, poolID xdr.PoolId) (xdr.LedgerEntry, xdr.LedgerEntry, bool) {
+	line, ok := v.loadTrustLine(account, xdr.TrustLineAsset{
+		Type:            xdr.AssetTypeAssetTypePoolShare,
+		LiquidityPoolId: &poolID,
+	})
+	if !ok {
+		return xdr.LedgerEntry{}, xdr.LedgerEntry{}, false
+	}
+	pool, ok := v.load(liquidityPoolKey(poolID))
+	return pool, line, ok
+}
+
+// priceInRange returns true if amountA / amountB is in [min, max].
+func priceInRange(amountA, amountB xdr.Int64, min, max xdr.Price) bool {
+	a := big.NewInt(int64(amountA))
+	b := big.NewInt(int64(amountB))
+	lower := new(big.Int).Mul(a, big.NewInt(int64(min.D)))
+	lower.Sub(lower, new(big.Int).Mul(b, big.NewInt(int64(min.N))))
+	upper := new(big.Int).Mul(a, big.NewInt(int64(max.D)))
+	upper.Sub(upper, new(big.Int).Mul(b, big.NewInt(int64(max.N))))
+	return lower.Sign() >= 0 && upper.Sign() <= 0
+}
+
+func validPrice(price xdr.Price) bool {
+	return price.N > 0 && price.D > 0
+}
+
+func (a *applier) liquidityPoolDeposit(v *view, source xdr.AccountId, op xdr.LiquidityPoolDepositOp) (xdr.LiquidityPoolDepositResult, bool) {
+	result := func(code xdr.LiquidityPoolDepositResultCode) (xdr.LiquidityPoolDepositResult, bool) {
+		return xdr.LiquidityPoolDepositResult{Code: code}, code == xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositSuccess
+	}
+
+	if op.MaxAmountA <= 0 || op.MaxAmountB <= 0 || !validPrice(op.MinPrice) || !validPrice(op.MaxPrice) ||
+		op.MaxPrice.Cheaper(op.MinPrice) {
+		return result(xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositMalformed)
+	}
+	poolEntry, lineEntry, ok := poolShares(v, source, op.LiquidityPoolId)
+	if !ok {
+		return result(xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositNoTrust)
+	}
+	pool := poolEntry.Data.MustLiquidityPool().Body.MustConstantProduct()
+	line := lineEntry.Data.MustTrustLine()
+
+	var amountA, amountB, shares xdr.Int64
+	if pool.TotalPoolShares == 0 {
+		amountA, amountB = op.MaxAmountA, op.MaxAmountB
+		product := new(big.Int).Mul(big.NewInt(int64(amountA)), big.NewInt(int64(amountB)))
+		shares = xdr.Int64(product.Sqrt(product).Int64())
+	} else {
+		shares = mulDiv(pool.TotalPoolShares, int64(op.MaxAmountA), int64(pool.ReserveA), false)
+		if sharesB := mulDiv(pool.TotalPoolShares, int64(op.MaxAmountB), int64(pool.ReserveB), false); sharesB < shares {
+			shares = sharesB
+		}
+		amountA = mulDiv(shares, int64(pool.ReserveA), int64(pool.TotalPoolShares), true)
+		amountB = mulDiv(shares, int64(pool.ReserveB), int64(pool.TotalPoolShares), true)
+	}
+	if shares == 0 || amountA == 0 || amountB == 0 {
+		return result(xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositUnderfunded)
+	}
+	if !priceInRange(amountA, amountB, op.MinPrice, op.MaxPrice) {
+		return result(xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositBadPrice)
+	}
+
+	for _, deposit := range []struct {
+		asset  xdr.Asset
+		amount xdr.Int64
+	}{{pool.Params.AssetA, amountA}, {pool.Params.AssetB, amountB}} {
+		switch a.changeBalance(v, source, deposit.asset, -deposit.amount) {
+		case balanceNoTrust:
+			return result(xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositNoTrust)
+		case balanceNotAuthorized:
+			return result(xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositNotAuthorized)
+		case balanceUnderfunded:
+			return result(xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositUnderfunded)
+		}
+	}
+
+	const maxInt64 = xdr.Int64(^uint64(0) >> 1)
+	if line.Balance > line.Limit-shares {
+		return result(xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositLineFull)
+	}
+	if pool.ReserveA > maxInt64-amountA || pool.ReserveB > maxInt64-amountB || pool.TotalPoolShares > maxInt64-shares {
+		return result(xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositPoolFull)
+	}
+	line.Balance += shares
+	lineEntry.Data.TrustLine = &line
+	v.store(lineEntry)
+
+	pool.ReserveA += amountA
+	pool.ReserveB += amountB
+	pool.TotalPoolShares += shares
+	poolEntry.Data.LiquidityPool.Body.ConstantProduct = &pool
+	v.store(poolEntry)
+	return result(xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositSuccess)
+}
+
+func (a *applier) liquidityPoolWithdraw(v *view, source xdr.AccountId, op xdr.LiquidityPoolWithdrawOp) (xdr.LiquidityPoolWithdrawResult, bool) {
+	result := func(code xdr.LiquidityPoolWithdrawResultCode) (xdr.LiquidityPoolWithdrawResult, bool) {
+		return xdr.LiquidityPoolWithdrawResult{Code: code}, code == xdr.LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawSuccess
+	}
+
+	if op.Amount <= 0 || op.MinAmountA < 0 || op.MinAmountB < 0 {
+		return result(xdr.LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawMalformed)
+	}
+	poolEntry, lineEntry, ok := poolShares(v, source, op.LiquidityPoolId)
+	if !ok {
+		return result(xdr.LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawNoTrust)
+	}
+	pool := poolEntry.Data.MustLiquidityPool().Body.MustConstantProduct()
+	line := lineEntry.Data.MustTrustLine()
+	if line.Balance < op.Amount {
+		return result(xdr.LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawUnderfunded)
+	}
+
+	amountA := mulDiv(op.Amount, int64(pool.ReserveA), int64(pool.TotalPoolShares), false)
+	amountB := mulDiv(op.Amount, int64(pool.ReserveB), int64(pool.TotalPoolShares), false)
+	if amountA < op.MinAmountA || amountB < op.MinAmountB {
+		return result(xdr.LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawUnderMinimum)
+	}
+
+	for _, withdrawal := range []struct {
+		asset  xdr.Asset
+		amount xdr.Int64
+	}{{pool.Params.AssetA, amountA}, {pool.Params.AssetB, amountB}} {
+		if withdrawal.amount == 0 {
+			continue
+		}
+		switch a.changeBalance(v, source, withdrawal.asset, withdrawal.amount) {
+		case balanceNoTrust, balanceNotAuthorized:
+			return result(xdr.LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawNoTrust)
+		case balanceLineFull:
+			return result(xdr.LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawLineFull)
+		}
+	}
+
+	line.Balance -= op.Amount
+	lineEntry.Data.TrustLine = &line
+	v.store(lineEntry)
+
+	pool.ReserveA -= amountA
+	pool.ReserveB -= amountB
+	pool.TotalPoolShares -= op.Amount
+	poolEntry.Data.LiquidityPool.Body.ConstantProduct = &pool
+	v.store(poolEntry)
+	return result(xdr.LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawSuccess)
+}
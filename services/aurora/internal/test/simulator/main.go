@@ -0,0 +1,318 @@
+/*
+Package simulator runs an in-memory, single node network to test Aurora
+without hcnet-core.
+
+A Network applies classic transactions (account creation, payments,
+trustlines, offers, claimable balances and liquidity pools) to an in-memory
+ledger state and produces the LedgerCloseMeta and transaction results
+hcnet-core would produce. Ledgers are only closed when CloseLedger is called
+(or periodically by Run) so tests are deterministic: close times are derived
+from the ledger sequence and transactions are applied in the order they were
+submitted.
+
+The Network can be plugged into Aurora in three places:
+
+  - NewLedgerBackend returns a ledgerbackend.LedgerBackend streaming the
+    closed ledgers to ingestion.
+  - Submit implements txsub.Submitter, and ServeHTTP serves the /info and /tx
+    endpoints of hcnet-core so the network can also be used as
+    --hcnet-core-url.
+  - When Config.HistoryArchiveURL is set every checkpoint ledger is published
+    to the history archive, so ingestion can build its state from it.
+
+Operations other than the ones listed above fail with opNOT_SUPPORTED. The
+simulator does not track liabilities, so offers do not reserve the balances
+they sell, and offers never cross liquidity pools.
+*/
+package simulator
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/hcnet/go/historyarchive"
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/storage"
+	"github.com/hcnet/go/xdr"
+)
+
+const (
+	// DefaultProtocolVersion is the protocol version of the ledgers if
+	// Config.ProtocolVersion is not set.
+	DefaultProtocolVersion = 19
+	// DefaultBaseFee is the base fee of the network in stroops.
+	DefaultBaseFee = 100
+	// DefaultBaseReserve is the base reserve of the network in stroops.
+	DefaultBaseReserve = 5000000
+	// DefaultCloseInterval is the time between the close times of two
+	// consecutive ledgers.
+	DefaultCloseInterval = 5 * time.Second
+
+	totalCoins   = 1000000000000000000
+	maxTxSetSize = 1000
+)
+
+// DefaultGenesisCloseTime is the close time of the first ledger if
+// Config.GenesisCloseTime is not set.
+var DefaultGenesisCloseTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Config configures a Network.
+type Config struct {
+	NetworkPassphrase string
+	// CheckpointFrequency is the checkpoint frequency of the history archive,
+	// historyarchive.DefaultCheckpointFrequency if not set.
+	CheckpointFrequency uint32
+	// HistoryArchiveURL is the url of a writable storage, for example
+	// file:///tmp/archive, where checkpoints are published. Checkpoints are
+	// not published if it is empty.
+	HistoryArchiveURL string
+	ProtocolVersion   uint32
+	BaseFee           uint32
+	BaseReserve       uint32
+	GenesisCloseTime  time.Time
+	CloseInterval     time.Duration
+}
+
+// Network is an in-memory network. It is safe for concurrent use.
+type Network struct {
+	config Config
+	root   *keypair.Full
+
+	lock    sync.Mutex
+	state   *view
+	latest  xdr.LedgerHeaderHistoryEntry
+	ledgers map[uint32]xdr.LedgerCloseMeta
+	// closed is closed, and replaced, every time a ledger is closed.
+	closed chan struct{}
+
+	pending       []pendingTransaction
+	pendingHashes map[xdr.Hash]bool
+	// pendingSeqs holds the sequence number of the last pending transaction
+	// of every source account.
+	pendingSeqs map[string]int64
+
+	archive        *historyarchive.Archive
+	archiveStorage storage.Storage
+}
+
+// New creates a network whose genesis ledger contains the root account
+// (keypair.Root) holding all the lumens.
+func New(config Config) (*Network, error) {
+	if config.NetworkPassphrase == "" {
+		return nil, errors.New("network passphrase cannot be empty")
+	}
+	if config.CheckpointFrequency == 0 {
+		config.CheckpointFrequency = historyarchive.DefaultCheckpointFrequency
+	}
+	if config.ProtocolVersion == 0 {
+		config.ProtocolVersion = DefaultProtocolVersion
+	}
+	if config.BaseFee == 0 {
+		config.BaseFee = DefaultBaseFee
+	}
+	if config.BaseReserve == 0 {
+		config.BaseReserve = DefaultBaseReserve
+	}
+	if config.GenesisCloseTime.IsZero() {
+		config.GenesisCloseTime = DefaultGenesisCloseTime
+	}
+	if config.CloseInterval == 0 {
+		config.CloseInterval = DefaultCloseInterval
+	}
+
+	n := &Network{
+		config:        config,
+		root:          keypair.Root(config.NetworkPassphrase),
+		state:         newView(nil),
+		ledgers:       map[uint32]xdr.LedgerCloseMeta{},
+		closed:        make(chan struct{}),
+		pendingHashes: map[xdr.Hash]bool{},
+		pendingSeqs:   map[string]int64{},
+	}
+
+	if config.HistoryArchiveURL != "" {
+		var err error
+		n.archive, err = historyarchive.Connect(config.HistoryArchiveURL, historyarchive.ArchiveOptions{
+			NetworkPassphrase:   config.NetworkPassphrase,
+			CheckpointFrequency: config.CheckpointFrequency,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "could not connect to history archive")
+		}
+		n.archiveStorage, err = storage.ConnectBackend(config.HistoryArchiveURL, storage.ConnectOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "could not connect to history archive storage")
+		}
+	}
+
+	n.state.ledgerSeq = 1
+	n.state.store(accountEntry(xdr.AccountEntry{
+		AccountId:  xdr.MustAddress(n.root.Address()),
+		Balance:    totalCoins,
+		Thresholds: xdr.Thresholds{1, 0, 0, 0},
+	}))
+
+	header := xdr.LedgerHeader{
+		LedgerVersion: xdr.Uint32(config.ProtocolVersion),
+		ScpValue: xdr.HcnetValue{
+			CloseTime: xdr.TimePoint(n.closeTime(1).Unix()),
+		},
+		LedgerSeq:    1,
+		TotalCoins:   totalCoins,
+		BaseFee:      xdr.Uint32(config.BaseFee),
+		BaseReserve:  xdr.Uint32(config.BaseReserve),
+		MaxTxSetSize: maxTxSetSize,
+	}
+	var err error
+	header.BucketListHash, err = n.bucketListHash()
+	if err != nil {
+		return nil, err
+	}
+	n.latest, err = headerHistoryEntry(header)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// NetworkPassphrase returns the passphrase of the network.
+func (n *Network) NetworkPassphrase() string {
+	return n.config.NetworkPassphrase
+}
+
+// Root returns the keypair of the root account.
+func (n *Network) Root() *keypair.Full {
+	return n.root
+}
+
+// LatestLedger returns the header of the last closed ledger.
+func (n *Network) LatestLedger() xdr.LedgerHeaderHistoryEntry {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.latest
+}
+
+// LedgerEntry returns the current version of the ledger entry with the given
+// key.
+func (n *Network) LedgerEntry(key xdr.LedgerKey) (xdr.LedgerEntry, bool) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.state.load(key)
+}
+
+func (n *Network) closeTime(sequence uint32) time.Time {
+	return n.config.GenesisCloseTime.Add(time.Duration(sequence-1) * n.config.CloseInterval)
+}
+
+func headerHistoryEntry(header xdr.LedgerHeader) (xdr.LedgerHeaderHistoryEntry, error) {
+	hash, err := hashXDR(header)
+	if err != nil {
+		return xdr.LedgerHeaderHistoryEntry{}, errors.Wrap(err, "could not hash ledger header")
+	}
+	return xdr.LedgerHeaderHistoryEntry{Hash: hash, Header: header}, nil
+}
+
+func hashXDR(v interface{ MarshalBinary() ([]byte, error) }) (xdr.Hash, error) {
+	b, err := v.MarshalBinary()
+	if err != nil {
+		return xdr.Hash{}, err
+	}
+	return sha256.Sum256(b), nil
+}
+
+// CloseLedger applies the pending transactions and closes the next ledger,
+// returning its sequence.
+func (n *Network) CloseLedger() (uint32, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	header := n.latest.Header
+	header.LedgerSeq++
+	header.PreviousLedgerHash = n.latest.Hash
+	header.ScpValue = xdr.HcnetValue{
+		CloseTime: xdr.TimePoint(n.closeTime(uint32(header.LedgerSeq)).Unix()),
+		Upgrades:  []xdr.UpgradeType{},
+	}
+	n.state.ledgerSeq = uint32(header.LedgerSeq)
+
+	transactions := n.pending
+	n.pending = nil
+	n.pendingHashes = map[xdr.Hash]bool{}
+	n.pendingSeqs = map[string]int64{}
+
+	envelopes := make([]xdr.TransactionEnvelope, len(transactions))
+	processing := make([]xdr.TransactionResultMeta, len(transactions))
+	fees := make([]xdr.Int64, len(transactions))
+	// like hcnet-core, the fees of all the transactions are charged before
+	// any transaction is applied
+	for i, tx := range transactions {
+		envelopes[i] = tx.envelope
+		fees[i], processing[i].FeeProcessing = n.chargeFee(tx.envelope)
+		header.FeePool += fees[i]
+	}
+
+	results := make([]xdr.TransactionResultPair, len(transactions))
+	for i, tx := range transactions {
+		a := &applier{network: n, header: &header}
+		processing[i].Result, processing[i].TxApplyProcessing = a.applyTransaction(tx, fees[i])
+		results[i] = processing[i].Result
+	}
+
+	txSet := xdr.TransactionSet{PreviousLedgerHash: n.latest.Hash, Txs: envelopes}
+	var err error
+	if header.ScpValue.TxSetHash, err = hashXDR(txSet); err != nil {
+		return 0, errors.Wrap(err, "could not hash transaction set")
+	}
+	if header.TxSetResultHash, err = hashXDR(xdr.TransactionResultSet{Results: results}); err != nil {
+		return 0, errors.Wrap(err, "could not hash transaction results")
+	}
+	if header.BucketListHash, err = n.bucketListHash(); err != nil {
+		return 0, err
+	}
+	latest, err := headerHistoryEntry(header)
+	if err != nil {
+		return 0, err
+	}
+
+	sequence := uint32(header.LedgerSeq)
+	n.latest = latest
+	n.ledgers[sequence] = xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader:       latest,
+			TxSet:              txSet,
+			TxProcessing:       processing,
+			UpgradesProcessing: []xdr.UpgradeEntryMeta{},
+			ScpInfo:            []xdr.ScpHistoryEntry{},
+		},
+	}
+
+	if n.archive != nil && historyarchive.NewCheckpointManager(n.config.CheckpointFrequency).IsCheckpoint(sequence) {
+		if err := n.publishCheckpoint(sequence); err != nil {
+			return 0, err
+		}
+	}
+
+	close(n.closed)
+	n.closed = make(chan struct{})
+	return sequence, nil
+}
+
+// Run closes a ledger every interval until the context is done.
+func (n *Network) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := n.CloseLedger(); err != nil {
+				return err
+			}
+		}
+	}
+}
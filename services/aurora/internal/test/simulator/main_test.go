@@ -0,0 +1,456 @@
+package simulator
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/amount"
+	"github.com/hcnet/go/clients/hcnetcore"
+	"github.com/hcnet/go/historyarchive"
+	"github.com/hcnet/go/ingest"
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	proto "github.com/hcnet/go/protocols/hcnetcore"
+	"github.com/hcnet/go/services/aurora/internal/txsub"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/hcnet/go/xdr"
+)
+
+type harness struct {
+	t       *testing.T
+	network *Network
+}
+
+func newHarness(t *testing.T, config Config) *harness {
+	if config.NetworkPassphrase == "" {
+		config.NetworkPassphrase = network.TestNetworkPassphrase
+	}
+	n, err := New(config)
+	require.NoError(t, err)
+	return &harness{t: t, network: n}
+}
+
+func (h *harness) account(address string) xdr.AccountEntry {
+	entry, ok := h.network.LedgerEntry(accountKey(xdr.MustAddress(address)))
+	require.True(h.t, ok, "account %s does not exist", address)
+	return entry.Data.MustAccount()
+}
+
+func (h *harness) trustLineBalance(address string, asset xdr.TrustLineAsset) xdr.Int64 {
+	entry, ok := h.network.LedgerEntry(trustLineKey(xdr.MustAddress(address), asset))
+	require.True(h.t, ok)
+	return entry.Data.MustTrustLine().Balance
+}
+
+func (h *harness) transaction(source *keypair.Full, signers []*keypair.Full, ops ...txnbuild.Operation) *txnbuild.Transaction {
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount: &txnbuild.SimpleAccount{
+			AccountID: source.Address(),
+			Sequence:  int64(h.account(source.Address()).SeqNum),
+		},
+		IncrementSequenceNum: true,
+		Operations:           ops,
+		BaseFee:              txnbuild.MinBaseFee,
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()},
+	})
+	require.NoError(h.t, err)
+	tx, err = tx.Sign(h.network.NetworkPassphrase(), signers...)
+	require.NoError(h.t, err)
+	return tx
+}
+
+func (h *harness) submit(tx *txnbuild.Transaction) txsub.SubmissionResult {
+	b64, err := tx.Base64()
+	require.NoError(h.t, err)
+	return h.network.Submit(context.Background(), b64)
+}
+
+// apply submits the transaction, closes the ledger and returns the
+// transaction as read by ingestion.
+func (h *harness) apply(source *keypair.Full, ops ...txnbuild.Operation) ingest.LedgerTransaction {
+	result := h.submit(h.transaction(source, []*keypair.Full{source}, ops...))
+	require.NoError(h.t, result.Err)
+	require.Equal(h.t, proto.TXStatusPending, result.Status)
+
+	sequence, err := h.network.CloseLedger()
+	require.NoError(h.t, err)
+	reader, err := ingest.NewLedgerTransactionReader(
+		context.Background(), h.network.NewLedgerBackend(), h.network.NetworkPassphrase(), sequence,
+	)
+	require.NoError(h.t, err)
+	tx, err := reader.Read()
+	require.NoError(h.t, err)
+	_, err = reader.Read()
+	require.Equal(h.t, io.EOF, err)
+	return tx
+}
+
+func (h *harness) createAccount(balance string) *keypair.Full {
+	kp := keypair.MustRandom()
+	tx := h.apply(h.network.Root(), &txnbuild.CreateAccount{Destination: kp.Address(), Amount: balance})
+	require.True(h.t, tx.Result.Successful())
+	return kp
+}
+
+func operationResult(t *testing.T, tx ingest.LedgerTransaction, index int) xdr.OperationResultTr {
+	results, ok := tx.Result.OperationResults()
+	require.True(t, ok)
+	require.Equal(t, xdr.OperationResultCodeOpInner, results[index].Code)
+	return *results[index].Tr
+}
+
+func TestCreateAccountAndPayment(t *testing.T) {
+	h := newHarness(t, Config{})
+	genesis := h.network.LatestLedger()
+	root := h.network.Root()
+
+	kp := keypair.MustRandom()
+	tx := h.apply(root,
+		&txnbuild.CreateAccount{Destination: kp.Address(), Amount: "100"},
+		&txnbuild.Payment{Destination: kp.Address(), Amount: "10", Asset: txnbuild.NativeAsset{}},
+	)
+	assert.True(t, tx.Result.Successful())
+	assert.Equal(t, xdr.Int64(200), tx.Result.Result.FeeCharged)
+
+	latest := h.network.LatestLedger()
+	assert.Equal(t, xdr.Uint32(2), latest.Header.LedgerSeq)
+	assert.Equal(t, genesis.Hash, latest.Header.PreviousLedgerHash)
+	assert.Equal(t, xdr.Int64(200), latest.Header.FeePool)
+
+	account := h.account(kp.Address())
+	assert.Equal(t, xdr.Int64(110*amount.One), account.Balance)
+	assert.Equal(t, xdr.SequenceNumber(2<<32), account.SeqNum)
+	assert.Equal(t, xdr.Int64(totalCoins-110*amount.One-200), h.account(root.Address()).Balance)
+
+	changes, err := tx.GetChanges()
+	require.NoError(t, err)
+	// sequence bump, starting balance debit, account creation and the two
+	// balance updates of the payment
+	require.Len(t, changes, 5)
+	created := 0
+	for _, change := range changes {
+		if change.Pre == nil {
+			created++
+			assert.Equal(t, kp.Address(), change.Post.Data.MustAccount().AccountId.Address())
+		}
+	}
+	assert.Equal(t, 1, created)
+	assert.Len(t, tx.GetFeeChanges(), 1)
+}
+
+func TestSubmitValidation(t *testing.T) {
+	h := newHarness(t, Config{})
+	root := h.network.Root()
+	kp := h.createAccount("100")
+
+	resultCode := func(result txsub.SubmissionResult) xdr.TransactionResultCode {
+		require.Equal(t, proto.TXStatusError, result.Status)
+		failed, ok := result.Err.(*txsub.FailedTransactionError)
+		require.True(t, ok)
+		txResult, err := failed.Result()
+		require.NoError(t, err)
+		return txResult.Result.Code
+	}
+
+	payment := &txnbuild.Payment{Destination: root.Address(), Amount: "1", Asset: txnbuild.NativeAsset{}}
+	badAuth := h.transaction(kp, []*keypair.Full{root}, payment)
+	assert.Equal(t, xdr.TransactionResultCodeTxBadAuth, resultCode(h.submit(badAuth)))
+
+	tx := h.transaction(kp, []*keypair.Full{kp}, payment)
+	result := h.submit(tx)
+	require.NoError(t, result.Err)
+	assert.Equal(t, proto.TXStatusDuplicate, h.submit(tx).Status)
+	// the sequence number is already used by the pending transaction
+	badSeq := h.transaction(kp, []*keypair.Full{kp}, payment, payment)
+	assert.Equal(t, xdr.TransactionResultCodeTxBadSeq, resultCode(h.submit(badSeq)))
+
+	invalid := h.network.Submit(context.Background(), "AAAA")
+	assert.Error(t, invalid.Err)
+
+	_, err := h.network.CloseLedger()
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Int64(99*amount.One-100), h.account(kp.Address()).Balance)
+
+	// operations which fail make the transaction fail and are rolled back
+	failed := h.apply(kp,
+		&txnbuild.Payment{Destination: root.Address(), Amount: "1", Asset: txnbuild.NativeAsset{}},
+		&txnbuild.Payment{Destination: root.Address(), Amount: "1000", Asset: txnbuild.NativeAsset{}},
+	)
+	assert.Equal(t, xdr.TransactionResultCodeTxFailed, failed.Result.Result.Result.Code)
+	assert.Equal(t, xdr.PaymentResultCodePaymentSuccess, operationResult(t, failed, 0).MustPaymentResult().Code)
+	assert.Equal(t, xdr.PaymentResultCodePaymentUnderfunded, operationResult(t, failed, 1).MustPaymentResult().Code)
+	account := h.account(kp.Address())
+	assert.Equal(t, xdr.Int64(99*amount.One-300), account.Balance)
+	assert.Equal(t, xdr.SequenceNumber(2<<32+2), account.SeqNum)
+}
+
+func TestOffers(t *testing.T) {
+	h := newHarness(t, Config{})
+	issuer := h.createAccount("100")
+	seller := h.createAccount("100")
+	buyer := h.createAccount("100")
+	usd := txnbuild.CreditAsset{Code: "USD", Issuer: issuer.Address()}
+	usdLine := xdr.MustNewCreditAsset("USD", issuer.Address()).ToTrustLineAsset()
+
+	for _, kp := range []*keypair.Full{seller, buyer} {
+		line, err := usd.ToChangeTrustAsset()
+		require.NoError(t, err)
+		tx := h.apply(kp, &txnbuild.ChangeTrust{Line: line, Limit: "1000"})
+		require.True(t, tx.Result.Successful())
+	}
+	tx := h.apply(issuer, &txnbuild.Payment{Destination: seller.Address(), Amount: "100", Asset: usd})
+	require.True(t, tx.Result.Successful())
+
+	tx = h.apply(seller, &txnbuild.ManageSellOffer{
+		Selling: usd,
+		Buying:  txnbuild.NativeAsset{},
+		Amount:  "100",
+		Price:   xdr.Price{N: 1, D: 1},
+	})
+	require.True(t, tx.Result.Successful())
+	sellOffer := operationResult(t, tx, 0).MustManageSellOfferResult().MustSuccess()
+	assert.Equal(t, xdr.ManageOfferEffectManageOfferCreated, sellOffer.Offer.Effect)
+	assert.Equal(t, xdr.Int64(1), sellOffer.Offer.Offer.OfferId)
+	assert.Equal(t, xdr.Uint32(2), h.account(seller.Address()).NumSubEntries)
+
+	tx = h.apply(buyer, &txnbuild.ManageSellOffer{
+		Selling: txnbuild.NativeAsset{},
+		Buying:  usd,
+		Amount:  "30",
+		Price:   xdr.Price{N: 1, D: 1},
+	})
+	require.True(t, tx.Result.Successful())
+	buyOffer := operationResult(t, tx, 0).MustManageSellOfferResult().MustSuccess()
+	assert.Equal(t, xdr.ManageOfferEffectManageOfferDeleted, buyOffer.Offer.Effect)
+	require.Len(t, buyOffer.OffersClaimed, 1)
+	claimed := buyOffer.OffersClaimed[0].MustOrderBook()
+	assert.Equal(t, xdr.Int64(1), claimed.OfferId)
+	assert.Equal(t, xdr.Int64(30*amount.One), claimed.AmountSold)
+	assert.Equal(t, xdr.Int64(30*amount.One), claimed.AmountBought)
+
+	assert.Equal(t, xdr.Int64(30*amount.One), h.trustLineBalance(buyer.Address(), usdLine))
+	assert.Equal(t, xdr.Int64(70*amount.One), h.trustLineBalance(seller.Address(), usdLine))
+	offer, ok := h.network.LedgerEntry(offerKey(xdr.MustAddress(seller.Address()), 1))
+	require.True(t, ok)
+	assert.Equal(t, xdr.Int64(70*amount.One), offer.Data.MustOffer().Amount)
+
+	// the seller cannot cross its own offer
+	tx = h.apply(seller, &txnbuild.ManageSellOffer{
+		Selling: txnbuild.NativeAsset{},
+		Buying:  usd,
+		Amount:  "1",
+		Price:   xdr.Price{N: 1, D: 1},
+	})
+	assert.Equal(t,
+		xdr.ManageSellOfferResultCodeManageSellOfferCrossSelf,
+		operationResult(t, tx, 0).MustManageSellOfferResult().Code,
+	)
+}
+
+func TestClaimableBalances(t *testing.T) {
+	h := newHarness(t, Config{})
+	root := h.network.Root()
+	kp := h.createAccount("10")
+
+	create := h.transaction(root, []*keypair.Full{root}, &txnbuild.CreateClaimableBalance{
+		Amount:       "5",
+		Asset:        txnbuild.NativeAsset{},
+		Destinations: []txnbuild.Claimant{txnbuild.NewClaimant(kp.Address(), nil)},
+	})
+	expectedID, err := create.ClaimableBalanceID(0)
+	require.NoError(t, err)
+	require.NoError(t, h.submit(create).Err)
+	_, err = h.network.CloseLedger()
+	require.NoError(t, err)
+
+	var id xdr.ClaimableBalanceId
+	require.NoError(t, xdr.SafeUnmarshalHex(expectedID, &id))
+	entry, ok := h.network.LedgerEntry(claimableBalanceKey(id))
+	require.True(t, ok)
+	assert.Equal(t, root.Address(), entry.SponsoringID().Address())
+	rootAccount := h.account(root.Address())
+	assert.Equal(t, xdr.Uint32(1), rootAccount.NumSponsoring())
+
+	before := h.account(kp.Address()).Balance
+	tx := h.apply(kp, &txnbuild.ClaimClaimableBalance{BalanceID: expectedID})
+	require.True(t, tx.Result.Successful())
+	assert.Equal(t, before+5*amount.One-100, h.account(kp.Address()).Balance)
+	rootAccount = h.account(root.Address())
+	assert.Equal(t, xdr.Uint32(0), rootAccount.NumSponsoring())
+	_, ok = h.network.LedgerEntry(claimableBalanceKey(id))
+	assert.False(t, ok)
+
+	tx = h.apply(kp, &txnbuild.ClaimClaimableBalance{BalanceID: expectedID})
+	assert.Equal(t,
+		xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceDoesNotExist,
+		operationResult(t, tx, 0).MustClaimClaimableBalanceResult().Code,
+	)
+}
+
+func TestLiquidityPools(t *testing.T) {
+	h := newHarness(t, Config{})
+	issuer := h.createAccount("100")
+	kp := h.createAccount("1000")
+	usd := txnbuild.CreditAsset{Code: "USD", Issuer: issuer.Address()}
+
+	line, err := usd.ToChangeTrustAsset()
+	require.NoError(t, err)
+	require.True(t, h.apply(kp, &txnbuild.ChangeTrust{Line: line, Limit: "10000"}).Result.Successful())
+	require.True(t, h.apply(issuer, &txnbuild.Payment{Destination: kp.Address(), Amount: "1000", Asset: usd}).Result.Successful())
+
+	poolID, err := txnbuild.NewLiquidityPoolId(txnbuild.NativeAsset{}, usd)
+	require.NoError(t, err)
+	tx := h.apply(kp, &txnbuild.ChangeTrust{
+		Line: txnbuild.LiquidityPoolShareChangeTrustAsset{LiquidityPoolParameters: txnbuild.LiquidityPoolParameters{
+			AssetA: txnbuild.NativeAsset{},
+			AssetB: usd,
+			Fee:    txnbuild.LiquidityPoolFeeV18,
+		}},
+		Limit: txnbuild.MaxTrustlineLimit,
+	})
+	require.True(t, tx.Result.Successful())
+	assert.Equal(t, xdr.Uint32(3), h.account(kp.Address()).NumSubEntries)
+
+	tx = h.apply(kp, &txnbuild.LiquidityPoolDeposit{
+		LiquidityPoolID: poolID,
+		MaxAmountA:      "100",
+		MaxAmountB:      "400",
+		MinPrice:        xdr.Price{N: 1, D: 5},
+		MaxPrice:        xdr.Price{N: 1, D: 3},
+	})
+	require.True(t, tx.Result.Successful())
+	entry, ok := h.network.LedgerEntry(liquidityPoolKey(xdr.PoolId(poolID)))
+	require.True(t, ok)
+	pool := entry.Data.MustLiquidityPool().Body.MustConstantProduct()
+	assert.Equal(t, xdr.Int64(100*amount.One), pool.ReserveA)
+	assert.Equal(t, xdr.Int64(400*amount.One), pool.ReserveB)
+	assert.Equal(t, xdr.Int64(200*amount.One), pool.TotalPoolShares)
+	assert.Equal(t, xdr.Int64(1), pool.PoolSharesTrustLineCount)
+
+	tx = h.apply(kp, &txnbuild.LiquidityPoolWithdraw{
+		LiquidityPoolID: poolID,
+		Amount:          "100",
+		MinAmountA:      "50",
+		MinAmountB:      "200",
+	})
+	require.True(t, tx.Result.Successful())
+	entry, _ = h.network.LedgerEntry(liquidityPoolKey(xdr.PoolId(poolID)))
+	pool = entry.Data.MustLiquidityPool().Body.MustConstantProduct()
+	assert.Equal(t, xdr.Int64(50*amount.One), pool.ReserveA)
+	assert.Equal(t, xdr.Int64(200*amount.One), pool.ReserveB)
+	assert.Equal(t, xdr.Int64(800*amount.One), h.trustLineBalance(kp.Address(), xdr.MustNewCreditAsset("USD", issuer.Address()).ToTrustLineAsset()))
+}
+
+func TestCheckpointPublishing(t *testing.T) {
+	dir := t.TempDir()
+	h := newHarness(t, Config{
+		CheckpointFrequency: 8,
+		HistoryArchiveURL:   "file://" + dir,
+	})
+	h.createAccount("100")
+	for {
+		sequence, err := h.network.CloseLedger()
+		require.NoError(t, err)
+		if sequence == 7 {
+			break
+		}
+	}
+
+	// ingestion reads archives over http
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+	archive, err := historyarchive.Connect(server.URL, historyarchive.ArchiveOptions{
+		NetworkPassphrase:   h.network.NetworkPassphrase(),
+		CheckpointFrequency: 8,
+	})
+	require.NoError(t, err)
+	has, err := archive.GetCheckpointHAS(7)
+	require.NoError(t, err)
+	bucketListHash, err := has.BucketListHash()
+	require.NoError(t, err)
+	assert.Equal(t, h.network.LatestLedger().Header.BucketListHash, bucketListHash)
+
+	reader, err := ingest.NewCheckpointChangeReader(context.Background(), archive, 7)
+	require.NoError(t, err)
+	defer reader.Close()
+	accounts := 0
+	for {
+		change, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, xdr.LedgerEntryTypeAccount, change.Type)
+		accounts++
+	}
+	assert.Equal(t, 2, accounts)
+}
+
+func TestLedgerBackend(t *testing.T) {
+	h := newHarness(t, Config{})
+	backend := h.network.NewLedgerBackend()
+	ctx := context.Background()
+
+	_, err := backend.GetLedger(ctx, 1)
+	assert.Error(t, err)
+
+	ledger := make(chan xdr.LedgerCloseMeta)
+	go func() {
+		meta, err := backend.GetLedger(ctx, 3)
+		assert.NoError(t, err)
+		ledger <- meta
+	}()
+	for i := 0; i < 2; i++ {
+		_, err = h.network.CloseLedger()
+		require.NoError(t, err)
+	}
+	select {
+	case meta := <-ledger:
+		assert.Equal(t, uint32(3), meta.LedgerSequence())
+	case <-time.After(5 * time.Second):
+		t.Fatal("ledger 3 was not returned")
+	}
+
+	latest, err := backend.GetLatestLedgerSequence(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), latest)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = backend.GetLedger(cancelled, 4)
+	assert.Equal(t, context.Canceled, err)
+
+	require.NoError(t, backend.Close())
+	_, err = backend.GetLatestLedgerSequence(ctx)
+	assert.Error(t, err)
+}
+
+func TestServeHTTP(t *testing.T) {
+	h := newHarness(t, Config{})
+	server := httptest.NewServer(h.network)
+	defer server.Close()
+	client := &hcnetcore.Client{HTTP: http.DefaultClient, URL: server.URL}
+
+	info, err := client.Info(context.Background())
+	require.NoError(t, err)
+	assert.True(t, info.IsSynced())
+	assert.Equal(t, network.TestNetworkPassphrase, info.Info.Network)
+	assert.Equal(t, 1, info.Info.Ledger.Num)
+
+	root := h.network.Root()
+	tx := h.transaction(root, []*keypair.Full{root},
+		&txnbuild.CreateAccount{Destination: keypair.MustRandom().Address(), Amount: "1"},
+	)
+	b64, err := tx.Base64()
+	require.NoError(t, err)
+	response, err := client.SubmitTransaction(context.Background(), b64)
+	require.NoError(t, err)
+	assert.Equal(t, proto.TXStatusPending, response.Status)
+}
@@ -0,0 +1,284 @@
+package simulator
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/hcnet/go/xdr"
+)
+
+// sellOffer is a manage sell offer, create passive sell offer or manage buy
+// offer operation converted to sell semantics.
+type sellOffer struct {
+	selling xdr.Asset
+	buying  xdr.Asset
+	amount  xdr.Int64
+	price   xdr.Price
+	offerID xdr.Int64
+	passive bool
+}
+
+func (a *applier) manageSellOffer(v *view, source xdr.AccountId, op xdr.ManageSellOfferOp) (xdr.ManageSellOfferResult, bool) {
+	code, success := a.manageOffer(v, source, sellOffer{
+		selling: op.Selling,
+		buying:  op.Buying,
+		amount:  op.Amount,
+		price:   op.Price,
+		offerID: op.OfferId,
+	})
+	return xdr.ManageSellOfferResult{Code: code, Success: success}, success != nil
+}
+
+func (a *applier) createPassiveSellOffer(v *view, source xdr.AccountId, op xdr.CreatePassiveSellOfferOp) (xdr.ManageSellOfferResult, bool) {
+	code, success := a.manageOffer(v, source, sellOffer{
+		selling: op.Selling,
+		buying:  op.Buying,
+		amount:  op.Amount,
+		price:   op.Price,
+		passive: true,
+	})
+	return xdr.ManageSellOfferResult{Code: code, Success: success}, success != nil
+}
+
+// manageBuyOffer converts the buy offer into the sell offer selling the
+// amount needed to buy op.BuyAmount at op.Price, which is how hcnet-core
+// stores buy offers.
+func (a *applier) manageBuyOffer(v *view, source xdr.AccountId, op xdr.ManageBuyOfferOp) (xdr.ManageBuyOfferResult, bool) {
+	offer := sellOffer{
+		selling: op.Selling,
+		buying:  op.Buying,
+		amount:  op.BuyAmount,
+		price:   xdr.Price{N: op.Price.D, D: op.Price.N},
+		offerID: op.OfferId,
+	}
+	if op.Price.N > 0 && op.Price.D > 0 && op.BuyAmount > 0 {
+		offer.amount = mulDiv(op.BuyAmount, int64(op.Price.N), int64(op.Price.D), false)
+	}
+	code, success := a.manageOffer(v, source, offer)
+	return xdr.ManageBuyOfferResult{Code: xdr.ManageBuyOfferResultCode(code), Success: success}, success != nil
+}
+
+// mulDiv returns a * b / c rounded down, or up if roundUp is true. The result
+// is capped at math.MaxInt64.
+func mulDiv(a xdr.Int64, b, c int64, roundUp bool) xdr.Int64 {
+	n := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(b))
+	d := big.NewInt(c)
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if roundUp && r.Sign() != 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if !q.IsInt64() {
+		return xdr.Int64(^uint64(0) >> 1)
+	}
+	return xdr.Int64(q.Int64())
+}
+
+func validAsset(asset xdr.Asset) bool {
+	_, err := asset.MarshalBinary()
+	return err == nil && asset.Type != xdr.AssetTypeAssetTypePoolShare
+}
+
+// checkOfferTrust returns the code of an offer the source cannot hold the
+// assets of.
+func checkOfferTrust(v *view, source xdr.AccountId, offer sellOffer) xdr.ManageSellOfferResultCode {
+	for _, side := range []struct {
+		asset         xdr.Asset
+		noTrust       xdr.ManageSellOfferResultCode
+		notAuthorized xdr.ManageSellOfferResultCode
+	}{
+		{offer.selling, xdr.ManageSellOfferResultCodeManageSellOfferSellNoTrust, xdr.ManageSellOfferResultCodeManageSellOfferSellNotAuthorized},
+		{offer.buying, xdr.ManageSellOfferResultCodeManageSellOfferBuyNoTrust, xdr.ManageSellOfferResultCodeManageSellOfferBuyNotAuthorized},
+	} {
+		if side.asset.Type == xdr.AssetTypeAssetTypeNative || isIssuer(source, side.asset) {
+			continue
+		}
+		entry, ok := v.loadTrustLine(source, side.asset.ToTrustLineAsset())
+		if !ok {
+			return side.noTrust
+		}
+		if !trustLineAuthorized(entry.Data.MustTrustLine()) {
+			return side.notAuthorized
+		}
+	}
+	return xdr.ManageSellOfferResultCodeManageSellOfferSuccess
+}
+
+func (a *applier) manageOffer(v *view, source xdr.AccountId, offer sellOffer) (xdr.ManageSellOfferResultCode, *xdr.ManageOfferSuccessResult) {
+	if !validAsset(offer.selling) || !validAsset(offer.buying) || offer.selling.Equals(offer.buying) ||
+		offer.amount < 0 || offer.price.N <= 0 || offer.price.D <= 0 || (offer.offerID == 0 && offer.amount == 0) {
+		return xdr.ManageSellOfferResultCodeManageSellOfferMalformed, nil
+	}
+
+	var existing *xdr.OfferEntry
+	if offer.offerID != 0 {
+		entry, ok := v.load(offerKey(source, offer.offerID))
+		if !ok {
+			return xdr.ManageSellOfferResultCodeManageSellOfferNotFound, nil
+		}
+		current := entry.Data.MustOffer()
+		existing = &current
+		offer.passive = xdr.OfferEntryFlags(current.Flags)&xdr.OfferEntryFlagsPassiveFlag != 0
+	}
+
+	success := &xdr.ManageOfferSuccessResult{OffersClaimed: []xdr.ClaimAtom{}}
+	if offer.amount == 0 {
+		v.remove(offerKey(source, offer.offerID))
+		a.addSubEntries(v, source, -1)
+		success.Offer.Effect = xdr.ManageOfferEffectManageOfferDeleted
+		return xdr.ManageSellOfferResultCodeManageSellOfferSuccess, success
+	}
+
+	if code := checkOfferTrust(v, source, offer); code != xdr.ManageSellOfferResultCodeManageSellOfferSuccess {
+		return code, nil
+	}
+	budget := a.balance(v, source, offer.selling)
+	if budget <= 0 {
+		return xdr.ManageSellOfferResultCodeManageSellOfferUnderfunded, nil
+	}
+	if budget > offer.amount {
+		budget = offer.amount
+	}
+
+	remaining := offer.amount
+	for _, counter := range counterOffers(v, offer) {
+		if budget == 0 {
+			break
+		}
+		if counter.SellerId.Equals(source) {
+			return xdr.ManageSellOfferResultCodeManageSellOfferCrossSelf, nil
+		}
+		atom, ok := a.crossOffer(v, source, offer, counter, budget)
+		if !ok {
+			continue
+		}
+		if atom.AmountSold == 0 {
+			break
+		}
+		success.OffersClaimed = append(success.OffersClaimed, xdr.ClaimAtom{
+			Type:      xdr.ClaimAtomTypeClaimAtomTypeOrderBook,
+			OrderBook: &atom,
+		})
+		budget -= atom.AmountBought
+		remaining -= atom.AmountBought
+	}
+
+	if remaining == 0 {
+		if existing != nil {
+			v.remove(offerKey(source, offer.offerID))
+			a.addSubEntries(v, source, -1)
+		}
+		success.Offer.Effect = xdr.ManageOfferEffectManageOfferDeleted
+		return xdr.ManageSellOfferResultCodeManageSellOfferSuccess, success
+	}
+
+	entry := xdr.OfferEntry{
+		SellerId: source,
+		OfferId:  offer.offerID,
+		Selling:  offer.selling,
+		Buying:   offer.buying,
+		Amount:   remaining,
+		Price:    offer.price,
+	}
+	if offer.passive {
+		entry.Flags = xdr.Uint32(xdr.OfferEntryFlagsPassiveFlag)
+	}
+	if existing != nil {
+		entry.Ext = existing.Ext
+		success.Offer.Effect = xdr.ManageOfferEffectManageOfferUpdated
+	} else {
+		if !a.addSubEntries(v, source, 1) {
+			return xdr.ManageSellOfferResultCodeManageSellOfferLowReserve, nil
+		}
+		a.header.IdPool++
+		entry.OfferId = xdr.Int64(a.header.IdPool)
+		success.Offer.Effect = xdr.ManageOfferEffectManageOfferCreated
+	}
+	v.store(xdr.LedgerEntry{Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeOffer, Offer: &entry}})
+	success.Offer.Offer = &entry
+	return xdr.ManageSellOfferResultCodeManageSellOfferSuccess, success
+}
+
+// counterOffers returns the offers crossing the offer, best price first.
+// Passive offers do not cross offers at the same price.
+func counterOffers(v *view, offer sellOffer) []xdr.OfferEntry {
+	var offers []xdr.OfferEntry
+	for _, entry := range v.all(xdr.LedgerEntryTypeOffer) {
+		counter := entry.Data.MustOffer()
+		if !counter.Selling.Equals(offer.buying) || !counter.Buying.Equals(offer.selling) {
+			continue
+		}
+		// the offers cross if counter.Price * offer.price <= 1
+		lhs := int64(counter.Price.N) * int64(offer.price.N)
+		rhs := int64(counter.Price.D) * int64(offer.price.D)
+		if lhs > rhs || (offer.passive && lhs == rhs) {
+			continue
+		}
+		offers = append(offers, counter)
+	}
+	sort.SliceStable(offers, func(i, j int) bool {
+		if offers[i].Price.Equal(offers[j].Price) {
+			return offers[i].OfferId < offers[j].OfferId
+		}
+		return offers[i].Price.Cheaper(offers[j].Price)
+	})
+	return offers
+}
+
+// crossOffer exchanges up to budget of the selling asset of the offer with
+// the counter offer at the price of the counter offer. Counter offers whose
+// seller cannot deliver or receive the assets are removed and false is
+// returned.
+func (a *applier) crossOffer(v *view, source xdr.AccountId, offer sellOffer, counter xdr.OfferEntry, budget xdr.Int64) (xdr.ClaimOfferAtom, bool) {
+	available := a.balance(v, counter.SellerId, counter.Selling)
+	if available > counter.Amount {
+		available = counter.Amount
+	}
+
+	bought := mulDiv(budget, int64(counter.Price.D), int64(counter.Price.N), false)
+	if bought > available {
+		bought = available
+	}
+	sold := mulDiv(bought, int64(counter.Price.N), int64(counter.Price.D), true)
+	atom := xdr.ClaimOfferAtom{
+		SellerId:     counter.SellerId,
+		OfferId:      counter.OfferId,
+		AssetSold:    counter.Selling,
+		AmountSold:   bought,
+		AssetBought:  counter.Buying,
+		AmountBought: sold,
+	}
+	if available == 0 {
+		a.removeOffer(v, counter)
+		return atom, false
+	}
+	if bought == 0 {
+		return atom, true
+	}
+
+	exchange := newView(v)
+	if a.changeBalance(exchange, counter.SellerId, counter.Selling, -bought) != balanceChanged ||
+		a.changeBalance(exchange, counter.SellerId, counter.Buying, sold) != balanceChanged {
+		a.removeOffer(v, counter)
+		return atom, false
+	}
+	if a.changeBalance(exchange, source, offer.selling, -sold) != balanceChanged ||
+		a.changeBalance(exchange, source, offer.buying, bought) != balanceChanged {
+		// the source cannot receive the bought asset, stop crossing
+		atom.AmountSold = 0
+		return atom, true
+	}
+	exchange.commit()
+
+	counter.Amount -= bought
+	if counter.Amount == 0 {
+		a.removeOffer(v, counter)
+	} else {
+		v.store(xdr.LedgerEntry{Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeOffer, Offer: &counter}})
+	}
+	return atom, true
+}
+
+func (a *applier) removeOffer(v *view, offer xdr.OfferEntry) {
+	v.remove(offerKey(offer.SellerId, offer.OfferId))
+	a.addSubEntries(v, offer.SellerId, -1)
+}
@@ -0,0 +1,394 @@
+package simulator
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/hcnet/go/xdr"
+)
+
+func (a *applier) createAccount(v *view, source xdr.AccountId, op xdr.CreateAccountOp) (xdr.CreateAccountResult, bool) {
+	result := func(code xdr.CreateAccountResultCode) (xdr.CreateAccountResult, bool) {
+		return xdr.CreateAccountResult{Code: code}, code == xdr.CreateAccountResultCodeCreateAccountSuccess
+	}
+
+	if op.StartingBalance < 0 || op.Destination.Equals(source) {
+		return result(xdr.CreateAccountResultCodeCreateAccountMalformed)
+	}
+	if _, ok := v.loadAccount(op.Destination); ok {
+		return result(xdr.CreateAccountResultCodeCreateAccountAlreadyExist)
+	}
+	if op.StartingBalance < xdr.Int64(2*a.baseReserve()) {
+		return result(xdr.CreateAccountResultCodeCreateAccountLowReserve)
+	}
+	if a.changeBalance(v, source, xdr.MustNewNativeAsset(), -op.StartingBalance) != balanceChanged {
+		return result(xdr.CreateAccountResultCodeCreateAccountUnderfunded)
+	}
+
+	account := xdr.AccountEntry{
+		AccountId:  op.Destination,
+		Balance:    op.StartingBalance,
+		SeqNum:     xdr.SequenceNumber(int64(a.header.LedgerSeq) << 32),
+		Thresholds: xdr.Thresholds{1, 0, 0, 0},
+	}
+	v.store(accountEntry(account))
+	return result(xdr.CreateAccountResultCodeCreateAccountSuccess)
+}
+
+func (a *applier) payment(v *view, source xdr.AccountId, op xdr.PaymentOp) (xdr.PaymentResult, bool) {
+	result := func(code xdr.PaymentResultCode) (xdr.PaymentResult, bool) {
+		return xdr.PaymentResult{Code: code}, code == xdr.PaymentResultCodePaymentSuccess
+	}
+
+	if op.Amount <= 0 {
+		return result(xdr.PaymentResultCodePaymentMalformed)
+	}
+	destination := op.Destination.ToAccountId()
+	if _, ok := v.loadAccount(destination); !ok {
+		return result(xdr.PaymentResultCodePaymentNoDestination)
+	}
+
+	switch a.changeBalance(v, source, op.Asset, -op.Amount) {
+	case balanceNoTrust:
+		return result(xdr.PaymentResultCodePaymentSrcNoTrust)
+	case balanceNotAuthorized:
+		return result(xdr.PaymentResultCodePaymentSrcNotAuthorized)
+	case balanceUnderfunded:
+		return result(xdr.PaymentResultCodePaymentUnderfunded)
+	}
+	switch a.changeBalance(v, destination, op.Asset, op.Amount) {
+	case balanceNoTrust:
+		return result(xdr.PaymentResultCodePaymentNoTrust)
+	case balanceNotAuthorized:
+		return result(xdr.PaymentResultCodePaymentNotAuthorized)
+	case balanceLineFull:
+		return result(xdr.PaymentResultCodePaymentLineFull)
+	}
+	return result(xdr.PaymentResultCodePaymentSuccess)
+}
+
+// initialTrustLineFlags returns the flags of a new trust line to an asset of
+// the issuer.
+func initialTrustLineFlags(issuer xdr.AccountEntry) xdr.Uint32 {
+	flags := xdr.AccountFlags(issuer.Flags)
+	if flags.IsAuthRequired() {
+		return 0
+	}
+	trustLineFlags := xdr.TrustLineFlagsAuthorizedFlag
+	if flags.IsAuthClawbackEnabled() {
+		trustLineFlags |= xdr.TrustLineFlagsTrustlineClawbackEnabledFlag
+	}
+	return xdr.Uint32(trustLineFlags)
+}
+
+func (a *applier) changeTrust(v *view, source xdr.AccountId, op xdr.ChangeTrustOp) (xdr.ChangeTrustResult, bool) {
+	result := func(code xdr.ChangeTrustResultCode) (xdr.ChangeTrustResult, bool) {
+		return xdr.ChangeTrustResult{Code: code}, code == xdr.ChangeTrustResultCodeChangeTrustSuccess
+	}
+
+	if op.Limit < 0 || op.Line.Type == xdr.AssetTypeAssetTypeNative {
+		return result(xdr.ChangeTrustResultCodeChangeTrustMalformed)
+	}
+	if op.Line.Type == xdr.AssetTypeAssetTypePoolShare {
+		return result(a.changePoolShareTrust(v, source, op))
+	}
+
+	asset := op.Line.ToAsset()
+	if isIssuer(source, asset) {
+		return result(xdr.ChangeTrustResultCodeChangeTrustMalformed)
+	}
+	lineAsset := asset.ToTrustLineAsset()
+	entry, ok := v.loadTrustLine(source, lineAsset)
+	if ok {
+		line := entry.Data.MustTrustLine()
+		if op.Limit < line.Balance {
+			return result(xdr.ChangeTrustResultCodeChangeTrustInvalidLimit)
+		}
+		if op.Limit > 0 {
+			line.Limit = op.Limit
+			entry.Data.TrustLine = &line
+			v.store(entry)
+			return result(xdr.ChangeTrustResultCodeChangeTrustSuccess)
+		}
+		if line.Ext.V == 1 && line.Ext.V1.Ext.V == 2 && line.Ext.V1.Ext.V2.LiquidityPoolUseCount > 0 {
+			return result(xdr.ChangeTrustResultCodeChangeTrustCannotDelete)
+		}
+		v.remove(entryKey(entry))
+		a.addSubEntries(v, source, -1)
+		return result(xdr.ChangeTrustResultCodeChangeTrustSuccess)
+	}
+
+	if op.Limit == 0 {
+		return result(xdr.ChangeTrustResultCodeChangeTrustInvalidLimit)
+	}
+	issuer, ok := v.loadAccount(xdr.MustAddress(asset.GetIssuer()))
+	if !ok {
+		return result(xdr.ChangeTrustResultCodeChangeTrustNoIssuer)
+	}
+	if !a.addSubEntries(v, source, 1) {
+		return result(xdr.ChangeTrustResultCodeChangeTrustLowReserve)
+	}
+	v.store(xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeTrustline,
+		TrustLine: &xdr.TrustLineEntry{
+			AccountId: source,
+			Asset:     lineAsset,
+			Limit:     op.Limit,
+			Flags:     initialTrustLineFlags(issuer),
+		},
+	}})
+	return result(xdr.ChangeTrustResultCodeChangeTrustSuccess)
+}
+
+// changePoolShareTrust creates, updates or deletes a trust line to the shares
+// of a liquidity pool, creating the pool with the first trust line and
+// deleting it with the last one.
+func (a *applier) changePoolShareTrust(v *view, source xdr.AccountId, op xdr.ChangeTrustOp) xdr.ChangeTrustResultCode {
+	params := op.Line.LiquidityPool.MustConstantProduct()
+	if !params.AssetA.LessThan(params.AssetB) || params.Fee != xdr.LiquidityPoolFeeV18 {
+		return xdr.ChangeTrustResultCodeChangeTrustMalformed
+	}
+	poolID, err := xdr.NewPoolId(params.AssetA, params.AssetB, params.Fee)
+	if err != nil {
+		return xdr.ChangeTrustResultCodeChangeTrustMalformed
+	}
+	lineAsset := xdr.TrustLineAsset{Type: xdr.AssetTypeAssetTypePoolShare, LiquidityPoolId: &poolID}
+
+	entry, ok := v.loadTrustLine(source, lineAsset)
+	if ok {
+		line := entry.Data.MustTrustLine()
+		if op.Limit < line.Balance {
+			return xdr.ChangeTrustResultCodeChangeTrustInvalidLimit
+		}
+		if op.Limit > 0 {
+			line.Limit = op.Limit
+			entry.Data.TrustLine = &line
+			v.store(entry)
+			return xdr.ChangeTrustResultCodeChangeTrustSuccess
+		}
+		v.remove(entryKey(entry))
+		a.addSubEntries(v, source, -2)
+		for _, asset := range []xdr.Asset{params.AssetA, params.AssetB} {
+			a.addPoolUse(v, source, asset, -1)
+		}
+		pool, _ := v.load(liquidityPoolKey(poolID))
+		body := pool.Data.MustLiquidityPool().Body.MustConstantProduct()
+		body.PoolSharesTrustLineCount--
+		if body.PoolSharesTrustLineCount == 0 {
+			v.remove(liquidityPoolKey(poolID))
+		} else {
+			pool.Data.LiquidityPool.Body.ConstantProduct = &body
+			v.store(pool)
+		}
+		return xdr.ChangeTrustResultCodeChangeTrustSuccess
+	}
+
+	if op.Limit == 0 {
+		return xdr.ChangeTrustResultCodeChangeTrustInvalidLimit
+	}
+	for _, asset := range []xdr.Asset{params.AssetA, params.AssetB} {
+		if asset.Type == xdr.AssetTypeAssetTypeNative || isIssuer(source, asset) {
+			continue
+		}
+		assetLine, ok := v.loadTrustLine(source, asset.ToTrustLineAsset())
+		if !ok {
+			return xdr.ChangeTrustResultCodeChangeTrustTrustLineMissing
+		}
+		if !trustLineAuthorized(assetLine.Data.MustTrustLine()) {
+			return xdr.ChangeTrustResultCodeChangeTrustNotAuthMaintainLiabilities
+		}
+		a.addPoolUse(v, source, asset, 1)
+	}
+	if !a.addSubEntries(v, source, 2) {
+		return xdr.ChangeTrustResultCodeChangeTrustLowReserve
+	}
+	v.store(xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeTrustline,
+		TrustLine: &xdr.TrustLineEntry{
+			AccountId: source,
+			Asset:     lineAsset,
+			Limit:     op.Limit,
+			Flags:     xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+		},
+	}})
+
+	pool, ok := v.load(liquidityPoolKey(poolID))
+	if !ok {
+		pool = xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeLiquidityPool,
+			LiquidityPool: &xdr.LiquidityPoolEntry{
+				LiquidityPoolId: poolID,
+				Body: xdr.LiquidityPoolEntryBody{
+					Type:            xdr.LiquidityPoolTypeLiquidityPoolConstantProduct,
+					ConstantProduct: &xdr.LiquidityPoolEntryConstantProduct{Params: params},
+				},
+			},
+		}}
+	}
+	pool.Data.LiquidityPool.Body.ConstantProduct.PoolSharesTrustLineCount++
+	v.store(pool)
+	return xdr.ChangeTrustResultCodeChangeTrustSuccess
+}
+
+// addPoolUse changes the number of pool share trust lines using the trust
+// line of the account to the asset.
+func (a *applier) addPoolUse(v *view, account xdr.AccountId, asset xdr.Asset, delta int) {
+	if asset.Type == xdr.AssetTypeAssetTypeNative || isIssuer(account, asset) {
+		return
+	}
+	entry, ok := v.loadTrustLine(account, asset.ToTrustLineAsset())
+	if !ok {
+		return
+	}
+	line := entry.Data.MustTrustLine()
+	ext := trustLineExtV2(&line)
+	ext.LiquidityPoolUseCount = xdr.Int32(int(ext.LiquidityPoolUseCount) + delta)
+	entry.Data.TrustLine = &line
+	v.store(entry)
+}
+
+const allAccountFlags = xdr.AccountFlagsAuthRequiredFlag | xdr.AccountFlagsAuthRevocableFlag |
+	xdr.AccountFlagsAuthImmutableFlag | xdr.AccountFlagsAuthClawbackEnabledFlag
+
+func (a *applier) setOptions(v *view, source xdr.AccountId, op xdr.SetOptionsOp) (xdr.SetOptionsResult, bool) {
+	result := func(code xdr.SetOptionsResultCode) (xdr.SetOptionsResult, bool) {
+		return xdr.SetOptionsResult{Code: code}, code == xdr.SetOptionsResultCodeSetOptionsSuccess
+	}
+
+	account, _ := v.loadAccount(source)
+	if op.InflationDest != nil {
+		if _, ok := v.loadAccount(*op.InflationDest); !ok {
+			return result(xdr.SetOptionsResultCodeSetOptionsInvalidInflation)
+		}
+		dest := *op.InflationDest
+		account.InflationDest = &dest
+	}
+
+	flags := xdr.AccountFlags(account.Flags)
+	if op.SetFlags != nil || op.ClearFlags != nil {
+		var set, clear xdr.AccountFlags
+		if op.SetFlags != nil {
+			set = xdr.AccountFlags(*op.SetFlags)
+		}
+		if op.ClearFlags != nil {
+			clear = xdr.AccountFlags(*op.ClearFlags)
+		}
+		if (set|clear)&^allAccountFlags != 0 {
+			return result(xdr.SetOptionsResultCodeSetOptionsUnknownFlag)
+		}
+		if set&clear != 0 {
+			return result(xdr.SetOptionsResultCodeSetOptionsBadFlags)
+		}
+		if flags.IsAuthImmutable() {
+			return result(xdr.SetOptionsResultCodeSetOptionsCantChange)
+		}
+		flags = flags&^clear | set
+		if flags.IsAuthClawbackEnabled() && !flags.IsAuthRevocable() {
+			return result(xdr.SetOptionsResultCodeSetOptionsAuthRevocableRequired)
+		}
+		account.Flags = xdr.Uint32(flags)
+	}
+
+	for i, threshold := range []*xdr.Uint32{op.MasterWeight, op.LowThreshold, op.MedThreshold, op.HighThreshold} {
+		if threshold == nil {
+			continue
+		}
+		if *threshold > 255 {
+			return result(xdr.SetOptionsResultCodeSetOptionsThresholdOutOfRange)
+		}
+		account.Thresholds[i] = byte(*threshold)
+	}
+
+	if op.HomeDomain != nil {
+		account.HomeDomain = *op.HomeDomain
+	}
+
+	if op.Signer != nil {
+		code := a.setSigner(v, &account, *op.Signer)
+		if code != xdr.SetOptionsResultCodeSetOptionsSuccess {
+			return result(code)
+		}
+	}
+
+	v.storeAccount(account)
+	return result(xdr.SetOptionsResultCodeSetOptionsSuccess)
+}
+
+// setSigner adds, updates or, if its weight is 0, removes a signer of the
+// account. Signers are kept sorted by key like hcnet-core does.
+func (a *applier) setSigner(v *view, account *xdr.AccountEntry, signer xdr.Signer) xdr.SetOptionsResultCode {
+	if signer.Weight > 255 {
+		return xdr.SetOptionsResultCodeSetOptionsBadSigner
+	}
+	if signer.Key.Type == xdr.SignerKeyTypeSignerKeyTypeEd25519 && signer.Key.Address() == account.AccountId.Address() {
+		return xdr.SetOptionsResultCodeSetOptionsBadSigner
+	}
+
+	var sponsors []xdr.SponsorshipDescriptor
+	if account.Ext.V == 1 && account.Ext.V1.Ext.V == 2 {
+		sponsors = account.Ext.V1.Ext.V2.SignerSponsoringIDs
+	}
+	for i := range account.Signers {
+		if !account.Signers[i].Key.Equals(signer.Key) {
+			continue
+		}
+		if signer.Weight > 0 {
+			account.Signers[i].Weight = signer.Weight
+			return xdr.SetOptionsResultCodeSetOptionsSuccess
+		}
+		account.Signers = append(account.Signers[:i], account.Signers[i+1:]...)
+		if sponsors != nil {
+			account.Ext.V1.Ext.V2.SignerSponsoringIDs = append(sponsors[:i], sponsors[i+1:]...)
+		}
+		account.NumSubEntries--
+		return xdr.SetOptionsResultCodeSetOptionsSuccess
+	}
+
+	if signer.Weight == 0 {
+		return xdr.SetOptionsResultCodeSetOptionsSuccess
+	}
+	if len(account.Signers) >= xdr.MaxSigners {
+		return xdr.SetOptionsResultCodeSetOptionsTooManySigners
+	}
+	account.NumSubEntries++
+	if availableBalance(*account, a.baseReserve()) < 0 {
+		return xdr.SetOptionsResultCodeSetOptionsLowReserve
+	}
+
+	key := signerKeyBytes(signer.Key)
+	i := sort.Search(len(account.Signers), func(i int) bool {
+		return bytes.Compare(signerKeyBytes(account.Signers[i].Key), key) > 0
+	})
+	account.Signers = append(account.Signers, xdr.Signer{})
+	copy(account.Signers[i+1:], account.Signers[i:])
+	account.Signers[i] = signer
+	if sponsors != nil {
+		sponsors = append(sponsors, nil)
+		copy(sponsors[i+1:], sponsors[i:])
+		sponsors[i] = nil
+		account.Ext.V1.Ext.V2.SignerSponsoringIDs = sponsors
+	}
+	return xdr.SetOptionsResultCodeSetOptionsSuccess
+}
+
+func signerKeyBytes(key xdr.SignerKey) []byte {
+	b, err := key.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (a *applier) bumpSequence(v *view, source xdr.AccountId, op xdr.BumpSequenceOp) (xdr.BumpSequenceResult, bool) {
+	if op.BumpTo < 0 {
+		return xdr.BumpSequenceResult{Code: xdr.BumpSequenceResultCodeBumpSequenceBadSeq}, false
+	}
+	account, _ := v.loadAccount(source)
+	seq := account.SeqNum
+	if op.BumpTo > seq {
+		seq = op.BumpTo
+	}
+	a.setSequence(&account, seq)
+	v.storeAccount(account)
+	return xdr.BumpSequenceResult{Code: xdr.BumpSequenceResultCodeBumpSequenceSuccess}, true
+}
@@ -0,0 +1,171 @@
+package simulator
+
+import (
+	"sort"
+
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// view is a layer of ledger state. The ledger state of the network is a view
+// without parent, transactions and operations are applied to child views which
+// are committed into their parent when they succeed or dropped when they fail.
+// A view records the order in which entries were changed so that the changes
+// can be turned into deterministic LedgerEntryChanges.
+type view struct {
+	parent    *view
+	ledgerSeq uint32
+	// entries holds the entries changed in this view, keyed by the binary
+	// encoding of their ledger key. A nil entry has been removed.
+	entries map[string]*xdr.LedgerEntry
+	order   []string
+}
+
+func newView(parent *view) *view {
+	v := &view{parent: parent, entries: map[string]*xdr.LedgerEntry{}}
+	if parent != nil {
+		v.ledgerSeq = parent.ledgerSeq
+	}
+	return v
+}
+
+func keyString(key xdr.LedgerKey) string {
+	b, err := key.MarshalBinary()
+	if err != nil {
+		panic(errors.Wrap(err, "could not marshal ledger key"))
+	}
+	return string(b)
+}
+
+func entryKey(entry xdr.LedgerEntry) xdr.LedgerKey {
+	key, err := entry.LedgerKey()
+	if err != nil {
+		panic(errors.Wrap(err, "could not get ledger key"))
+	}
+	return key
+}
+
+// copyEntry returns a deep copy of the entry so that changing the copy does
+// not change the pointers it shares with the stored entry.
+func copyEntry(entry xdr.LedgerEntry) xdr.LedgerEntry {
+	b, err := entry.MarshalBinary()
+	if err != nil {
+		panic(errors.Wrap(err, "could not marshal ledger entry"))
+	}
+	var copied xdr.LedgerEntry
+	if err := copied.UnmarshalBinary(b); err != nil {
+		panic(errors.Wrap(err, "could not unmarshal ledger entry"))
+	}
+	return copied
+}
+
+func (v *view) lookup(key string) *xdr.LedgerEntry {
+	for current := v; current != nil; current = current.parent {
+		if entry, ok := current.entries[key]; ok {
+			return entry
+		}
+	}
+	return nil
+}
+
+// load returns a copy of the entry with the given key.
+func (v *view) load(key xdr.LedgerKey) (xdr.LedgerEntry, bool) {
+	entry := v.lookup(keyString(key))
+	if entry == nil {
+		return xdr.LedgerEntry{}, false
+	}
+	return copyEntry(*entry), true
+}
+
+func (v *view) set(key string, entry *xdr.LedgerEntry) {
+	if _, ok := v.entries[key]; !ok && v.parent != nil {
+		v.order = append(v.order, key)
+	}
+	v.entries[key] = entry
+}
+
+// store creates or updates the entry.
+func (v *view) store(entry xdr.LedgerEntry) {
+	entry = copyEntry(entry)
+	entry.LastModifiedLedgerSeq = xdr.Uint32(v.ledgerSeq)
+	v.set(keyString(entryKey(entry)), &entry)
+}
+
+// remove deletes the entry with the given key.
+func (v *view) remove(key xdr.LedgerKey) {
+	v.set(keyString(key), nil)
+}
+
+// all returns the entries of the given type sorted by ledger key.
+func (v *view) all(typ xdr.LedgerEntryType) []xdr.LedgerEntry {
+	seen := map[string]bool{}
+	var keys []string
+	for current := v; current != nil; current = current.parent {
+		for key, entry := range current.entries {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if entry != nil && entry.Data.Type == typ {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	entries := make([]xdr.LedgerEntry, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, copyEntry(*v.lookup(key)))
+	}
+	return entries
+}
+
+// changes returns the changes of this view relative to its parent.
+func (v *view) changes() xdr.LedgerEntryChanges {
+	changes := xdr.LedgerEntryChanges{}
+	for _, key := range v.order {
+		after := v.entries[key]
+		var before *xdr.LedgerEntry
+		if v.parent != nil {
+			before = v.parent.lookup(key)
+		}
+
+		switch {
+		case before == nil && after == nil:
+		case before == nil:
+			changes = append(changes, xdr.LedgerEntryChange{
+				Type:    xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+				Created: after,
+			})
+		case after == nil:
+			state := copyEntry(*before)
+			key := entryKey(state)
+			changes = append(changes,
+				xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &state},
+				xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &key},
+			)
+		default:
+			state := copyEntry(*before)
+			changes = append(changes,
+				xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &state},
+				xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: after},
+			)
+		}
+	}
+	return changes
+}
+
+// commit applies the changes of this view to its parent.
+func (v *view) commit() {
+	for _, key := range v.order {
+		entry := v.entries[key]
+		if v.parent.parent == nil && entry == nil {
+			// the ledger state does not keep removed entries
+			delete(v.parent.entries, key)
+			continue
+		}
+		v.parent.set(key, entry)
+	}
+	v.entries = map[string]*xdr.LedgerEntry{}
+	v.order = nil
+}
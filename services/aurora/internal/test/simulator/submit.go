@@ -0,0 +1,137 @@
+package simulator
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	proto "github.com/hcnet/go/protocols/hcnetcore"
+	"github.com/hcnet/go/services/aurora/internal/txsub"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// Submit validates the transaction and, if it is valid, adds it to the
+// transactions applied when the next ledger closes. It implements
+// txsub.Submitter.
+func (n *Network) Submit(ctx context.Context, rawTx string) txsub.SubmissionResult {
+	start := time.Now()
+	response := n.submit(rawTx)
+	result := txsub.SubmissionResult{Status: response.Status}
+	switch {
+	case response.IsException():
+		result.Err = errors.Errorf("hcnet-core exception: %s", response.Exception)
+	case response.Status == proto.TXStatusError:
+		result.Err = &txsub.FailedTransactionError{ResultXDR: response.Error}
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+func (n *Network) submit(rawTx string) proto.TXResponse {
+	var envelope xdr.TransactionEnvelope
+	if err := xdr.SafeUnmarshalBase64(rawTx, &envelope); err != nil {
+		return proto.TXResponse{Exception: "invalid transaction envelope"}
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	tx, err := newPendingTransaction(envelope, n.config.NetworkPassphrase)
+	if err != nil {
+		return proto.TXResponse{Exception: err.Error()}
+	}
+	if n.pendingHashes[tx.hash] {
+		return proto.TXResponse{Status: proto.TXStatusDuplicate}
+	}
+
+	source := envelope.SourceAccount().ToAccountId()
+	closeTime := n.closeTime(uint32(n.latest.Header.LedgerSeq) + 1).Unix()
+	if code := checkPreconditions(n.state, envelope, closeTime, n.pendingSeqs[source.Address()]); code != xdr.TransactionResultCodeTxSuccess {
+		return n.rejected(tx, code, true)
+	}
+	if maxFee(envelope) < n.minFee(envelope) {
+		return n.rejected(tx, xdr.TransactionResultCodeTxInsufficientFee, false)
+	}
+	account, ok := n.state.loadAccount(feeSource(envelope))
+	if !ok {
+		return n.rejected(tx, xdr.TransactionResultCodeTxNoAccount, false)
+	}
+	if availableBalance(account, n.config.BaseReserve) < xdr.Int64(n.minFee(envelope)) {
+		return n.rejected(tx, xdr.TransactionResultCodeTxInsufficientBalance, false)
+	}
+	if !authorized(n.state, tx) {
+		return n.rejected(tx, xdr.TransactionResultCodeTxBadAuth, true)
+	}
+
+	n.pending = append(n.pending, tx)
+	n.pendingHashes[tx.hash] = true
+	n.pendingSeqs[source.Address()] = envelope.SeqNum()
+	return proto.TXResponse{Status: proto.TXStatusPending}
+}
+
+// rejected returns the error response of a transaction which failed
+// validation. inner is true if the code applies to the inner transaction of
+// a fee bump transaction.
+func (n *Network) rejected(tx pendingTransaction, code xdr.TransactionResultCode, inner bool) proto.TXResponse {
+	result := xdr.TransactionResult{
+		FeeCharged: xdr.Int64(n.minFee(tx.envelope)),
+		Result:     xdr.TransactionResultResult{Code: code},
+	}
+	if inner && tx.envelope.IsFeeBump() {
+		result.Result = xdr.TransactionResultResult{
+			Code: xdr.TransactionResultCodeTxFeeBumpInnerFailed,
+			InnerResultPair: &xdr.InnerTransactionResultPair{
+				TransactionHash: tx.innerHash,
+				Result: xdr.InnerTransactionResult{
+					Result: xdr.InnerTransactionResultResult{Code: code},
+				},
+			},
+		}
+	}
+	resultXDR, err := xdr.MarshalBase64(result)
+	if err != nil {
+		return proto.TXResponse{Exception: err.Error()}
+	}
+	return proto.TXResponse{Status: proto.TXStatusError, Error: resultXDR}
+}
+
+// ServeHTTP serves the /info and /tx endpoints of the hcnet-core http
+// interface.
+func (n *Network) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var response interface{}
+	switch r.URL.Path {
+	case "/info":
+		response = n.info()
+	case "/tx":
+		response = n.submit(r.URL.Query().Get("blob"))
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (n *Network) info() proto.InfoResponse {
+	latest := n.LatestLedger()
+	var response proto.InfoResponse
+	response.Info.Build = "aurora network simulator"
+	response.Info.Network = n.config.NetworkPassphrase
+	response.Info.ProtocolVersion = int(latest.Header.LedgerVersion)
+	response.Info.State = "Synced!"
+	response.Info.Ledger = proto.LedgerInfo{
+		BaseFee:      int(latest.Header.BaseFee),
+		BaseReserve:  int(latest.Header.BaseReserve),
+		CloseTime:    int(latest.Header.ScpValue.CloseTime),
+		Hash:         hex.EncodeToString(latest.Hash[:]),
+		MaxTxSetSize: int(latest.Header.MaxTxSetSize),
+		Num:          int(latest.Header.LedgerSeq),
+		Version:      int(latest.Header.LedgerVersion),
+	}
+	return response
+}
@@ -65,6 +65,11 @@ type SubmissionResult struct {
 	// inclusion in the ledger (i.e. A successful submission).
 	Err error
 
+	// Status is the status hcnet-core responded with (PENDING, DUPLICATE,
+	// ERROR or TRY_AGAIN_LATER). It is empty if hcnet-core could not be
+	// reached or responded with an exception.
+	Status string
+
 	// Duration records the time it took to submit a transaction
 	// to hcnet-core
 	Duration time.Duration
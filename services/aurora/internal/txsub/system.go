@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	proto "github.com/hcnet/go/protocols/hcnetcore"
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/support/log"
 	"github.com/hcnet/go/xdr"
@@ -169,6 +170,61 @@ func (sys *System) Submit(
 	return
 }
 
+// SubmitAsync submits the provided base64 encoded transaction envelope to
+// hcnet-core without waiting for the transaction to be included in a ledger.
+// The returned SubmissionResult contains the status hcnet-core responded
+// with. Transactions accepted by hcnet-core are added to the open
+// submissions so that their result is sent to the listeners registered with
+// Listen once they are ingested.
+func (sys *System) SubmitAsync(
+	ctx context.Context,
+	rawTx string,
+	envelope xdr.TransactionEnvelope,
+	hash string,
+) SubmissionResult {
+	sys.Init()
+
+	sys.Log.Ctx(ctx).WithFields(log.F{
+		"hash":    hash,
+		"tx_type": envelope.Type.String(),
+		"tx":      rawTx,
+	}).Info("Processing asynchronous transaction")
+
+	seqNum := envelope.SeqNum()
+	minSeqNum := envelope.MinSeqNum()
+	if seqNum < 0 || (minSeqNum != nil && (*minSeqNum < 0 || *minSeqNum >= seqNum)) {
+		return SubmissionResult{Err: ErrBadSequence}
+	}
+
+	sr := sys.submitOnce(ctx, rawTx)
+	sys.updateTransactionTypeMetrics(envelope)
+
+	if sr.Err == nil && (sr.Status == proto.TXStatusPending || sr.Status == proto.TXStatusDuplicate) {
+		// The listener keeps the submission open until its result is ingested
+		// or it times out, nobody reads from it.
+		sys.Pending.Add(hash, make(chan Result, 1))
+	}
+	return sr
+}
+
+// Listen returns a channel which receives the result of the transaction with
+// the given hash once it is ingested, or ErrTimeout if it is not ingested
+// within the submission timeout. The result is sent right away if the
+// transaction is already in the history database.
+func (sys *System) Listen(ctx context.Context, hash string) <-chan Result {
+	sys.Init()
+	resultCh := make(chan Result, 1)
+
+	tx, err := txResultByHash(ctx, sys.DB(ctx), hash)
+	if err != ErrNoResults {
+		sys.finish(ctx, hash, resultCh, Result{Transaction: tx, Err: err})
+		return resultCh
+	}
+
+	sys.Pending.Add(hash, resultCh)
+	return resultCh
+}
+
 // waitUntilAccountSequence blocks until either the context times out or the sequence number of the
 // given source account is greater than or equal to `seq`
 func (sys *System) waitUntilAccountSequence(ctx context.Context, db AuroraDB, sourceAddress string, seq uint64) error {
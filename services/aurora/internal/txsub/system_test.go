@@ -318,6 +318,89 @@ func (suite *SystemTestSuite) TestSubmit_OpenTransactionList() {
 	assert.Equal(suite.T(), uint64(1), getMetricValue(suite.system.Metrics.SubmissionDuration).GetSummary().GetSampleCount())
 }
 
+// Asynchronous submissions accepted by hcnet-core are added to the open
+// transaction list without waiting for their result.
+func (suite *SystemTestSuite) TestSubmitAsync_Pending() {
+	suite.submitter.R.Status = "PENDING"
+	sr := suite.system.SubmitAsync(
+		suite.ctx,
+		suite.successTx.Transaction.TxEnvelope,
+		suite.successXDR,
+		suite.successTx.Transaction.TransactionHash,
+	)
+
+	assert.NoError(suite.T(), sr.Err)
+	assert.Equal(suite.T(), "PENDING", sr.Status)
+	assert.True(suite.T(), suite.submitter.WasSubmittedTo)
+	assert.Equal(suite.T(), []string{suite.successTx.Transaction.TransactionHash}, suite.system.Pending.Pending())
+	assert.Equal(suite.T(), float64(1), getMetricValue(suite.system.Metrics.V1TransactionsCounter).GetCounter().GetValue())
+}
+
+func (suite *SystemTestSuite) TestSubmitAsync_TryAgainLater() {
+	suite.submitter.R.Status = "TRY_AGAIN_LATER"
+	sr := suite.system.SubmitAsync(
+		suite.ctx,
+		suite.successTx.Transaction.TxEnvelope,
+		suite.successXDR,
+		suite.successTx.Transaction.TransactionHash,
+	)
+
+	assert.NoError(suite.T(), sr.Err)
+	assert.Equal(suite.T(), "TRY_AGAIN_LATER", sr.Status)
+	assert.Empty(suite.T(), suite.system.Pending.Pending())
+}
+
+func (suite *SystemTestSuite) TestSubmitAsync_Error() {
+	suite.submitter.R = SubmissionResult{Status: "ERROR", Err: &FailedTransactionError{ResultXDR: "AAAA"}}
+	sr := suite.system.SubmitAsync(
+		suite.ctx,
+		suite.successTx.Transaction.TxEnvelope,
+		suite.successXDR,
+		suite.successTx.Transaction.TransactionHash,
+	)
+
+	assert.Equal(suite.T(), suite.submitter.R, sr)
+	assert.Empty(suite.T(), suite.system.Pending.Pending())
+	assert.Equal(suite.T(), float64(1), getMetricValue(suite.system.Metrics.FailedSubmissionsCounter).GetCounter().GetValue())
+}
+
+// Listen sends the result right away if the transaction is already ingested.
+func (suite *SystemTestSuite) TestListen_Ingested() {
+	suite.db.On("PreFilteredTransactionByHash", suite.ctx, mock.Anything, suite.successTx.Transaction.TransactionHash).
+		Run(func(args mock.Arguments) {
+			ptr := args.Get(1).(*history.Transaction)
+			*ptr = suite.successTx.Transaction
+		}).
+		Return(nil).Once()
+
+	r := <-suite.system.Listen(suite.ctx, suite.successTx.Transaction.TransactionHash)
+	assert.Equal(suite.T(), suite.successTx, r)
+	assert.Empty(suite.T(), suite.system.Pending.Pending())
+}
+
+// Listen waits for the next tick finding the transaction.
+func (suite *SystemTestSuite) TestListen_Pending() {
+	suite.db.On("PreFilteredTransactionByHash", suite.ctx, mock.Anything, suite.successTx.Transaction.TransactionHash).
+		Return(sql.ErrNoRows).Once()
+	suite.db.On("TransactionByHash", suite.ctx, mock.Anything, suite.successTx.Transaction.TransactionHash).
+		Return(sql.ErrNoRows).Once()
+	suite.db.On("NoRows", sql.ErrNoRows).Return(true).Twice()
+
+	l := suite.system.Listen(suite.ctx, suite.successTx.Transaction.TransactionHash)
+	assert.Equal(suite.T(), []string{suite.successTx.Transaction.TransactionHash}, suite.system.Pending.Pending())
+
+	suite.db.On("BeginTx", mock.AnythingOfType("*context.valueCtx"), &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	}).Return(nil).Once()
+	suite.db.On("Rollback").Return(nil).Once()
+	suite.db.On("AllTransactionsByHashesSinceLedger", suite.ctx, []string{suite.successTx.Transaction.TransactionHash}, uint32(940)).
+		Return([]history.Transaction{suite.successTx.Transaction}, nil).Once()
+	suite.system.Tick(suite.ctx)
+
+	assert.Equal(suite.T(), suite.successTx, <-l)
+}
+
 // Tick should be a no-op if there are no open submissions.
 func (suite *SystemTestSuite) TestTick_Noop() {
 	suite.db.On("BeginTx", mock.AnythingOfType("*context.valueCtx"), &sql.TxOptions{
@@ -0,0 +1,160 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+)
+
+const (
+	// DeliveryIDHeader contains the id of the delivery. It is the same for all
+	// the attempts of a delivery.
+	DeliveryIDHeader = "X-Aurora-Webhook-Id"
+	// TimestampHeader contains the unix time at which the request was signed.
+	TimestampHeader = "X-Aurora-Webhook-Timestamp"
+	// SignatureHeader contains the signature of the request, see Sign.
+	SignatureHeader = "X-Aurora-Webhook-Signature"
+
+	// deliveryConcurrency is the maximum number of concurrent requests.
+	deliveryConcurrency = 10
+)
+
+// Sign returns the value of the SignatureHeader of a request: the hex encoded
+// HMAC-SHA256, keyed with the subscription secret, of the timestamp followed by
+// a dot and the request body. Receivers should compute the signature of every
+// request and compare it with the header using a constant time comparison.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver attempts the due deliveries. Deliveries are leased for long enough
+// to be attempted, so they are not picked up by other aurora instances in the
+// meantime.
+func (s *System) deliver(ctx context.Context) error {
+	subscriptions, err := s.webhooksQ.GetWebhookSubscriptions(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not load webhook subscriptions")
+	}
+	subscriptionsByID := map[int64]history.WebhookSubscription{}
+	for _, subscription := range subscriptions {
+		subscriptionsByID[subscription.ID] = subscription
+	}
+
+	lease := s.config.Timeout * time.Duration(deliveryBatchSize/deliveryConcurrency+1)
+	deliveries, err := s.webhooksQ.ClaimWebhookDeliveries(ctx, s.now().UTC(), lease, deliveryBatchSize)
+	if err != nil {
+		return errors.Wrap(err, "could not claim webhook deliveries")
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, deliveryConcurrency)
+	for _, delivery := range deliveries {
+		subscription, ok := subscriptionsByID[delivery.SubscriptionID]
+		if !ok {
+			// the subscription was removed after the deliveries were loaded
+			continue
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(subscription history.WebhookSubscription, delivery history.WebhookDelivery) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			if err := s.attempt(ctx, subscription, delivery); err != nil {
+				log.WithField("delivery_id", delivery.ID).
+					WithError(err).
+					Error("could not update webhook delivery")
+			}
+		}(subscription, delivery)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// attempt sends a delivery and records its outcome.
+func (s *System) attempt(ctx context.Context, subscription history.WebhookSubscription, delivery history.WebhookDelivery) error {
+	postErr := s.post(ctx, subscription, delivery)
+	if postErr == nil {
+		return s.webhooksQ.DeleteWebhookDelivery(ctx, delivery.ID)
+	}
+	if ctx.Err() != nil {
+		// aurora is shutting down, the delivery will be attempted again once
+		// the lease expires
+		return nil
+	}
+
+	now := s.now().UTC()
+	delivery.Attempts++
+	delivery.LastError = postErr.Error()
+	logger := log.WithField("delivery_id", delivery.ID).
+		WithField("subscription_id", subscription.ID).
+		WithField("attempts", delivery.Attempts).
+		WithError(postErr)
+
+	if delivery.Attempts >= s.config.MaxAttempts {
+		logger.Warn("webhook delivery failed, moving it to the dead letter table")
+		return s.webhooksQ.DeadLetterWebhookDelivery(ctx, delivery, subscription.URL, now)
+	}
+
+	logger.Info("webhook delivery failed, retrying later")
+	return s.webhooksQ.RescheduleWebhookDelivery(
+		ctx, delivery.ID, delivery.Attempts, now.Add(s.backoff(delivery.Attempts)), delivery.LastError,
+	)
+}
+
+// backoff returns the delay before the next attempt of a delivery which failed
+// the given number of times.
+func (s *System) backoff(attempts int32) time.Duration {
+	delay := s.config.InitialBackoff
+	for i := int32(1); i < attempts && delay < s.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.config.MaxBackoff {
+		delay = s.config.MaxBackoff
+	}
+	return delay
+}
+
+func (s *System) post(ctx context.Context, subscription history.WebhookSubscription, delivery history.WebhookDelivery) error {
+	body := []byte(delivery.Payload)
+	timestamp := s.now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not build request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryIDHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "request failed")
+	}
+	defer resp.Body.Close()
+	// drain the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+)
+
+func newTestSystem(q history.QWebhooks, now time.Time) *System {
+	config := DefaultConfig
+	config.MaxAttempts = 3
+	return &System{
+		webhooksQ: q,
+		client:    &http.Client{Timeout: time.Second},
+		config:    config,
+		now:       func() time.Time { return now },
+	}
+}
+
+func TestSign(t *testing.T) {
+	assert.Equal(
+		t,
+		"v1=7bcf59a8626312d93fe03616a46ed91ae307544dbf5ffaa3891442ce20878bff",
+		Sign("secret", 1672531200, []byte(`{"ledger":10}`)),
+	)
+	assert.NotEqual(t, Sign("secret", 1672531200, []byte(`{"ledger":10}`)), Sign("other", 1672531200, []byte(`{"ledger":10}`)))
+	assert.NotEqual(t, Sign("secret", 1672531200, []byte(`{"ledger":10}`)), Sign("secret", 1672531201, []byte(`{"ledger":10}`)))
+}
+
+func TestBackoff(t *testing.T) {
+	s := newTestSystem(nil, time.Now())
+	s.config.InitialBackoff = time.Second
+	s.config.MaxBackoff = 10 * time.Second
+
+	assert.Equal(t, time.Second, s.backoff(1))
+	assert.Equal(t, 2*time.Second, s.backoff(2))
+	assert.Equal(t, 8*time.Second, s.backoff(4))
+	assert.Equal(t, 10*time.Second, s.backoff(5))
+	assert.Equal(t, 10*time.Second, s.backoff(100))
+}
+
+func TestDeliverSuccess(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	subscription := history.WebhookSubscription{ID: 1, URL: server.URL, Secret: "secret"}
+	delivery := history.WebhookDelivery{ID: 7, SubscriptionID: 1, LedgerSequence: 10, Payload: `{"ledger":10}`}
+
+	q := &history.MockQWebhooks{}
+	q.On("GetWebhookSubscriptions", ctx).Return([]history.WebhookSubscription{subscription}, nil).Once()
+	q.On("ClaimWebhookDeliveries", ctx, now, mock.Anything, deliveryBatchSize).
+		Return([]history.WebhookDelivery{delivery}, nil).Once()
+	q.On("DeleteWebhookDelivery", ctx, int64(7)).Return(nil).Once()
+
+	require.NoError(t, newTestSystem(q, now).deliver(ctx))
+	q.AssertExpectations(t)
+
+	require.NotNil(t, received)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "7", received.Header.Get(DeliveryIDHeader))
+	assert.Equal(t, strconv.FormatInt(now.Unix(), 10), received.Header.Get(TimestampHeader))
+	assert.Equal(t, Sign("secret", now.Unix(), body), received.Header.Get(SignatureHeader))
+	assert.Equal(t, `{"ledger":10}`, string(body))
+}
+
+func TestDeliverFailures(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	subscription := history.WebhookSubscription{ID: 1, URL: server.URL, Secret: "secret"}
+	retried := history.WebhookDelivery{ID: 7, SubscriptionID: 1, LedgerSequence: 10, Payload: `{}`, Attempts: 1}
+	exhausted := history.WebhookDelivery{ID: 8, SubscriptionID: 1, LedgerSequence: 11, Payload: `{}`, Attempts: 2}
+	orphaned := history.WebhookDelivery{ID: 9, SubscriptionID: 2, LedgerSequence: 11, Payload: `{}`}
+
+	q := &history.MockQWebhooks{}
+	q.On("GetWebhookSubscriptions", ctx).Return([]history.WebhookSubscription{subscription}, nil).Once()
+	q.On("ClaimWebhookDeliveries", ctx, now, mock.Anything, deliveryBatchSize).
+		Return([]history.WebhookDelivery{retried, exhausted, orphaned}, nil).Once()
+
+	s := newTestSystem(q, now)
+	q.On("RescheduleWebhookDelivery", ctx, int64(7), int32(2), now.Add(s.backoff(2)), "unexpected status code 500").
+		Return(nil).Once()
+	deadLetter := exhausted
+	deadLetter.Attempts = 3
+	deadLetter.LastError = "unexpected status code 500"
+	q.On("DeadLetterWebhookDelivery", ctx, deadLetter, server.URL, now).Return(nil).Once()
+
+	require.NoError(t, s.deliver(ctx))
+	q.AssertExpectations(t)
+}
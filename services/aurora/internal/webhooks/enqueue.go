@@ -0,0 +1,189 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/resourceadapter"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/hal"
+)
+
+// Payload is the JSON document POSTed to the url of a subscription. It
+// contains the operations and effects of a single ledger which match the
+// subscription, rendered like in the aurora API. A payload can be delivered
+// more than once, receivers can use the subscription id and ledger sequence to
+// detect duplicates.
+type Payload struct {
+	SubscriptionID int64          `json:"subscription_id"`
+	Ledger         int32          `json:"ledger"`
+	LedgerClosedAt time.Time      `json:"ledger_closed_at"`
+	Operations     []hal.Pageable `json:"operations"`
+	Effects        []hal.Pageable `json:"effects"`
+}
+
+// enqueue stores the deliveries of the ledgers ingested since the last run.
+// The webhooks cursor is locked for the duration of the transaction so
+// deliveries are enqueued exactly once when several aurora instances share a
+// database.
+func (s *System) enqueue(ctx context.Context) error {
+	status := s.ledgerState.CurrentStatus()
+	if status.HistoryLatest <= 0 {
+		return nil
+	}
+
+	q := &history.Q{s.historyQ.Clone()}
+	if err := q.Begin(ctx); err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer q.Rollback()
+
+	last, err := q.GetLastLedgerWebhooks(ctx, true)
+	if err != nil {
+		return errors.Wrap(err, "could not load webhooks cursor")
+	}
+
+	if last == 0 {
+		// Start from the ledgers ingested after the first run instead of
+		// sending the entire history.
+		if err = q.UpdateLastLedgerWebhooks(ctx, uint32(status.HistoryLatest)); err != nil {
+			return errors.Wrap(err, "could not update webhooks cursor")
+		}
+		return q.Commit()
+	}
+
+	from := int32(last) + 1
+	// ledgers older than the elder ledger have been reaped
+	if from < status.HistoryElder {
+		from = status.HistoryElder
+	}
+	to := status.HistoryLatest
+	if to-from >= maxLedgersPerRun {
+		to = from + maxLedgersPerRun - 1
+	}
+	if from > to {
+		return nil
+	}
+
+	subscriptions, err := q.GetWebhookSubscriptions(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not load webhook subscriptions")
+	}
+
+	var deliveries []history.WebhookDelivery
+	if len(subscriptions) > 0 {
+		for sequence := from; sequence <= to; sequence++ {
+			ledgerDeliveries, err := s.ledgerDeliveries(ctx, q, subscriptions, sequence)
+			if err != nil {
+				return errors.Wrapf(err, "could not build deliveries for ledger %d", sequence)
+			}
+			deliveries = append(deliveries, ledgerDeliveries...)
+		}
+	}
+
+	if err = q.InsertWebhookDeliveries(ctx, deliveries); err != nil {
+		return errors.Wrap(err, "could not insert webhook deliveries")
+	}
+	if err = q.UpdateLastLedgerWebhooks(ctx, uint32(to)); err != nil {
+		return errors.Wrap(err, "could not update webhooks cursor")
+	}
+	return q.Commit()
+}
+
+func (s *System) ledgerDeliveries(
+	ctx context.Context,
+	q *history.Q,
+	subscriptions []history.WebhookSubscription,
+	sequence int32,
+) ([]history.WebhookDelivery, error) {
+	var ledger history.Ledger
+	if err := q.LedgerBySequence(ctx, &ledger, sequence); err != nil {
+		return nil, errors.Wrap(err, "could not load ledger")
+	}
+
+	operations, transactions, err := q.Operations().
+		IncludeTransactions().
+		ForLedger(ctx, sequence).
+		Fetch(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load operations")
+	}
+
+	operationIDs := make([]int64, 0, len(operations))
+	for _, operation := range operations {
+		operationIDs = append(operationIDs, operation.ID)
+	}
+	participants, err := q.OperationParticipantsByIDs(ctx, operationIDs...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load operation participants")
+	}
+
+	var effects []history.Effect
+	if err = q.Effects().ForLedger(ctx, sequence).Select(ctx, &effects); err != nil {
+		return nil, errors.Wrap(err, "could not load effects")
+	}
+
+	matches, err := matchLedger(subscriptions, operations, participants, effects)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	transactionsByID := map[int64]*history.Transaction{}
+	for i := range transactions {
+		transactionsByID[transactions[i].ID] = &transactions[i]
+	}
+
+	now := s.now().UTC()
+	var deliveries []history.WebhookDelivery
+	for _, subscription := range subscriptions {
+		m, ok := matches[subscription.ID]
+		if !ok {
+			continue
+		}
+
+		payload := Payload{
+			SubscriptionID: subscription.ID,
+			Ledger:         sequence,
+			LedgerClosedAt: ledger.ClosedAt,
+			Operations:     []hal.Pageable{},
+			Effects:        []hal.Pageable{},
+		}
+		for _, i := range m.operations {
+			operation := operations[i]
+			resource, err := resourceadapter.NewOperation(
+				ctx, operation, operation.TransactionHash, transactionsByID[operation.TransactionID], ledger,
+			)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not render operation %d", operation.ID)
+			}
+			payload.Operations = append(payload.Operations, resource)
+		}
+		for _, i := range m.effects {
+			resource, err := resourceadapter.NewEffect(ctx, effects[i], ledger)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not render effect %s", effects[i].PagingToken())
+			}
+			payload.Effects = append(payload.Effects, resource)
+		}
+
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not encode payload")
+		}
+
+		deliveries = append(deliveries, history.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			LedgerSequence: uint32(sequence),
+			Payload:        string(encoded),
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,139 @@
+// Package webhooks contains the webhook delivery subsystem for aurora. After
+// each ingested ledger the operations and effects matching the registered
+// subscriptions are enqueued in the aurora database and POSTed, as signed JSON
+// documents, to the url of every matching subscription. Failed deliveries are
+// retried with an exponential backoff and moved to a dead letter table once
+// the maximum number of attempts is reached.
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	herrors "github.com/hcnet/go/services/aurora/internal/errors"
+	"github.com/hcnet/go/services/aurora/internal/ledger"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/log"
+)
+
+const (
+	// maxLedgersPerRun limits the number of ledgers for which deliveries are
+	// enqueued in a single run, so that a large backlog is processed in
+	// several smaller transactions.
+	maxLedgersPerRun = 100
+	// deliveryBatchSize is the maximum number of deliveries attempted in a
+	// single run.
+	deliveryBatchSize = 100
+)
+
+// Config configures the webhook subsystem.
+type Config struct {
+	// MaxAttempts is the number of failed attempts after which a delivery is
+	// moved to the dead letter table.
+	MaxAttempts int32
+	// InitialBackoff is the delay before the second attempt of a delivery.
+	// The delay doubles with every failed attempt up to MaxBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between two attempts of a delivery.
+	MaxBackoff time.Duration
+	// Timeout is the timeout of a single delivery request.
+	Timeout time.Duration
+	// PollInterval is how often new ledgers and due deliveries are checked.
+	PollInterval time.Duration
+}
+
+// DefaultConfig is the configuration used when a field of Config is not set.
+var DefaultConfig = Config{
+	MaxAttempts:    10,
+	InitialBackoff: 10 * time.Second,
+	MaxBackoff:     time.Hour,
+	Timeout:        10 * time.Second,
+	PollInterval:   time.Second,
+}
+
+// System represents the webhook delivery subsystem of aurora.
+type System struct {
+	historyQ    *history.Q
+	webhooksQ   history.QWebhooks
+	ledgerState *ledger.State
+	client      *http.Client
+	config      Config
+	now         func() time.Time
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// New initializes the webhook subsystem. Deliveries are enqueued starting
+// from the ledger following the last ingested one when the subsystem runs for
+// the first time.
+func New(config Config, dbSession db.SessionInterface, ledgerState *ledger.State) *System {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if config.MaxAttempts == 0 {
+		config.MaxAttempts = DefaultConfig.MaxAttempts
+	}
+	if config.InitialBackoff == 0 {
+		config.InitialBackoff = DefaultConfig.InitialBackoff
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = DefaultConfig.MaxBackoff
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultConfig.Timeout
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = DefaultConfig.PollInterval
+	}
+
+	historyQ := &history.Q{dbSession.Clone()}
+	return &System{
+		historyQ:    historyQ,
+		webhooksQ:   historyQ,
+		ledgerState: ledgerState,
+		client:      &http.Client{Timeout: config.Timeout},
+		config:      config,
+		now:         time.Now,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Run enqueues deliveries for newly ingested ledgers and attempts the due
+// deliveries until the system is shut down.
+func (s *System) Run() {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.runOnce(s.ctx)
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// Shutdown stops the system.
+func (s *System) Shutdown() {
+	s.cancel()
+}
+
+func (s *System) runOnce(ctx context.Context) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err := herrors.FromPanic(rec)
+			log.Errorf("webhooks panicked: %s", err)
+			herrors.ReportToSentry(err, nil)
+		}
+	}()
+
+	if err := s.enqueue(ctx); err != nil {
+		log.Errorf("could not enqueue webhook deliveries: %s", err)
+	}
+	if err := s.deliver(ctx); err != nil {
+		log.Errorf("could not deliver webhooks: %s", err)
+	}
+}
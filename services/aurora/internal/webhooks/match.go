@@ -0,0 +1,164 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/errors"
+)
+
+// match holds the indexes of the operations and effects of a ledger which
+// match a subscription.
+type match struct {
+	operations []int
+	effects    []int
+}
+
+// targets are the assets and liquidity pools referenced by the details of an
+// operation or effect.
+type targets struct {
+	assets map[string]bool
+	pools  map[string]bool
+}
+
+// matchLedger returns the operations and effects of a ledger matching each
+// subscription, keyed by subscription id. Subscriptions without any match are
+// not included.
+//
+// An effect matches an account subscription when it belongs to the account
+// and an asset or liquidity pool subscription when its details reference the
+// asset or pool. An operation matches when the account participates in it,
+// like in /accounts/{id}/operations, when its details reference the asset or
+// pool or when any of its effects match. participants holds the addresses of
+// the participants of the operations, keyed by operation id.
+func matchLedger(
+	subscriptions []history.WebhookSubscription,
+	operations []history.Operation,
+	participants map[int64][]string,
+	effects []history.Effect,
+) (map[int64]*match, error) {
+	operationTargets := make([]targets, len(operations))
+	operationIndexes := map[int64]int{}
+	for i, operation := range operations {
+		t, err := detailsTargets(operation.DetailsString.String)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decode details of operation %d", operation.ID)
+		}
+		operationTargets[i] = t
+		operationIndexes[operation.ID] = i
+	}
+
+	effectTargets := make([]targets, len(effects))
+	for i, effect := range effects {
+		t, err := detailsTargets(effect.DetailsString.String)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err, "could not decode details of effect %d-%d", effect.HistoryOperationID, effect.Order,
+			)
+		}
+		effectTargets[i] = t
+	}
+
+	matches := map[int64]*match{}
+	for _, subscription := range subscriptions {
+		matchedOperations := map[int]bool{}
+		m := &match{}
+
+		for i, effect := range effects {
+			if !subscriptionMatches(subscription, []string{effect.Account}, effectTargets[i]) {
+				continue
+			}
+			m.effects = append(m.effects, i)
+			if index, ok := operationIndexes[effect.HistoryOperationID]; ok {
+				matchedOperations[index] = true
+			}
+		}
+
+		for i, operation := range operations {
+			if matchedOperations[i] || subscriptionMatches(subscription, participants[operation.ID], operationTargets[i]) {
+				m.operations = append(m.operations, i)
+			}
+		}
+
+		if len(m.operations) > 0 || len(m.effects) > 0 {
+			matches[subscription.ID] = m
+		}
+	}
+
+	return matches, nil
+}
+
+func subscriptionMatches(subscription history.WebhookSubscription, accounts []string, t targets) bool {
+	switch {
+	case subscription.AccountID.Valid:
+		for _, account := range accounts {
+			if account == subscription.AccountID.String {
+				return true
+			}
+		}
+		return false
+	case subscription.Asset.Valid:
+		return t.assets[subscription.Asset.String]
+	case subscription.LiquidityPoolID.Valid:
+		return t.pools[subscription.LiquidityPoolID.String]
+	default:
+		return false
+	}
+}
+
+// detailsTargets collects the assets, in canonical form, and the liquidity
+// pool ids referenced anywhere in the JSON details of an operation or effect.
+func detailsTargets(details string) (targets, error) {
+	t := targets{assets: map[string]bool{}, pools: map[string]bool{}}
+	if details == "" {
+		return t, nil
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(details), &decoded); err != nil {
+		return t, err
+	}
+	t.collect(decoded)
+	return t, nil
+}
+
+func (t targets) collect(value interface{}) {
+	switch value := value.(type) {
+	case []interface{}:
+		for _, item := range value {
+			t.collect(item)
+		}
+	case map[string]interface{}:
+		for key, item := range value {
+			switch {
+			// ex. asset_type, selling_asset_type, source_asset_type
+			case strings.HasSuffix(key, "asset_type"):
+				prefix := strings.TrimSuffix(key, "asset_type")
+				if item == "native" {
+					t.assets["native"] = true
+				} else if code, ok := value[prefix+"asset_code"].(string); ok {
+					if issuer, ok := value[prefix+"asset_issuer"].(string); ok {
+						t.assets[code+":"+issuer] = true
+					}
+				}
+			// claimable balances and liquidity pool reserves
+			case key == "asset":
+				if asset, ok := item.(string); ok {
+					t.assets[asset] = true
+				}
+			case key == "liquidity_pool_id":
+				if id, ok := item.(string); ok {
+					t.pools[id] = true
+				}
+			case key == "liquidity_pool":
+				if pool, ok := item.(map[string]interface{}); ok {
+					if id, ok := pool["id"].(string); ok {
+						t.pools[id] = true
+					}
+				}
+			}
+			t.collect(item)
+		}
+	}
+}
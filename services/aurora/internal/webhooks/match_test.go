@@ -0,0 +1,143 @@
+package webhooks
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+)
+
+const (
+	accountA = "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"
+	accountB = "GACAR2AEYEKITE2LKI5RMXF5MIVZ6Q7XILROGDT22O7JX4DSWFS7FDDP"
+	usd      = "USD:GACAR2AEYEKITE2LKI5RMXF5MIVZ6Q7XILROGDT22O7JX4DSWFS7FDDP"
+	poolID   = "ea4e3e63a95fd840c1394f195722ffdcb2d0d4f0a26589c6ab557d81e6b0bf9d"
+)
+
+func TestDetailsTargets(t *testing.T) {
+	for _, testCase := range []struct {
+		name    string
+		details string
+		assets  []string
+		pools   []string
+	}{
+		{
+			name:    "empty",
+			details: "",
+		},
+		{
+			name:    "payment",
+			details: `{"from": "` + accountA + `", "to": "` + accountB + `", "amount": "10.0000000", "asset_type": "credit_alphanum4", "asset_code": "USD", "asset_issuer": "` + accountB + `"}`,
+			assets:  []string{usd},
+		},
+		{
+			name:    "path payment",
+			details: `{"asset_type": "native", "source_asset_type": "credit_alphanum4", "source_asset_code": "USD", "source_asset_issuer": "` + accountB + `", "path": [{"asset_type": "native"}]}`,
+			assets:  []string{"native", usd},
+		},
+		{
+			name:    "claimable balance",
+			details: `{"asset": "` + usd + `", "amount": "1.0000000"}`,
+			assets:  []string{usd},
+		},
+		{
+			name:    "liquidity pool deposit",
+			details: `{"liquidity_pool_id": "` + poolID + `", "reserves_max": [{"asset": "native", "amount": "1.0000000"}, {"asset": "` + usd + `", "amount": "1.0000000"}]}`,
+			assets:  []string{"native", usd},
+			pools:   []string{poolID},
+		},
+		{
+			name:    "liquidity pool effect",
+			details: `{"liquidity_pool": {"id": "` + poolID + `", "reserves": [{"asset": "native", "amount": "1.0000000"}]}}`,
+			assets:  []string{"native"},
+			pools:   []string{poolID},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			targets, err := detailsTargets(testCase.details)
+			require.NoError(t, err)
+
+			var assets, pools []string
+			for asset := range targets.assets {
+				assets = append(assets, asset)
+			}
+			for pool := range targets.pools {
+				pools = append(pools, pool)
+			}
+			assert.ElementsMatch(t, testCase.assets, assets)
+			assert.ElementsMatch(t, testCase.pools, pools)
+		})
+	}
+
+	_, err := detailsTargets("{")
+	assert.Error(t, err)
+}
+
+func TestMatchLedger(t *testing.T) {
+	operations := []history.Operation{
+		{
+			TotalOrderID:  history.TotalOrderID{ID: 100},
+			SourceAccount: accountA,
+			DetailsString: null.StringFrom(`{"from": "` + accountA + `", "to": "` + accountB + `", "asset_type": "native"}`),
+		},
+		{
+			TotalOrderID:  history.TotalOrderID{ID: 200},
+			SourceAccount: accountB,
+			DetailsString: null.StringFrom(`{"liquidity_pool_id": "` + poolID + `"}`),
+		},
+		{
+			TotalOrderID:  history.TotalOrderID{ID: 300},
+			SourceAccount: accountB,
+			DetailsString: null.StringFrom(`{"trustor": "` + accountA + `", "trustee": "` + accountB + `"}`),
+		},
+	}
+	// accountA participates in the third operation without being its source
+	// nor having any of its effects.
+	participants := map[int64][]string{
+		100: {accountA, accountB},
+		200: {accountB},
+		300: {accountB, accountA},
+	}
+	effects := []history.Effect{
+		{
+			Account:            accountA,
+			HistoryOperationID: 100,
+			Order:              1,
+			DetailsString:      null.StringFrom(`{"asset_type": "native", "amount": "1.0000000"}`),
+		},
+		{
+			Account:            accountB,
+			HistoryOperationID: 100,
+			Order:              2,
+			DetailsString:      null.StringFrom(`{"asset_type": "native", "amount": "1.0000000"}`),
+		},
+		{
+			Account:            accountB,
+			HistoryOperationID: 200,
+			Order:              1,
+			DetailsString:      null.StringFrom(`{"asset_type": "credit_alphanum4", "asset_code": "USD", "asset_issuer": "` + accountB + `"}`),
+		},
+	}
+	subscriptions := []history.WebhookSubscription{
+		{ID: 1, AccountID: sql.NullString{String: accountA, Valid: true}},
+		{ID: 2, AccountID: sql.NullString{String: accountB, Valid: true}},
+		{ID: 3, Asset: sql.NullString{String: usd, Valid: true}},
+		{ID: 4, LiquidityPoolID: sql.NullString{String: poolID, Valid: true}},
+		{ID: 5, Asset: sql.NullString{String: "EUR:" + accountA, Valid: true}},
+	}
+
+	matches, err := matchLedger(subscriptions, operations, participants, effects)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[int64]*match{
+		1: {operations: []int{0, 2}, effects: []int{0}},
+		2: {operations: []int{0, 1, 2}, effects: []int{1, 2}},
+		// the operation matches because of its effect
+		3: {operations: []int{1}, effects: []int{2}},
+		4: {operations: []int{1}},
+	}, matches)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/hcnet/go/strkey"
 	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/txnbuild"
+	"github.com/hcnet/go/txnbuild/txpool"
 )
 
 func initFriendbot(
@@ -57,25 +58,37 @@ func initFriendbot(
 		submitTxRetriesAllowed = 5
 	}
 	log.Printf("Found all valid params, now creating %d minions", numMinions)
-	minions, err := createMinionAccounts(botAccount, botKeypair, networkPassphrase, startingBalance, minionBalance, numMinions, minionBatchSize, submitTxRetriesAllowed, baseFee, hclient)
+	minions, err := createMinionAccounts(botAccount, botKeypair, networkPassphrase, minionBalance, numMinions, minionBatchSize, submitTxRetriesAllowed, hclient)
 	if err != nil && len(minions) == 0 {
 		return nil, errors.Wrap(err, "creating minion accounts")
 	}
 	log.Printf("Adding %d minions to friendbot", len(minions))
-	return &internal.Bot{Minions: minions}, nil
+	pool, err := txpool.New(txpool.Config{
+		Client:            hclient,
+		NetworkPassphrase: networkPassphrase,
+		Channels:          minions,
+		BaseFee:           baseFee,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "creating transaction pool")
+	}
+	return &internal.Bot{Pool: pool, BotKeypair: botKeypair, StartingBalance: startingBalance}, nil
 }
 
-func createMinionAccounts(botAccount internal.Account, botKeypair *keypair.Full, networkPassphrase, newAccountBalance, minionBalance string,
-	numMinions, minionBatchSize, submitTxRetriesAllowed int, baseFee int64, hclient auroraclient.ClientInterface) ([]internal.Minion, error) {
+// createMinionAccounts creates the minion accounts, which are the channel
+// accounts friendbot submits its transactions with, and returns their
+// keypairs.
+func createMinionAccounts(botAccount internal.Account, botKeypair *keypair.Full, networkPassphrase, minionBalance string,
+	numMinions, minionBatchSize, submitTxRetriesAllowed int, hclient auroraclient.ClientInterface) ([]*keypair.Full, error) {
 
-	var minions []internal.Minion
+	var minions []*keypair.Full
 	numRemainingMinions := numMinions
 	// Allow retries to account for testnet congestion
 	currentSubmitTxRetry := 0
 
 	for numRemainingMinions > 0 {
 		var (
-			newMinions []internal.Minion
+			newMinions []*keypair.Full
 			ops        []txnbuild.Operation
 		)
 		// Refresh the sequence number before submitting a new transaction.
@@ -94,18 +107,7 @@ func createMinionAccounts(botAccount internal.Account, botKeypair *keypair.Full,
 			if err != nil {
 				return minions, errors.Wrap(err, "making keypair")
 			}
-			newMinions = append(newMinions, internal.Minion{
-				Account:              internal.Account{AccountID: minionKeypair.Address()},
-				Keypair:              minionKeypair,
-				BotAccount:           botAccount,
-				BotKeypair:           botKeypair,
-				Aurora:              hclient,
-				Network:              networkPassphrase,
-				StartingBalance:      newAccountBalance,
-				SubmitTransaction:    internal.SubmitTransaction,
-				CheckSequenceRefresh: internal.CheckSequenceRefresh,
-				BaseFee:              baseFee,
-			})
+			newMinions = append(newMinions, minionKeypair)
 
 			ops = append(ops, &txnbuild.CreateAccount{
 				Destination: minionKeypair.Address(),
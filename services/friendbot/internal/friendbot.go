@@ -1,36 +1,56 @@
 package internal
 
 import (
-	"log"
-	"sync"
+	"context"
+	"fmt"
 
+	"github.com/hcnet/go/clients/auroraclient"
+	"github.com/hcnet/go/keypair"
 	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/hcnet/go/txnbuild/txpool"
 )
 
-// Bot represents the friendbot subsystem and primarily delegates work
-// to its Minions.
-type Bot struct {
-	Minions         []Minion
-	nextMinionIndex int
-	indexMux        sync.Mutex
-}
+const createAccountAlreadyExistXDR = "AAAAAAAAAGT/////AAAAAQAAAAAAAAAA/////AAAAAA="
+
+var ErrAccountExists error = errors.New(fmt.Sprintf("createAccountAlreadyExist (%s)", createAccountAlreadyExistXDR))
 
-// SubmitResult is the result from the asynchronous tx submission.
-type SubmitResult struct {
-	maybeTransactionSuccess *hProtocol.Transaction
-	maybeErr                error
+// Bot represents the friendbot subsystem. It funds accounts with create
+// account operations paid by the bot account and submitted by a pool whose
+// channel accounts are the minions.
+type Bot struct {
+	Pool            *txpool.Pool
+	BotKeypair      *keypair.Full
+	StartingBalance string
 }
 
 // Pay funds the account at `destAddress`.
 func (bot *Bot) Pay(destAddress string) (*hProtocol.Transaction, error) {
-	bot.indexMux.Lock()
-	log.Printf("Selecting minion at index %d of max length %d", bot.nextMinionIndex, len(bot.Minions))
-	minion := bot.Minions[bot.nextMinionIndex]
-	bot.nextMinionIndex = (bot.nextMinionIndex + 1) % len(bot.Minions)
-	bot.indexMux.Unlock()
-	resultChan := make(chan SubmitResult)
-	go minion.Run(destAddress, resultChan)
-	maybeSubmitResult := <-resultChan
-	close(resultChan)
-	return maybeSubmitResult.maybeTransactionSuccess, maybeSubmitResult.maybeErr
+	createAccountOp := txnbuild.CreateAccount{
+		Destination:   destAddress,
+		SourceAccount: bot.BotKeypair.Address(),
+		Amount:        bot.StartingBalance,
+	}
+	result, err := bot.Pool.Submit(context.Background(), txpool.Request{
+		Operations:    []txnbuild.Operation{&createAccountOp},
+		Preconditions: txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()},
+		Signers:       []*keypair.Full{bot.BotKeypair},
+	}).Result()
+	if err != nil {
+		return nil, submitError(err)
+	}
+	return &result, nil
+}
+
+// submitError replaces the aurora error returned when the destination account
+// already exists with ErrAccountExists.
+func submitError(err error) error {
+	if herr := auroraclient.GetError(err); herr != nil {
+		resStr, resErr := herr.ResultString()
+		if resErr == nil && resStr == createAccountAlreadyExistXDR {
+			return errors.Wrap(ErrAccountExists, "submitting tx to aurora")
+		}
+	}
+	return errors.Wrap(err, "submitting create account tx")
 }
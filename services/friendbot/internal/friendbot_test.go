@@ -5,50 +5,71 @@ import (
 	"testing"
 
 	"github.com/hcnet/go/txnbuild"
+	"github.com/hcnet/go/txnbuild/txpool"
 
 	"github.com/hcnet/go/clients/auroraclient"
 	"github.com/hcnet/go/keypair"
 	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/problem"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestFriendbot_Pay(t *testing.T) {
-	mockSubmitTransaction := func(minion *Minion, hclient auroraclient.ClientInterface, tx string) (*hProtocol.Transaction, error) {
-		// Instead of submitting the tx, we emulate a success.
-		txSuccess := hProtocol.Transaction{EnvelopeXdr: tx, Successful: true}
-		return &txSuccess, nil
+// submitClient emulates successful submissions, returning the submitted
+// envelope.
+type submitClient struct {
+	auroraclient.MockClient
+	lock         sync.Mutex
+	numTxSubmits int
+	err          error
+}
+
+func (c *submitClient) SubmitTransactionXDR(tx string) (hProtocol.Transaction, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.numTxSubmits++
+	if c.err != nil {
+		return hProtocol.Transaction{}, c.err
 	}
+	return hProtocol.Transaction{EnvelopeXdr: tx, Successful: true}, nil
+}
 
+func newTestBot(t *testing.T, client auroraclient.ClientInterface) *Bot {
 	// Public key: GD25B4QI6KWVDWXDW25CIM7EKR6A6PBSWE2RCNSAC4NJQDQJXZJYMMKR
 	botSeed := "SCWNLYELENPBXN46FHYXETT5LJCYBZD5VUQQVW4KZPHFO2YTQJUWT4D5"
 	botKeypair, err := keypair.Parse(botSeed)
-	if !assert.NoError(t, err) {
-		return
-	}
-	botAccount := Account{AccountID: botKeypair.Address()}
+	require.NoError(t, err)
 
 	// Public key: GD4AGPPDFFHKK3Z2X4XZDRXX6GZQKP4FMLVQ5T55NDEYGG3GIP7BQUHM
 	minionSeed := "SDTNSEERJPJFUE2LSDNYBFHYGVTPIWY7TU2IOJZQQGLWO2THTGB7NU5A"
 	minionKeypair, err := keypair.Parse(minionSeed)
-	if !assert.NoError(t, err) {
-		return
-	}
+	require.NoError(t, err)
 
-	minion := Minion{
-		Account: Account{
-			AccountID: minionKeypair.Address(),
-			Sequence:  1,
-		},
-		Keypair:              minionKeypair.(*keypair.Full),
-		BotAccount:           botAccount,
-		BotKeypair:           botKeypair.(*keypair.Full),
-		Network:              "Test SDF Network ; September 2015",
-		StartingBalance:      "10000.00",
-		SubmitTransaction:    mockSubmitTransaction,
-		CheckSequenceRefresh: CheckSequenceRefresh,
-		BaseFee:              txnbuild.MinBaseFee,
+	pool, err := txpool.New(txpool.Config{
+		Client:            client,
+		NetworkPassphrase: "Test SDF Network ; September 2015",
+		Channels:          []*keypair.Full{minionKeypair.(*keypair.Full)},
+		BaseFee:           txnbuild.MinBaseFee,
+	})
+	require.NoError(t, err)
+	return &Bot{
+		Pool:            pool,
+		BotKeypair:      botKeypair.(*keypair.Full),
+		StartingBalance: "10000.00",
 	}
-	fb := &Bot{Minions: []Minion{minion}}
+}
+
+func mockMinionAccount(client *auroraclient.MockClient) {
+	minionAddress := "GD4AGPPDFFHKK3Z2X4XZDRXX6GZQKP4FMLVQ5T55NDEYGG3GIP7BQUHM"
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: minionAddress}).
+		Return(hProtocol.Account{AccountID: minionAddress, Sequence: 1}, nil)
+}
+
+func TestFriendbot_Pay(t *testing.T) {
+	client := &submitClient{}
+	mockMinionAccount(&client.MockClient)
+	fb := newTestBot(t, client)
 
 	recipientAddress := "GDJIN6W6PLTPKLLM57UW65ZH4BITUXUMYQHIMAZFYXF45PZVAWDBI77Z"
 	txSuccess, err := fb.Pay(recipientAddress)
@@ -73,3 +94,59 @@ func TestFriendbot_Pay(t *testing.T) {
 	}()
 	wg.Wait()
 }
+
+func TestFriendbot_PayAccountExists(t *testing.T) {
+	client := &submitClient{
+		err: &auroraclient.Error{Problem: problem.P{
+			Status: 400,
+			Extras: map[string]interface{}{"result_xdr": createAccountAlreadyExistXDR},
+		}},
+	}
+	mockMinionAccount(&client.MockClient)
+	fb := newTestBot(t, client)
+
+	_, err := fb.Pay("GDJIN6W6PLTPKLLM57UW65ZH4BITUXUMYQHIMAZFYXF45PZVAWDBI77Z")
+	assert.Equal(t, ErrAccountExists, errors.Cause(err))
+}
+
+// The minion sequence number can't be loaded, every payment must fail without
+// blocking the other ones.
+func TestFriendbot_SequenceErrors(t *testing.T) {
+	client := &submitClient{}
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: "GD4AGPPDFFHKK3Z2X4XZDRXX6GZQKP4FMLVQ5T55NDEYGG3GIP7BQUHM"}).
+		Return(hProtocol.Account{}, errors.New("could not refresh sequence"))
+	fb := newTestBot(t, client)
+
+	recipientAddress := "GDJIN6W6PLTPKLLM57UW65ZH4BITUXUMYQHIMAZFYXF45PZVAWDBI77Z"
+	numTests := 100
+	var wg sync.WaitGroup
+	wg.Add(numTests)
+	for i := 0; i < numTests; i++ {
+		go func() {
+			_, err := fb.Pay(recipientAddress)
+			assert.Error(t, err)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 0, client.numTxSubmits)
+}
+
+func TestFriendbot_CorrectNumberOfTxSubmissions(t *testing.T) {
+	client := &submitClient{}
+	mockMinionAccount(&client.MockClient)
+	fb := newTestBot(t, client)
+
+	recipientAddress := "GDJIN6W6PLTPKLLM57UW65ZH4BITUXUMYQHIMAZFYXF45PZVAWDBI77Z"
+	numTests := 1000
+	var wg sync.WaitGroup
+	wg.Add(numTests)
+	for i := 0; i < numTests; i++ {
+		go func() {
+			fb.Pay(recipientAddress)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, numTests, client.numTxSubmits)
+}
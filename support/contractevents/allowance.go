@@ -0,0 +1,64 @@
+package contractevents
+
+import (
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+var (
+	ErrNotIncrAllowEvent = errors.New("event is not a valid 'incr_allow' event")
+	ErrNotDecrAllowEvent = errors.New("event is not a valid 'decr_allow' event")
+)
+
+type IncrAllowEvent struct {
+	sacEvent
+
+	From    string
+	Spender string
+	Amount  xdr.Int128Parts
+}
+
+// parse tries to parse the given topics and value as a SAC "incr_allow"
+// event.
+//
+// Internally, it assumes that the `topics` array has already validated both the
+// function name AND the asset <--> contract ID relationship. It will return a
+// best-effort parsing even in error cases.
+func (event *IncrAllowEvent) parse(topics xdr.ScVec, value xdr.ScVal) error {
+	//
+	// The incr_allow event format is:
+	//
+	// 	"incr_allow"	Symbol
+	//  <from>			Address
+	//  <spender>		Address
+	// 	<asset>			Bytes
+	//
+	// 	<amount> 		i128
+	//
+	var err error
+	event.From, event.Spender, event.Amount, err = parseBalanceChangeEvent(topics, value)
+	if err != nil {
+		return ErrNotIncrAllowEvent
+	}
+	return nil
+}
+
+type DecrAllowEvent struct {
+	sacEvent
+
+	From    string
+	Spender string
+	Amount  xdr.Int128Parts
+}
+
+// parse tries to parse the given topics and value as a SAC "decr_allow"
+// event. It shares the format of "incr_allow" events, but the amount is
+// subtracted from the allowance rather than added to it.
+func (event *DecrAllowEvent) parse(topics xdr.ScVec, value xdr.ScVal) error {
+	var err error
+	event.From, event.Spender, event.Amount, err = parseBalanceChangeEvent(topics, value)
+	if err != nil {
+		return ErrNotDecrAllowEvent
+	}
+	return nil
+}
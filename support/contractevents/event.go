@@ -15,12 +15,10 @@ type EventType int
 // nor the other *_from variants. This is intentional from the host environment.
 
 const (
-	// Implemented
 	EventTypeTransfer EventType = iota
 	EventTypeMint
 	EventTypeClawback
 	EventTypeBurn
-	// TODO: Not implemented
 	EventTypeIncrAllow
 	EventTypeDecrAllow
 	EventTypeSetAuthorized
@@ -29,10 +27,14 @@ const (
 
 var (
 	HCNET_ASSET_CONTRACT_TOPICS = map[xdr.ScSymbol]EventType{
-		xdr.ScSymbol("transfer"): EventTypeTransfer,
-		xdr.ScSymbol("mint"):     EventTypeMint,
-		xdr.ScSymbol("clawback"): EventTypeClawback,
-		xdr.ScSymbol("burn"):     EventTypeBurn,
+		xdr.ScSymbol("transfer"):       EventTypeTransfer,
+		xdr.ScSymbol("mint"):           EventTypeMint,
+		xdr.ScSymbol("clawback"):       EventTypeClawback,
+		xdr.ScSymbol("burn"):           EventTypeBurn,
+		xdr.ScSymbol("incr_allow"):     EventTypeIncrAllow,
+		xdr.ScSymbol("decr_allow"):     EventTypeDecrAllow,
+		xdr.ScSymbol("set_authorized"): EventTypeSetAuthorized,
+		xdr.ScSymbol("set_admin"):      EventTypeSetAdmin,
 	}
 
 	ErrNotHcnetAssetContract = errors.New("event was not from a Hcnet Asset Contract")
@@ -138,6 +140,22 @@ func NewHcnetAssetContractEvent(event *Event, networkPassphrase string) (HcnetAs
 		burnEvent := BurnEvent{sacEvent: *evt}
 		return &burnEvent, burnEvent.parse(topics, value)
 
+	case EventTypeIncrAllow:
+		incrAllowEvent := IncrAllowEvent{sacEvent: *evt}
+		return &incrAllowEvent, incrAllowEvent.parse(topics, value)
+
+	case EventTypeDecrAllow:
+		decrAllowEvent := DecrAllowEvent{sacEvent: *evt}
+		return &decrAllowEvent, decrAllowEvent.parse(topics, value)
+
+	case EventTypeSetAuthorized:
+		setAuthorizedEvent := SetAuthorizedEvent{sacEvent: *evt}
+		return &setAuthorizedEvent, setAuthorizedEvent.parse(topics, value)
+
+	case EventTypeSetAdmin:
+		setAdminEvent := SetAdminEvent{sacEvent: *evt}
+		return &setAdminEvent, setAdminEvent.parse(topics, value)
+
 	default:
 		return evt, errors.Wrapf(ErrEventUnsupported,
 			"event type %d ('%s') unsupported", evt.Type, fn)
@@ -63,6 +63,10 @@ func TestEventGenerator(t *testing.T) {
 		EventTypeMint,
 		EventTypeClawback,
 		EventTypeBurn,
+		EventTypeIncrAllow,
+		EventTypeDecrAllow,
+		EventTypeSetAuthorized,
+		EventTypeSetAdmin,
 	} {
 		event := GenerateEvent(type_, from, to, admin, xdr.MustNewNativeAsset(), big.NewInt(12345), passphrase)
 		parsedEvent, err := NewHcnetAssetContractEvent(&event, passphrase)
@@ -201,6 +205,80 @@ func TestSACBurnEvent(t *testing.T) {
 	require.EqualValues(t, 0, burnEvent.Amount.Hi)
 }
 
+func TestSACAllowanceEvents(t *testing.T) {
+	xdrEvent := GenerateEvent(EventTypeIncrAllow, randomAccount, zeroContract, "", randomAsset, big.NewInt(10000), passphrase)
+
+	// Ensure the happy path for incr_allow events works
+	sacEvent, err := NewHcnetAssetContractEvent(&xdrEvent, passphrase)
+	require.NoError(t, err)
+	require.Equal(t, EventTypeIncrAllow, sacEvent.GetType())
+
+	incrEvent := sacEvent.(*IncrAllowEvent)
+	require.Equal(t, randomAccount, incrEvent.From)
+	require.Equal(t, zeroContract, incrEvent.Spender)
+	require.EqualValues(t, 10000, incrEvent.Amount.Lo)
+	require.EqualValues(t, 0, incrEvent.Amount.Hi)
+
+	// Ensure the happy path for decr_allow events works
+	xdrEvent = GenerateEvent(EventTypeDecrAllow, randomAccount, zeroContract, "", randomAsset, big.NewInt(500), passphrase)
+	sacEvent, err = NewHcnetAssetContractEvent(&xdrEvent, passphrase)
+	require.NoError(t, err)
+	require.Equal(t, EventTypeDecrAllow, sacEvent.GetType())
+
+	decrEvent := sacEvent.(*DecrAllowEvent)
+	require.Equal(t, randomAccount, decrEvent.From)
+	require.Equal(t, zeroContract, decrEvent.Spender)
+	require.EqualValues(t, 500, decrEvent.Amount.Lo)
+
+	// Ensure a non-i128 amount is rejected
+	xdrEvent.Body.V0.Data = makeBool(true)
+	_, err = NewHcnetAssetContractEvent(&xdrEvent, passphrase)
+	require.ErrorIs(t, err, ErrNotDecrAllowEvent)
+}
+
+func TestSACSetAuthorizedEvent(t *testing.T) {
+	xdrEvent := GenerateEvent(EventTypeSetAuthorized, "", randomAccount, randomIssuer.Address(), randomAsset, big.NewInt(1), passphrase)
+
+	// Ensure the happy path for set_authorized events works
+	sacEvent, err := NewHcnetAssetContractEvent(&xdrEvent, passphrase)
+	require.NoError(t, err)
+	require.Equal(t, EventTypeSetAuthorized, sacEvent.GetType())
+
+	authEvent := sacEvent.(*SetAuthorizedEvent)
+	require.Equal(t, randomIssuer.Address(), authEvent.Admin)
+	require.Equal(t, randomAccount, authEvent.ID)
+	require.True(t, authEvent.Authorized)
+
+	// Ensure deauthorization is parsed
+	xdrEvent = GenerateEvent(EventTypeSetAuthorized, "", randomAccount, randomIssuer.Address(), randomAsset, big.NewInt(0), passphrase)
+	sacEvent, err = NewHcnetAssetContractEvent(&xdrEvent, passphrase)
+	require.NoError(t, err)
+	require.False(t, sacEvent.(*SetAuthorizedEvent).Authorized)
+
+	// Ensure a non-bool value is rejected
+	xdrEvent.Body.V0.Data = makeAmount(1)
+	_, err = NewHcnetAssetContractEvent(&xdrEvent, passphrase)
+	require.ErrorIs(t, err, ErrNotSetAuthorizedEvent)
+}
+
+func TestSACSetAdminEvent(t *testing.T) {
+	xdrEvent := GenerateEvent(EventTypeSetAdmin, "", zeroContract, randomIssuer.Address(), randomAsset, big.NewInt(0), passphrase)
+
+	// Ensure the happy path for set_admin events works
+	sacEvent, err := NewHcnetAssetContractEvent(&xdrEvent, passphrase)
+	require.NoError(t, err)
+	require.Equal(t, EventTypeSetAdmin, sacEvent.GetType())
+
+	adminEvent := sacEvent.(*SetAdminEvent)
+	require.Equal(t, randomIssuer.Address(), adminEvent.Admin)
+	require.Equal(t, zeroContract, adminEvent.NewAdmin)
+
+	// Ensure a non-address value is rejected
+	xdrEvent.Body.V0.Data = makeAmount(1)
+	_, err = NewHcnetAssetContractEvent(&xdrEvent, passphrase)
+	require.ErrorIs(t, err, ErrNotSetAdminEvent)
+}
+
 func TestFuzzingSACEventParser(t *testing.T) {
 	gen := randxdr.NewGenerator()
 	for i := 0; i < 100_000; i++ {
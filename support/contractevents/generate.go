@@ -19,6 +19,10 @@ import (
 // transfer events have no admin, so it will be ignored). This means you can
 // always pass your set of testing parameters, modify the type, and get the
 // event filled out with the details you expect.
+//
+// For allowance events, `to` is the spender. For set_authorized events, `to`
+// is the account being (de)authorized and a non-zero amount authorizes it.
+// For set_admin events, `to` is the new admin.
 func GenerateEvent(
 	type_ EventType,
 	from, to, admin string,
@@ -61,6 +65,39 @@ func GenerateEvent(
 			makeAsset(asset),
 		}
 
+	case EventTypeIncrAllow:
+		topics = []xdr.ScVal{
+			makeSymbol("incr_allow"),
+			makeAddress(from),
+			makeAddress(to),
+			makeAsset(asset),
+		}
+
+	case EventTypeDecrAllow:
+		topics = []xdr.ScVal{
+			makeSymbol("decr_allow"),
+			makeAddress(from),
+			makeAddress(to),
+			makeAsset(asset),
+		}
+
+	case EventTypeSetAuthorized:
+		topics = []xdr.ScVal{
+			makeSymbol("set_authorized"),
+			makeAddress(admin),
+			makeAddress(to),
+			makeAsset(asset),
+		}
+		data = makeBool(amount.Sign() != 0)
+
+	case EventTypeSetAdmin:
+		topics = []xdr.ScVal{
+			makeSymbol("set_admin"),
+			makeAddress(admin),
+			makeAsset(asset),
+		}
+		data = makeAddress(to)
+
 	default:
 		panic(fmt.Errorf("event type %v unsupported", type_))
 	}
@@ -108,6 +145,13 @@ func makeSymbol(sym string) xdr.ScVal {
 	}
 }
 
+func makeBool(b bool) xdr.ScVal {
+	return xdr.ScVal{
+		Type: xdr.ScValTypeScvBool,
+		B:    &b,
+	}
+}
+
 func makeBigAmount(amount *big.Int) xdr.ScVal {
 	// TODO: Better check, as MaxUint128 shouldn't be allowed
 	if amount.BitLen() > 128 {
@@ -0,0 +1,48 @@
+package contractevents
+
+import (
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+var ErrNotSetAdminEvent = errors.New("event is not a valid 'set_admin' event")
+
+type SetAdminEvent struct {
+	sacEvent
+
+	Admin    string
+	NewAdmin string
+}
+
+// parse tries to parse the given topics and value as a SAC "set_admin" event.
+//
+// Internally, it assumes that the `topics` array has already validated both the
+// function name AND the asset <--> contract ID relationship. It will return a
+// best-effort parsing even in error cases.
+func (event *SetAdminEvent) parse(topics xdr.ScVec, value xdr.ScVal) error {
+	//
+	// The set_admin event format is:
+	//
+	// 	"set_admin"	Symbol
+	//  <admin>		Address
+	// 	<asset>		Bytes
+	//
+	// 	<new_admin>	Address
+	//
+	if len(topics) != 3 {
+		return ErrNotSetAdminEvent
+	}
+
+	var err error
+	event.Admin, err = parseAddress(topics[1])
+	if err != nil {
+		return ErrNotSetAdminEvent
+	}
+
+	event.NewAdmin, err = parseAddress(value)
+	if err != nil {
+		return ErrNotSetAdminEvent
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package contractevents
+
+import (
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+var ErrNotSetAuthorizedEvent = errors.New("event is not a valid 'set_authorized' event")
+
+type SetAuthorizedEvent struct {
+	sacEvent
+
+	Admin      string
+	ID         string
+	Authorized bool
+}
+
+// parse tries to parse the given topics and value as a SAC "set_authorized"
+// event.
+//
+// Internally, it assumes that the `topics` array has already validated both the
+// function name AND the asset <--> contract ID relationship. It will return a
+// best-effort parsing even in error cases.
+func (event *SetAuthorizedEvent) parse(topics xdr.ScVec, value xdr.ScVal) error {
+	//
+	// The set_authorized event format is:
+	//
+	// 	"set_authorized"	Symbol
+	//  <admin>				Address
+	//  <id> 				Address
+	// 	<asset>				Bytes
+	//
+	// 	<authorize> 		bool
+	//
+	if len(topics) != 4 {
+		return ErrNotSetAuthorizedEvent
+	}
+
+	var err error
+	event.Admin, err = parseAddress(topics[1])
+	if err != nil {
+		return ErrNotSetAuthorizedEvent
+	}
+
+	event.ID, err = parseAddress(topics[2])
+	if err != nil {
+		return ErrNotSetAuthorizedEvent
+	}
+
+	authorized, ok := value.GetB()
+	if !ok {
+		return ErrNotSetAuthorizedEvent
+	}
+	event.Authorized = authorized
+
+	return nil
+}
@@ -45,8 +45,19 @@ func parseBalanceChangeEvent(topics xdr.ScVec, value xdr.ScVal) (
 
 	amount, ok = value.GetI128()
 	if !ok {
+		err = ErrNotBalanceChangeEvent
 		return
 	}
 
 	return first, second, amount, nil
 }
+
+// parseAddress extracts the strkey representation of the address held by the
+// given value.
+func parseAddress(value xdr.ScVal) (string, error) {
+	address, ok := value.GetAddress()
+	if !ok {
+		return "", errors.New("value is not an address")
+	}
+	return address.String()
+}
@@ -23,6 +23,23 @@ func (b *Filesystem) GetFile(pth string) (io.ReadCloser, error) {
 	return os.Open(path.Join(b.prefix, pth))
 }
 
+func (b *Filesystem) GetFileRange(pth string, offset, length int64) (io.ReadCloser, error) {
+	f, err := os.Open(path.Join(b.prefix, pth))
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.NewSectionReader(f, offset, length), f}, nil
+}
+
+func (b *Filesystem) RemoveFile(pth string) error {
+	pth = path.Join(b.prefix, pth)
+	log.WithField("path", pth).Trace("fs: remove file")
+	return os.Remove(pth)
+}
+
 func (b *Filesystem) Exists(pth string) (bool, error) {
 	pth = path.Join(b.prefix, pth)
 	log.WithField("path", pth).Trace("fs: check exists")
@@ -81,13 +81,37 @@ func (b *GCSStorage) GetFile(pth string) (io.ReadCloser, error) {
 	log.WithField("path", pth).Trace("gcs: get file")
 	r, err := b.bucket.Object(pth).NewReader(context.Background())
 	if err == storage.ErrObjectNotExist {
-		// TODO: Check this is right
-		//lint:ignore SA4006 Ignore unused function temporarily
-		err = os.ErrNotExist
+		return nil, os.ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (b *GCSStorage) GetFileRange(pth string, offset, length int64) (io.ReadCloser, error) {
+	pth = path.Join(b.prefix, pth)
+	log.WithField("path", pth).Trace("gcs: get file range")
+	r, err := b.bucket.Object(pth).NewRangeReader(context.Background(), offset, length)
+	if err == storage.ErrObjectNotExist {
+		return nil, os.ErrNotExist
+	}
+	if err != nil {
+		return nil, err
 	}
 	return r, nil
 }
 
+func (b *GCSStorage) RemoveFile(pth string) error {
+	pth = path.Join(b.prefix, pth)
+	log.WithField("path", pth).Trace("gcs: remove file")
+	err := b.bucket.Object(pth).Delete(context.Background())
+	if err == storage.ErrObjectNotExist {
+		return os.ErrNotExist
+	}
+	return err
+}
+
 func (b *GCSStorage) PutFile(pth string, in io.ReadCloser) error {
 	pth = path.Join(b.prefix, pth)
 	log.WithField("path", pth).Trace("gcs: get file")
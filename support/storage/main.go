@@ -22,6 +22,39 @@ type Storage interface {
 	Close() error
 }
 
+// RangeReader is implemented by storages which can read part of a file
+// without downloading all of it.
+type RangeReader interface {
+	GetFileRange(path string, offset, length int64) (io.ReadCloser, error)
+}
+
+// FileRemover is implemented by storages which can remove files.
+type FileRemover interface {
+	RemoveFile(path string) error
+}
+
+// GetFileRange returns `length` bytes of the file starting at `offset`. If the
+// storage isn't a RangeReader, the beginning of the file is downloaded and
+// skipped instead.
+func GetFileRange(s Storage, pth string, offset, length int64) (io.ReadCloser, error) {
+	if r, ok := s.(RangeReader); ok {
+		return r.GetFileRange(pth, offset, length)
+	}
+
+	file, err := s.GetFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.CopyN(io.Discard, file, offset); err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "could not skip to offset %d of %s", offset, pth)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(file, length), file}, nil
+}
+
 type ConnectOptions struct {
 	Context          context.Context
 	S3Region         string
@@ -39,6 +72,11 @@ type ConnectOptions struct {
 	// Wrap the Storage after connection. For example, to add a caching or
 	// introspection layer.
 	Wrap func(Storage) (Storage, error)
+
+	// MultiStorageOptions configures the storage returned for multi:// URLs,
+	// see ConnectMultiBackend. The options set in the query of the URL take
+	// precedence.
+	MultiStorageOptions MultiStorageOptions
 }
 
 func ConnectBackend(u string, opts ConnectOptions) (Storage, error) {
@@ -88,6 +126,19 @@ func ConnectBackend(u string, opts ConnectOptions) (Storage, error) {
 	case "http", "https":
 		backend = NewHttpStorage(opts.Context, parsed, opts.UserAgent)
 
+	case "multi":
+		var urls []string
+		urls, err = multiBackendURLs(parsed)
+		if err == nil {
+			opts.MultiStorageOptions, err = multiURLOptions(parsed, opts.MultiStorageOptions)
+		}
+		if err == nil {
+			var multi *MultiStorage
+			if multi, err = ConnectMultiBackend(urls, opts); err == nil {
+				backend = multi
+			}
+		}
+
 	default:
 		err = errors.New("unknown URL scheme: '" + parsed.Scheme + "'")
 	}
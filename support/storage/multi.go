@@ -0,0 +1,629 @@
+package storage
+
+import (
+	"bytes"
+	stderrors "errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+)
+
+// DefaultMultiStorageRetryInterval is the default time after which a backend
+// which failed is tried again in its configured position.
+const DefaultMultiStorageRetryInterval = time.Minute
+
+// MultiStorageOptions configures a MultiStorage.
+type MultiStorageOptions struct {
+	// RetryInterval is the time after which an unhealthy backend is tried
+	// again before the backends configured after it. Until then it is only
+	// read from when all the healthy backends fail. Defaults to
+	// DefaultMultiStorageRetryInterval.
+	RetryInterval time.Duration
+
+	// ConsistencyCheckInterval enables the background consistency checker
+	// when it is not zero. The checker compares the files found under
+	// ConsistencyCheckPrefixes in every backend and logs the differences.
+	ConsistencyCheckInterval time.Duration
+	// ConsistencyCheckPrefixes are the paths checked by the background
+	// consistency checker, see CheckConsistency.
+	ConsistencyCheckPrefixes []string
+	// RepairInconsistencies makes the background consistency checker copy
+	// missing files to the backends lacking them.
+	RepairInconsistencies bool
+}
+
+// MultiStorageBackend is one of the backends of a MultiStorage.
+type MultiStorageBackend struct {
+	// Name identifies the backend in logs, health and consistency reports.
+	Name    string
+	Storage Storage
+}
+
+// BackendHealth is the health of a backend of a MultiStorage.
+type BackendHealth struct {
+	Name    string
+	Healthy bool
+	// ConsecutiveFailures is the number of failed operations since the last
+	// successful one.
+	ConsecutiveFailures uint64
+	LastError           string
+	LastFailure         time.Time
+}
+
+// ConsistencyReport is the result of a consistency check of a MultiStorage.
+type ConsistencyReport struct {
+	// Files is the number of distinct files found in all the backends.
+	Files int
+	// Missing contains, by backend name, the files found in other backends
+	// but not in the backend.
+	Missing map[string][]string
+	// Repaired contains, by backend name, the missing files which have been
+	// copied to the backend.
+	Repaired map[string][]string
+}
+
+// Consistent returns true if no backend is missing files.
+func (r ConsistencyReport) Consistent() bool {
+	for _, missing := range r.Missing {
+		if len(missing) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MultiStorage replicates files across several backends. Files are written to
+// all the backends and read from the first healthy backend, in the configured
+// order, falling back to the others when it fails or doesn't have the file.
+type MultiStorage struct {
+	backends []*multiBackend
+	options  MultiStorageOptions
+	log      *log.Entry
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+type multiBackend struct {
+	MultiStorageBackend
+
+	mutex               sync.Mutex
+	consecutiveFailures uint64
+	lastError           error
+	lastFailure         time.Time
+}
+
+// NewMultiStorage returns a Storage replicating files across the given
+// backends. It starts the background consistency checker if it is enabled in
+// the options; Close stops it.
+func NewMultiStorage(options MultiStorageOptions, backends ...MultiStorageBackend) (*MultiStorage, error) {
+	if len(backends) == 0 {
+		return nil, errors.New("at least one backend is required")
+	}
+	if options.RetryInterval == 0 {
+		options.RetryInterval = DefaultMultiStorageRetryInterval
+	}
+	if options.ConsistencyCheckInterval > 0 && len(options.ConsistencyCheckPrefixes) == 0 {
+		return nil, errors.New("consistency check prefixes are required to run the consistency checker")
+	}
+
+	m := &MultiStorage{
+		options: options,
+		log:     log.WithField("subservice", "multi-storage"),
+		done:    make(chan struct{}),
+	}
+	names := map[string]bool{}
+	for _, backend := range backends {
+		if backend.Storage == nil {
+			return nil, errors.Errorf("backend %s is nil", backend.Name)
+		}
+		if names[backend.Name] {
+			return nil, errors.Errorf("duplicate backend name %s", backend.Name)
+		}
+		names[backend.Name] = true
+		m.backends = append(m.backends, &multiBackend{MultiStorageBackend: backend})
+	}
+
+	if options.ConsistencyCheckInterval > 0 {
+		m.wg.Add(1)
+		go m.runConsistencyChecker()
+	}
+	return m, nil
+}
+
+// ConnectMultiBackend connects to every url with ConnectBackend and returns a
+// MultiStorage replicating files across them.
+func ConnectMultiBackend(urls []string, opts ConnectOptions) (*MultiStorage, error) {
+	backendOpts := opts
+	backendOpts.Wrap = nil
+
+	var backends []MultiStorageBackend
+	for _, u := range urls {
+		backend, err := ConnectBackend(u, backendOpts)
+		if err != nil {
+			for _, connected := range backends {
+				connected.Storage.Close()
+			}
+			return nil, errors.Wrapf(err, "could not connect to %s", redactURL(u))
+		}
+		backends = append(backends, MultiStorageBackend{Name: redactURL(u), Storage: backend})
+	}
+	return NewMultiStorage(opts.MultiStorageOptions, backends...)
+}
+
+// multiBackendURLs returns the urls of the backends of a multi:// url, ex.
+// multi://?backend=s3://bucket/path&backend=gcs://bucket/path. Urls containing
+// a query must be escaped.
+func multiBackendURLs(parsed *url.URL) ([]string, error) {
+	urls := parsed.Query()["backend"]
+	if len(urls) == 0 {
+		return nil, errors.New("multi URL requires at least one backend query parameter")
+	}
+	for _, u := range urls {
+		if strings.HasPrefix(u, "multi:") {
+			return nil, errors.New("multi URLs cannot be nested")
+		}
+	}
+	return urls, nil
+}
+
+// multiURLOptions returns the options of a multi:// url, which override the
+// given ones, ex.
+// multi://?backend=...&retry_interval=30s&consistency_check_interval=1h&consistency_check_prefix=ledger&repair_inconsistencies=true.
+// The consistency_check_prefix parameter can be repeated.
+func multiURLOptions(parsed *url.URL, options MultiStorageOptions) (MultiStorageOptions, error) {
+	query := parsed.Query()
+	var err error
+	if value := query.Get("retry_interval"); value != "" {
+		if options.RetryInterval, err = time.ParseDuration(value); err != nil {
+			return options, errors.Wrap(err, "invalid retry_interval")
+		}
+	}
+	if value := query.Get("consistency_check_interval"); value != "" {
+		if options.ConsistencyCheckInterval, err = time.ParseDuration(value); err != nil {
+			return options, errors.Wrap(err, "invalid consistency_check_interval")
+		}
+	}
+	if prefixes, ok := query["consistency_check_prefix"]; ok {
+		options.ConsistencyCheckPrefixes = prefixes
+	}
+	if value := query.Get("repair_inconsistencies"); value != "" {
+		if options.RepairInconsistencies, err = strconv.ParseBool(value); err != nil {
+			return options, errors.Wrap(err, "invalid repair_inconsistencies")
+		}
+	}
+	return options, nil
+}
+
+// redactURL removes the credentials of a url so it can be logged.
+func redactURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	return parsed.Redacted()
+}
+
+// Health returns the health of every backend in the configured order.
+func (m *MultiStorage) Health() []BackendHealth {
+	health := make([]BackendHealth, 0, len(m.backends))
+	for _, backend := range m.backends {
+		backend.mutex.Lock()
+		h := BackendHealth{
+			Name:                backend.Name,
+			Healthy:             backend.consecutiveFailures == 0,
+			ConsecutiveFailures: backend.consecutiveFailures,
+			LastFailure:         backend.lastFailure,
+		}
+		if backend.lastError != nil {
+			h.LastError = backend.lastError.Error()
+		}
+		backend.mutex.Unlock()
+		health = append(health, h)
+	}
+	return health
+}
+
+func (b *multiBackend) record(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err == nil {
+		b.consecutiveFailures = 0
+		return
+	}
+	b.consecutiveFailures++
+	b.lastError = err
+	b.lastFailure = time.Now()
+}
+
+// available returns true if the backend is healthy or if it failed longer
+// than retryInterval ago.
+func (b *multiBackend) available(retryInterval time.Duration) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.consecutiveFailures == 0 || time.Since(b.lastFailure) >= retryInterval
+}
+
+// readOrder returns the available backends in the configured order followed
+// by the unavailable ones, which are only used as a last resort.
+func (m *MultiStorage) readOrder() []*multiBackend {
+	ordered := make([]*multiBackend, 0, len(m.backends))
+	var unavailable []*multiBackend
+	for _, backend := range m.backends {
+		if backend.available(m.options.RetryInterval) {
+			ordered = append(ordered, backend)
+		} else {
+			unavailable = append(unavailable, backend)
+		}
+	}
+	return append(ordered, unavailable...)
+}
+
+func isNotExist(err error) bool {
+	return os.IsNotExist(err) || stderrors.Is(err, fs.ErrNotExist)
+}
+
+// read calls fn on every backend, in read order, until it succeeds. Backends
+// which don't have the file are skipped without being marked as unhealthy.
+// The returned error is fs.ErrNotExist if no backend has the file.
+func (m *MultiStorage) read(pth string, fn func(Storage) error) error {
+	var firstErr error
+	for _, backend := range m.readOrder() {
+		err := fn(backend.Storage)
+		if err == nil {
+			backend.record(nil)
+			return nil
+		}
+
+		if isNotExist(err) {
+			// the backend is reachable, the file may only be missing
+			// from a replica which hasn't caught up yet
+			backend.record(nil)
+			continue
+		}
+
+		backend.record(err)
+		m.log.WithField("backend", backend.Name).
+			WithField("path", pth).
+			WithError(err).
+			Warn("backend failed, trying the next one")
+		if firstErr == nil {
+			firstErr = errors.Wrapf(err, "backend %s", backend.Name)
+		}
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return fs.ErrNotExist
+}
+
+// Exists returns true if any backend has the file.
+func (m *MultiStorage) Exists(pth string) (bool, error) {
+	err := m.read(pth, func(s Storage) error {
+		exists, err := s.Exists(pth)
+		if err == nil && !exists {
+			return fs.ErrNotExist
+		}
+		return err
+	})
+	if isNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Size returns the size of the file in the first backend which has it.
+func (m *MultiStorage) Size(pth string) (int64, error) {
+	var size int64
+	err := m.read(pth, func(s Storage) error {
+		var err error
+		size, err = s.Size(pth)
+		return err
+	})
+	return size, err
+}
+
+// GetFile returns the file from the first backend which has it.
+func (m *MultiStorage) GetFile(pth string) (io.ReadCloser, error) {
+	var file io.ReadCloser
+	err := m.read(pth, func(s Storage) error {
+		var err error
+		file, err = s.GetFile(pth)
+		return err
+	})
+	return file, err
+}
+
+// GetFileRange returns part of the file from the first backend which has it.
+func (m *MultiStorage) GetFileRange(pth string, offset, length int64) (io.ReadCloser, error) {
+	var file io.ReadCloser
+	err := m.read(pth, func(s Storage) error {
+		var err error
+		file, err = GetFileRange(s, pth, offset, length)
+		return err
+	})
+	return file, err
+}
+
+// RemoveFile removes the file from all the backends. Backends which don't
+// have the file are ignored, but all of them must be FileRemovers.
+func (m *MultiStorage) RemoveFile(pth string) error {
+	var failed []string
+	for _, backend := range m.backends {
+		remover, ok := backend.Storage.(FileRemover)
+		if !ok {
+			failed = append(failed, "backend "+backend.Name+" can't remove files")
+			continue
+		}
+		if err := remover.RemoveFile(pth); err != nil && !isNotExist(err) {
+			backend.record(err)
+			failed = append(failed, errors.Wrapf(err, "backend %s", backend.Name).Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("could not remove %s: %s", pth, strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// PutFile writes the file to all the backends concurrently. An error is
+// returned if any of the writes fails, in which case the file may be present
+// in some of the backends only.
+func (m *MultiStorage) PutFile(pth string, in io.ReadCloser) error {
+	// the input can only be read once so it's buffered for every backend
+	data, err := io.ReadAll(in)
+	in.Close()
+	if err != nil {
+		return errors.Wrap(err, "could not read file")
+	}
+
+	errs := make([]error, len(m.backends))
+	var wg sync.WaitGroup
+	for i, backend := range m.backends {
+		wg.Add(1)
+		go func(i int, backend *multiBackend) {
+			defer wg.Done()
+			err := backend.Storage.PutFile(pth, io.NopCloser(bytes.NewReader(data)))
+			backend.record(err)
+			if err != nil {
+				errs[i] = errors.Wrapf(err, "backend %s", backend.Name)
+			}
+		}(i, backend)
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("could not write %s: %s", pth, strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// ListFiles lists the files of the first available backend which can list
+// files.
+func (m *MultiStorage) ListFiles(pth string) (chan string, chan error) {
+	for _, backend := range m.readOrder() {
+		if backend.Storage.CanListFiles() {
+			return backend.Storage.ListFiles(pth)
+		}
+	}
+
+	ch := make(chan string)
+	errs := make(chan error, 1)
+	errs <- errors.New("no backend can list files")
+	close(ch)
+	close(errs)
+	return ch, errs
+}
+
+// CanListFiles returns true if any backend can list files.
+func (m *MultiStorage) CanListFiles() bool {
+	for _, backend := range m.backends {
+		if backend.Storage.CanListFiles() {
+			return true
+		}
+	}
+	return false
+}
+
+// Close stops the consistency checker and closes all the backends. Closing
+// it again does nothing.
+func (m *MultiStorage) Close() error {
+	var failed []string
+	m.closeOnce.Do(func() {
+		close(m.done)
+		m.wg.Wait()
+
+		for _, backend := range m.backends {
+			if err := backend.Storage.Close(); err != nil {
+				failed = append(failed, backend.Name+": "+err.Error())
+			}
+		}
+	})
+	if len(failed) > 0 {
+		return errors.Errorf("could not close backends: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// CheckConsistency compares the files found under prefix in every backend
+// which can list files and reports the files missing from each of them.
+// Backends which can't list files are checked with Exists. If repair is true
+// the missing files are copied from a backend which has them.
+//
+// Backends list files with their own root path, so listed paths are made
+// relative again by looking for prefix in them. prefix must therefore be a
+// non-empty directory, ex. "ledger" or "bucket" in a history archive.
+func (m *MultiStorage) CheckConsistency(prefix string, repair bool) (ConsistencyReport, error) {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ConsistencyReport{}, errors.New("prefix cannot be empty")
+	}
+
+	listings := make([]map[string]bool, len(m.backends))
+	all := map[string]bool{}
+	for i, backend := range m.backends {
+		if !backend.Storage.CanListFiles() {
+			continue
+		}
+		files, err := listRelative(backend.Storage, prefix)
+		backend.record(err)
+		if err != nil {
+			return ConsistencyReport{}, errors.Wrapf(err, "could not list files of backend %s", backend.Name)
+		}
+		listings[i] = files
+		for file := range files {
+			all[file] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(all))
+	for file := range all {
+		sorted = append(sorted, file)
+	}
+	sort.Strings(sorted)
+
+	report := ConsistencyReport{
+		Files:    len(sorted),
+		Missing:  map[string][]string{},
+		Repaired: map[string][]string{},
+	}
+	for i, backend := range m.backends {
+		for _, file := range sorted {
+			if listings[i] != nil {
+				if listings[i][file] {
+					continue
+				}
+			} else {
+				exists, err := backend.Storage.Exists(file)
+				backend.record(err)
+				if err != nil {
+					return report, errors.Wrapf(err, "could not check %s in backend %s", file, backend.Name)
+				}
+				if exists {
+					continue
+				}
+			}
+			report.Missing[backend.Name] = append(report.Missing[backend.Name], file)
+		}
+	}
+
+	if repair {
+		for i, backend := range m.backends {
+			for _, file := range report.Missing[backend.Name] {
+				if err := m.repair(i, listings, file); err != nil {
+					return report, errors.Wrapf(err, "could not repair %s in backend %s", file, backend.Name)
+				}
+				report.Repaired[backend.Name] = append(report.Repaired[backend.Name], file)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// repair copies file to the backend at index target from the first other
+// backend which listed it.
+func (m *MultiStorage) repair(target int, listings []map[string]bool, file string) error {
+	for i, source := range m.backends {
+		if i == target || !listings[i][file] {
+			continue
+		}
+		in, err := source.Storage.GetFile(file)
+		source.record(err)
+		if err != nil {
+			return errors.Wrapf(err, "could not read from backend %s", source.Name)
+		}
+		err = m.backends[target].Storage.PutFile(file, in)
+		m.backends[target].record(err)
+		return err
+	}
+	return errors.New("no backend has the file")
+}
+
+// listRelative lists the files under prefix, relative to the root of the
+// backend.
+func listRelative(s Storage, prefix string) (map[string]bool, error) {
+	files := map[string]bool{}
+	ch, errs := s.ListFiles(prefix)
+	for ch != nil || errs != nil {
+		select {
+		case file, ok := <-ch:
+			if !ok {
+				ch = nil
+				continue
+			}
+			files[relativePath(file, prefix)] = true
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// relativePath strips the root path of a backend, which precedes prefix, from
+// a listed path.
+func relativePath(listed, prefix string) string {
+	listed = strings.TrimPrefix(listed, "/")
+	if strings.HasPrefix(listed, prefix+"/") {
+		return listed
+	}
+	if i := strings.Index(listed, "/"+prefix+"/"); i >= 0 {
+		return listed[i+1:]
+	}
+	return listed
+}
+
+func (m *MultiStorage) runConsistencyChecker() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.options.ConsistencyCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, prefix := range m.options.ConsistencyCheckPrefixes {
+				report, err := m.CheckConsistency(prefix, m.options.RepairInconsistencies)
+				L := m.log.WithField("prefix", prefix)
+				if err != nil {
+					L.WithError(err).Error("consistency check failed")
+					continue
+				}
+				for name, missing := range report.Missing {
+					L.WithField("backend", name).
+						WithField("missing", len(missing)).
+						WithField("repaired", len(report.Repaired[name])).
+						Warn("backend is missing files")
+				}
+				L.WithField("files", report.Files).
+					WithField("consistent", report.Consistent()).
+					Info("consistency check finished")
+			}
+		case <-m.done:
+			return
+		}
+	}
+}
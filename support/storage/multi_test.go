@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStorage fails every operation while failing is true.
+type failingStorage struct {
+	Storage
+	failing bool
+	calls   int
+}
+
+var errUnavailable = errors.New("backend unavailable")
+
+func (s *failingStorage) Exists(pth string) (bool, error) {
+	s.calls++
+	if s.failing {
+		return false, errUnavailable
+	}
+	return s.Storage.Exists(pth)
+}
+
+func (s *failingStorage) GetFile(pth string) (io.ReadCloser, error) {
+	s.calls++
+	if s.failing {
+		return nil, errUnavailable
+	}
+	return s.Storage.GetFile(pth)
+}
+
+func (s *failingStorage) PutFile(pth string, in io.ReadCloser) error {
+	s.calls++
+	if s.failing {
+		in.Close()
+		return errUnavailable
+	}
+	return s.Storage.PutFile(pth, in)
+}
+
+func putString(t *testing.T, s Storage, pth, content string) {
+	require.NoError(t, s.PutFile(pth, io.NopCloser(bytes.NewBufferString(content))))
+}
+
+func getString(t *testing.T, s Storage, pth string) string {
+	file, err := s.GetFile(pth)
+	require.NoError(t, err)
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func newTestMultiStorage(t *testing.T, options MultiStorageOptions) (*MultiStorage, []string, *failingStorage) {
+	dirs := []string{t.TempDir(), t.TempDir()}
+	first := &failingStorage{Storage: NewFilesystemStorage(dirs[0])}
+	multi, err := NewMultiStorage(options,
+		MultiStorageBackend{Name: "first", Storage: first},
+		MultiStorageBackend{Name: "second", Storage: NewFilesystemStorage(dirs[1])},
+	)
+	require.NoError(t, err)
+	return multi, dirs, first
+}
+
+func TestMultiStorageWritesToAllBackends(t *testing.T) {
+	multi, dirs, _ := newTestMultiStorage(t, MultiStorageOptions{})
+	defer multi.Close()
+
+	putString(t, multi, "ledger/00/00/00/ledger-0000003f.xdr.gz", "content")
+	for _, dir := range dirs {
+		content, err := os.ReadFile(filepath.Join(dir, "ledger/00/00/00/ledger-0000003f.xdr.gz"))
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(content))
+	}
+}
+
+func TestMultiStorageReadsFromNextBackend(t *testing.T) {
+	multi, _, first := newTestMultiStorage(t, MultiStorageOptions{})
+	defer multi.Close()
+
+	putString(t, multi, "a/file", "content")
+
+	first.failing = true
+	assert.Equal(t, "content", getString(t, multi, "a/file"))
+	exists, err := multi.Exists("a/file")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	health := multi.Health()
+	require.Len(t, health, 2)
+	assert.Equal(t, "first", health[0].Name)
+	assert.False(t, health[0].Healthy)
+	assert.Equal(t, uint64(1), health[0].ConsecutiveFailures)
+	assert.Equal(t, errUnavailable.Error(), health[0].LastError)
+	assert.True(t, health[1].Healthy)
+
+	// the unhealthy backend is skipped until the retry interval elapses
+	calls := first.calls
+	assert.Equal(t, "content", getString(t, multi, "a/file"))
+	assert.Equal(t, calls, first.calls)
+}
+
+func TestMultiStorageRetriesUnhealthyBackend(t *testing.T) {
+	multi, _, first := newTestMultiStorage(t, MultiStorageOptions{RetryInterval: 1})
+	defer multi.Close()
+
+	putString(t, multi, "a/file", "content")
+	first.failing = true
+	assert.Equal(t, "content", getString(t, multi, "a/file"))
+	assert.False(t, multi.Health()[0].Healthy)
+
+	first.failing = false
+	assert.Equal(t, "content", getString(t, multi, "a/file"))
+	assert.True(t, multi.Health()[0].Healthy)
+}
+
+func TestMultiStorageMissingFile(t *testing.T) {
+	multi, dirs, _ := newTestMultiStorage(t, MultiStorageOptions{})
+	defer multi.Close()
+
+	exists, err := multi.Exists("a/file")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	_, err = multi.GetFile("a/file")
+	assert.True(t, os.IsNotExist(err))
+
+	// a file missing from the first backend is read from the second one
+	// without marking the first one unhealthy
+	require.NoError(t, os.MkdirAll(filepath.Join(dirs[1], "a"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dirs[1], "a/file"), []byte("content"), 0644))
+	assert.Equal(t, "content", getString(t, multi, "a/file"))
+	for _, health := range multi.Health() {
+		assert.True(t, health.Healthy)
+	}
+}
+
+func TestMultiStoragePutFileFailure(t *testing.T) {
+	multi, dirs, first := newTestMultiStorage(t, MultiStorageOptions{})
+	defer multi.Close()
+
+	first.failing = true
+	err := multi.PutFile("a/file", io.NopCloser(bytes.NewBufferString("content")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backend first")
+
+	// the write succeeded in the healthy backend
+	_, err = os.Stat(filepath.Join(dirs[1], "a/file"))
+	assert.NoError(t, err)
+	assert.False(t, multi.Health()[0].Healthy)
+}
+
+func TestMultiStorageCheckConsistency(t *testing.T) {
+	multi, dirs, _ := newTestMultiStorage(t, MultiStorageOptions{})
+	defer multi.Close()
+
+	putString(t, multi, "ledger/00/both", "both")
+	require.NoError(t, os.WriteFile(filepath.Join(dirs[0], "ledger/00/first"), []byte("first"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dirs[1], "ledger/00/second"), []byte("second"), 0644))
+
+	report, err := multi.CheckConsistency("ledger", false)
+	require.NoError(t, err)
+	assert.False(t, report.Consistent())
+	assert.Equal(t, 3, report.Files)
+	assert.Equal(t, map[string][]string{
+		"first":  {"ledger/00/second"},
+		"second": {"ledger/00/first"},
+	}, report.Missing)
+	assert.Empty(t, report.Repaired)
+
+	report, err = multi.CheckConsistency("ledger", true)
+	require.NoError(t, err)
+	assert.Equal(t, report.Missing, report.Repaired)
+	content, err := os.ReadFile(filepath.Join(dirs[0], "ledger/00/second"))
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+
+	report, err = multi.CheckConsistency("ledger", false)
+	require.NoError(t, err)
+	assert.True(t, report.Consistent())
+
+	_, err = multi.CheckConsistency("", false)
+	assert.Error(t, err)
+}
+
+func TestConnectMultiBackend(t *testing.T) {
+	dirs := []string{t.TempDir(), t.TempDir()}
+	query := url.Values{}
+	for _, dir := range dirs {
+		query.Add("backend", "file://"+dir)
+	}
+
+	backend, err := ConnectBackend("multi://?"+query.Encode(), ConnectOptions{})
+	require.NoError(t, err)
+	defer backend.Close()
+	require.IsType(t, &MultiStorage{}, backend)
+	assert.Len(t, backend.(*MultiStorage).Health(), 2)
+
+	putString(t, backend, "a/file", "content")
+	for _, dir := range dirs {
+		_, err = os.Stat(filepath.Join(dir, "a/file"))
+		assert.NoError(t, err)
+	}
+
+	_, err = ConnectBackend("multi://", ConnectOptions{})
+	assert.EqualError(t, err, "multi URL requires at least one backend query parameter")
+	_, err = ConnectBackend("multi://?backend=multi://", ConnectOptions{})
+	assert.EqualError(t, err, "multi URLs cannot be nested")
+}
+
+func TestMultiStorageCloseTwice(t *testing.T) {
+	multi, _, _ := newTestMultiStorage(t, MultiStorageOptions{})
+	require.NoError(t, multi.Close())
+	require.NoError(t, multi.Close())
+}
+
+func TestMultiStorageGetFileRange(t *testing.T) {
+	multi, _, first := newTestMultiStorage(t, MultiStorageOptions{})
+	defer multi.Close()
+
+	putString(t, multi, "a/file", "0123456789")
+
+	// the first backend isn't a RangeReader, so the range is read from the
+	// whole file
+	for _, failing := range []bool{false, true} {
+		first.failing = failing
+		file, err := multi.GetFileRange("a/file", 3, 4)
+		require.NoError(t, err)
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+		assert.Equal(t, "3456", string(content))
+	}
+
+	first.failing = false
+	_, err := multi.GetFileRange("a/missing", 0, 1)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMultiStorageRemoveFile(t *testing.T) {
+	dirs := []string{t.TempDir(), t.TempDir()}
+	multi, err := NewMultiStorage(MultiStorageOptions{},
+		MultiStorageBackend{Name: "first", Storage: NewFilesystemStorage(dirs[0])},
+		MultiStorageBackend{Name: "second", Storage: NewFilesystemStorage(dirs[1])},
+	)
+	require.NoError(t, err)
+	defer multi.Close()
+
+	putString(t, multi, "a/file", "content")
+	require.NoError(t, os.Remove(filepath.Join(dirs[1], "a/file")))
+
+	// files missing from some of the backends are still removed from the rest
+	require.NoError(t, multi.RemoveFile("a/file"))
+	exists, err := multi.Exists("a/file")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// backends which can't remove files fail the removal
+	failing, _, _ := newTestMultiStorage(t, MultiStorageOptions{})
+	defer failing.Close()
+	putString(t, failing, "a/file", "content")
+	err = failing.RemoveFile("a/file")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backend first can't remove files")
+}
+
+func TestConnectMultiBackendURLOptions(t *testing.T) {
+	query := url.Values{}
+	query.Add("backend", "file://"+t.TempDir())
+	query.Add("retry_interval", "30s")
+	query.Add("consistency_check_interval", "1h")
+	query.Add("consistency_check_prefix", "ledger")
+	query.Add("consistency_check_prefix", "results")
+	query.Add("repair_inconsistencies", "true")
+
+	backend, err := ConnectBackend("multi://?"+query.Encode(), ConnectOptions{
+		MultiStorageOptions: MultiStorageOptions{RetryInterval: time.Minute},
+	})
+	require.NoError(t, err)
+	defer backend.Close()
+	assert.Equal(t, MultiStorageOptions{
+		RetryInterval:            30 * time.Second,
+		ConsistencyCheckInterval: time.Hour,
+		ConsistencyCheckPrefixes: []string{"ledger", "results"},
+		RepairInconsistencies:    true,
+	}, backend.(*MultiStorage).options)
+
+	query.Set("retry_interval", "soon")
+	_, err = ConnectBackend("multi://?"+query.Encode(), ConnectOptions{})
+	assert.EqualError(t, err, `invalid retry_interval: time: invalid duration "soon"`)
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -103,6 +104,27 @@ func (b *S3Storage) GetFile(pth string) (io.ReadCloser, error) {
 	return resp, nil
 }
 
+// GetFileRange returns `length` bytes of the file starting at `offset` using
+// a ranged GET.
+func (b *S3Storage) GetFileRange(pth string, offset, length int64) (io.ReadCloser, error) {
+	key := path.Join(b.prefix, pth)
+	params := &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+	}
+
+	resp, err := b.s3HttpProxy().Send(params)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 func (b *S3Storage) Head(pth string) (*http.Response, error) {
 	key := path.Join(b.prefix, pth)
 	params := &s3.HeadObjectInput{
@@ -198,6 +220,24 @@ func (b *S3Storage) PutFile(pth string, in io.ReadCloser) error {
 	return err
 }
 
+func (b *S3Storage) RemoveFile(pth string) error {
+	key := path.Join(b.prefix, pth)
+	params := &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	}
+	req, _ := b.svc.DeleteObjectRequest(params)
+	if b.unsignedRequests {
+		req.Handlers.Sign.Clear() // makes this request unsigned
+	}
+	req.SetContext(b.ctx)
+	logReq(req.HTTPRequest)
+	err := req.Send()
+	logResp(req.HTTPResponse)
+
+	return err
+}
+
 func (b *S3Storage) ListFiles(pth string) (chan string, chan error) {
 	prefix := path.Join(b.prefix, pth)
 	ch := make(chan string)
@@ -48,6 +48,8 @@ type Options struct {
 	Last        int
 	Recent      bool
 	Profile     bool
+	Resume      bool
+	StateFile   string
 	Debug       bool
 	Trace       bool
 	CommandOpts historyarchive.CommandOptions
@@ -94,6 +96,16 @@ func (opts *Options) SetRange(srcArch *historyarchive.Archive, dstArch *historya
 
 }
 
+// SetProgress configures where the progress of mirror and repair is saved so
+// an interrupted run can be resumed.
+func (opts *Options) SetProgress(dstArch *historyarchive.Archive) {
+	if opts.StateFile != "" {
+		opts.CommandOpts.Progress = historyarchive.NewFileProgressStore(opts.StateFile)
+	} else if opts.Resume {
+		opts.CommandOpts.Progress = historyarchive.NewArchiveProgressStore(dstArch)
+	}
+}
+
 func (opts *Options) MaybeProfile() {
 	if opts.Profile {
 		go func() {
@@ -146,6 +158,7 @@ func mirror(src string, dst string, opts *Options) {
 	srcArch := historyarchive.MustConnect(src, opts.ConnectOpts)
 	dstArch := historyarchive.MustConnect(dst, opts.ConnectOpts)
 	opts.SetRange(srcArch, dstArch)
+	opts.SetProgress(dstArch)
 	log.Printf("mirroring %v -> %v\n", src, dst)
 	e := historyarchive.Mirror(srcArch, dstArch, &opts.CommandOpts)
 	if e != nil {
@@ -157,6 +170,7 @@ func repair(src string, dst string, opts *Options) {
 	srcArch := historyarchive.MustConnect(src, opts.ConnectOpts)
 	dstArch := historyarchive.MustConnect(dst, opts.ConnectOpts)
 	opts.SetRange(srcArch, dstArch)
+	opts.SetProgress(dstArch)
 	log.Printf("repairing %v -> %v\n", src, dst)
 	e := historyarchive.Repair(srcArch, dstArch, &opts.CommandOpts)
 	if e != nil {
@@ -266,6 +280,41 @@ func main() {
 		"skip optional (SCP) checkpoint files",
 	)
 
+	rootCmd.PersistentFlags().BoolVar(
+		&opts.Resume,
+		"resume",
+		false,
+		"save the progress of mirror and repair in the destination archive and resume from it",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&opts.StateFile,
+		"state-file",
+		"",
+		"save the progress of mirror and repair in a local file and resume from it",
+	)
+
+	rootCmd.PersistentFlags().BoolVar(
+		&opts.CommandOpts.SinceLastRun,
+		"since-last-run",
+		false,
+		"only mirror checkpoints newer than the destination's current ledger",
+	)
+
+	rootCmd.PersistentFlags().Float64Var(
+		&opts.CommandOpts.MaxFilesPerSecond,
+		"max-files-per-second",
+		0,
+		"maximum number of files copied per second, 0 for no limit",
+	)
+
+	rootCmd.PersistentFlags().Int64Var(
+		&opts.CommandOpts.MaxBytesPerSecond,
+		"max-bytes-per-second",
+		0,
+		"maximum number of bytes copied per second, 0 for no limit",
+	)
+
 	rootCmd.PersistentFlags().BoolVar(
 		&opts.Profile,
 		"profile",
@@ -0,0 +1,518 @@
+/*
+Package multisig helps co-signers of a transaction coordinate their signatures.
+
+Check loads the signers and thresholds of every account which has to authorize a
+transaction and reports whether the signatures attached to the transaction are
+enough to authorize it, which signatures are valid, which are extraneous and
+which signers of the accounts are still missing. Merge combines the signatures
+of envelopes signed separately by several co-signers into a single envelope.
+*/
+package multisig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/hcnet/go/clients/auroraclient"
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/strkey"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/hcnet/go/xdr"
+)
+
+// ThresholdLevel is the threshold category of an operation: the weight of the
+// signatures authorizing the operation must reach the corresponding threshold
+// of its source account.
+type ThresholdLevel int
+
+const (
+	// ThresholdLevelLow requires the low threshold of the source account.
+	ThresholdLevelLow ThresholdLevel = iota + 1
+	// ThresholdLevelMedium requires the medium threshold of the source account.
+	ThresholdLevelMedium
+	// ThresholdLevelHigh requires the high threshold of the source account.
+	ThresholdLevelHigh
+)
+
+// String returns the name of the threshold level.
+func (l ThresholdLevel) String() string {
+	switch l {
+	case ThresholdLevelLow:
+		return "low"
+	case ThresholdLevelMedium:
+		return "medium"
+	case ThresholdLevelHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// threshold returns the threshold of the account for the level.
+func (l ThresholdLevel) threshold(thresholds aurora.AccountThresholds) int32 {
+	switch l {
+	case ThresholdLevelLow:
+		return int32(thresholds.LowThreshold)
+	case ThresholdLevelHigh:
+		return int32(thresholds.HighThreshold)
+	default:
+		return int32(thresholds.MedThreshold)
+	}
+}
+
+// OperationThresholdLevel returns the threshold level required by an
+// operation.
+func OperationThresholdLevel(op txnbuild.Operation) ThresholdLevel {
+	switch o := op.(type) {
+	case *txnbuild.AllowTrust, *txnbuild.SetTrustLineFlags, *txnbuild.BumpSequence,
+		*txnbuild.ClaimClaimableBalance, *txnbuild.Inflation, *txnbuild.ExtendFootprintTtl,
+		*txnbuild.RestoreFootprint:
+		return ThresholdLevelLow
+	case *txnbuild.AccountMerge:
+		return ThresholdLevelHigh
+	case *txnbuild.SetOptions:
+		// changing the signers or the thresholds of an account requires the
+		// high threshold, any other change only the medium threshold
+		if o.MasterWeight != nil || o.LowThreshold != nil || o.MediumThreshold != nil ||
+			o.HighThreshold != nil || o.Signer != nil {
+			return ThresholdLevelHigh
+		}
+		return ThresholdLevelMedium
+	default:
+		return ThresholdLevelMedium
+	}
+}
+
+// AccountLoader loads the details of accounts, it is implemented by
+// auroraclient.Client.
+type AccountLoader interface {
+	AccountDetail(request auroraclient.AccountRequest) (aurora.Account, error)
+}
+
+// Requirement is a threshold level the source account of an operation must
+// reach. The transaction itself requires the low threshold of its source
+// account, or of its fee account for fee bump transactions; those
+// requirements have an Operation of -1.
+type Requirement struct {
+	Operation int
+	Account   string
+	Level     ThresholdLevel
+}
+
+// AccountStatus describes the authorization of an account.
+type AccountStatus struct {
+	Account string
+	// Level is the highest threshold level required from the account and
+	// Threshold the weight corresponding to that level.
+	Level     ThresholdLevel
+	Threshold int32
+	// Weight is the total weight of the signers of the account which signed
+	// the transaction.
+	Weight int32
+	// Signers are the keys of the signers of the account which signed the
+	// transaction.
+	Signers []string
+	// Missing are the keys of the signers of the account which could still
+	// sign the transaction. It is only set when the account is not
+	// satisfied.
+	Missing   []string
+	Satisfied bool
+}
+
+// Signature is a valid signature of a transaction.
+type Signature struct {
+	// Index is the position of the signature in the transaction signatures.
+	Index int
+	// Signer is the key of the signer which made the signature.
+	Signer string
+	// Accounts are the accounts the signer is a signer of.
+	Accounts []string
+}
+
+// Report describes the authorization of a transaction.
+type Report struct {
+	// Hash is the hex encoded hash of the transaction.
+	Hash         string
+	Requirements []Requirement
+	// Accounts contains the status of every account which has to authorize
+	// the transaction, sorted by account id.
+	Accounts []AccountStatus
+	Valid    []Signature
+	// Extraneous are the indexes of the signatures which do not match any
+	// signer of the accounts.
+	Extraneous []int
+	// Authorized is true when all the accounts are satisfied, and for fee
+	// bump transactions when the inner transaction is authorized too.
+	Authorized bool
+	// Inner is the report of the inner transaction of a fee bump
+	// transaction.
+	Inner *Report
+}
+
+// Check loads the accounts which have to authorize the transaction with the
+// client and reports whether the transaction is authorized.
+func Check(client AccountLoader, network string, tx *txnbuild.GenericTransaction) (Report, error) {
+	addresses, err := RequiredAccounts(tx)
+	if err != nil {
+		return Report{}, err
+	}
+
+	accounts := make(map[string]aurora.Account, len(addresses))
+	for _, address := range addresses {
+		account, err := client.AccountDetail(auroraclient.AccountRequest{AccountID: address})
+		if err != nil {
+			return Report{}, errors.Wrapf(err, "could not load account %s", address)
+		}
+		accounts[address] = account
+	}
+	return CheckAccounts(network, tx, accounts)
+}
+
+// RequiredAccounts returns the ids of the accounts which have to authorize the
+// transaction, including the accounts of the inner transaction of fee bump
+// transactions.
+func RequiredAccounts(tx *txnbuild.GenericTransaction) ([]string, error) {
+	seen := map[string]bool{}
+	var addresses []string
+	add := func(requirements []Requirement) {
+		for _, requirement := range requirements {
+			if !seen[requirement.Account] {
+				seen[requirement.Account] = true
+				addresses = append(addresses, requirement.Account)
+			}
+		}
+	}
+
+	if feeBump, ok := tx.FeeBump(); ok {
+		requirements, err := feeBumpRequirements(feeBump)
+		if err != nil {
+			return nil, err
+		}
+		add(requirements)
+		requirements, err = transactionRequirements(feeBump.InnerTransaction())
+		if err != nil {
+			return nil, err
+		}
+		add(requirements)
+		return addresses, nil
+	}
+
+	inner, ok := tx.Transaction()
+	if !ok {
+		return nil, errors.New("transaction is empty")
+	}
+	requirements, err := transactionRequirements(inner)
+	if err != nil {
+		return nil, err
+	}
+	add(requirements)
+	return addresses, nil
+}
+
+// CheckAccounts reports whether the transaction is authorized by the given
+// accounts, keyed by account id. All the accounts returned by RequiredAccounts
+// must be present.
+func CheckAccounts(network string, tx *txnbuild.GenericTransaction, accounts map[string]aurora.Account) (Report, error) {
+	if feeBump, ok := tx.FeeBump(); ok {
+		requirements, err := feeBumpRequirements(feeBump)
+		if err != nil {
+			return Report{}, err
+		}
+		hash, err := feeBump.Hash(network)
+		if err != nil {
+			return Report{}, errors.Wrap(err, "could not hash fee bump transaction")
+		}
+		report, err := check(hash, feeBump.Signatures(), requirements, accounts)
+		if err != nil {
+			return Report{}, err
+		}
+
+		inner, err := checkTransaction(network, feeBump.InnerTransaction(), accounts)
+		if err != nil {
+			return Report{}, err
+		}
+		report.Inner = &inner
+		report.Authorized = report.Authorized && inner.Authorized
+		return report, nil
+	}
+
+	inner, ok := tx.Transaction()
+	if !ok {
+		return Report{}, errors.New("transaction is empty")
+	}
+	return checkTransaction(network, inner, accounts)
+}
+
+func checkTransaction(network string, tx *txnbuild.Transaction, accounts map[string]aurora.Account) (Report, error) {
+	requirements, err := transactionRequirements(tx)
+	if err != nil {
+		return Report{}, err
+	}
+	hash, err := tx.Hash(network)
+	if err != nil {
+		return Report{}, errors.Wrap(err, "could not hash transaction")
+	}
+	return check(hash, tx.Signatures(), requirements, accounts)
+}
+
+func transactionRequirements(tx *txnbuild.Transaction) ([]Requirement, error) {
+	source, err := accountID(tx.SourceAccount().AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid transaction source account")
+	}
+
+	requirements := []Requirement{{Operation: -1, Account: source, Level: ThresholdLevelLow}}
+	for i, op := range tx.Operations() {
+		opSource := source
+		if address := op.GetSourceAccount(); address != "" {
+			opSource, err = accountID(address)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid source account of operation %d", i)
+			}
+		}
+		requirements = append(requirements, Requirement{
+			Operation: i,
+			Account:   opSource,
+			Level:     OperationThresholdLevel(op),
+		})
+	}
+	return requirements, nil
+}
+
+func feeBumpRequirements(tx *txnbuild.FeeBumpTransaction) ([]Requirement, error) {
+	feeAccount, err := accountID(tx.FeeAccount())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid fee account")
+	}
+	return []Requirement{{Operation: -1, Account: feeAccount, Level: ThresholdLevelLow}}, nil
+}
+
+// accountID returns the account id of an address, which may be a muxed
+// account address.
+func accountID(address string) (string, error) {
+	muxed, err := xdr.AddressToMuxedAccount(address)
+	if err != nil {
+		return "", err
+	}
+	accountID := muxed.ToAccountId()
+	return accountID.Address(), nil
+}
+
+func check(
+	hash [32]byte,
+	signatures []xdr.DecoratedSignature,
+	requirements []Requirement,
+	accounts map[string]aurora.Account,
+) (Report, error) {
+	report := Report{
+		Hash:         hex.EncodeToString(hash[:]),
+		Requirements: requirements,
+		Authorized:   true,
+	}
+
+	levels := map[string]ThresholdLevel{}
+	var addresses []string
+	for _, requirement := range requirements {
+		level, ok := levels[requirement.Account]
+		if !ok {
+			addresses = append(addresses, requirement.Account)
+		}
+		if requirement.Level > level {
+			levels[requirement.Account] = requirement.Level
+		}
+	}
+	sort.Strings(addresses)
+
+	// signerAccounts maps the index of every valid signature to the signer
+	// which made it and the accounts the signer is a signer of
+	signerKeys := map[int]string{}
+	signerAccounts := map[int][]string{}
+	for _, address := range addresses {
+		account, ok := accounts[address]
+		if !ok {
+			return Report{}, errors.Errorf("account %s is not loaded", address)
+		}
+
+		status := AccountStatus{
+			Account:   address,
+			Level:     levels[address],
+			Threshold: levels[address].threshold(account.Thresholds),
+		}
+		for _, signer := range account.Signers {
+			if signer.Weight <= 0 {
+				continue
+			}
+			index, signed, err := matchSigner(hash, signatures, signer)
+			if err != nil {
+				return Report{}, errors.Wrapf(err, "invalid signer %s of account %s", signer.Key, address)
+			}
+			if !signed {
+				status.Missing = append(status.Missing, signer.Key)
+				continue
+			}
+			status.Weight += signer.Weight
+			status.Signers = append(status.Signers, signer.Key)
+			if index >= 0 {
+				signerKeys[index] = signer.Key
+				signerAccounts[index] = append(signerAccounts[index], address)
+			}
+		}
+
+		// at least one signature is required even if the threshold is zero
+		required := status.Threshold
+		if required < 1 {
+			required = 1
+		}
+		status.Satisfied = status.Weight >= required
+		if status.Satisfied {
+			status.Missing = nil
+		}
+		report.Authorized = report.Authorized && status.Satisfied
+		report.Accounts = append(report.Accounts, status)
+	}
+
+	for i := range signatures {
+		key, ok := signerKeys[i]
+		if !ok {
+			report.Extraneous = append(report.Extraneous, i)
+			continue
+		}
+		report.Valid = append(report.Valid, Signature{Index: i, Signer: key, Accounts: signerAccounts[i]})
+	}
+	return report, nil
+}
+
+// matchSigner returns whether the signer authorized the transaction with the
+// given hash, along with the index of the signature made by the signer. Pre
+// authorized transaction signers authorize the transaction without a
+// signature so their index is -1.
+func matchSigner(hash [32]byte, signatures []xdr.DecoratedSignature, signer aurora.Signer) (int, bool, error) {
+	switch signer.Type {
+	case aurora.KeyTypeNames[strkey.VersionByteAccountID]:
+		kp, err := keypair.ParseAddress(signer.Key)
+		if err != nil {
+			return -1, false, err
+		}
+		for i, signature := range signatures {
+			if signature.Hint == kp.Hint() && kp.Verify(hash[:], signature.Signature) == nil {
+				return i, true, nil
+			}
+		}
+	case aurora.KeyTypeNames[strkey.VersionByteHashTx]:
+		preAuthHash, err := strkey.Decode(strkey.VersionByteHashTx, signer.Key)
+		if err != nil {
+			return -1, false, err
+		}
+		return -1, bytes.Equal(preAuthHash, hash[:]), nil
+	case aurora.KeyTypeNames[strkey.VersionByteHashX]:
+		hashX, err := strkey.Decode(strkey.VersionByteHashX, signer.Key)
+		if err != nil {
+			return -1, false, err
+		}
+		var hint xdr.SignatureHint
+		copy(hint[:], hashX[len(hashX)-4:])
+		for i, signature := range signatures {
+			preimageHash := sha256.Sum256(signature.Signature)
+			if signature.Hint == hint && bytes.Equal(preimageHash[:], hashX) {
+				return i, true, nil
+			}
+		}
+	case aurora.KeyTypeNames[strkey.VersionByteSignedPayload]:
+		signedPayload, err := strkey.DecodeSignedPayload(signer.Key)
+		if err != nil {
+			return -1, false, err
+		}
+		kp, err := keypair.ParseAddress(signedPayload.Signer())
+		if err != nil {
+			return -1, false, err
+		}
+		payload := signedPayload.Payload()
+		hint := xdr.NewDecoratedSignatureForPayload(nil, kp.Hint(), payload).Hint
+		for i, signature := range signatures {
+			if signature.Hint == hint && kp.Verify(payload, signature.Signature) == nil {
+				return i, true, nil
+			}
+		}
+	default:
+		return -1, false, errors.Errorf("unknown signer type %s", signer.Type)
+	}
+	return -1, false, nil
+}
+
+// Merge combines the signatures of several base64 encoded envelopes of the
+// same transaction, each one signed by some of the co-signers, into a single
+// transaction. Duplicated signatures are only added once.
+func Merge(network string, envelopes ...string) (*txnbuild.GenericTransaction, error) {
+	if len(envelopes) == 0 {
+		return nil, errors.New("no envelopes to merge")
+	}
+
+	var merged *txnbuild.GenericTransaction
+	var mergedHash [32]byte
+	var signatures []xdr.DecoratedSignature
+	for i, envelope := range envelopes {
+		tx, err := txnbuild.TransactionFromXDR(envelope)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse envelope %d", i)
+		}
+		hash, err := tx.Hash(network)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not hash envelope %d", i)
+		}
+
+		var txSignatures []xdr.DecoratedSignature
+		if feeBump, ok := tx.FeeBump(); ok {
+			txSignatures = feeBump.Signatures()
+		} else if inner, ok := tx.Transaction(); ok {
+			txSignatures = inner.Signatures()
+		}
+
+		if merged == nil {
+			merged = tx
+			mergedHash = hash
+		} else if hash != mergedHash {
+			return nil, errors.Errorf("envelope %d is a different transaction", i)
+		}
+		for _, signature := range txSignatures {
+			if !containsSignature(signatures, signature) {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+
+	if feeBump, ok := merged.FeeBump(); ok {
+		feeBump, err := feeBump.ClearSignatures()
+		if err != nil {
+			return nil, err
+		}
+		feeBump, err = feeBump.AddSignatureDecorated(signatures...)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not add signatures")
+		}
+		return feeBump.ToGenericTransaction(), nil
+	}
+
+	inner, _ := merged.Transaction()
+	inner, err := inner.ClearSignatures()
+	if err != nil {
+		return nil, err
+	}
+	inner, err = inner.AddSignatureDecorated(signatures...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not add signatures")
+	}
+	return inner.ToGenericTransaction(), nil
+}
+
+func containsSignature(signatures []xdr.DecoratedSignature, signature xdr.DecoratedSignature) bool {
+	for _, s := range signatures {
+		if s.Hint == signature.Hint && bytes.Equal(s.Signature, signature.Signature) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package multisig
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/clients/auroraclient"
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/strkey"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+)
+
+func signer(key string, weight int32) aurora.Signer {
+	return aurora.Signer{Key: key, Weight: weight, Type: aurora.MustKeyTypeFromAddress(key)}
+}
+
+func newAccount(id string, low, med, high byte, signers ...aurora.Signer) aurora.Account {
+	return aurora.Account{
+		AccountID:  id,
+		Sequence:   100,
+		Thresholds: aurora.AccountThresholds{LowThreshold: low, MedThreshold: med, HighThreshold: high},
+		Signers:    signers,
+	}
+}
+
+func newTestTransaction(t *testing.T, source string, ops ...txnbuild.Operation) *txnbuild.Transaction {
+	account := txnbuild.NewSimpleAccount(source, 100)
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &account,
+		IncrementSequenceNum: true,
+		Operations:           ops,
+		BaseFee:              txnbuild.MinBaseFee,
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()},
+	})
+	require.NoError(t, err)
+	return tx
+}
+
+func TestOperationThresholdLevel(t *testing.T) {
+	weight := txnbuild.Threshold(1)
+	assert.Equal(t, ThresholdLevelLow, OperationThresholdLevel(&txnbuild.BumpSequence{}))
+	assert.Equal(t, ThresholdLevelLow, OperationThresholdLevel(&txnbuild.AllowTrust{}))
+	assert.Equal(t, ThresholdLevelMedium, OperationThresholdLevel(&txnbuild.Payment{}))
+	assert.Equal(t, ThresholdLevelMedium, OperationThresholdLevel(&txnbuild.SetOptions{}))
+	assert.Equal(t, ThresholdLevelHigh, OperationThresholdLevel(&txnbuild.SetOptions{MasterWeight: &weight}))
+	assert.Equal(t, ThresholdLevelHigh, OperationThresholdLevel(&txnbuild.AccountMerge{}))
+	assert.Equal(t, "high", ThresholdLevelHigh.String())
+}
+
+func TestCheck(t *testing.T) {
+	kp0 := keypair.MustRandom()
+	kp1 := keypair.MustRandom()
+	kp2 := keypair.MustRandom()
+	kp3 := keypair.MustRandom()
+
+	// kp0 needs kp1 to reach its medium threshold, kp2 signs on its own
+	tx := newTestTransaction(t, kp0.Address(),
+		&txnbuild.Payment{Destination: kp2.Address(), Amount: "10", Asset: txnbuild.NativeAsset{}},
+		&txnbuild.BumpSequence{BumpTo: 200, SourceAccount: kp2.Address()},
+	)
+	accounts := map[string]aurora.Account{
+		kp0.Address(): newAccount(kp0.Address(), 1, 2, 3, signer(kp0.Address(), 1), signer(kp1.Address(), 1)),
+		kp2.Address(): newAccount(kp2.Address(), 0, 0, 0, signer(kp2.Address(), 1), signer(kp3.Address(), 0)),
+	}
+
+	client := &auroraclient.MockClient{}
+	for id, account := range accounts {
+		client.On("AccountDetail", auroraclient.AccountRequest{AccountID: id}).Return(account, nil)
+	}
+
+	signed, err := tx.Sign(network.TestNetworkPassphrase, kp0, kp3)
+	require.NoError(t, err)
+	report, err := Check(client, network.TestNetworkPassphrase, signed.ToGenericTransaction())
+	require.NoError(t, err)
+	assert.False(t, report.Authorized)
+	assert.Equal(t, []Requirement{
+		{Operation: -1, Account: kp0.Address(), Level: ThresholdLevelLow},
+		{Operation: 0, Account: kp0.Address(), Level: ThresholdLevelMedium},
+		{Operation: 1, Account: kp2.Address(), Level: ThresholdLevelLow},
+	}, report.Requirements)
+	require.Len(t, report.Accounts, 2)
+	for _, status := range report.Accounts {
+		switch status.Account {
+		case kp0.Address():
+			assert.Equal(t, AccountStatus{
+				Account:   kp0.Address(),
+				Level:     ThresholdLevelMedium,
+				Threshold: 2,
+				Weight:    1,
+				Signers:   []string{kp0.Address()},
+				Missing:   []string{kp1.Address()},
+			}, status)
+		case kp2.Address():
+			// weight zero signers can't authorize transactions
+			assert.Equal(t, AccountStatus{
+				Account: kp2.Address(),
+				Level:   ThresholdLevelLow,
+				Missing: []string{kp2.Address()},
+			}, status)
+		}
+	}
+	assert.Equal(t, []Signature{{Index: 0, Signer: kp0.Address(), Accounts: []string{kp0.Address()}}}, report.Valid)
+	assert.Equal(t, []int{1}, report.Extraneous)
+
+	signed, err = tx.Sign(network.TestNetworkPassphrase, kp0, kp1, kp2)
+	require.NoError(t, err)
+	report, err = Check(client, network.TestNetworkPassphrase, signed.ToGenericTransaction())
+	require.NoError(t, err)
+	assert.True(t, report.Authorized)
+	assert.Len(t, report.Valid, 3)
+	assert.Empty(t, report.Extraneous)
+	for _, status := range report.Accounts {
+		assert.True(t, status.Satisfied)
+		assert.Empty(t, status.Missing)
+	}
+
+	client = &auroraclient.MockClient{}
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: kp0.Address()}).
+		Return(aurora.Account{}, errors.New("not found"))
+	_, err = Check(client, network.TestNetworkPassphrase, signed.ToGenericTransaction())
+	assert.EqualError(t, err, "could not load account "+kp0.Address()+": not found")
+}
+
+func TestCheckAccountsSignerTypes(t *testing.T) {
+	kp0 := keypair.MustRandom()
+	tx := newTestTransaction(t, kp0.Address(), &txnbuild.BumpSequence{BumpTo: 200})
+	hash, err := tx.Hash(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+
+	preimage := []byte("secret preimage")
+	preimageHash := sha256.Sum256(preimage)
+	hashX, err := strkey.Encode(strkey.VersionByteHashX, preimageHash[:])
+	require.NoError(t, err)
+	preAuthTx, err := strkey.Encode(strkey.VersionByteHashTx, hash[:])
+	require.NoError(t, err)
+
+	accounts := map[string]aurora.Account{
+		kp0.Address(): newAccount(kp0.Address(), 2, 2, 2, signer(kp0.Address(), 0), signer(hashX, 1), signer(preAuthTx, 1)),
+	}
+	signed, err := tx.SignHashX(preimage)
+	require.NoError(t, err)
+	report, err := CheckAccounts(network.TestNetworkPassphrase, signed.ToGenericTransaction(), accounts)
+	require.NoError(t, err)
+	assert.True(t, report.Authorized)
+	assert.Equal(t, []string{hashX, preAuthTx}, report.Accounts[0].Signers)
+	assert.Equal(t, []Signature{{Index: 0, Signer: hashX, Accounts: []string{kp0.Address()}}}, report.Valid)
+
+	_, err = CheckAccounts(network.TestNetworkPassphrase, signed.ToGenericTransaction(), map[string]aurora.Account{})
+	assert.EqualError(t, err, "account "+kp0.Address()+" is not loaded")
+}
+
+func TestCheckAccountsFeeBump(t *testing.T) {
+	kp0 := keypair.MustRandom()
+	kp1 := keypair.MustRandom()
+	inner, err := newTestTransaction(t, kp0.Address(), &txnbuild.BumpSequence{BumpTo: 200}).
+		Sign(network.TestNetworkPassphrase, kp0)
+	require.NoError(t, err)
+	feeBump, err := txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: kp1.Address(),
+		BaseFee:    txnbuild.MinBaseFee,
+	})
+	require.NoError(t, err)
+	accounts := map[string]aurora.Account{
+		kp0.Address(): newAccount(kp0.Address(), 0, 0, 0, signer(kp0.Address(), 1)),
+		kp1.Address(): newAccount(kp1.Address(), 0, 0, 0, signer(kp1.Address(), 1)),
+	}
+
+	addresses, err := RequiredAccounts(feeBump.ToGenericTransaction())
+	require.NoError(t, err)
+	assert.Equal(t, []string{kp1.Address(), kp0.Address()}, addresses)
+
+	report, err := CheckAccounts(network.TestNetworkPassphrase, feeBump.ToGenericTransaction(), accounts)
+	require.NoError(t, err)
+	assert.False(t, report.Authorized)
+	assert.True(t, report.Inner.Authorized)
+
+	signed, err := feeBump.Sign(network.TestNetworkPassphrase, kp1)
+	require.NoError(t, err)
+	report, err = CheckAccounts(network.TestNetworkPassphrase, signed.ToGenericTransaction(), accounts)
+	require.NoError(t, err)
+	assert.True(t, report.Authorized)
+}
+
+func TestMerge(t *testing.T) {
+	kp0 := keypair.MustRandom()
+	kp1 := keypair.MustRandom()
+	kp2 := keypair.MustRandom()
+	tx := newTestTransaction(t, kp0.Address(), &txnbuild.BumpSequence{BumpTo: 200})
+
+	var envelopes []string
+	for _, kps := range [][]*keypair.Full{{kp0}, {kp0, kp1}, {kp2}} {
+		signed, err := tx.Sign(network.TestNetworkPassphrase, kps...)
+		require.NoError(t, err)
+		envelope, err := signed.Base64()
+		require.NoError(t, err)
+		envelopes = append(envelopes, envelope)
+	}
+
+	merged, err := Merge(network.TestNetworkPassphrase, envelopes...)
+	require.NoError(t, err)
+	expected, err := tx.Sign(network.TestNetworkPassphrase, kp0, kp1, kp2)
+	require.NoError(t, err)
+	mergedTx, ok := merged.Transaction()
+	require.True(t, ok)
+	assert.Equal(t, expected.Signatures(), mergedTx.Signatures())
+
+	other, err := newTestTransaction(t, kp1.Address(), &txnbuild.BumpSequence{BumpTo: 200}).
+		Sign(network.TestNetworkPassphrase, kp1)
+	require.NoError(t, err)
+	otherEnvelope, err := other.Base64()
+	require.NoError(t, err)
+	_, err = Merge(network.TestNetworkPassphrase, envelopes[0], otherEnvelope)
+	assert.EqualError(t, err, "envelope 1 is a different transaction")
+
+	_, err = Merge(network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "no envelopes to merge")
+}
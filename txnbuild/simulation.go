@@ -0,0 +1,114 @@
+package txnbuild
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// SimulationResult holds the parts of a Soroban transaction simulation which
+// are needed to assemble a transaction that can be submitted to the network.
+type SimulationResult struct {
+	// TransactionData contains the footprint and resources needed by the
+	// operation.
+	TransactionData xdr.SorobanTransactionData
+	// MinResourceFee is the minimum resource fee, in stroops, required for
+	// the transaction to be accepted.
+	MinResourceFee int64
+	// Auth contains the authorization entries which the invocation requires.
+	// They are only used by InvokeHostFunction operations and only when the
+	// operation doesn't already have auth entries.
+	Auth []xdr.SorobanAuthorizationEntry
+}
+
+// NewSimulationResultFromXDR builds a SimulationResult out of the base64
+// encoded values returned by the simulateTransaction RPC method.
+func NewSimulationResultFromXDR(transactionData, minResourceFee string, auth []string) (SimulationResult, error) {
+	var result SimulationResult
+
+	if err := xdr.SafeUnmarshalBase64(transactionData, &result.TransactionData); err != nil {
+		return SimulationResult{}, errors.Wrap(err, "could not decode transaction data")
+	}
+
+	fee, err := strconv.ParseInt(minResourceFee, 10, 64)
+	if err != nil {
+		return SimulationResult{}, errors.Wrap(err, "could not parse min resource fee")
+	}
+	result.MinResourceFee = fee
+
+	for i, entry := range auth {
+		var authEntry xdr.SorobanAuthorizationEntry
+		if err := xdr.SafeUnmarshalBase64(entry, &authEntry); err != nil {
+			return SimulationResult{}, errors.Wrapf(err, "could not decode auth entry %d", i)
+		}
+		result.Auth = append(result.Auth, authEntry)
+	}
+
+	return result, nil
+}
+
+// AssembleTransaction builds a Soroban transaction out of the given params and
+// the result of simulating them. The transaction must contain exactly one
+// InvokeHostFunction, ExtendFootprintTtl or RestoreFootprint operation.
+//
+// The footprint and resources of the simulation are attached to the
+// transaction and its fee is set to the base fee plus the resource fee, so
+// the returned transaction is ready to be signed. The operations in params are
+// not modified.
+func AssembleTransaction(params TransactionParams, simulation SimulationResult) (*Transaction, error) {
+	if len(params.Operations) != 1 {
+		return nil, errors.New("soroban transactions must contain exactly one operation")
+	}
+	if simulation.MinResourceFee < 0 {
+		return nil, errors.New("min resource fee cannot be negative")
+	}
+
+	sorobanData := simulation.TransactionData
+	if simulation.MinResourceFee > int64(sorobanData.ResourceFee) {
+		sorobanData.ResourceFee = xdr.Int64(simulation.MinResourceFee)
+	}
+	ext, err := xdr.NewTransactionExt(1, sorobanData)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not build transaction ext")
+	}
+
+	var op Operation
+	switch original := params.Operations[0].(type) {
+	case *InvokeHostFunction:
+		assembled := *original
+		assembled.Ext = ext
+		if len(assembled.Auth) == 0 {
+			assembled.Auth = simulation.Auth
+		}
+		op = &assembled
+	case *ExtendFootprintTtl:
+		assembled := *original
+		assembled.Ext = ext
+		op = &assembled
+	case *RestoreFootprint:
+		assembled := *original
+		assembled.Ext = ext
+		op = &assembled
+	default:
+		return nil, errors.Errorf("%T is not a soroban operation", original)
+	}
+
+	params.Operations = []Operation{op}
+	tx, err := NewTransaction(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// The resource fee is charged on top of the inclusion fee.
+	maxFee := tx.maxFee + int64(sorobanData.ResourceFee)
+	if maxFee > math.MaxUint32 {
+		return nil, errors.Errorf(
+			"resource fee %d results in an overflow of max fee", sorobanData.ResourceFee)
+	}
+	tx.maxFee = maxFee
+	tx.envelope.V1.Tx.Fee = xdr.Uint32(maxFee)
+
+	return tx, nil
+}
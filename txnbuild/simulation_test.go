@@ -0,0 +1,202 @@
+package txnbuild
+
+import (
+	"testing"
+
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/xdr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeSimulationResult() SimulationResult {
+	contractID := xdr.Hash{1, 2, 3}
+	return SimulationResult{
+		TransactionData: xdr.SorobanTransactionData{
+			Resources: xdr.SorobanResources{
+				Footprint: xdr.LedgerFootprint{
+					ReadOnly: []xdr.LedgerKey{
+						{
+							Type: xdr.LedgerEntryTypeContractData,
+							ContractData: &xdr.LedgerKeyContractData{
+								Contract: xdr.ScAddress{
+									Type:       xdr.ScAddressTypeScAddressTypeContract,
+									ContractId: &contractID,
+								},
+								Key:        xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance},
+								Durability: xdr.ContractDataDurabilityPersistent,
+							},
+						},
+					},
+				},
+				Instructions: 1000,
+				ReadBytes:    100,
+				WriteBytes:   10,
+			},
+			ResourceFee: 500,
+		},
+		MinResourceFee: 900,
+		Auth: []xdr.SorobanAuthorizationEntry{
+			{
+				Credentials: xdr.SorobanCredentials{
+					Type: xdr.SorobanCredentialsTypeSorobanCredentialsSourceAccount,
+				},
+				RootInvocation: xdr.SorobanAuthorizedInvocation{
+					Function: xdr.SorobanAuthorizedFunction{
+						Type: xdr.SorobanAuthorizedFunctionTypeSorobanAuthorizedFunctionTypeContractFn,
+						ContractFn: &xdr.InvokeContractArgs{
+							ContractAddress: xdr.ScAddress{
+								Type:       xdr.ScAddressTypeScAddressTypeContract,
+								ContractId: &contractID,
+							},
+							FunctionName: "hello",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestAssembleTransaction(t *testing.T) {
+	kp1 := newKeypair1()
+	sourceAccount := NewSimpleAccount(kp1.Address(), int64(41137196761100))
+	simulation := makeSimulationResult()
+
+	op := &InvokeHostFunction{
+		HostFunction: xdr.HostFunction{
+			Type:           xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+			InvokeContract: simulation.Auth[0].RootInvocation.Function.ContractFn,
+		},
+	}
+
+	tx, err := AssembleTransaction(TransactionParams{
+		SourceAccount:        &sourceAccount,
+		IncrementSequenceNum: true,
+		Operations:           []Operation{op},
+		BaseFee:              MinBaseFee,
+		Preconditions:        Preconditions{TimeBounds: NewInfiniteTimeout()},
+	}, simulation)
+	require.NoError(t, err)
+
+	// the fee is the inclusion fee plus the min resource fee
+	assert.Equal(t, int64(MinBaseFee+900), tx.MaxFee())
+	assert.Equal(t, uint32(MinBaseFee+900), tx.ToXDR().Fee())
+
+	sorobanData, ok := tx.ToXDR().V1.Tx.Ext.GetSorobanData()
+	require.True(t, ok)
+	assert.Equal(t, xdr.Int64(900), sorobanData.ResourceFee)
+	assert.Equal(t, simulation.TransactionData.Resources, sorobanData.Resources)
+
+	invokeOp, ok := tx.ToXDR().Operations()[0].Body.GetInvokeHostFunctionOp()
+	require.True(t, ok)
+	assert.Equal(t, simulation.Auth, invokeOp.Auth)
+
+	// the caller's operation is left untouched
+	assert.Empty(t, op.Auth)
+	assert.Equal(t, int32(0), op.Ext.V)
+
+	// the assembled transaction can be signed
+	_, err = tx.Sign(network.TestNetworkPassphrase, kp1)
+	assert.NoError(t, err)
+}
+
+func TestAssembleTransactionKeepsAuth(t *testing.T) {
+	kp1 := newKeypair1()
+	sourceAccount := NewSimpleAccount(kp1.Address(), int64(41137196761100))
+	simulation := makeSimulationResult()
+	auth := []xdr.SorobanAuthorizationEntry{simulation.Auth[0], simulation.Auth[0]}
+
+	tx, err := AssembleTransaction(TransactionParams{
+		SourceAccount: &sourceAccount,
+		Operations: []Operation{&InvokeHostFunction{
+			HostFunction: xdr.HostFunction{
+				Type:           xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+				InvokeContract: simulation.Auth[0].RootInvocation.Function.ContractFn,
+			},
+			Auth: auth,
+		}},
+		BaseFee:       MinBaseFee,
+		Preconditions: Preconditions{TimeBounds: NewInfiniteTimeout()},
+	}, simulation)
+	require.NoError(t, err)
+
+	invokeOp := tx.ToXDR().Operations()[0].Body.MustInvokeHostFunctionOp()
+	assert.Len(t, invokeOp.Auth, 2)
+}
+
+func TestAssembleTransactionFootprintOperations(t *testing.T) {
+	kp1 := newKeypair1()
+	simulation := makeSimulationResult()
+	simulation.MinResourceFee = 100
+
+	for _, op := range []Operation{
+		&ExtendFootprintTtl{ExtendTo: 1000},
+		&RestoreFootprint{},
+	} {
+		sourceAccount := NewSimpleAccount(kp1.Address(), int64(41137196761100))
+		tx, err := AssembleTransaction(TransactionParams{
+			SourceAccount: &sourceAccount,
+			Operations:    []Operation{op},
+			BaseFee:       MinBaseFee,
+			Preconditions: Preconditions{TimeBounds: NewInfiniteTimeout()},
+		}, simulation)
+		require.NoError(t, err)
+
+		// the resource fee in the transaction data is higher than the min
+		// resource fee, so it is kept
+		assert.Equal(t, int64(MinBaseFee+500), tx.MaxFee())
+		sorobanData := tx.ToXDR().V1.Tx.Ext.MustSorobanData()
+		assert.Equal(t, xdr.Int64(500), sorobanData.ResourceFee)
+	}
+}
+
+func TestAssembleTransactionInvalid(t *testing.T) {
+	kp1 := newKeypair1()
+	sourceAccount := NewSimpleAccount(kp1.Address(), int64(41137196761100))
+	simulation := makeSimulationResult()
+
+	_, err := AssembleTransaction(TransactionParams{
+		SourceAccount: &sourceAccount,
+		Operations:    []Operation{&BumpSequence{BumpTo: 1}},
+		BaseFee:       MinBaseFee,
+		Preconditions: Preconditions{TimeBounds: NewInfiniteTimeout()},
+	}, simulation)
+	assert.EqualError(t, err, "*txnbuild.BumpSequence is not a soroban operation")
+
+	_, err = AssembleTransaction(TransactionParams{
+		SourceAccount: &sourceAccount,
+		Operations:    []Operation{&RestoreFootprint{}, &RestoreFootprint{}},
+		BaseFee:       MinBaseFee,
+		Preconditions: Preconditions{TimeBounds: NewInfiniteTimeout()},
+	}, simulation)
+	assert.EqualError(t, err, "soroban transactions must contain exactly one operation")
+
+	simulation.MinResourceFee = 1 << 32
+	_, err = AssembleTransaction(TransactionParams{
+		SourceAccount: &sourceAccount,
+		Operations:    []Operation{&RestoreFootprint{}},
+		BaseFee:       MinBaseFee,
+		Preconditions: Preconditions{TimeBounds: NewInfiniteTimeout()},
+	}, simulation)
+	assert.EqualError(t, err, "resource fee 4294967296 results in an overflow of max fee")
+}
+
+func TestNewSimulationResultFromXDR(t *testing.T) {
+	expected := makeSimulationResult()
+	transactionData, err := xdr.MarshalBase64(expected.TransactionData)
+	require.NoError(t, err)
+	auth, err := xdr.MarshalBase64(expected.Auth[0])
+	require.NoError(t, err)
+
+	result, err := NewSimulationResultFromXDR(transactionData, "900", []string{auth})
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	_, err = NewSimulationResultFromXDR(transactionData, "not a number", nil)
+	assert.Error(t, err)
+
+	_, err = NewSimulationResultFromXDR("AAAA", "900", nil)
+	assert.Error(t, err)
+}
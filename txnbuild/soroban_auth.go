@@ -0,0 +1,135 @@
+package txnbuild
+
+import (
+	"crypto/sha256"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// SignAuthEntry signs the address credentials of a Soroban authorization
+// entry with the given keypair, making the signature valid until
+// signatureExpirationLedger (inclusive).
+//
+// The credentials must belong to the account of the signer. Entries using
+// source account credentials are authorized by the transaction signature, so
+// they are returned unchanged. The given entry is not modified.
+func SignAuthEntry(
+	entry xdr.SorobanAuthorizationEntry,
+	signatureExpirationLedger uint32,
+	signer *keypair.Full,
+	networkPassphrase string,
+) (xdr.SorobanAuthorizationEntry, error) {
+	if entry.Credentials.Type == xdr.SorobanCredentialsTypeSorobanCredentialsSourceAccount {
+		return entry, nil
+	}
+
+	addressCredentials, ok := entry.Credentials.GetAddress()
+	if !ok {
+		return xdr.SorobanAuthorizationEntry{}, errors.New("auth entry has no address credentials")
+	}
+
+	address, err := addressCredentials.Address.String()
+	if err != nil {
+		return xdr.SorobanAuthorizationEntry{}, errors.Wrap(err, "invalid credentials address")
+	}
+	if address != signer.Address() {
+		return xdr.SorobanAuthorizationEntry{}, errors.Errorf(
+			"auth entry credentials belong to %s, not to signer %s", address, signer.Address())
+	}
+
+	preimage := xdr.HashIdPreimage{
+		Type: xdr.EnvelopeTypeEnvelopeTypeSorobanAuthorization,
+		SorobanAuthorization: &xdr.HashIdPreimageSorobanAuthorization{
+			NetworkId:                 network.ID(networkPassphrase),
+			Nonce:                     addressCredentials.Nonce,
+			SignatureExpirationLedger: xdr.Uint32(signatureExpirationLedger),
+			Invocation:                entry.RootInvocation,
+		},
+	}
+	payload, err := preimage.MarshalBinary()
+	if err != nil {
+		return xdr.SorobanAuthorizationEntry{}, errors.Wrap(err, "could not marshal auth preimage")
+	}
+	hash := sha256.Sum256(payload)
+
+	signature, err := signer.Sign(hash[:])
+	if err != nil {
+		return xdr.SorobanAuthorizationEntry{}, errors.Wrap(err, "could not sign auth entry")
+	}
+
+	signatureVal, err := accountSignatureScVal(signer, signature)
+	if err != nil {
+		return xdr.SorobanAuthorizationEntry{}, err
+	}
+
+	addressCredentials.SignatureExpirationLedger = xdr.Uint32(signatureExpirationLedger)
+	addressCredentials.Signature = signatureVal
+	entry.Credentials = xdr.SorobanCredentials{
+		Type:    xdr.SorobanCredentialsTypeSorobanCredentialsAddress,
+		Address: &addressCredentials,
+	}
+
+	return entry, nil
+}
+
+// SignAuthEntries signs every entry whose address credentials belong to the
+// signer and leaves the rest unchanged. See SignAuthEntry.
+func SignAuthEntries(
+	entries []xdr.SorobanAuthorizationEntry,
+	signatureExpirationLedger uint32,
+	signer *keypair.Full,
+	networkPassphrase string,
+) ([]xdr.SorobanAuthorizationEntry, error) {
+	signed := make([]xdr.SorobanAuthorizationEntry, 0, len(entries))
+	for i, entry := range entries {
+		if addressCredentials, ok := entry.Credentials.GetAddress(); ok {
+			address, err := addressCredentials.Address.String()
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid credentials address in auth entry %d", i)
+			}
+			if address == signer.Address() {
+				entry, err = SignAuthEntry(entry, signatureExpirationLedger, signer, networkPassphrase)
+				if err != nil {
+					return nil, errors.Wrapf(err, "could not sign auth entry %d", i)
+				}
+			}
+		}
+		signed = append(signed, entry)
+	}
+	return signed, nil
+}
+
+// accountSignatureScVal encodes an ed25519 signature in the format expected
+// by the built-in account contract: a vector of maps with the public key and
+// signature of each signer.
+func accountSignatureScVal(signer *keypair.Full, signature []byte) (xdr.ScVal, error) {
+	publicKey, err := xdr.AddressToAccountId(signer.Address())
+	if err != nil {
+		return xdr.ScVal{}, errors.Wrap(err, "invalid signer address")
+	}
+
+	publicKeyBytes := xdr.ScBytes(publicKey.Ed25519[:])
+	signatureBytes := xdr.ScBytes(signature)
+	publicKeySym := xdr.ScSymbol("public_key")
+	signatureSym := xdr.ScSymbol("signature")
+
+	// map keys must be sorted
+	signatureMap := &xdr.ScMap{
+		{
+			Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &publicKeySym},
+			Val: xdr.ScVal{Type: xdr.ScValTypeScvBytes, Bytes: &publicKeyBytes},
+		},
+		{
+			Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &signatureSym},
+			Val: xdr.ScVal{Type: xdr.ScValTypeScvBytes, Bytes: &signatureBytes},
+		},
+	}
+	signatureVec := &xdr.ScVec{
+		{Type: xdr.ScValTypeScvMap, Map: &signatureMap},
+	}
+
+	return xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: &signatureVec}, nil
+}
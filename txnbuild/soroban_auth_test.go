@@ -0,0 +1,111 @@
+package txnbuild
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/xdr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeAddressAuthEntry(address string) xdr.SorobanAuthorizationEntry {
+	entry := makeSimulationResult().Auth[0]
+	accountID := xdr.MustAddress(address)
+	entry.Credentials = xdr.SorobanCredentials{
+		Type: xdr.SorobanCredentialsTypeSorobanCredentialsAddress,
+		Address: &xdr.SorobanAddressCredentials{
+			Address: xdr.ScAddress{
+				Type:      xdr.ScAddressTypeScAddressTypeAccount,
+				AccountId: &accountID,
+			},
+			Nonce:     123,
+			Signature: xdr.ScVal{Type: xdr.ScValTypeScvVoid},
+		},
+	}
+	return entry
+}
+
+func TestSignAuthEntry(t *testing.T) {
+	kp1 := newKeypair1()
+	entry := makeAddressAuthEntry(kp1.Address())
+
+	signed, err := SignAuthEntry(entry, 1000, kp1, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+
+	// the original entry is left untouched
+	assert.Equal(t, xdr.Uint32(0), entry.Credentials.Address.SignatureExpirationLedger)
+	assert.Equal(t, xdr.ScValTypeScvVoid, entry.Credentials.Address.Signature.Type)
+
+	credentials := signed.Credentials.MustAddress()
+	assert.Equal(t, xdr.Uint32(1000), credentials.SignatureExpirationLedger)
+	assert.Equal(t, xdr.Int64(123), credentials.Nonce)
+
+	// the signature is a vector with a single {public_key, signature} map
+	signatures := *credentials.Signature.MustVec()
+	require.Len(t, signatures, 1)
+	signatureMap := *signatures[0].MustMap()
+	require.Len(t, signatureMap, 2)
+	assert.Equal(t, xdr.ScSymbol("public_key"), signatureMap[0].Key.MustSym())
+	assert.Equal(t, xdr.ScSymbol("signature"), signatureMap[1].Key.MustSym())
+
+	publicKey := signatureMap[0].Val.MustBytes()
+	accountID := xdr.MustAddress(kp1.Address())
+	assert.Equal(t, accountID.Ed25519[:], []byte(publicKey))
+
+	// the signature covers the network, nonce, expiration and invocation
+	preimage := xdr.HashIdPreimage{
+		Type: xdr.EnvelopeTypeEnvelopeTypeSorobanAuthorization,
+		SorobanAuthorization: &xdr.HashIdPreimageSorobanAuthorization{
+			NetworkId:                 network.ID(network.TestNetworkPassphrase),
+			Nonce:                     123,
+			SignatureExpirationLedger: 1000,
+			Invocation:                entry.RootInvocation,
+		},
+	}
+	payload, err := preimage.MarshalBinary()
+	require.NoError(t, err)
+	hash := sha256.Sum256(payload)
+	assert.NoError(t, kp1.Verify(hash[:], signatureMap[1].Val.MustBytes()))
+
+	// a different network invalidates the signature
+	otherNetwork, err := SignAuthEntry(entry, 1000, kp1, network.PublicNetworkPassphrase)
+	require.NoError(t, err)
+	otherSignature := (*(*otherNetwork.Credentials.MustAddress().Signature.MustVec())[0].MustMap())[1].Val.MustBytes()
+	assert.Error(t, kp1.Verify(hash[:], otherSignature))
+}
+
+func TestSignAuthEntryWrongSigner(t *testing.T) {
+	kp1, kp2 := newKeypair1(), newKeypair2()
+	entry := makeAddressAuthEntry(kp1.Address())
+
+	_, err := SignAuthEntry(entry, 1000, kp2, network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "auth entry credentials belong to "+kp1.Address()+", not to signer "+kp2.Address())
+}
+
+func TestSignAuthEntrySourceAccount(t *testing.T) {
+	entry := makeSimulationResult().Auth[0]
+
+	signed, err := SignAuthEntry(entry, 1000, newKeypair1(), network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, entry, signed)
+}
+
+func TestSignAuthEntries(t *testing.T) {
+	kp1, kp2 := newKeypair1(), newKeypair2()
+	entries := []xdr.SorobanAuthorizationEntry{
+		makeAddressAuthEntry(kp1.Address()),
+		makeAddressAuthEntry(kp2.Address()),
+		makeSimulationResult().Auth[0],
+	}
+
+	signed, err := SignAuthEntries(entries, 1000, kp1, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	require.Len(t, signed, 3)
+
+	assert.Equal(t, xdr.Uint32(1000), signed[0].Credentials.MustAddress().SignatureExpirationLedger)
+	assert.Equal(t, entries[1], signed[1])
+	assert.Equal(t, entries[2], signed[2])
+}
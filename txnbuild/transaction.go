@@ -11,6 +11,7 @@ package txnbuild
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
@@ -302,6 +303,18 @@ func (t *Transaction) Sign(network string, kps ...*keypair.Full) (*Transaction,
 	return t.clone(extendedSignatures), nil
 }
 
+// SignWithSigners returns a new Transaction instance which extends the current instance
+// with additional signatures produced by the given list of signers. Unlike Sign,
+// the secret seeds of the signers don't need to be available in memory.
+func (t *Transaction) SignWithSigners(ctx context.Context, network string, signers ...TransactionSigner) (*Transaction, error) {
+	extendedSignatures, err := concatSignerSignatures(ctx, t.envelope, network, t.Signatures(), signers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.clone(extendedSignatures), nil
+}
+
 // SignWithKeyString returns a new Transaction instance which extends the current instance
 // with additional signatures derived from the given list of private key strings.
 func (t *Transaction) SignWithKeyString(network string, keys ...string) (*Transaction, error) {
@@ -504,6 +517,18 @@ func (t *FeeBumpTransaction) Sign(network string, kps ...*keypair.Full) (*FeeBum
 	return t.clone(extendedSignatures), nil
 }
 
+// SignWithSigners returns a new FeeBumpTransaction instance which extends the current instance
+// with additional signatures produced by the given list of signers. Unlike Sign,
+// the secret seeds of the signers don't need to be available in memory.
+func (t *FeeBumpTransaction) SignWithSigners(ctx context.Context, network string, signers ...TransactionSigner) (*FeeBumpTransaction, error) {
+	extendedSignatures, err := concatSignerSignatures(ctx, t.envelope, network, t.Signatures(), signers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.clone(extendedSignatures), nil
+}
+
 // SignWithKeyString returns a new FeeBumpTransaction instance which extends the current instance
 // with additional signatures derived from the given list of private key strings.
 func (t *FeeBumpTransaction) SignWithKeyString(network string, keys ...string) (*FeeBumpTransaction, error) {
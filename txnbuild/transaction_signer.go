@@ -0,0 +1,253 @@
+package txnbuild
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// TransactionSigner signs transactions on behalf of a Hcnet key without
+// necessarily holding the secret seed of the key, which can be kept by a remote
+// signing service, a hardware security module or a key management service.
+type TransactionSigner interface {
+	// Address returns the public key of the signer (G...).
+	Address() string
+	// Hint returns the last 4 bytes of the public key, used to decorate the
+	// signatures of the signer.
+	Hint() [4]byte
+	// Sign returns the decorated signature of the given network specific
+	// transaction hash.
+	Sign(ctx context.Context, txHash [32]byte) (xdr.DecoratedSignature, error)
+}
+
+// KeypairSigner is a TransactionSigner which holds the secret seed of the key in memory.
+type KeypairSigner struct {
+	kp *keypair.Full
+}
+
+// NewKeypairSigner returns a TransactionSigner signing with the given keypair.
+func NewKeypairSigner(kp *keypair.Full) *KeypairSigner {
+	return &KeypairSigner{kp: kp}
+}
+
+// Address returns the public key of the keypair.
+func (s *KeypairSigner) Address() string {
+	return s.kp.Address()
+}
+
+// Hint returns the signature hint of the keypair.
+func (s *KeypairSigner) Hint() [4]byte {
+	return s.kp.Hint()
+}
+
+// Sign signs the transaction hash with the keypair.
+func (s *KeypairSigner) Sign(ctx context.Context, txHash [32]byte) (xdr.DecoratedSignature, error) {
+	return s.kp.SignDecorated(txHash[:])
+}
+
+// HTTPSignerConfig configures an HTTPSigner.
+type HTTPSignerConfig struct {
+	// URL is the url of the signing service.
+	URL string
+	// PublicKey is the public key (G...) of the key held by the service.
+	PublicKey string
+	// Header contains additional headers sent with each request, for example
+	// to authenticate with the service.
+	Header http.Header
+	// Client is the client used to send the requests. If nil a client with a
+	// 30 seconds timeout is used.
+	Client *http.Client
+}
+
+// HTTPSigner is a TransactionSigner which asks a remote signing service to sign
+// transaction hashes. The service holds the secret seed and is called with a
+// POST request whose JSON body contains the public key of the signer and the
+// hex encoded transaction hash:
+//
+//	{"public_key": "G...", "transaction_hash": "<hex>"}
+//
+// The service must respond with a 200 status and the base64 encoded ed25519
+// signature of the hash:
+//
+//	{"signature": "<base64>"}
+//
+// The signature is verified against the public key before it's returned.
+type HTTPSigner struct {
+	config HTTPSignerConfig
+	kp     *keypair.FromAddress
+}
+
+// NewHTTPSigner returns an HTTPSigner for the key held by the signing service
+// described by config.
+func NewHTTPSigner(config HTTPSignerConfig) (*HTTPSigner, error) {
+	if config.URL == "" {
+		return nil, errors.New("signing service url cannot be empty")
+	}
+	kp, err := keypair.ParseAddress(config.PublicKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the public key %s", config.PublicKey)
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &HTTPSigner{config: config, kp: kp}, nil
+}
+
+// HTTPSignRequest is the body of the requests sent by HTTPSigner.
+type HTTPSignRequest struct {
+	PublicKey       string `json:"public_key"`
+	TransactionHash string `json:"transaction_hash"`
+}
+
+// HTTPSignResponse is the body of the responses expected by HTTPSigner.
+type HTTPSignResponse struct {
+	Signature string `json:"signature"`
+}
+
+// Address returns the public key of the signer.
+func (s *HTTPSigner) Address() string {
+	return s.kp.Address()
+}
+
+// Hint returns the signature hint of the public key.
+func (s *HTTPSigner) Hint() [4]byte {
+	return s.kp.Hint()
+}
+
+// Sign asks the signing service to sign the transaction hash.
+func (s *HTTPSigner) Sign(ctx context.Context, txHash [32]byte) (xdr.DecoratedSignature, error) {
+	body, err := json.Marshal(HTTPSignRequest{
+		PublicKey:       s.kp.Address(),
+		TransactionHash: hex.EncodeToString(txHash[:]),
+	})
+	if err != nil {
+		return xdr.DecoratedSignature{}, errors.Wrap(err, "failed to encode sign request")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return xdr.DecoratedSignature{}, errors.Wrap(err, "failed to create sign request")
+	}
+	for name, values := range s.config.Header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return xdr.DecoratedSignature{}, errors.Wrap(err, "failed to send sign request")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return xdr.DecoratedSignature{}, fmt.Errorf("signing service responded with status %s", resp.Status)
+	}
+
+	var response HTTPSignResponse
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&response); err != nil {
+		return xdr.DecoratedSignature{}, errors.Wrap(err, "failed to decode sign response")
+	}
+	signature, err := base64.StdEncoding.DecodeString(response.Signature)
+	if err != nil {
+		return xdr.DecoratedSignature{}, errors.Wrapf(err, "failed to base64-decode the signature %s", response.Signature)
+	}
+	return decorateSignature(s.kp, txHash, signature)
+}
+
+// KeyStore is implemented by the adapters of hardware security modules (for
+// example through PKCS#11) and key management services which hold ed25519
+// keys and sign messages without ever exposing the keys.
+type KeyStore interface {
+	// SignEd25519 returns the 64 bytes ed25519 signature of message signed
+	// by the key identified by keyID.
+	SignEd25519(ctx context.Context, keyID string, message []byte) ([]byte, error)
+}
+
+// KeyStoreSigner is a TransactionSigner which signs transaction hashes with a key held
+// by a KeyStore. The signature is verified against the public key before it's
+// returned.
+type KeyStoreSigner struct {
+	store KeyStore
+	keyID string
+	kp    *keypair.FromAddress
+}
+
+// NewKeyStoreSigner returns a KeyStoreSigner signing with the key identified
+// by keyID in store, whose public key is publicKey.
+func NewKeyStoreSigner(store KeyStore, keyID, publicKey string) (*KeyStoreSigner, error) {
+	kp, err := keypair.ParseAddress(publicKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the public key %s", publicKey)
+	}
+	return &KeyStoreSigner{store: store, keyID: keyID, kp: kp}, nil
+}
+
+// Address returns the public key of the signer.
+func (s *KeyStoreSigner) Address() string {
+	return s.kp.Address()
+}
+
+// Hint returns the signature hint of the public key.
+func (s *KeyStoreSigner) Hint() [4]byte {
+	return s.kp.Hint()
+}
+
+// Sign signs the transaction hash with the key store.
+func (s *KeyStoreSigner) Sign(ctx context.Context, txHash [32]byte) (xdr.DecoratedSignature, error) {
+	signature, err := s.store.SignEd25519(ctx, s.keyID, txHash[:])
+	if err != nil {
+		return xdr.DecoratedSignature{}, errors.Wrapf(err, "key store failed to sign with key %s", s.keyID)
+	}
+	return decorateSignature(s.kp, txHash, signature)
+}
+
+// decorateSignature verifies the signature of txHash made by an external
+// signer and decorates it with the hint of the key.
+func decorateSignature(kp *keypair.FromAddress, txHash [32]byte, signature []byte) (xdr.DecoratedSignature, error) {
+	if err := kp.Verify(txHash[:], signature); err != nil {
+		return xdr.DecoratedSignature{}, errors.Wrapf(err, "invalid signature for %s", kp.Address())
+	}
+	return xdr.DecoratedSignature{
+		Hint:      xdr.SignatureHint(kp.Hint()),
+		Signature: xdr.Signature(signature),
+	}, nil
+}
+
+func concatSignerSignatures(
+	ctx context.Context,
+	e xdr.TransactionEnvelope,
+	networkStr string,
+	signatures []xdr.DecoratedSignature,
+	signers ...TransactionSigner,
+) ([]xdr.DecoratedSignature, error) {
+	h, err := network.HashTransactionInEnvelope(e, networkStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash transaction")
+	}
+
+	extended := make(
+		[]xdr.DecoratedSignature,
+		len(signatures),
+		len(signatures)+len(signers),
+	)
+	copy(extended, signatures)
+	for _, signer := range signers {
+		sig, err := signer.Sign(ctx, h)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to sign transaction with %s", signer.Address())
+		}
+		extended = append(extended, sig)
+	}
+	return extended, nil
+}
@@ -0,0 +1,178 @@
+package txnbuild
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/support/errors"
+)
+
+func newTransactionSignerTestTx(t *testing.T) *Transaction {
+	kp0 := newKeypair0()
+	sourceAccount := NewSimpleAccount(kp0.Address(), int64(9605939170639897))
+	tx, err := NewTransaction(
+		TransactionParams{
+			SourceAccount:        &sourceAccount,
+			IncrementSequenceNum: true,
+			Operations:           []Operation{&BumpSequence{BumpTo: 0}},
+			BaseFee:              MinBaseFee,
+			Preconditions:        Preconditions{TimeBounds: NewInfiniteTimeout()},
+		},
+	)
+	require.NoError(t, err)
+	return tx
+}
+
+func TestSignWithKeypairSigner(t *testing.T) {
+	kp0 := newKeypair0()
+	kp1 := newKeypair1()
+	tx := newTransactionSignerTestTx(t)
+
+	expected, err := tx.Sign(network.TestNetworkPassphrase, kp0, kp1)
+	require.NoError(t, err)
+	signed, err := tx.SignWithSigners(
+		context.Background(), network.TestNetworkPassphrase, NewKeypairSigner(kp0), NewKeypairSigner(kp1),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Signatures(), signed.Signatures())
+	assert.Empty(t, tx.Signatures())
+
+	signer := NewKeypairSigner(kp0)
+	assert.Equal(t, kp0.Address(), signer.Address())
+	assert.Equal(t, kp0.Hint(), signer.Hint())
+
+	feeBump, err := NewFeeBumpTransaction(FeeBumpTransactionParams{
+		Inner:      expected,
+		FeeAccount: kp1.Address(),
+		BaseFee:    MinBaseFee,
+	})
+	require.NoError(t, err)
+	expectedFeeBump, err := feeBump.Sign(network.TestNetworkPassphrase, kp1)
+	require.NoError(t, err)
+	signedFeeBump, err := feeBump.SignWithSigners(
+		context.Background(), network.TestNetworkPassphrase, NewKeypairSigner(kp1),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, expectedFeeBump.Signatures(), signedFeeBump.Signatures())
+}
+
+func TestHTTPSigner(t *testing.T) {
+	kp0 := newKeypair0()
+	tx := newTransactionSignerTestTx(t)
+	hash, err := tx.Hash(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+
+	signingKey := kp0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		var request HTTPSignRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, kp0.Address(), request.PublicKey)
+		assert.Equal(t, hex.EncodeToString(hash[:]), request.TransactionHash)
+
+		requestHash, err := hex.DecodeString(request.TransactionHash)
+		require.NoError(t, err)
+		signature, err := signingKey.Sign(requestHash)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(HTTPSignResponse{
+			Signature: base64.StdEncoding.EncodeToString(signature),
+		}))
+	}))
+	defer server.Close()
+
+	signer, err := NewHTTPSigner(HTTPSignerConfig{
+		URL:       server.URL,
+		PublicKey: kp0.Address(),
+		Header:    http.Header{"Authorization": []string{"Bearer token"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, kp0.Address(), signer.Address())
+	assert.Equal(t, kp0.Hint(), signer.Hint())
+
+	expected, err := tx.Sign(network.TestNetworkPassphrase, kp0)
+	require.NoError(t, err)
+	signed, err := tx.SignWithSigners(context.Background(), network.TestNetworkPassphrase, signer)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Signatures(), signed.Signatures())
+
+	// signatures made by another key are rejected
+	signingKey = newKeypair1()
+	_, err = tx.SignWithSigners(context.Background(), network.TestNetworkPassphrase, signer)
+	assert.EqualError(
+		t, err,
+		"failed to sign transaction with "+kp0.Address()+": invalid signature for "+kp0.Address()+": signature verification failed",
+	)
+
+	_, err = NewHTTPSigner(HTTPSignerConfig{URL: server.URL, PublicKey: "invalid"})
+	assert.Error(t, err)
+	_, err = NewHTTPSigner(HTTPSignerConfig{PublicKey: kp0.Address()})
+	assert.EqualError(t, err, "signing service url cannot be empty")
+}
+
+func TestHTTPSignerErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	kp0 := newKeypair0()
+	signer, err := NewHTTPSigner(HTTPSignerConfig{URL: server.URL, PublicKey: kp0.Address()})
+	require.NoError(t, err)
+	_, err = signer.Sign(context.Background(), [32]byte{})
+	assert.EqualError(t, err, "signing service responded with status 403 Forbidden")
+}
+
+type testKeyStore struct {
+	keys map[string]*keypair.Full
+}
+
+func (s testKeyStore) SignEd25519(ctx context.Context, keyID string, message []byte) ([]byte, error) {
+	kp, ok := s.keys[keyID]
+	if !ok {
+		return nil, errors.New("unknown key")
+	}
+	return kp.Sign(message)
+}
+
+func TestKeyStoreSigner(t *testing.T) {
+	kp0 := newKeypair0()
+	kp1 := newKeypair1()
+	store := testKeyStore{keys: map[string]*keypair.Full{"key-0": kp0, "key-1": kp1}}
+	tx := newTransactionSignerTestTx(t)
+
+	signer, err := NewKeyStoreSigner(store, "key-0", kp0.Address())
+	require.NoError(t, err)
+	assert.Equal(t, kp0.Address(), signer.Address())
+	assert.Equal(t, kp0.Hint(), signer.Hint())
+
+	expected, err := tx.Sign(network.TestNetworkPassphrase, kp0)
+	require.NoError(t, err)
+	signed, err := tx.SignWithSigners(context.Background(), network.TestNetworkPassphrase, signer)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Signatures(), signed.Signatures())
+
+	// the key id doesn't match the public key
+	signer, err = NewKeyStoreSigner(store, "key-1", kp0.Address())
+	require.NoError(t, err)
+	_, err = tx.SignWithSigners(context.Background(), network.TestNetworkPassphrase, signer)
+	assert.EqualError(
+		t, err,
+		"failed to sign transaction with "+kp0.Address()+": invalid signature for "+kp0.Address()+": signature verification failed",
+	)
+
+	signer, err = NewKeyStoreSigner(store, "key-2", kp0.Address())
+	require.NoError(t, err)
+	_, err = signer.Sign(context.Background(), [32]byte{})
+	assert.EqualError(t, err, "key store failed to sign with key key-2: unknown key")
+}
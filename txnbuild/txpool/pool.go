@@ -0,0 +1,294 @@
+/*
+Package txpool submits transactions concurrently from a pool of channel
+accounts.
+
+Every transaction submitted to a Pool uses one of the channel accounts as its
+source account, so transactions sharing a channel account are never in flight
+at the same time and many transactions can be submitted at once without
+sequence number conflicts. The sequence numbers of the channel accounts are
+cached by a SequenceManager and loaded again from aurora whenever a
+transaction fails with tx_bad_seq. Transactions rejected with
+tx_insufficient_fee are wrapped in fee bump transactions with increasing fees.
+*/
+package txpool
+
+import (
+	"context"
+
+	"github.com/hcnet/go/clients/auroraclient"
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+)
+
+// DefaultMaxRetries is the number of times a transaction is rebuilt after a
+// tx_bad_seq error when Config.MaxRetries is not set.
+const DefaultMaxRetries = 3
+
+const (
+	txBadSeq             = "tx_bad_seq"
+	txFailed             = "tx_failed"
+	txInsufficientFee    = "tx_insufficient_fee"
+	txFeeBumpInnerFailed = "tx_fee_bump_inner_failed"
+)
+
+// Config configures a Pool.
+type Config struct {
+	Client            auroraclient.ClientInterface
+	NetworkPassphrase string
+	// Channels are the keypairs of the channel accounts used as the source
+	// accounts of the transactions. At least one channel account is
+	// required.
+	Channels []*keypair.Full
+	// BaseFee is the base fee of the transactions, txnbuild.MinBaseFee if not
+	// set.
+	BaseFee int64
+	// MaxBaseFee is the highest base fee of the fee bump transactions built
+	// when a transaction is rejected with tx_insufficient_fee. Transactions
+	// are not fee bumped if MaxBaseFee is not greater than BaseFee.
+	MaxBaseFee int64
+	// FeeAccount pays the fees of the fee bump transactions. If not set the
+	// channel account of the transaction pays them.
+	FeeAccount *keypair.Full
+	// MaxConcurrency is the maximum number of transactions submitted at the
+	// same time. It is never more than the number of channel accounts, which
+	// is also the default.
+	MaxConcurrency int
+	// MaxRetries is the number of times a transaction is rebuilt with a new
+	// sequence number after a tx_bad_seq error, DefaultMaxRetries if not set.
+	MaxRetries int
+}
+
+// Request describes a transaction to submit.
+type Request struct {
+	Operations    []txnbuild.Operation
+	Memo          txnbuild.Memo
+	Preconditions txnbuild.Preconditions
+	// Signers sign the transaction along with the channel account, for
+	// example the source accounts of the operations.
+	Signers []*keypair.Full
+}
+
+// Future is the result of a submitted transaction.
+type Future struct {
+	done chan struct{}
+	tx   aurora.Transaction
+	err  error
+}
+
+// Done returns a channel which is closed once the result is available.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Result waits for the transaction to be submitted and returns the result.
+func (f *Future) Result() (aurora.Transaction, error) {
+	<-f.done
+	return f.tx, f.err
+}
+
+// Wait is like Result but stops waiting when the context is done. The
+// transaction may still be submitted after Wait returns.
+func (f *Future) Wait(ctx context.Context) (aurora.Transaction, error) {
+	select {
+	case <-f.done:
+		return f.tx, f.err
+	case <-ctx.Done():
+		return aurora.Transaction{}, ctx.Err()
+	}
+}
+
+// Pool submits transactions using a pool of channel accounts.
+type Pool struct {
+	config    Config
+	sequences *SequenceManager
+	channels  chan *keypair.Full
+	slots     chan struct{}
+}
+
+// New constructs a new Pool.
+func New(config Config) (*Pool, error) {
+	if config.Client == nil {
+		return nil, errors.New("client cannot be nil")
+	}
+	if config.NetworkPassphrase == "" {
+		return nil, errors.New("network passphrase cannot be empty")
+	}
+	if len(config.Channels) == 0 {
+		return nil, errors.New("at least one channel account is required")
+	}
+	if config.BaseFee == 0 {
+		config.BaseFee = txnbuild.MinBaseFee
+	}
+	if config.MaxConcurrency <= 0 || config.MaxConcurrency > len(config.Channels) {
+		config.MaxConcurrency = len(config.Channels)
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = DefaultMaxRetries
+	}
+
+	pool := &Pool{
+		config:    config,
+		sequences: NewSequenceManager(config.Client),
+		channels:  make(chan *keypair.Full, len(config.Channels)),
+		slots:     make(chan struct{}, config.MaxConcurrency),
+	}
+	for _, channel := range config.Channels {
+		pool.channels <- channel
+	}
+	return pool, nil
+}
+
+// Sequences returns the SequenceManager caching the sequence numbers of the
+// channel accounts.
+func (p *Pool) Sequences() *SequenceManager {
+	return p.sequences
+}
+
+// Submit builds the transaction described by the request with the next
+// available channel account and submits it. It does not block, the result is
+// available through the returned Future. The context bounds the time spent
+// waiting for a channel account.
+func (p *Pool) Submit(ctx context.Context, request Request) *Future {
+	future := &Future{done: make(chan struct{})}
+	go func() {
+		defer close(future.done)
+		future.tx, future.err = p.submit(ctx, request)
+	}()
+	return future
+}
+
+func (p *Pool) submit(ctx context.Context, request Request) (aurora.Transaction, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return aurora.Transaction{}, ctx.Err()
+	}
+	defer func() { <-p.slots }()
+
+	var channel *keypair.Full
+	select {
+	case channel = <-p.channels:
+	case <-ctx.Done():
+		return aurora.Transaction{}, ctx.Err()
+	}
+	defer func() { p.channels <- channel }()
+
+	for attempt := 0; ; attempt++ {
+		tx, err := p.build(channel, request)
+		if err != nil {
+			return aurora.Transaction{}, err
+		}
+
+		result, err := p.send(channel, tx)
+		if err == nil {
+			return result, nil
+		}
+
+		// the sequence number is only consumed when the transaction made it
+		// into a ledger, in any other case it is loaded again
+		code := transactionCode(err)
+		if code != txFailed {
+			p.sequences.Reset(channel.Address())
+		}
+		if code != txBadSeq || attempt >= p.config.MaxRetries || ctx.Err() != nil {
+			return aurora.Transaction{}, err
+		}
+	}
+}
+
+func (p *Pool) build(channel *keypair.Full, request Request) (*txnbuild.Transaction, error) {
+	account, err := p.sequences.Next(channel.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := txnbuild.NewTransaction(
+		txnbuild.TransactionParams{
+			SourceAccount:        account,
+			IncrementSequenceNum: true,
+			Operations:           request.Operations,
+			BaseFee:              p.config.BaseFee,
+			Memo:                 request.Memo,
+			Preconditions:        request.Preconditions,
+		},
+	)
+	if err != nil {
+		// the sequence number was not used
+		p.sequences.Reset(channel.Address())
+		return nil, errors.Wrap(err, "unable to build tx")
+	}
+
+	signers := append([]*keypair.Full{channel}, request.Signers...)
+	tx, err = tx.Sign(p.config.NetworkPassphrase, signers...)
+	if err != nil {
+		p.sequences.Reset(channel.Address())
+		return nil, errors.Wrap(err, "unable to sign tx")
+	}
+	return tx, nil
+}
+
+// send submits the transaction, wrapping it in fee bump transactions with
+// doubling base fees for as long as it is rejected with tx_insufficient_fee
+// and the base fee does not exceed Config.MaxBaseFee.
+func (p *Pool) send(channel *keypair.Full, tx *txnbuild.Transaction) (aurora.Transaction, error) {
+	envelope, err := tx.Base64()
+	if err != nil {
+		return aurora.Transaction{}, errors.Wrap(err, "unable to serialize tx")
+	}
+	result, err := p.config.Client.SubmitTransactionXDR(envelope)
+
+	feeAccount := p.config.FeeAccount
+	if feeAccount == nil {
+		feeAccount = channel
+	}
+	baseFee := tx.BaseFee()
+	for err != nil && transactionCode(err) == txInsufficientFee && baseFee < p.config.MaxBaseFee {
+		baseFee *= 2
+		if baseFee > p.config.MaxBaseFee {
+			baseFee = p.config.MaxBaseFee
+		}
+
+		var feeBump *txnbuild.FeeBumpTransaction
+		feeBump, err = txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+			Inner:      tx,
+			FeeAccount: feeAccount.Address(),
+			BaseFee:    baseFee,
+		})
+		if err != nil {
+			return aurora.Transaction{}, errors.Wrap(err, "unable to build fee bump tx")
+		}
+		feeBump, err = feeBump.Sign(p.config.NetworkPassphrase, feeAccount)
+		if err != nil {
+			return aurora.Transaction{}, errors.Wrap(err, "unable to sign fee bump tx")
+		}
+		envelope, err = feeBump.Base64()
+		if err != nil {
+			return aurora.Transaction{}, errors.Wrap(err, "unable to serialize fee bump tx")
+		}
+		result, err = p.config.Client.SubmitTransactionXDR(envelope)
+	}
+	if err != nil {
+		return aurora.Transaction{}, errors.Wrap(err, "submitting tx to aurora")
+	}
+	return result, nil
+}
+
+// transactionCode returns the result code of a failed submission, or the
+// result code of the inner transaction if a fee bump transaction failed
+// because of it.
+func transactionCode(err error) string {
+	herr := auroraclient.GetError(err)
+	if herr == nil {
+		return ""
+	}
+	codes, err := herr.ResultCodes()
+	if err != nil {
+		return ""
+	}
+	if codes.TransactionCode == txFeeBumpInnerFailed {
+		return codes.InnerTransactionCode
+	}
+	return codes.TransactionCode
+}
@@ -0,0 +1,235 @@
+package txpool
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/clients/auroraclient"
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/txnbuild"
+)
+
+func resultCodesError(codes aurora.TransactionResultCodes) *auroraclient.Error {
+	return &auroraclient.Error{
+		Problem: problem.P{
+			Type:   "transaction_failed",
+			Status: 400,
+			Extras: map[string]interface{}{"result_codes": codes},
+		},
+	}
+}
+
+func testRequest(destination string) Request {
+	return Request{
+		Operations: []txnbuild.Operation{&txnbuild.Payment{
+			Destination: destination,
+			Amount:      "10",
+			Asset:       txnbuild.NativeAsset{},
+		}},
+		Preconditions: txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()},
+	}
+}
+
+func parseEnvelope(t *testing.T, envelope string) *txnbuild.GenericTransaction {
+	tx, err := txnbuild.TransactionFromXDR(envelope)
+	require.NoError(t, err)
+	return tx
+}
+
+func TestSequenceManager(t *testing.T) {
+	kp := keypair.MustRandom()
+	client := &auroraclient.MockClient{}
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: kp.Address()}).
+		Return(aurora.Account{AccountID: kp.Address(), Sequence: 10}, nil).Twice()
+
+	sequences := NewSequenceManager(client)
+	for _, expected := range []int64{10, 11, 12} {
+		account, err := sequences.Next(kp.Address())
+		require.NoError(t, err)
+		assert.Equal(t, expected, account.Sequence)
+	}
+
+	sequences.Reset(kp.Address())
+	account, err := sequences.Next(kp.Address())
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), account.Sequence)
+	client.AssertExpectations(t)
+}
+
+func TestNewPoolValidation(t *testing.T) {
+	client := &auroraclient.MockClient{}
+	_, err := New(Config{NetworkPassphrase: network.TestNetworkPassphrase, Channels: []*keypair.Full{keypair.MustRandom()}})
+	assert.EqualError(t, err, "client cannot be nil")
+	_, err = New(Config{Client: client, Channels: []*keypair.Full{keypair.MustRandom()}})
+	assert.EqualError(t, err, "network passphrase cannot be empty")
+	_, err = New(Config{Client: client, NetworkPassphrase: network.TestNetworkPassphrase})
+	assert.EqualError(t, err, "at least one channel account is required")
+}
+
+func TestPoolSubmit(t *testing.T) {
+	channels := []*keypair.Full{keypair.MustRandom(), keypair.MustRandom()}
+	destination := keypair.MustRandom().Address()
+	client := &auroraclient.MockClient{}
+	for _, channel := range channels {
+		client.On("AccountDetail", auroraclient.AccountRequest{AccountID: channel.Address()}).
+			Return(aurora.Account{AccountID: channel.Address(), Sequence: 100}, nil).Once()
+	}
+
+	var lock sync.Mutex
+	sequences := map[string][]int64{}
+	client.On("SubmitTransactionXDR", mock.Anything).Run(func(args mock.Arguments) {
+		tx, ok := parseEnvelope(t, args.String(0)).Transaction()
+		require.True(t, ok)
+		lock.Lock()
+		defer lock.Unlock()
+		source := tx.SourceAccount().AccountID
+		sequences[source] = append(sequences[source], tx.SequenceNumber())
+	}).Return(aurora.Transaction{Successful: true}, nil)
+
+	pool, err := New(Config{Client: client, NetworkPassphrase: network.TestNetworkPassphrase, Channels: channels})
+	require.NoError(t, err)
+
+	var futures []*Future
+	for i := 0; i < 20; i++ {
+		futures = append(futures, pool.Submit(context.Background(), testRequest(destination)))
+	}
+	for _, future := range futures {
+		result, err := future.Wait(context.Background())
+		require.NoError(t, err)
+		assert.True(t, result.Successful)
+	}
+
+	// every channel account submits its transactions in sequence order
+	total := 0
+	for _, used := range sequences {
+		for i, sequence := range used {
+			assert.Equal(t, int64(101+i), sequence)
+		}
+		total += len(used)
+	}
+	assert.Equal(t, 20, total)
+	client.AssertExpectations(t)
+}
+
+func TestPoolBadSequence(t *testing.T) {
+	channel := keypair.MustRandom()
+	client := &auroraclient.MockClient{}
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: channel.Address()}).
+		Return(aurora.Account{AccountID: channel.Address(), Sequence: 100}, nil).Once()
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: channel.Address()}).
+		Return(aurora.Account{AccountID: channel.Address(), Sequence: 200}, nil).Once()
+
+	var submitted []int64
+	record := func(args mock.Arguments) {
+		tx, _ := parseEnvelope(t, args.String(0)).Transaction()
+		submitted = append(submitted, tx.SequenceNumber())
+	}
+	client.On("SubmitTransactionXDR", mock.Anything).Run(record).
+		Return(aurora.Transaction{}, resultCodesError(aurora.TransactionResultCodes{TransactionCode: "tx_bad_seq"})).Once()
+	client.On("SubmitTransactionXDR", mock.Anything).Run(record).
+		Return(aurora.Transaction{Successful: true}, nil).Once()
+
+	pool, err := New(Config{
+		Client:            client,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		Channels:          []*keypair.Full{channel},
+	})
+	require.NoError(t, err)
+	_, err = pool.Submit(context.Background(), testRequest(keypair.MustRandom().Address())).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []int64{101, 201}, submitted)
+	client.AssertExpectations(t)
+
+	// the next transaction reuses the cached sequence number
+	account, err := pool.Sequences().Next(channel.Address())
+	require.NoError(t, err)
+	assert.Equal(t, int64(201), account.Sequence)
+}
+
+func TestPoolBadSequenceRetries(t *testing.T) {
+	channel := keypair.MustRandom()
+	client := &auroraclient.MockClient{}
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: channel.Address()}).
+		Return(aurora.Account{AccountID: channel.Address(), Sequence: 100}, nil).Times(3)
+	client.On("SubmitTransactionXDR", mock.Anything).
+		Return(aurora.Transaction{}, resultCodesError(aurora.TransactionResultCodes{TransactionCode: "tx_bad_seq"})).Times(3)
+
+	pool, err := New(Config{
+		Client:            client,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		Channels:          []*keypair.Full{channel},
+		MaxRetries:        2,
+	})
+	require.NoError(t, err)
+	_, err = pool.Submit(context.Background(), testRequest(keypair.MustRandom().Address())).Result()
+	assert.EqualError(t, err, "submitting tx to aurora: aurora error: \"\" (tx_bad_seq) - check aurora.Error.Problem for more information")
+	client.AssertExpectations(t)
+}
+
+func TestPoolFeeBump(t *testing.T) {
+	channel := keypair.MustRandom()
+	feeAccount := keypair.MustRandom()
+	client := &auroraclient.MockClient{}
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: channel.Address()}).
+		Return(aurora.Account{AccountID: channel.Address(), Sequence: 100}, nil).Once()
+
+	var envelopes []string
+	record := func(args mock.Arguments) {
+		envelopes = append(envelopes, args.String(0))
+	}
+	insufficientFee := resultCodesError(aurora.TransactionResultCodes{TransactionCode: "tx_insufficient_fee"})
+	client.On("SubmitTransactionXDR", mock.Anything).Run(record).Return(aurora.Transaction{}, insufficientFee).Twice()
+	client.On("SubmitTransactionXDR", mock.Anything).Run(record).Return(aurora.Transaction{Successful: true}, nil).Once()
+
+	pool, err := New(Config{
+		Client:            client,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		Channels:          []*keypair.Full{channel},
+		MaxBaseFee:        300,
+		FeeAccount:        feeAccount,
+	})
+	require.NoError(t, err)
+	_, err = pool.Submit(context.Background(), testRequest(keypair.MustRandom().Address())).Result()
+	require.NoError(t, err)
+	client.AssertExpectations(t)
+
+	require.Len(t, envelopes, 3)
+	inner, ok := parseEnvelope(t, envelopes[0]).Transaction()
+	require.True(t, ok)
+	assert.Equal(t, int64(txnbuild.MinBaseFee), inner.BaseFee())
+	for i, expectedFee := range []int64{200, 300} {
+		feeBump, ok := parseEnvelope(t, envelopes[i+1]).FeeBump()
+		require.True(t, ok)
+		assert.Equal(t, expectedFee, feeBump.BaseFee())
+		assert.Equal(t, feeAccount.Address(), feeBump.FeeAccount())
+		assert.Equal(t, inner.Signatures(), feeBump.InnerTransaction().Signatures())
+	}
+}
+
+func TestPoolContextCanceled(t *testing.T) {
+	channel := keypair.MustRandom()
+	client := &auroraclient.MockClient{}
+	pool, err := New(Config{
+		Client:            client,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		Channels:          []*keypair.Full{channel},
+	})
+	require.NoError(t, err)
+
+	// take the only channel account so that the request has to wait for it
+	<-pool.channels
+	ctx, cancel := context.WithCancel(context.Background())
+	future := pool.Submit(ctx, testRequest(keypair.MustRandom().Address()))
+	cancel()
+	_, err = future.Result()
+	assert.Equal(t, context.Canceled, err)
+	client.AssertExpectations(t)
+}
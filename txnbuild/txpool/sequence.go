@@ -0,0 +1,62 @@
+package txpool
+
+import (
+	"sync"
+
+	"github.com/hcnet/go/clients/auroraclient"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+)
+
+// SequenceManager caches the sequence numbers of accounts so that
+// transactions can be built without loading the source account from aurora
+// every time. The sequence number of an account is loaded from aurora the
+// first time it is needed and after Reset is called, which must happen
+// whenever a transaction was not applied, for example after a tx_bad_seq
+// error.
+//
+// SequenceManager is safe for concurrent use but sequence numbers are handed
+// out in order, it is up to the caller to submit the transactions of an
+// account in the same order.
+type SequenceManager struct {
+	client    auroraclient.ClientInterface
+	lock      sync.Mutex
+	sequences map[string]int64
+}
+
+// NewSequenceManager constructs a SequenceManager which loads sequence
+// numbers with the given client.
+func NewSequenceManager(client auroraclient.ClientInterface) *SequenceManager {
+	return &SequenceManager{
+		client:    client,
+		sequences: map[string]int64{},
+	}
+}
+
+// Next reserves the next sequence number of the account and returns a
+// txnbuild.Account which builds a transaction with that sequence number when
+// IncrementSequenceNum is set.
+func (m *SequenceManager) Next(accountID string) (*txnbuild.SimpleAccount, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	sequence, ok := m.sequences[accountID]
+	if !ok {
+		account, err := m.client.AccountDetail(auroraclient.AccountRequest{AccountID: accountID})
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not load sequence number of %s", accountID)
+		}
+		sequence = account.Sequence
+	}
+	m.sequences[accountID] = sequence + 1
+	account := txnbuild.NewSimpleAccount(accountID, sequence)
+	return &account, nil
+}
+
+// Reset drops the cached sequence number of the account. It is loaded again
+// from aurora by the next call to Next.
+func (m *SequenceManager) Reset(accountID string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.sequences, accountID)
+}